@@ -55,3 +55,17 @@ func (a *AdminAPI) Reconfigurations(ctx context.Context) ([]Reconfiguration, err
 	var response []Reconfiguration
 	return response, a.sendAny(ctx, http.MethodGet, "/v1/partitions/reconfigurations", nil, &response)
 }
+
+// UpdatePartitionReplicas moves the replicas of the partition to the given
+// ones, starting a partition reconfiguration whose progress is reported by
+// Reconfigurations.
+func (a *AdminAPI) UpdatePartitionReplicas(
+	ctx context.Context, namespace, topic string, partition int, replicas []Replica,
+) error {
+	return a.sendAny(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("/v1/partitions/%s/%s/%d/replicas", namespace, topic, partition),
+		replicas,
+		nil)
+}
@@ -0,0 +1,250 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package schemaregistry provides a client to interact with Redpanda's schema
+// registry.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/net"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// Schema types supported by the schema registry.
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+	TypeJSON     = "JSON"
+)
+
+// Error codes returned by the schema registry in the error_code field of its
+// error responses.
+const (
+	// ErrCodeSubjectNotFound is returned when the request subject doesn't
+	// exist.
+	ErrCodeSubjectNotFound = 40401
+	// ErrCodeSubjectLevelNotFound is returned when the subject doesn't have
+	// its own compatibility level.
+	ErrCodeSubjectLevelNotFound = 40408
+)
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// ResponseError is the error returned when the schema registry replies with
+// a non 2xx status.
+type ResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// ErrorCode and Message are decoded from the response body, if the body
+	// is a schema registry error.
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("request %s %s failed: %s (error code %d)", e.Method, e.URL, e.Message, e.ErrorCode)
+	}
+	return fmt.Sprintf("request %s %s failed: %s", e.Method, e.URL, http.StatusText(e.StatusCode))
+}
+
+// Client is a client to interact with the schema registry.
+type Client struct {
+	urls   []string
+	cl     *http.Client
+	user   string
+	passwd string
+}
+
+// NewClient returns a Client that talks to each of the addresses in the
+// rpk.schema_registry section of the config, authenticating with the SASL
+// credentials of the rpk.kafka_api section, if any.
+func NewClient(fs afero.Fs, cfg *config.Config) (*Client, error) {
+	sr := &cfg.Rpk.SchemaRegistry
+	tc, err := sr.TLS.Config(fs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create schema registry tls config: %v", err)
+	}
+	var user, passwd string
+	if sasl := cfg.Rpk.KafkaAPI.SASL; sasl != nil {
+		user, passwd = sasl.User, sasl.Password
+	}
+	return NewHostClient(sr.Addresses, user, passwd, tc)
+}
+
+// NewHostClient returns a Client that talks to the given hosts, using basic
+// authentication if user is not empty.
+func NewHostClient(hosts []string, user, passwd string, tlsConfig *tls.Config) (*Client, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("at least one url is required for the schema registry")
+	}
+	c := &Client{
+		cl:     &http.Client{Timeout: 10 * time.Second},
+		user:   user,
+		passwd: passwd,
+	}
+	if tlsConfig != nil {
+		c.cl.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+	for _, h := range hosts {
+		scheme, host, err := net.ParseHostMaybeScheme(h)
+		if err != nil {
+			return nil, err
+		}
+		switch scheme {
+		case "", "http":
+			scheme = "http"
+			if tlsConfig != nil {
+				scheme = "https"
+			}
+		case "https":
+		default:
+			return nil, fmt.Errorf("unrecognized scheme %q in host %q", scheme, h)
+		}
+		c.urls = append(c.urls, fmt.Sprintf("%s://%s", scheme, host))
+	}
+	return c, nil
+}
+
+// Schema is a schema to register or to check, as sent to the registry.
+type Schema struct {
+	Schema string `json:"schema"`
+	// Type is the schema type, the registry considering an empty type as
+	// AVRO.
+	Type string `json:"schemaType,omitempty"`
+}
+
+// CompatibilityResult is the result of a compatibility check.
+type CompatibilityResult struct {
+	IsCompatible bool `json:"is_compatible"`
+	// Messages describe why the schema is incompatible, if the registry
+	// supports verbose compatibility checks.
+	Messages []string `json:"messages,omitempty"`
+}
+
+// CheckCompatibility checks if the schema is compatible with the given
+// version of the subject, e.g. "latest", according to the compatibility level
+// of the subject.
+func (c *Client) CheckCompatibility(
+	ctx context.Context, subject, version string, schema Schema,
+) (CompatibilityResult, error) {
+	var res CompatibilityResult
+	path := fmt.Sprintf("/compatibility/subjects/%s/versions/%s?verbose=true", url.PathEscape(subject), url.PathEscape(version))
+	return res, c.sendAny(ctx, http.MethodPost, path, schema, &res)
+}
+
+type compatibilityLevel struct {
+	// Compatibility is set in requests, the registry replying with
+	// CompatibilityLevel.
+	Compatibility      string `json:"compatibility,omitempty"`
+	CompatibilityLevel string `json:"compatibilityLevel,omitempty"`
+}
+
+// SubjectCompatibility returns the compatibility level of the subject. If
+// the subject doesn't have its own level, this returns a ResponseError with
+// the ErrCodeSubjectLevelNotFound error code.
+func (c *Client) SubjectCompatibility(ctx context.Context, subject string) (string, error) {
+	var res compatibilityLevel
+	err := c.sendAny(ctx, http.MethodGet, "/config/"+url.PathEscape(subject), nil, &res)
+	return res.CompatibilityLevel, err
+}
+
+// GlobalCompatibility returns the global compatibility level, which applies
+// to the subjects without their own level.
+func (c *Client) GlobalCompatibility(ctx context.Context) (string, error) {
+	var res compatibilityLevel
+	err := c.sendAny(ctx, http.MethodGet, "/config", nil, &res)
+	return res.CompatibilityLevel, err
+}
+
+// SetSubjectCompatibility sets the compatibility level of the subject.
+func (c *Client) SetSubjectCompatibility(ctx context.Context, subject, level string) error {
+	return c.sendAny(ctx, http.MethodPut, "/config/"+url.PathEscape(subject), compatibilityLevel{Compatibility: level}, nil)
+}
+
+// DeleteSubjectCompatibility deletes the compatibility level of the subject,
+// which then falls back to the global level.
+func (c *Client) DeleteSubjectCompatibility(ctx context.Context, subject string) error {
+	return c.sendAny(ctx, http.MethodDelete, "/config/"+url.PathEscape(subject), nil, nil)
+}
+
+// sendAny sends the request to each of the client's urls in turn until one
+// replies, and unmarshals the reply into into, if it is not nil. A
+// ResponseError is returned as is, without trying the next url, as the urls
+// are instances of the same registry.
+func (c *Client) sendAny(ctx context.Context, method, path string, body, into interface{}) error {
+	var err error
+	for i, u := range c.urls {
+		if i > 0 {
+			log.Infof("Request error, trying another node: %s", err)
+		}
+		err = c.send(ctx, method, u+path, body, into)
+		var re *ResponseError
+		if err == nil || errors.As(err, &re) {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method, url string, body, into interface{}) error {
+	var r io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("unable to encode request body for %s %s: %w", method, url, err)
+		}
+		r = bytes.NewReader(bs)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return err
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.passwd)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+
+	res, err := c.cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read %s %s response body: %w", method, url, err)
+	}
+	if res.StatusCode/100 != 2 {
+		re := &ResponseError{Method: method, URL: url, StatusCode: res.StatusCode}
+		json.Unmarshal(resBody, re) // best effort, the body may be empty
+		return re
+	}
+	if into == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resBody, into); err != nil {
+		return fmt.Errorf("unable to decode %s %s response body: %w", method, url, err)
+	}
+	return nil
+}
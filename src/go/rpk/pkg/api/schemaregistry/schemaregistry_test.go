@@ -0,0 +1,69 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CheckCompatibility(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/compatibility/subjects/foo%2Fbar/versions/latest", r.URL.EscapedPath())
+		require.Equal(t, "true", r.URL.Query().Get("verbose"))
+		require.Equal(t, contentType, r.Header.Get("Content-Type"))
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "pass", pass)
+		w.Write([]byte(`{"is_compatible":false,"messages":["field removed"]}`))
+	}))
+	defer ts.Close()
+
+	// The first host is down, the client falls back to the second one.
+	cl, err := NewHostClient([]string{"127.0.0.1:1", ts.URL}, "user", "pass", nil)
+	require.NoError(t, err)
+	res, err := cl.CheckCompatibility(context.Background(), "foo/bar", "latest", Schema{Schema: "{}", Type: TypeJSON})
+	require.NoError(t, err)
+	require.Equal(t, CompatibilityResult{Messages: []string{"field removed"}}, res)
+}
+
+func TestClient_responseError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error_code":40408,"message":"Subject 'foo' does not have subject-level compatibility configured"}`))
+	}))
+	defer ts.Close()
+
+	cl, err := NewHostClient([]string{ts.URL}, "", "", nil)
+	require.NoError(t, err)
+	_, err = cl.SubjectCompatibility(context.Background(), "foo")
+	var re *ResponseError
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, http.StatusNotFound, re.StatusCode)
+	require.Equal(t, ErrCodeSubjectLevelNotFound, re.ErrorCode)
+}
+
+func TestNewHostClient(t *testing.T) {
+	cl, err := NewHostClient([]string{"localhost:8081", "https://10.0.0.1:8081"}, "", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://localhost:8081", "https://10.0.0.1:8081"}, cl.urls)
+
+	_, err = NewHostClient([]string{"ftp://localhost:8081"}, "", "", nil)
+	require.Error(t, err)
+
+	_, err = NewHostClient(nil, "", "", nil)
+	require.Error(t, err)
+}
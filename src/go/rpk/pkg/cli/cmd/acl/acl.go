@@ -71,6 +71,7 @@ func NewCommand(fs afero.Fs) *cobra.Command {
 		&adminAPITruststoreFile,
 	)
 
+	command.AddCommand(newApplyCommand(fs))
 	command.AddCommand(newCreateCommand(fs))
 	command.AddCommand(newDeleteCommand(fs))
 	command.AddCommand(newListCommand(fs))
@@ -182,7 +183,8 @@ Creating ACLs works on a specific ACL basis, but listing and deleting ACLs
 works on filters. Filters allow matching many ACLs to be printed listed and
 deleted at once. Because this can be risky for deleting, the delete command
 prompts for confirmation by default. More details and examples for creating,
-listing, and deleting can be seen in each of the commands.
+listing, and deleting can be seen in each of the commands. To manage ACLs from
+a policy file instead, see the "apply" command.
 
 Using SASL requires setting "enable_sasl: true" in the redpanda section of your
 redpanda.yaml. User management is a separate, simpler concept that is
@@ -0,0 +1,402 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/twmb/types"
+	"gopkg.in/yaml.v3"
+)
+
+func newApplyCommand(fs afero.Fs) *cobra.Command {
+	var (
+		file   string
+		prune  bool
+		dryRun bool
+	)
+	cmd := &cobra.Command{
+		Use:   "apply -f [FILE]",
+		Short: "Create and delete ACLs to match a policy file",
+		Long: `Create and delete ACLs to match a policy file.
+
+See the 'rpk acl' help text for a full write up on ACLs. The policy file lists
+the desired rules, each granting or denying operations on a resource to a
+principal, e.g.:
+
+    acls:
+      - principal: User:alice
+        resource_type: topic
+        resource_name: orders
+        operations: [read, describe]
+        permission: allow
+      - principal: bob
+        host: 10.0.0.1
+        resource_type: group
+        resource_name: billing-
+        pattern_type: prefixed
+        operations: [read]
+        permission: allow
+      - principal: '*'
+        resource_type: cluster
+        operations: [alter]
+        permission: deny
+
+The host defaults to the wildcard '*', the pattern type to literal, and the
+name of the cluster resource is always "kafka-cluster". As with the other ACL
+commands, principals without a type are prefixed with "User:". Each operation
+of a rule is a separate ACL.
+
+The rules missing from the cluster are created. With --prune, the ACLs of the
+cluster missing from the file are deleted as well, so that the cluster has
+exactly the ACLs of the file; without it, no ACL is ever deleted. Applying the
+same file twice changes nothing.
+
+The --dry-run flag prints the ACLs that would be created and deleted, without
+changing them.
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if file == "" {
+				out.Die("missing the policy file, use -f")
+			}
+			spec, err := readACLsSpec(fs, file)
+			out.MaybeDie(err, "unable to read %q: %v", file, err)
+			desired, err := spec.rules()
+			out.MaybeDie(err, "invalid policy %q: %v", file, err)
+
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			adm, err := kafka.NewAdmin(fs, p, cfg)
+			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
+			defer adm.Close()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+
+			existing, err := describeAllACLs(ctx, adm)
+			out.MaybeDie(err, "unable to list ACLs: %v", err)
+			changes := planACLChanges(desired, existing, prune)
+
+			if len(changes) == 0 {
+				fmt.Println("All ACLs are up to date.")
+				return
+			}
+			if dryRun {
+				tw := out.NewTable(append([]string{"Action"}, headers...)...)
+				defer tw.Flush()
+				for _, c := range changes {
+					tw.Print(append([]interface{}{c.action}, c.fields()...)...)
+				}
+				return
+			}
+
+			var exit1 bool
+			defer func() {
+				if exit1 {
+					os.Exit(1)
+				}
+			}()
+			tw := out.NewTable(append([]string{"Action"}, headersWithError...)...)
+			defer tw.Flush()
+			for _, c := range changes {
+				msg := kafka.ErrMessage(applyACLChange(ctx, adm, c))
+				exit1 = exit1 || msg != ""
+				if msg == "" {
+					msg = "OK"
+				}
+				tw.Print(append(append([]interface{}{c.action}, c.fields()...), msg)...)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Policy file listing the desired ACLs")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete the ACLs of the cluster missing from the policy file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the ACLs to create and delete, without changing them")
+	return cmd
+}
+
+// aclsSpec is the desired set of ACLs, as read from the file given to
+// 'rpk acl apply'.
+type aclsSpec struct {
+	ACLs []aclSpec `yaml:"acls"`
+}
+
+type aclSpec struct {
+	Principal string `yaml:"principal"`
+	// Host defaults to '*', PatternType to literal.
+	Host         string   `yaml:"host"`
+	ResourceType string   `yaml:"resource_type"`
+	ResourceName string   `yaml:"resource_name"`
+	PatternType  string   `yaml:"pattern_type"`
+	Operations   []string `yaml:"operations"`
+	Permission   string   `yaml:"permission"`
+}
+
+func readACLsSpec(fs afero.Fs, path string) (*aclsSpec, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	var spec aclsSpec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// rules returns the ACLs of the spec, one per operation of each of its
+// rules, in file order and without duplicates.
+func (s *aclsSpec) rules() ([]acl, error) {
+	var (
+		rules []acl
+		seen  = map[acl]bool{}
+	)
+	for i, spec := range s.ACLs {
+		parsed, err := spec.parse()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i+1, err)
+		}
+		for _, rule := range parsed {
+			if !seen[rule] {
+				seen[rule] = true
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (s aclSpec) parse() ([]acl, error) {
+	if s.Principal == "" {
+		return nil, errors.New("missing principal")
+	}
+	principal := s.Principal
+	if !strings.HasPrefix(principal, "User:") {
+		principal = "User:" + principal
+	}
+	host := s.Host
+	if host == "" {
+		host = "*"
+	}
+	resourceType, err := kmsg.ParseACLResourceType(s.ResourceType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource type %q", s.ResourceType)
+	}
+	name := s.ResourceName
+	switch resourceType {
+	case kmsg.ACLResourceTypeTopic, kmsg.ACLResourceTypeGroup, kmsg.ACLResourceTypeTransactionalId:
+		if name == "" {
+			return nil, fmt.Errorf("missing resource name of %s", s.ResourceType)
+		}
+	case kmsg.ACLResourceTypeCluster:
+		if name != "" && name != kafkaCluster {
+			return nil, fmt.Errorf("invalid cluster name %q, it can only be %q", name, kafkaCluster)
+		}
+		name = kafkaCluster
+	default:
+		return nil, fmt.Errorf("unsupported resource type %q, use topic, group, cluster or transactional_id", s.ResourceType)
+	}
+	patternType := s.PatternType
+	if patternType == "" {
+		patternType = "literal"
+	}
+	pattern, err := kmsg.ParseACLResourcePatternType(patternType)
+	if err != nil || (pattern != kmsg.ACLResourcePatternTypeLiteral && pattern != kmsg.ACLResourcePatternTypePrefixed) {
+		return nil, fmt.Errorf("invalid pattern type %q, use literal or prefixed", s.PatternType)
+	}
+	permission, err := kmsg.ParseACLPermissionType(s.Permission)
+	if err != nil || permission == kmsg.ACLPermissionTypeAny {
+		return nil, fmt.Errorf("invalid permission %q, use allow or deny", s.Permission)
+	}
+	if len(s.Operations) == 0 {
+		return nil, errors.New("missing operations")
+	}
+	var rules []acl
+	for _, op := range s.Operations {
+		operation, err := kmsg.ParseACLOperation(op)
+		if err != nil || operation == kmsg.ACLOperationAny {
+			return nil, fmt.Errorf("invalid operation %q", op)
+		}
+		rules = append(rules, acl{
+			Principal:           principal,
+			Host:                host,
+			ResourceType:        resourceType,
+			ResourceName:        name,
+			ResourcePatternType: pattern,
+			Operation:           operation,
+			Permission:          permission,
+		})
+	}
+	return rules, nil
+}
+
+// describeAllACLs returns every ACL of the cluster, sorted.
+func describeAllACLs(ctx context.Context, adm *kadm.Client) ([]acl, error) {
+	b := kadm.NewACLs().
+		AnyResource().
+		ResourcePatternType(kadm.ACLPatternAny).
+		Operations().
+		Allow().
+		AllowHosts().
+		Deny().
+		DenyHosts()
+	results, err := adm.DescribeACLs(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	types.Sort(results)
+	var acls []acl
+	for _, f := range results {
+		if f.Err != nil {
+			return nil, f.Err
+		}
+		for _, d := range f.Described {
+			acls = append(acls, acl{
+				d.Principal,
+				d.Host,
+				d.Type,
+				d.Name,
+				d.Pattern,
+				d.Operation,
+				d.Permission,
+			})
+		}
+	}
+	return acls, nil
+}
+
+// The actions of an aclChange.
+const (
+	aclActionCreate = "create"
+	aclActionDelete = "delete"
+)
+
+// aclChange is an ACL to create or delete to bring the cluster closer to the
+// policy file.
+type aclChange struct {
+	action string
+	acl    acl
+}
+
+// fields returns the columns of the ACL, as printed with headers.
+func (c aclChange) fields() []interface{} {
+	return []interface{}{
+		c.acl.Principal,
+		c.acl.Host,
+		c.acl.ResourceType,
+		c.acl.ResourceName,
+		c.acl.ResourcePatternType,
+		c.acl.Operation,
+		c.acl.Permission,
+	}
+}
+
+// planACLChanges returns the creations of the desired ACLs missing from the
+// existing ones, in desired order, followed, if pruning, by the deletions of
+// the existing ACLs that aren't desired, in existing order.
+func planACLChanges(desired, existing []acl, prune bool) []aclChange {
+	var (
+		changes    []aclChange
+		isExisting = map[acl]bool{}
+		isDesired  = map[acl]bool{}
+	)
+	for _, a := range existing {
+		isExisting[a] = true
+	}
+	for _, a := range desired {
+		isDesired[a] = true
+		if !isExisting[a] {
+			changes = append(changes, aclChange{aclActionCreate, a})
+		}
+	}
+	if !prune {
+		return changes
+	}
+	for _, a := range existing {
+		if !isDesired[a] {
+			changes = append(changes, aclChange{aclActionDelete, a})
+		}
+	}
+	return changes
+}
+
+// aclBuilder returns a builder matching exactly the given ACL, to create or
+// delete it.
+func aclBuilder(a acl) *kadm.ACLBuilder {
+	b := kadm.NewACLs().
+		ResourcePatternType(a.ResourcePatternType).
+		Operations(a.Operation)
+	switch a.ResourceType {
+	case kmsg.ACLResourceTypeTopic:
+		b.Topics(a.ResourceName)
+	case kmsg.ACLResourceTypeGroup:
+		b.Groups(a.ResourceName)
+	case kmsg.ACLResourceTypeCluster:
+		b.Clusters()
+	case kmsg.ACLResourceTypeTransactionalId:
+		b.TransactionalIDs(a.ResourceName)
+	case kmsg.ACLResourceTypeDelegationToken:
+		b.DelegationTokens(a.ResourceName)
+	}
+	if a.Permission == kmsg.ACLPermissionTypeDeny {
+		b.Deny(a.Principal).DenyHosts(a.Host)
+	} else {
+		b.Allow(a.Principal).AllowHosts(a.Host)
+	}
+	return b
+}
+
+func applyACLChange(ctx context.Context, adm *kadm.Client, c aclChange) error {
+	b := aclBuilder(c.acl)
+	if c.action == aclActionCreate {
+		results, err := adm.CreateACLs(ctx, b)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				return r.Err
+			}
+		}
+		return nil
+	}
+	results, err := adm.DeleteACLs(ctx, b)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			return r.Err
+		}
+		for _, d := range r.Deleted {
+			if d.Err != nil {
+				return d.Err
+			}
+		}
+	}
+	return nil
+}
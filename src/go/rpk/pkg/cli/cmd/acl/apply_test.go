@@ -0,0 +1,164 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package acl
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestReadACLsSpecRules(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		in     string
+		exp    []acl
+		expErr bool
+	}{
+		{
+			name: "full spec",
+			in: `acls:
+  - principal: User:alice
+    resource_type: topic
+    resource_name: orders
+    operations: [read, describe]
+    permission: allow
+  - principal: bob
+    host: 10.0.0.1
+    resource_type: group
+    resource_name: billing-
+    pattern_type: prefixed
+    operations: [read]
+    permission: allow
+  - principal: '*'
+    resource_type: cluster
+    operations: [alter]
+    permission: deny
+`,
+			exp: []acl{
+				{"User:alice", "*", kmsg.ACLResourceTypeTopic, "orders", kmsg.ACLResourcePatternTypeLiteral, kmsg.ACLOperationRead, kmsg.ACLPermissionTypeAllow},
+				{"User:alice", "*", kmsg.ACLResourceTypeTopic, "orders", kmsg.ACLResourcePatternTypeLiteral, kmsg.ACLOperationDescribe, kmsg.ACLPermissionTypeAllow},
+				{"User:bob", "10.0.0.1", kmsg.ACLResourceTypeGroup, "billing-", kmsg.ACLResourcePatternTypePrefixed, kmsg.ACLOperationRead, kmsg.ACLPermissionTypeAllow},
+				{"User:*", "*", kmsg.ACLResourceTypeCluster, kafkaCluster, kmsg.ACLResourcePatternTypeLiteral, kmsg.ACLOperationAlter, kmsg.ACLPermissionTypeDeny},
+			},
+		},
+		{
+			name: "duplicates are dropped",
+			in: `acls:
+  - {principal: alice, resource_type: transactional_id, resource_name: txn, operations: [write, write], permission: allow}
+  - {principal: User:alice, resource_type: transactional_id, resource_name: txn, operations: [write], permission: allow}
+`,
+			exp: []acl{
+				{"User:alice", "*", kmsg.ACLResourceTypeTransactionalId, "txn", kmsg.ACLResourcePatternTypeLiteral, kmsg.ACLOperationWrite, kmsg.ACLPermissionTypeAllow},
+			},
+		},
+		{
+			name:   "unknown field",
+			in:     "acls:\n  - principal: alice\n    topic: foo\n",
+			expErr: true,
+		},
+		{
+			name:   "missing principal",
+			in:     "acls:\n  - {resource_type: topic, resource_name: foo, operations: [read], permission: allow}\n",
+			expErr: true,
+		},
+		{
+			name:   "missing resource name",
+			in:     "acls:\n  - {principal: alice, resource_type: topic, operations: [read], permission: allow}\n",
+			expErr: true,
+		},
+		{
+			name:   "invalid cluster name",
+			in:     "acls:\n  - {principal: alice, resource_type: cluster, resource_name: foo, operations: [read], permission: allow}\n",
+			expErr: true,
+		},
+		{
+			name:   "match pattern type",
+			in:     "acls:\n  - {principal: alice, resource_type: topic, resource_name: foo, pattern_type: match, operations: [read], permission: allow}\n",
+			expErr: true,
+		},
+		{
+			name:   "any permission",
+			in:     "acls:\n  - {principal: alice, resource_type: topic, resource_name: foo, operations: [read], permission: any}\n",
+			expErr: true,
+		},
+		{
+			name:   "missing operations",
+			in:     "acls:\n  - {principal: alice, resource_type: topic, resource_name: foo, permission: allow}\n",
+			expErr: true,
+		},
+		{
+			name:   "invalid operation",
+			in:     "acls:\n  - {principal: alice, resource_type: topic, resource_name: foo, operations: [fly], permission: allow}\n",
+			expErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/acls.yaml", []byte(test.in), 0o644))
+			var got []acl
+			spec, err := readACLsSpec(fs, "/acls.yaml")
+			if err == nil {
+				got, err = spec.rules()
+			}
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, got)
+		})
+	}
+}
+
+func TestPlanACLChanges(t *testing.T) {
+	read := acl{"User:alice", "*", kmsg.ACLResourceTypeTopic, "orders", kmsg.ACLResourcePatternTypeLiteral, kmsg.ACLOperationRead, kmsg.ACLPermissionTypeAllow}
+	prefixed := acl{"User:alice", "*", kmsg.ACLResourceTypeTopic, "orders", kmsg.ACLResourcePatternTypePrefixed, kmsg.ACLOperationRead, kmsg.ACLPermissionTypeAllow}
+	deny := acl{"User:bob", "*", kmsg.ACLResourceTypeGroup, "g", kmsg.ACLResourcePatternTypeLiteral, kmsg.ACLOperationRead, kmsg.ACLPermissionTypeDeny}
+
+	for _, test := range []struct {
+		name     string
+		desired  []acl
+		existing []acl
+		prune    bool
+		exp      []aclChange
+	}{
+		{
+			name:    "creates missing ACLs",
+			desired: []acl{read, prefixed},
+			exp:     []aclChange{{aclActionCreate, read}, {aclActionCreate, prefixed}},
+		},
+		{
+			name:     "up to date",
+			desired:  []acl{read},
+			existing: []acl{read},
+			prune:    true,
+		},
+		{
+			name:     "keeps extra ACLs without prune",
+			desired:  []acl{prefixed},
+			existing: []acl{read, deny},
+			exp:      []aclChange{{aclActionCreate, prefixed}},
+		},
+		{
+			name:     "deletes extra ACLs with prune",
+			desired:  []acl{prefixed},
+			existing: []acl{read, deny},
+			prune:    true,
+			exp:      []aclChange{{aclActionCreate, prefixed}, {aclActionDelete, read}, {aclActionDelete, deny}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.exp, planACLChanges(test.desired, test.existing, test.prune))
+		})
+	}
+}
@@ -38,7 +38,8 @@ the cluster.  These are separate to node properties, which are set with
 
 Use the 'edit' subcommand to interactively modify the cluster configuration, or
 'export' and 'import' to write configuration to a file that can be edited and
-read back later.
+read back later. The 'diff' subcommand shows how the cluster configuration
+differs from a file, e.g. a version-controlled one.
 
 These commands take an optional '--all' flag to include all properties including
 low level tunables such as internal buffer sizes, that do not usually need
@@ -75,6 +76,7 @@ different redpanda version that does not recognize certain properties.`,
 		newImportCommand(fs, &all),
 		newExportCommand(fs, &all),
 		newEditCommand(fs, &all),
+		newDiffCommand(fs, &all),
 		newStatusCommand(fs),
 		newForceResetCommand(fs),
 		newLintCommand(fs),
@@ -0,0 +1,253 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// propertyDiff is how a cluster property differs between the live cluster
+// and the file: added properties are only set in the cluster, removed ones
+// only in the file.
+type propertyDiff struct {
+	Property string
+	Status   string
+	File     string
+	Cluster  string
+}
+
+func newDiffCommand(fs afero.Fs, all *bool) *cobra.Command {
+	var filename, exportFilename string
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show how the cluster configuration differs from a file",
+		Long: `Show how the cluster configuration differs from a file.
+
+This fetches the current cluster configuration and compares it with the
+cluster properties of the given file, either a file written by 'export', or a
+redpanda.yaml whose 'redpanda' section sets cluster properties. Properties are
+reported as:
+
+  added     set in the cluster, but not in the file
+  removed   set in the file, but unknown to the cluster
+  changed   set to a different value in the file than in the cluster, which
+            may be the default value of the property
+
+Secret properties are redacted: as the cluster never returns their value,
+they are only reported if they are set on one side only.
+
+By default, low level tunables only set in the cluster are excluded: use the
+'--all' flag to include them. Use '--export' to write the current cluster
+configuration to a file, which can be version-controlled in place of the
+original one.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			if filename == "" {
+				out.Die("the file to compare the cluster configuration with is required, use --filename")
+			}
+			raw, err := afero.ReadFile(fs, filename)
+			out.MaybeDie(err, "unable to read %q: %v", filename, err)
+
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			client, err := admin.NewClient(fs, cfg)
+			out.MaybeDie(err, "unable to initialize admin client: %v", err)
+
+			schema, err := client.ClusterConfigSchema(cmd.Context())
+			out.MaybeDie(err, "unable to query config schema: %v", err)
+
+			currentConfig, err := client.Config(cmd.Context(), false)
+			out.MaybeDie(err, "unable to query config values: %v", err)
+
+			currentFullConfig, err := client.Config(cmd.Context(), true)
+			out.MaybeDie(err, "unable to query config values: %v", err)
+
+			desired, err := fileClusterConfig(raw, schema)
+			out.MaybeDie(err, "unable to parse %q: %v", filename, err)
+
+			diffs := diffConfig(desired, currentConfig, currentFullConfig, schema, *all)
+			if len(diffs) == 0 {
+				fmt.Printf("The cluster configuration matches %q.\n", filename)
+			} else {
+				tw := out.NewTable("PROPERTY", "STATUS", "FILE", "CLUSTER")
+				for _, d := range diffs {
+					tw.PrintStructFields(d)
+				}
+				tw.Flush()
+			}
+
+			if exportFilename != "" {
+				file, err := os.Create(exportFilename)
+				out.MaybeDie(err, "unable to create file %q: %v", exportFilename, err)
+				err = exportConfig(file, schema, currentFullConfig, *all)
+				out.MaybeDie(err, "failed to write out config %q: %v", exportFilename, err)
+				err = file.Close()
+				out.MaybeDie(err, "error closing file %q: %v", exportFilename, err)
+				fmt.Printf("\nWrote the cluster configuration to file %q.\n", exportFilename)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(
+		&filename,
+		"filename",
+		"f",
+		"",
+		"path to the file to compare with, e.g. './redpanda.yaml'",
+	)
+	cmd.Flags().StringVar(
+		&exportFilename,
+		"export",
+		"",
+		"path to the file to write the current cluster configuration to",
+	)
+	return cmd
+}
+
+// fileClusterConfig returns the cluster properties set in the file: its top
+// level properties, as written by 'export', and the ones set in its
+// 'redpanda' section, as in a redpanda.yaml. The node properties and the other
+// sections of a redpanda.yaml are ignored, but the properties unknown to the
+// cluster, e.g. misspelled or removed ones, are kept to be reported as
+// removed. Deprecated properties are ignored, as by 'export'.
+func fileClusterConfig(raw []byte, schema admin.ConfigSchema) (clusterConfig, error) {
+	var in clusterConfig
+	if err := yaml.Unmarshal(raw, &in); err != nil {
+		return nil, err
+	}
+	var (
+		sections  = yamlTags(reflect.TypeOf(config.Config{}))
+		nodeProps = yamlTags(reflect.TypeOf(config.RedpandaNodeConfig{}))
+		desired   = make(clusterConfig)
+	)
+	add := func(k string, v any, ignored map[string]bool) {
+		meta, inSchema := schema[k]
+		if inSchema && meta.Visibility == "deprecated" || !inSchema && ignored[k] {
+			return
+		}
+		desired[k] = v
+	}
+	for k, v := range in {
+		if k == "redpanda" {
+			if section, ok := v.(map[string]any); ok {
+				for sk, sv := range section {
+					add(sk, sv, nodeProps)
+				}
+			}
+			continue
+		}
+		add(k, v, sections)
+	}
+	// The cluster_id is never imported, see importConfig.
+	delete(desired, "cluster_id")
+	return desired, nil
+}
+
+// yamlTags returns the yaml keys of the fields of the struct type.
+func yamlTags(t reflect.Type) map[string]bool {
+	tags := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		if tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]; tag != "" && tag != "-" {
+			tags[tag] = true
+		}
+	}
+	return tags
+}
+
+// diffConfig returns how the current cluster configuration, the properties
+// set in the cluster and the full configuration with defaults, differs from
+// the desired one, sorted by property.
+func diffConfig(
+	desired clusterConfig,
+	current admin.Config,
+	currentFull admin.Config,
+	schema admin.ConfigSchema,
+	all bool,
+) []propertyDiff {
+	var diffs []propertyDiff
+	for k, v := range desired {
+		fileValue, err := normalizeConfigValue(v)
+		if err != nil {
+			// Let the value be reported as changed.
+			fileValue = fmt.Sprintf("%v", v)
+		}
+		clusterValue, isSet := current[k]
+		if !isSet {
+			clusterValue, isSet = currentFull[k]
+		}
+		if !isSet {
+			diffs = append(diffs, propertyDiff{k, diffRemoved, formatDiffValue(schema, k, fileValue), ""})
+			continue
+		}
+		// The cluster redacts the secrets, so they can't be compared.
+		if schema[k].IsSecret || reflect.DeepEqual(fileValue, clusterValue) {
+			continue
+		}
+		diffs = append(diffs, propertyDiff{k, diffChanged, formatDiffValue(schema, k, fileValue), formatDiffValue(schema, k, clusterValue)})
+	}
+	for k, v := range current {
+		if _, found := desired[k]; found || k == "cluster_id" {
+			continue
+		}
+		if meta, inSchema := schema[k]; !inSchema || meta.Visibility == "deprecated" || (!all && meta.Visibility == "tunable") {
+			continue
+		}
+		diffs = append(diffs, propertyDiff{k, diffAdded, "", formatDiffValue(schema, k, v)})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Property < diffs[j].Property })
+	return diffs
+}
+
+// normalizeConfigValue returns the YAML value as it would be decoded from the
+// JSON of the admin API, e.g. with float64 numbers, so that it's comparable
+// with the values of the cluster.
+func normalizeConfigValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	err = json.Unmarshal(raw, &normalized)
+	return normalized, err
+}
+
+func formatDiffValue(schema admin.ConfigSchema, property string, v any) string {
+	if v == nil {
+		return "null"
+	}
+	if schema[property].IsSecret {
+		return "[redacted]"
+	}
+	if f64, ok := v.(float64); ok && f64 == float64(int64(f64)) {
+		v = int64(f64)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfig(t *testing.T) {
+	schema := admin.ConfigSchema{
+		"cluster_id":                 {Type: "string"},
+		"cloud_storage_secret_key":   {Type: "string", IsSecret: true},
+		"cloud_storage_access_key":   {Type: "string", IsSecret: true},
+		"kafka_qdc_enable":           {Type: "boolean"},
+		"log_segment_size":           {Type: "integer"},
+		"superusers":                 {Type: "array", Items: admin.ConfigPropertyItems{Type: "string"}},
+		"retention_bytes":            {Type: "integer", Nullable: true},
+		"raft_heartbeat_interval_ms": {Type: "integer", Visibility: "tunable"},
+		"old_property":               {Type: "string", Visibility: "deprecated"},
+	}
+	in := `
+redpanda:
+  data_directory: /var/lib/redpanda/data
+  log_segment_size: 134217728
+  superusers:
+    - admin
+  cloud_storage_secret_key: changeme
+  kafka_qdc_enable: false
+  legacy_property: foo
+  old_property: bar
+  node_id: 1
+  seed_servers: []
+rpk:
+  tune_network: true
+cluster_id: foo
+log_segmnet_size: 1024
+`
+	desired, err := fileClusterConfig([]byte(in), schema)
+	require.NoError(t, err)
+	require.Equal(t, clusterConfig{
+		"log_segment_size":         134217728,
+		"superusers":               []any{"admin"},
+		"cloud_storage_secret_key": "changeme",
+		"kafka_qdc_enable":         false,
+		"legacy_property":          "foo",
+		"log_segmnet_size":         1024,
+	}, desired)
+
+	current := admin.Config{
+		"cluster_id":                 "bar",
+		"log_segment_size":           float64(134217728),
+		"superusers":                 []any{"admin", "alice"},
+		"cloud_storage_secret_key":   "[secret]",
+		"cloud_storage_access_key":   "[secret]",
+		"retention_bytes":            nil,
+		"raft_heartbeat_interval_ms": float64(100),
+		"old_property":               "baz",
+	}
+	full := admin.Config{"kafka_qdc_enable": true}
+	for k, v := range current {
+		full[k] = v
+	}
+
+	require.Equal(t, []propertyDiff{
+		{"cloud_storage_access_key", diffAdded, "", "[redacted]"},
+		{"kafka_qdc_enable", diffChanged, "false", "true"},
+		{"legacy_property", diffRemoved, "foo", ""},
+		{"log_segmnet_size", diffRemoved, "1024", ""},
+		{"retention_bytes", diffAdded, "", "null"},
+		{"superusers", diffChanged, "[admin]", "[admin alice]"},
+	}, diffConfig(desired, current, full, schema, false))
+
+	diffs := diffConfig(desired, current, full, schema, true)
+	require.Len(t, diffs, 7)
+	require.Equal(t, propertyDiff{"raft_heartbeat_interval_ms", diffAdded, "", "100"}, diffs[4])
+}
@@ -10,22 +10,35 @@
 package cluster
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
 )
 
+// maxHealthPollInterval bounds the interval between the polls of 'rpk
+// cluster health --wait', which doubles while the cluster isn't healthy.
+const maxHealthPollInterval = 10 * time.Second
+
 func newHealthOverviewCommand(fs afero.Fs) *cobra.Command {
 	var (
-		watch bool
-		exit  bool
+		watch        bool
+		exit         bool
+		wait         bool
+		timeout      time.Duration
+		pollInterval time.Duration
 
 		adminURL       string
 		adminEnableTLS bool
@@ -45,6 +58,13 @@ following conditions are met:
 * all cluster nodes are responding
 * all partitions have leaders
 * the cluster controller is present
+
+With --wait, the command blocks until the cluster is healthy and has no
+under-replicated partitions, as reported by the Kafka API, then prints the
+health overview. It exits with an error if the cluster isn't healthy once
+--timeout elapses. The cluster is polled every --poll-interval, doubling up to
+10s while it isn't healthy, and failed polls are retried as the cluster may
+still be starting. Each poll is printed with --verbose.
 `,
 		Args: cobra.ExactArgs(0),
 		Run: func(cmd *cobra.Command, _ []string) {
@@ -55,6 +75,25 @@ following conditions are met:
 			cl, err := admin.NewClient(fs, cfg)
 			out.MaybeDie(err, "unable to initialize admin client: %v", err)
 
+			if wait {
+				if watch {
+					out.Die("--wait and --watch cannot be used together")
+				}
+				if pollInterval <= 0 {
+					out.Die("invalid --poll-interval %s, must be positive", pollInterval)
+				}
+				adm, err := kafka.NewAdmin(fs, p, cfg)
+				out.MaybeDie(err, "unable to initialize kafka client: %v", err)
+				defer adm.Close()
+
+				ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+				defer cancel()
+				overview, err := waitHealthy(ctx, cl, adm, pollInterval, p.Verbose)
+				out.MaybeDie(err, "cluster not healthy after %s: %v", timeout, err)
+				printHealthOverview(&overview)
+				return
+			}
+
 			var lastOverview admin.ClusterHealthOverview
 			for {
 				ret, err := cl.GetHealthOverview(cmd.Context())
@@ -86,6 +125,9 @@ following conditions are met:
 
 	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Blocks and writes out all cluster health changes")
 	cmd.Flags().BoolVarP(&exit, "exit-when-healthy", "e", false, "When used with watch, exits after cluster is back in healthy state")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Block until the cluster is healthy, failing once --timeout elapses")
+	cmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "How long to wait for the cluster to be healthy with --wait")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", time.Second, "Initial interval between the polls of --wait")
 	return cmd
 }
 
@@ -99,3 +141,110 @@ Leaderless partitions: %v
 `
 	fmt.Printf(overviewFormat, hov.IsHealthy, hov.ControllerID, hov.AllNodes, hov.NodesDown, hov.LeaderlessPartitions)
 }
+
+// waitHealthy polls the cluster until it's healthy or ctx is done, in which
+// case the last reason the cluster wasn't healthy is returned. The interval
+// between polls starts at pollInterval and doubles while the cluster isn't
+// healthy, see nextHealthPollInterval.
+func waitHealthy(
+	ctx context.Context,
+	cl *admin.AdminAPI,
+	adm *kadm.Client,
+	pollInterval time.Duration,
+	verbose bool,
+) (admin.ClusterHealthOverview, error) {
+	var (
+		lastOverview admin.ClusterHealthOverview
+		lastReason   string
+	)
+	interval := pollInterval
+	for poll := 1; ; poll++ {
+		overview, problems, err := checkHealth(ctx, cl, adm)
+		if err == nil && len(problems) == 0 {
+			return overview, nil
+		}
+		// The requests interrupted by the timeout fail with the context
+		// error, the reason of the previous poll is more helpful.
+		if ctx.Err() != nil && lastReason != "" {
+			return lastOverview, errors.New(lastReason)
+		}
+		lastOverview = overview
+		if err != nil {
+			lastReason = err.Error()
+		} else {
+			lastReason = strings.Join(problems, ", ")
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Poll %d: cluster not healthy (%s), polling again in %s\n", poll, lastReason, interval)
+		}
+		select {
+		case <-ctx.Done():
+			return lastOverview, errors.New(lastReason)
+		case <-time.After(interval):
+		}
+		interval = nextHealthPollInterval(interval, pollInterval)
+	}
+}
+
+// checkHealth returns the health overview of the cluster and the reasons it
+// isn't healthy, if any.
+func checkHealth(
+	ctx context.Context, cl *admin.AdminAPI, adm *kadm.Client,
+) (admin.ClusterHealthOverview, []string, error) {
+	overview, err := cl.GetHealthOverview(ctx)
+	if err != nil {
+		return overview, nil, fmt.Errorf("unable to request cluster health: %w", err)
+	}
+	topics, err := adm.ListTopicsWithInternal(ctx)
+	if err != nil {
+		return overview, nil, fmt.Errorf("unable to list the partitions: %w", err)
+	}
+	return overview, healthProblems(overview, topics), nil
+}
+
+// healthProblems returns the reasons the cluster isn't healthy: those of its
+// health overview, and the under-replicated partitions of the topics.
+func healthProblems(overview admin.ClusterHealthOverview, topics kadm.TopicDetails) []string {
+	var problems []string
+	if len(overview.NodesDown) > 0 {
+		problems = append(problems, fmt.Sprintf("nodes down: %v", overview.NodesDown))
+	}
+	if n := len(overview.LeaderlessPartitions); n > 0 {
+		problems = append(problems, fmt.Sprintf("%d leaderless partitions", n))
+	}
+	if overview.ControllerID < 0 {
+		problems = append(problems, "no controller")
+	}
+	if !overview.IsHealthy && len(problems) == 0 {
+		problems = append(problems, "reported unhealthy")
+	}
+	var underReplicated int
+	for _, t := range topics.Sorted() {
+		if t.Err != nil {
+			problems = append(problems, fmt.Sprintf("topic %q: %v", t.Topic, t.Err))
+			continue
+		}
+		for _, p := range t.Partitions.Sorted() {
+			if len(p.ISR) < len(p.Replicas) {
+				underReplicated++
+			}
+		}
+	}
+	if underReplicated > 0 {
+		problems = append(problems, fmt.Sprintf("%d under-replicated partitions", underReplicated))
+	}
+	return problems
+}
+
+// nextHealthPollInterval doubles the interval between polls, up to
+// maxHealthPollInterval unless the initial one is longer.
+func nextHealthPollInterval(interval, initial time.Duration) time.Duration {
+	next := 2 * interval
+	if next > maxHealthPollInterval {
+		next = maxHealthPollInterval
+	}
+	if next < initial {
+		next = initial
+	}
+	return next
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+func TestHealthProblems(t *testing.T) {
+	healthy := admin.ClusterHealthOverview{IsHealthy: true, ControllerID: 1, AllNodes: []int{1, 2, 3}}
+	inSync := kadm.TopicDetails{
+		"foo": {Topic: "foo", Partitions: kadm.PartitionDetails{
+			0: {Topic: "foo", Partition: 0, Replicas: []int32{1, 2, 3}, ISR: []int32{1, 2, 3}},
+		}},
+	}
+	for _, test := range []struct {
+		name     string
+		overview admin.ClusterHealthOverview
+		topics   kadm.TopicDetails
+		exp      []string
+	}{
+		{
+			name:     "healthy",
+			overview: healthy,
+			topics:   inSync,
+		},
+		{
+			name: "nodes down and leaderless partitions",
+			overview: admin.ClusterHealthOverview{
+				ControllerID:         1,
+				AllNodes:             []int{1, 2, 3},
+				NodesDown:            []int{3},
+				LeaderlessPartitions: []string{"kafka/foo/0", "kafka/foo/1"},
+			},
+			topics: inSync,
+			exp:    []string{"nodes down: [3]", "2 leaderless partitions"},
+		},
+		{
+			name:     "no controller",
+			overview: admin.ClusterHealthOverview{ControllerID: -1},
+			exp:      []string{"no controller"},
+		},
+		{
+			name:     "unhealthy without details",
+			overview: admin.ClusterHealthOverview{ControllerID: 1},
+			exp:      []string{"reported unhealthy"},
+		},
+		{
+			name:     "under-replicated partitions",
+			overview: healthy,
+			topics: kadm.TopicDetails{
+				"foo": {Topic: "foo", Partitions: kadm.PartitionDetails{
+					0: {Topic: "foo", Partition: 0, Replicas: []int32{1, 2, 3}, ISR: []int32{1, 2}},
+					1: {Topic: "foo", Partition: 1, Replicas: []int32{1, 2, 3}, ISR: []int32{1, 2, 3}},
+				}},
+				"bar": {Topic: "bar", Partitions: kadm.PartitionDetails{
+					0: {Topic: "bar", Partition: 0, Replicas: []int32{1, 2, 3}, ISR: []int32{3}},
+				}},
+				"baz": {Topic: "baz", Err: kerr.LeaderNotAvailable},
+			},
+			exp: []string{
+				`topic "baz": LEADER_NOT_AVAILABLE: There is no leader for this topic-partition as we are in the middle of a leadership election.`,
+				"2 under-replicated partitions",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.exp, healthProblems(test.overview, test.topics))
+		})
+	}
+}
+
+func TestNextHealthPollInterval(t *testing.T) {
+	interval := 500 * time.Millisecond
+	var intervals []time.Duration
+	for i := 0; i < 7; i++ {
+		interval = nextHealthPollInterval(interval, 500*time.Millisecond)
+		intervals = append(intervals, interval)
+	}
+	require.Equal(t, []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}, intervals)
+
+	// Initial intervals above the maximum are kept.
+	require.Equal(t, time.Minute, nextHealthPollInterval(time.Minute, time.Minute))
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/twmb/types"
+)
+
+// decommissionPollInterval is how often the decommission status is polled
+// with --wait.
+const decommissionPollInterval = 2 * time.Second
+
+func newDecommissionCommand(fs afero.Fs) *cobra.Command {
+	var (
+		wait         bool
+		stuckTimeout time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "decommission <broker-id>",
+		Short: "Decommission a broker, optionally waiting until it's drained",
+		Long: `Decommission a broker, optionally waiting until it's drained.
+
+This command issues the decommission of the broker with the specified ID: its
+partition replicas are moved to the other brokers, after which it's removed
+from the cluster. Decommissioning a broker that is already being decommissioned
+doesn't issue the decommission again, and a broker that was decommissioned
+already is reported as such.
+
+With --wait, the command tracks the replicas moving off the broker, printing
+the number of replicas left to move until the broker is fully drained. If the
+moves make no progress for --stuck-timeout, the partitions still moving are
+printed and the command fails: the decommission keeps going in the background,
+check it with 'rpk redpanda admin brokers decommission-status'.
+
+On v22.x clusters, use 'rpk redpanda admin brokers decommission' instead, which
+checks that the broker is not in maintenance mode first.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			broker, err := strconv.Atoi(args[0])
+			out.MaybeDie(err, "could not parse broker id: %s: %v", args[0], err)
+			if broker < 0 {
+				out.Die("invalid broker id: %d", broker)
+			}
+			if stuckTimeout <= 0 {
+				out.Die("--stuck-timeout must be positive")
+			}
+
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			client, err := admin.NewClient(fs, cfg)
+			out.MaybeDie(err, "unable to initialize admin client: %v", err)
+
+			brokers, err := client.Brokers(cmd.Context())
+			out.MaybeDie(err, "unable to get broker list: %v", err)
+			b, found := findBroker(brokers, broker)
+			switch {
+			case found && b.MembershipStatus == admin.MembershipStatusDraining:
+				fmt.Printf("Broker %d is already being decommissioned\n", broker)
+			case found:
+				err = client.DecommissionBroker(cmd.Context(), broker)
+				out.MaybeDie(err, "unable to decommission broker %d: %v", broker, err)
+				fmt.Printf("Successfully issued the decommission of broker %d\n", broker)
+			default:
+				// A decommissioned broker is no longer listed, the leader
+				// still knows of its decommission.
+				dsr, err := client.DecommissionBrokerStatus(cmd.Context(), broker)
+				if err == nil && dsr.Finished {
+					out.Exit("Broker %d is already decommissioned", broker)
+				}
+				out.Die("unable to find broker %d in the cluster", broker)
+			}
+
+			if !wait {
+				return
+			}
+
+			fmt.Println("Waiting for the broker to drain...")
+			status := func(ctx context.Context) (admin.DecommissionStatusResponse, error) {
+				return client.DecommissionBrokerStatus(ctx, broker)
+			}
+			err = waitDecommission(cmd.Context(), os.Stdout, status, decommissionPollInterval, stuckTimeout)
+			out.MaybeDie(err, "decommission of broker %d not finished: %v", broker, err)
+			fmt.Printf("Broker %d is fully drained and decommissioned\n", broker)
+		},
+	}
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait until the broker is fully drained")
+	cmd.Flags().DurationVar(&stuckTimeout, "stuck-timeout", 10*time.Minute, "With --wait, how long the moves can make no progress before failing")
+	return cmd
+}
+
+func findBroker(brokers []admin.Broker, id int) (admin.Broker, bool) {
+	for _, b := range brokers {
+		if b.NodeID == id {
+			return b, true
+		}
+	}
+	return admin.Broker{}, false
+}
+
+// errDecommissionStuck is returned by waitDecommission when the replicas of
+// the broker made no progress in the stuck timeout.
+var errDecommissionStuck = errors.New("replica moves made no progress")
+
+// waitDecommission polls the decommission status until it's finished,
+// printing the replicas left to move to w whenever they change. It fails
+// with errDecommissionStuck, after printing the partitions still moving, if
+// neither the replicas left nor the bytes moved changed in stuckTimeout.
+// Failing to get the status is retried until then, e.g. while the
+// controller leadership moves.
+func waitDecommission(
+	ctx context.Context,
+	w io.Writer,
+	status func(context.Context) (admin.DecommissionStatusResponse, error),
+	interval, stuckTimeout time.Duration,
+) error {
+	var (
+		lastProgress = time.Now()
+		lastLeft     = -1
+		lastMoved    = -1
+		lastErr      error
+		dsr          admin.DecommissionStatusResponse
+	)
+	for {
+		current, err := status(ctx)
+		if err == nil {
+			dsr, lastErr = current, nil
+			if dsr.Finished {
+				if dsr.ReplicasLeft > 0 {
+					return fmt.Errorf("decommission finished but %d replicas are left", dsr.ReplicasLeft)
+				}
+				fmt.Fprintln(w, "Replicas left to move: 0")
+				return nil
+			}
+			moved := 0
+			for _, p := range dsr.Partitions {
+				moved += p.BytesMoved
+			}
+			if dsr.ReplicasLeft != lastLeft || moved != lastMoved {
+				lastProgress = time.Now()
+				if dsr.ReplicasLeft != lastLeft {
+					fmt.Fprintf(w, "Replicas left to move: %d\n", dsr.ReplicasLeft)
+				}
+				lastLeft, lastMoved = dsr.ReplicasLeft, moved
+			}
+		} else {
+			lastErr = err
+		}
+
+		if time.Since(lastProgress) >= stuckTimeout {
+			if lastErr != nil {
+				return fmt.Errorf("unable to get the decommission status in %v: %w", stuckTimeout, lastErr)
+			}
+			printStuckMoves(w, dsr.Partitions)
+			return fmt.Errorf("%w in %v, %d replicas left", errDecommissionStuck, stuckTimeout, dsr.ReplicasLeft)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printStuckMoves prints the partitions still moving, as 'rpk redpanda admin
+// brokers decommission-status' does.
+func printStuckMoves(w io.Writer, partitions []admin.DecommissionPartitions) {
+	if len(partitions) == 0 {
+		return
+	}
+	types.Sort(partitions)
+	fmt.Fprintln(w, "Partitions still moving:")
+	tw := out.NewTableTo(w, "Namespace-Topic", "Partition", "Moving-to", "Completion-%", "Bytes-remaining")
+	defer tw.Flush()
+	for _, p := range partitions {
+		var completion int
+		if p.PartitionSize > 0 {
+			completion = p.BytesMoved * 100 / p.PartitionSize
+		}
+		tw.Print(p.Ns+"/"+p.Topic, p.Partition, p.MovingTo.NodeID, completion, p.BytesLeftToMove)
+	}
+}
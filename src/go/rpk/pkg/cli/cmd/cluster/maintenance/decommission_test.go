@@ -0,0 +1,93 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/stretchr/testify/require"
+)
+
+// statusSequence returns the responses in turn, then the last one forever.
+func statusSequence(responses ...admin.DecommissionStatusResponse) func(context.Context) (admin.DecommissionStatusResponse, error) {
+	var i int
+	return func(context.Context) (admin.DecommissionStatusResponse, error) {
+		r := responses[i]
+		if i < len(responses)-1 {
+			i++
+		}
+		return r, nil
+	}
+}
+
+func TestWaitDecommission(t *testing.T) {
+	moving := func(moved int) []admin.DecommissionPartitions {
+		return []admin.DecommissionPartitions{{
+			Ns: "kafka", Topic: "foo", Partition: 1,
+			MovingTo:      admin.DecommissionMovingTo{NodeID: 2},
+			BytesMoved:    moved,
+			PartitionSize: 100, BytesLeftToMove: 100 - moved,
+		}}
+	}
+
+	t.Run("drained", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := waitDecommission(context.Background(), &buf, statusSequence(
+			admin.DecommissionStatusResponse{ReplicasLeft: 3},
+			admin.DecommissionStatusResponse{ReplicasLeft: 3},
+			admin.DecommissionStatusResponse{ReplicasLeft: 1, Partitions: moving(10)},
+			admin.DecommissionStatusResponse{ReplicasLeft: 1, Partitions: moving(50)},
+			admin.DecommissionStatusResponse{Finished: true},
+		), time.Millisecond, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, "Replicas left to move: 3\nReplicas left to move: 1\nReplicas left to move: 0\n", buf.String())
+	})
+
+	t.Run("stuck", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := waitDecommission(context.Background(), &buf, statusSequence(
+			admin.DecommissionStatusResponse{ReplicasLeft: 1, Partitions: moving(40)},
+		), time.Millisecond, 20*time.Millisecond)
+		require.ErrorIs(t, err, errDecommissionStuck)
+		require.Contains(t, err.Error(), "1 replicas left")
+		require.Contains(t, buf.String(), "Partitions still moving:")
+		require.Contains(t, buf.String(), "kafka/foo")
+	})
+
+	t.Run("replicas left once finished", func(t *testing.T) {
+		err := waitDecommission(context.Background(), &bytes.Buffer{}, statusSequence(
+			admin.DecommissionStatusResponse{Finished: true, ReplicasLeft: 2},
+		), time.Millisecond, time.Minute)
+		require.EqualError(t, err, "decommission finished but 2 replicas are left")
+	})
+
+	t.Run("status unavailable", func(t *testing.T) {
+		errUnavailable := errors.New("no leader")
+		err := waitDecommission(context.Background(), &bytes.Buffer{},
+			func(context.Context) (admin.DecommissionStatusResponse, error) {
+				return admin.DecommissionStatusResponse{}, errUnavailable
+			}, time.Millisecond, 20*time.Millisecond)
+		require.ErrorIs(t, err, errUnavailable)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := waitDecommission(ctx, &bytes.Buffer{}, statusSequence(
+			admin.DecommissionStatusResponse{ReplicasLeft: 1},
+		), time.Hour, time.Hour)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -47,6 +47,9 @@ and requests are directed to other nodes minimizing disruption to client
 workloads when the node is shutdown.
 
 Currently leadership is not transferred for partitions with one replica.
+
+To remove a node from the cluster rather than restarting it, use the
+'decommission' subcommand, which moves its partition replicas off the node.
 `,
 	}
 
@@ -66,7 +69,8 @@ Currently leadership is not transferred for partitions with one replica.
 	cmd.AddCommand(
 		newEnableCommand(fs),
 		newDisableCommand(fs),
-		newStatusCommand(fs))
+		newStatusCommand(fs),
+		newDecommissionCommand(fs))
 
 	return cmd
 }
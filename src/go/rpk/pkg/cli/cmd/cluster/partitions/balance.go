@@ -0,0 +1,470 @@
+package partitions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// balancePollInterval is the interval between the polls of the ongoing
+// reconfigurations while executing a balance plan.
+const balancePollInterval = 2 * time.Second
+
+func newBalanceCommand(fs afero.Fs) *cobra.Command {
+	var (
+		generate           bool
+		execute            bool
+		topics             []string
+		maxConcurrentMoves int
+		noConfirm          bool
+	)
+	cmd := &cobra.Command{
+		Use:   "balance",
+		Short: "Generate and execute a plan spreading the partitions evenly across the brokers",
+		Long: `Generate and execute a plan spreading the partitions evenly across the brokers.
+
+When brokers are added to a cluster, the existing partitions stay on the
+brokers they were created on. This command plans the partition movements
+spreading the replicas evenly across the active and alive brokers, moving as
+few replicas as possible, each from the broker with the most replicas to the
+one with the fewest. Among the partitions that can be moved, those led by
+brokers leading more than their share of partitions are moved first, spreading
+the leaders as well.
+
+With --generate, the plan is printed along with the replicas and leaders each
+broker would hold, without moving anything:
+
+    rpk cluster partitions balance --generate
+
+With --execute, the plan is generated, printed, and once confirmed submitted
+through the admin API. At most --max-concurrent-moves partitions are moved at
+once, the next ones being submitted as the ongoing reconfigurations complete,
+and the progress is printed until all the movements are done:
+
+    rpk cluster partitions balance --execute --max-concurrent-moves 5
+
+Use --topics to only balance the partitions of some topics, the replicas of the
+other topics are then neither moved nor counted.
+
+Partitions whose replicas changed since the plan was generated are skipped.
+Use 'rpk cluster partitions movement-cancel' to cancel the ongoing movements.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			if generate == execute {
+				out.Die("exactly one of --generate or --execute must be set")
+			}
+			if maxConcurrentMoves <= 0 {
+				out.Die("invalid --max-concurrent-moves %d, must be positive", maxConcurrentMoves)
+			}
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			cl, err := admin.NewClient(fs, cfg)
+			out.MaybeDie(err, "unable to initialize admin client: %v", err)
+
+			adm, err := kafka.NewAdmin(fs, p, cfg)
+			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
+			defer adm.Close()
+
+			brokers, err := cl.Brokers(cmd.Context())
+			out.MaybeDie(err, "unable to list the brokers: %v", err)
+			listed, err := adm.ListTopics(cmd.Context(), topics...)
+			out.MaybeDie(err, "unable to list the topics: %v", err)
+			partitions, err := balancePartitions(listed)
+			out.MaybeDieErr(err)
+
+			plan := planBalance(partitions, balanceBrokers(brokers))
+			if len(plan.Moves) == 0 {
+				fmt.Println("The partitions are already evenly spread across the brokers.")
+				return
+			}
+			printBalancePlan(plan)
+			if generate {
+				return
+			}
+
+			if !noConfirm {
+				confirmed, err := out.Confirm("Confirm the movement of %d partitions?", len(plan.Moves))
+				out.MaybeDie(err, "unable to confirm the partition movements: %v", err)
+				if !confirmed {
+					out.Exit("Command execution canceled.")
+				}
+			}
+			fmt.Println()
+			err = executeBalancePlan(cmd.Context(), cl, plan, brokers, maxConcurrentMoves)
+			out.MaybeDie(err, "unable to execute the balance plan: %v", err)
+		},
+	}
+	cmd.Flags().BoolVar(&generate, "generate", false, "Print the balance plan without moving any partition")
+	cmd.Flags().BoolVar(&execute, "execute", false, "Generate the balance plan and move the partitions")
+	cmd.Flags().StringSliceVar(&topics, "topics", nil, "Only balance the partitions of these topics (repeatable or comma-separated)")
+	cmd.Flags().IntVar(&maxConcurrentMoves, "max-concurrent-moves", 10, "Maximum number of partitions moved at once with --execute")
+	cmd.Flags().BoolVar(&noConfirm, "no-confirm", false, "Disable confirmation prompt")
+	return cmd
+}
+
+// balancePartition is a partition of the kafka namespace, as balanced.
+type balancePartition struct {
+	Topic     string
+	Partition int
+	Leader    int
+	// Replicas are the IDs of the brokers holding the replicas, in order.
+	Replicas []int
+}
+
+// balanceMove is the movement of the replicas of a partition from Current
+// to Proposed.
+type balanceMove struct {
+	Topic     string
+	Partition int
+	Current   []int
+	Proposed  []int
+}
+
+// brokerLoad is the number of replicas and leaders of a broker.
+type brokerLoad struct {
+	Replicas int
+	Leaders  int
+}
+
+// balancePlan is a plan generated by planBalance, along with the load of the
+// brokers before and after it's executed. The leaders after the plan are
+// estimated, the moved leaders being assumed to follow their replica.
+type balancePlan struct {
+	Moves  []balanceMove
+	Before map[int]brokerLoad
+	After  map[int]brokerLoad
+}
+
+// balancePartitions returns the partitions of the listed topics, sorted by
+// topic and partition.
+func balancePartitions(listed kadm.TopicDetails) ([]balancePartition, error) {
+	var partitions []balancePartition
+	for _, t := range listed.Sorted() {
+		if t.Err != nil {
+			return nil, fmt.Errorf("unable to describe topic %q: %v", t.Topic, t.Err)
+		}
+		for _, p := range t.Partitions.Sorted() {
+			replicas := make([]int, 0, len(p.Replicas))
+			for _, r := range p.Replicas {
+				replicas = append(replicas, int(r))
+			}
+			partitions = append(partitions, balancePartition{
+				Topic:     t.Topic,
+				Partition: int(p.Partition),
+				Leader:    int(p.Leader),
+				Replicas:  replicas,
+			})
+		}
+	}
+	return partitions, nil
+}
+
+// balanceBrokers returns the IDs of the brokers partitions can be moved to:
+// the active ones that are alive and not in maintenance mode.
+func balanceBrokers(brokers []admin.Broker) []int {
+	var ids []int
+	for _, b := range brokers {
+		if b.MembershipStatus != admin.MembershipStatusActive ||
+			b.IsAlive != nil && !*b.IsAlive ||
+			b.Maintenance != nil && b.Maintenance.Draining {
+			continue
+		}
+		ids = append(ids, b.NodeID)
+	}
+	return ids
+}
+
+// planBalance plans the replica movements spreading the replicas of the
+// partitions evenly across the brokers, i.e. until the numbers of replicas
+// of any two brokers differ by at most one. Each movement moves a replica
+// from the most loaded broker to the least loaded one not already holding a
+// replica of the partition, keeping its position in the replica list. The
+// partitions led by the source broker are moved first if it leads more than
+// its share of partitions, the others otherwise. The replicas on other
+// brokers, e.g. draining ones, are counted but never moved to.
+func planBalance(partitions []balancePartition, brokers []int) *balancePlan {
+	brokers = append([]int{}, brokers...)
+	sort.Ints(brokers)
+	plan := &balancePlan{Before: brokerLoads(partitions, brokers)}
+	if len(brokers) == 0 {
+		plan.After = plan.Before
+		return plan
+	}
+	proposed := make([]balancePartition, len(partitions))
+	for i, p := range partitions {
+		p.Replicas = append([]int{}, p.Replicas...)
+		proposed[i] = p
+	}
+	loads := brokerLoads(proposed, brokers)
+	leaderShare := (len(partitions) + len(brokers) - 1) / len(brokers)
+
+	// Each movement strictly reduces the sum of the squared replica counts,
+	// the loop ends once no movement does.
+	for {
+		byLoad := append([]int{}, brokers...)
+		sort.SliceStable(byLoad, func(i, j int) bool {
+			return loads[byLoad[i]].Replicas > loads[byLoad[j]].Replicas
+		})
+		moved := false
+		for i := 0; i < len(byLoad) && !moved; i++ {
+			src := byLoad[i]
+			for j := len(byLoad) - 1; j > i && !moved; j-- {
+				dst := byLoad[j]
+				if loads[src].Replicas-loads[dst].Replicas <= 1 {
+					break
+				}
+				idx := pickBalanceCandidate(proposed, src, dst, loads[src].Leaders > leaderShare)
+				if idx < 0 {
+					continue
+				}
+				p := &proposed[idx]
+				for k, r := range p.Replicas {
+					if r == src {
+						p.Replicas[k] = dst
+					}
+				}
+				srcLoad, dstLoad := loads[src], loads[dst]
+				srcLoad.Replicas--
+				dstLoad.Replicas++
+				if p.Leader == src {
+					p.Leader = dst
+					srcLoad.Leaders--
+					dstLoad.Leaders++
+				}
+				loads[src], loads[dst] = srcLoad, dstLoad
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+
+	for i, p := range partitions {
+		if !sameReplicas(p.Replicas, proposed[i].Replicas) {
+			plan.Moves = append(plan.Moves, balanceMove{
+				Topic:     p.Topic,
+				Partition: p.Partition,
+				Current:   p.Replicas,
+				Proposed:  proposed[i].Replicas,
+			})
+		}
+	}
+	plan.After = loads
+	return plan
+}
+
+// pickBalanceCandidate returns the index of the first partition with a
+// replica on src and none on dst, preferring those led by src if preferLed,
+// or -1 if there is none.
+func pickBalanceCandidate(partitions []balancePartition, src, dst int, preferLed bool) int {
+	fallback := -1
+	for i, p := range partitions {
+		if !containsBroker(p.Replicas, src) || containsBroker(p.Replicas, dst) {
+			continue
+		}
+		if (p.Leader == src) == preferLed {
+			return i
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	return fallback
+}
+
+// brokerLoads returns the load of each broker, including those holding
+// replicas without being in brokers.
+func brokerLoads(partitions []balancePartition, brokers []int) map[int]brokerLoad {
+	loads := make(map[int]brokerLoad, len(brokers))
+	for _, b := range brokers {
+		loads[b] = brokerLoad{}
+	}
+	for _, p := range partitions {
+		for _, r := range p.Replicas {
+			load := loads[r]
+			load.Replicas++
+			if r == p.Leader {
+				load.Leaders++
+			}
+			loads[r] = load
+		}
+	}
+	return loads
+}
+
+func containsBroker(replicas []int, broker int) bool {
+	for _, r := range replicas {
+		if r == broker {
+			return true
+		}
+	}
+	return false
+}
+
+// sameReplicas returns whether the two replica lists hold the same brokers,
+// regardless of their order.
+func sameReplicas(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, r := range a {
+		if !containsBroker(b, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func printBalancePlan(plan *balancePlan) {
+	out.Section("PROPOSED MOVES")
+	moves := out.NewTable("TOPIC", "PARTITION", "CURRENT-REPLICAS", "PROPOSED-REPLICAS")
+	for _, m := range plan.Moves {
+		moves.Print(m.Topic, m.Partition, m.Current, m.Proposed)
+	}
+	moves.Flush()
+	fmt.Println()
+
+	out.Section("BROKERS")
+	brokers := out.NewTable("BROKER", "REPLICAS", "LEADERS")
+	ids := make([]int, 0, len(plan.Before))
+	for id := range plan.Before {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		before, after := plan.Before[id], plan.After[id]
+		brokers.Print(id,
+			fmt.Sprintf("%d -> %d", before.Replicas, after.Replicas),
+			fmt.Sprintf("%d -> %d", before.Leaders, after.Leaders))
+	}
+	brokers.Flush()
+}
+
+// proposedReplicas returns the replicas to submit for the move: the shard of
+// the replicas staying on their broker is kept, the moved ones are spread
+// across the shards of their new broker by partition.
+func proposedReplicas(move balanceMove, current []admin.Replica, cores map[int]int) []admin.Replica {
+	replicas := make([]admin.Replica, 0, len(move.Proposed))
+	for _, node := range move.Proposed {
+		replica := admin.Replica{NodeID: node}
+		kept := false
+		for _, c := range current {
+			if c.NodeID == node {
+				replica.Core, kept = c.Core, true
+			}
+		}
+		if !kept && cores[node] > 0 {
+			replica.Core = move.Partition % cores[node]
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas
+}
+
+// reconfigurationKey returns the topic and partition of the kafka namespace
+// partition being reconfigured.
+func reconfigurationKey(r admin.Reconfiguration) (string, bool) {
+	ns, _ := r["ns"].(string)
+	topic, _ := r["topic"].(string)
+	partition, ok := r["partition"].(float64)
+	if !ok || ns != "kafka" {
+		return "", false
+	}
+	return fmt.Sprintf("%s/%d", topic, int(partition)), true
+}
+
+// executeBalancePlan submits the moves of the plan, at most maxMoves at once,
+// submitting the next ones as the reconfigurations complete. A move is
+// complete once its partition isn't reconfigured anymore.
+func executeBalancePlan(
+	ctx context.Context,
+	cl *admin.AdminAPI,
+	plan *balancePlan,
+	brokers []admin.Broker,
+	maxMoves int,
+) error {
+	cores := make(map[int]int, len(brokers))
+	for _, b := range brokers {
+		cores[b.NodeID] = b.NumCores
+	}
+	var (
+		pending  = plan.Moves
+		ongoing  = map[string]bool{}
+		done     int
+		skipped  []string
+		lastLine string
+	)
+	for len(pending) > 0 || len(ongoing) > 0 {
+		for len(pending) > 0 && len(ongoing) < maxMoves {
+			move := pending[0]
+			pending = pending[1:]
+			key := fmt.Sprintf("%s/%d", move.Topic, move.Partition)
+			current, err := cl.GetPartition(ctx, "kafka", move.Topic, move.Partition)
+			if err != nil {
+				return fmt.Errorf("unable to describe partition %s: %v", key, err)
+			}
+			nodes := make([]int, 0, len(current.Replicas))
+			for _, r := range current.Replicas {
+				nodes = append(nodes, r.NodeID)
+			}
+			if !sameReplicas(nodes, move.Current) {
+				skipped = append(skipped, fmt.Sprintf("%s (replicas changed to %v)", key, nodes))
+				continue
+			}
+			replicas := proposedReplicas(move, current.Replicas, cores)
+			if err := cl.UpdatePartitionReplicas(ctx, "kafka", move.Topic, move.Partition, replicas); err != nil {
+				return fmt.Errorf("unable to move partition %s to %v: %v", key, move.Proposed, err)
+			}
+			ongoing[key] = true
+		}
+		if len(ongoing) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(balancePollInterval):
+		}
+		reconfigurations, err := cl.Reconfigurations(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to request the ongoing reconfigurations: %v", err)
+		}
+		reconfiguring := map[string]bool{}
+		for _, r := range reconfigurations {
+			if key, ok := reconfigurationKey(r); ok {
+				reconfiguring[key] = true
+			}
+		}
+		for key := range ongoing {
+			if !reconfiguring[key] {
+				delete(ongoing, key)
+				done++
+			}
+		}
+		line := fmt.Sprintf("Moved %d/%d partitions, %d in progress", done, len(plan.Moves)-len(skipped), len(ongoing))
+		if line != lastLine {
+			fmt.Println(line)
+			lastLine = line
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Skipped %d partitions whose replicas changed since the plan was generated: %s\n",
+			len(skipped), strings.Join(skipped, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package partitions
+
+import (
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBalance(t *testing.T) {
+	tests := []struct {
+		name       string
+		partitions []balancePartition
+		brokers    []int
+		expMoves   []balanceMove
+		expAfter   map[int]brokerLoad
+	}{
+		{
+			name: "spreads single replica partitions to new brokers",
+			partitions: []balancePartition{
+				{Topic: "foo", Partition: 0, Leader: 1, Replicas: []int{1}},
+				{Topic: "foo", Partition: 1, Leader: 1, Replicas: []int{1}},
+				{Topic: "foo", Partition: 2, Leader: 1, Replicas: []int{1}},
+			},
+			brokers: []int{3, 2, 1},
+			expMoves: []balanceMove{
+				{Topic: "foo", Partition: 0, Current: []int{1}, Proposed: []int{3}},
+				{Topic: "foo", Partition: 1, Current: []int{1}, Proposed: []int{2}},
+			},
+			expAfter: map[int]brokerLoad{
+				1: {Replicas: 1, Leaders: 1},
+				2: {Replicas: 1, Leaders: 1},
+				3: {Replicas: 1, Leaders: 1},
+			},
+		},
+		{
+			name: "moves the replicas of the most loaded brokers to an added one",
+			partitions: []balancePartition{
+				{Topic: "bar", Partition: 0, Leader: 1, Replicas: []int{1, 2, 3}},
+				{Topic: "bar", Partition: 1, Leader: 2, Replicas: []int{2, 3, 1}},
+				{Topic: "bar", Partition: 2, Leader: 3, Replicas: []int{3, 1, 2}},
+				{Topic: "bar", Partition: 3, Leader: 1, Replicas: []int{1, 2, 3}},
+			},
+			brokers: []int{1, 2, 3, 4},
+			expMoves: []balanceMove{
+				{Topic: "bar", Partition: 0, Current: []int{1, 2, 3}, Proposed: []int{4, 2, 3}},
+				{Topic: "bar", Partition: 1, Current: []int{2, 3, 1}, Proposed: []int{2, 4, 1}},
+				{Topic: "bar", Partition: 2, Current: []int{3, 1, 2}, Proposed: []int{3, 1, 4}},
+			},
+			// Only broker 1 leads more than its share, the leader moved to
+			// broker 4 is one of its partitions.
+			expAfter: map[int]brokerLoad{
+				1: {Replicas: 3, Leaders: 1},
+				2: {Replicas: 3, Leaders: 1},
+				3: {Replicas: 3, Leaders: 1},
+				4: {Replicas: 3, Leaders: 1},
+			},
+		},
+		{
+			name: "leaves balanced partitions",
+			partitions: []balancePartition{
+				{Topic: "foo", Partition: 0, Leader: 1, Replicas: []int{1, 2}},
+				{Topic: "foo", Partition: 1, Leader: 2, Replicas: []int{2, 3}},
+				{Topic: "foo", Partition: 2, Leader: 3, Replicas: []int{3, 1}},
+			},
+			brokers: []int{1, 2, 3},
+			expAfter: map[int]brokerLoad{
+				1: {Replicas: 2, Leaders: 1},
+				2: {Replicas: 2, Leaders: 1},
+				3: {Replicas: 2, Leaders: 1},
+			},
+		},
+		{
+			name: "can't move partitions replicated on all the brokers",
+			partitions: []balancePartition{
+				{Topic: "foo", Partition: 0, Leader: 1, Replicas: []int{1, 2}},
+				{Topic: "foo", Partition: 1, Leader: 1, Replicas: []int{1, 2}},
+			},
+			brokers: []int{1, 2},
+			expAfter: map[int]brokerLoad{
+				1: {Replicas: 2, Leaders: 2},
+				2: {Replicas: 2, Leaders: 0},
+			},
+		},
+		{
+			name: "counts the replicas of other brokers without moving any there",
+			partitions: []balancePartition{
+				{Topic: "foo", Partition: 0, Leader: 5, Replicas: []int{5}},
+				{Topic: "foo", Partition: 1, Leader: 5, Replicas: []int{5}},
+				{Topic: "foo", Partition: 2, Leader: 1, Replicas: []int{1}},
+				{Topic: "foo", Partition: 3, Leader: 1, Replicas: []int{1}},
+			},
+			brokers: []int{1, 2},
+			expMoves: []balanceMove{
+				{Topic: "foo", Partition: 2, Current: []int{1}, Proposed: []int{2}},
+			},
+			expAfter: map[int]brokerLoad{
+				1: {Replicas: 1, Leaders: 1},
+				2: {Replicas: 1, Leaders: 1},
+				5: {Replicas: 2, Leaders: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planBalance(tt.partitions, tt.brokers)
+			require.Equal(t, tt.expMoves, plan.Moves)
+			require.Equal(t, tt.expAfter, plan.After)
+		})
+	}
+}
+
+func TestBalanceBrokers(t *testing.T) {
+	alive, dead := true, false
+	brokers := []admin.Broker{
+		{NodeID: 1, MembershipStatus: admin.MembershipStatusActive, IsAlive: &alive},
+		{NodeID: 2, MembershipStatus: admin.MembershipStatusDraining, IsAlive: &alive},
+		{NodeID: 3, MembershipStatus: admin.MembershipStatusActive, IsAlive: &dead},
+		{NodeID: 4, MembershipStatus: admin.MembershipStatusActive, Maintenance: &admin.MaintenanceStatus{Draining: true}},
+		{NodeID: 5, MembershipStatus: admin.MembershipStatusActive},
+	}
+	require.Equal(t, []int{1, 5}, balanceBrokers(brokers))
+}
+
+func TestProposedReplicas(t *testing.T) {
+	move := balanceMove{Topic: "foo", Partition: 5, Current: []int{1, 2}, Proposed: []int{3, 2}}
+	current := []admin.Replica{{NodeID: 1, Core: 1}, {NodeID: 2, Core: 3}}
+	replicas := proposedReplicas(move, current, map[int]int{1: 4, 2: 4, 3: 2})
+	require.Equal(t, []admin.Replica{{NodeID: 3, Core: 1}, {NodeID: 2, Core: 3}}, replicas)
+}
+
+func TestReconfigurationKey(t *testing.T) {
+	key, ok := reconfigurationKey(admin.Reconfiguration{"ns": "kafka", "topic": "foo", "partition": float64(3)})
+	require.True(t, ok)
+	require.Equal(t, "foo/3", key)
+
+	_, ok = reconfigurationKey(admin.Reconfiguration{"ns": "redpanda", "topic": "controller", "partition": float64(0)})
+	require.False(t, ok)
+}
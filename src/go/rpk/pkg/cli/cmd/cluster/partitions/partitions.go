@@ -30,6 +30,7 @@ func NewPartitionsCommand(fs afero.Fs) *cobra.Command {
 	)
 
 	cmd.AddCommand(
+		newBalanceCommand(fs),
 		newBalancerStatusCommand(fs),
 		newMovementCancelCommand(fs),
 	)
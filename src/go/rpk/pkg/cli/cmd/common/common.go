@@ -11,8 +11,11 @@ package common
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -143,3 +146,15 @@ func AddAdminAPITLSFlags(
 
 	return command
 }
+
+// SetOutputFormat validates the --format of the commands printing either text
+// or JSON. With JSON, the logs are written to stderr to keep stdout for the
+// JSON document only.
+func SetOutputFormat(format string) {
+	if format != "text" && format != "json" {
+		out.Die("unsupported format %q, use either text or json", format)
+	}
+	if format == "json" {
+		log.SetOutput(os.Stderr)
+	}
+}
@@ -293,6 +293,9 @@ The following are the data sources that are bundled in the compressed file:
 
  - Disk usage: The disk usage for the data directory, as output by 'du'.
 
+ - Block devices: The block device holding the data directory, its class and
+   the model, vendor and serial of its drives.
+
  - redpanda logs: The redpanda logs written to journald. If --logs-since or
    --logs-until are passed, then only the logs within the resulting time frame
    will be included.
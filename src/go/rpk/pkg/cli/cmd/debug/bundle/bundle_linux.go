@@ -41,6 +41,7 @@ import (
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/system"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/system/syslog"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/twmb/franz-go/pkg/kadm"
@@ -132,6 +133,7 @@ func executeBundle(ctx context.Context, bp bundleParams) error {
 		savePublicMetrics(ctx, ps, bp.admin),
 		saveDNSData(ctx, ps),
 		saveDiskUsage(ctx, ps, bp.cfg),
+		saveBlockDevices(ctx, ps, bp.cfg),
 		saveLogs(ctx, ps, bp.logsSince, bp.logsUntil, bp.logsLimitBytes),
 		saveSocketData(ctx, ps),
 		saveTopOutput(ctx, ps),
@@ -654,6 +656,113 @@ func saveDiskUsage(ctx context.Context, ps *stepParams, conf *config.Config) ste
 	}
 }
 
+// blockDeviceInfo describes the block device holding the data directory.
+type blockDeviceInfo struct {
+	Directory  string `json:"directory"`
+	Syspath    string `json:"syspath,omitempty"`
+	Devnode    string `json:"devnode,omitempty"`
+	Class      string `json:"class,omitempty"`
+	Rotational bool   `json:"rotational"`
+	Model      string `json:"model,omitempty"`
+	Vendor     string `json:"vendor,omitempty"`
+	Serial     string `json:"serial,omitempty"`
+	Discard    bool   `json:"discard"`
+	// DiscardGranularity is in bytes, 0 if discard isn't supported.
+	DiscardGranularity uint64 `json:"discard_granularity"`
+	// IdentifiedOnly is set if the device was identified without sysfs,
+	// e.g. from /proc/partitions, its attributes are then not available.
+	IdentifiedOnly bool `json:"identified_only,omitempty"`
+	// Multipath is set if the device is a dm-mpath device.
+	Multipath *blockMultipathInfo `json:"multipath,omitempty"`
+	// Cache is set if the device is a bcache device or an LVM cache volume.
+	Cache *blockCacheInfo `json:"cache,omitempty"`
+	// Devices are the snapshots of the device and of its physical devices,
+	// each with the errors of the fields which couldn't be read.
+	Devices []disk.DeviceInfo `json:"devices,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// blockMultipathInfo describes the paths of a multipath device.
+type blockMultipathInfo struct {
+	Name        string   `json:"name"`
+	UUID        string   `json:"uuid"`
+	Paths       []string `json:"paths"`
+	FailedPaths []string `json:"failed_paths,omitempty"`
+}
+
+// blockCacheInfo describes the caching and backing devices of a cache
+// device.
+type blockCacheInfo struct {
+	Kind    string   `json:"kind"`
+	Mode    string   `json:"mode,omitempty"`
+	State   string   `json:"state,omitempty"`
+	Backing []string `json:"backing"`
+	Cache   []string `json:"cache"`
+}
+
+// Saves the block device holding redpanda's data directory, the identity of
+// its drives, whether they support discard, and the snapshot of each of its
+// devices. Failures to read them are saved along with what was read.
+func saveBlockDevices(ctx context.Context, ps *stepParams, conf *config.Config) step {
+	return func() error {
+		info := blockDeviceInfo{Directory: conf.Redpanda.Directory}
+		resolver := disk.NewDeviceResolver(ps.fs, disk.SysfsRootFromEnv())
+		device, err := resolver.NewDeviceFromPath(ctx, conf.Redpanda.Directory)
+		if err == nil && disk.IsIdentifiedOnly(device) {
+			info.Devnode = device.Devnode()
+			info.Class = device.Class().String()
+			info.IdentifiedOnly = true
+		} else if err == nil {
+			info.Syspath = device.Syspath()
+			info.Devnode = device.Devnode()
+			info.Class = device.Class().String()
+			if mpath := device.Multipath(); mpath != nil {
+				info.Multipath = &blockMultipathInfo{
+					Name:        mpath.Name,
+					UUID:        mpath.UUID,
+					Paths:       mpath.Paths,
+					FailedPaths: mpath.FailedPaths,
+				}
+			}
+			if cache := device.Cache(); cache != nil {
+				info.Cache = &blockCacheInfo{
+					Kind:    cache.Kind,
+					Mode:    cache.Mode,
+					State:   cache.State,
+					Backing: cache.Backing,
+					Cache:   cache.Cache,
+				}
+			}
+			var errs *multierror.Error
+			info.Model, err = device.Model()
+			errs = multierror.Append(errs, err)
+			info.Vendor, err = device.Vendor()
+			errs = multierror.Append(errs, err)
+			info.Serial, err = device.Serial()
+			errs = multierror.Append(errs, err)
+			info.Rotational, err = device.IsRotational()
+			errs = multierror.Append(errs, err)
+			info.DiscardGranularity, err = device.DiscardGranularity()
+			info.Discard = info.DiscardGranularity > 0
+			errs = multierror.Append(errs, err)
+			err = errs.ErrorOrNil()
+		}
+		if err != nil {
+			info.Error = err.Error()
+		}
+		if devices, err := resolver.DeviceInfo(ctx, conf.Redpanda.Directory); err == nil {
+			info.Devices = devices
+		} else if info.Error == "" {
+			info.Error = err.Error()
+		}
+		bs, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("couldn't encode the block devices as JSON: %w", err)
+		}
+		return writeFileToZip(ps, "block-devices.json", bs)
+	}
+}
+
 // TODO: What if running inside a container/ k8s?
 // Writes the journald redpanda logs, if available, to the bundle.
 func saveLogs(ctx context.Context, ps *stepParams, since, until string, logsLimitBytes int) step {
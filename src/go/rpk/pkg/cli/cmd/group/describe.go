@@ -12,7 +12,10 @@ package group
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
@@ -21,10 +24,12 @@ import (
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
 )
 
 func NewDescribeCommand(fs afero.Fs) *cobra.Command {
-	var summary bool
+	var summary, showMembers bool
 
 	cmd := &cobra.Command{
 		Use:   "describe [GROUPS...]",
@@ -33,6 +38,14 @@ func NewDescribeCommand(fs afero.Fs) *cobra.Command {
 
 This command describes group members, calculates their lag, and prints detailed
 information about the members.
+
+With --show-members, the members of each group are also listed with their
+client ID, host, and the partitions assigned to them, as decoded from the
+assignment the group leader distributed. Consumer groups share one assignment
+encoding whatever their balancer (range, roundrobin, sticky,
+cooperative-sticky), and connect groups list their connectors and tasks. The
+assignments of other protocols, or those which can't be decoded, are shown as
+"unknown assignment encoding".
 `,
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, groups []string) {
@@ -40,9 +53,11 @@ information about the members.
 			cfg, err := p.Load(fs)
 			out.MaybeDie(err, "unable to load config: %v", err)
 
-			adm, err := kafka.NewAdmin(fs, p, cfg)
+			cl, err := kafka.NewFranzClient(fs, p, cfg)
 			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
-			defer adm.Close()
+			defer cl.Close()
+			adm := kadm.NewClient(cl)
+			adm.SetTimeoutMillis(5000)
 
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
@@ -50,39 +65,60 @@ information about the members.
 			described, err := adm.DescribeGroups(ctx, groups...)
 			out.HandleShardError("DescribeGroups", err)
 
-			if summary {
-				printDescribedSummary(described)
-				return
-			}
-
-			fetched := adm.FetchManyOffsets(ctx, groups...)
-			fetched.EachError(func(r kadm.FetchOffsetsResponse) {
-				fmt.Printf("unable to fetch offsets for group %q: %v\n", r.Group, r.Err)
-				delete(fetched, r.Group)
-			})
-			if fetched.AllFailed() {
-				out.Die("unable to fetch offsets for any group")
+			var assignments map[string]map[string]string
+			if showMembers {
+				assignments = describeMemberAssignments(ctx, cl, described.Names())
 			}
 
-			var listed kadm.ListedOffsets
-			listPartitions := described.AssignedPartitions()
-			listPartitions.Merge(fetched.CommittedPartitions())
-			if topics := listPartitions.Topics(); len(topics) > 0 {
-				listed, err = adm.ListEndOffsets(ctx, topics...)
-				out.HandleShardError("ListOffsets", err)
+			if summary {
+				printDescribedSummary(described, assignments)
+				return
 			}
 
+			fetched, listed := fetchGroupOffsets(ctx, adm, described, groups)
 			printDescribed(
 				described,
 				fetched,
 				listed,
+				assignments,
 			)
 		},
 	}
 	cmd.Flags().BoolVarP(&summary, "print-summary", "s", false, "Print only the group summary section")
+	cmd.Flags().BoolVar(&showMembers, "show-members", false, "Print the client ID, host and assigned partitions of each member")
 	return cmd
 }
 
+// fetchGroupOffsets fetches the committed offsets of the groups and lists the
+// end offsets of the partitions they are assigned or committed to, the inputs
+// of kadm.CalculateGroupLag. Groups whose offsets can't be fetched are left
+// out.
+func fetchGroupOffsets(
+	ctx context.Context,
+	adm *kadm.Client,
+	described kadm.DescribedGroups,
+	groups []string,
+) (kadm.FetchOffsetsResponses, kadm.ListedOffsets) {
+	fetched := adm.FetchManyOffsets(ctx, groups...)
+	fetched.EachError(func(r kadm.FetchOffsetsResponse) {
+		fmt.Fprintf(os.Stderr, "unable to fetch offsets for group %q: %v\n", r.Group, r.Err)
+		delete(fetched, r.Group)
+	})
+	if fetched.AllFailed() {
+		out.Die("unable to fetch offsets for any group")
+	}
+
+	var listed kadm.ListedOffsets
+	listPartitions := described.AssignedPartitions()
+	listPartitions.Merge(fetched.CommittedPartitions())
+	if topics := listPartitions.Topics(); len(topics) > 0 {
+		var err error
+		listed, err = adm.ListEndOffsets(ctx, topics...)
+		out.HandleShardError("ListOffsets", err)
+	}
+	return fetched, listed
+}
+
 // Below here lies printing the output of everything we have done.
 //
 // There is not much logic; the main thing to note is that we use dashes when
@@ -106,6 +142,7 @@ func printDescribed(
 	groups kadm.DescribedGroups,
 	fetched map[string]kadm.FetchOffsetsResponse,
 	listed kadm.ListedOffsets,
+	assignments map[string]map[string]string,
 ) {
 	for _, group := range groups.Sorted() {
 		lag := kadm.CalculateGroupLag(group, fetched[group.Group].Fetched, listed)
@@ -145,13 +182,24 @@ func printDescribed(
 		}
 
 		printDescribedGroup(group, rows, useInstanceID, useErr)
+		if assignments != nil {
+			fmt.Println()
+			printDescribedMembers(group, assignments[group.Group])
+		}
 		fmt.Println()
 	}
 }
 
-func printDescribedSummary(groups kadm.DescribedGroups) {
+// printDescribedSummary prints the summary of the groups, along with their
+// members if their assignments were described.
+func printDescribedSummary(groups kadm.DescribedGroups, assignments map[string]map[string]string) {
 	for _, group := range groups.Sorted() {
 		printDescribedGroupSummary(group)
+		if assignments != nil {
+			fmt.Println()
+			printDescribedMembers(group, assignments[group.Group])
+			fmt.Println()
+		}
 	}
 }
 
@@ -232,3 +280,116 @@ func printDescribedGroup(
 		tw.Print(args(&row)...)
 	}
 }
+
+// unknownAssignment is printed in place of the assignments which can't be
+// decoded.
+const unknownAssignment = "unknown assignment encoding"
+
+// describeMemberAssignments describes the groups again to decode the raw
+// assignment of each of their members, which kadm decodes without telling
+// whether it succeeded. It returns the formatted assignment of each member of
+// each group.
+func describeMemberAssignments(
+	ctx context.Context, cl *kgo.Client, groups []string,
+) map[string]map[string]string {
+	assignments := make(map[string]map[string]string)
+	req := kmsg.NewPtrDescribeGroupsRequest()
+	req.Groups = groups
+	shards := cl.RequestSharded(ctx, req)
+	kafka.EachShard(req, shards, func(shard kgo.ResponseShard) {
+		resp := shard.Resp.(*kmsg.DescribeGroupsResponse)
+		for _, g := range resp.Groups {
+			members := make(map[string]string, len(g.Members))
+			for _, m := range g.Members {
+				members[m.MemberID] = memberAssignment(g.ProtocolType, m.MemberAssignment)
+			}
+			assignments[g.Group] = members
+		}
+	})
+	return assignments
+}
+
+func printDescribedMembers(group kadm.DescribedGroup, assignments map[string]string) {
+	if len(group.Members) == 0 {
+		return
+	}
+	var useInstanceID bool
+	for _, m := range group.Members {
+		useInstanceID = useInstanceID || m.InstanceID != nil
+	}
+	headers := []string{"MEMBER-ID"}
+	if useInstanceID {
+		headers = append(headers, "INSTANCE-ID")
+	}
+	headers = append(headers, "CLIENT-ID", "HOST", "ASSIGNMENTS")
+
+	tw := out.NewTable(headers...)
+	defer tw.Flush()
+	for _, m := range group.Members {
+		row := []interface{}{m.MemberID}
+		if useInstanceID {
+			var instanceID string
+			if m.InstanceID != nil {
+				instanceID = *m.InstanceID
+			}
+			row = append(row, instanceID)
+		}
+		assignment, ok := assignments[m.MemberID]
+		if !ok {
+			// The member joined after the groups were described again,
+			// or describing them failed.
+			assignment = "-"
+		}
+		tw.Print(append(row, m.ClientID, m.ClientHost, assignment)...)
+	}
+}
+
+// memberAssignment decodes and formats the raw assignment of a member of a
+// group using the given protocol type: the partitions of each topic for
+// consumers, e.g. 'bar[3], foo[0 1 2]', or the tasks of each connector for
+// Kafka connect workers, e.g. 'sink[0 1], source[0]'. It's '-' if nothing is
+// assigned yet.
+func memberAssignment(protocolType string, raw []byte) string {
+	if len(raw) == 0 {
+		return "-"
+	}
+	var assigned []string
+	switch protocolType {
+	case "consumer":
+		// All of the consumer balancers share this encoding, the sticky
+		// ones add their previous assignment as user data.
+		a := kmsg.NewConsumerMemberAssignment()
+		if err := a.ReadFrom(raw); err != nil {
+			return unknownAssignment
+		}
+		for _, t := range a.Topics {
+			partitions := make([]int32, len(t.Partitions))
+			copy(partitions, t.Partitions)
+			sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+			ps := make([]string, 0, len(partitions))
+			for _, p := range partitions {
+				ps = append(ps, strconv.Itoa(int(p)))
+			}
+			assigned = append(assigned, fmt.Sprintf("%s[%s]", t.Topic, strings.Join(ps, " ")))
+		}
+	case "connect":
+		a := kmsg.NewConnectMemberAssignment()
+		if err := a.ReadFrom(raw); err != nil {
+			return unknownAssignment
+		}
+		for _, c := range a.Assignment {
+			tasks := make([]string, 0, len(c.Tasks))
+			for _, task := range c.Tasks {
+				tasks = append(tasks, strconv.Itoa(int(task)))
+			}
+			assigned = append(assigned, fmt.Sprintf("%s[%s]", c.Connector, strings.Join(tasks, " ")))
+		}
+	default:
+		return unknownAssignment
+	}
+	if len(assigned) == 0 {
+		return "-"
+	}
+	sort.Strings(assigned)
+	return strings.Join(assigned, ", ")
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package group
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestMemberAssignment(t *testing.T) {
+	consumer := func(version int16, userData []byte, topics ...kmsg.ConsumerMemberAssignmentTopic) []byte {
+		a := kmsg.NewConsumerMemberAssignment()
+		a.Version, a.Topics, a.UserData = version, topics, userData
+		return a.AppendTo(nil)
+	}
+	topic := func(name string, partitions ...int32) kmsg.ConsumerMemberAssignmentTopic {
+		return kmsg.ConsumerMemberAssignmentTopic{Topic: name, Partitions: partitions}
+	}
+	connect := func(assignments ...kmsg.ConnectMemberAssignmentAssignment) []byte {
+		a := kmsg.NewConnectMemberAssignment()
+		a.Assignment = assignments
+		return a.AppendTo(nil)
+	}
+	tests := []struct {
+		name         string
+		protocolType string
+		raw          []byte
+		exp          string
+	}{
+		{
+			name:         "range",
+			protocolType: "consumer",
+			raw:          consumer(0, nil, topic("foo", 2, 0, 1), topic("bar", 3)),
+			exp:          "bar[3], foo[0 1 2]",
+		},
+		{
+			name:         "roundrobin",
+			protocolType: "consumer",
+			raw:          consumer(0, nil, topic("foo", 0, 2), topic("bar", 1)),
+			exp:          "bar[1], foo[0 2]",
+		},
+		{
+			// The sticky balancers encode their previous assignment in
+			// the user data, which isn't printed.
+			name:         "cooperative-sticky",
+			protocolType: "consumer",
+			raw:          consumer(1, []byte{0, 1, 0, 0, 0, 0}, topic("foo", 1)),
+			exp:          "foo[1]",
+		},
+		{
+			name:         "nothing assigned",
+			protocolType: "consumer",
+			raw:          consumer(0, nil),
+			exp:          "-",
+		},
+		{
+			name:         "not assigned yet",
+			protocolType: "consumer",
+			exp:          "-",
+		},
+		{
+			name:         "truncated",
+			protocolType: "consumer",
+			raw:          consumer(0, nil, topic("foo", 0, 1))[:9],
+			exp:          unknownAssignment,
+		},
+		{
+			name:         "connect",
+			protocolType: "connect",
+			raw: connect(
+				kmsg.ConnectMemberAssignmentAssignment{Connector: "source", Tasks: []int16{0}},
+				kmsg.ConnectMemberAssignmentAssignment{Connector: "sink", Tasks: []int16{0, 1}},
+			),
+			exp: "sink[0 1], source[0]",
+		},
+		{
+			name:         "unknown protocol type",
+			protocolType: "sr",
+			raw:          []byte{0, 1, 2},
+			exp:          unknownAssignment,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.exp, memberAssignment(test.protocolType, test.raw))
+		})
+	}
+}
@@ -97,6 +97,7 @@ members and their lag), and manage offsets.
 	cmd.AddCommand(
 		newDeleteCommand(fs),
 		NewDescribeCommand(fs),
+		newLagCommand(fs),
 		newListCommand(fs),
 		newSeekCommand(fs),
 	)
@@ -0,0 +1,129 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package group
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+func newLagCommand(fs afero.Fs) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "lag [GROUPS...]",
+		Short: "Print the lag of groups",
+		Long: `Print the lag of groups.
+
+This command prints the lag of each partition the groups are assigned or have
+committed offsets to: the difference between the high watermark of the
+partition and the offset committed by the group. Partitions without a committed
+offset, or whose offsets couldn't be listed, are left out rather than reported
+with a made up lag, and so are empty and dead groups without any commit.
+
+With --format prometheus, the lag is printed as 'redpanda_group_lag' gauges in
+the Prometheus text exposition format, labeled with the group, topic and
+partition, e.g. to be collected by the node exporter textfile collector.
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, groups []string) {
+			if format != "text" && format != "prometheus" {
+				out.Die("unsupported format %q, use either text or prometheus", format)
+			}
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			adm, err := kafka.NewAdmin(fs, p, cfg)
+			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
+			defer adm.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			described, err := adm.DescribeGroups(ctx, groups...)
+			out.HandleShardError("DescribeGroups", err)
+
+			fetched, listed := fetchGroupOffsets(ctx, adm, described, groups)
+			lags := groupLags(described, fetched, listed)
+			if format == "prometheus" {
+				writePrometheusLags(os.Stdout, lags)
+				return
+			}
+			tw := out.NewTable("GROUP", "TOPIC", "PARTITION", "LAG")
+			defer tw.Flush()
+			for _, l := range lags {
+				tw.Print(l.group, l.topic, l.partition, l.lag)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, prometheus)")
+	return cmd
+}
+
+type groupLag struct {
+	group     string
+	topic     string
+	partition int32
+	lag       int64
+}
+
+// groupLags returns the lag of the partitions the groups committed offsets
+// to, sorted by group, topic and partition.
+func groupLags(
+	groups kadm.DescribedGroups,
+	fetched kadm.FetchOffsetsResponses,
+	listed kadm.ListedOffsets,
+) []groupLag {
+	var lags []groupLag
+	for _, group := range groups.Sorted() {
+		commits := fetched[group.Group].Fetched
+		for _, l := range kadm.CalculateGroupLag(group, commits, listed).Sorted() {
+			if l.Err != nil {
+				continue
+			}
+			// The lag of partitions the group didn't commit to is
+			// computed from a made up offset.
+			commit, ok := commits.Lookup(l.End.Topic, l.End.Partition)
+			if !ok || commit.Err != nil || commit.At < 0 {
+				continue
+			}
+			lags = append(lags, groupLag{
+				group:     group.Group,
+				topic:     l.End.Topic,
+				partition: l.End.Partition,
+				lag:       l.Lag,
+			})
+		}
+	}
+	return lags
+}
+
+// writePrometheusLags writes the lags as redpanda_group_lag gauges, in the
+// Prometheus text exposition format.
+func writePrometheusLags(w io.Writer, lags []groupLag) {
+	fmt.Fprintln(w, "# HELP redpanda_group_lag Difference between the high watermark of a partition and the offset committed by a group.")
+	fmt.Fprintln(w, "# TYPE redpanda_group_lag gauge")
+	for _, l := range lags {
+		fmt.Fprintf(w, "redpanda_group_lag{group=\"%s\",topic=\"%s\",partition=\"%d\"} %d\n",
+			utils.EscapePrometheusLabel(l.group), utils.EscapePrometheusLabel(l.topic), l.partition, l.lag)
+	}
+}
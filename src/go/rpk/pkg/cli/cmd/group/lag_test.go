@@ -0,0 +1,72 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package group
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+func TestGroupLags(t *testing.T) {
+	commit := func(topic string, partition int32, at int64) kadm.OffsetResponse {
+		return kadm.OffsetResponse{Offset: kadm.Offset{Topic: topic, Partition: partition, At: at}}
+	}
+	end := func(topic string, partition int32, offset int64) kadm.ListedOffset {
+		return kadm.ListedOffset{Topic: topic, Partition: partition, Offset: offset}
+	}
+	described := kadm.DescribedGroups{
+		"empty": {Group: "empty", State: "Empty"},
+		"dead":  {Group: "dead", State: "Dead"},
+	}
+	fetched := kadm.FetchOffsetsResponses{
+		"empty": {
+			Group: "empty",
+			Fetched: kadm.OffsetResponses{
+				"foo": {
+					0: commit("foo", 0, 7),
+					1: commit("foo", 1, 10),
+					// Nothing committed.
+					2: commit("foo", 2, -1),
+				},
+				// The end offsets of bar couldn't be listed.
+				"bar": {0: commit("bar", 0, 3)},
+			},
+		},
+		"dead": {Group: "dead"},
+	}
+	listed := kadm.ListedOffsets{
+		"foo": {
+			0: end("foo", 0, 10),
+			1: end("foo", 1, 10),
+			2: end("foo", 2, 5),
+		},
+	}
+
+	require.Equal(t, []groupLag{
+		{group: "empty", topic: "foo", partition: 0, lag: 3},
+		{group: "empty", topic: "foo", partition: 1, lag: 0},
+	}, groupLags(described, fetched, listed))
+}
+
+func TestWritePrometheusLags(t *testing.T) {
+	var buf bytes.Buffer
+	writePrometheusLags(&buf, []groupLag{
+		{group: "g", topic: "foo", partition: 0, lag: 3},
+		{group: `we"ird\`, topic: "foo", partition: 12, lag: 0},
+	})
+	require.Equal(t, `# HELP redpanda_group_lag Difference between the high watermark of a partition and the offset committed by a group.
+# TYPE redpanda_group_lag gauge
+redpanda_group_lag{group="g",topic="foo",partition="0"} 3
+redpanda_group_lag{group="we\"ird\\",topic="foo",partition="12"} 0
+`, buf.String())
+}
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
@@ -29,6 +30,7 @@ import (
 func newSeekCommand(fs afero.Fs) *cobra.Command {
 	var (
 		to             string
+		toTimestamp    string
 		toGroup        string
 		toFile         string
 		topics         []string
@@ -36,7 +38,7 @@ func newSeekCommand(fs afero.Fs) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "seek [GROUP] --to (start|end|timestamp) --to-group ... --topics ...",
+		Use:   "seek [GROUP] --to (start|end|timestamp) --to-timestamp ... --to-group ... --topics ...",
 		Short: "Modify a group's current offsets",
 		Long: `Modify a group's current offsets.
 
@@ -50,6 +52,14 @@ previously committed. Using --topics allows to you set commits for only the
 specified topics; all other commits will remain untouched. Topics with no
 commits will not be committed unless allowed with --allow-new-topics.
 
+The --to-timestamp option seeks to the first offset of each partition whose
+message timestamp is at or after the given RFC3339 time, e.g.
+2023-05-17T14:05:00Z. Partitions with no such message are seeked to their log
+end, which is reported in the timestamp column. As with --to, only topics
+previously committed are seeked, unless others are given with --topics and
+--allow-new-topics. The group must have no active members: stop its consumers
+before seeking.
+
 The --to-group option allows you to seek to commits that are in another group.
 This is a merging operation: if g1 is consuming topics A and B, and g2 is
 consuming only topic B, "rpk group seek g1 --to-group g2" will update g1's
@@ -67,7 +77,8 @@ the prior options, --topics allows filtering which topics are updated. Similar
 to --to-group, all non-filtered topics are committed, even topics not yet being
 consumed, meaning --allow-new-topics is not needed.
 
-The --to, --to-group, and --to-file options are mutually exclusive. If you are
+The --to, --to-timestamp, --to-group, and --to-file options are mutually
+exclusive. If you are
 not authorized to describe or read some topics used in a group, you will not be
 able to modify offsets for those topics.
 
@@ -77,6 +88,8 @@ Seek group G to June 1st, 2021:
     rpk group seek g --to 1622505600
     or, rpk group seek g --to 1622505600000
     or, rpk group seek g --to 1622505600000000000
+Seek group G to just before 14:05 UTC on May 17th, 2023:
+    rpk group seek g --to-timestamp 2023-05-17T14:04:59Z
 Seek group X to the commits of group Y topic foo:
     rpk group seek X --to-group Y --topics foo
 Seek group G's topics foo, bar, and biz to the end:
@@ -95,7 +108,7 @@ Seek group G to the beginning of a topic it was not previously consuming:
 			defer adm.Close()
 
 			var n int
-			for _, f := range []string{to, toGroup, toFile} {
+			for _, f := range []string{to, toTimestamp, toGroup, toFile} {
 				if f != "" {
 					n++
 				}
@@ -115,11 +128,16 @@ Seek group G to the beginning of a topic it was not previously consuming:
 
 			group := args[0]
 
-			seek(fs, adm, group, to, toGroup, toFile, tset, allowNewTopics)
+			if toTimestamp != "" {
+				seekEnsureEmpty(adm, group)
+			}
+
+			seek(fs, adm, group, to, toTimestamp, toGroup, toFile, tset, allowNewTopics)
 		},
 	}
 
 	cmd.Flags().StringVar(&to, "to", "", "Where to seek (start, end, unix second | millisecond | nanosecond)")
+	cmd.Flags().StringVar(&toTimestamp, "to-timestamp", "", "Seek to the first offsets at or after an RFC3339 timestamp, or to the log end if none")
 	cmd.Flags().StringVar(&toGroup, "to-group", "", "Seek to the commits of another group")
 	cmd.Flags().StringVar(&toFile, "to-file", "", "Seek to offsets as specified in the file")
 	cmd.Flags().StringSliceVar(&topics, "topics", nil, "Only seek these topics, if any are specified")
@@ -180,6 +198,52 @@ func parseSeekFile(
 	return o, nil
 }
 
+// parseSeekTimestamp parses the RFC3339 time of --to-timestamp into
+// milliseconds since the epoch.
+func parseSeekTimestamp(timestamp string) (int64, error) {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse --to-timestamp %q as an RFC3339 time, e.g. 2023-05-17T14:05:00Z: %v", timestamp, err)
+	}
+	return t.UnixMilli(), nil
+}
+
+// seekEnsureEmpty exits if the group has active members, since committing
+// offsets for it would fail, if not be overwritten by its consumers.
+func seekEnsureEmpty(adm *kadm.Client, group string) {
+	described, err := adm.DescribeGroups(context.Background(), group)
+	out.HandleShardError("DescribeGroups", err)
+	d, exists := described[group]
+	if !exists {
+		out.Die("unable to describe group %q", group)
+	}
+	out.MaybeDieErr(checkSeekGroupEmpty(d))
+}
+
+func checkSeekGroupEmpty(described kadm.DescribedGroup) error {
+	if described.Err != nil {
+		return fmt.Errorf("unable to describe group %q: %v", described.Group, described.Err)
+	}
+	if n := len(described.Members); n > 0 {
+		return fmt.Errorf("group %q has %d active members (state %s), stop its consumers before seeking", described.Group, n, described.State)
+	}
+	return nil
+}
+
+// seekTimestampColumn returns the timestamp of the offset listed after a
+// --to-timestamp time, or "log end" if the partition had no message at or
+// after it and its end offset was listed instead.
+func seekTimestampColumn(listed kadm.ListedOffset, exists bool) string {
+	switch {
+	case !exists:
+		return "-"
+	case listed.Timestamp < 0:
+		return "log end"
+	default:
+		return time.UnixMilli(listed.Timestamp).UTC().Format(time.RFC3339Nano)
+	}
+}
+
 func seekFetch(
 	adm *kadm.Client, group string, topics map[string]bool,
 ) kadm.Offsets {
@@ -200,20 +264,24 @@ func seek(
 	adm *kadm.Client,
 	group string,
 	to string,
+	toTimestamp string,
 	toGroup string,
 	toFile string,
 	topics map[string]bool,
 	allowNewTopics bool,
 ) {
 	current := seekFetch(adm, group, topics)
-	var commitTo kadm.Offsets
+	var (
+		commitTo kadm.Offsets
+		listed   kadm.ListedOffsets
+	)
 	if toFile != "" {
 		var err error
 		commitTo, err = parseSeekFile(fs, toFile, topics)
 		out.MaybeDieErr(err)
 	} else if toGroup != "" {
 		commitTo = seekFetch(adm, toGroup, topics)
-	} else { // --to, we need to list offsets currently used, as well as any extra
+	} else { // --to or --to-timestamp, we need to list offsets currently used, as well as any extra
 		tps := current.TopicsSet()
 		for topic := range topics {
 			if _, exists := tps[topic]; !exists && !allowNewTopics {
@@ -222,13 +290,17 @@ func seek(
 			tps[topic] = map[int32]struct{}{} // ensure exists
 		}
 		topics := tps.Topics()
-		var listed kadm.ListedOffsets
 		if len(topics) > 0 {
 			var err error
-			switch to {
-			case "start":
+			switch {
+			case toTimestamp != "":
+				var milli int64
+				milli, err = parseSeekTimestamp(toTimestamp)
+				out.MaybeDieErr(err)
+				listed, err = adm.ListOffsetsAfterMilli(context.Background(), milli, topics...)
+			case to == "start":
 				listed, err = adm.ListStartOffsets(context.Background(), topics...)
-			case "end":
+			case to == "end":
 				listed, err = adm.ListEndOffsets(context.Background(), topics...)
 			default:
 				var milli int64
@@ -259,49 +331,41 @@ func seek(
 
 	useErr := committed.Error() != nil
 	headers := []string{"topic", "partition", "prior-offset", "current-offset"}
+	if toTimestamp != "" {
+		headers = append(headers, "timestamp")
+	}
 	if useErr {
 		headers = append(headers, "error")
 	}
 	tw := out.NewTable(headers...)
 	defer tw.Flush()
 	for _, c := range committed.Sorted() {
-		s := seekCommit{c.Topic, c.Partition, -1, -1}
+		prior, now := int64(-1), int64(-1)
 		if o, exists := current.Lookup(c.Topic, c.Partition); exists {
-			s.Prior = o.At
+			prior = o.At
 		}
 		if o, exists := commitTo.Lookup(c.Topic, c.Partition); exists {
-			s.Current = o.At
+			now = o.At
+		}
+		row := []interface{}{c.Topic, c.Partition, prior, now}
+		if toTimestamp != "" {
+			row = append(row, seekTimestampColumn(listed.Lookup(c.Topic, c.Partition)))
 		}
-		se := seekCommitErr{c.Topic, c.Partition, -1, -1, ""}
+		if !useErr {
+			tw.Print(row...)
+			continue
+		}
+		var errMsg string
 		if c.Err != nil {
 			// Redpanda / Kafka send UnknownMemberID when issuing OffsetCommit
 			// if the group is not empty. This error is unclear to end users, so
 			// we remap it here.
 			if errors.Is(c.Err, kerr.UnknownMemberID) {
-				se.Error = "INVALID_OPERATION: seeking a non-empty group is not allowed."
+				errMsg = "INVALID_OPERATION: seeking a non-empty group is not allowed."
 			} else {
-				se.Error = c.Err.Error()
+				errMsg = c.Err.Error()
 			}
 		}
-		if useErr {
-			tw.PrintStructFields(se)
-		} else {
-			tw.PrintStructFields(s)
-		}
+		tw.Print(append(row, errMsg)...)
 	}
 }
-
-type seekCommit struct {
-	Topic     string
-	Partition int32
-	Prior     int64
-	Current   int64
-}
-
-type seekCommitErr struct {
-	Topic     string
-	Partition int32
-	Prior     int64
-	Current   int64
-	Error     string
-}
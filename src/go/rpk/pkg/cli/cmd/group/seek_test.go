@@ -15,6 +15,7 @@ import (
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/testfs"
 	"github.com/stretchr/testify/require"
 	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
 )
 
 func TestParseSeekFile(t *testing.T) {
@@ -124,3 +125,44 @@ func TestParseSeekFile(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSeekTimestamp(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		in     string
+		exp    int64
+		expErr bool
+	}{
+		{name: "utc", in: "2023-05-17T14:05:00Z", exp: 1684332300000},
+		{name: "offset", in: "2023-05-17T16:05:00+02:00", exp: 1684332300000},
+		{name: "fraction", in: "2023-05-17T14:04:59.5Z", exp: 1684332299500},
+		{name: "no_zone", in: "2023-05-17T14:05:00", expErr: true},
+		{name: "unix", in: "1684332300", expErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseSeekTimestamp(test.in)
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, got)
+		})
+	}
+}
+
+func TestCheckSeekGroupEmpty(t *testing.T) {
+	require.NoError(t, checkSeekGroupEmpty(kadm.DescribedGroup{Group: "g", State: "Empty"}))
+	require.Error(t, checkSeekGroupEmpty(kadm.DescribedGroup{
+		Group:   "g",
+		State:   "Stable",
+		Members: []kadm.DescribedGroupMember{{MemberID: "m"}},
+	}))
+	require.Error(t, checkSeekGroupEmpty(kadm.DescribedGroup{Group: "g", Err: kerr.GroupAuthorizationFailed}))
+}
+
+func TestSeekTimestampColumn(t *testing.T) {
+	require.Equal(t, "2023-05-17T14:05:00.25Z", seekTimestampColumn(kadm.ListedOffset{Timestamp: 1684332300250, Offset: 10}, true))
+	require.Equal(t, "log end", seekTimestampColumn(kadm.ListedOffset{Timestamp: -1, Offset: 42}, true))
+	require.Equal(t, "-", seekTimestampColumn(kadm.ListedOffset{}, false))
+}
@@ -41,14 +41,12 @@ func NewCommand(fs afero.Fs) *cobra.Command {
 			cfg, err := p.Load(fs)
 			out.MaybeDie(err, "unable to load config: %v", err)
 
-			var evalDirectories []string
 			if len(directories) != 0 {
 				fmt.Printf("Overriding evaluation directories with: %q\n",
 					directories)
-				evalDirectories = directories
-			} else {
-				evalDirectories = []string{cfg.Redpanda.Directory}
 			}
+			evalDirectories, err := cfg.DataDirectories(directories)
+			out.MaybeDieErr(err)
 
 			if exists, _ := afero.Exists(fs, outputFile); exists && !noConfirm {
 				confirmed, err := out.Confirm("Overwrite existing configuration file at %q?", outputFile)
@@ -65,7 +63,7 @@ func NewCommand(fs afero.Fs) *cobra.Command {
 				timeout,
 			)
 			fmt.Println("Starting iotune...")
-			result := tuner.Tune()
+			result := tuner.Tune(cmd.Context())
 			out.MaybeDie(result.Error(), "error during iotune execution: %v", result.Error())
 
 			fmt.Printf("IO configuration file stored as %q\n", outputFile)
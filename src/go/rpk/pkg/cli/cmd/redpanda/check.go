@@ -13,16 +13,23 @@
 package redpanda
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/ui"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 )
@@ -31,15 +38,36 @@ func NewCheckCommand(fs afero.Fs) *cobra.Command {
 	var (
 		configFile string
 		timeout    time.Duration
+		format     string
+		fio        bool
+		fioJob     = disk.DefaultFioJob
 	)
 	command := &cobra.Command{
 		Use:   "check",
 		Short: "Check if system meets redpanda requirements",
+		Long: `Check if system meets redpanda requirements.
+
+With --fio, the data directory is also benchmarked with fio, if installed: large
+sequential writes, then small random reads and writes, each for half of
+--fio-duration, to files of --fio-size bytes in a temporary directory removed
+once done. The measured write bandwidth, IOPS and write latency are compared to
+the minimum recommendations. The benchmark loads the disk, avoid running it on
+a busy node.
+`,
 		Run: func(cmd *cobra.Command, args []string) {
+			common.SetOutputFormat(format)
 			p := config.ParamsFromCommand(cmd)
 			cfg, err := p.Load(fs)
 			out.MaybeDie(err, "unable to load config: %v", err)
-			err = executeCheck(fs, cfg, timeout)
+			var job *disk.FioJob
+			if fio {
+				job = &fioJob
+			}
+			// Interrupting cancels the fio benchmark, which still removes
+			// its files from the data directory.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			err = executeCheck(ctx, fs, cfg, timeout, format, job)
 			out.MaybeDie(err, "unable to check: %v", err)
 		},
 	}
@@ -50,6 +78,7 @@ func NewCheckCommand(fs afero.Fs) *cobra.Command {
 		"Redpanda config file, if not set the file will be searched for"+
 			" in the default locations.",
 	)
+	command.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
 	command.Flags().DurationVar(
 		&timeout,
 		"timeout",
@@ -59,6 +88,10 @@ func NewCheckCommand(fs afero.Fs) *cobra.Command {
 			"fraction and a unit suffix, such as '300ms', '1.5s' or '2h45m'. "+
 			"Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'",
 	)
+	command.Flags().BoolVar(&fio, "fio", false, "Benchmark the data directory with fio")
+	command.Flags().DurationVar(&fioJob.Duration, "fio-duration", fioJob.Duration, "The duration of the fio benchmark")
+	command.Flags().Int64Var(&fioJob.Size, "fio-size", fioJob.Size, "The size of the files written by the fio benchmark, in bytes")
+	command.Flags().IntVar(&fioJob.ReadPercent, "fio-read-percent", fioJob.ReadPercent, "The percentage of reads of the random fio benchmark")
 	return command
 }
 
@@ -72,13 +105,42 @@ func appendToTable(t *tablewriter.Table, r tuners.CheckResult) {
 	})
 }
 
+// jsonCheckResult is the result of a checker as printed with '--format
+// json'.
+type jsonCheckResult struct {
+	Condition string `json:"condition"`
+	Required  string `json:"required"`
+	Current   string `json:"current"`
+	Severity  string `json:"severity"`
+	Passed    bool   `json:"passed"`
+	Error     string `json:"error"`
+	// Details are the raw values of the checks exposing them, e.g. the
+	// disk usage of the data directory.
+	Details interface{} `json:"details,omitempty"`
+}
+
 func executeCheck(
-	fs afero.Fs, cfg *config.Config, timeout time.Duration,
+	ctx context.Context,
+	fs afero.Fs,
+	cfg *config.Config,
+	timeout time.Duration,
+	format string,
+	fioJob *disk.FioJob,
 ) error {
-	results, err := tuners.Check(fs, cfg, timeout)
+	results, err := tuners.Check(ctx, fs, cfg, timeout)
 	if err != nil {
 		return err
 	}
+	if fioJob != nil {
+		log.Infof("Benchmarking '%s' with fio for %s", cfg.Redpanda.Directory, fioJob.Duration)
+		// Leave fio some time to lay out its files and report.
+		fioCtx, cancel := context.WithTimeout(ctx, fioJob.Duration+time.Minute)
+		defer cancel()
+		results = append(results, tuners.CheckFio(fioCtx, cfg.Redpanda.Directory, *fioJob)...)
+	}
+	if format == "json" {
+		return printJSONCheckResults(results)
+	}
 	table := ui.NewRpkTable(os.Stdout)
 	table.SetHeader([]string{
 		"Condition",
@@ -96,6 +158,33 @@ func executeCheck(
 	return nil
 }
 
+func printJSONCheckResults(results []tuners.CheckResult) error {
+	jsonResults := []jsonCheckResult{}
+	for _, res := range results {
+		errMsg := ""
+		if res.Err != nil {
+			errMsg = res.Err.Error()
+		}
+		jsonResults = append(jsonResults, jsonCheckResult{
+			Condition: res.Desc,
+			Required:  res.Required,
+			Current:   res.Current,
+			Severity:  res.Severity.String(),
+			Passed:    res.IsOk,
+			Error:     errMsg,
+			Details:   res.Details,
+		})
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	// Keep the '>=' of the requirements readable.
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(jsonResults); err != nil {
+		return fmt.Errorf("unable to format the results as JSON: %v", err)
+	}
+	return nil
+}
+
 func printResult(sev tuners.Severity, isOk bool) string {
 	if isOk {
 		return color.GreenString("%v", isOk)
@@ -32,9 +32,15 @@ func fillRpkConfig(path, mode string) *config.Config {
 		TuneDiskScheduler:  val,
 		TuneDiskWriteCache: val,
 		TuneNomerges:       val,
+		TuneDiskNrRequests: val,
+		TuneDiskReadAhead:  val,
+		TuneDiskAddRandom:  val,
+		TuneDiskMaxSectors: val,
+		TuneDiskRqAffinity: val,
 		TuneDiskIrq:        val,
 		TuneFstrim:         false,
 		TuneCPU:            val,
+		TuneCPUGovernor:    val,
 		TuneAioEvents:      val,
 		TuneClocksource:    val,
 		TuneSwappiness:     val,
@@ -13,6 +13,7 @@
 package redpanda
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -354,7 +355,7 @@ func NewStartCommand(fs afero.Fs, launcher rp.Launcher) *cobra.Command {
 				cfg.Redpanda.Directory = config.DevDefault().Redpanda.Directory
 			}
 
-			err = prestart(fs, rpArgs, cfg, prestartCfg, timeout)
+			err = prestart(cmd.Context(), fs, rpArgs, cfg, prestartCfg, timeout)
 			if err != nil {
 				return err
 			}
@@ -521,6 +522,7 @@ func flagsMap(sFlags seastarFlags) map[string]interface{} {
 }
 
 func prestart(
+	ctx context.Context,
 	fs afero.Fs,
 	args *rp.RedpandaArgs,
 	conf *config.Config,
@@ -528,7 +530,7 @@ func prestart(
 	timeout time.Duration,
 ) error {
 	if prestartCfg.checkEnabled {
-		err := check(fs, conf, timeout, checkFailedActions(args))
+		err := check(ctx, fs, conf, timeout, checkFailedActions(args))
 		if err != nil {
 			return err
 		}
@@ -536,7 +538,7 @@ func prestart(
 	}
 	if prestartCfg.tuneEnabled {
 		cpuset := fmt.Sprint(args.SeastarFlags[cpuSetFlag])
-		err := tuneAll(fs, cpuset, conf, timeout)
+		err := tuneAll(ctx, fs, cpuset, conf, timeout)
 		if err != nil {
 			return err
 		}
@@ -699,6 +701,19 @@ func resolveWellKnownIo(
 		}
 		return ioProps, nil
 	}
+	// Prefer the IO properties measured with 'rpk redpanda tune disk-iotune'
+	// to the precompiled ones of the cloud vendor.
+	for _, measured := range conf.Rpk.IoProperties {
+		if measured.MountPoint == conf.Redpanda.Directory {
+			return &iotune.IoProperties{
+				MountPoint:     measured.MountPoint,
+				ReadIops:       measured.ReadIops,
+				ReadBandwidth:  measured.ReadBandwidth,
+				WriteIops:      measured.WriteIops,
+				WriteBandwidth: measured.WriteBandwidth,
+			}, nil
+		}
+	}
 	// Skip detecting the cloud vendor if skipChecks is true
 	if skipChecks {
 		return nil, nil
@@ -717,7 +732,11 @@ func resolveWellKnownIo(
 }
 
 func tuneAll(
-	fs afero.Fs, cpuSet string, conf *config.Config, timeout time.Duration,
+	ctx context.Context,
+	fs afero.Fs,
+	cpuSet string,
+	conf *config.Config,
+	timeout time.Duration,
 ) error {
 	params := &factory.TunerParams{}
 	tunerFactory := factory.NewDirectExecutorTunersFactory(fs, *conf, timeout)
@@ -756,7 +775,7 @@ func tuneAll(
 			continue
 		}
 		log.Debugf("Tuner parameters %+v", params)
-		result := tuner.Tune()
+		result := tuner.Tune(ctx)
 		if result.IsFailed() {
 			return result.Error()
 		}
@@ -778,12 +797,13 @@ func checkFailedActions(
 }
 
 func check(
+	ctx context.Context,
 	fs afero.Fs,
 	conf *config.Config,
 	timeout time.Duration,
 	checkFailedActions map[tuners.CheckerID]checkFailedAction,
 ) error {
-	results, err := tuners.Check(fs, conf, timeout)
+	results, err := tuners.Check(ctx, fs, conf, timeout)
 	if err != nil {
 		return err
 	}
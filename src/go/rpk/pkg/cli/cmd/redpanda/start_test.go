@@ -1857,6 +1857,36 @@ func Test_buildRedpandaFlags(t *testing.T) {
 	}
 }
 
+func Test_resolveWellKnownIo_measured(t *testing.T) {
+	conf := config.DevDefault()
+	conf.Redpanda.Directory = "/var/lib/redpanda/data"
+	conf.Rpk.IoProperties = []config.RpkIoProperties{
+		{MountPoint: "/mnt/other", ReadIops: 1},
+		{
+			MountPoint:     "/var/lib/redpanda/data",
+			Devices:        []string{"nvme0n1"},
+			ReadIops:       400000,
+			ReadBandwidth:  2000000000,
+			WriteIops:      180000,
+			WriteBandwidth: 800000000,
+		},
+	}
+	ioProps, err := resolveWellKnownIo(conf, true)
+	require.NoError(t, err)
+	require.Equal(t, &iotune.IoProperties{
+		MountPoint:     "/var/lib/redpanda/data",
+		ReadIops:       400000,
+		ReadBandwidth:  2000000000,
+		WriteIops:      180000,
+		WriteBandwidth: 800000000,
+	}, ioProps)
+
+	conf.Rpk.IoProperties = conf.Rpk.IoProperties[:1]
+	ioProps, err = resolveWellKnownIo(conf, true)
+	require.NoError(t, err)
+	require.Nil(t, ioProps)
+}
+
 func intPtr(i int) *int {
 	return &i
 }
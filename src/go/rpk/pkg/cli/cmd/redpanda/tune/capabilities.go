@@ -0,0 +1,199 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/factory"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func newCapabilitiesCommand(fs afero.Fs) *cobra.Command {
+	var (
+		tunerParams factory.TunerParams
+		format      string
+		timeout     time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "capabilities",
+		Short: "Report which tunables of the host can be tuned, without tuning them",
+		Long: `Report which tunables of the host can be tuned, without tuning them.
+
+The devices of the data directories are resolved like 'rpk redpanda tune'
+does. For each tuner, and each of its devices for the disk tuners, the files
+it writes to are opened for writing, but never written to, e.g.
+'/sys/block/nvme0n1/queue/scheduler' or '/proc/sys/vm/swappiness'. Each is
+reported as:
+
+  supported         the file exists and is writable.
+  unsupported       the file, or the feature the tuner needs, is missing,
+                    e.g. the write cache of devices not exposing it.
+  needs-privilege   the file exists but can't be written to, e.g. as a
+                    regular user or in an unprivileged container.
+
+The tuners whose files aren't known ahead of tuning, e.g. those running
+commands, are reported from their support checks only, without a path.
+
+With '--format json', the report is a list of objects with stable fields:
+'tuner', 'device' (for the disk tuners), 'path' (if known), 'status' and
+'reason' (if not supported), to be consumed by provisioning tools before
+tuning.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			common.SetOutputFormat(format)
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			// Using the cpu mask default since we are not executing any
+			// tuner.
+			tunerParams.CPUMask = "all"
+			err = factory.ResolveDiskDevices(cmd.Context(), fs, &tunerParams)
+			if advice := resolveAdvice(err); advice != "" {
+				out.Die("%v\n%s", err, advice)
+			}
+			out.MaybeDieErr(err)
+			params, err := factory.MergeTunerParamsConfig(&tunerParams, cfg, factory.AvailableTuners())
+			out.MaybeDieErr(err)
+
+			// The tuners are created with a dry-run executor: even if a
+			// tuner was mistakenly run, nothing would be changed.
+			tunerFactory := factory.NewRecordingTunersFactory(
+				fs, *cfg, executors.NewDryRunExecutor(), timeout)
+			report := tunableCapabilities(fs, tunerFactory, params)
+
+			if format == "json" {
+				asJSON, err := json.MarshalIndent(report, "", "  ")
+				out.MaybeDie(err, "unable to format the capabilities as JSON: %v", err)
+				fmt.Println(string(asJSON))
+				return
+			}
+			printTunableCapabilities(report)
+		},
+	}
+	addTunerParamsFlags(command, &tunerParams)
+	command.Flags().StringVar(&format,
+		"format",
+		"text",
+		"Output format (text, json)")
+	command.Flags().DurationVar(&timeout,
+		"timeout",
+		10000*time.Millisecond,
+		"The maximum time to wait for the processes used to detect the block devices")
+	command.Flags().StringVar(
+		new(string),
+		config.FlagConfig,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations.",
+	)
+	return command
+}
+
+// tunableCapabilities probes the files of each of the available tuners, in
+// the order of their names.
+func tunableCapabilities(
+	fs afero.Fs, tunerFactory factory.TunersFactory, params *factory.TunerParams,
+) []tuners.TunableCapability {
+	names := factory.AvailableTuners()
+	sort.Strings(names)
+	report := []tuners.TunableCapability{}
+	for _, name := range names {
+		tuner := tunerFactory.CreateTuner(name, params)
+		if supported, reason := tuner.CheckIfSupported(); !supported {
+			report = append(report, tuners.TunableCapability{
+				Tuner:  name,
+				Status: tuners.CapabilityUnsupported,
+				Reason: reason,
+			})
+			continue
+		}
+		devices := tunerDevices(name, tuner)
+		if _, ok := tuner.(tuners.DeviceTunable); !ok {
+			// The host-wide tuners probe their files once.
+			devices = []string{""}
+		}
+		for _, device := range devices {
+			report = append(report, probeTunableFiles(fs, tuner, name, device)...)
+		}
+	}
+	return report
+}
+
+// probeTunableFiles probes the files the tuner writes to for the device, or
+// the host-wide ones if device is empty. The tuners whose files aren't known
+// are reported as unknown.
+func probeTunableFiles(
+	fs afero.Fs, tuner tuners.Tunable, name, device string,
+) []tuners.TunableCapability {
+	fileTuner, ok := tuner.(tuners.FileTunable)
+	if !ok {
+		return []tuners.TunableCapability{{
+			Tuner:  name,
+			Device: device,
+			Status: tuners.CapabilityUnknown,
+			Reason: "files not known ahead of tuning",
+		}}
+	}
+	files, err := fileTuner.TunableFiles(device)
+	if err != nil {
+		return []tuners.TunableCapability{{
+			Tuner:  name,
+			Device: device,
+			Status: tuners.CapabilityUnsupported,
+			Reason: err.Error(),
+		}}
+	}
+	if len(files) == 0 {
+		capability := tuners.TunableCapability{
+			Tuner:  name,
+			Device: device,
+			Status: tuners.CapabilitySupported,
+		}
+		if device != "" {
+			capability.Status = tuners.CapabilityUnsupported
+			capability.Reason = "not exposed by the device"
+		}
+		return []tuners.TunableCapability{capability}
+	}
+	var capabilities []tuners.TunableCapability
+	for _, file := range files {
+		status, reason := tuners.ProbeTunableFile(fs, file)
+		capabilities = append(capabilities, tuners.TunableCapability{
+			Tuner:  name,
+			Device: device,
+			Path:   file,
+			Status: status,
+			Reason: reason,
+		})
+	}
+	return capabilities
+}
+
+func printTunableCapabilities(report []tuners.TunableCapability) {
+	table := out.NewTable("Tuner", "Device", "Path", "Status", "Reason")
+	defer table.Flush()
+	for _, c := range report {
+		table.Print(c.Tuner, c.Device, c.Path, c.Status, c.Reason)
+	}
+}
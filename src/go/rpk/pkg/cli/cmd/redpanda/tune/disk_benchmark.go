@@ -0,0 +1,149 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	vos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type diskBenchmark struct {
+	Directory    string                           `json:"directory"`
+	Samples      int                              `json:"samples"`
+	P50LatencyUs int64                            `json:"p50_latency_us"`
+	P99LatencyUs int64                            `json:"p99_latency_us"`
+	MaxLatencyUs int64                            `json:"max_latency_us"`
+	Devices      []tuners.SchedulerRecommendation `json:"devices"`
+}
+
+func newDiskBenchmarkCommand(fs afero.Fs) *cobra.Command {
+	var (
+		directories []string
+		duration    time.Duration
+		timeout     time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "disk-benchmark",
+		Short: "Measure the write latency of the data directories and recommend an I/O scheduler",
+		Long: fmt.Sprintf(`Measure the write latency of the data directories and recommend an I/O scheduler.
+
+The benchmark issues back to back 4KB writes, each followed by an fsync, to a
+temporary file in each directory and reports their p50 and p99 latency in
+JSON. The temporary file is removed once done.
+
+The scheduler of each device is recommended from its device class, like the
+disk_scheduler tuner does, but non-rotational devices whose p99 write latency
+is above %s are scheduled like rotational ones, as they don't
+keep up with concurrent I/O either.
+
+The benchmark writes to the directories, avoid running it on a busy node.
+`, tuners.SlowDeviceWriteLatency),
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			common.SetOutputFormat("json")
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+			directories, err = cfg.DataDirectories(directories)
+			out.MaybeDieErr(err)
+
+			irqProcFile := irq.NewProcFile(fs)
+			blockDevices := disk.NewBlockDevices(
+				fs,
+				irq.NewDeviceInfo(fs, irqProcFile),
+				irqProcFile,
+				vos.NewProc(),
+				timeout,
+				disk.SysfsRootFromEnv(),
+			)
+			deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+
+			var benchmarks []diskBenchmark
+			for _, directory := range directories {
+				benchmark, err := benchmarkDirectory(fs, directory, duration, blockDevices, deviceFeatures)
+				out.MaybeDie(err, "unable to benchmark '%s': %v", directory, err)
+				benchmarks = append(benchmarks, *benchmark)
+			}
+			asJSON, err := json.MarshalIndent(benchmarks, "", "  ")
+			out.MaybeDie(err, "unable to format the benchmark as JSON: %v", err)
+			fmt.Println(string(asJSON))
+		},
+	}
+	command.Flags().StringSliceVarP(&directories,
+		"dirs", "r",
+		[]string{}, "List of directories to benchmark, defaults to the"+
+			" redpanda.data_directory of the configuration")
+	command.Flags().DurationVar(&duration,
+		"duration",
+		disk.DefaultProbeDuration,
+		"How long to probe the write latency of each directory for")
+	command.Flags().DurationVar(&timeout,
+		"timeout",
+		10000*time.Millisecond,
+		"The maximum time to wait for the processes used to detect the block devices")
+	command.Flags().StringVar(
+		new(string),
+		config.FlagConfig,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations.",
+	)
+	return command
+}
+
+// benchmarkDirectory probes the write latency of the directory and
+// recommends a scheduler for each of the devices it's stored on.
+func benchmarkDirectory(
+	fs afero.Fs,
+	directory string,
+	duration time.Duration,
+	blockDevices disk.BlockDevices,
+	deviceFeatures disk.DeviceFeatures,
+) (*diskBenchmark, error) {
+	devices, err := blockDevices.GetDirectoryDevices(directory)
+	if err != nil {
+		return nil, err
+	}
+	latency, err := disk.ProbeWriteLatency(fs, directory, duration)
+	if err != nil {
+		return nil, err
+	}
+	benchmark := &diskBenchmark{
+		Directory:    directory,
+		Samples:      latency.Samples,
+		P50LatencyUs: latency.P50.Microseconds(),
+		P99LatencyUs: latency.P99.Microseconds(),
+		MaxLatencyUs: latency.Max.Microseconds(),
+		Devices:      []tuners.SchedulerRecommendation{},
+	}
+	for _, device := range devices {
+		recommendation, err := tuners.RecommendScheduler(device, deviceFeatures, latency)
+		if err != nil {
+			return nil, err
+		}
+		if recommendation != nil {
+			benchmark.Devices = append(benchmark.Devices, *recommendation)
+		}
+	}
+	return benchmark, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	vos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func newDiskIoTuneCommand(fs afero.Fs) *cobra.Command {
+	var (
+		directories []string
+		duration    time.Duration
+		timeout     time.Duration
+		force       bool
+	)
+	command := &cobra.Command{
+		Use:   "disk-iotune",
+		Short: "Measure the IO properties of the data directories and write them to redpanda.yaml",
+		Long: `Measure the IO properties of the data directories and write them to redpanda.yaml.
+
+The maximum read and write IOPS and bandwidth of the device holding each
+directory are measured, like iotune does, and written to rpk.io_properties in
+redpanda.yaml. 'rpk redpanda start' passes them to Redpanda unless an IO
+properties file or flag is given, in place of the well known IO properties of
+the cloud vendor.
+
+IOPS are measured with random 4KB requests and the bandwidth with sequential
+1MB ones, issued with direct IO to a 1GB temporary file in each directory. The
+temporary file is removed once done. The measurement saturates the devices,
+avoid running it on a busy node.
+
+Directories already measured are skipped, pass --force to measure them again.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+			cfg = cfg.FileOrDefaults() // we write the measurement to the raw file without writing env / flag overrides
+			directories, err = cfg.DataDirectories(directories)
+			out.MaybeDieErr(err)
+
+			irqProcFile := irq.NewProcFile(fs)
+			blockDevices := disk.NewBlockDevices(
+				fs,
+				irq.NewDeviceInfo(fs, irqProcFile),
+				irqProcFile,
+				vos.NewProc(),
+				timeout,
+				disk.SysfsRootFromEnv(),
+			)
+			// Stop the measurement on interrupt, so its file is removed.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var measured bool
+			for _, directory := range directories {
+				directory = filepath.Clean(directory)
+				if ioPropertiesIndex(cfg.Rpk.IoProperties, directory) >= 0 && !force {
+					fmt.Printf("IO properties of '%s' already measured, skipping; pass --force to measure them again\n", directory)
+					continue
+				}
+				devices, err := blockDevices.GetDirectoryDevices(directory)
+				out.MaybeDie(err, "unable to resolve the devices of '%s': %v", directory, err)
+				fmt.Printf("Measuring the IO properties of '%s' on %s for %s\n", directory, strings.Join(devices, ", "), duration)
+				measurement, err := disk.ProbeIo(ctx, fs, directory, duration)
+				out.MaybeDie(err, "unable to measure the IO properties of '%s': %v", directory, err)
+				fmt.Printf("Read: %d IOPS, %s/s; write: %d IOPS, %s/s\n",
+					measurement.ReadIops,
+					units.HumanSize(float64(measurement.ReadBandwidth)),
+					measurement.WriteIops,
+					units.HumanSize(float64(measurement.WriteBandwidth)),
+				)
+				cfg.Rpk.IoProperties = setIoProperties(cfg.Rpk.IoProperties, config.RpkIoProperties{
+					MountPoint:     directory,
+					Devices:        devices,
+					ReadIops:       measurement.ReadIops,
+					ReadBandwidth:  measurement.ReadBandwidth,
+					WriteIops:      measurement.WriteIops,
+					WriteBandwidth: measurement.WriteBandwidth,
+				})
+				measured = true
+			}
+			if !measured {
+				return
+			}
+			fmt.Printf("Writing IO properties to %q\n", cfg.FileLocation())
+			err = cfg.Write(fs)
+			out.MaybeDieErr(err)
+		},
+	}
+	command.Flags().StringSliceVarP(&directories,
+		"dirs", "r",
+		[]string{}, "List of directories to measure, defaults to the"+
+			" redpanda.data_directory of the configuration")
+	command.Flags().DurationVar(&duration,
+		"duration",
+		disk.DefaultIoProbeDuration,
+		"How long to measure each directory for")
+	command.Flags().DurationVar(&timeout,
+		"timeout",
+		10000*time.Millisecond,
+		"The maximum time to wait for the processes used to detect the block devices")
+	command.Flags().BoolVar(&force,
+		"force",
+		false,
+		"Measure the directories again even if their IO properties were already measured")
+	command.Flags().StringVar(
+		new(string),
+		config.FlagConfig,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations.",
+	)
+	return command
+}
+
+// ioPropertiesIndex returns the index of the IO properties of the mount
+// point, or -1 if it wasn't measured.
+func ioPropertiesIndex(ioProperties []config.RpkIoProperties, mountPoint string) int {
+	for i, props := range ioProperties {
+		if filepath.Clean(props.MountPoint) == mountPoint {
+			return i
+		}
+	}
+	return -1
+}
+
+// setIoProperties replaces the IO properties of the same mount point, or
+// appends them if it wasn't measured.
+func setIoProperties(
+	ioProperties []config.RpkIoProperties, props config.RpkIoProperties,
+) []config.RpkIoProperties {
+	if i := ioPropertiesIndex(ioProperties, props.MountPoint); i >= 0 {
+		ioProperties[i] = props
+		return ioProperties
+	}
+	return append(ioProperties, props)
+}
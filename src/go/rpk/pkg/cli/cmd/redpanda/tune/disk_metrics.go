@@ -0,0 +1,84 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	vos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/factory"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// writeDiskMetrics writes the disk tuning metrics of the devices the disk
+// tuners act on to file, or to stdout if file is '-', see
+// tuners.WriteDiskMetrics. The file is replaced atomically, as the textfile
+// collector of node_exporter may read it at any time. Devices whose state
+// can't be read are left out.
+func writeDiskMetrics(
+	fs afero.Fs, file string, params *factory.TunerParams, timeout time.Duration,
+) error {
+	irqProcFile := irq.NewProcFile(fs)
+	blockDevices := disk.NewBlockDevices(
+		fs,
+		irq.NewDeviceInfo(fs, irqProcFile),
+		irqProcFile,
+		vos.NewProc(),
+		timeout,
+		disk.SysfsRootFromEnv(),
+	)
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+
+	devices := map[string]bool{}
+	for _, device := range params.Disks {
+		devices[device] = true
+	}
+	if len(params.Directories) > 0 {
+		directoryDevices, err := blockDevices.GetDirectoriesDevices(params.Directories)
+		if err != nil {
+			return err
+		}
+		for _, dirDevices := range directoryDevices {
+			for _, device := range dirDevices {
+				devices[device] = true
+			}
+		}
+	}
+	var states []tuners.DiskTuningState
+	for device := range devices {
+		state, err := tuners.ReadDiskTuningState(device, deviceFeatures)
+		if err != nil {
+			log.Warnf("Unable to read the tuning state of '%s', leaving it out of the metrics: %v", device, err)
+			continue
+		}
+		states = append(states, state)
+	}
+
+	if file == "-" {
+		return tuners.WriteDiskMetrics(os.Stdout, states)
+	}
+	var b bytes.Buffer
+	if err := tuners.WriteDiskMetrics(&b, states); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := afero.WriteFile(fs, tmp, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return fs.Rename(tmp, file)
+}
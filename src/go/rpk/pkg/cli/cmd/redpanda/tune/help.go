@@ -22,16 +22,25 @@ import (
 
 func newHelpCommand() *cobra.Command {
 	tunersHelp := map[string]string{
-		"cpu":                   cpuTunerHelp,
-		"disk_irq":              diskIrqTunerHelp,
-		"disk_scheduler":        diskSchedulerTunerHelp,
-		"net":                   netTunerHelp,
-		"swappiness":            swappinessTunerHelp,
-		"fstrim":                fstrimTunerHelp,
-		"aio_events":            aioEventsTunerHelp,
-		"transparent_hugepages": transparentHugepagesTunerHelp,
-		"clocksource":           clocksourceTunerHelp,
-		"nomerges":              nomergesTunerHelp,
+		"cpu":                       cpuTunerHelp,
+		"disk_irq":                  diskIrqTunerHelp,
+		"disk_scheduler":            diskSchedulerTunerHelp,
+		"net":                       netTunerHelp,
+		"rps":                       rpsTunerHelp,
+		"xps":                       xpsTunerHelp,
+		"swappiness":                swappinessTunerHelp,
+		"cpu_governor":              cpuGovernorTunerHelp,
+		"fstrim":                    fstrimTunerHelp,
+		"aio_events":                aioEventsTunerHelp,
+		"transparent_hugepages":     transparentHugepagesTunerHelp,
+		"clocksource":               clocksourceTunerHelp,
+		"nomerges":                  nomergesTunerHelp,
+		"disk_nr_requests":          diskNrRequestsTunerHelp,
+		"disk_add_random":           diskAddRandomTunerHelp,
+		"disk_max_sectors":          diskMaxSectorsTunerHelp,
+		"disk_rq_affinity":          diskRqAffinityTunerHelp,
+		"disk_read_ahead":           diskReadAheadTunerHelp,
+		"disk_volatile_write_cache": diskVolatileWriteCacheTunerHelp,
 	}
 
 	return &cobra.Command{
@@ -67,7 +76,8 @@ After this tuner execution system CPUs will operate with maximum
 This tuner performs the following operations:
 
 - Disable Hyper Threading
-- Sets the ACPI-cpufreq governor to ‘performance’
+- Sets the ACPI-cpufreq governor to ‘performance’, or to rpk.cpu_governor,
+  as the cpu_governor tuner does
 
 Additionally if system reboot is allowed:
 - Disable Hyper Threading via Kernel boot parameter
@@ -116,16 +126,27 @@ const diskSchedulerTunerHelp = `
 This tuner sets the preferred I/O scheduler for given block devices and disables
 I/O operation merging. It can work using both the device name or a directory,
 then the device where directory is stored will be optimized.
-The tuner sets either ‘none’ or ‘noop’ scheduler if supported.
+The tuner picks the scheduler based on the device class, falling back to the
+next preferred one if it's not supported:
+
+	NVMe devices           - 'none' or 'noop'
+	non rotational devices - 'mq-deadline', 'none' or 'noop'
+	rotational devices     - 'mq-deadline', 'bfq' or 'deadline'
+
+Devices not exposing their scheduler, or without one to pick, e.g. only 'none',
+are reported as not applied.
 
 Schedulers:
 
-	none - used with modern NVMe devices to
-	   	   bypass OS I/O scheduler and minimize latency
-	noop - used when ‘none’ is not available,
-		   it is preferred for non-NVME devices, this scheduler uses simple FIFO
-		   queue where all I/O operations are first stored
-		   and then handled by the driver`
+	none        - used with modern NVMe devices to
+	              bypass OS I/O scheduler and minimize latency
+	noop        - used when 'none' is not available, this scheduler uses simple
+	              FIFO queue where all I/O operations are first stored
+	              and then handled by the driver
+	mq-deadline - prevents the starvation of requests by serving them
+	              before their deadline expires, 'deadline' is its legacy
+	              single queue equivalent
+	bfq         - fair scheduler reducing seeks on rotational disks`
 
 const diskIrqTunerHelp = `
 This tuner distributes block devices IRQs according to the specified mode.
@@ -135,6 +156,13 @@ For the IRQs distribution the tuner uses only those CPUs that are allowed by
 the provided CPU masks. IRQs of non-NVMe devices are distributed across the
 cores that are calculated based on the mode provided,
 NVMe devices IRQs are distributed across all available cores allowed by CPU mask.
+When running in a cgroup v2, e.g. in a container, the CPU mask is further
+restricted to the CPUs of its cpuset (cpuset.cpus.effective).
+On NUMA machines, the IRQs of NVMe devices are distributed across the CPUs of
+the NUMA node of the device (/sys/block/<dev>/device/numa_node), or across all
+the allowed CPUs if its node is unknown (-1) or has none of them. The node each
+device was assigned, and the CPUs each IRQ of its queues was pinned to, are
+reported in its device result.
 
 This tuner performs the following operations:
 	- Setup disks IRQs affinity
@@ -162,7 +190,23 @@ If there isn't any mode given script will use a default mode:
 
 const swappinessTunerHelp = `
 Tunes the kernel to keep process data in-memory for as long as possible, instead
-of swapping it out to disk.
+of swapping it out to disk, by setting vm.swappiness to 1. The previous value is
+restored by 'rpk redpanda tune --revert'. If /proc/sys is read-only, e.g. in
+containers, the tuner is reported as not applied.
+
+To keep redpanda from swapping at all, enable rpk.enable_memory_locking: the
+checks then verify that the memlock limit of the running redpanda process, or,
+if redpanda isn't running, the LimitMEMLOCK of the redpanda service, is
+unlimited.
+`
+
+const cpuGovernorTunerHelp = `
+Sets the frequency governor of each CPU exposing cpufreq to 'performance', so
+that the CPUs serving redpanda run at their maximum frequency instead of being
+throttled by a power saving governor like 'powersave' under bursty load. Another
+governor may be set with rpk.cpu_governor, it must be available on all the CPUs
+(scaling_available_governors). CPUs without cpufreq, e.g. in most VMs, are
+skipped. The previous governors are restored by 'rpk redpanda tune --revert'.
 `
 
 const fstrimTunerHelp = `
@@ -192,6 +236,11 @@ Enables Transparent Hugepages. This allows the kernel to index larger pages
 (2MB, as opposed to the standard 4KB) in the CPU's TLB (if it supports it, which
 is the case for most current CPUs). This results in fewer cache misses, which
 means less time is spent searching and loading pages.
+
+The mode is set to 'always' if transparent huge pages are disabled ('never'),
+'madvise' is left as is since redpanda advises them for its memory. The previous
+mode is restored by 'rpk redpanda tune --revert'. Kernels without transparent huge
+pages support are skipped.
 `
 
 const clocksourceTunerHelp = `
@@ -204,5 +253,86 @@ entails.
 
 const nomergesTunerHelp = `
 Disables merging adjacent IO requests, which would require checking outstanding
-IO requests to batch them where possible, incurring in some CPU overhead.
+IO requests to batch them where possible, incurring in some CPU overhead. Only
+non-rotational devices, such as NVMe drives, are tuned: merging still reduces
+seeks on rotational disks.
+`
+
+const diskNrRequestsTunerHelp = `
+Raises the number of requests the block layer allocates for each non rotational
+disk (queue/nr_requests) to its hardware queue depth, so that fast devices such
+as NVMe drives can keep their queues full. The hardware queue depth is also the
+maximum the kernel accepts for queues without an I/O scheduler ('none'), whose
+nr_requests may not be writable: those are skipped. Rotational disks and disks
+exposing neither nr_requests nor their queue depth are left untouched.
+`
+
+const diskAddRandomTunerHelp = `
+Stops the I/O completions of each non rotational disk from contributing to the
+kernel entropy pool (queue/add_random), which costs CPU time on every request
+of high-IOPS devices such as NVMe drives, whose timings are hardly random
+anyway. Rotational disks are left untouched and reported as skipped, as are
+disks not exposing add_random.
+`
+
+const diskMaxSectorsTunerHelp = `
+Raises the largest request the block layer sends to each disk
+(queue/max_sectors_kb) to the largest one the disk accepts
+(queue/max_hw_sectors_kb), rounded down to a multiple of its optimal I/O size
+(queue/optimal_io_size), if it reports one, so that large sequential writes
+aren't split into many small requests. Only disks whose max_sectors_kb is below
+half of their hardware max, or misaligned with their optimal I/O size, are
+tuned. Disks exposing neither value are left untouched.
+`
+
+const diskRqAffinityTunerHelp = `
+Completes the I/O requests of each non rotational disk on the CPU that issued
+them (queue/rq_affinity set to 2), rather than on any CPU of its group, so that
+the completions are handled by the shard waiting for them, with their data
+still in its caches. Rotational disks are left untouched and reported as
+skipped, as are disks not exposing rq_affinity, like some older virtio disks.
+`
+
+const diskReadAheadTunerHelp = `
+Sets how much data the kernel reads ahead of sequential reads on each disk
+(queue/read_ahead_kb): 128KB on NVMe and other non-rotational devices, which
+have no seeks to amortize, at least 4MB on rotational devices and at least a
+full stripe on md arrays. Rotational devices and md arrays already reading
+further ahead are left untouched. The value of stacked devices,
+like md arrays, is set on each of their member devices too. RAID1 arrays,
+whose reads are each served by a single member, have no stripe to read ahead
+and only get the target of their devices. Devices not exposing their
+read-ahead are reported as not applied.
+`
+
+const diskVolatileWriteCacheTunerHelp = `
+Disables the volatile write cache of the drives holding the data directory by
+setting their SCSI cache type to 'write through', so that acknowledged writes
+aren't lost on power failure. Only SCSI and SATA disks expose it; NVMe drives
+are left untouched. This lowers the write throughput of drives without power
+loss protection, so it's not enabled in production mode. The previous cache
+type is restored by 'rpk redpanda tune --revert'.
+`
+
+const rpsTunerHelp = `
+Sets the RPS CPU mask of each Rx queue of the NICs serving the Kafka API, so
+that the packets they receive are processed by the CPUs left for computations
+by the IRQs distribution mode. The mask is the one the 'net' tuner sets, see
+'rpk redpanda tune help net' for the modes.
+
+The NICs are those with the advertised Kafka address, or with the address of
+the Kafka listener if none has it, unless given with --nic. Virtual interfaces
+are skipped and the slaves of bonds are tuned in their place. The previous
+masks are restored by 'rpk redpanda tune --revert'.
+`
+
+const xpsTunerHelp = `
+Sets the XPS CPU mask of each Tx queue of the NICs serving the Kafka API, so
+that the CPUs are distributed among the queues and each CPU transmits on its
+own queue, as the 'net' tuner does.
+
+The NICs are those with the advertised Kafka address, or with the address of
+the Kafka listener if none has it, unless given with --nic. Virtual interfaces
+are skipped and the slaves of bonds are tuned in their place. The previous
+masks are restored by 'rpk redpanda tune --revert'.
 `
@@ -57,9 +57,11 @@ You may use 'rpk redpanda config set' to enable or disable a tuner.
 			// Using cpu mask and timeout defaults since we are not executing
 			// any tuner.
 			tunerParams.CPUMask = "all"
+			err = factory.ResolveDiskDevices(cmd.Context(), fs, &tunerParams)
+			out.MaybeDieErr(err)
 			tunerFactory := factory.NewDirectExecutorTunersFactory(fs, *cfg, 10000*time.Millisecond)
 
-			params, err := factory.MergeTunerParamsConfig(&tunerParams, cfg)
+			params, err := factory.MergeTunerParamsConfig(&tunerParams, cfg, factory.AvailableTuners())
 			out.MaybeDieErr(err)
 
 			var list []tunerInfo
@@ -0,0 +1,361 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	vos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// deviceResolution is the resolution of a directory to its physical
+// devices, as printed by list-devices.
+type deviceResolution struct {
+	Directory      string `json:"directory"`
+	DeviceNumber   string `json:"device_number"`
+	FilesystemType string `json:"filesystem_type,omitempty"`
+	Syspath        string `json:"syspath"`
+	Device         string `json:"device"`
+	DeviceSyspath  string `json:"device_syspath"`
+	Partition      string `json:"partition,omitempty"`
+	// Encrypted is set if the directory crosses a dm-crypt layer.
+	Encrypted bool `json:"encrypted"`
+	// IdentifiedOnly is set if the device was identified without sysfs,
+	// e.g. from /proc/partitions: its attributes aren't available and it
+	// can't be tuned.
+	IdentifiedOnly bool `json:"identified_only"`
+	// Discard is supported by the device only if all its physical devices
+	// support it, DiscardGranularity is in bytes.
+	Discard            bool               `json:"discard"`
+	DiscardGranularity uint64             `json:"discard_granularity"`
+	Capabilities       deviceCapabilities `json:"capabilities"`
+	Layers             []resolvedLayer    `json:"layers"`
+	PhysicalDevices    []physicalDevice   `json:"physical_devices"`
+}
+
+// deviceCapabilities are the capabilities of a device, see
+// disk.DeviceCapabilities.
+type deviceCapabilities struct {
+	TunableScheduler bool `json:"tunable_scheduler"`
+	TunableReadAhead bool `json:"tunable_read_ahead"`
+	SupportsDiscard  bool `json:"supports_discard"`
+	Rotational       bool `json:"rotational"`
+	Zoned            bool `json:"zoned"`
+	CloudAttached    bool `json:"cloud_attached"`
+	Pseudo           bool `json:"pseudo"`
+}
+
+type resolvedLayer struct {
+	Name   string   `json:"name"`
+	Kind   string   `json:"kind"`
+	Detail string   `json:"detail,omitempty"`
+	Slaves []string `json:"slaves"`
+}
+
+type physicalDevice struct {
+	Name  string `json:"name"`
+	Class string `json:"class"`
+	// Transport is the bus the device is attached through, e.g. 'sata'
+	// or 'usb', 'unknown' if it isn't known.
+	Transport  string `json:"transport"`
+	Rotational bool   `json:"rotational"`
+	Scheduler  string `json:"scheduler"`
+	Discard    bool   `json:"discard"`
+	// DiscardGranularity is in bytes, 0 if discard isn't supported.
+	DiscardGranularity uint64 `json:"discard_granularity"`
+	// LogicalBlockSize and PhysicalBlockSize are in bytes, 0 if the
+	// device doesn't expose them.
+	LogicalBlockSize  uint64 `json:"logical_block_size"`
+	PhysicalBlockSize uint64 `json:"physical_block_size"`
+	// WriteCache is 'write back' or 'write through', empty if the device
+	// doesn't expose it.
+	WriteCache string `json:"write_cache"`
+	// CacheRole is 'cache' or 'backing' for the devices of a bcache or LVM
+	// cache device.
+	CacheRole    string             `json:"cache_role,omitempty"`
+	Capabilities deviceCapabilities `json:"capabilities"`
+	Syspath      string             `json:"syspath"`
+}
+
+func newListDevicesCommand(fs afero.Fs) *cobra.Command {
+	var (
+		directory string
+		format    string
+		timeout   time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "list-devices",
+		Short: "Show how a directory is resolved to the block devices the disk tuners act on",
+		Long: `Show how a directory is resolved to the block devices the disk tuners act on.
+
+The directory is resolved like the disk tuners do: from the number of the
+device holding it, to the device it links to in sysfs, through the partitions,
+device-mapper volumes (including dm-crypt ones, e.g. opened LUKS volumes, and
+multipath ones, whose paths are each tuned) and md arrays it's stacked on, down
+to its physical devices. Each of these steps is printed, along with the class,
+transport (nvme, sata, sas, usb or virtio), rotational flag, I/O scheduler,
+discard granularity, logical/physical block sizes and write cache mode of the
+physical devices. Stacked devices only support discard if all their physical
+devices do.
+
+The capabilities of the device tell which tuners apply to it: whether its
+scheduler and read-ahead are tunable, whether it supports discard, and whether
+it is rotational, zoned, cloud-attached or a pseudo (loop or RAM) device.
+
+Both the caching and the backing devices of bcache and LVM cache (dm-cache,
+dm-writecache) devices are resolved, each tuned for its own class, and their
+role is printed along with their class.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			common.SetOutputFormat(format)
+			if directory == "" {
+				p := config.ParamsFromCommand(cmd)
+				cfg, err := p.Load(fs)
+				out.MaybeDie(err, "unable to load config: %v", err)
+				directories, err := cfg.DataDirectories(nil)
+				out.MaybeDieErr(err)
+				directory = directories[0]
+			}
+
+			irqProcFile := irq.NewProcFile(fs)
+			blockDevices := disk.NewBlockDevices(
+				fs,
+				irq.NewDeviceInfo(fs, irqProcFile),
+				irqProcFile,
+				vos.NewProc(),
+				timeout,
+				disk.SysfsRootFromEnv(),
+			)
+			deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+			resolver := disk.NewDeviceResolver(fs, disk.SysfsRootFromEnv())
+			resolution, err := resolver.ResolvePath(cmd.Context(), directory)
+			out.MaybeDie(err, "unable to resolve the devices of '%s': %v", directory, err)
+			printed, err := newDeviceResolution(cmd.Context(), resolution, deviceFeatures)
+			out.MaybeDieErr(err)
+
+			if format == "json" {
+				asJSON, err := json.MarshalIndent(printed, "", "  ")
+				out.MaybeDie(err, "unable to format the devices as JSON: %v", err)
+				fmt.Println(string(asJSON))
+				return
+			}
+			printDeviceResolution(printed)
+		},
+	}
+	command.Flags().StringVar(&directory,
+		"directory",
+		"",
+		"Directory to resolve, defaults to the redpanda.data_directory of the configuration")
+	command.Flags().StringVar(&format,
+		"format",
+		"text",
+		"Output format (text, json)")
+	command.Flags().DurationVar(&timeout,
+		"timeout",
+		10000*time.Millisecond,
+		"The maximum time to wait for the processes used to detect the block devices")
+	command.Flags().StringVar(
+		new(string),
+		config.FlagConfig,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations.",
+	)
+	return command
+}
+
+func newDeviceResolution(
+	ctx context.Context, resolution *disk.Resolution, deviceFeatures disk.DeviceFeatures,
+) (*deviceResolution, error) {
+	printed := &deviceResolution{
+		Directory:       resolution.Path,
+		DeviceNumber:    fmt.Sprintf("%d:%d", resolution.Major, resolution.Minor),
+		FilesystemType:  resolution.FilesystemType,
+		Syspath:         resolution.Syspath,
+		Device:          deviceName(resolution.Device),
+		DeviceSyspath:   resolution.Device.Syspath(),
+		Encrypted:       resolution.Encrypted,
+		Layers:          []resolvedLayer{},
+		PhysicalDevices: []physicalDevice{},
+	}
+	if partition := resolution.Device.Partition(); partition != nil {
+		printed.Partition = deviceName(partition)
+	}
+	if disk.IsIdentifiedOnly(resolution.Device) {
+		printed.IdentifiedOnly = true
+		return printed, nil
+	}
+	granularity, err := resolution.Device.DiscardGranularity()
+	if err != nil {
+		return nil, err
+	}
+	printed.Discard, printed.DiscardGranularity = granularity > 0, granularity
+	printed.Capabilities = deviceCapabilities(resolution.Device.Capabilities())
+	for _, layer := range resolution.Layers {
+		printed.Layers = append(printed.Layers, resolvedLayer(layer))
+	}
+	for _, device := range resolution.PhysicalDevices {
+		name := deviceName(device)
+		rotational, err := device.IsRotational()
+		if err != nil {
+			return nil, err
+		}
+		scheduler, err := deviceFeatures.GetScheduler(name)
+		if err != nil {
+			log.Debugf("Unable to read the scheduler of '%s': %v", name, err)
+		}
+		granularity, err := device.DiscardGranularity()
+		if err != nil {
+			return nil, err
+		}
+		logical, err := device.LogicalBlockSize()
+		if err != nil {
+			return nil, err
+		}
+		physical, err := device.PhysicalBlockSize()
+		if err != nil {
+			return nil, err
+		}
+		writeCache, err := device.WriteCache()
+		if err != nil {
+			return nil, err
+		}
+		transport, err := device.Transport(ctx)
+		if err != nil {
+			return nil, err
+		}
+		printed.PhysicalDevices = append(printed.PhysicalDevices, physicalDevice{
+			Name:               name,
+			Class:              device.Class().String(),
+			Transport:          transport.String(),
+			Rotational:         rotational,
+			Scheduler:          scheduler,
+			Discard:            granularity > 0,
+			DiscardGranularity: granularity,
+			LogicalBlockSize:   logical,
+			PhysicalBlockSize:  physical,
+			WriteCache:         writeCache,
+			CacheRole:          device.CacheRole(),
+			Capabilities:       deviceCapabilities(device.Capabilities()),
+			Syspath:            device.Syspath(),
+		})
+	}
+	return printed, nil
+}
+
+func printDeviceResolution(resolution *deviceResolution) {
+	tw := out.NewTabWriter()
+	tw.PrintColumn("directory", resolution.Directory)
+	deviceNumber := resolution.DeviceNumber
+	if resolution.FilesystemType != "" {
+		deviceNumber += fmt.Sprintf(" (%s)", resolution.FilesystemType)
+	}
+	tw.PrintColumn("device number", deviceNumber)
+	syspath := resolution.Syspath
+	if syspath == "" {
+		syspath = "-"
+	}
+	tw.PrintColumn("sysfs link", syspath)
+	device := resolution.Device
+	if resolution.Partition != "" {
+		device += fmt.Sprintf(" (holding partition %s)", resolution.Partition)
+	}
+	tw.PrintColumn("device", device)
+	if resolution.IdentifiedOnly {
+		tw.PrintColumn("device syspath", "-, identified from /proc/partitions only, not tunable")
+		tw.Flush()
+		return
+	}
+	tw.PrintColumn("device syspath", resolution.DeviceSyspath)
+	if resolution.Encrypted {
+		tw.PrintColumn("encryption", "dm-crypt, see the crypt stacked devices")
+	}
+	tw.PrintColumn("discard", discardColumn(resolution.Discard, resolution.DiscardGranularity))
+	tw.PrintColumn("capabilities", capabilitiesColumn(resolution.Capabilities))
+	tw.Flush()
+
+	if len(resolution.Layers) > 0 {
+		fmt.Println()
+		out.Section("stacked devices")
+		layers := out.NewTable("name", "kind", "detail", "slaves")
+		for _, layer := range resolution.Layers {
+			layers.Print(layer.Name, layer.Kind, layer.Detail, strings.Join(layer.Slaves, ", "))
+		}
+		layers.Flush()
+	}
+
+	fmt.Println()
+	out.Section("physical devices")
+	physical := out.NewTable("name", "class", "transport", "rotational", "scheduler", "discard", "block size", "write cache", "syspath")
+	for _, device := range resolution.PhysicalDevices {
+		class := device.Class
+		if device.CacheRole != "" {
+			class += fmt.Sprintf(" (%s)", device.CacheRole)
+		}
+		physical.Print(device.Name, class, device.Transport, device.Rotational, device.Scheduler,
+			discardColumn(device.Discard, device.DiscardGranularity),
+			fmt.Sprintf("%d/%d", device.LogicalBlockSize, device.PhysicalBlockSize), device.WriteCache, device.Syspath)
+	}
+	physical.Flush()
+}
+
+// capabilitiesColumn lists the capabilities the device has, e.g.
+// 'tunable scheduler, tunable read-ahead, discard', or 'none'.
+func capabilitiesColumn(caps deviceCapabilities) string {
+	var names []string
+	for _, c := range []struct {
+		name string
+		set  bool
+	}{
+		{"tunable scheduler", caps.TunableScheduler},
+		{"tunable read-ahead", caps.TunableReadAhead},
+		{"discard", caps.SupportsDiscard},
+		{"rotational", caps.Rotational},
+		{"zoned", caps.Zoned},
+		{"cloud-attached", caps.CloudAttached},
+		{"pseudo", caps.Pseudo},
+	} {
+		if c.set {
+			names = append(names, c.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// discardColumn prints the discard granularity, e.g. '4096 bytes', or 'no'
+// if discard isn't supported.
+func discardColumn(discard bool, granularity uint64) string {
+	if !discard {
+		return "no"
+	}
+	return fmt.Sprintf("%d bytes", granularity)
+}
+
+func deviceName(device disk.BlockDevice) string {
+	return strings.TrimPrefix(device.Devnode(), "/dev/")
+}
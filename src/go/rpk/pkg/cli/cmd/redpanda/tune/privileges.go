@@ -0,0 +1,87 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// capSysAdmin is the bit of CAP_SYS_ADMIN in the capability sets listed in
+// /proc/<pid>/status, see capabilities(7).
+const capSysAdmin = 21
+
+// privilegesAdvice is the advice of the tuners skipped for lack of
+// privileges.
+const privilegesAdvice = "the tuners can't write to sysfs and /proc/sys, as in unprivileged " +
+	"containers: run 'rpk redpanda tune' as root on the host, or in a privileged container"
+
+// privilegeProbes returns files representative of the ones the tuners write
+// to. They're opened for writing, but never written to, to tell whether the
+// tuners' writes would succeed: unprivileged containers mount sysfs and
+// /proc/sys read-only.
+func privilegeProbes(sysfsRoot string) []string {
+	return []string{
+		filepath.Join(sysfsRoot, "kernel", "mm", "transparent_hugepage", "enabled"),
+		"/proc/sys/vm/swappiness",
+	}
+}
+
+// insufficientPrivileges returns why the tuners' writes won't succeed, e.g.
+// in an unprivileged container, or an empty string if they would. Only the
+// failures telling that writes are denied count: probes that are missing or
+// fail otherwise are left to the tuners to report.
+func insufficientPrivileges(fs afero.Fs, sysfsRoot string) string {
+	capEff, err := effectiveCapabilities(fs)
+	if err != nil {
+		log.Debugf("Unable to read the effective capabilities: %v", err)
+	} else if capEff&(1<<capSysAdmin) == 0 {
+		return fmt.Sprintf("insufficient privileges: CAP_SYS_ADMIN is not effective (CapEff %016x)", capEff)
+	}
+	for _, probe := range privilegeProbes(sysfsRoot) {
+		f, err := fs.OpenFile(probe, os.O_WRONLY, 0)
+		if err == nil {
+			f.Close()
+			continue
+		}
+		if errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS) {
+			return fmt.Sprintf("insufficient privileges: '%s' is not writable: %v", probe, err)
+		}
+		log.Debugf("Unable to probe whether '%s' is writable: %v", probe, err)
+	}
+	return ""
+}
+
+// effectiveCapabilities returns the effective capability set of rpk, as
+// listed by the 'CapEff' line of /proc/self/status.
+func effectiveCapabilities(fs afero.Fs) (uint64, error) {
+	lines, err := utils.ReadFileLines(fs, "/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "CapEff:") {
+			continue
+		}
+		return strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+	}
+	return 0, errors.New("no CapEff in /proc/self/status")
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsufficientPrivileges(t *testing.T) {
+	const (
+		// All the capabilities, as for root on the host.
+		capRoot = "000001ffffffffff"
+		// The default capabilities of Docker containers.
+		capDocker = "00000000a80425fb"
+	)
+	writeStatus := func(fs afero.Fs, capEff string) {
+		afero.WriteFile(fs, "/proc/self/status", []byte("Name:\trpk\nCapInh:\t0000000000000000\nCapPrm:\t"+capEff+"\nCapEff:\t"+capEff+"\n"), 0o644)
+	}
+	writeProbes := func(fs afero.Fs) {
+		for _, probe := range privilegeProbes("/sys") {
+			afero.WriteFile(fs, probe, []byte("0\n"), 0o644)
+		}
+	}
+	tests := []struct {
+		name      string
+		fs        func() afero.Fs
+		expReason string
+	}{
+		{
+			name: "privileged",
+			fs: func() afero.Fs {
+				fs := afero.NewMemMapFs()
+				writeStatus(fs, capRoot)
+				writeProbes(fs)
+				return fs
+			},
+		},
+		{
+			name: "missing CAP_SYS_ADMIN",
+			fs: func() afero.Fs {
+				fs := afero.NewMemMapFs()
+				writeStatus(fs, capDocker)
+				writeProbes(fs)
+				return fs
+			},
+			expReason: "insufficient privileges: CAP_SYS_ADMIN is not effective (CapEff 00000000a80425fb)",
+		},
+		{
+			name: "read-only sysfs",
+			fs: func() afero.Fs {
+				fs := afero.NewMemMapFs()
+				writeStatus(fs, capRoot)
+				writeProbes(fs)
+				return afero.NewReadOnlyFs(fs)
+			},
+			expReason: "insufficient privileges: '/sys/kernel/mm/transparent_hugepage/enabled' is not writable",
+		},
+		{
+			name: "missing probes and status",
+			fs:   afero.NewMemMapFs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := insufficientPrivileges(tt.fs(), "/sys")
+			if tt.expReason == "" {
+				require.Empty(t, reason)
+				return
+			}
+			require.Contains(t, reason, tt.expReason)
+		})
+	}
+}
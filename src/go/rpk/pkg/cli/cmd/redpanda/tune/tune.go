@@ -12,18 +12,28 @@
 package tune
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/ui"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/factory"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/hwloc"
 	log "github.com/sirupsen/logrus"
@@ -37,15 +47,50 @@ type result struct {
 	enabled   bool
 	supported bool
 	errMsg    string
+	// advice is how to fix the failure of a disk tuner to resolve its
+	// devices, if that's why it failed.
+	advice         string
+	failed         bool
+	rebootRequired bool
+	devices        []string
+	deviceResults  []tuners.DeviceTuneResult
 }
 
+// jsonResult is the result of a tuner as printed with '--format json'.
+type jsonResult struct {
+	Name           string   `json:"name"`
+	Status         string   `json:"status"`
+	Enabled        bool     `json:"enabled"`
+	Supported      bool     `json:"supported"`
+	RebootRequired bool     `json:"reboot_required"`
+	Devices        []string `json:"devices"`
+	Error          string   `json:"error"`
+	Advice         string   `json:"advice,omitempty"`
+	// DeviceResults are the results of each device of the disk tuners.
+	DeviceResults []tuners.DeviceTuneResult `json:"device_results,omitempty"`
+	// DeviceGroups are the same results, grouped by the devices holding
+	// the data directories.
+	DeviceGroups []tuners.DeviceGroup `json:"device_groups,omitempty"`
+}
+
+const (
+	statusApplied = "applied"
+	statusSkipped = "skipped"
+	statusFailed  = "failed"
+)
+
 func NewCommand(fs afero.Fs) *cobra.Command {
 	tunerParams := factory.TunerParams{}
 	var (
-		configFile        string
 		outTuneScriptFile string
 		cpuSet            string
 		timeout           time.Duration
+		dryRun            bool
+		revert            bool
+		snapshotFile      string
+		format            string
+		metricsFile       string
+		readRetries       = disk.DefaultRetryPolicy
 	)
 	baseMsg := "Sets the OS parameters to tune system performance"
 	longMsg := fmt.Sprintf(`Sets the OS parameters to tune system performance.
@@ -56,12 +101,70 @@ Available tuners:
   - %s
 
 To learn more about a tuner, run 'rpk redpanda tune help <tuner name>'.
-`, strings.Join(factory.AvailableTuners(), "\n  - "))
+
+The disk tuners act on the devices of the redpanda.data_directory of the
+configuration, unless data directories are given with --dirs or devices with
+--disk-devices, by name, or --disk-major-minor, by device numbers. Flags take
+precedence over the configuration, and tuning fails if no data directory is
+set in either.
+
+Directories holding other data, e.g. rpk.coredump_dir or a WAL on another disk,
+are tuned too when given with '--directory', which can be repeated. Their
+devices are tuned once even when shared with the data directories, and the
+DEVICES section of the results lists the directories each device holds.
+
+Disk tuners look up block devices in the sysfs mounted at /sys. When running
+in a container with the host sysfs mounted elsewhere, set %s to
+its mount point, e.g. '/host/sys'. When the devices can't be resolved, the
+results advise on whether sysfs is missing, as in containers, or the host is
+misconfigured, e.g. a data directory that isn't on a block device.
+
+The tuners are skipped, and the tuning fails, if their writes wouldn't
+succeed: if rpk lacks CAP_SYS_ADMIN or sysfs and /proc/sys are read-only, as in
+unprivileged containers. Their results read 'insufficient privileges' rather
+than reporting values that were never written. Dry runs and '--output-script'
+don't write to the system and aren't checked.
+
+As tuning many devices can take a while, the disk tuners log their progress:
+the devices they resolved, and when each of them is tuned, with the time it
+took. The progress isn't logged with '--format json'.
+
+With '--format json', the results of the disk tuners list the result of each
+of their devices in 'device_results': its 'tuner', 'device', 'syspath',
+'status' (applied, skipped or failed), 'previous_value', 'new_value', and the
+'reason' it was skipped or the 'error' it failed with. Combined with
+'--dry-run', it's a plan of the changes to review before tuning: the devices
+that would be changed are skipped with the reason 'dry run', along with their
+current and new values. Dry runs read sysfs but never write to it.
+
+The device results are also grouped by the device holding the data
+directories, e.g. an md array or an LVM volume, then by each of the leaf
+devices it resolves to: in 'device_groups' with '--format json', each with its
+'device', 'directories', its own 'results' and the 'results' of its
+'members', and in a DEVICES section of the text output.
+
+The values the tuners overwrite are recorded in the snapshot file, along with
+the device they belong to, the first time they're changed. '--revert' restores
+them; values the tuners wrote without changing aren't recorded, and those of
+devices that no longer exist are skipped. The restored values are then removed
+from the snapshot, so reverting again is a no-op and tuning again records the
+values in place by then.
+
+'--metrics' writes the tuning state of the disks once tuned, e.g. whether
+their scheduler is the preferred one, as Prometheus gauges to the given file,
+or to stdout with '-', which can't be combined with '--format json'. Pointed at
+the directory of the textfile collector of node_exporter, e.g.
+'/var/lib/node_exporter/redpanda_disk.prom', it lets the fleet be monitored for
+untuned disks.
+`, strings.Join(factory.AvailableTuners(), "\n  - "), disk.SysfsRootEnv)
 	command := &cobra.Command{
 		Use:   "tune <list of elements to tune>",
 		Short: baseMsg,
 		Long:  longMsg,
 		Args: func(cmd *cobra.Command, args []string) error {
+			if revert {
+				return nil
+			}
 			if len(args) < 1 {
 				return errors.New("requires the list of elements to tune")
 			}
@@ -79,32 +182,89 @@ To learn more about a tuner, run 'rpk redpanda tune help <tuner name>'.
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if !tunerParamsEmpty(&tunerParams) && configFile != "" {
-				out.Die("use either tuner params or redpanda config file")
+			if len(tunerParams.DiskDevices)+len(tunerParams.DiskNumbers) > 0 && len(tunerParams.Directories) > 0 {
+				out.Die("use either --disk-devices and --disk-major-minor, or --dirs")
+			}
+			if dryRun && outTuneScriptFile != "" {
+				out.Die("use either --dry-run or --output-script")
+			}
+			common.SetOutputFormat(format)
+			if metricsFile == "-" && format == "json" {
+				out.Die("--metrics - can't be used with --format json, both are written to stdout")
 			}
-			var tuners []string
+			if revert {
+				err := revertTuning(fs, snapshotFile)
+				out.MaybeDie(err, "unable to revert tuning: %v", err)
+				return
+			}
+			var tunerNames []string
 			p := config.ParamsFromCommand(cmd)
 			if args[0] == "all" {
-				tuners = factory.AvailableTuners()
+				tunerNames = factory.AvailableTuners()
 			} else {
-				tuners = strings.Split(args[0], ",")
+				tunerNames = strings.Split(args[0], ",")
 			}
 			cpuMask, err := hwloc.TranslateToHwLocCPUSet(cpuSet)
 			out.MaybeDieErr(err)
 
+			// Interrupting stops the tuning before its next change, the
+			// changes made so far are still recorded for --revert.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// The tuners and checkers share the sysfs attributes of the
+			// devices, which are read once for the whole run, retrying the
+			// reads failing while the link of fabric-attached devices flaps.
+			sysfsRoot := disk.SysfsRootFromEnv()
+			sysfs := disk.NewAttributeCache(disk.NewRetryingFs(fs, sysfsRoot, readRetries), sysfsRoot)
+
 			tunerParams.CPUMask = cpuMask
+			err = factory.ResolveDiskDevices(ctx, sysfs, &tunerParams)
+			if advice := resolveAdvice(err); advice != "" {
+				out.Die("%v\n%s", err, advice)
+			}
+			out.MaybeDieErr(err)
 			cfg, err := p.Load(fs)
 			out.MaybeDie(err, "unable to load config: %v", err)
-			var tunerFactory factory.TunersFactory
-			if outTuneScriptFile != "" {
+			var (
+				tunerFactory factory.TunersFactory
+				recorder     executors.RecordingExecutor
+			)
+			tunerParams.DryRun = dryRun
+			if tunerParams.DryRun {
+				recorder = executors.NewDryRunExecutor()
+				tunerFactory = factory.NewRecordingTunersFactory(
+					sysfs, *cfg, recorder, timeout)
+			} else if outTuneScriptFile != "" {
 				tunerFactory = factory.NewScriptRenderingTunersFactory(
-					fs, *cfg, outTuneScriptFile, timeout)
+					sysfs, *cfg, outTuneScriptFile, timeout)
 			} else {
-				tunerFactory = factory.NewDirectExecutorTunersFactory(
-					fs, *cfg, timeout)
+				// We keep track of the values overwritten by the tuners
+				// so they can be restored with --revert.
+				recorder = executors.NewRecordingExecutor(executors.NewDirectExecutor())
+				tunerFactory = factory.NewRecordingTunersFactory(
+					sysfs, *cfg, recorder, timeout)
+			}
+			// Dry runs and scripts don't write to the system, the tuning is
+			// otherwise skipped if its writes wouldn't succeed, rather than
+			// reporting changes that silently failed.
+			var unprivileged string
+			if !dryRun && outTuneScriptFile == "" {
+				unprivileged = insufficientPrivileges(fs, sysfsRoot)
+			}
+			exit1, err := tune(ctx, cfg, tunerNames, tunerFactory, &tunerParams, recorder, format, unprivileged)
+			if recorder != nil && !dryRun {
+				// The tuning is done, failing to record it only loses
+				// the ability to revert it.
+				if snapshotErr := recordSnapshot(fs, snapshotFile, recorder.Changes()); snapshotErr != nil {
+					log.Warnf("Unable to record the tuned values in '%s', they can't be reverted: %v", snapshotFile, snapshotErr)
+				}
 			}
-			exit1, err := tune(cfg, tuners, tunerFactory, &tunerParams)
 			out.MaybeDieErr(err)
+			if metricsFile != "" {
+				err := writeDiskMetrics(sysfs, metricsFile, &tunerParams, timeout)
+				out.MaybeDie(err, "unable to write the disk metrics: %v", err)
+			}
 			if exit1 {
 				os.Exit(1)
 			}
@@ -116,7 +276,7 @@ To learn more about a tuner, run 'rpk redpanda tune help <tuner name>'.
 		"all",
 		"Set of CPUs for tuner to use in cpuset(7) format if not specified tuner will use all available CPUs")
 	command.Flags().StringVar(
-		&configFile,
+		new(string),
 		config.FlagConfig,
 		"",
 		"Redpanda config file, if not set the file will be searched for in the default locations.",
@@ -125,6 +285,34 @@ To learn more about a tuner, run 'rpk redpanda tune help <tuner name>'.
 		"output-script",
 		"",
 		"If set tuners will generate tuning file that can later be used to tune the system")
+	command.Flags().BoolVar(&dryRun,
+		"dry-run",
+		false,
+		"If set tuners will print the changes they would make to the system without applying them")
+	command.Flags().BoolVar(&revert,
+		"revert",
+		false,
+		"Restore the values overwritten by previous tune runs, as recorded in the snapshot file")
+	command.Flags().StringVar(&format,
+		"format",
+		"text",
+		"Output format (text, json)")
+	command.Flags().StringVar(&snapshotFile,
+		"snapshot-file",
+		tuners.DefaultSnapshotFile,
+		"File where the values overwritten by the tuners are recorded, and restored from with --revert")
+	command.Flags().StringVar(&metricsFile,
+		"metrics",
+		"",
+		"File to write the tuning state of the disks to as Prometheus metrics, '-' for stdout")
+	command.Flags().IntVar(&readRetries.Attempts,
+		"sysfs-read-attempts",
+		readRetries.Attempts,
+		"Number of times the sysfs attributes failing to read with EIO or EAGAIN are read before giving up")
+	command.Flags().DurationVar(&readRetries.Delay,
+		"sysfs-read-delay",
+		readRetries.Delay,
+		"Delay before retrying the read of a sysfs attribute, doubled on each retry")
 	command.Flags().DurationVar(
 		&timeout,
 		"timeout",
@@ -145,6 +333,10 @@ To learn more about a tuner, run 'rpk redpanda tune help <tuner name>'.
 
 	command.AddCommand(newHelpCommand())
 	command.AddCommand(newListCommand(fs))
+	command.AddCommand(newDiskBenchmarkCommand(fs))
+	command.AddCommand(newDiskIoTuneCommand(fs))
+	command.AddCommand(newListDevicesCommand(fs))
+	command.AddCommand(newCapabilitiesCommand(fs))
 	return command
 }
 
@@ -158,23 +350,45 @@ func addTunerParamsFlags(command *cobra.Command, tunerParams *factory.TunerParam
 	command.Flags().StringSliceVarP(&tunerParams.Nics,
 		"nic", "n",
 		[]string{}, "Network Interface Controllers to tune")
+	command.Flags().StringSliceVar(&tunerParams.DiskDevices,
+		"disk-devices",
+		[]string{}, "Lists of block devices to tune instead of the devices of the data"+
+			" directories, by device node or name, i.e.: '/dev/nvme0n1,nvme1n1,/dev/mapper/vg0-data'."+
+			" Use it when the devices of the data directories can't be detected, e.g. on multipath setups.")
+	command.Flags().StringSliceVar(&tunerParams.DiskNumbers,
+		"disk-major-minor",
+		[]string{}, "Lists of block devices to tune instead of the devices of the data"+
+			" directories, by major and minor numbers, i.e.: '259:0,8:16'. Can be combined with --disk-devices")
+	command.Flags().StringToStringVar(&tunerParams.SchedulerOverrides,
+		"scheduler",
+		nil, "I/O scheduler to set on a device in place of the one preferred for its"+
+			" device class, as <device>=<scheduler>, e.g. 'nvme0n1=kyber'. Repeatable;"+
+			" the scheduler must be one the device supports")
 	command.Flags().StringSliceVarP(&tunerParams.Directories,
 		"dirs", "r",
 		[]string{}, "List of *data* directories or places to store data,"+
 			" i.e.: '/var/vectorized/redpanda/',"+
 			" usually your XFS filesystem on an NVMe SSD device.")
+	command.Flags().StringArrayVar(&tunerParams.AdditionalDirectories,
+		"directory",
+		[]string{}, "Directory holding other data than the data directories, e.g. the"+
+			" coredumps or the WAL, whose device is tuned along with theirs. Repeatable")
 	command.Flags().BoolVar(&tunerParams.RebootAllowed,
 		"reboot-allowed", false, "If set will allow tuners to tune boot parameters"+
 			" and request system reboot.")
 }
 
 func tune(
+	ctx context.Context,
 	conf *config.Config,
 	tunerNames []string,
 	tunersFactory factory.TunersFactory,
 	params *factory.TunerParams,
+	recorder executors.RecordingExecutor,
+	format string,
+	unprivileged string,
 ) (bool, error) {
-	params, err := factory.MergeTunerParamsConfig(params, conf)
+	params, err := factory.MergeTunerParamsConfig(params, conf, tunerNames)
 	if err != nil {
 		return false, err
 	}
@@ -182,39 +396,121 @@ func tune(
 		rebootRequired, includeErr, exit1 bool
 		results                           []result
 		allDisabled                       = true
+		changes                           = map[string][]commands.Change{}
+		reporter                          = tuners.NewLogReporter()
 	)
+	if format == "json" {
+		// Only the results are printed in JSON mode.
+		reporter = tuners.NopReporter{}
+	}
 
 	for _, tunerName := range tunerNames {
 		enabled := factory.IsTunerEnabled(tunerName, conf.Rpk)
+		if err := ctx.Err(); err != nil {
+			// The tuners not run yet are reported as failed.
+			results = append(results, result{
+				name:      tunerName,
+				enabled:   enabled,
+				supported: true,
+				errMsg:    fmt.Sprintf("tuning cancelled: %v", err),
+				failed:    true,
+			})
+			includeErr, exit1 = true, true
+			continue
+		}
 		allDisabled = allDisabled && !enabled
 		tuner := tunersFactory.CreateTuner(tunerName, params)
 		supported, reason := tuner.CheckIfSupported()
 		if !enabled || !supported {
 			includeErr = includeErr || !supported
-			results = append(results, result{tunerName, false, enabled, supported, reason})
+			res := result{
+				name:      tunerName,
+				enabled:   enabled,
+				supported: supported,
+				errMsg:    reason,
+			}
+			if supported {
+				res.devices = tunerDevices(tunerName, tuner)
+			} else {
+				res.advice = unsupportedAdvice(tuner)
+			}
+			results = append(results, res)
 			// We exit with code 1 when it's enabled and not supported except
 			// for disk_write_cache since it's only supported for GCP.
 			// We also allow clocksource to fail, see #6444.
 			exit1 = exit1 || enabled && !supported && !(tunerName == "disk_write_cache" || tunerName == "clocksource")
 			continue
 		}
+		if unprivileged != "" {
+			// Enabled tuners that can't write are skipped, failing the
+			// run: nothing of what they'd report as applied would be.
+			results = append(results, result{
+				name:      tunerName,
+				enabled:   enabled,
+				supported: supported,
+				errMsg:    unprivileged,
+				advice:    privilegesAdvice,
+				devices:   tunerDevices(tunerName, tuner),
+			})
+			includeErr, exit1 = true, true
+			continue
+		}
 		log.Debugf("Tuner parameters %+v", params)
-		res := tuner.Tune()
+		var recorded int
+		if recorder != nil {
+			recorded = len(recorder.Changes())
+		}
+		res := tuner.Tune(tuners.WithReporter(ctx, reporter, tunerName))
+		if recorder != nil {
+			tunerChanges := recorder.Changes()[recorded:]
+			if !params.DryRun {
+				// Devices are tuned concurrently, the changes are sorted
+				// so that the report doesn't depend on their timing.
+				sort.SliceStable(tunerChanges, func(i, j int) bool {
+					return tunerChanges[i].Path < tunerChanges[j].Path
+				})
+			}
+			changes[tunerName] = tunerChanges
+		}
 		includeErr = includeErr || res.IsFailed()
 		rebootRequired = rebootRequired || res.IsRebootRequired()
-		errMsg := ""
+		errMsg, advice := "", ""
 		if res.IsFailed() {
 			errMsg = res.Error().Error()
+			advice = resolveAdvice(res.Error())
 			exit1 = true
+		} else if reason := res.NotAppliedReason(); reason != "" {
+			errMsg = reason
+			includeErr = true
 		}
-		results = append(results, result{tunerName, !res.IsFailed(), enabled, supported, errMsg})
+		applied := !res.IsFailed() && res.NotAppliedReason() == "" && !params.DryRun
+		results = append(results, result{
+			name:           tunerName,
+			applied:        applied,
+			enabled:        enabled,
+			supported:      supported,
+			errMsg:         errMsg,
+			advice:         advice,
+			failed:         res.IsFailed(),
+			rebootRequired: res.IsRebootRequired(),
+			devices:        tunerDevices(tunerName, tuner),
+			deviceResults:  tunerDeviceResults(tuner, tunerName, res, changes[tunerName], params.DryRun),
+		})
+	}
+
+	stacks := deviceStacks(ctx, tunersFactory, params, results)
+	if format == "json" {
+		return exit1, printJSONTuneResult(results, stacks)
 	}
 
 	if allDisabled {
 		fmt.Println("All tuners were disabled, so none were applied. You may run `rpk redpanda mode prod` to enable the recommended set of tuners for non-containerized production use.")
 	}
 
+	printChanges(tunerNames, changes)
 	printTuneResult(results, includeErr)
+	printDeviceGroups(os.Stdout, results, stacks)
+	printAdvice(results)
 
 	if rebootRequired {
 		red := color.New(color.FgRed).SprintFunc()
@@ -227,10 +523,269 @@ func tune(
 	return exit1, nil
 }
 
-func tunerParamsEmpty(params *factory.TunerParams) bool {
-	return len(params.Directories) == 0 &&
-		len(params.Disks) == 0 &&
-		len(params.Nics) == 0
+// resolveAdvice returns how to fix the failure to resolve block devices err
+// wraps, telling a sysfs missing from the environment, as in containers, from
+// a misconfiguration of the host, and from directories on filesystems
+// without a block device. It returns an empty string if err isn't a failure
+// to resolve block devices, or if there's nothing to advise.
+func resolveAdvice(err error) string {
+	var notBlockErr *disk.NotBlockDeviceError
+	if errors.As(err, &notBlockErr) {
+		return fmt.Sprintf("'%s' is on %s, which no block device backs: disk tuning "+
+			"is not applicable to it, move it to a local disk to tune it", notBlockErr.Path, notBlockErr.FsType)
+	}
+	var resolveErr *disk.DeviceResolveError
+	if !errors.As(err, &resolveErr) {
+		return ""
+	}
+	switch resolveErr.Kind {
+	case disk.ResolveErrorSysfsUnavailable:
+		return fmt.Sprintf("sysfs is not available, as in most containers: tune the disks "+
+			"from the host, or mount the host sysfs in the container and set %s to "+
+			"its mount point, e.g. '/host/sys'", disk.SysfsRootEnv)
+	case disk.ResolveErrorPermissionDenied:
+		return "sysfs can't be read with the current permissions: run the disk tuners as root"
+	case disk.ResolveErrorNotFound:
+		return "the device is missing from sysfs: check that the data directories " +
+			"exist and live on block devices, or that the --disk-devices exist"
+	case disk.ResolveErrorMalformed:
+		return fmt.Sprintf("sysfs has unexpected content: check that %s, if set, "+
+			"points to a sysfs mount", disk.SysfsRootEnv)
+	case disk.ResolveErrorUnsupportedStack:
+		return "the devices are stacked in a way the disk tuners can't resolve: " +
+			"tune the physical devices directly with --disk-devices"
+	default:
+		return ""
+	}
+}
+
+// unsupportedAdvice returns how to fix the failure of a disk tuner to resolve
+// its devices, if that's why it's unsupported: the reason of unsupported
+// tuners is only a message, the devices are resolved again for the error.
+func unsupportedAdvice(tuner tuners.Tunable) string {
+	deviceTuner, ok := tuner.(tuners.DeviceTunable)
+	if !ok {
+		return ""
+	}
+	_, err := deviceTuner.Devices()
+	return resolveAdvice(err)
+}
+
+// printAdvice prints the advice of the results, each advice once.
+func printAdvice(results []result) {
+	yellow := color.New(color.FgYellow).SprintFunc()
+	printed := map[string]bool{}
+	for _, res := range results {
+		if res.advice == "" || printed[res.advice] {
+			continue
+		}
+		printed[res.advice] = true
+		fmt.Printf("%s: %s\n", yellow("ADVICE"), res.advice)
+	}
+}
+
+// tunerDevices returns the physical devices the tuner acts on, if it acts on
+// block devices.
+func tunerDevices(tunerName string, tuner tuners.Tunable) []string {
+	deviceTuner, ok := tuner.(tuners.DeviceTunable)
+	if !ok {
+		return nil
+	}
+	// The devices resolved are returned even if some couldn't be.
+	devices, err := deviceTuner.Devices()
+	if err != nil {
+		log.Debugf("Unable to get the devices of tuner '%s': %v", tunerName, err)
+	}
+	return devices
+}
+
+// deviceStacks returns the device stacks the results of the disk tuners are
+// grouped by, if any tuner reported the results of its devices. Failing to
+// resolve them only leaves the results ungrouped: it's up to the tuners to
+// report why the devices can't be resolved.
+func deviceStacks(
+	ctx context.Context,
+	tunersFactory factory.TunersFactory,
+	params *factory.TunerParams,
+	results []result,
+) []tuners.DeviceStack {
+	var hasDeviceResults bool
+	for _, res := range results {
+		hasDeviceResults = hasDeviceResults || len(res.deviceResults) > 0
+	}
+	if !hasDeviceResults {
+		return nil
+	}
+	stacks, err := tunersFactory.DeviceStacks(ctx, params)
+	if err != nil {
+		log.Debugf("Unable to get the device stacks, leaving the device results ungrouped: %v", err)
+		return nil
+	}
+	return stacks
+}
+
+// printDeviceGroups prints the results of the devices of the disk tuners,
+// by the device holding the data directories, then by each of its members.
+func printDeviceGroups(w io.Writer, results []result, stacks []tuners.DeviceStack) {
+	var deviceResults []tuners.DeviceTuneResult
+	for _, res := range results {
+		deviceResults = append(deviceResults, res.deviceResults...)
+	}
+	if len(deviceResults) == 0 {
+		return
+	}
+	tw := out.NewTabWriterTo(w)
+	defer tw.Flush()
+	printResults := func(indent string, results []tuners.DeviceTuneResult) {
+		for _, r := range results {
+			tw.Print(indent+r.Tuner, r.Status, deviceResultDetail(r))
+		}
+	}
+	tw.Line("DEVICES")
+	for _, group := range tuners.GroupDeviceResults(stacks, deviceResults) {
+		if len(group.Directories) > 0 {
+			tw.Line(fmt.Sprintf("%s (%s)", group.Device, strings.Join(group.Directories, ", ")))
+		} else {
+			tw.Line(group.Device)
+		}
+		printResults("  ", group.Results)
+		for _, member := range group.Members {
+			tw.Line("  " + member.Device)
+			printResults("    ", member.Results)
+		}
+	}
+}
+
+// deviceResultDetail returns the change of the device, or why it was
+// skipped or failed.
+func deviceResultDetail(r tuners.DeviceTuneResult) string {
+	switch {
+	case r.Error != "":
+		return r.Error
+	case r.Reason != "":
+		return r.Reason
+	case r.Previous != "" && r.New != "":
+		return fmt.Sprintf("%s -> %s", r.Previous, r.New)
+	default:
+		return r.New
+	}
+}
+
+// tunerDeviceResults returns the result of each device of the tuner, if it
+// acts on block devices. The values of the devices changed are the ones of
+// the recorded changes of the files the tuner writes to for them, see
+// tuners.FileTunable. Devices aren't changed on dry runs, their changes are
+// reported as skipped.
+func tunerDeviceResults(
+	tuner tuners.Tunable,
+	tunerName string,
+	res tuners.TuneResult,
+	changes []commands.Change,
+	dryRun bool,
+) []tuners.DeviceTuneResult {
+	deviceResults, ok := res.(tuners.DeviceTuneResults)
+	if !ok {
+		return nil
+	}
+	results := deviceResults.DeviceResults()
+	for i := range results {
+		results[i].Tuner = tunerName
+		fileTuner, isFileTuner := tuner.(tuners.FileTunable)
+		if results[i].Status == tuners.DeviceApplied && isFileTuner && len(changes) > 0 {
+			files, err := fileTuner.TunableFiles(results[i].Device)
+			if err != nil {
+				log.Debugf("Unable to get the files %s writes to for '%s': %v", tunerName, results[i].Device, err)
+			}
+			for _, change := range changes {
+				for _, file := range files {
+					if change.Path == file {
+						results[i].Previous, results[i].New = change.Current, change.Proposed
+					}
+				}
+			}
+		}
+		if dryRun && results[i].Status == tuners.DeviceApplied {
+			results[i].Status, results[i].Reason = tuners.DeviceSkipped, "dry run"
+		}
+	}
+	return results
+}
+
+// recordSnapshot adds the values overwritten by the given changes to the
+// snapshot file.
+func recordSnapshot(fs afero.Fs, file string, changes []commands.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	snapshot, err := tuners.ReadSnapshot(fs, file)
+	if err != nil {
+		return err
+	}
+	snapshot.Record(changes)
+	return tuners.WriteSnapshot(fs, file, snapshot)
+}
+
+func revertTuning(fs afero.Fs, file string) error {
+	// The snapshot is removed once all of its values are restored.
+	if exists, _ := afero.Exists(fs, file); !exists {
+		fmt.Printf("No tuned values recorded in '%s', nothing to revert.\n", file)
+		return nil
+	}
+	snapshot, err := tuners.ReadSnapshot(fs, file)
+	if err != nil {
+		return err
+	}
+	changes, err := snapshot.Revert(fs, executors.NewDirectExecutor())
+	for _, change := range changes {
+		fmt.Printf("Restored '%s' from '%s' to '%s'\n", change.Path, change.Current, change.Proposed)
+	}
+	// The restored values are pruned, so that tuning again records the
+	// values in place from then on.
+	if pruneErr := pruneSnapshot(fs, file, snapshot); pruneErr != nil {
+		log.Warnf("Unable to prune the tune snapshot '%s': %v", file, pruneErr)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("All the recorded values are already in place, nothing to revert.")
+	}
+	return nil
+}
+
+// pruneSnapshot stores the values of the snapshot left to restore, removing
+// the file if there are none.
+func pruneSnapshot(fs afero.Fs, file string, snapshot *tuners.Snapshot) error {
+	if len(snapshot.Values) == 0 {
+		return fs.Remove(file)
+	}
+	return tuners.WriteSnapshot(fs, file, snapshot)
+}
+
+// printChanges prints the changes each tuner made, or would make in dry-run
+// mode, to the system as a diff of the files they wrote.
+func printChanges(tunerNames []string, changes map[string][]commands.Change) {
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	for _, tunerName := range tunerNames {
+		tunerChanges := changes[tunerName]
+		if len(tunerChanges) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", tunerName)
+		for _, change := range tunerChanges {
+			if change.Path == "" {
+				fmt.Printf("%s\n", green("+ "+change.Proposed))
+				continue
+			}
+			fmt.Printf("--- %s\n", change.Path)
+			if change.Current != "" {
+				fmt.Printf("%s\n", red("- "+change.Current))
+			}
+			fmt.Printf("%s\n", green("+ "+change.Proposed))
+		}
+		fmt.Println()
+	}
 }
 
 func printTuneResult(results []result, includeErr bool) {
@@ -277,6 +832,47 @@ func printTuneResult(results []result, includeErr bool) {
 	t.Render()
 }
 
+func printJSONTuneResult(results []result, stacks []tuners.DeviceStack) error {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].name < results[j].name
+	})
+	jsonResults := []jsonResult{}
+	for _, res := range results {
+		status := statusSkipped
+		if res.failed {
+			status = statusFailed
+		} else if res.applied {
+			status = statusApplied
+		}
+		devices := res.devices
+		if devices == nil {
+			devices = []string{}
+		}
+		jr := jsonResult{
+			Name:           res.name,
+			Status:         status,
+			Enabled:        res.enabled,
+			Supported:      res.supported,
+			RebootRequired: res.rebootRequired,
+			Devices:        devices,
+			Error:          res.errMsg,
+			Advice:         res.advice,
+			DeviceResults:  res.deviceResults,
+		}
+		if len(res.deviceResults) > 0 {
+			jr.DeviceGroups = tuners.GroupDeviceResults(stacks, res.deviceResults)
+		}
+		jsonResults = append(jsonResults, jr)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(jsonResults); err != nil {
+		return fmt.Errorf("unable to format the results as JSON: %v", err)
+	}
+	return nil
+}
+
 func colorRow(c func(...interface{}) string, row []string) []string {
 	for i, s := range row {
 		row[i] = c(s)
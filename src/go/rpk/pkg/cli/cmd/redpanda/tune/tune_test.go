@@ -0,0 +1,166 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package tune
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAdvice(t *testing.T) {
+	resolveErr := func(kind disk.ResolveErrorKind) error {
+		err := &disk.DeviceResolveError{Path: "/sys/dev/block/8:0", Op: "resolve", Kind: kind, Err: errors.New("failed")}
+		return fmt.Errorf("invalid disk device '/dev/sda': %w", err)
+	}
+	tests := []struct {
+		name      string
+		err       error
+		expAdvice string
+	}{
+		{
+			name:      "sysfs unavailable",
+			err:       resolveErr(disk.ResolveErrorSysfsUnavailable),
+			expAdvice: "sysfs is not available, as in most containers",
+		},
+		{
+			name:      "permission denied",
+			err:       resolveErr(disk.ResolveErrorPermissionDenied),
+			expAdvice: "run the disk tuners as root",
+		},
+		{
+			name:      "device not found",
+			err:       resolveErr(disk.ResolveErrorNotFound),
+			expAdvice: "the device is missing from sysfs",
+		},
+		{
+			name:      "unsupported stack",
+			err:       resolveErr(disk.ResolveErrorUnsupportedStack),
+			expAdvice: "tune the physical devices directly",
+		},
+		{
+			name:      "not a block device",
+			err:       fmt.Errorf("unable to get the devices of '/var/lib/redpanda/data': %w", &disk.NotBlockDeviceError{Path: "/var/lib/redpanda/data", FsType: "tmpfs"}),
+			expAdvice: "'/var/lib/redpanda/data' is on tmpfs, which no block device backs: disk tuning is not applicable",
+		},
+		{
+			name: "unknown failure",
+			err:  resolveErr(disk.ResolveErrorUnknown),
+		},
+		{
+			name: "not a resolve error",
+			err:  errors.New("failed"),
+		},
+		{
+			name: "no error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			advice := resolveAdvice(tt.err)
+			if tt.expAdvice == "" {
+				require.Empty(t, advice)
+				return
+			}
+			require.Contains(t, advice, tt.expAdvice)
+		})
+	}
+}
+
+func TestPrintDeviceGroups(t *testing.T) {
+	results := []result{
+		{name: "disk_read_ahead", deviceResults: []tuners.DeviceTuneResult{
+			{Tuner: "disk_read_ahead", Device: "md0", Status: tuners.DeviceApplied, Previous: "128", New: "4096"},
+		}},
+		{name: "disk_scheduler", deviceResults: []tuners.DeviceTuneResult{
+			{Tuner: "disk_scheduler", Device: "sda", Status: tuners.DeviceApplied, Previous: "mq-deadline", New: "none"},
+			{Tuner: "disk_scheduler", Device: "sdb", Status: tuners.DeviceSkipped, Reason: "already tuned"},
+		}},
+		{name: "swappiness"},
+	}
+	stacks := []tuners.DeviceStack{{Directory: "/var/lib/redpanda/data", Devices: []string{"md0", "sda", "sdb"}}}
+	var b bytes.Buffer
+	printDeviceGroups(&b, results, stacks)
+	require.Equal(t, `DEVICES
+md0 (/var/lib/redpanda/data)
+  disk_read_ahead  applied  128 -> 4096
+  sda
+    disk_scheduler  applied  mq-deadline -> none
+  sdb
+    disk_scheduler  skipped  already tuned
+`, b.String())
+
+	b.Reset()
+	printDeviceGroups(&b, results[2:], stacks)
+	require.Empty(t, b.String())
+}
+
+type deviceResultsMock struct {
+	tuners.TuneResult
+	results []tuners.DeviceTuneResult
+}
+
+func (r *deviceResultsMock) DeviceResults() []tuners.DeviceTuneResult {
+	return r.results
+}
+
+type tunableFilesMock struct {
+	tuners.Tunable
+	files map[string]string
+}
+
+func (f *tunableFilesMock) TunableFiles(device string) ([]string, error) {
+	if file, ok := f.files[device]; ok {
+		return []string{file}, nil
+	}
+	return nil, nil
+}
+
+func TestTunerDeviceResults(t *testing.T) {
+	tuner := &tunableFilesMock{files: map[string]string{
+		"nvme0n1":   "/sys/devices/pci0000:00/0000:00:04.0/nvme/nvme0/nvme0n1/queue/scheduler",
+		"nvme0n1p1": "/sys/devices/pci0000:00/0000:00:04.0/nvme/nvme0/nvme0n1/nvme0n1p1/queue/scheduler",
+	}}
+	res := func() tuners.TuneResult {
+		return &deviceResultsMock{
+			TuneResult: tuners.NewTuneResult(false),
+			results: []tuners.DeviceTuneResult{
+				{Device: "nvme0n1", Status: tuners.DeviceApplied, New: "none"},
+				{Device: "nvme0n1p1", Status: tuners.DeviceApplied, New: "none"},
+				{Device: "sda", Status: tuners.DeviceSkipped, Reason: "already tuned"},
+			},
+		}
+	}
+	// The change of the partition is under the directory of the disk: it
+	// must not be taken for the change of the disk.
+	changes := []commands.Change{
+		{Path: "/sys/devices/pci0000:00/0000:00:04.0/nvme/nvme0/nvme0n1/nvme0n1p1/queue/scheduler", Current: "mq-deadline", Proposed: "none"},
+		{Path: "/sys/devices/pci0000:00/0000:00:04.0/nvme/nvme0/nvme0n1/queue/scheduler", Current: "kyber", Proposed: "none"},
+	}
+	require.Equal(t, []tuners.DeviceTuneResult{
+		{Tuner: "disk_scheduler", Device: "nvme0n1", Status: tuners.DeviceApplied, Previous: "kyber", New: "none"},
+		{Tuner: "disk_scheduler", Device: "nvme0n1p1", Status: tuners.DeviceApplied, Previous: "mq-deadline", New: "none"},
+		{Tuner: "disk_scheduler", Device: "sda", Status: tuners.DeviceSkipped, Reason: "already tuned"},
+	}, tunerDeviceResults(tuner, "disk_scheduler", res(), changes, false))
+
+	require.Equal(t, []tuners.DeviceTuneResult{
+		{Tuner: "disk_scheduler", Device: "nvme0n1", Status: tuners.DeviceSkipped, Reason: "dry run", New: "none"},
+		{Tuner: "disk_scheduler", Device: "nvme0n1p1", Status: tuners.DeviceSkipped, Reason: "dry run", New: "none"},
+		{Tuner: "disk_scheduler", Device: "sda", Status: tuners.DeviceSkipped, Reason: "already tuned"},
+	}, tunerDeviceResults(tuner, "disk_scheduler", res(), nil, true))
+}
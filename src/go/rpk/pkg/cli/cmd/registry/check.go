@@ -0,0 +1,255 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/schemaregistry"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// schemaTypes maps the --type values to the schema registry schema types.
+var schemaTypes = map[string]string{
+	"avro":     schemaregistry.TypeAvro,
+	"protobuf": schemaregistry.TypeProtobuf,
+	"json":     schemaregistry.TypeJSON,
+}
+
+var compatibilityLevels = []string{
+	"NONE",
+	"BACKWARD",
+	"BACKWARD_TRANSITIVE",
+	"FORWARD",
+	"FORWARD_TRANSITIVE",
+	"FULL",
+	"FULL_TRANSITIVE",
+}
+
+func newCheckCommand(fs afero.Fs) *cobra.Command {
+	var (
+		subject    string
+		schemaArg  string
+		schemaType string
+		level      string
+		override   bool
+	)
+	command := &cobra.Command{
+		Use:   "check",
+		Short: "Check if a schema is compatible with the latest schema of a subject",
+		Long: `Check if a schema is compatible with the latest schema of a subject.
+
+The schema is checked by the schema registry against the latest version of
+the subject, according to the compatibility level of the subject. The schema
+is either passed inline, or read from a file by prefixing its path with '@':
+
+    rpk registry schema check --subject foo-value --schema @foo.avsc
+
+This command exits with 0 if the schema is compatible, and with 1 if it is
+not, listing the changes that break the compatibility, if the schema registry
+reports them. A schema is compatible with a subject that has no schema yet.
+
+The --compatibility-level flag checks the schema against the given level. If
+it's the level in effect for the subject, the schema is checked as is.
+Otherwise the schema registry has to check the schema with the subject set to
+that level, which changes the live configuration of the subject: this is only
+done with the --override-level flag, which requires the permission to write
+the subject configuration. The level of the subject is then set to the given
+level for the duration of the check, and restored afterwards, including when
+the check is interrupted. Schemas registered to the subject while the check is
+running are checked against that level too.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			typ, ok := schemaTypes[strings.ToLower(schemaType)]
+			if !ok {
+				out.Die("unsupported schema type %q, supported types are avro, protobuf and json", schemaType)
+			}
+			if level != "" {
+				level = strings.ToUpper(level)
+				if !isCompatibilityLevel(level) {
+					out.Die("unknown compatibility level %q, supported levels are %s", level, strings.Join(compatibilityLevels, ", "))
+				}
+			}
+			schema, err := readSchema(fs, schemaArg)
+			out.MaybeDie(err, "unable to read the schema: %v", err)
+
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			cl, err := schemaregistry.NewClient(fs, cfg)
+			out.MaybeDie(err, "unable to initialize schema registry client: %v", err)
+
+			ctx := cmd.Context()
+			if override {
+				// Interrupting the check must still restore the level
+				// of the subject.
+				var cancel context.CancelFunc
+				ctx, cancel = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+				defer cancel()
+			}
+			res, err := checkSchema(ctx, cl, subject, level, override, schemaregistry.Schema{
+				Schema: schema,
+				Type:   typ,
+			})
+			out.MaybeDie(err, "unable to check the schema compatibility: %v", err)
+
+			if res.IsCompatible {
+				out.Exit("Schema is compatible with the latest schema of subject %q.", subject)
+			}
+			fmt.Printf("Schema is not compatible with the latest schema of subject %q.\n", subject)
+			for _, m := range res.Messages {
+				fmt.Printf("  - %s\n", m)
+			}
+			os.Exit(1)
+		},
+	}
+
+	command.Flags().StringVar(&subject, "subject", "", "Subject to check the schema against")
+	command.Flags().StringVar(&schemaArg, "schema", "", "Schema to check, or @path of the file to read it from")
+	command.Flags().StringVar(&schemaType, "type", "avro", "Type of the schema (avro, protobuf, json)")
+	command.Flags().StringVar(&level, "compatibility-level", "", "Compatibility level to check the schema with, instead of the one of the subject")
+	command.Flags().BoolVar(&override, "override-level", false, "Set the level of the subject to --compatibility-level for the duration of the check, if it differs")
+	command.MarkFlagRequired("subject")
+	command.MarkFlagRequired("schema")
+
+	return command
+}
+
+// readSchema returns the schema passed to --schema, which is read from a file
+// if the argument is prefixed with '@'.
+func readSchema(fs afero.Fs, arg string) (string, error) {
+	if !strings.HasPrefix(arg, "@") {
+		return arg, nil
+	}
+	file, err := afero.ReadFile(fs, strings.TrimPrefix(arg, "@"))
+	if err != nil {
+		return "", err
+	}
+	return string(file), nil
+}
+
+func isCompatibilityLevel(level string) bool {
+	for _, l := range compatibilityLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSchema checks the schema against the latest schema of the subject. If
+// level is not empty and differs from the level in effect for the subject,
+// the subject is checked with that compatibility level if override is set,
+// the previous level of the subject being restored afterwards.
+func checkSchema(
+	ctx context.Context,
+	cl *schemaregistry.Client,
+	subject, level string,
+	override bool,
+	schema schemaregistry.Schema,
+) (res schemaregistry.CompatibilityResult, rerr error) {
+	if level != "" {
+		current, err := effectiveCompatibility(ctx, cl, subject)
+		if err != nil {
+			return res, err
+		}
+		if current == level {
+			level = ""
+		} else if !override {
+			return res, fmt.Errorf("subject %q is checked with the %s level: checking it with %s requires setting its level for the duration of the check, use --override-level to allow it", subject, current, level)
+		}
+	}
+	if level != "" {
+		restore, err := overrideCompatibility(ctx, cl, subject, level)
+		if err != nil {
+			return res, err
+		}
+		defer func() {
+			if err := restore(); err != nil && rerr == nil {
+				rerr = fmt.Errorf("unable to restore the compatibility level of subject %q: %v", subject, err)
+			}
+		}()
+	}
+	res, err := cl.CheckCompatibility(ctx, subject, "latest", schema)
+	if isResponseErrorCode(err, schemaregistry.ErrCodeSubjectNotFound) {
+		log.Debugf("Subject %q has no schema, the schema is compatible", subject)
+		return schemaregistry.CompatibilityResult{IsCompatible: true}, nil
+	}
+	return res, err
+}
+
+// effectiveCompatibility returns the compatibility level in effect for the
+// subject: its own level, or the global level.
+func effectiveCompatibility(
+	ctx context.Context, cl *schemaregistry.Client, subject string,
+) (string, error) {
+	level, err := cl.SubjectCompatibility(ctx, subject)
+	if err == nil {
+		return level, nil
+	}
+	if !isResponseErrorCode(err, schemaregistry.ErrCodeSubjectNotFound, schemaregistry.ErrCodeSubjectLevelNotFound) {
+		return "", fmt.Errorf("unable to get the compatibility level of subject %q: %v", subject, err)
+	}
+	level, err = cl.GlobalCompatibility(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the global compatibility level: %v", err)
+	}
+	return level, nil
+}
+
+// overrideCompatibility sets the compatibility level of the subject, returning
+// a function that restores the previous level: either the own level of the
+// subject, or the global level by deleting the level of the subject.
+func overrideCompatibility(
+	ctx context.Context, cl *schemaregistry.Client, subject, level string,
+) (func() error, error) {
+	prev, err := cl.SubjectCompatibility(ctx, subject)
+	if err != nil && !isResponseErrorCode(err, schemaregistry.ErrCodeSubjectNotFound, schemaregistry.ErrCodeSubjectLevelNotFound) {
+		return nil, fmt.Errorf("unable to get the compatibility level of subject %q: %v", subject, err)
+	}
+	if err := cl.SetSubjectCompatibility(ctx, subject, level); err != nil {
+		return nil, fmt.Errorf("unable to set the compatibility level of subject %q: %v", subject, err)
+	}
+	log.Debugf("Set the compatibility level of subject %q to %s, previously %q", subject, level, prev)
+	return func() error {
+		// The check context may be canceled, but the level must be
+		// restored nonetheless.
+		ctx := context.Background()
+		if prev == "" {
+			return cl.DeleteSubjectCompatibility(ctx, subject)
+		}
+		return cl.SetSubjectCompatibility(ctx, subject, prev)
+	}, nil
+}
+
+func isResponseErrorCode(err error, codes ...int) bool {
+	var re *schemaregistry.ResponseError
+	if !errors.As(err, &re) {
+		return false
+	}
+	for _, code := range codes {
+		if re.ErrorCode == code {
+			return true
+		}
+	}
+	return false
+}
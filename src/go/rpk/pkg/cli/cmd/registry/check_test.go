@@ -0,0 +1,187 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/api/schemaregistry"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry serves the compatibility and config endpoints for the subject
+// foo-value, the check result depending on the level of the subject.
+type fakeRegistry struct {
+	hasSubject bool
+	// level is the own level of the subject, if any.
+	level string
+	// checkedWith is the level of the subject during the check.
+	checkedWith string
+	// sets counts the changes of the level of the subject.
+	sets int
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeError := func(status, code int) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": code, "message": "not found"})
+	}
+	switch r.Method + " " + r.URL.Path {
+	case "POST /compatibility/subjects/foo-value/versions/latest":
+		if !f.hasSubject {
+			writeError(http.StatusNotFound, schemaregistry.ErrCodeSubjectNotFound)
+			return
+		}
+		f.checkedWith = f.level
+		if f.checkedWith == "" {
+			f.checkedWith = "BACKWARD"
+		}
+		if f.level == "NONE" {
+			w.Write([]byte(`{"is_compatible":true}`))
+			return
+		}
+		w.Write([]byte(`{"is_compatible":false,"messages":["reader field 'id' has no default value"]}`))
+	case "GET /config/foo-value":
+		if f.level == "" {
+			writeError(http.StatusNotFound, schemaregistry.ErrCodeSubjectLevelNotFound)
+			return
+		}
+		w.Write([]byte(`{"compatibilityLevel":"` + f.level + `"}`))
+	case "GET /config":
+		w.Write([]byte(`{"compatibilityLevel":"BACKWARD"}`))
+	case "PUT /config/foo-value":
+		f.sets++
+		var req struct {
+			Compatibility string `json:"compatibility"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.level = req.Compatibility
+		w.Write([]byte(`{"compatibility":"` + f.level + `"}`))
+	case "DELETE /config/foo-value":
+		f.sets++
+		f.level = ""
+		w.Write([]byte(`{"compatibilityLevel":"BACKWARD"}`))
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name           string
+		registry       fakeRegistry
+		level          string
+		override       bool
+		exp            schemaregistry.CompatibilityResult
+		expErr         bool
+		expCheckedWith string
+		expLevel       string
+		expSets        int
+	}{
+		{
+			name:     "incompatible schema",
+			registry: fakeRegistry{hasSubject: true},
+			exp: schemaregistry.CompatibilityResult{
+				Messages: []string{"reader field 'id' has no default value"},
+			},
+			expCheckedWith: "BACKWARD",
+		},
+		{
+			name:     "level of the subject already in effect",
+			registry: fakeRegistry{hasSubject: true, level: "NONE"},
+			level:    "NONE",
+			exp:      schemaregistry.CompatibilityResult{IsCompatible: true},
+			// No override is needed.
+			expCheckedWith: "NONE",
+			expLevel:       "NONE",
+		},
+		{
+			name:     "global level already in effect",
+			registry: fakeRegistry{hasSubject: true},
+			level:    "BACKWARD",
+			exp: schemaregistry.CompatibilityResult{
+				Messages: []string{"reader field 'id' has no default value"},
+			},
+			expCheckedWith: "BACKWARD",
+		},
+		{
+			name:     "other level without override",
+			registry: fakeRegistry{hasSubject: true, level: "FULL"},
+			level:    "NONE",
+			expErr:   true,
+			expLevel: "FULL",
+		},
+		{
+			name:     "subject without schema",
+			registry: fakeRegistry{},
+			exp:      schemaregistry.CompatibilityResult{IsCompatible: true},
+		},
+		{
+			name:           "override and restore the level of the subject",
+			registry:       fakeRegistry{hasSubject: true, level: "FULL"},
+			level:          "NONE",
+			override:       true,
+			exp:            schemaregistry.CompatibilityResult{IsCompatible: true},
+			expCheckedWith: "NONE",
+			expLevel:       "FULL",
+			expSets:        2,
+		},
+		{
+			name:           "override and delete the level of the subject",
+			registry:       fakeRegistry{hasSubject: true},
+			level:          "NONE",
+			override:       true,
+			exp:            schemaregistry.CompatibilityResult{IsCompatible: true},
+			expCheckedWith: "NONE",
+			expSets:        2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := tt.registry
+			ts := httptest.NewServer(&registry)
+			defer ts.Close()
+
+			cl, err := schemaregistry.NewHostClient([]string{ts.URL}, "", "", nil)
+			require.NoError(t, err)
+			res, err := checkSchema(context.Background(), cl, "foo-value", tt.level, tt.override, schemaregistry.Schema{Schema: `{"type":"string"}`})
+			require.Equal(t, tt.expLevel, registry.level)
+			require.Equal(t, tt.expSets, registry.sets)
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.exp, res)
+			require.Equal(t, tt.expCheckedWith, registry.checkedWith)
+		})
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "/tmp/foo.avsc", []byte(`{"type":"string"}`), 0o644)
+
+	schema, err := readSchema(fs, "@/tmp/foo.avsc")
+	require.NoError(t, err)
+	require.Equal(t, `{"type":"string"}`, schema)
+
+	schema, err = readSchema(fs, `{"type":"int"}`)
+	require.NoError(t, err)
+	require.Equal(t, `{"type":"int"}`, schema)
+
+	_, err = readSchema(fs, "@/tmp/bar.avsc")
+	require.Error(t, err)
+}
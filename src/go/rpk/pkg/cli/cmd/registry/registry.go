@@ -0,0 +1,73 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package registry
+
+import (
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(fs afero.Fs) *cobra.Command {
+	var (
+		configFile string
+		user       string
+		password   string
+		hosts      []string
+	)
+	command := &cobra.Command{
+		Use:   "registry",
+		Short: "Interact with the Redpanda schema registry",
+	}
+
+	command.PersistentFlags().StringVar(
+		&configFile,
+		config.FlagConfig,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	command.PersistentFlags().StringVar(
+		&user,
+		config.FlagSASLUser,
+		"",
+		"User to be used for the schema registry basic authentication",
+	)
+	command.PersistentFlags().StringVar(
+		&password,
+		config.FlagSASLPass,
+		"",
+		"Password to be used for the schema registry basic authentication",
+	)
+	command.PersistentFlags().StringSliceVar(
+		&hosts,
+		config.FlagRegistryHosts,
+		[]string{},
+		"Comma-separated list of schema registry host:ports (defaults to"+
+			" the schema_registry listeners of the config file)",
+	)
+
+	command.AddCommand(
+		newSchemaCommand(fs),
+	)
+
+	return command
+}
+
+func newSchemaCommand(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "schema",
+		Short: "Manage the schemas of the schema registry",
+	}
+	command.AddCommand(
+		newCheckCommand(fs),
+	)
+	return command
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/generate"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/group"
 	plugincmd "github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/plugin"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/registry"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/topic"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/version"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cli/cmd/wasm"
@@ -85,6 +86,7 @@ func Execute() {
 		generate.NewCommand(fs),
 		group.NewCommand(fs),
 		plugincmd.NewCommand(fs),
+		registry.NewCommand(fs),
 		topic.NewCommand(fs),
 		version.NewCommand(),
 		wasm.NewCommand(fs),
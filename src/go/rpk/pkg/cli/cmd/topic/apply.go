@@ -0,0 +1,375 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"gopkg.in/yaml.v3"
+)
+
+func newApplyCommand(fs afero.Fs) *cobra.Command {
+	var (
+		file   string
+		dryRun bool
+	)
+	cmd := &cobra.Command{
+		Use:   "apply -f [FILE]",
+		Short: "Create and update topics to match a spec file",
+		Long: `Create and update topics to match a spec file.
+
+The spec file lists the desired topics, with their number of partitions,
+replication factor and configs, e.g.:
+
+    topics:
+      - name: orders
+        partitions: 12
+        replication_factor: 3
+        configs:
+          cleanup.policy: compact
+          retention.ms: "604800000"
+      - name: events
+
+The desired state is compared to the cluster, then missing topics are created,
+partitions are added to topics with fewer than desired, and the listed configs
+whose value differs are set. Topics, partitions and configs missing from the
+file are never deleted, and applying the same file twice changes nothing.
+
+Omitting the partitions or the replication factor of a topic creates it with
+the cluster defaults and leaves those of an existing topic untouched. As Kafka
+can't remove partitions nor this command move replicas, fewer partitions or a
+different replication factor than the existing ones fail before any change.
+
+The --dry-run flag prints the changes that would be made, without making them.
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if file == "" {
+				out.Die("missing the spec file, use -f")
+			}
+			spec, err := readTopicsSpec(fs, file)
+			out.MaybeDie(err, "unable to read %q: %v", file, err)
+
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			adm, err := kafka.NewAdmin(fs, p, cfg)
+			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
+			defer adm.Close()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+
+			existing, err := describeTopicStates(ctx, adm, spec.topicNames())
+			out.MaybeDie(err, "unable to describe topics: %v", err)
+			changes, err := planTopicChanges(spec.Topics, existing)
+			out.MaybeDieErr(err)
+
+			if len(changes) == 0 {
+				fmt.Println("All topics are up to date.")
+				return
+			}
+			if dryRun {
+				tw := out.NewTable("TOPIC", "ACTION", "CHANGE")
+				defer tw.Flush()
+				for _, c := range changes {
+					tw.Print(c.topic, c.action, c.details())
+				}
+				return
+			}
+
+			var exit1 bool
+			defer func() {
+				if exit1 {
+					os.Exit(1)
+				}
+			}()
+			tw := out.NewTable("TOPIC", "ACTION", "CHANGE", "STATUS")
+			defer tw.Flush()
+			for _, c := range changes {
+				msg := "OK"
+				if err := applyTopicChange(ctx, adm, c); err != nil {
+					msg = err.Error()
+					exit1 = true
+				}
+				tw.Print(c.topic, c.action, c.details(), msg)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Spec file listing the desired topics")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the changes to make, without making them")
+	return cmd
+}
+
+// topicsSpec is the desired state of topics, as read from the file given to
+// 'rpk topic apply'.
+type topicsSpec struct {
+	Topics []topicSpec `yaml:"topics"`
+}
+
+type topicSpec struct {
+	Name string `yaml:"name"`
+	// Partitions and ReplicationFactor are the cluster defaults if 0.
+	Partitions        int32             `yaml:"partitions"`
+	ReplicationFactor int16             `yaml:"replication_factor"`
+	Configs           map[string]string `yaml:"configs"`
+}
+
+func (s *topicsSpec) topicNames() []string {
+	names := make([]string, 0, len(s.Topics))
+	for _, t := range s.Topics {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func readTopicsSpec(fs afero.Fs, path string) (*topicsSpec, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	var spec topicsSpec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, t := range spec.Topics {
+		switch {
+		case t.Name == "":
+			return nil, errors.New("topic without name")
+		case seen[t.Name]:
+			return nil, fmt.Errorf("topic %q listed twice", t.Name)
+		case t.Partitions < 0:
+			return nil, fmt.Errorf("invalid partitions %d of topic %q", t.Partitions, t.Name)
+		case t.ReplicationFactor < 0:
+			return nil, fmt.Errorf("invalid replication factor %d of topic %q", t.ReplicationFactor, t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return &spec, nil
+}
+
+// topicState is the state of an existing topic, as compared to its spec.
+type topicState struct {
+	partitions int32
+	replicas   int16
+	configs    map[string]string
+}
+
+// describeTopicStates returns the state of the given topics that exist.
+func describeTopicStates(ctx context.Context, adm *kadm.Client, topics []string) (map[string]topicState, error) {
+	states := map[string]topicState{}
+	if len(topics) == 0 {
+		return states, nil
+	}
+	details, err := adm.ListTopics(ctx, topics...)
+	if err != nil {
+		return nil, err
+	}
+	var existing []string
+	for _, d := range details {
+		if errors.Is(d.Err, kerr.UnknownTopicOrPartition) {
+			continue
+		}
+		if d.Err != nil {
+			return nil, fmt.Errorf("unable to describe topic %q: %w", d.Topic, d.Err)
+		}
+		states[d.Topic] = topicState{
+			partitions: int32(len(d.Partitions)),
+			replicas:   int16(d.Partitions.NumReplicas()),
+			configs:    map[string]string{},
+		}
+		existing = append(existing, d.Topic)
+	}
+	if len(existing) == 0 {
+		return states, nil
+	}
+	configs, err := adm.DescribeTopicConfigs(ctx, existing...)
+	if err != nil {
+		return nil, err
+	}
+	for _, rc := range configs {
+		if rc.Err != nil {
+			return nil, fmt.Errorf("unable to describe the configs of topic %q: %w", rc.Name, rc.Err)
+		}
+		for _, c := range rc.Configs {
+			states[rc.Name].configs[c.Key] = c.MaybeValue()
+		}
+	}
+	return states, nil
+}
+
+// The actions of a topicChange.
+const (
+	topicActionCreate        = "create"
+	topicActionAddPartitions = "add-partitions"
+	topicActionSetConfig     = "set-config"
+)
+
+// topicChange is a change bringing a topic closer to its spec.
+type topicChange struct {
+	topic  string
+	action string
+
+	// partitions and replicas are the desired ones, for create and
+	// add-partitions.
+	partitions     int32
+	replicas       int16
+	fromPartitions int32
+	configs        map[string]string
+
+	// key, from and to are the config changed by set-config.
+	key, from, to string
+}
+
+func (c topicChange) details() string {
+	switch c.action {
+	case topicActionCreate:
+		details := []string{
+			"partitions=" + defaultIfUnset(int64(c.partitions)),
+			"replicas=" + defaultIfUnset(int64(c.replicas)),
+		}
+		keys := make([]string, 0, len(c.configs))
+		for k := range c.configs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			details = append(details, k+"="+c.configs[k])
+		}
+		return strings.Join(details, " ")
+	case topicActionAddPartitions:
+		return fmt.Sprintf("partitions %d -> %d", c.fromPartitions, c.partitions)
+	default:
+		return fmt.Sprintf("%s %q -> %q", c.key, c.from, c.to)
+	}
+}
+
+func defaultIfUnset(n int64) string {
+	if n == 0 {
+		return "(default)"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// planTopicChanges returns the changes bringing the existing topics to their
+// specs, in spec order, or an error if a spec asks for what the cluster can't
+// do: removing partitions or changing the replication factor.
+func planTopicChanges(specs []topicSpec, existing map[string]topicState) ([]topicChange, error) {
+	var changes []topicChange
+	for _, spec := range specs {
+		state, ok := existing[spec.Name]
+		if !ok {
+			changes = append(changes, topicChange{
+				topic:      spec.Name,
+				action:     topicActionCreate,
+				partitions: spec.Partitions,
+				replicas:   spec.ReplicationFactor,
+				configs:    spec.Configs,
+			})
+			continue
+		}
+		if spec.Partitions != 0 && spec.Partitions < state.partitions {
+			return nil, fmt.Errorf("topic %q has %d partitions, more than the %d desired: partitions can't be removed",
+				spec.Name, state.partitions, spec.Partitions)
+		}
+		if spec.ReplicationFactor != 0 && spec.ReplicationFactor != state.replicas {
+			return nil, fmt.Errorf("topic %q has a replication factor of %d instead of the %d desired: changing it is not supported",
+				spec.Name, state.replicas, spec.ReplicationFactor)
+		}
+		if spec.Partitions > state.partitions {
+			changes = append(changes, topicChange{
+				topic:          spec.Name,
+				action:         topicActionAddPartitions,
+				partitions:     spec.Partitions,
+				fromPartitions: state.partitions,
+			})
+		}
+		keys := make([]string, 0, len(spec.Configs))
+		for k := range spec.Configs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if current, ok := state.configs[k]; ok && current == spec.Configs[k] {
+				continue
+			}
+			changes = append(changes, topicChange{
+				topic:  spec.Name,
+				action: topicActionSetConfig,
+				key:    k,
+				from:   state.configs[k],
+				to:     spec.Configs[k],
+			})
+		}
+	}
+	return changes, nil
+}
+
+func applyTopicChange(ctx context.Context, adm *kadm.Client, c topicChange) error {
+	switch c.action {
+	case topicActionCreate:
+		partitions, replicas := c.partitions, c.replicas
+		if partitions == 0 {
+			partitions = -1
+		}
+		if replicas == 0 {
+			replicas = -1
+		}
+		configs := make(map[string]*string, len(c.configs))
+		for k, v := range c.configs {
+			configs[k] = kadm.StringPtr(v)
+		}
+		resps, err := adm.CreateTopics(ctx, partitions, replicas, configs, c.topic)
+		if err != nil {
+			return err
+		}
+		return resps[c.topic].Err
+	case topicActionAddPartitions:
+		resps, err := adm.UpdatePartitions(ctx, int(c.partitions), c.topic)
+		if err != nil {
+			return err
+		}
+		return resps[c.topic].Err
+	default:
+		resps, err := adm.AlterTopicConfigs(ctx, []kadm.AlterConfig{{
+			Op:    kadm.SetConfig,
+			Name:  c.key,
+			Value: kadm.StringPtr(c.to),
+		}}, c.topic)
+		if err != nil {
+			return err
+		}
+		for _, resp := range resps {
+			if resp.Err != nil {
+				return resp.Err
+			}
+		}
+		return nil
+	}
+}
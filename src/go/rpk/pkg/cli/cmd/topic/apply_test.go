@@ -0,0 +1,166 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topic
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTopicsSpec(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		in     string
+		exp    *topicsSpec
+		expErr bool
+	}{
+		{
+			name: "full spec",
+			in: `topics:
+  - name: foo
+    partitions: 12
+    replication_factor: 3
+    configs:
+      cleanup.policy: compact
+      retention.ms: "1000"
+  - name: bar
+`,
+			exp: &topicsSpec{Topics: []topicSpec{
+				{
+					Name:              "foo",
+					Partitions:        12,
+					ReplicationFactor: 3,
+					Configs:           map[string]string{"cleanup.policy": "compact", "retention.ms": "1000"},
+				},
+				{Name: "bar"},
+			}},
+		},
+		{
+			name:   "unknown field",
+			in:     "topics:\n  - name: foo\n    partition: 3\n",
+			expErr: true,
+		},
+		{
+			name:   "missing name",
+			in:     "topics:\n  - partitions: 3\n",
+			expErr: true,
+		},
+		{
+			name:   "duplicate topic",
+			in:     "topics:\n  - name: foo\n  - name: foo\n",
+			expErr: true,
+		},
+		{
+			name:   "negative partitions",
+			in:     "topics:\n  - name: foo\n    partitions: -1\n",
+			expErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/topics.yaml", []byte(test.in), 0o644))
+			got, err := readTopicsSpec(fs, "/topics.yaml")
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, got)
+		})
+	}
+}
+
+func TestPlanTopicChanges(t *testing.T) {
+	existing := map[string]topicState{
+		"foo": {
+			partitions: 6,
+			replicas:   3,
+			configs:    map[string]string{"cleanup.policy": "delete", "retention.ms": "1000"},
+		},
+	}
+	for _, test := range []struct {
+		name   string
+		specs  []topicSpec
+		exp    []topicChange
+		expErr bool
+	}{
+		{
+			name: "up to date",
+			specs: []topicSpec{{
+				Name:              "foo",
+				Partitions:        6,
+				ReplicationFactor: 3,
+				Configs:           map[string]string{"retention.ms": "1000"},
+			}},
+		},
+		{
+			name:  "unset partitions and replicas are left untouched",
+			specs: []topicSpec{{Name: "foo"}},
+		},
+		{
+			name: "create, add partitions and set configs",
+			specs: []topicSpec{
+				{Name: "bar", Partitions: 3, Configs: map[string]string{"cleanup.policy": "compact"}},
+				{
+					Name:       "foo",
+					Partitions: 12,
+					Configs: map[string]string{
+						"retention.ms":   "1000",
+						"segment.bytes":  "1048576",
+						"cleanup.policy": "compact",
+					},
+				},
+			},
+			exp: []topicChange{
+				{topic: "bar", action: topicActionCreate, partitions: 3, configs: map[string]string{"cleanup.policy": "compact"}},
+				{topic: "foo", action: topicActionAddPartitions, partitions: 12, fromPartitions: 6},
+				{topic: "foo", action: topicActionSetConfig, key: "cleanup.policy", from: "delete", to: "compact"},
+				{topic: "foo", action: topicActionSetConfig, key: "segment.bytes", from: "", to: "1048576"},
+			},
+		},
+		{
+			name:   "fewer partitions",
+			specs:  []topicSpec{{Name: "foo", Partitions: 3}},
+			expErr: true,
+		},
+		{
+			name:   "different replication factor",
+			specs:  []topicSpec{{Name: "foo", ReplicationFactor: 1}},
+			expErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := planTopicChanges(test.specs, existing)
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, got)
+		})
+	}
+}
+
+func TestTopicChangeDetails(t *testing.T) {
+	require.Equal(t,
+		"partitions=(default) replicas=3 a=1 b=2",
+		topicChange{action: topicActionCreate, replicas: 3, configs: map[string]string{"b": "2", "a": "1"}}.details(),
+	)
+	require.Equal(t,
+		"partitions 6 -> 12",
+		topicChange{action: topicActionAddPartitions, partitions: 12, fromPartitions: 6}.details(),
+	)
+	require.Equal(t,
+		`retention.ms "1000" -> "2000"`,
+		topicChange{action: topicActionSetConfig, key: "retention.ms", from: "1000", to: "2000"}.details(),
+	)
+}
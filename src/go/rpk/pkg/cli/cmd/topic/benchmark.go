@@ -0,0 +1,336 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// benchmarkResult is the outcome of a produce benchmark, as printed with
+// '--format json'.
+type benchmarkResult struct {
+	Topic            string           `json:"topic"`
+	DurationSeconds  float64          `json:"duration_seconds"`
+	Records          int64            `json:"records"`
+	Bytes            int64            `json:"bytes"`
+	Errors           int64            `json:"errors"`
+	RecordsPerSecond float64          `json:"records_per_second"`
+	BytesPerSecond   float64          `json:"bytes_per_second"`
+	LatencyMs        benchmarkLatency `json:"latency_ms"`
+	// ErrorCounts counts the records that failed with each error.
+	ErrorCounts map[string]int64 `json:"error_counts,omitempty"`
+}
+
+// benchmarkLatency are the percentiles of the produce latency, from the
+// time a record is handed to the client to the time it's acknowledged.
+type benchmarkLatency struct {
+	P50  float64 `json:"p50"`
+	P99  float64 `json:"p99"`
+	P999 float64 `json:"p999"`
+}
+
+// benchmarkStats accumulates the outcome of the produced records, from the
+// produce callbacks.
+type benchmarkStats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	bytes     int64
+	errors    map[string]int64
+}
+
+func (s *benchmarkStats) record(r *kgo.Record, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.errors[err.Error()]++
+		return
+	}
+	s.latencies = append(s.latencies, latency)
+	s.bytes += int64(len(r.Key) + len(r.Value))
+}
+
+func newBenchmarkCommand(fs afero.Fs) *cobra.Command {
+	var (
+		recordSize      int
+		rate            int
+		duration        time.Duration
+		acks            int
+		format          string
+		createTempTopic bool
+	)
+	cmd := &cobra.Command{
+		Use:   "benchmark [TOPIC]",
+		Short: "Benchmark producing synthetic records to a topic",
+		Long: `Benchmark producing synthetic records to a topic.
+
+Records of --record-size random bytes are produced at --rate records per second
+for --duration, after which the achieved throughput, the 50th, 99th and 99.9th
+percentiles of the produce latency, and the number of records that failed are
+printed. A rate of 0 produces as fast as the client allows. Interrupting the
+benchmark stops producing and prints the results so far.
+
+The latency of a record is measured from the time it's handed to the client to
+the time it's acknowledged, as required by --acks, which includes the time it
+spends buffered in the client.
+
+With --create-temp-topic, the topic is created with the cluster defaults before
+producing and deleted once done; its name defaults to 'rpk-benchmark-<id>' if
+TOPIC isn't given. Otherwise TOPIC must exist.
+`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if format != "text" && format != "json" {
+				out.Die("unsupported format %q, use either text or json", format)
+			}
+			if recordSize < 0 {
+				out.Die("invalid --record-size %d, must be non-negative", recordSize)
+			}
+			if rate < 0 {
+				out.Die("invalid --rate %d, must be non-negative", rate)
+			}
+			if duration <= 0 {
+				out.Die("invalid --duration %s, must be positive", duration)
+			}
+			var topic string
+			if len(args) == 1 {
+				topic = args[0]
+			} else if createTempTopic {
+				topic = "rpk-benchmark-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+			} else {
+				out.Die("topic to benchmark is missing, pass a topic or --create-temp-topic")
+			}
+			acksOpts, err := produceAcksOpts(acks)
+			out.MaybeDieErr(err)
+			opts := append([]kgo.Opt{
+				kgo.ProduceRequestTimeout(5 * time.Second),
+				// Random values don't compress.
+				kgo.ProducerBatchCompression(kgo.NoCompression()),
+				kgo.DefaultProduceTopic(topic),
+			}, acksOpts...)
+
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			// Failures exit 1 once the deferred calls, e.g. the deletion of
+			// the temporary topic, ran.
+			var exit1 bool
+			defer func() {
+				if exit1 {
+					os.Exit(1)
+				}
+			}()
+
+			// Stop producing on interrupt, so the results are still
+			// printed and the temporary topic deleted.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if createTempTopic {
+				adm, err := kafka.NewAdmin(fs, p, cfg)
+				out.MaybeDie(err, "unable to initialize kafka client: %v", err)
+				defer adm.Close()
+				err = createBenchmarkTopic(ctx, adm, topic)
+				out.MaybeDie(err, "unable to create topic %q: %v", topic, err)
+				if format == "text" {
+					fmt.Printf("Created temporary topic %q\n", topic)
+				}
+				// out.Die exits without running the deferred calls, we
+				// delete the topic before dying.
+				defer func() {
+					resps, err := adm.DeleteTopics(context.Background(), topic)
+					if err == nil {
+						err = resps[topic].Err
+					}
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "unable to delete temporary topic %q: %v\n", topic, err)
+					}
+				}()
+			}
+
+			result, err := runBenchmark(ctx, fs, p, cfg, opts, topic, recordSize, rate, duration)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "unable to run the benchmark: %v\n", err)
+				exit1 = true
+				return
+			}
+			if format == "json" {
+				asJSON, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "unable to format the results as JSON: %v\n", err)
+					exit1 = true
+					return
+				}
+				fmt.Println(string(asJSON))
+				return
+			}
+			printBenchmarkResult(result)
+		},
+	}
+	cmd.Flags().IntVar(&recordSize, "record-size", 1024, "Size of the value of each record, in bytes")
+	cmd.Flags().IntVar(&rate, "rate", 1000, "Records to produce per second, 0 produces as fast as possible")
+	cmd.Flags().DurationVar(&duration, "duration", 30*time.Second, "How long to produce records for")
+	cmd.Flags().IntVar(&acks, "acks", -1, "Number of acks required for producing (-1=all, 0=none, 1=leader)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
+	cmd.Flags().BoolVar(&createTempTopic, "create-temp-topic", false, "Create the topic before benchmarking and delete it once done")
+	return cmd
+}
+
+func createBenchmarkTopic(ctx context.Context, adm *kadm.Client, topic string) error {
+	resps, err := adm.CreateTopics(ctx, -1, -1, nil, topic)
+	if err != nil {
+		return err
+	}
+	return resps[topic].Err
+}
+
+// runBenchmark produces records to the topic at the given rate, in records
+// per second or unbounded if 0, until the duration elapses or ctx is done,
+// and waits for them to be acknowledged.
+func runBenchmark(
+	ctx context.Context,
+	fs afero.Fs,
+	p *config.Params,
+	cfg *config.Config,
+	opts []kgo.Opt,
+	topic string,
+	recordSize, rate int,
+	duration time.Duration,
+) (*benchmarkResult, error) {
+	cl, err := kafka.NewFranzClient(fs, p, cfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize kafka client: %w", err)
+	}
+	defer cl.Close()
+
+	value := make([]byte, recordSize)
+	if _, err := rand.Read(value); err != nil {
+		return nil, err
+	}
+	stats := &benchmarkStats{errors: map[string]int64{}}
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Second / time.Duration(rate)
+	}
+	start := time.Now()
+	for i := 0; ctx.Err() == nil && time.Since(start) < duration; i++ {
+		if interval > 0 {
+			// Records are paced from the start, so a slow send doesn't
+			// lower the rate of the following ones.
+			if wait := time.Until(start.Add(time.Duration(i) * interval)); wait > 0 {
+				select {
+				case <-ctx.Done():
+					continue
+				case <-time.After(wait):
+				}
+			}
+		}
+		sent := time.Now()
+		cl.Produce(ctx, &kgo.Record{Value: value}, func(r *kgo.Record, err error) {
+			stats.record(r, time.Since(sent), err)
+		})
+	}
+	// The records buffered when interrupted fail with the context error,
+	// and are counted as such.
+	if err := cl.Flush(context.Background()); err != nil {
+		return nil, err
+	}
+	return newBenchmarkResult(topic, time.Since(start), stats), nil
+}
+
+func newBenchmarkResult(topic string, elapsed time.Duration, stats *benchmarkStats) *benchmarkResult {
+	result := &benchmarkResult{
+		Topic:           topic,
+		DurationSeconds: elapsed.Seconds(),
+		Records:         int64(len(stats.latencies)),
+		Bytes:           stats.bytes,
+	}
+	for _, count := range stats.errors {
+		result.Errors += count
+	}
+	if result.Errors > 0 {
+		result.ErrorCounts = stats.errors
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		result.RecordsPerSecond = float64(result.Records) / seconds
+		result.BytesPerSecond = float64(result.Bytes) / seconds
+	}
+	sort.Slice(stats.latencies, func(i, j int) bool {
+		return stats.latencies[i] < stats.latencies[j]
+	})
+	result.LatencyMs = benchmarkLatency{
+		P50:  latencyPercentile(stats.latencies, 0.5),
+		P99:  latencyPercentile(stats.latencies, 0.99),
+		P999: latencyPercentile(stats.latencies, 0.999),
+	}
+	return result
+}
+
+// latencyPercentile returns the nearest-rank percentile of the sorted
+// latencies, in milliseconds, or 0 if there are none.
+func latencyPercentile(sorted []time.Duration, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return float64(sorted[rank]) / float64(time.Millisecond)
+}
+
+func printBenchmarkResult(result *benchmarkResult) {
+	tw := out.NewTabWriter()
+	tw.PrintColumn("topic", result.Topic)
+	tw.PrintColumn("duration", time.Duration(result.DurationSeconds*float64(time.Second)).Round(time.Millisecond))
+	tw.PrintColumn("records", result.Records)
+	tw.PrintColumn("throughput", fmt.Sprintf("%.1f records/s, %s/s",
+		result.RecordsPerSecond, units.HumanSize(result.BytesPerSecond)))
+	tw.PrintColumn("latency p50", fmt.Sprintf("%.3fms", result.LatencyMs.P50))
+	tw.PrintColumn("latency p99", fmt.Sprintf("%.3fms", result.LatencyMs.P99))
+	tw.PrintColumn("latency p99.9", fmt.Sprintf("%.3fms", result.LatencyMs.P999))
+	tw.PrintColumn("errors", result.Errors)
+	tw.Flush()
+	if len(result.ErrorCounts) == 0 {
+		return
+	}
+	fmt.Println()
+	out.Section("errors")
+	errs := out.NewTable("error", "records")
+	msgs := make([]string, 0, len(result.ErrorCounts))
+	for msg := range result.ErrorCounts {
+		msgs = append(msgs, msg)
+	}
+	sort.Strings(msgs)
+	for _, msg := range msgs {
+		errs.Print(msg, result.ErrorCounts[msg])
+	}
+	errs.Flush()
+}
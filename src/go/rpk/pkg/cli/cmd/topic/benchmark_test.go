@@ -0,0 +1,105 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 1000; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	for _, test := range []struct {
+		name       string
+		latencies  []time.Duration
+		percentile float64
+		exp        float64
+	}{
+		{
+			name:       "no latencies",
+			percentile: 0.99,
+			exp:        0,
+		},
+		{
+			name:       "single latency",
+			latencies:  []time.Duration{1500 * time.Microsecond},
+			percentile: 0.999,
+			exp:        1.5,
+		},
+		{
+			name:       "p50",
+			latencies:  latencies,
+			percentile: 0.5,
+			exp:        500,
+		},
+		{
+			name:       "p99",
+			latencies:  latencies,
+			percentile: 0.99,
+			exp:        990,
+		},
+		{
+			name:       "p999",
+			latencies:  latencies,
+			percentile: 0.999,
+			exp:        999,
+		},
+		{
+			name:       "nearest rank rounds up",
+			latencies:  latencies[:10],
+			percentile: 0.99,
+			exp:        10,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := latencyPercentile(test.latencies, test.percentile)
+			require.InDelta(t, test.exp, got, 1e-9)
+		})
+	}
+}
+
+func TestNewBenchmarkResult(t *testing.T) {
+	stats := &benchmarkStats{
+		latencies: []time.Duration{
+			4 * time.Millisecond,
+			time.Millisecond,
+			3 * time.Millisecond,
+			2 * time.Millisecond,
+		},
+		bytes: 4096,
+		errors: map[string]int64{
+			"context canceled":         2,
+			"NOT_LEADER_FOR_PARTITION": 1,
+		},
+	}
+	got := newBenchmarkResult("foo", 2*time.Second, stats)
+	require.Equal(t, &benchmarkResult{
+		Topic:            "foo",
+		DurationSeconds:  2,
+		Records:          4,
+		Bytes:            4096,
+		Errors:           3,
+		RecordsPerSecond: 2,
+		BytesPerSecond:   2048,
+		LatencyMs:        benchmarkLatency{P50: 2, P99: 4, P999: 4},
+		ErrorCounts: map[string]int64{
+			"context canceled":         2,
+			"NOT_LEADER_FOR_PARTITION": 1,
+		},
+	}, got)
+
+	empty := newBenchmarkResult("foo", 0, &benchmarkStats{errors: map[string]int64{}})
+	require.Equal(t, &benchmarkResult{Topic: "foo"}, empty)
+}
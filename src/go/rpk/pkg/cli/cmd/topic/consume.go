@@ -49,6 +49,7 @@ type consumer struct {
 	metaOnly bool // specific to -f json
 
 	resetOffset kgo.Offset // defaults to NoResetOffset, can be start or end
+	untilOffset int64      // last offset to consume, inclusive; -1 if unbounded
 
 	// If an end offset is specified, we immediately look up where we will
 	// end and quit rpk when we hit the end.
@@ -137,6 +138,7 @@ func newConsumeCommand(fs afero.Fs) *cobra.Command {
 
 	cmd.Flags().StringVarP(&format, "format", "f", "json", "Output format (see --help for details)")
 	cmd.Flags().IntVarP(&c.num, "num", "n", 0, "Quit after consuming this number of records (0 is unbounded)")
+	cmd.Flags().Int64Var(&c.untilOffset, "until-offset", -1, "Quit after consuming this offset of each partition, inclusive (-1 is unbounded)")
 	cmd.Flags().BoolVar(&c.pretty, "pretty-print", true, "Pretty print each record over multiple lines (for -f json)")
 	cmd.Flags().BoolVar(&c.metaOnly, "meta-only", false, "Print all record info except the record value (for -f json)")
 
@@ -223,8 +225,9 @@ func (c *consumer) writeRecordJSON(r *kgo.Record) {
 		Headers   []Header `json:"headers,omitempty"`
 		Timestamp int64    `json:"timestamp"` // millis
 
-		Partition int32 `json:"partition"`
-		Offset    int64 `json:"offset"`
+		Partition   int32  `json:"partition"`
+		Offset      int64  `json:"offset"`
+		Compression string `json:"compression"` // of the batch
 	}{
 		Topic:     r.Topic,
 		Key:       string(r.Key),
@@ -232,8 +235,9 @@ func (c *consumer) writeRecordJSON(r *kgo.Record) {
 		Headers:   make([]Header, 0, len(r.Headers)),
 		Timestamp: r.Timestamp.UnixNano() / 1e6,
 
-		Partition: r.Partition,
-		Offset:    r.Offset,
+		Partition:   r.Partition,
+		Offset:      r.Offset,
+		Compression: compressionName(r.Attrs.CompressionType()),
 	}
 
 	if c.metaOnly {
@@ -267,6 +271,9 @@ func (c *consumer) parseOffset(
 	offset string, topics []string, adm *kadm.Client,
 ) error {
 	if strings.HasPrefix(offset, "@") { // timestamp offset; strip @
+		if c.untilOffset >= 0 {
+			return errors.New("--until-offset cannot be used with timestamp offsets")
+		}
 		offset = offset[1:]
 		return c.parseTimeOffset(offset, topics, adm)
 	}
@@ -275,6 +282,15 @@ func (c *consumer) parseOffset(
 	if err != nil {
 		return fmt.Errorf("unable to parse offset: %v", err)
 	}
+	if c.untilOffset >= 0 {
+		if hasEnd || currentEnd || atEnd {
+			return errors.New("--until-offset cannot be used with offsets relative to the end or with their own end")
+		}
+		end, hasEnd = c.untilOffset+1, true
+		if !atStart && end <= start {
+			return fmt.Errorf("--until-offset %d is before the start offset %d", c.untilOffset, start)
+		}
+	}
 
 	if atStart {
 		c.resetOffset = kgo.NewOffset().AtStart().Relative(rel)
@@ -302,32 +318,47 @@ func (c *consumer) parseOffset(
 		return fmt.Errorf("unable to list end offsets: %v", err)
 	}
 
-	c.setParts(&c.partStarts, lstart, func(o int64) int64 {
-		if !atStart && o < start {
-			o = start
+	c.setParts(&c.partStarts, lstart, func(t string, p int32, o int64) int64 {
+		if atStart {
+			return o + rel
 		}
-		return o
+		return clampOffset(t, p, start, o, false)
 	})
-	c.setParts(&c.partEnds, lend, func(o int64) int64 {
-		if !currentEnd && o > end {
-			o = end
+	c.setParts(&c.partEnds, lend, func(t string, p int32, o int64) int64 {
+		if currentEnd {
+			return o
 		}
-		return o
+		return clampOffset(t, p, end, o, true)
 	})
 	return nil
 }
 
+// clampOffset bounds the offset requested for a partition by its listed one,
+// warning if it's out of the partition: starts below the log start offset are
+// raised to it, and ends past the high watermark are lowered to it.
+func clampOffset(t string, p int32, requested, listed int64, isEnd bool) int64 {
+	switch {
+	case !isEnd && requested < listed:
+		fmt.Fprintf(os.Stderr, "WARN: topic %s partition %d: start offset %d is below the log start offset %d, consuming from %d\n", t, p, requested, listed, listed)
+		return listed
+	case isEnd && requested > listed:
+		fmt.Fprintf(os.Stderr, "WARN: topic %s partition %d: end offset %d is past the high watermark %d, consuming until %d\n", t, p, requested-1, listed, listed-1)
+		return listed
+	}
+	return requested
+}
+
 // Setting partStarts and partEnds is identical, so we use a small closure to
 // capture the logic: if partitions are specified, we keep only those,
 // otherwise we keep all partitions. The optional offsetFn can be used to
-// override the listed offset (bound to the start, end).
+// override the listed offset of each partition (bound to the start, end).
 func (c *consumer) setParts(
 	mp *map[string]map[int32]int64,
 	l kadm.ListedOffsets,
-	offsetFn func(int64) int64,
+	offsetFn func(t string, p int32, o int64) int64,
 ) {
 	if offsetFn == nil {
-		offsetFn = func(o int64) int64 { return o }
+		offsetFn = func(_ string, _ int32, o int64) int64 { return o }
 	}
 	toffsets := make(map[string]map[int32]int64)
 	*mp = toffsets
@@ -336,12 +367,12 @@ func (c *consumer) setParts(
 		toffsets[t] = poffsets
 		for _, p := range c.partitions {
 			if l, exists := lps[p]; exists {
-				poffsets[p] = offsetFn(l.Offset)
+				poffsets[p] = offsetFn(t, p, l.Offset)
 			}
 		}
 		if len(c.partitions) == 0 {
 			for p, l := range lps {
-				poffsets[p] = offsetFn(l.Offset)
+				poffsets[p] = offsetFn(t, p, l.Offset)
 			}
 		}
 	}
@@ -728,6 +759,15 @@ func (c *consumer) intoOptions(topics []string) ([]kgo.Opt, error) {
 	return opts, nil
 }
 
+// compressionName returns the name of the compression type of a record batch,
+// see compressionCodecs.
+func compressionName(compressionType uint8) string {
+	if int(compressionType) < len(compressionCodecs) {
+		return compressionCodecs[compressionType]
+	}
+	return fmt.Sprintf("unknown (%d)", compressionType)
+}
+
 const helpConsume = `Consume records from topics.
 
 Consuming records reads from any amount of input topics, formats each record
@@ -735,7 +775,8 @@ according to --format, and prints them to STDOUT. The output formatter
 understands a wide variety of formats.
 
 The default output format "--format json" is a special format that outputs each
-record as JSON. There may be more single-word-no-escapes formats added later.
+record as JSON, along with the compression codec of its batch, e.g. "zstd".
+There may be more single-word-no-escapes formats added later.
 Outside of these special formats, formatting follows the rules described below.
 
 Formatting output is based on percent escapes and modifiers. Slashes can be
@@ -948,6 +989,14 @@ specify consuming from the start and the end.
     @:t       consume until a given timestamp
     @t1:t2    consume from timestamp t1 until timestamp t2
 
+The --until-offset flag sets the last offset to consume of each partition,
+inclusive, for offsets without an end: e.g. '-p 3 -o 1000 --until-offset 1050'
+consumes offsets 1000 through 1050 of partition 3 and quits. Offsets below the
+log start offset of a partition are consumed from its log start offset, and
+ends past the high watermark stop at the high watermark, with a warning on
+stderr. Partitions with nothing to consume in the range are skipped. With
+-f json, each record includes its partition, offset and timestamp.
+
 There are a few options for timestamps, with each option being evaluated
 until one succeeds:
 
@@ -335,3 +335,21 @@ func TestParseConsumeTimestamp(t *testing.T) {
 		}
 	}
 }
+
+func TestClampOffset(t *testing.T) {
+	for i, test := range []struct {
+		requested int64
+		listed    int64
+		isEnd     bool
+		exp       int64
+	}{
+		{requested: 1000, listed: 10, exp: 1000},                // start within the log
+		{requested: 1000, listed: 1200, exp: 1200},              // start below the log start
+		{requested: 1051, listed: 2000, isEnd: true, exp: 1051}, // end within the log
+		{requested: 1051, listed: 1020, isEnd: true, exp: 1020}, // end past the high watermark
+	} {
+		if got := clampOffset("foo", 3, test.requested, test.listed, test.isEnd); got != test.exp {
+			t.Errorf("#%d: got %d != exp %d", i, got, test.exp)
+		}
+	}
+}
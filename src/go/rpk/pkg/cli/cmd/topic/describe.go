@@ -11,6 +11,7 @@ package topic
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -33,6 +34,9 @@ func newDescribeCommand(fs afero.Fs) *cobra.Command {
 		summary    bool
 		configs    bool
 		partitions bool
+
+		configsSource bool
+		format        string
 	)
 	cmd := &cobra.Command{
 		Use:     "describe [TOPIC]",
@@ -43,6 +47,15 @@ func newDescribeCommand(fs afero.Fs) *cobra.Command {
 This command prints detailed information about a topic. There are three
 potential sections: a summary of the topic, the topic configs, and a detailed
 partitions section. By default, the summary and configs sections are printed.
+
+The SOURCE of a config tells where its value comes from, e.g. a topic override
+(DYNAMIC_TOPIC_CONFIG) or the default (DEFAULT_CONFIG). With
+--print-configs-source, only the config section is printed, and --verbose
+includes the synonyms of each config: the full chain of values it would take,
+in order of precedence, from the topic override down to the default.
+
+With --format json, the configs are printed as a JSON array, with the source
+of each config and, with --print-configs-source and --verbose, its synonyms.
 `,
 
 		Args: cobra.ExactArgs(1),
@@ -57,6 +70,17 @@ partitions section. By default, the summary and configs sections are printed.
 
 			topic := topicArg[0]
 
+			if format != "text" && format != "json" {
+				out.Die("unsupported format %q, use either text or json", format)
+			}
+			if configsSource || format == "json" {
+				if summary || partitions || all {
+					out.Die("only the config section can be printed with --print-configs-source or --format json")
+				}
+				configs = true
+			}
+			synonyms := configsSource && p.Verbose
+
 			// By default, if neither are specified, we opt in to
 			// the config section only.
 			if !summary && !configs && !partitions {
@@ -122,6 +146,7 @@ partitions section. By default, the summary and configs sections are printed.
 				reqResource.ResourceType = kmsg.ConfigResourceTypeTopic
 				reqResource.ResourceName = topic
 				req.Resources = append(req.Resources, reqResource)
+				req.IncludeSynonyms = synonyms
 
 				resp, err := req.RequestWith(context.Background(), cl)
 				out.MaybeDie(err, "unable to request configs: %v", err)
@@ -131,17 +156,18 @@ partitions section. By default, the summary and configs sections are printed.
 				err = kerr.ErrorForCode(resp.Resources[0].ErrorCode)
 				out.MaybeDie(err, "config response contained error: %v", err)
 
+				types.Sort(resp)
+				described := describeConfigs(resp.Resources[0].Configs, synonyms)
+				if format == "json" {
+					asJSON, err := json.MarshalIndent(described, "", "  ")
+					out.MaybeDie(err, "unable to format the configs as JSON: %v", err)
+					fmt.Println(string(asJSON))
+					return
+				}
 				tw := out.NewTable("KEY", "VALUE", "SOURCE")
 				defer tw.Flush()
-				types.Sort(resp)
-				for _, config := range resp.Resources[0].Configs {
-					var val string
-					if config.IsSensitive {
-						val = "(sensitive)"
-					} else if config.Value != nil {
-						val = *config.Value
-					}
-					tw.Print(config.Name, val, config.Source)
+				for _, row := range describeConfigsRows(described) {
+					tw.Print(row...)
 				}
 			})
 
@@ -183,10 +209,76 @@ partitions section. By default, the summary and configs sections are printed.
 	cmd.Flags().BoolVarP(&configs, "print-configs", "c", false, "Print the config section")
 	cmd.Flags().BoolVarP(&partitions, "print-partitions", "p", false, "Print the detailed partitions section")
 	cmd.Flags().BoolVarP(&all, "print-all", "a", false, "Print all sections")
+	cmd.Flags().BoolVar(&configsSource, "print-configs-source", false, "Print the config section only, including the synonyms of each config with --verbose")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
 
 	return cmd
 }
 
+// describedConfig is a topic config as printed with '--format json'.
+type describedConfig struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+	// Source is where the value comes from, e.g. DYNAMIC_TOPIC_CONFIG.
+	Source string `json:"source"`
+	// Synonyms is the chain of values the config would take, in order of
+	// precedence, only included with --print-configs-source --verbose.
+	Synonyms []describedConfigSynonym `json:"synonyms,omitempty"`
+}
+
+type describedConfigSynonym struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+func describeConfigs(configs []kmsg.DescribeConfigsResponseResourceConfig, synonyms bool) []describedConfig {
+	described := make([]describedConfig, 0, len(configs))
+	for _, config := range configs {
+		d := describedConfig{
+			Name:      config.Name,
+			Value:     describeConfigValue(config.Value, config.IsSensitive),
+			Sensitive: config.IsSensitive,
+			Source:    config.Source.String(),
+		}
+		if synonyms {
+			for _, syn := range config.ConfigSynonyms {
+				d.Synonyms = append(d.Synonyms, describedConfigSynonym{
+					Name:   syn.Name,
+					Value:  describeConfigValue(syn.Value, config.IsSensitive),
+					Source: syn.Source.String(),
+				})
+			}
+		}
+		described = append(described, d)
+	}
+	return described
+}
+
+func describeConfigValue(value *string, sensitive bool) string {
+	if sensitive {
+		return "(sensitive)"
+	}
+	if value != nil {
+		return *value
+	}
+	return ""
+}
+
+// describeConfigsRows returns the KEY, VALUE and SOURCE rows of the configs,
+// each followed by its synonyms, indented.
+func describeConfigsRows(configs []describedConfig) [][]interface{} {
+	var rows [][]interface{}
+	for _, config := range configs {
+		rows = append(rows, []interface{}{config.Name, config.Value, config.Source})
+		for _, syn := range config.Synonyms {
+			rows = append(rows, []interface{}{"  " + syn.Name, syn.Value, syn.Source})
+		}
+	}
+	return rows
+}
+
 // We optionally include the following columns:
 //   - offline-replicas, if any are offline
 //   - load-error, if metadata indicates load errors any partitions
@@ -134,3 +134,60 @@ func TestDescribePartitions(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeConfigs(t *testing.T) {
+	configs := []kmsg.DescribeConfigsResponseResourceConfig{
+		{
+			Name:   "retention.ms",
+			Value:  kmsg.StringPtr("3600000"),
+			Source: kmsg.ConfigSourceDynamicTopicConfig,
+			ConfigSynonyms: []kmsg.DescribeConfigsResponseResourceConfigConfigSynonym{
+				{Name: "retention.ms", Value: kmsg.StringPtr("3600000"), Source: kmsg.ConfigSourceDynamicTopicConfig},
+				{Name: "log_retention_ms", Value: kmsg.StringPtr("604800000"), Source: kmsg.ConfigSourceDefaultConfig},
+			},
+		},
+		{
+			Name:        "sasl.secret",
+			Value:       kmsg.StringPtr("hunter2"),
+			Source:      kmsg.ConfigSourceStaticBrokerConfig,
+			IsSensitive: true,
+			ConfigSynonyms: []kmsg.DescribeConfigsResponseResourceConfigConfigSynonym{
+				{Name: "sasl_secret", Value: kmsg.StringPtr("hunter2"), Source: kmsg.ConfigSourceStaticBrokerConfig},
+			},
+		},
+		{
+			Name:   "cleanup.policy",
+			Source: kmsg.ConfigSourceDefaultConfig,
+		},
+	}
+
+	t.Run("without synonyms", func(t *testing.T) {
+		got := describeConfigs(configs, false)
+		require.Equal(t, []describedConfig{
+			{Name: "retention.ms", Value: "3600000", Source: "DYNAMIC_TOPIC_CONFIG"},
+			{Name: "sasl.secret", Value: "(sensitive)", Sensitive: true, Source: "STATIC_BROKER_CONFIG"},
+			{Name: "cleanup.policy", Value: "", Source: "DEFAULT_CONFIG"},
+		}, got)
+		require.Equal(t, [][]interface{}{
+			{"retention.ms", "3600000", "DYNAMIC_TOPIC_CONFIG"},
+			{"sasl.secret", "(sensitive)", "STATIC_BROKER_CONFIG"},
+			{"cleanup.policy", "", "DEFAULT_CONFIG"},
+		}, describeConfigsRows(got))
+	})
+
+	t.Run("with synonyms", func(t *testing.T) {
+		got := describeConfigs(configs, true)
+		require.Equal(t, []describedConfigSynonym{
+			{Name: "retention.ms", Value: "3600000", Source: "DYNAMIC_TOPIC_CONFIG"},
+			{Name: "log_retention_ms", Value: "604800000", Source: "DEFAULT_CONFIG"},
+		}, got[0].Synonyms)
+		require.Equal(t, [][]interface{}{
+			{"retention.ms", "3600000", "DYNAMIC_TOPIC_CONFIG"},
+			{"  retention.ms", "3600000", "DYNAMIC_TOPIC_CONFIG"},
+			{"  log_retention_ms", "604800000", "DEFAULT_CONFIG"},
+			{"sasl.secret", "(sensitive)", "STATIC_BROKER_CONFIG"},
+			{"  sasl_secret", "(sensitive)", "STATIC_BROKER_CONFIG"},
+			{"cleanup.policy", "", "DEFAULT_CONFIG"},
+		}, describeConfigsRows(got))
+	})
+}
@@ -0,0 +1,322 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+func newLintCommand(fs afero.Fs) *cobra.Command {
+	var (
+		all    bool
+		format string
+	)
+	cmd := &cobra.Command{
+		Use:   "lint [TOPICS...]",
+		Short: "Check the configs of topics for known problematic combinations",
+		Long: `Check the configs of topics for known problematic combinations.
+
+This command fetches the effective configs of the given topics, or of all the
+topics with --all, and flags the combinations known to cause trouble, e.g. a
+compacted topic rolling its segments every few seconds, or a local retention
+conflicting with the tiered storage retention. Each issue has a severity and a
+suggested fix.
+
+The command exits with code 1 if any issue has the error severity, or if the
+configs of a topic can't be fetched. With --format json, the issues are printed
+as a JSON array, for CI.
+`,
+		Run: func(cmd *cobra.Command, topics []string) {
+			if format != "text" && format != "json" {
+				out.Die("unsupported format %q, use either text or json", format)
+			}
+			if all == (len(topics) > 0) {
+				out.Die("either pass the topics to lint or use --all")
+			}
+
+			p := config.ParamsFromCommand(cmd)
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "unable to load config: %v", err)
+
+			adm, err := kafka.NewAdmin(fs, p, cfg)
+			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
+			defer adm.Close()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+
+			linted, failures, err := describeLintTopics(ctx, adm, topics)
+			out.MaybeDie(err, "unable to describe topics: %v", err)
+			issues := lintTopics(linted, lintRules)
+
+			exit1 := len(failures) > 0
+			for _, issue := range issues {
+				exit1 = exit1 || issue.Severity == lintError
+			}
+			defer func() {
+				if exit1 {
+					os.Exit(1)
+				}
+			}()
+			for _, failure := range failures {
+				fmt.Fprintln(os.Stderr, failure)
+			}
+
+			if format == "json" {
+				if issues == nil {
+					issues = []lintIssue{}
+				}
+				asJSON, err := json.MarshalIndent(issues, "", "  ")
+				out.MaybeDie(err, "unable to format the issues as JSON: %v", err)
+				fmt.Println(string(asJSON))
+				return
+			}
+			if len(issues) == 0 {
+				fmt.Printf("No issues found in %d topic(s).\n", len(linted))
+				return
+			}
+			tw := out.NewTable("TOPIC", "SEVERITY", "RULE", "ISSUE", "FIX")
+			defer tw.Flush()
+			for _, issue := range issues {
+				tw.Print(issue.Topic, issue.Severity, issue.Rule, issue.Message, issue.Fix)
+			}
+		},
+	}
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Lint all the topics, internal ones excluded")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
+	return cmd
+}
+
+// lintTopic is the effective config of a topic, as checked by the lint rules.
+type lintTopic struct {
+	Name     string
+	Replicas int
+	Configs  map[string]string
+}
+
+// int returns the config as a number, and whether it's set to one.
+func (t lintTopic) int(key string) (int64, bool) {
+	v, err := strconv.ParseInt(t.Configs[key], 10, 64)
+	return v, err == nil
+}
+
+func (t lintTopic) bool(key string) bool {
+	v, _ := strconv.ParseBool(t.Configs[key])
+	return v
+}
+
+// hasPolicy returns whether the cleanup.policy of the topic includes policy,
+// e.g. 'compact,delete' includes both.
+func (t lintTopic) hasPolicy(policy string) bool {
+	for _, p := range strings.Split(t.Configs["cleanup.policy"], ",") {
+		if strings.TrimSpace(p) == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// describeLintTopics returns the topics to lint with their effective configs,
+// and why the ones which couldn't be described failed.
+func describeLintTopics(
+	ctx context.Context, adm *kadm.Client, topics []string,
+) ([]lintTopic, []string, error) {
+	details, err := adm.ListTopics(ctx, topics...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		names    []string
+		failures []string
+	)
+	for _, d := range details.Sorted() {
+		if d.Err != nil {
+			failures = append(failures, fmt.Sprintf("unable to lint %q: %v", d.Topic, d.Err))
+			continue
+		}
+		names = append(names, d.Topic)
+	}
+	configs, err := adm.DescribeTopicConfigs(ctx, names...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var linted []lintTopic
+	for _, rc := range configs {
+		if rc.Err != nil {
+			failures = append(failures, fmt.Sprintf("unable to lint %q: %v", rc.Name, rc.Err))
+			continue
+		}
+		t := lintTopic{
+			Name:     rc.Name,
+			Replicas: details[rc.Name].Partitions.NumReplicas(),
+			Configs:  make(map[string]string, len(rc.Configs)),
+		}
+		for _, c := range rc.Configs {
+			if c.Value != nil {
+				t.Configs[c.Key] = *c.Value
+			}
+		}
+		linted = append(linted, t)
+	}
+	sort.Slice(linted, func(i, j int) bool { return linted[i].Name < linted[j].Name })
+	return linted, failures, nil
+}
+
+type lintSeverity string
+
+const (
+	lintError   lintSeverity = "error"
+	lintWarning lintSeverity = "warning"
+)
+
+// lintIssue is an issue found by a lint rule, as printed with --format json.
+type lintIssue struct {
+	Topic    string       `json:"topic"`
+	Severity lintSeverity `json:"severity"`
+	Rule     string       `json:"rule"`
+	Message  string       `json:"message"`
+	Fix      string       `json:"fix"`
+}
+
+// lintRule is a known problematic combination of topic configs. Check
+// returns the issue found in the topic and its suggested fix, if any.
+type lintRule struct {
+	Name     string
+	Severity lintSeverity
+	Check    func(t lintTopic) (message, fix string, found bool)
+}
+
+// lintRules are the rules topics are linted with, add new ones here.
+var lintRules = []lintRule{
+	{
+		Name:     "compact-short-segment-ms",
+		Severity: lintError,
+		Check: func(t lintTopic) (string, string, bool) {
+			segmentMs, ok := t.int("segment.ms")
+			if !t.hasPolicy("compact") || !ok || segmentMs <= 0 || segmentMs >= (10*time.Minute).Milliseconds() {
+				return "", "", false
+			}
+			return fmt.Sprintf("compacted topic rolls a segment every %s: the many small segments slow down compaction and recovery", lintDuration(segmentMs)),
+				alterConfigFix(t.Name, "segment.ms", time.Hour.Milliseconds()), true
+		},
+	},
+	{
+		Name:     "min-insync-above-replicas",
+		Severity: lintError,
+		Check: func(t lintTopic) (string, string, bool) {
+			minISR, ok := t.int("min.insync.replicas")
+			if !ok || t.Replicas <= 0 || minISR <= int64(t.Replicas) {
+				return "", "", false
+			}
+			return fmt.Sprintf("min.insync.replicas %d is above the replication factor %d: produce requests with acks=all fail", minISR, t.Replicas),
+				alterConfigFix(t.Name, "min.insync.replicas", int64(t.Replicas)), true
+		},
+	},
+	{
+		Name:     "segment-ms-above-retention-ms",
+		Severity: lintWarning,
+		Check: func(t lintTopic) (string, string, bool) {
+			segmentMs, okSegment := t.int("segment.ms")
+			retentionMs, okRetention := t.int("retention.ms")
+			if !t.hasPolicy("delete") || !okSegment || !okRetention || retentionMs <= 0 || segmentMs <= retentionMs {
+				return "", "", false
+			}
+			return fmt.Sprintf("segments roll every %s, which is above the retention of %s: only full segments are deleted, so data is kept up to segment.ms", lintDuration(segmentMs), lintDuration(retentionMs)),
+				alterConfigFix(t.Name, "segment.ms", retentionMs), true
+		},
+	},
+	{
+		Name:     "local-retention-above-retention",
+		Severity: lintWarning,
+		Check: func(t lintTopic) (string, string, bool) {
+			localMs, okLocal := t.int("retention.local.target.ms")
+			retentionMs, okRetention := t.int("retention.ms")
+			if !t.bool("redpanda.remote.write") || !okLocal || !okRetention || retentionMs <= 0 || (localMs >= 0 && localMs <= retentionMs) {
+				return "", "", false
+			}
+			return fmt.Sprintf("the local retention of %s is above the retention of %s, which caps it: data isn't kept locally as long as requested", lintRetention(localMs), lintDuration(retentionMs)),
+				alterConfigFix(t.Name, "retention.local.target.ms", retentionMs), true
+		},
+	},
+	{
+		Name:     "tiered-unlimited-local-retention",
+		Severity: lintWarning,
+		Check: func(t lintTopic) (string, string, bool) {
+			localMs, ok := t.int("retention.local.target.ms")
+			retentionMs, _ := t.int("retention.ms")
+			// Unlimited retentions are covered by the rule above.
+			if !t.bool("redpanda.remote.write") || !ok || localMs >= 0 || retentionMs > 0 {
+				return "", "", false
+			}
+			return "the local retention of a tiered storage topic is unlimited: its uploaded segments never free the local disk",
+				alterConfigFix(t.Name, "retention.local.target.ms", (24 * time.Hour).Milliseconds()), true
+		},
+	},
+}
+
+// lintTopics returns the issues the rules found in the topics, sorted by
+// topic, with errors first, then in rule order.
+func lintTopics(topics []lintTopic, rules []lintRule) []lintIssue {
+	var issues []lintIssue
+	for _, t := range topics {
+		var warnings []lintIssue
+		for _, rule := range rules {
+			message, fix, found := rule.Check(t)
+			if !found {
+				continue
+			}
+			issue := lintIssue{
+				Topic:    t.Name,
+				Severity: rule.Severity,
+				Rule:     rule.Name,
+				Message:  message,
+				Fix:      fix,
+			}
+			if rule.Severity == lintError {
+				issues = append(issues, issue)
+			} else {
+				warnings = append(warnings, issue)
+			}
+		}
+		issues = append(issues, warnings...)
+	}
+	return issues
+}
+
+func alterConfigFix(topic, key string, value int64) string {
+	return fmt.Sprintf("rpk topic alter-config %s --set %s=%d", topic, key, value)
+}
+
+func lintDuration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// lintRetention is lintDuration for retentions, which are unlimited if
+// negative.
+func lintRetention(ms int64) string {
+	if ms < 0 {
+		return "unlimited"
+	}
+	return lintDuration(ms)
+}
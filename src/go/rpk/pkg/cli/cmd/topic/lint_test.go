@@ -0,0 +1,133 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintTopics(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int
+		configs  map[string]string
+		exp      []string
+	}{
+		{
+			name:     "defaults",
+			replicas: 3,
+			configs: map[string]string{
+				"cleanup.policy":      "delete",
+				"segment.ms":          "1209600000",
+				"retention.ms":        "604800000",
+				"min.insync.replicas": "1",
+			},
+			exp: []string{"segment-ms-above-retention-ms"},
+		},
+		{
+			name: "compacted topic with a tiny segment.ms",
+			configs: map[string]string{
+				"cleanup.policy": "compact,delete",
+				"segment.ms":     "10000",
+				"retention.ms":   "-1",
+			},
+			exp: []string{"compact-short-segment-ms"},
+		},
+		{
+			name: "compacted topic with an hourly segment.ms",
+			configs: map[string]string{
+				"cleanup.policy": "compact",
+				"segment.ms":     "3600000",
+			},
+		},
+		{
+			name:     "min.insync.replicas above the replication factor",
+			replicas: 1,
+			configs: map[string]string{
+				"min.insync.replicas": "2",
+			},
+			exp: []string{"min-insync-above-replicas"},
+		},
+		{
+			name: "local retention above the retention",
+			configs: map[string]string{
+				"redpanda.remote.write":     "true",
+				"retention.ms":              "3600000",
+				"retention.local.target.ms": "86400000",
+			},
+			exp: []string{"local-retention-above-retention"},
+		},
+		{
+			name: "unlimited local retention",
+			configs: map[string]string{
+				"redpanda.remote.write":     "true",
+				"retention.ms":              "-1",
+				"retention.local.target.ms": "-1",
+			},
+			exp: []string{"tiered-unlimited-local-retention"},
+		},
+		{
+			name: "local retention without tiered storage",
+			configs: map[string]string{
+				"redpanda.remote.write":     "false",
+				"retention.local.target.ms": "-1",
+			},
+		},
+		{
+			name:     "errors first",
+			replicas: 1,
+			configs: map[string]string{
+				"cleanup.policy":      "compact,delete",
+				"segment.ms":          "7200000",
+				"retention.ms":        "3600000",
+				"min.insync.replicas": "3",
+			},
+			exp: []string{"min-insync-above-replicas", "segment-ms-above-retention-ms"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues := lintTopics([]lintTopic{{Name: "foo", Replicas: test.replicas, Configs: test.configs}}, lintRules)
+			var rules []string
+			for _, issue := range issues {
+				require.Equal(t, "foo", issue.Topic)
+				require.NotEmpty(t, issue.Message)
+				require.Contains(t, issue.Fix, "rpk topic alter-config foo --set ")
+				rules = append(rules, issue.Rule)
+			}
+			require.Equal(t, test.exp, rules)
+		})
+	}
+}
+
+func TestLintTopics_severity(t *testing.T) {
+	issues := lintTopics([]lintTopic{
+		{Name: "bar", Configs: map[string]string{"cleanup.policy": "delete", "segment.ms": "7200000", "retention.ms": "3600000"}},
+		{Name: "foo", Replicas: 1, Configs: map[string]string{"min.insync.replicas": "2"}},
+	}, lintRules)
+	require.Equal(t, []lintIssue{
+		{
+			Topic:    "bar",
+			Severity: lintWarning,
+			Rule:     "segment-ms-above-retention-ms",
+			Message:  "segments roll every 2h0m0s, which is above the retention of 1h0m0s: only full segments are deleted, so data is kept up to segment.ms",
+			Fix:      "rpk topic alter-config bar --set segment.ms=3600000",
+		},
+		{
+			Topic:    "foo",
+			Severity: lintError,
+			Rule:     "min-insync-above-replicas",
+			Message:  "min.insync.replicas 2 is above the replication factor 1: produce requests with acks=all fail",
+			Fix:      "rpk topic alter-config foo --set min.insync.replicas=1",
+		},
+	}, issues)
+}
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
@@ -31,6 +32,10 @@ func newProduceCommand(fs afero.Fs) *cobra.Command {
 		recHeaders []string
 		partition  int32
 
+		keyFile   string
+		valueFile string
+		count     int
+
 		inFormat        string
 		outFormat       string
 		compression     string
@@ -55,35 +60,17 @@ func newProduceCommand(fs afero.Fs) *cobra.Command {
 			opts := []kgo.Opt{
 				kgo.ProduceRequestTimeout(5 * time.Second),
 			}
-			switch compression {
-			case "none":
-				opts = append(opts, kgo.ProducerBatchCompression(kgo.NoCompression()))
-			case "gzip":
-				opts = append(opts, kgo.ProducerBatchCompression(kgo.GzipCompression()))
-			case "snappy":
-				opts = append(opts, kgo.ProducerBatchCompression(kgo.SnappyCompression()))
-			case "lz4":
-				opts = append(opts, kgo.ProducerBatchCompression(kgo.Lz4Compression()))
-			case "zstd":
-				opts = append(opts, kgo.ProducerBatchCompression(kgo.ZstdCompression()))
-			default:
-				out.Die("invalid compression codec %q", compression)
-			}
+			codec, err := compressionCodec(compression)
+			out.MaybeDieErr(err)
+			opts = append(opts, kgo.ProducerBatchCompression(codec))
 
 			if allowAutoTopicCreation {
 				opts = append(opts, kgo.AllowAutoTopicCreation())
 			}
 
-			switch acks {
-			case -1:
-				opts = append(opts, kgo.RequiredAcks(kgo.AllISRAcks()))
-			case 0:
-				opts = append(opts, kgo.RequiredAcks(kgo.NoAck()), kgo.DisableIdempotentWrite())
-			case 1:
-				opts = append(opts, kgo.RequiredAcks(kgo.LeaderAck()), kgo.DisableIdempotentWrite())
-			default:
-				out.Die("invalid acks %d, only -1, 0, and 1 are supported", acks)
-			}
+			acksOpts, err := produceAcksOpts(acks)
+			out.MaybeDieErr(err)
+			opts = append(opts, acksOpts...)
 
 			switch {
 			case timeout == 0:
@@ -106,6 +93,16 @@ func newProduceCommand(fs afero.Fs) *cobra.Command {
 			if len(inFormat) == 0 {
 				out.Die("invalid empty format")
 			}
+			fromFiles := keyFile != "" || valueFile != ""
+			if count < 1 {
+				out.Die("invalid --count %d, must be at least 1", count)
+			}
+			if count > 1 && !fromFiles {
+				out.Die("--count requires --key-file or --value-file")
+			}
+			if fromFiles && defaultTopic == "" {
+				out.Die("topic to produce to is missing, check --help for produce syntax")
+			}
 
 			// Parse our input/output formats.
 			inf, err := kgo.NewRecordReader(os.Stdin, inFormat)
@@ -135,6 +132,37 @@ func newProduceCommand(fs afero.Fs) *cobra.Command {
 			defer cl.Close()
 			defer cl.Flush(context.Background())
 
+			onProduced := func(r *kgo.Record, err error) {
+				out.MaybeDie(err, "unable to produce record: %v", err)
+				if outf != nil {
+					outfBuf = outf.AppendRecord(outfBuf[:0], r)
+					os.Stdout.Write(outfBuf)
+				}
+			}
+
+			if fromFiles {
+				k, v, err := readRecordFiles(fs, keyFile, valueFile)
+				out.MaybeDieErr(err)
+				if len(k) == 0 && len(key) > 0 {
+					k = []byte(key)
+				}
+				if tombstone && len(v) == 0 {
+					v = nil
+				}
+				// The client owns the records it produces until their
+				// promise is called: each copy is a distinct record, all
+				// sharing the same key, value, and headers.
+				for i := 0; i < count; i++ {
+					cl.Produce(context.Background(), &kgo.Record{
+						Partition: partition,
+						Headers:   headers,
+						Key:       k,
+						Value:     v,
+					}, onProduced)
+				}
+				return
+			}
+
 			for {
 				r := &kgo.Record{
 					Partition: partition,
@@ -155,19 +183,13 @@ func newProduceCommand(fs afero.Fs) *cobra.Command {
 				if tombstone && len(r.Value) == 0 {
 					r.Value = nil
 				}
-				cl.Produce(context.Background(), r, func(r *kgo.Record, err error) {
-					out.MaybeDie(err, "unable to produce record: %v", err)
-					if outf != nil {
-						outfBuf = outf.AppendRecord(outfBuf[:0], r)
-						os.Stdout.Write(outfBuf)
-					}
-				})
+				cl.Produce(context.Background(), r, onProduced)
 			}
 		},
 	}
 
 	// The following flags require parsing before we initialize our client.
-	cmd.Flags().StringVarP(&compression, "compression", "z", "snappy", "Compression to use for producing batches (none, gzip, snapy, lz4, zstd)")
+	cmd.Flags().StringVarP(&compression, "compression", "z", "snappy", "Compression to use for producing batches (none, gzip, snappy, lz4, zstd)")
 	cmd.Flags().IntVar(&acks, "acks", -1, "Number of acks required for producing (-1=all, 0=none, 1=leader)")
 	cmd.Flags().DurationVar(&timeout, "delivery-timeout", 0, "Per-record delivery timeout, if non-zero, min 1s")
 	cmd.Flags().Int32VarP(&partition, "partition", "p", -1, "Partition to directly produce to, if non-negative (also allows %p parsing to set partitions)")
@@ -185,6 +207,12 @@ func newProduceCommand(fs afero.Fs) *cobra.Command {
 	cmd.Flags().StringVarP(&key, "key", "k", "", "A fixed key to use for each record (parsed input keys take precedence)")
 	cmd.Flags().BoolVarP(&tombstone, "tombstone", "Z", false, "Produce empty values as tombstones")
 	cmd.Flags().BoolVar(&allowAutoTopicCreation, "allow-auto-topic-creation", false, "Auto-create non-existent topics; requires auto_create_topics_enabled on the broker")
+	cmd.Flags().StringVar(&keyFile, "key-file", "", "File to read the key of the record from, as is, instead of parsing records from STDIN")
+	cmd.Flags().StringVar(&valueFile, "value-file", "", "File to read the value of the record from, as is, instead of parsing records from STDIN")
+	cmd.Flags().IntVar(&count, "count", 1, "Number of times to produce the record read from --key-file and --value-file")
+	cmd.MarkFlagsMutuallyExclusive("key", "key-file")
+	cmd.MarkFlagsMutuallyExclusive("format", "key-file")
+	cmd.MarkFlagsMutuallyExclusive("format", "value-file")
 
 	// Deprecated
 	cmd.Flags().IntVarP(new(int), "num", "n", 1, "")
@@ -197,6 +225,63 @@ func newProduceCommand(fs afero.Fs) *cobra.Command {
 	return cmd
 }
 
+// readRecordFiles reads the key and value of a record from the given files,
+// either of which may be empty to leave the key or value empty. The files are
+// read as is, without any decoding nor trimming of trailing newlines, so that
+// binary payloads are produced unchanged.
+func readRecordFiles(fs afero.Fs, keyFile, valueFile string) (key, value []byte, err error) {
+	if keyFile != "" {
+		if key, err = afero.ReadFile(fs, keyFile); err != nil {
+			return nil, nil, fmt.Errorf("unable to read key file: %w", err)
+		}
+	}
+	if valueFile != "" {
+		if value, err = afero.ReadFile(fs, valueFile); err != nil {
+			return nil, nil, fmt.Errorf("unable to read value file: %w", err)
+		}
+	}
+	return key, value, nil
+}
+
+// compressionCodecs are the names of the compression codecs, indexed by the
+// compression type of the record batch attributes.
+var compressionCodecs = []string{"none", "gzip", "snappy", "lz4", "zstd"}
+
+// compressionCodec returns the codec to compress the produced batches with,
+// failing before anything is produced if the name is not one of
+// compressionCodecs.
+func compressionCodec(name string) (kgo.CompressionCodec, error) {
+	switch name {
+	case "none":
+		return kgo.NoCompression(), nil
+	case "gzip":
+		return kgo.GzipCompression(), nil
+	case "snappy":
+		return kgo.SnappyCompression(), nil
+	case "lz4":
+		return kgo.Lz4Compression(), nil
+	case "zstd":
+		return kgo.ZstdCompression(), nil
+	}
+	return kgo.CompressionCodec{}, fmt.Errorf("invalid compression codec %q, the supported codecs are: %s",
+		name, strings.Join(compressionCodecs, ", "))
+}
+
+// produceAcksOpts returns the client options requiring the given number of
+// acks: -1 for all the in-sync replicas, 0 for none, or 1 for the leader.
+// Idempotent writes require all the acks.
+func produceAcksOpts(acks int) ([]kgo.Opt, error) {
+	switch acks {
+	case -1:
+		return []kgo.Opt{kgo.RequiredAcks(kgo.AllISRAcks())}, nil
+	case 0:
+		return []kgo.Opt{kgo.RequiredAcks(kgo.NoAck()), kgo.DisableIdempotentWrite()}, nil
+	case 1:
+		return []kgo.Opt{kgo.RequiredAcks(kgo.LeaderAck()), kgo.DisableIdempotentWrite()}, nil
+	}
+	return nil, fmt.Errorf("invalid acks %d, only -1, 0, and 1 are supported", acks)
+}
+
 const helpProduce = `Produce records to a topic.
 
 Producing records reads from STDIN, parses input according to --format, and
@@ -306,6 +391,18 @@ A big-endian uint16 key size, the text " foo ", and then that key:
 A value that can be two or three characters followed by a newline:
     -f '%v{re#...?#}\n'
 
+PRODUCING FROM FILES
+
+Rather than parsing records from STDIN, a single record can be assembled from
+files with --key-file and --value-file, each read as is: binary payloads are
+produced unchanged, and trailing newlines are kept. Headers from --header are
+added to the record, which is produced --count times, e.g. to replay a
+captured message:
+
+    rpk topic produce foo --key-file key.bin --value-file msg.avro -H trace=1 --count 10
+
+These flags require a topic argument, and can't be used along with --format.
+
 MISC
 
 Producing requires a topic to produce to. The topic can be specified either
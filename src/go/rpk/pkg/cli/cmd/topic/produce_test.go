@@ -0,0 +1,60 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topic
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestReadRecordFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	// Values are read as is: invalid UTF-8 and trailing newlines are kept.
+	value := []byte{0x00, 0xff, 0xfe, 'a', 0xc3, 0x28, '\n'}
+	require.NoError(t, afero.WriteFile(fs, "/tmp/key", []byte("user-1\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/tmp/value", value, 0o644))
+
+	key, got, err := readRecordFiles(fs, "/tmp/key", "/tmp/value")
+	require.NoError(t, err)
+	require.Equal(t, []byte("user-1\n"), key)
+	require.Equal(t, value, got)
+
+	key, got, err = readRecordFiles(fs, "", "/tmp/value")
+	require.NoError(t, err)
+	require.Nil(t, key)
+	require.Equal(t, value, got)
+
+	key, got, err = readRecordFiles(fs, "/tmp/key", "")
+	require.NoError(t, err)
+	require.Equal(t, []byte("user-1\n"), key)
+	require.Nil(t, got)
+
+	_, _, err = readRecordFiles(fs, "/tmp/key", "/tmp/missing")
+	require.Error(t, err)
+}
+
+func TestCompressionCodec(t *testing.T) {
+	for compressionType, name := range compressionCodecs {
+		_, err := compressionCodec(name)
+		require.NoError(t, err)
+		// Consumed batches report the codec they were produced with.
+		require.Equal(t, name, compressionName(uint8(compressionType)))
+	}
+	codec, err := compressionCodec("zstd")
+	require.NoError(t, err)
+	require.Equal(t, kgo.ZstdCompression(), codec)
+
+	_, err = compressionCodec("zstandard")
+	require.EqualError(t, err, `invalid compression codec "zstandard", the supported codecs are: none, gzip, snappy, lz4, zstd`)
+	require.Equal(t, "unknown (7)", compressionName(7))
+}
@@ -37,10 +37,13 @@ func NewCommand(fs afero.Fs) *cobra.Command {
 	command.AddCommand(
 		newAddPartitionsCommand(fs),
 		newAlterConfigCommand(fs),
+		newApplyCommand(fs),
+		newBenchmarkCommand(fs),
 		newConsumeCommand(fs),
 		newCreateCommand(fs),
 		newDeleteCommand(fs),
 		newDescribeCommand(fs),
+		newLintCommand(fs),
 		newListCommand(fs),
 		newProduceCommand(fs),
 	)
@@ -27,6 +27,8 @@ const (
 
 	DefaultBallastFilePath = "/var/lib/redpanda/data/ballast"
 	DefaultBallastFileSize = "1GiB"
+
+	DefaultDataDirMinFreePercent = 10
 )
 
 func DevDefault() *Config {
@@ -110,9 +112,15 @@ func setProduction(conf *Config) *Config {
 	conf.Rpk.TuneNetwork = true
 	conf.Rpk.TuneDiskScheduler = true
 	conf.Rpk.TuneNomerges = true
+	conf.Rpk.TuneDiskNrRequests = true
+	conf.Rpk.TuneDiskReadAhead = true
+	conf.Rpk.TuneDiskAddRandom = true
+	conf.Rpk.TuneDiskMaxSectors = true
+	conf.Rpk.TuneDiskRqAffinity = true
 	conf.Rpk.TuneDiskIrq = true
 	conf.Rpk.TuneFstrim = false
 	conf.Rpk.TuneCPU = true
+	conf.Rpk.TuneCPUGovernor = true
 	conf.Rpk.TuneAioEvents = true
 	conf.Rpk.TuneClocksource = true
 	conf.Rpk.TuneSwappiness = true
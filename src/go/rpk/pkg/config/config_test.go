@@ -30,6 +30,8 @@ func getValidConfig() *Config {
 		TuneDiskScheduler:        true,
 		TuneDiskWriteCache:       true,
 		TuneNomerges:             true,
+		TuneDiskNrRequests:       true,
+		TuneDiskReadAhead:        true,
 		TuneDiskIrq:              true,
 		TuneFstrim:               true,
 		TuneCPU:                  true,
@@ -497,6 +499,7 @@ func TestProdDefault(t *testing.T) {
 			TuneAioEvents:      true,
 			TuneBallastFile:    true,
 			TuneCPU:            true,
+			TuneCPUGovernor:    true,
 			TuneClocksource:    true,
 			TuneDiskIrq:        true,
 			TuneDiskScheduler:  true,
@@ -504,6 +507,11 @@ func TestProdDefault(t *testing.T) {
 			TuneFstrim:         false,
 			TuneNetwork:        true,
 			TuneNomerges:       true,
+			TuneDiskNrRequests: true,
+			TuneDiskReadAhead:  true,
+			TuneDiskAddRandom:  true,
+			TuneDiskMaxSectors: true,
+			TuneDiskRqAffinity: true,
 			TuneSwappiness:     true,
 		},
 	}
@@ -544,6 +552,8 @@ rpk:
     tune_network: true
     tune_disk_scheduler: true
     tune_disk_nomerges: true
+    tune_disk_nr_requests: true
+    tune_disk_read_ahead: true
     tune_disk_write_cache: true
     tune_disk_irq: true
     tune_fstrim: true
@@ -597,6 +607,8 @@ rpk:
     tune_network: true
     tune_disk_scheduler: true
     tune_disk_nomerges: true
+    tune_disk_nr_requests: true
+    tune_disk_read_ahead: true
     tune_disk_write_cache: true
     tune_disk_irq: true
     tune_fstrim: true
@@ -680,6 +692,8 @@ rpk:
     tune_network: true
     tune_disk_scheduler: true
     tune_disk_nomerges: true
+    tune_disk_nr_requests: true
+    tune_disk_read_ahead: true
     tune_disk_write_cache: true
     tune_disk_irq: true
     tune_fstrim: true
@@ -732,10 +746,16 @@ func TestSetMode(t *testing.T) {
 				TuneNetwork:        val,
 				TuneDiskScheduler:  val,
 				TuneNomerges:       val,
+				TuneDiskNrRequests: val,
+				TuneDiskReadAhead:  val,
+				TuneDiskAddRandom:  val,
+				TuneDiskMaxSectors: val,
+				TuneDiskRqAffinity: val,
 				TuneDiskWriteCache: val,
 				TuneDiskIrq:        val,
 				TuneFstrim:         false,
 				TuneCPU:            val,
+				TuneCPUGovernor:    val,
 				TuneAioEvents:      val,
 				TuneClocksource:    val,
 				TuneSwappiness:     val,
@@ -964,3 +984,46 @@ func TestCheckConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestDataDirectories(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		configDir   string
+		directories []string
+		exp         []string
+		expErr      bool
+	}{
+		{
+			name:      "defaults to the configured data directory",
+			configDir: "/var/lib/redpanda/data",
+			exp:       []string{"/var/lib/redpanda/data"},
+		},
+		{
+			name:        "given directories override the configured one",
+			configDir:   "/var/lib/redpanda/data",
+			directories: []string{"/mnt/a", "/mnt/b"},
+			exp:         []string{"/mnt/a", "/mnt/b"},
+		},
+		{
+			name:        "given directories are used without a configured one",
+			directories: []string{"/mnt/a"},
+			exp:         []string{"/mnt/a"},
+		},
+		{
+			name:   "fails without any directory",
+			expErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := DevDefault()
+			conf.Redpanda.Directory = test.configDir
+			got, err := conf.DataDirectories(test.directories)
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, got)
+		})
+	}
+}
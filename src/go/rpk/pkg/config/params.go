@@ -98,6 +98,7 @@ const (
 	FlagAdminTLSCA     = "admin-api-tls-truststore"
 	FlagAdminTLSCert   = "admin-api-tls-cert"
 	FlagAdminTLSKey    = "admin-api-tls-key"
+	FlagRegistryHosts  = "registry-urls"
 
 	EnvBrokers       = "REDPANDA_BROKERS"
 	EnvTLSCA         = "REDPANDA_TLS_TRUSTSTORE"
@@ -132,6 +133,12 @@ const (
 	xAdminCACert     = "admin.tls.ca_cert_path"
 	xAdminClientCert = "admin.tls.client_cert_path"
 	xAdminClientKey  = "admin.tls.client_key_path"
+
+	xRegistryHosts      = "registry.hosts"
+	xRegistryTLSEnabled = "registry.tls.enabled"
+	xRegistryCACert     = "registry.tls.ca_cert_path"
+	xRegistryClientCert = "registry.tls.client_cert_path"
+	xRegistryClientKey  = "registry.tls.client_key_path"
 )
 
 // DefaultPath is where redpanda's configuration is located by default.
@@ -213,6 +220,10 @@ func ParamsFromCommand(cmd *cobra.Command) *Params {
 				key = xAdminClientCert
 			case FlagAdminTLSKey:
 				key = xAdminClientKey
+
+			case FlagRegistryHosts:
+				key = xRegistryHosts
+				stripBrackets = true
 			}
 
 			val := f.Value.String()
@@ -404,8 +415,9 @@ func (p *Params) processOverrides(c *Config) error {
 	r := &c.Rpk
 	k := &r.KafkaAPI
 	a := &r.AdminAPI
+	sr := &r.SchemaRegistry
 
-	// We have three "make" functions that initialize pointer values if
+	// We have four "make" functions that initialize pointer values if
 	// necessary.
 	var (
 		mkKafkaTLS = func() {
@@ -423,6 +435,11 @@ func (p *Params) processOverrides(c *Config) error {
 				a.TLS = new(TLS)
 			}
 		}
+		mkRegistryTLS = func() {
+			if sr.TLS == nil {
+				sr.TLS = new(TLS)
+			}
+		}
 	)
 
 	// To override, we lookup any override key (e.g., kafka.tls.enabled or
@@ -445,6 +462,12 @@ func (p *Params) processOverrides(c *Config) error {
 		xAdminCACert:     func(v string) error { mkAdminTLS(); a.TLS.TruststoreFile = v; return nil },
 		xAdminClientCert: func(v string) error { mkAdminTLS(); a.TLS.CertFile = v; return nil },
 		xAdminClientKey:  func(v string) error { mkAdminTLS(); a.TLS.KeyFile = v; return nil },
+
+		xRegistryHosts:      func(v string) error { return splitCommaIntoStrings(v, &sr.Addresses) },
+		xRegistryTLSEnabled: func(string) error { mkRegistryTLS(); return nil },
+		xRegistryCACert:     func(v string) error { mkRegistryTLS(); sr.TLS.TruststoreFile = v; return nil },
+		xRegistryClientCert: func(v string) error { mkRegistryTLS(); sr.TLS.CertFile = v; return nil },
+		xRegistryClientKey:  func(v string) error { mkRegistryTLS(); sr.TLS.KeyFile = v; return nil },
 	}
 
 	// The parse function accepts the given overrides (key=value pairs) and
@@ -535,6 +558,18 @@ func (c *Config) addUnsetDefaults() {
 		&c.Rpk.AdminAPI.Addresses,
 		"127.0.0.1:9644",
 	)
+	var registry []NamedSocketAddress
+	var registryTLS []ServerTLS
+	if c.SchemaRegistry != nil {
+		registry = namedAuthnToNamed(c.SchemaRegistry.SchemaRegistryAPI)
+		registryTLS = c.SchemaRegistry.SchemaRegistryAPITLS
+	}
+	defaultFromRedpanda(
+		registry,
+		registryTLS,
+		&c.Rpk.SchemaRegistry.Addresses,
+		"127.0.0.1:8081",
+	)
 }
 
 // defaultFromRedpanda sets fields in our `rpk` config section if those fields
@@ -252,6 +252,9 @@ func TestAddUnsetDefaults(t *testing.T) {
 					AdminAPI: RpkAdminAPI{
 						Addresses: []string{"127.0.0.1:9644"},
 					},
+					SchemaRegistry: RpkSchemaRegistry{
+						Addresses: []string{"127.0.0.1:8081"},
+					},
 				},
 			},
 		},
@@ -274,6 +277,9 @@ func TestAddUnsetDefaults(t *testing.T) {
 					AdminAPI: RpkAdminAPI{
 						Addresses: []string{"bar:9644"},
 					},
+					SchemaRegistry: RpkSchemaRegistry{
+						Addresses: []string{"baz:8081"},
+					},
 				},
 			},
 			expCfg: &Config{
@@ -292,12 +298,15 @@ func TestAddUnsetDefaults(t *testing.T) {
 					AdminAPI: RpkAdminAPI{
 						Addresses: []string{"bar:9644"},
 					},
+					SchemaRegistry: RpkSchemaRegistry{
+						Addresses: []string{"baz:8081"},
+					},
 				},
 			},
 		},
 
 		{
-			name: "kafka broker and admin api from redpanda",
+			name: "kafka broker, admin api and schema registry from redpanda",
 			inCfg: &Config{
 				Redpanda: RedpandaNodeConfig{
 					KafkaAPI: []NamedAuthNSocketAddress{
@@ -307,6 +316,11 @@ func TestAddUnsetDefaults(t *testing.T) {
 						{Address: "0.0.2.3", Port: 4444},
 					},
 				},
+				SchemaRegistry: &SchemaRegistry{
+					SchemaRegistryAPI: []NamedAuthNSocketAddress{
+						{Address: "0.0.2.3", Port: 8081},
+					},
+				},
 			},
 			expCfg: &Config{
 				Redpanda: RedpandaNodeConfig{
@@ -317,6 +331,11 @@ func TestAddUnsetDefaults(t *testing.T) {
 						{Address: "0.0.2.3", Port: 4444},
 					},
 				},
+				SchemaRegistry: &SchemaRegistry{
+					SchemaRegistryAPI: []NamedAuthNSocketAddress{
+						{Address: "0.0.2.3", Port: 8081},
+					},
+				},
 				Rpk: RpkConfig{
 					KafkaAPI: RpkKafkaAPI{
 						Brokers: []string{"250.12.12.12:9095"},
@@ -324,6 +343,9 @@ func TestAddUnsetDefaults(t *testing.T) {
 					AdminAPI: RpkAdminAPI{
 						Addresses: []string{"0.0.2.3:4444"},
 					},
+					SchemaRegistry: RpkSchemaRegistry{
+						Addresses: []string{"0.0.2.3:8081"},
+					},
 				},
 			},
 		},
@@ -401,6 +423,9 @@ func TestAddUnsetDefaults(t *testing.T) {
 							"122.65.32.12:7777", // public
 						},
 					},
+					SchemaRegistry: RpkSchemaRegistry{
+						Addresses: []string{"127.0.0.1:8081"},
+					},
 				},
 			},
 		},
@@ -487,6 +512,9 @@ func TestAddUnsetDefaults(t *testing.T) {
 							"122.61.33.9:4444",
 						},
 					},
+					SchemaRegistry: RpkSchemaRegistry{
+						Addresses: []string{"127.0.0.1:8081"},
+					},
 				},
 			},
 		},
@@ -545,6 +573,9 @@ func TestAddUnsetDefaults(t *testing.T) {
 							"122.65.33.12:4444",
 						},
 					},
+					SchemaRegistry: RpkSchemaRegistry{
+						Addresses: []string{"127.0.0.1:8081"},
+					},
 				},
 			},
 		},
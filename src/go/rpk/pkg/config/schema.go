@@ -11,6 +11,7 @@ package config
 
 import (
 	"crypto/tls"
+	"errors"
 	"path"
 
 	"github.com/spf13/afero"
@@ -182,30 +183,52 @@ type RpkConfig struct {
 	// Deprecated 2021-07-1
 	SASL *SASL `yaml:"sasl,omitempty" json:"sasl,omitempty"`
 
-	KafkaAPI                 RpkKafkaAPI `yaml:"kafka_api,omitempty" json:"kafka_api"`
-	AdminAPI                 RpkAdminAPI `yaml:"admin_api,omitempty" json:"admin_api"`
-	AdditionalStartFlags     []string    `yaml:"additional_start_flags,omitempty"  json:"additional_start_flags"`
-	EnableUsageStats         bool        `yaml:"enable_usage_stats,omitempty" json:"enable_usage_stats"`
-	TuneNetwork              bool        `yaml:"tune_network,omitempty" json:"tune_network"`
-	TuneDiskScheduler        bool        `yaml:"tune_disk_scheduler,omitempty" json:"tune_disk_scheduler"`
-	TuneNomerges             bool        `yaml:"tune_disk_nomerges,omitempty" json:"tune_disk_nomerges"`
-	TuneDiskWriteCache       bool        `yaml:"tune_disk_write_cache,omitempty" json:"tune_disk_write_cache"`
-	TuneDiskIrq              bool        `yaml:"tune_disk_irq,omitempty" json:"tune_disk_irq"`
-	TuneFstrim               bool        `yaml:"tune_fstrim,omitempty" json:"tune_fstrim"`
-	TuneCPU                  bool        `yaml:"tune_cpu,omitempty" json:"tune_cpu"`
-	TuneAioEvents            bool        `yaml:"tune_aio_events,omitempty" json:"tune_aio_events"`
-	TuneClocksource          bool        `yaml:"tune_clocksource,omitempty" json:"tune_clocksource"`
-	TuneSwappiness           bool        `yaml:"tune_swappiness,omitempty" json:"tune_swappiness"`
-	TuneTransparentHugePages bool        `yaml:"tune_transparent_hugepages,omitempty" json:"tune_transparent_hugepages"`
-	EnableMemoryLocking      bool        `yaml:"enable_memory_locking,omitempty" json:"enable_memory_locking"`
-	TuneCoredump             bool        `yaml:"tune_coredump,omitempty" json:"tune_coredump"`
-	CoredumpDir              string      `yaml:"coredump_dir,omitempty" json:"coredump_dir"`
-	TuneBallastFile          bool        `yaml:"tune_ballast_file,omitempty" json:"tune_ballast_file"`
-	BallastFilePath          string      `yaml:"ballast_file_path,omitempty" json:"ballast_file_path"`
-	BallastFileSize          string      `yaml:"ballast_file_size,omitempty" json:"ballast_file_size"`
-	WellKnownIo              string      `yaml:"well_known_io,omitempty" json:"well_known_io"`
-	Overprovisioned          bool        `yaml:"overprovisioned,omitempty" json:"overprovisioned"`
-	SMP                      *int        `yaml:"smp,omitempty" json:"smp,omitempty"`
+	KafkaAPI                   RpkKafkaAPI       `yaml:"kafka_api,omitempty" json:"kafka_api"`
+	AdminAPI                   RpkAdminAPI       `yaml:"admin_api,omitempty" json:"admin_api"`
+	SchemaRegistry             RpkSchemaRegistry `yaml:"schema_registry,omitempty" json:"schema_registry"`
+	AdditionalStartFlags       []string          `yaml:"additional_start_flags,omitempty"  json:"additional_start_flags"`
+	EnableUsageStats           bool              `yaml:"enable_usage_stats,omitempty" json:"enable_usage_stats"`
+	TuneNetwork                bool              `yaml:"tune_network,omitempty" json:"tune_network"`
+	TuneDiskScheduler          bool              `yaml:"tune_disk_scheduler,omitempty" json:"tune_disk_scheduler"`
+	TuneNomerges               bool              `yaml:"tune_disk_nomerges,omitempty" json:"tune_disk_nomerges"`
+	TuneDiskNrRequests         bool              `yaml:"tune_disk_nr_requests,omitempty" json:"tune_disk_nr_requests"`
+	TuneDiskReadAhead          bool              `yaml:"tune_disk_read_ahead,omitempty" json:"tune_disk_read_ahead"`
+	TuneDiskAddRandom          bool              `yaml:"tune_disk_add_random,omitempty" json:"tune_disk_add_random"`
+	TuneDiskMaxSectors         bool              `yaml:"tune_disk_max_sectors,omitempty" json:"tune_disk_max_sectors"`
+	TuneDiskRqAffinity         bool              `yaml:"tune_disk_rq_affinity,omitempty" json:"tune_disk_rq_affinity"`
+	TuneDiskWriteCache         bool              `yaml:"tune_disk_write_cache,omitempty" json:"tune_disk_write_cache"`
+	TuneDiskVolatileWriteCache bool              `yaml:"tune_disk_volatile_write_cache,omitempty" json:"tune_disk_volatile_write_cache"`
+	TuneDiskIrq                bool              `yaml:"tune_disk_irq,omitempty" json:"tune_disk_irq"`
+	TuneFstrim                 bool              `yaml:"tune_fstrim,omitempty" json:"tune_fstrim"`
+	TuneCPU                    bool              `yaml:"tune_cpu,omitempty" json:"tune_cpu"`
+	TuneCPUGovernor            bool              `yaml:"tune_cpu_governor,omitempty" json:"tune_cpu_governor"`
+	CPUGovernor                string            `yaml:"cpu_governor,omitempty" json:"cpu_governor"`
+	TuneAioEvents              bool              `yaml:"tune_aio_events,omitempty" json:"tune_aio_events"`
+	TuneClocksource            bool              `yaml:"tune_clocksource,omitempty" json:"tune_clocksource"`
+	TuneSwappiness             bool              `yaml:"tune_swappiness,omitempty" json:"tune_swappiness"`
+	TuneTransparentHugePages   bool              `yaml:"tune_transparent_hugepages,omitempty" json:"tune_transparent_hugepages"`
+	EnableMemoryLocking        bool              `yaml:"enable_memory_locking,omitempty" json:"enable_memory_locking"`
+	TuneCoredump               bool              `yaml:"tune_coredump,omitempty" json:"tune_coredump"`
+	CoredumpDir                string            `yaml:"coredump_dir,omitempty" json:"coredump_dir"`
+	TuneBallastFile            bool              `yaml:"tune_ballast_file,omitempty" json:"tune_ballast_file"`
+	BallastFilePath            string            `yaml:"ballast_file_path,omitempty" json:"ballast_file_path"`
+	BallastFileSize            string            `yaml:"ballast_file_size,omitempty" json:"ballast_file_size"`
+	DataDirMinFreePercent      *int              `yaml:"data_dir_min_free_percent,omitempty" json:"data_dir_min_free_percent,omitempty"`
+	WellKnownIo                string            `yaml:"well_known_io,omitempty" json:"well_known_io"`
+	IoProperties               []RpkIoProperties `yaml:"io_properties,omitempty" json:"io_properties"`
+	Overprovisioned            bool              `yaml:"overprovisioned,omitempty" json:"overprovisioned"`
+	SMP                        *int              `yaml:"smp,omitempty" json:"smp,omitempty"`
+}
+
+// RpkIoProperties are the IO properties of a data directory, as measured by
+// 'rpk redpanda tune disk-iotune'.
+type RpkIoProperties struct {
+	MountPoint     string   `yaml:"mountpoint" json:"mountpoint"`
+	Devices        []string `yaml:"devices,omitempty" json:"devices"`
+	ReadIops       int64    `yaml:"read_iops" json:"read_iops"`
+	ReadBandwidth  int64    `yaml:"read_bandwidth" json:"read_bandwidth"`
+	WriteIops      int64    `yaml:"write_iops" json:"write_iops"`
+	WriteBandwidth int64    `yaml:"write_bandwidth" json:"write_bandwidth"`
 }
 
 type RpkKafkaAPI struct {
@@ -219,6 +242,11 @@ type RpkAdminAPI struct {
 	TLS       *TLS     `yaml:"tls,omitempty" json:"tls"`
 }
 
+type RpkSchemaRegistry struct {
+	Addresses []string `yaml:"addresses,omitempty" json:"addresses"`
+	TLS       *TLS     `yaml:"tls,omitempty" json:"tls"`
+}
+
 type SASL struct {
 	User      string `yaml:"user,omitempty" json:"user,omitempty"`
 	Password  string `yaml:"password,omitempty" json:"password,omitempty"`
@@ -228,3 +256,16 @@ type SASL struct {
 func (c *Config) PIDFile() string {
 	return path.Join(c.Redpanda.Directory, "pid.lock")
 }
+
+// DataDirectories returns the given directories, e.g. those of a --dirs flag,
+// or, if none is given, the redpanda.data_directory of the configuration. It
+// errors if neither is set.
+func (c *Config) DataDirectories(directories []string) ([]string, error) {
+	if len(directories) > 0 {
+		return directories, nil
+	}
+	if c.Redpanda.Directory == "" {
+		return nil, errors.New("no data directory given and redpanda.data_directory is not set in the configuration")
+	}
+	return []string{c.Redpanda.Directory}, nil
+}
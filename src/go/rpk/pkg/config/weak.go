@@ -382,30 +382,41 @@ func (rpkc *RpkConfig) UnmarshalYAML(n *yaml.Node) error {
 		// Deprecated 2021-07-1
 		SASL *SASL `yaml:"sasl"`
 
-		KafkaAPI                 RpkKafkaAPI     `yaml:"kafka_api"`
-		AdminAPI                 RpkAdminAPI     `yaml:"admin_api"`
-		AdditionalStartFlags     weakStringArray `yaml:"additional_start_flags"`
-		EnableUsageStats         weakBool        `yaml:"enable_usage_stats"`
-		TuneNetwork              weakBool        `yaml:"tune_network"`
-		TuneDiskScheduler        weakBool        `yaml:"tune_disk_scheduler"`
-		TuneNomerges             weakBool        `yaml:"tune_disk_nomerges"`
-		TuneDiskWriteCache       weakBool        `yaml:"tune_disk_write_cache"`
-		TuneDiskIrq              weakBool        `yaml:"tune_disk_irq"`
-		TuneFstrim               weakBool        `yaml:"tune_fstrim"`
-		TuneCPU                  weakBool        `yaml:"tune_cpu"`
-		TuneAioEvents            weakBool        `yaml:"tune_aio_events"`
-		TuneClocksource          weakBool        `yaml:"tune_clocksource"`
-		TuneSwappiness           weakBool        `yaml:"tune_swappiness"`
-		TuneTransparentHugePages weakBool        `yaml:"tune_transparent_hugepages"`
-		EnableMemoryLocking      weakBool        `yaml:"enable_memory_locking"`
-		TuneCoredump             weakBool        `yaml:"tune_coredump"`
-		CoredumpDir              weakString      `yaml:"coredump_dir"`
-		TuneBallastFile          weakBool        `yaml:"tune_ballast_file"`
-		BallastFilePath          weakString      `yaml:"ballast_file_path"`
-		BallastFileSize          weakString      `yaml:"ballast_file_size"`
-		WellKnownIo              weakString      `yaml:"well_known_io"`
-		Overprovisioned          weakBool        `yaml:"overprovisioned"`
-		SMP                      *weakInt        `yaml:"smp"`
+		KafkaAPI                   RpkKafkaAPI       `yaml:"kafka_api"`
+		AdminAPI                   RpkAdminAPI       `yaml:"admin_api"`
+		SchemaRegistry             RpkSchemaRegistry `yaml:"schema_registry"`
+		AdditionalStartFlags       weakStringArray   `yaml:"additional_start_flags"`
+		EnableUsageStats           weakBool          `yaml:"enable_usage_stats"`
+		TuneNetwork                weakBool          `yaml:"tune_network"`
+		TuneDiskScheduler          weakBool          `yaml:"tune_disk_scheduler"`
+		TuneNomerges               weakBool          `yaml:"tune_disk_nomerges"`
+		TuneDiskNrRequests         weakBool          `yaml:"tune_disk_nr_requests"`
+		TuneDiskReadAhead          weakBool          `yaml:"tune_disk_read_ahead"`
+		TuneDiskAddRandom          weakBool          `yaml:"tune_disk_add_random"`
+		TuneDiskMaxSectors         weakBool          `yaml:"tune_disk_max_sectors"`
+		TuneDiskRqAffinity         weakBool          `yaml:"tune_disk_rq_affinity"`
+		TuneDiskWriteCache         weakBool          `yaml:"tune_disk_write_cache"`
+		TuneDiskVolatileWriteCache weakBool          `yaml:"tune_disk_volatile_write_cache"`
+		TuneDiskIrq                weakBool          `yaml:"tune_disk_irq"`
+		TuneFstrim                 weakBool          `yaml:"tune_fstrim"`
+		TuneCPU                    weakBool          `yaml:"tune_cpu"`
+		TuneCPUGovernor            weakBool          `yaml:"tune_cpu_governor"`
+		CPUGovernor                weakString        `yaml:"cpu_governor"`
+		TuneAioEvents              weakBool          `yaml:"tune_aio_events"`
+		TuneClocksource            weakBool          `yaml:"tune_clocksource"`
+		TuneSwappiness             weakBool          `yaml:"tune_swappiness"`
+		TuneTransparentHugePages   weakBool          `yaml:"tune_transparent_hugepages"`
+		EnableMemoryLocking        weakBool          `yaml:"enable_memory_locking"`
+		TuneCoredump               weakBool          `yaml:"tune_coredump"`
+		CoredumpDir                weakString        `yaml:"coredump_dir"`
+		TuneBallastFile            weakBool          `yaml:"tune_ballast_file"`
+		BallastFilePath            weakString        `yaml:"ballast_file_path"`
+		BallastFileSize            weakString        `yaml:"ballast_file_size"`
+		DataDirMinFreePercent      *weakInt          `yaml:"data_dir_min_free_percent"`
+		WellKnownIo                weakString        `yaml:"well_known_io"`
+		IoProperties               []RpkIoProperties `yaml:"io_properties"`
+		Overprovisioned            weakBool          `yaml:"overprovisioned"`
+		SMP                        *weakInt          `yaml:"smp"`
 	}
 	if err := n.Decode(&internal); err != nil {
 		return err
@@ -415,15 +426,24 @@ func (rpkc *RpkConfig) UnmarshalYAML(n *yaml.Node) error {
 	rpkc.SASL = internal.SASL
 	rpkc.KafkaAPI = internal.KafkaAPI
 	rpkc.AdminAPI = internal.AdminAPI
+	rpkc.SchemaRegistry = internal.SchemaRegistry
 	rpkc.AdditionalStartFlags = internal.AdditionalStartFlags
 	rpkc.EnableUsageStats = bool(internal.EnableUsageStats)
 	rpkc.TuneNetwork = bool(internal.TuneNetwork)
 	rpkc.TuneDiskScheduler = bool(internal.TuneDiskScheduler)
 	rpkc.TuneNomerges = bool(internal.TuneNomerges)
+	rpkc.TuneDiskNrRequests = bool(internal.TuneDiskNrRequests)
+	rpkc.TuneDiskReadAhead = bool(internal.TuneDiskReadAhead)
+	rpkc.TuneDiskAddRandom = bool(internal.TuneDiskAddRandom)
+	rpkc.TuneDiskMaxSectors = bool(internal.TuneDiskMaxSectors)
+	rpkc.TuneDiskRqAffinity = bool(internal.TuneDiskRqAffinity)
 	rpkc.TuneDiskWriteCache = bool(internal.TuneDiskWriteCache)
+	rpkc.TuneDiskVolatileWriteCache = bool(internal.TuneDiskVolatileWriteCache)
 	rpkc.TuneDiskIrq = bool(internal.TuneDiskIrq)
 	rpkc.TuneFstrim = bool(internal.TuneFstrim)
 	rpkc.TuneCPU = bool(internal.TuneCPU)
+	rpkc.TuneCPUGovernor = bool(internal.TuneCPUGovernor)
+	rpkc.CPUGovernor = string(internal.CPUGovernor)
 	rpkc.TuneAioEvents = bool(internal.TuneAioEvents)
 	rpkc.TuneClocksource = bool(internal.TuneClocksource)
 	rpkc.TuneSwappiness = bool(internal.TuneSwappiness)
@@ -434,7 +454,9 @@ func (rpkc *RpkConfig) UnmarshalYAML(n *yaml.Node) error {
 	rpkc.TuneBallastFile = bool(internal.TuneBallastFile)
 	rpkc.BallastFilePath = string(internal.BallastFilePath)
 	rpkc.BallastFileSize = string(internal.BallastFileSize)
+	rpkc.DataDirMinFreePercent = (*int)(internal.DataDirMinFreePercent)
 	rpkc.WellKnownIo = string(internal.WellKnownIo)
+	rpkc.IoProperties = internal.IoProperties
 	rpkc.Overprovisioned = bool(internal.Overprovisioned)
 	rpkc.SMP = (*int)(internal.SMP)
 	return nil
@@ -468,6 +490,19 @@ func (r *RpkAdminAPI) UnmarshalYAML(n *yaml.Node) error {
 	return nil
 }
 
+func (r *RpkSchemaRegistry) UnmarshalYAML(n *yaml.Node) error {
+	var internal struct {
+		Addresses weakStringArray `yaml:"addresses"`
+		TLS       *TLS            `yaml:"tls"`
+	}
+	if err := n.Decode(&internal); err != nil {
+		return err
+	}
+	r.Addresses = internal.Addresses
+	r.TLS = internal.TLS
+	return nil
+}
+
 func (p *Pandaproxy) UnmarshalYAML(n *yaml.Node) error {
 	var internal struct {
 		PandaproxyAPI           namedAuthNSocketAddresses `yaml:"pandaproxy_api"`
@@ -44,6 +44,59 @@ func GetInterfacesByIps(addresses ...string) ([]string, error) {
 	return utils.GetKeys(nics), nil
 }
 
+// GetInterfacesByHost returns the interfaces with one of the addresses the
+// given host resolves to, which may be an IP. Unlike GetInterfacesByIps, the
+// interfaces must have the address itself, unless it's '0.0.0.0' which
+// matches every non loopback interface.
+func GetInterfacesByHost(host string) ([]string, error) {
+	if host == "0.0.0.0" {
+		return GetInterfacesByIps(host)
+	}
+	ips := []string{host}
+	if net.ParseIP(host) == nil {
+		var err error
+		if ips, err = net.LookupHost(host); err != nil {
+			return nil, err
+		}
+	}
+	log.Debugf("Looking for interface with '%v' addresses", ips)
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	nics := make(map[string]bool)
+	for _, iface := range ifaces {
+		addr, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range addr {
+			for _, ip := range ips {
+				if addressIs(address, ip) {
+					nics[iface.Name] = true
+				}
+			}
+		}
+	}
+	return utils.GetKeys(nics), nil
+}
+
+// addressIs returns whether the interface address, e.g. '10.0.0.1/24', is the
+// given IP.
+func addressIs(address net.Addr, ip string) bool {
+	requested := net.ParseIP(ip)
+	if requested == nil {
+		return false
+	}
+	switch a := address.(type) {
+	case *net.IPNet:
+		return a.IP.Equal(requested)
+	case *net.IPAddr:
+		return a.IP.Equal(requested)
+	}
+	return false
+}
+
 func getFreePort() (uint, error) {
 	addr, err := net.ResolveTCPAddr("tcp", "localhost:0")
 	if err != nil {
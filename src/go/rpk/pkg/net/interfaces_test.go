@@ -0,0 +1,42 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package net
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressIs(t *testing.T) {
+	ipNet := func(cidr string) net.Addr {
+		ip, n, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		n.IP = ip
+		return n
+	}
+	for _, test := range []struct {
+		name    string
+		address net.Addr
+		ip      string
+		exp     bool
+	}{
+		{"same ipv4", ipNet("10.0.0.1/24"), "10.0.0.1", true},
+		{"other ipv4 of the network", ipNet("10.0.0.1/24"), "10.0.0.2", false},
+		{"same ipv6", ipNet("fe80::1/64"), "fe80::1", true},
+		{"ip addr", &net.IPAddr{IP: net.ParseIP("192.168.1.1")}, "192.168.1.1", true},
+		{"not an ip", ipNet("10.0.0.1/24"), "redpanda.local", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.exp, addressIs(test.address, test.ip))
+		})
+	}
+}
@@ -57,24 +57,61 @@ func readUintCgroupsProp(
 	return strconv.ParseUint(strings.TrimSpace(val), 10, 64)
 }
 
+// ReadCgroupV2EffectiveCpus returns the CPUs in the cpuset.cpus.effective of
+// the cgroup v2 of the process, or nil if the process isn't in a cgroup v2,
+// e.g. on cgroup v1 or hybrid systems, or if its hierarchy doesn't expose
+// cpusets.
+func ReadCgroupV2EffectiveCpus(fs afero.Fs) ([]uint, error) {
+	v2CgroupPath, err := v2CgroupPath(fs)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if v2CgroupPath == "" {
+		return nil, nil
+	}
+	filePath, err := recursiveCgroupsLookup(fs, v2CgroupPath, "/cpuset.cpus.effective")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ls, err := utils.ReadFileLines(fs, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(ls) < 1 {
+		return nil, fmt.Errorf("no value found in %s", filePath)
+	}
+	return parseCPUList(ls[0])
+}
+
 func calculateEffectiveCpus(cpuList string) (uint64, error) {
+	cpus, err := parseCPUList(cpuList)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(cpus)), nil
+}
+
+// parseCPUList returns the CPUs in a cgroup CPU list, e.g. 0, 1, 2 and 5 for
+// '0-2,5'.
+func parseCPUList(cpuList string) ([]uint, error) {
 	if cpuList == "" {
-		return 0, errors.New("no CPUs assigned to process")
+		return nil, errors.New("no CPUs assigned to process")
 	}
-	cpus := 0
+	var cpus []uint
 	ranges := strings.Split(cpuList, ",")
 	for _, r := range ranges {
 		if r == "" {
-			return 0, fmt.Errorf("missing value in cpu list '%s'", cpuList)
+			return nil, fmt.Errorf("missing value in cpu list '%s'", cpuList)
 		}
-		if !strings.Contains(r, "-") {
-			cpus++
-			continue
-		}
-
 		limits := strings.Split(r, "-")
-		if len(limits) != 2 {
-			return 0, fmt.Errorf(
+		if len(limits) > 2 {
+			return nil, fmt.Errorf(
 				"invalid effective CPU range '%s' in '%s'",
 				r,
 				cpuList,
@@ -82,26 +119,30 @@ func calculateEffectiveCpus(cpuList string) (uint64, error) {
 		}
 		lower, err := strconv.Atoi(limits[0])
 		if err != nil {
-			return 0, fmt.Errorf(
+			return nil, fmt.Errorf(
 				"couldn't parse effective CPU lower range limit '%s' in '%s'",
 				limits[0],
 				cpuList,
 			)
 		}
-		upper, err := strconv.Atoi(limits[1])
-		if err != nil {
-			return 0, fmt.Errorf(
-				"couldn't parse effective CPU upper range limit '%s' in '%s'",
-				limits[1],
-				cpuList,
-			)
+		upper := lower
+		if len(limits) == 2 {
+			upper, err = strconv.Atoi(limits[1])
+			if err != nil {
+				return nil, fmt.Errorf(
+					"couldn't parse effective CPU upper range limit '%s' in '%s'",
+					limits[1],
+					cpuList,
+				)
+			}
+		}
+		// Ranges are inclusive on both limits, e.g. range 6-10 means CPUs
+		// 6, 7, 8, 9 and 10 are available.
+		for cpu := lower; cpu <= upper; cpu++ {
+			cpus = append(cpus, uint(cpu))
 		}
-		// Ranges are inclusive on both limits, so we have to add 1.
-		// e.g. range 6-10 means CPUs 6, 7, 8, 9 and 10 (5, in total)
-		// are available: (10 - 6) + 1 = 5.
-		cpus += (upper - lower) + 1
 	}
-	return uint64(cpus), nil
+	return cpus, nil
 }
 
 func readCgroupFile(fs afero.Fs, v1Subpath, v2Subpath string) (string, error) {
@@ -11,12 +11,16 @@ package system
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/afero"
 )
 
+// numaNodeCPUListPath is the list of the CPUs of a NUMA node, e.g. '0-15,32-47'.
+const numaNodeCPUListPath = "/sys/devices/system/node/node%d/cpulist"
+
 type CPUInfo struct {
 	ModelName string
 	Cores     int
@@ -66,3 +70,22 @@ func GetCPUInfo(fs afero.Fs) ([]*CPUInfo, error) {
 	}
 	return cpus, nil
 }
+
+// ReadNumaNodeCpus returns the CPUs of the NUMA node, none for nodes without
+// CPUs, e.g. those of memory only.
+func ReadNumaNodeCpus(fs afero.Fs, node int) ([]uint, error) {
+	path := fmt.Sprintf(numaNodeCPUListPath, node)
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	cpuList := strings.TrimSpace(string(b))
+	if cpuList == "" {
+		return nil, nil
+	}
+	cpus, err := parseCPUList(cpuList)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the CPUs of NUMA node %d in %s: %v", node, path, err)
+	}
+	return cpus, nil
+}
@@ -120,3 +120,23 @@ power management:
 		})
 	}
 }
+
+func TestReadNumaNodeCpus(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "/sys/devices/system/node/node0/cpulist", []byte("0-3,8-9\n"), 0o644)
+	afero.WriteFile(fs, "/sys/devices/system/node/node1/cpulist", []byte("\n"), 0o644)
+	afero.WriteFile(fs, "/sys/devices/system/node/node2/cpulist", []byte("4-x\n"), 0o644)
+
+	cpus, err := system.ReadNumaNodeCpus(fs, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint{0, 1, 2, 3, 8, 9}, cpus)
+
+	cpus, err = system.ReadNumaNodeCpus(fs, 1)
+	require.NoError(t, err)
+	require.Empty(t, cpus)
+
+	_, err = system.ReadNumaNodeCpus(fs, 2)
+	require.Error(t, err)
+	_, err = system.ReadNumaNodeCpus(fs, 3)
+	require.Error(t, err)
+}
@@ -12,6 +12,9 @@
 package filesystem
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
 	"syscall"
 
@@ -47,3 +50,45 @@ func GetFreeDiskSpaceGB(path string) (float64, error) {
 	}
 	return float64(statFs.Bfree*uint64(statFs.Bsize)) / units.GiB, nil
 }
+
+// DiskUsage is the usage of the filesystem holding a path.
+type DiskUsage struct {
+	TotalBytes uint64 `json:"total_bytes"`
+	UsedBytes  uint64 `json:"used_bytes"`
+	// FreeBytes are those available to unprivileged users, i.e. without the
+	// blocks reserved to root.
+	FreeBytes uint64 `json:"free_bytes"`
+	// FreePercent is the percentage of the space usable by unprivileged
+	// users that is free, as computed by df.
+	FreePercent float64 `json:"free_percent"`
+}
+
+// statfs is replaced in tests to fake the statistics of filesystems.
+var statfs = syscall.Statfs
+
+// GetDiskUsage returns the usage of the filesystem holding path, which must
+// exist.
+func GetDiskUsage(path string) (*DiskUsage, error) {
+	var statFs syscall.Statfs_t
+	if err := statfs(path, &statFs); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("unable to get the disk usage of '%s': it doesn't exist yet", path)
+		}
+		return nil, fmt.Errorf("unable to get the disk usage of '%s': %w", path, err)
+	}
+	blockSize := blockSize(&statFs)
+	if blockSize == 0 {
+		return nil, fmt.Errorf("unable to get the disk usage of '%s': its filesystem reports a block size of 0", path)
+	}
+	usage := &DiskUsage{
+		TotalBytes: statFs.Blocks * blockSize,
+		FreeBytes:  statFs.Bavail * blockSize,
+	}
+	if statFs.Bfree < statFs.Blocks {
+		usage.UsedBytes = (statFs.Blocks - statFs.Bfree) * blockSize
+	}
+	if usable := usage.UsedBytes + usage.FreeBytes; usable > 0 {
+		usage.FreePercent = float64(usage.FreeBytes) / float64(usable) * 100
+	}
+	return usage, nil
+}
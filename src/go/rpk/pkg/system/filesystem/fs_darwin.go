@@ -9,8 +9,9 @@
 
 package filesystem
 
-import "errors"
+import "syscall"
 
-func GetFilesystemType(string) (FsType, error) {
-	return Unknown, errors.New("Filesystem detection not available for MacOS")
+// blockSize returns the unit of the block counts of the statistics.
+func blockSize(statFs *syscall.Statfs_t) uint64 {
+	return uint64(statFs.Bsize)
 }
@@ -9,28 +9,17 @@
 
 package filesystem
 
-import (
-	"syscall"
+import "syscall"
 
-	"golang.org/x/sys/unix"
-)
-
-func GetFilesystemType(path string) (FsType, error) {
-	statFs := syscall.Statfs_t{}
-	err := syscall.Statfs(path, &statFs)
-	if err != nil {
-		return "", err
+// blockSize returns the unit of the block counts of the statistics, which is
+// the fragment size, as the block size is only the preferred size of I/Os on
+// filesystems where they differ.
+func blockSize(statFs *syscall.Statfs_t) uint64 {
+	if statFs.Frsize > 0 {
+		return uint64(statFs.Frsize)
 	}
-	switch statFs.Type {
-	case unix.EXT4_SUPER_MAGIC:
-		return Ext, nil
-	case unix.XFS_SUPER_MAGIC:
-		return Xfs, nil
-	case unix.TMPFS_MAGIC:
-		return Tmpfs, nil
-	case 0x4244:
-		return Hfs, nil
-	default:
-		return Unknown, nil
+	if statFs.Bsize > 0 {
+		return uint64(statFs.Bsize)
 	}
+	return 0
 }
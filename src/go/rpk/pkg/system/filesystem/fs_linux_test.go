@@ -0,0 +1,95 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package filesystem
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDiskUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		statFs  syscall.Statfs_t
+		want    *DiskUsage
+		wantErr bool
+	}{
+		{
+			name: "shall compute the usage from the fragment size",
+			// ext4 reserving 5% of its blocks to root, 25% used.
+			statFs: syscall.Statfs_t{Bsize: 4096, Frsize: 4096, Blocks: 1000, Bfree: 750, Bavail: 700},
+			want: &DiskUsage{
+				TotalBytes:  4096000,
+				UsedBytes:   1024000,
+				FreeBytes:   2867200,
+				FreePercent: 2867200.0 / (1024000 + 2867200) * 100,
+			},
+		},
+		{
+			name:   "shall count the blocks in fragments larger than the preferred I/O size",
+			statFs: syscall.Statfs_t{Bsize: 4096, Frsize: 65536, Blocks: 100, Bfree: 95, Bavail: 95},
+			want: &DiskUsage{
+				TotalBytes:  6553600,
+				UsedBytes:   327680,
+				FreeBytes:   6225920,
+				FreePercent: 95,
+			},
+		},
+		{
+			name:   "shall fall back to the block size without a fragment size",
+			statFs: syscall.Statfs_t{Bsize: 512, Blocks: 10, Bfree: 1, Bavail: 1},
+			want: &DiskUsage{
+				TotalBytes:  5120,
+				UsedBytes:   4608,
+				FreeBytes:   512,
+				FreePercent: 10,
+			},
+		},
+		{
+			name:    "shall fail on a zero block size",
+			statFs:  syscall.Statfs_t{Blocks: 10, Bfree: 1, Bavail: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			defer func(s func(string, *syscall.Statfs_t) error) { statfs = s }(statfs)
+			statfs = func(path string, buf *syscall.Statfs_t) error {
+				require.Equal(t, dir, path)
+				*buf = tt.statFs
+				return nil
+			}
+			got, err := GetDiskUsage(dir)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want.TotalBytes, got.TotalBytes)
+			require.Equal(t, tt.want.UsedBytes, got.UsedBytes)
+			require.Equal(t, tt.want.FreeBytes, got.FreeBytes)
+			require.InDelta(t, tt.want.FreePercent, got.FreePercent, 1e-9)
+		})
+	}
+}
+
+func TestGetDiskUsageMissingDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	_, err := GetDiskUsage(dir)
+	require.EqualError(t, err, "unable to get the disk usage of '"+dir+"': it doesn't exist yet")
+
+	usage, err := GetDiskUsage(filepath.Dir(dir))
+	require.NoError(t, err)
+	require.NotZero(t, usage.TotalBytes)
+}
@@ -29,20 +29,6 @@ type MemInfo struct {
 	CGroupMemLimit uint64 `json:"omitempty"`
 }
 
-func GetTransparentHugePagesActive(fs afero.Fs) (bool, error) {
-	options, err := ReadRuntineOptions(fs,
-		"/sys/kernel/mm/transparent_hugepage/enabled")
-	if err != nil {
-		return false, err
-	}
-
-	if options.GetActive() != "never" {
-		return true, nil
-	}
-
-	return false, nil
-}
-
 func GetMemTotalMB(fs afero.Fs) (int, error) {
 	mInfo, err := getMemInfo(fs)
 	if err != nil {
@@ -9,6 +9,8 @@
 
 package tuners
 
+import "context"
+
 func NewAggregatedTunable(tunables []Tunable) Tunable {
 	return &aggregatedTunable{tunables}
 }
@@ -27,16 +29,20 @@ func (t *aggregatedTunable) CheckIfSupported() (supported bool, reason string) {
 	return true, ""
 }
 
-func (t *aggregatedTunable) Tune() TuneResult {
+func (t *aggregatedTunable) Tune(ctx context.Context) TuneResult {
 	needReboot := false
+	notApplied := ""
 	for _, tunable := range t.tunables {
-		result := tunable.Tune()
+		result := tunable.Tune(ctx)
 		if result.IsFailed() {
 			return result
 		}
 		if result.IsRebootRequired() {
 			needReboot = true
 		}
+		if notApplied == "" {
+			notApplied = result.NotAppliedReason()
+		}
 	}
-	return NewTuneResult(needReboot)
+	return &tuneResult{rebootRequired: needReboot, notApplied: notApplied}
 }
@@ -10,6 +10,7 @@
 package tuners
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -25,7 +26,7 @@ func (t *mockedTunable) CheckIfSupported() (supported bool, reason string) {
 	return t.checkIfSupported()
 }
 
-func (t *mockedTunable) Tune() TuneResult {
+func (t *mockedTunable) Tune(context.Context) TuneResult {
 	return t.tune()
 }
 
@@ -112,7 +113,7 @@ func Test_aggregatedTunable_Tune(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tunable := &aggregatedTunable{tt.fields.tunables}
-			got := tunable.Tune()
+			got := tunable.Tune(context.Background())
 			require.Exactly(t, tt.want, got)
 		})
 	}
@@ -21,7 +21,7 @@ import (
 
 const (
 	maxAIOEvents     = 1048576
-	maxAIOEventsFile = "/proc/sys/fs/aio-max-nr"
+	MaxAIOEventsFile = "/proc/sys/fs/aio-max-nr"
 )
 
 func NewMaxAIOEventsChecker(fs afero.Fs) Checker {
@@ -36,20 +36,20 @@ func NewMaxAIOEventsChecker(fs afero.Fs) Checker {
 			return fmt.Sprintf(">= %d", maxAIOEvents)
 		},
 		func() (int, error) {
-			return utils.ReadIntFromFile(fs, maxAIOEventsFile)
+			return utils.ReadIntFromFile(fs, MaxAIOEventsFile)
 		},
 	)
 }
 
 func NewMaxAIOEventsTuner(fs afero.Fs, executor executors.Executor) Tunable {
-	return NewCheckedTunable(
+	return newFileCheckedTunable(
 		NewMaxAIOEventsChecker(fs),
 		func() TuneResult {
 			log.Debugf("Setting max AIO events to %d", maxAIOEvents)
 			err := executor.Execute(
 				commands.NewWriteFileCmd(
 					fs,
-					maxAIOEventsFile,
+					MaxAIOEventsFile,
 					fmt.Sprint(maxAIOEvents),
 				),
 			)
@@ -62,5 +62,8 @@ func NewMaxAIOEventsTuner(fs afero.Fs, executor executors.Executor) Tunable {
 			return true, ""
 		},
 		executor.IsLazy(),
+		func() ([]string, error) {
+			return []string{MaxAIOEventsFile}, nil
+		},
 	)
 }
@@ -10,6 +10,7 @@
 package tuners_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -88,7 +89,7 @@ func TestMaxAIOEventsCheck(t *testing.T) {
 				require.NoError(st, err)
 			}
 			tuner := tuners.NewMaxAIOEventsTuner(fs, exec)
-			res := tuner.Tune()
+			res := tuner.Tune(context.Background())
 			if tt.expectedErrMsg != "" {
 				require.Contains(st, res.Error().Error(), tt.expectedErrMsg)
 				return
@@ -13,6 +13,7 @@
 package ballast
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -34,7 +35,7 @@ func NewBallastFileTuner(
 	return &ballastTuner{conf, executor}
 }
 
-func (t *ballastTuner) Tune() tuners.TuneResult {
+func (t *ballastTuner) Tune(_ context.Context) tuners.TuneResult {
 	path := config.DefaultBallastFilePath
 	if t.conf.Rpk.BallastFilePath != "" {
 		path = t.conf.Rpk.BallastFilePath
@@ -0,0 +1,72 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/spf13/afero"
+)
+
+// CapabilityStatus tells whether a tunable can be tuned on the host.
+type CapabilityStatus string
+
+const (
+	// CapabilitySupported is the status of the tunables whose files exist
+	// and are writable.
+	CapabilitySupported CapabilityStatus = "supported"
+	// CapabilityUnsupported is the status of the tunables the host lacks,
+	// e.g. the write cache of devices not exposing it, or cpufreq in VMs.
+	CapabilityUnsupported CapabilityStatus = "unsupported"
+	// CapabilityNeedsPrivilege is the status of the tunables whose files
+	// exist but can't be written to by rpk, e.g. as a regular user or in
+	// an unprivileged container mounting sysfs read-only.
+	CapabilityNeedsPrivilege CapabilityStatus = "needs-privilege"
+	// CapabilityUnknown is the status of the tuners whose files aren't known
+	// ahead of tuning, e.g. those running commands, see FileTunable.
+	CapabilityUnknown CapabilityStatus = "unknown"
+)
+
+// TunableCapability is whether a tuner can tune one of the files it writes
+// to, of one of its devices for the disk tuners. Its JSON field names are
+// stable:
+//
+//   - tuner: the name of the tuner, e.g. 'disk_scheduler'.
+//   - device: the device the file belongs to, if any, e.g. 'nvme0n1'.
+//   - path: the file the tuner writes to, if known.
+//   - status: supported, unsupported, needs-privilege or unknown.
+//   - reason: why it's unsupported, needs privileges or unknown.
+type TunableCapability struct {
+	Tuner  string           `json:"tuner"`
+	Device string           `json:"device,omitempty"`
+	Path   string           `json:"path,omitempty"`
+	Status CapabilityStatus `json:"status"`
+	Reason string           `json:"reason,omitempty"`
+}
+
+// ProbeTunableFile returns whether the tuners could write to the file at
+// path: it's opened for writing, but never written to.
+func ProbeTunableFile(fs afero.Fs, path string) (CapabilityStatus, string) {
+	if exists, _ := afero.Exists(fs, path); !exists {
+		return CapabilityUnsupported, fmt.Sprintf("'%s' doesn't exist", path)
+	}
+	f, err := fs.OpenFile(path, os.O_WRONLY, 0)
+	if err == nil {
+		f.Close()
+		return CapabilitySupported, ""
+	}
+	if errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS) {
+		return CapabilityNeedsPrivilege, fmt.Sprintf("'%s' is not writable: %v", path, err)
+	}
+	return CapabilityUnsupported, err.Error()
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeTunableFile(t *testing.T) {
+	const scheduler = "/sys/block/nvme0n1/queue/scheduler"
+	tests := []struct {
+		name   string
+		fs     func() afero.Fs
+		want   tuners.CapabilityStatus
+		reason bool
+	}{
+		{
+			name: "shall report writable files as supported",
+			fs: func() afero.Fs {
+				fs := afero.NewMemMapFs()
+				afero.WriteFile(fs, scheduler, []byte("[none] mq-deadline\n"), 0o644)
+				return fs
+			},
+			want: tuners.CapabilitySupported,
+		},
+		{
+			name:   "shall report missing files as unsupported",
+			fs:     afero.NewMemMapFs,
+			want:   tuners.CapabilityUnsupported,
+			reason: true,
+		},
+		{
+			name: "shall report read-only files as needing privileges",
+			fs: func() afero.Fs {
+				fs := afero.NewMemMapFs()
+				afero.WriteFile(fs, scheduler, []byte("[none] mq-deadline\n"), 0o644)
+				return afero.NewReadOnlyFs(fs)
+			},
+			want:   tuners.CapabilityNeedsPrivilege,
+			reason: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := tt.fs()
+			status, reason := tuners.ProbeTunableFile(fs, scheduler)
+			require.Equal(t, tt.want, status)
+			require.Equal(t, tt.reason, reason != "")
+			// Probing never writes to the file.
+			if exists, _ := afero.Exists(fs, scheduler); exists {
+				content, err := afero.ReadFile(fs, scheduler)
+				require.NoError(t, err)
+				require.Equal(t, "[none] mq-deadline\n", string(content))
+			}
+		})
+	}
+}
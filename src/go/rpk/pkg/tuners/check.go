@@ -10,6 +10,8 @@
 package tuners
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -17,16 +19,19 @@ import (
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/redpanda"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
+// Check runs the checkers of the configuration, it stops before the next
+// checker once ctx is done.
 func Check(
-	fs afero.Fs, conf *config.Config, timeout time.Duration,
+	ctx context.Context, fs afero.Fs, conf *config.Config, timeout time.Duration,
 ) ([]CheckResult, error) {
 	var results []CheckResult
 	ioConfigFile := redpanda.GetIOConfigPath(filepath.Dir(conf.FileLocation()))
-	checkersMap, err := RedpandaCheckers(fs, ioConfigFile, conf, timeout)
+	checkersMap, err := RedpandaCheckers(ctx, fs, ioConfigFile, conf, timeout)
 	if err != nil {
 		return results, err
 	}
@@ -42,13 +47,21 @@ func Check(
 	for _, id := range ids {
 		checkers := checkersMap[CheckerID(id)]
 		for _, c := range checkers {
+			if err := ctx.Err(); err != nil {
+				return results, fmt.Errorf("checker %q cancelled: %w", c.GetDesc(), err)
+			}
 			log.Debugf("Starting checker %q", c.GetDesc())
 			result := c.Check()
+			if errors.Is(result.Err, disk.ErrUnsupportedPlatform) {
+				log.Debugf("Skipping checker %q: %v", c.GetDesc(), result.Err)
+				result.Current = "unsupported on this OS"
+				result.Err = nil
+			}
 			if result.Err != nil {
 				if c.GetSeverity() == Fatal {
 					return results, fmt.Errorf("fatal error during checker %q execution: %v", c.GetDesc(), result.Err)
 				}
-				fmt.Printf("System check %q failed with non-fatal error %q\n", c.GetDesc(), result.Err)
+				log.Warnf("System check %q failed with non-fatal error %q", c.GetDesc(), result.Err)
 			}
 			log.Debugf("Finished checker %q; result %+v", c.GetDesc(), result)
 			results = append(results, *result)
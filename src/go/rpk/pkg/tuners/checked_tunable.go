@@ -10,6 +10,7 @@
 package tuners
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -30,33 +31,92 @@ func NewCheckedTunable(
 	}
 }
 
+// newFileCheckedTunable returns a checked tunable writing to the host-wide
+// files returned by files, see FileTunable.
+func newFileCheckedTunable(
+	checker Checker,
+	tuneAction func() TuneResult,
+	supportedAction func() (supported bool, reason string),
+	disablePostTuneCheck bool,
+	files func() ([]string, error),
+) Tunable {
+	return &fileCheckedTunable{
+		checkedTunable: NewCheckedTunable(
+			checker, tuneAction, supportedAction, disablePostTuneCheck,
+		).(*checkedTunable),
+		files: files,
+	}
+}
+
+type fileCheckedTunable struct {
+	*checkedTunable
+	files func() ([]string, error)
+}
+
+func (t *fileCheckedTunable) TunableFiles(string) ([]string, error) {
+	return t.files()
+}
+
 type checkedTunable struct {
 	checker              Checker
 	tuneAction           func() TuneResult
 	supportedAction      func() (supported bool, reason string)
 	disablePostTuneCheck bool
+	// current is the value found by the last check, and tuned whether it
+	// ran the tune action, see checkedValues.
+	current string
+	tuned   bool
+}
+
+// checkedValues returns the value found by the last check, the required
+// one, and whether the tune action ran.
+func (t *checkedTunable) checkedValues() (current, required string, tuned bool) {
+	// Checks of whether a device is tuned, e.g. the scheduler one, don't
+	// know its value.
+	if c, ok := t.checker.(*equalityChecker); ok {
+		if _, ok := c.required.(bool); ok {
+			return "", "", t.tuned
+		}
+	}
+	return t.current, t.checker.GetRequiredAsString(), t.tuned
 }
 
 func (t *checkedTunable) CheckIfSupported() (supported bool, reason string) {
 	return t.supportedAction()
 }
 
-func (t *checkedTunable) Tune() TuneResult {
+func (t *checkedTunable) Tune(ctx context.Context) TuneResult {
+	if err := ctx.Err(); err != nil {
+		return NewTuneError(err)
+	}
 	log.Debugf("Checking '%s'", t.checker.GetDesc())
 	result := t.checker.Check()
 	if result.Err != nil {
 		return NewTuneError(result.Err)
 	}
+	t.current = result.Current
 
 	if result.IsOk {
 		log.Debugf("Check '%s' passed, skipping tuning", t.checker.GetDesc())
 		return NewTuneResult(false)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return NewTuneError(err)
+	}
+	t.tuned = true
 	tuneResult := t.tuneAction()
 	if tuneResult.Error() != nil {
 		return NewTuneError(tuneResult.Error())
 	}
+	if isUnchanged(tuneResult) {
+		t.tuned = false
+		return tuneResult
+	}
+	if tuneResult.NotAppliedReason() != "" {
+		log.Debugf("Tuning '%s' not applied: %s", t.checker.GetDesc(), tuneResult.NotAppliedReason())
+		return tuneResult
+	}
 	if !t.disablePostTuneCheck {
 		postTuneResult := t.checker.Check()
 		if !postTuneResult.IsOk {
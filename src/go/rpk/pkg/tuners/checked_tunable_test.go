@@ -10,6 +10,7 @@
 package tuners
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -122,7 +123,7 @@ func TestTune(t *testing.T) {
 				severity: tt.severity,
 			}
 			ct := NewCheckedTunable(c, c.Tune, c.CheckIfSupported, false)
-			got := ct.Tune()
+			got := ct.Tune(context.Background())
 			require.Equal(t, tt.expectTuneCalled, c.tuneCalled)
 			require.Equal(t, tt.want, got)
 		})
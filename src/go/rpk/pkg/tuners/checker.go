@@ -34,6 +34,9 @@ type CheckResult struct {
 	Desc      string
 	Severity  Severity
 	Required  string
+	// Details are the raw values the check is based on, for checkers
+	// exposing them in the structured output.
+	Details interface{}
 }
 
 type Checker interface {
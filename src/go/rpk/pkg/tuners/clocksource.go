@@ -21,6 +21,9 @@ import (
 
 const preferredClkSource = "tsc"
 
+// ClockSourceFile is the file the clock source is read from and set in.
+const ClockSourceFile = "/sys/devices/system/clocksource/clocksource0/current_clocksource"
+
 func NewClockSourceChecker(fs afero.Fs) Checker {
 	return NewEqualityChecker(
 		ClockSource,
@@ -28,8 +31,7 @@ func NewClockSourceChecker(fs afero.Fs) Checker {
 		Warning,
 		preferredClkSource,
 		func() (interface{}, error) {
-			content, err := afero.ReadFile(fs,
-				"/sys/devices/system/clocksource/clocksource0/current_clocksource")
+			content, err := afero.ReadFile(fs, ClockSourceFile)
 			if err != nil {
 				return "", err
 			}
@@ -39,11 +41,11 @@ func NewClockSourceChecker(fs afero.Fs) Checker {
 }
 
 func NewClockSourceTuner(fs afero.Fs, executor executors.Executor) Tunable {
-	return NewCheckedTunable(
+	return newFileCheckedTunable(
 		NewClockSourceChecker(fs),
 		func() TuneResult {
 			err := executor.Execute(commands.NewWriteFileCmd(fs,
-				"/sys/devices/system/clocksource/clocksource0/current_clocksource",
+				ClockSourceFile,
 				preferredClkSource))
 			if err != nil {
 				return NewTuneError(err)
@@ -71,5 +73,8 @@ func NewClockSourceTuner(fs afero.Fs, executor executors.Executor) Tunable {
 				"Preferred clocksource '%s' not available", preferredClkSource)
 		},
 		executor.IsLazy(),
+		func() ([]string, error) {
+			return []string{ClockSourceFile}, nil
+		},
 	)
 }
@@ -11,6 +11,7 @@ package coredump
 
 import (
 	"bytes"
+	"context"
 	"text/template"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
@@ -51,7 +52,7 @@ func NewCoredumpTuner(
 	return &tuner{fs, conf, executor}
 }
 
-func (t *tuner) Tune() tuners.TuneResult {
+func (t *tuner) Tune(_ context.Context) tuners.TuneResult {
 	script, err := renderTemplate(coredumpScriptTmpl, t.conf.Rpk)
 	if err != nil {
 		return tuners.NewTuneError(err)
@@ -10,6 +10,7 @@
 package coredump
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -54,7 +55,7 @@ func TestTune(t *testing.T) {
 				require.NoError(t, err)
 			}
 			tuner := NewCoredumpTuner(fs, *conf, executors.NewDirectExecutor())
-			res := tuner.Tune()
+			res := tuner.Tune(context.Background())
 			require.NoError(t, res.Error())
 			pattern, err := fs.Open(corePatternFilePath)
 			require.NoError(t, err)
@@ -10,6 +10,7 @@
 package cpu
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -31,14 +32,19 @@ type tuner struct {
 	pus           uint
 	fs            afero.Fs
 	executor      executors.Executor
+	governorTuner tuners.Tunable
 }
 
+// NewCPUTuner creates the CPU tuner, which sets the frequency governors of
+// the CPUs with governorTuner, the cpu_governor tuner, so that both tuners
+// agree on the governor.
 func NewCPUTuner(
 	cpuMasks irq.CPUMasks,
 	grub system.Grub,
 	fs afero.Fs,
 	rebootAllowed bool,
 	executor executors.Executor,
+	governorTuner tuners.Tunable,
 ) tuners.Tunable {
 	return &tuner{
 		cpuMasks:      cpuMasks,
@@ -46,10 +52,11 @@ func NewCPUTuner(
 		fs:            fs,
 		rebootAllowed: rebootAllowed,
 		executor:      executor,
+		governorTuner: governorTuner,
 	}
 }
 
-func (tuner *tuner) Tune() tuners.TuneResult {
+func (tuner *tuner) Tune(ctx context.Context) tuners.TuneResult {
 	grubUpdated := false
 	log.Debug("Running CPU tuner...")
 	allCpusMask, err := tuner.cpuMasks.GetAllCpusMask()
@@ -99,7 +106,7 @@ func (tuner *tuner) Tune() tuners.TuneResult {
 		return tuners.NewTuneResult(true)
 	}
 
-	err = tuner.setupCPUGovernors()
+	err = tuner.setupCPUGovernors(ctx)
 	if err != nil {
 		return tuners.NewTuneError(err)
 	}
@@ -171,7 +178,7 @@ func (tuner *tuner) disablePStates() error {
 	return tuner.grub.AddCommandLineOptions([]string{"intel_pstate=disable"})
 }
 
-func (tuner *tuner) setupCPUGovernors() error {
+func (tuner *tuner) setupCPUGovernors(ctx context.Context) error {
 	log.Debugf("Setting up ACPI based CPU governors")
 	if exists, _ := afero.Exists(tuner.fs, "/sys/devices/system/cpu/cpufreq/boost"); exists {
 		err := tuner.executor.Execute(
@@ -183,18 +190,8 @@ func (tuner *tuner) setupCPUGovernors() error {
 	} else {
 		log.Debugf("CPU frequency boost is not available in this system")
 	}
-	for i := uint(0); i < tuner.cores; i = i + 1 {
-		policyPath := fmt.Sprintf(
-			"/sys/devices/system/cpu/cpufreq/policy%d/scaling_governor", i)
-		if exists, _ := afero.Exists(tuner.fs, policyPath); exists {
-			err := tuner.executor.Execute(
-				commands.NewWriteFileCmd(tuner.fs, policyPath, "performance"))
-			if err != nil {
-				return err
-			}
-		} else {
-			log.Debugf("Unable to set CPU governor policy for CPU %d", i)
-		}
+	if res := tuner.governorTuner.Tune(ctx); res.IsFailed() {
+		return res.Error()
 	}
 	return nil
 }
@@ -0,0 +1,250 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !windows
+
+package tuners
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// DefaultCPUGovernor is the frequency governor the cpu_governor tuner sets
+// unless configured otherwise, keeping the CPUs at their maximum frequency.
+const DefaultCPUGovernor = "performance"
+
+const cpuSysfsDir = "/sys/devices/system/cpu"
+
+var cpuDirPattern = regexp.MustCompile(`^cpu(\d+)$`)
+
+// cpuGovernor is the frequency governor of a CPU exposing cpufreq.
+type cpuGovernor struct {
+	cpu      int
+	file     string
+	governor string
+}
+
+// readCPUGovernors returns the governors of the CPUs exposing cpufreq,
+// sorted by CPU. CPUs without a cpufreq directory, e.g. those of VMs whose
+// frequency is managed by the hypervisor, are skipped.
+func readCPUGovernors(fs afero.Fs) ([]cpuGovernor, error) {
+	dirs, err := afero.ReadDir(fs, cpuSysfsDir)
+	if err != nil {
+		return nil, err
+	}
+	var governors []cpuGovernor
+	for _, dir := range dirs {
+		matches := cpuDirPattern.FindStringSubmatch(dir.Name())
+		if matches == nil {
+			continue
+		}
+		cpu, _ := strconv.Atoi(matches[1])
+		file := filepath.Join(cpuSysfsDir, dir.Name(), "cpufreq", "scaling_governor")
+		content, err := afero.ReadFile(fs, file)
+		if os.IsNotExist(err) {
+			log.Debugf("Skipping CPU %d as it doesn't expose cpufreq", cpu)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		governors = append(governors, cpuGovernor{cpu, file, strings.TrimSpace(string(content))})
+	}
+	sort.Slice(governors, func(i, j int) bool { return governors[i].cpu < governors[j].cpu })
+	return governors, nil
+}
+
+// CPUGovernorFiles returns the 'scaling_governor' files of the CPUs exposing
+// cpufreq, which the CPU governor tuner writes to, sorted by CPU.
+func CPUGovernorFiles(fs afero.Fs) ([]string, error) {
+	governors, err := readCPUGovernors(fs)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(governors))
+	for _, governor := range governors {
+		files = append(files, governor.file)
+	}
+	return files, nil
+}
+
+// cpuGovernorsDistribution summarizes the governors of the CPUs, e.g.
+// 'performance (64 CPUs)' or 'powersave: 48 CPUs, performance: 16 CPUs', the
+// most used first.
+func cpuGovernorsDistribution(governors []cpuGovernor) string {
+	counts := map[string]int{}
+	for _, g := range governors {
+		counts[g.governor]++
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	cpus := func(n int) string {
+		if n == 1 {
+			return "1 CPU"
+		}
+		return fmt.Sprintf("%d CPUs", n)
+	}
+	if len(names) == 1 {
+		return fmt.Sprintf("%s (%s)", names[0], cpus(counts[names[0]]))
+	}
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %s", name, cpus(counts[name])))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NewCPUGovernorChecker creates a checker passing if all the CPUs exposing
+// cpufreq use the governor, the current value being the distribution of
+// their governors. Systems without cpufreq pass, their CPUs frequency isn't
+// scaled by the kernel.
+func NewCPUGovernorChecker(fs afero.Fs, governor string) Checker {
+	return &cpuGovernorChecker{fs: fs, governor: governor}
+}
+
+type cpuGovernorChecker struct {
+	fs       afero.Fs
+	governor string
+}
+
+func (*cpuGovernorChecker) ID() CheckerID {
+	return CPUGovernorChecker
+}
+
+func (*cpuGovernorChecker) GetDesc() string {
+	return "CPU frequency governor"
+}
+
+func (*cpuGovernorChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *cpuGovernorChecker) GetRequiredAsString() string {
+	return c.governor
+}
+
+func (c *cpuGovernorChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerID: c.ID(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	governors, err := readCPUGovernors(c.fs)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if len(governors) == 0 {
+		res.IsOk = true
+		res.Current = "cpufreq not available"
+		return res
+	}
+	res.IsOk = true
+	for _, g := range governors {
+		res.IsOk = res.IsOk && g.governor == c.governor
+	}
+	res.Current = cpuGovernorsDistribution(governors)
+	return res
+}
+
+// NewCPUGovernorTuner creates a tuner setting the frequency governor of each
+// CPU exposing cpufreq to the given one, e.g. 'performance', which must be
+// available on all of them. CPUs already using it are left untouched, the
+// previous governors are recorded in the tune snapshot, so they're restored
+// by reverting. A single result is reported for all the CPUs.
+func NewCPUGovernorTuner(
+	fs afero.Fs, governor string, executor executors.Executor,
+) Tunable {
+	return newFileCheckedTunable(
+		NewCPUGovernorChecker(fs, governor),
+		func() TuneResult {
+			governors, err := readCPUGovernors(fs)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			if err := checkCPUGovernorAvailable(fs, governors, governor); err != nil {
+				return NewTuneError(err)
+			}
+			log.Debugf("Setting the frequency governor of %d CPUs to '%s'", len(governors), governor)
+			var changed int
+			for _, g := range governors {
+				written, err := applyIfChanged(fs, executor, g.file, governor)
+				if err != nil {
+					return NewTuneError(fmt.Errorf("unable to set the governor of CPU %d: %w", g.cpu, err))
+				}
+				if written {
+					changed++
+				}
+			}
+			if changed == 0 {
+				return newTuneUnchanged()
+			}
+			return newTuneChanged(cpuGovernorsDistribution(governors),
+				fmt.Sprintf("%s (%d CPUs changed)", governor, changed))
+		},
+		func() (bool, string) {
+			governors, err := readCPUGovernors(fs)
+			if err != nil {
+				return false, err.Error()
+			}
+			if len(governors) == 0 {
+				return false, "no CPU exposes cpufreq"
+			}
+			return true, ""
+		},
+		executor.IsLazy(),
+		func() ([]string, error) {
+			return CPUGovernorFiles(fs)
+		},
+	)
+}
+
+// checkCPUGovernorAvailable returns an error if the governor isn't one of
+// the scaling_available_governors of all the CPUs. CPUs not listing their
+// available governors are assumed to support it.
+func checkCPUGovernorAvailable(
+	fs afero.Fs, governors []cpuGovernor, governor string,
+) error {
+	for _, g := range governors {
+		file := filepath.Join(filepath.Dir(g.file), "scaling_available_governors")
+		content, err := afero.ReadFile(fs, file)
+		if err != nil {
+			continue
+		}
+		available := strings.Fields(string(content))
+		found := false
+		for _, a := range available {
+			found = found || a == governor
+		}
+		if !found {
+			return fmt.Errorf("governor '%s' isn't available on CPU %d, available: %s",
+				governor, g.cpu, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !windows
+
+package tuners
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCPUGovernors fakes the cpufreq directory of each CPU with the given
+// governor, or none for an empty one.
+func writeCPUGovernors(t *testing.T, fs afero.Fs, governors ...string) {
+	require.NoError(t, fs.MkdirAll(cpuSysfsDir+"/cpufreq", 0o755))
+	require.NoError(t, fs.MkdirAll(cpuSysfsDir+"/cpuidle", 0o755))
+	for cpu, governor := range governors {
+		dir := fmt.Sprintf("%s/cpu%d", cpuSysfsDir, cpu)
+		require.NoError(t, fs.MkdirAll(dir, 0o755))
+		if governor == "" {
+			continue
+		}
+		require.NoError(t, afero.WriteFile(fs, dir+"/cpufreq/scaling_governor", []byte(governor+"\n"), 0o644))
+		require.NoError(t, afero.WriteFile(fs, dir+"/cpufreq/scaling_available_governors", []byte("performance powersave\n"), 0o644))
+	}
+}
+
+func TestCPUGovernorChecker(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		governors  []string
+		expOk      bool
+		expCurrent string
+	}{
+		{
+			name:       "all performance",
+			governors:  []string{"performance", "performance"},
+			expOk:      true,
+			expCurrent: "performance (2 CPUs)",
+		},
+		{
+			name:       "mixed governors",
+			governors:  []string{"performance", "powersave", "powersave", ""},
+			expCurrent: "powersave: 2 CPUs, performance: 1 CPU",
+		},
+		{
+			name:       "no cpufreq",
+			governors:  []string{"", ""},
+			expOk:      true,
+			expCurrent: "cpufreq not available",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeCPUGovernors(t, fs, test.governors...)
+			res := NewCPUGovernorChecker(fs, DefaultCPUGovernor).Check()
+			require.NoError(t, res.Err)
+			require.Equal(t, test.expOk, res.IsOk)
+			require.Equal(t, test.expCurrent, res.Current)
+		})
+	}
+}
+
+func TestCPUGovernorTuner(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	// CPUs are sorted by number, not name.
+	governors := make([]string, 12)
+	for i := range governors {
+		governors[i] = "powersave"
+	}
+	governors[3], governors[5] = "performance", ""
+	writeCPUGovernors(t, fs, governors...)
+
+	exec := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	tuner := NewCPUGovernorTuner(fs, DefaultCPUGovernor, exec)
+	supported, _ := tuner.CheckIfSupported()
+	require.True(t, supported)
+	res := tuner.Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, "powersave: 10 CPUs, performance: 1 CPU", res.(*tuneResult).previous)
+	require.Equal(t, "performance (10 CPUs changed)", res.(*tuneResult).value)
+
+	check := NewCPUGovernorChecker(fs, DefaultCPUGovernor).Check()
+	require.True(t, check.IsOk)
+	require.Equal(t, "performance (11 CPUs)", check.Current)
+
+	// Only the CPUs that changed are recorded, and restored.
+	snapshot := &Snapshot{}
+	snapshot.Record(exec.Changes())
+	require.Len(t, snapshot.Values, 10)
+	_, err := snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.NoError(t, err)
+	check = NewCPUGovernorChecker(fs, DefaultCPUGovernor).Check()
+	require.Equal(t, "powersave: 10 CPUs, performance: 1 CPU", check.Current)
+}
+
+func TestCPUGovernorTunerUnavailableGovernor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeCPUGovernors(t, fs, "powersave", "powersave")
+	res := NewCPUGovernorTuner(fs, "schedutil", executors.NewDirectExecutor()).Tune(context.Background())
+	require.True(t, res.IsFailed())
+	require.Contains(t, res.Error().Error(), "governor 'schedutil' isn't available on CPU 0")
+}
+
+func TestCPUGovernorTunerNoCpufreq(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeCPUGovernors(t, fs, "", "")
+	supported, reason := NewCPUGovernorTuner(fs, DefaultCPUGovernor, executors.NewDirectExecutor()).CheckIfSupported()
+	require.False(t, supported)
+	require.Equal(t, "no CPU exposes cpufreq", reason)
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+)
+
+// DeviceStack is the device holding a directory, e.g. an md array, an LVM
+// volume or a partition, followed by the leaf devices it's stacked on, if
+// any, as returned by disk.BlockDevices.GetDirectoryStacks.
+type DeviceStack struct {
+	Directory string
+	Devices   []string
+}
+
+// DirectoryDeviceStacks returns the device stacks of the directories, in
+// directory order.
+func DirectoryDeviceStacks(
+	ctx context.Context, blockDevices disk.BlockDevices, directories []string,
+) ([]DeviceStack, error) {
+	var stacks []DeviceStack
+	for _, directory := range directories {
+		directoryStacks, err := blockDevices.GetDirectoryStacks(ctx, directory)
+		if err != nil {
+			return nil, err
+		}
+		for _, devices := range directoryStacks {
+			stacks = append(stacks, DeviceStack{directory, devices})
+		}
+	}
+	return stacks, nil
+}
+
+// DeviceGroup ties the device a user knows of, the one holding the data
+// directories, to the leaf devices it resolves to, along with the results
+// of the tuners on each of them. Its JSON field names are stable:
+//
+//   - device: the name of the device, e.g. 'md0'.
+//   - directories: the directories it holds, if any.
+//   - results: the results of the tuners on the device itself, e.g. its
+//     read-ahead.
+//   - members: the leaf devices, e.g. the disks of the md array, along with
+//     the results of the tuners on them.
+type DeviceGroup struct {
+	Device      string              `json:"device"`
+	Directories []string            `json:"directories,omitempty"`
+	Results     []DeviceTuneResult  `json:"results,omitempty"`
+	Members     []DeviceGroupMember `json:"members,omitempty"`
+}
+
+// DeviceGroupMember is a leaf device of a DeviceGroup and the results of the
+// tuners on it.
+type DeviceGroupMember struct {
+	Device  string             `json:"device"`
+	Results []DeviceTuneResult `json:"results"`
+}
+
+// GroupDeviceResults groups the results of the devices by the stacks they
+// belong to. The groups follow the order of the stacks, the devices of the
+// same stacks being merged into one group, e.g. for several directories on
+// the same array. Devices in no stack, e.g. those tuned with --disk-devices,
+// get a group of their own, after the others. A leaf device shared by
+// several groups, e.g. the disk of several partitions, has its results in
+// each of them. Only the results are structured, none is left out or added.
+func GroupDeviceResults(stacks []DeviceStack, results []DeviceTuneResult) []DeviceGroup {
+	var (
+		groups []DeviceGroup
+		// groupOf is the index of the group of each top device, and
+		// membersOf the groups each leaf device is a member of.
+		groupOf   = map[string]int{}
+		membersOf = map[string][]int{}
+	)
+	for _, stack := range stacks {
+		if len(stack.Devices) == 0 {
+			continue
+		}
+		top := stack.Devices[0]
+		i, ok := groupOf[top]
+		if !ok {
+			i = len(groups)
+			groupOf[top] = i
+			groups = append(groups, DeviceGroup{Device: top})
+		}
+		if stack.Directory != "" && !contains(groups[i].Directories, stack.Directory) {
+			groups[i].Directories = append(groups[i].Directories, stack.Directory)
+		}
+		for _, member := range stack.Devices[1:] {
+			if !contains(membersOf[member], i) {
+				membersOf[member] = append(membersOf[member], i)
+				groups[i].Members = append(groups[i].Members, DeviceGroupMember{Device: member})
+			}
+		}
+	}
+	for _, result := range results {
+		i, isTop := groupOf[result.Device]
+		members, isMember := membersOf[result.Device]
+		if !isTop && !isMember {
+			i = len(groups)
+			groupOf[result.Device] = i
+			groups = append(groups, DeviceGroup{Device: result.Device})
+			isTop = true
+		}
+		if isTop {
+			groups[i].Results = append(groups[i].Results, result)
+		}
+		for _, g := range members {
+			for m := range groups[g].Members {
+				if groups[g].Members[m].Device == result.Device {
+					groups[g].Members[m].Results = append(groups[g].Members[m].Results, result)
+				}
+			}
+		}
+	}
+	return groups
+}
+
+func contains[T comparable](s []T, v T) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
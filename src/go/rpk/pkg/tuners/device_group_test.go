@@ -0,0 +1,64 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupDeviceResults(t *testing.T) {
+	// md0 holds the data directory and its snapshots, it's an array of
+	// sda and sdb. nvme0n1 was given with --disk-devices.
+	blockDevices := &blockDevicesMock{
+		getDirectoryStacks: func(directory string) ([][]string, error) {
+			return [][]string{{"md0", "sda", "sdb"}}, nil
+		},
+	}
+	stacks, err := DirectoryDeviceStacks(context.Background(), blockDevices, []string{"/var/lib/redpanda/data", "/var/lib/redpanda/snapshots"})
+	require.NoError(t, err)
+	require.Equal(t, []DeviceStack{
+		{"/var/lib/redpanda/data", []string{"md0", "sda", "sdb"}},
+		{"/var/lib/redpanda/snapshots", []string{"md0", "sda", "sdb"}},
+	}, stacks)
+
+	results := []DeviceTuneResult{
+		{Tuner: "disk_read_ahead", Device: "md0", Status: DeviceApplied, New: "4096"},
+		{Tuner: "disk_read_ahead", Device: "sda", Status: DeviceApplied, New: "4096"},
+		{Tuner: "disk_read_ahead", Device: "sdb", Status: DeviceSkipped, Reason: "already tuned"},
+		{Tuner: "disk_scheduler", Device: "nvme0n1", Status: DeviceApplied, New: "none"},
+		{Tuner: "disk_scheduler", Device: "sda", Status: DeviceApplied, New: "none"},
+		{Tuner: "disk_scheduler", Device: "sdb", Status: DeviceFailed, Error: "unable to write"},
+	}
+	require.Equal(t, []DeviceGroup{
+		{
+			Device:      "md0",
+			Directories: []string{"/var/lib/redpanda/data", "/var/lib/redpanda/snapshots"},
+			Results:     []DeviceTuneResult{results[0]},
+			Members: []DeviceGroupMember{
+				{Device: "sda", Results: []DeviceTuneResult{results[1], results[4]}},
+				{Device: "sdb", Results: []DeviceTuneResult{results[2], results[5]}},
+			},
+		},
+		{
+			Device:  "nvme0n1",
+			Results: []DeviceTuneResult{results[3]},
+		},
+	}, GroupDeviceResults(stacks, results))
+
+	// Without stacks, each device is a group of its own.
+	require.Equal(t, []DeviceGroup{
+		{Device: "md0", Results: []DeviceTuneResult{results[0]}},
+		{Device: "sda", Results: []DeviceTuneResult{results[1]}},
+		{Device: "sdb", Results: []DeviceTuneResult{results[2]}},
+	}, GroupDeviceResults(nil, results[:3]))
+}
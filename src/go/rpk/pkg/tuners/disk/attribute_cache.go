@@ -0,0 +1,264 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/afero/mem"
+)
+
+// AttributeCache is a filesystem caching the sysfs attributes read through
+// it, so that the tuners and checkers of a tune run sharing a device read
+// each of its attributes once, e.g. its scheduler or whether it's rotational,
+// instead of stating and reading them again for each tuner.
+//
+// Attributes are cached by their path, i.e. the resolved system path of
+// their device joined with the name of the attribute, along with the results
+// of stating them and of reading the sysfs links. Only the paths under the
+// sysfs root are cached, the others are passed through.
+//
+// Writing an attribute through the cache drops the cached attributes of its
+// directory, as the kernel updates related attributes on writes, e.g. writing
+// 'queue/scheduler' resets 'queue/nr_requests'. Changes made to sysfs outside
+// of the cache aren't seen, the cache is meant to last for a single run. It's
+// safe for concurrent use.
+type AttributeCache struct {
+	afero.Fs
+	root string
+
+	mu    sync.Mutex
+	stats map[string]statResult
+	files map[string]*mem.FileData
+	links map[string]linkResult
+	// gen is bumped on each invalidation, so that reads racing with a write
+	// don't cache the value read before it.
+	gen uint64
+}
+
+type statResult struct {
+	info os.FileInfo
+	err  error
+}
+
+type linkResult struct {
+	target string
+	err    error
+}
+
+// NewAttributeCache returns an AttributeCache caching the attributes of the
+// sysfs mounted at sysfsRoot, or at DefaultSysfsRoot if empty, read through
+// fs.
+func NewAttributeCache(fs afero.Fs, sysfsRoot string) *AttributeCache {
+	if sysfsRoot == "" {
+		sysfsRoot = DefaultSysfsRoot
+	}
+	return &AttributeCache{
+		Fs:    fs,
+		root:  filepath.Clean(sysfsRoot),
+		stats: map[string]statResult{},
+		files: map[string]*mem.FileData{},
+		links: map[string]linkResult{},
+	}
+}
+
+func (c *AttributeCache) Name() string {
+	return "AttributeCache"
+}
+
+// cacheable returns the cache key of name, and whether it's under the sysfs
+// root.
+func (c *AttributeCache) cacheable(name string) (string, bool) {
+	name = filepath.Clean(name)
+	return name, strings.HasPrefix(name, c.root+string(filepath.Separator))
+}
+
+// store calls fn to cache a value read in the given generation, unless an
+// attribute was written since.
+func (c *AttributeCache) store(gen uint64, fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen == gen {
+		fn()
+	}
+}
+
+// invalidate drops the cached entries of the directory holding name, which
+// is about to be, or was, written.
+func (c *AttributeCache) invalidate(name string) {
+	name, ok := c.cacheable(name)
+	if !ok {
+		return
+	}
+	prefix := filepath.Dir(name) + string(filepath.Separator)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for key := range c.stats {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.stats, key)
+		}
+	}
+	for key := range c.files {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.files, key)
+		}
+	}
+	for key := range c.links {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.links, key)
+		}
+	}
+}
+
+func (c *AttributeCache) Stat(name string) (os.FileInfo, error) {
+	key, ok := c.cacheable(name)
+	if !ok {
+		return c.Fs.Stat(name)
+	}
+	c.mu.Lock()
+	res, cached := c.stats[key]
+	gen := c.gen
+	c.mu.Unlock()
+	if cached {
+		return res.info, res.err
+	}
+	info, err := c.Fs.Stat(name)
+	c.store(gen, func() { c.stats[key] = statResult{info, err} })
+	return info, err
+}
+
+// ReadlinkIfPossible reads the link through the underlying filesystem, see
+// afero.LinkReader.
+func (c *AttributeCache) ReadlinkIfPossible(name string) (string, error) {
+	reader, ok := c.Fs.(afero.LinkReader)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}
+	}
+	key, ok := c.cacheable(name)
+	if !ok {
+		return reader.ReadlinkIfPossible(name)
+	}
+	c.mu.Lock()
+	res, cached := c.links[key]
+	gen := c.gen
+	c.mu.Unlock()
+	if cached {
+		return res.target, res.err
+	}
+	target, err := reader.ReadlinkIfPossible(name)
+	c.store(gen, func() { c.links[key] = linkResult{target, err} })
+	return target, err
+}
+
+func (c *AttributeCache) Open(name string) (afero.File, error) {
+	return c.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (c *AttributeCache) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		c.invalidate(name)
+		f, err := c.Fs.OpenFile(name, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		return &writtenFile{File: f, cache: c}, nil
+	}
+	key, ok := c.cacheable(name)
+	if !ok {
+		return c.Fs.OpenFile(name, flag, perm)
+	}
+	// Directories are listed, and errors reported, by the underlying
+	// filesystem.
+	info, err := c.Stat(name)
+	if err != nil || info.IsDir() {
+		return c.Fs.OpenFile(name, flag, perm)
+	}
+	c.mu.Lock()
+	data, cached := c.files[key]
+	gen := c.gen
+	c.mu.Unlock()
+	if !cached {
+		content, err := afero.ReadFile(c.Fs, name)
+		if err != nil {
+			// Not cached, e.g. attributes only root may read.
+			return nil, err
+		}
+		data = mem.CreateFile(key)
+		w := mem.NewFileHandle(data)
+		w.Write(content)
+		mem.SetMode(data, info.Mode())
+		c.store(gen, func() { c.files[key] = data })
+	}
+	return mem.NewReadOnlyFileHandle(data), nil
+}
+
+func (c *AttributeCache) Create(name string) (afero.File, error) {
+	return c.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666)
+}
+
+func (c *AttributeCache) Mkdir(name string, perm os.FileMode) error {
+	defer c.invalidate(name)
+	return c.Fs.Mkdir(name, perm)
+}
+
+func (c *AttributeCache) MkdirAll(path string, perm os.FileMode) error {
+	defer c.invalidate(path)
+	return c.Fs.MkdirAll(path, perm)
+}
+
+func (c *AttributeCache) Remove(name string) error {
+	defer c.invalidate(name)
+	return c.Fs.Remove(name)
+}
+
+func (c *AttributeCache) RemoveAll(path string) error {
+	defer c.invalidate(path)
+	return c.Fs.RemoveAll(path)
+}
+
+func (c *AttributeCache) Rename(oldname, newname string) error {
+	defer c.invalidate(newname)
+	defer c.invalidate(oldname)
+	return c.Fs.Rename(oldname, newname)
+}
+
+func (c *AttributeCache) Chmod(name string, mode os.FileMode) error {
+	defer c.invalidate(name)
+	return c.Fs.Chmod(name, mode)
+}
+
+func (c *AttributeCache) Chown(name string, uid, gid int) error {
+	defer c.invalidate(name)
+	return c.Fs.Chown(name, uid, gid)
+}
+
+func (c *AttributeCache) Chtimes(name string, atime, mtime time.Time) error {
+	defer c.invalidate(name)
+	return c.Fs.Chtimes(name, atime, mtime)
+}
+
+// writtenFile is a file opened for writing through the cache, the attributes
+// of its directory are dropped again once it's closed, as they may have been
+// read and cached while it was written.
+type writtenFile struct {
+	afero.File
+	cache *AttributeCache
+}
+
+func (f *writtenFile) Close() error {
+	defer f.cache.invalidate(f.File.Name())
+	return f.File.Close()
+}
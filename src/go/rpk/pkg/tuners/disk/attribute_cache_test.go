@@ -0,0 +1,183 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFs counts the calls reaching the filesystem, each of them being a
+// syscall on a real sysfs.
+type countingFs struct {
+	afero.Fs
+	calls int64
+}
+
+func (c *countingFs) Stat(name string) (os.FileInfo, error) {
+	atomic.AddInt64(&c.calls, 1)
+	return c.Fs.Stat(name)
+}
+
+func (c *countingFs) Open(name string) (afero.File, error) {
+	atomic.AddInt64(&c.calls, 1)
+	return c.Fs.Open(name)
+}
+
+func (c *countingFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	atomic.AddInt64(&c.calls, 1)
+	return c.Fs.OpenFile(name, flag, perm)
+}
+
+func TestAttributeCache(t *testing.T) {
+	const (
+		scheduler  = "/sys/block/sda/queue/scheduler"
+		nrRequests = "/sys/block/sda/queue/nr_requests"
+		config     = "/etc/redpanda/redpanda.yaml"
+	)
+	mem := afero.NewMemMapFs()
+	afero.WriteFile(mem, scheduler, []byte("[mq-deadline] none\n"), 0o644)
+	afero.WriteFile(mem, nrRequests, []byte("64\n"), 0o644)
+	afero.WriteFile(mem, config, []byte("redpanda: {}\n"), 0o644)
+	counting := &countingFs{Fs: mem}
+	cache := NewAttributeCache(counting, "/sys")
+
+	read := func(path string) string {
+		content, err := afero.ReadFile(cache, path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	// Each attribute is stated and read once.
+	for i := 0; i < 3; i++ {
+		exists, err := afero.Exists(cache, scheduler)
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, "[mq-deadline] none\n", read(scheduler))
+		require.Equal(t, "64\n", read(nrRequests))
+	}
+	require.EqualValues(t, 4, counting.calls)
+
+	// Missing attributes too.
+	for i := 0; i < 3; i++ {
+		exists, err := afero.Exists(cache, "/sys/block/sda/queue/write_cache")
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+	require.EqualValues(t, 5, counting.calls)
+
+	// Paths outside of sysfs aren't cached.
+	require.Equal(t, "redpanda: {}\n", read(config))
+	require.Equal(t, "redpanda: {}\n", read(config))
+	require.EqualValues(t, 7, counting.calls)
+
+	// Writing an attribute drops the attributes of its directory, the
+	// kernel resetting nr_requests when the scheduler changes.
+	require.NoError(t, afero.WriteFile(cache, scheduler, []byte("none"), 0o644))
+	afero.WriteFile(mem, nrRequests, []byte("1023\n"), 0o644)
+	require.Equal(t, "none", read(scheduler))
+	require.Equal(t, "1023\n", read(nrRequests))
+}
+
+func TestAttributeCache_links(t *testing.T) {
+	links := &linkFs{
+		Fs:    afero.NewMemMapFs(),
+		links: map[string]string{"/sys/dev/block/8:0": "../../block/sda"},
+	}
+	cache := NewAttributeCache(links, "/sys")
+	for i := 0; i < 2; i++ {
+		target, err := cache.ReadlinkIfPossible("/sys/dev/block/8:0")
+		require.NoError(t, err)
+		require.Equal(t, "../../block/sda", target)
+	}
+	// The link is cached, even if it changes afterwards.
+	links.links["/sys/dev/block/8:0"] = "../../block/sdb"
+	target, err := cache.ReadlinkIfPossible("/sys/dev/block/8:0")
+	require.NoError(t, err)
+	require.Equal(t, "../../block/sda", target)
+
+	_, err = NewAttributeCache(afero.NewMemMapFs(), "/sys").ReadlinkIfPossible("/sys/dev/block/8:0")
+	require.ErrorIs(t, err, afero.ErrNoReadlink)
+}
+
+// BenchmarkAttributeCache reads the attributes of the devices of a host with
+// 16 NVMe disks, as the disk tuners and their checkers do in a tune run,
+// reporting the filesystem calls, i.e. the syscalls, of each run.
+func BenchmarkAttributeCache(b *testing.B) {
+	const devices = 16
+	mem := afero.NewMemMapFs()
+	for i := 0; i < devices; i++ {
+		syspath := fmt.Sprintf("/sys/block/nvme%dn1", i)
+		writeFakeDevice(mem, syspath, filepath.Base(syspath), false)
+		writeQueueAttributes(mem, syspath, map[string]string{
+			"scheduler":     "[none] mq-deadline kyber",
+			"nomerges":      "0",
+			"nr_requests":   "1023",
+			"read_ahead_kb": "128",
+			"add_random":    "0",
+			"rotational":    "0",
+		})
+	}
+	blockDevices := &blockDevicesMock{
+		getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+			name := filepath.Base(path)
+			return &blockDevice{devnode: path, syspath: filepath.Join("/sys/block", name)}, nil
+		},
+	}
+	// tuneRun reads the attributes of each device once per disk tuner and
+	// once per checker.
+	tuneRun := func(b *testing.B, fs afero.Fs) {
+		features := NewDeviceFeatures(fs, blockDevices)
+		for pass := 0; pass < 2; pass++ {
+			for i := 0; i < devices; i++ {
+				device := fmt.Sprintf("nvme%dn1", i)
+				for _, read := range []func(string) (int, error){
+					features.GetNomerges,
+					features.GetNrRequests,
+					features.GetReadAheadKB,
+					features.GetAddRandom,
+				} {
+					if _, err := read(device); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if _, err := features.GetScheduler(device); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+	for _, bench := range []struct {
+		name  string
+		cache bool
+	}{
+		{"uncached", false},
+		{"cached", true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			counting := &countingFs{Fs: mem}
+			for n := 0; n < b.N; n++ {
+				var fs afero.Fs = counting
+				if bench.cache {
+					// One cache per run.
+					fs = NewAttributeCache(counting, "/sys")
+				}
+				tuneRun(b, fs)
+			}
+			b.ReportMetric(float64(counting.calls)/float64(b.N), "syscalls/op")
+		})
+	}
+}
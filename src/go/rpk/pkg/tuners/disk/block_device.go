@@ -10,8 +10,12 @@
 package disk
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
@@ -19,16 +23,306 @@ import (
 	"github.com/spf13/afero"
 )
 
+// ErrUnsupportedPlatform is returned when resolving block devices on
+// platforms without sysfs, where disk tuning is not available.
+var ErrUnsupportedPlatform = errors.New("block devices detection is not supported on this platform")
+
+// ErrNotBlockDevice is returned when resolving the block device of a path
+// living on a filesystem without one, e.g. tmpfs, an overlay or NFS, see
+// NotBlockDeviceError. Unlike ErrSysfsUnavailable, there's no device to tune.
+var ErrNotBlockDevice = errors.New("no block device backs the filesystem")
+
+// NotBlockDeviceError is ErrNotBlockDevice for a path, naming the type of the
+// filesystem holding it, e.g. for callers to tell that disk tuning doesn't
+// apply to a data directory on tmpfs.
+type NotBlockDeviceError struct {
+	Path   string
+	FsType string
+	// Detail tells why the filesystem has no block device, if it's not
+	// implied by its type, e.g. "mounted from '10.0.0.1:/export'".
+	Detail string
+}
+
+func (e *NotBlockDeviceError) Error() string {
+	msg := fmt.Sprintf("%v: '%s' is on %s", ErrNotBlockDevice, e.Path, e.FsType)
+	if e.Detail != "" {
+		msg += " " + e.Detail
+	}
+	return msg
+}
+
+func (*NotBlockDeviceError) Is(target error) bool {
+	return target == ErrNotBlockDevice
+}
+
+// ErrDeviceNotFound is returned when looking up a block device by a name
+// which is not in sysfs, see DeviceNotFoundError.
+var ErrDeviceNotFound = errors.New("block device not found")
+
+// DeviceNotFoundError is ErrDeviceNotFound for a name, e.g. a mistyped one
+// given to tune, listing the sysfs directories it was looked up in.
+type DeviceNotFoundError struct {
+	Name     string
+	Searched []string
+}
+
+func (e *DeviceNotFoundError) Error() string {
+	return fmt.Sprintf("block device '%s' not found in '%s'", e.Name, strings.Join(e.Searched, "' nor in '"))
+}
+
+func (*DeviceNotFoundError) Is(target error) bool {
+	return target == ErrDeviceNotFound
+}
+
+// CharDeviceError is returned when resolving the block device of a device
+// number that is the number of a character device, as listed in
+// '<sysfs>/dev/char', e.g. the st_rdev of a terminal mistaken for the st_dev
+// of a file.
+type CharDeviceError struct {
+	Major, Minor uint32
+	// Syspath is the system path of the character device.
+	Syspath string
+}
+
+func (e *CharDeviceError) Error() string {
+	return fmt.Sprintf("{%d, %d} is character device '%s', not a block device",
+		e.Major, e.Minor, filepath.Base(e.Syspath))
+}
+
+// ErrSysfsUnavailable is returned when the '<sysfs>/dev/block' link of a
+// device is missing or isn't a link, e.g. on stripped-down container kernels
+// which don't populate it, and the device couldn't be found by name either.
+// The disk tuners skip tuning instead of failing on it.
+var ErrSysfsUnavailable = errors.New("sysfs not available")
+
+// sysfsUnavailableError is ErrSysfsUnavailable, while still wrapping the
+// error it was caused by, e.g. an os.ErrNotExist one.
+type sysfsUnavailableError struct {
+	err error
+}
+
+func (e *sysfsUnavailableError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrSysfsUnavailable, e.err)
+}
+
+func (e *sysfsUnavailableError) Is(target error) bool {
+	return target == ErrSysfsUnavailable
+}
+
+func (e *sysfsUnavailableError) Unwrap() error {
+	return e.err
+}
+
+// errMalformedUevent is returned when the uevent file of a device isn't a
+// list of KEY=value lines.
+var errMalformedUevent = errors.New("Malformed uevent file content")
+
+// ResolveErrorKind classifies the failures to resolve block devices, so that
+// callers can tell the environment lacking sysfs, as in containers, from a
+// genuine misconfiguration of the host.
+type ResolveErrorKind int
+
+const (
+	// ResolveErrorUnknown is any failure not classified below.
+	ResolveErrorUnknown ResolveErrorKind = iota
+	// ResolveErrorSysfsUnavailable is a sysfs that isn't mounted or isn't
+	// populated, see ErrSysfsUnavailable.
+	ResolveErrorSysfsUnavailable
+	// ResolveErrorNotFound is a device, or one of its attributes, missing
+	// from a sysfs that is otherwise available.
+	ResolveErrorNotFound
+	// ResolveErrorPermissionDenied is a sysfs which can't be read with the
+	// permissions rpk runs with.
+	ResolveErrorPermissionDenied
+	// ResolveErrorMalformed is a sysfs attribute whose content can't be
+	// parsed.
+	ResolveErrorMalformed
+	// ResolveErrorUnsupportedStack is a stack of devices which can't be
+	// resolved to its physical devices, e.g. one with a cycle.
+	ResolveErrorUnsupportedStack
+	// ResolveErrorCanceled is a resolution stopped by its context.
+	ResolveErrorCanceled
+)
+
+func (k ResolveErrorKind) String() string {
+	switch k {
+	case ResolveErrorSysfsUnavailable:
+		return "sysfs unavailable"
+	case ResolveErrorNotFound:
+		return "not found"
+	case ResolveErrorPermissionDenied:
+		return "permission denied"
+	case ResolveErrorMalformed:
+		return "malformed"
+	case ResolveErrorUnsupportedStack:
+		return "unsupported stack"
+	case ResolveErrorCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// resolveErrorKind returns the kind of the failure caused by err.
+func resolveErrorKind(err error) ResolveErrorKind {
+	switch {
+	case errors.Is(err, ErrSysfsUnavailable):
+		return ResolveErrorSysfsUnavailable
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ResolveErrorCanceled
+	case errors.Is(err, os.ErrPermission):
+		return ResolveErrorPermissionDenied
+	case errors.Is(err, os.ErrNotExist):
+		return ResolveErrorNotFound
+	case errors.Is(err, errMalformedUevent):
+		return ResolveErrorMalformed
+	default:
+		return ResolveErrorUnknown
+	}
+}
+
+// DeviceResolveError is returned when a block device fails to resolve, it
+// names the device and the operation that failed and wraps the cause, e.g.
+// an os.ErrNotExist error when the container lacks sysfs.
+type DeviceResolveError struct {
+	// Major and Minor are the numbers of the device, they are unset when it
+	// was resolved from its system path or name.
+	Major, Minor uint32
+	// Path is the system path of the device, or the sysfs link to it.
+	Path string
+	// Op is the operation that failed, e.g. "resolve" or "read".
+	Op string
+	// Kind classifies the failure, for callers to act on it.
+	Kind ResolveErrorKind
+	Err  error
+}
+
+func (e *DeviceResolveError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "unable to %s block device", e.Op)
+	if e.Major != 0 || e.Minor != 0 {
+		fmt.Fprintf(&b, " {%d, %d}", e.Major, e.Minor)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, " at '%s'", e.Path)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	return b.String()
+}
+
+func (e *DeviceResolveError) Unwrap() error {
+	return e.Err
+}
+
+// readError returns the error of reading the device at syspath.
+func readError(syspath string, err error) error {
+	return &DeviceResolveError{Path: syspath, Op: "read", Kind: resolveErrorKind(err), Err: err}
+}
+
+// ResolveErrorKindOf returns the kind of the DeviceResolveError err wraps,
+// or ResolveErrorUnknown if it doesn't wrap one.
+func ResolveErrorKindOf(err error) ResolveErrorKind {
+	var resolveErr *DeviceResolveError
+	if !errors.As(err, &resolveErr) {
+		return ResolveErrorUnknown
+	}
+	return resolveErr.Kind
+}
+
 type BlockDevice interface {
 	Syspath() string
 	Devnode() string
 	Parent() BlockDevice
+	// Nvme returns the NVMe namespace details of the device, or nil if the
+	// device is not an NVMe namespace.
+	Nvme() *NvmeNamespace
+	// Partition returns the partition the device was resolved from, or nil
+	// if the device was not resolved from a partition.
+	Partition() BlockDevice
+	// Md returns the md array details of the device, or nil if the device
+	// is not an md array.
+	Md() *MdArray
+	// RaidLevel returns the RAID level of the md array the device is, or of
+	// the nearest md array the device was resolved through when resolving
+	// physical devices. It's empty for devices that are not part of an array.
+	RaidLevel() string
+	// Multipath returns the multipath details of the device, or nil if the
+	// device is not a dm-mpath device.
+	Multipath() *Multipath
+	// Cache returns the layered cache details of the device, or nil if the
+	// device is neither a bcache device nor an LVM cache volume.
+	Cache() *CacheDevice
+	// Thin returns the thin volume details of the device, or nil if the
+	// device is not an LVM thin volume, see thin.go.
+	Thin() *ThinVolume
+	// CacheRole returns whether the device is a caching or a backing device
+	// of the nearest cache device it was resolved through when resolving
+	// physical devices, see CacheRoleCache and CacheRoleBacking. It's empty
+	// for devices that are not part of a cache device.
+	CacheRole() string
+	// IsRotational returns whether the device is a spinning disk, as read
+	// from its 'queue/rotational' attribute. Stacked devices, e.g.
+	// device-mapper volumes or md arrays, are rotational if any of their
+	// physical devices is.
+	IsRotational() (bool, error)
+	// Class returns the kind of storage backing the device, e.g. a local SSD
+	// or a network-attached cloud volume.
+	Class() DeviceClass
+	// Model, Vendor and Serial return the identity the drive reports, see
+	// device_identity.go. They are empty if its transport doesn't expose
+	// them, and list the ones of each physical device of stacked devices.
+	Model() (string, error)
+	Vendor() (string, error)
+	Serial() (string, error)
+	// ZonedModel returns whether the device is zoned, e.g. an SMR drive, see
+	// zoned.go.
+	ZonedModel() (string, error)
+	// Transport returns the bus the device is attached through, e.g.
+	// TransportSATA, or TransportMixed for stacked devices over several
+	// buses, see transport.go.
+	Transport(ctx context.Context) (TransportType, error)
+	// WriteCache returns the write cache mode of the device, or of each of
+	// the physical devices of stacked devices, see write_cache.go.
+	WriteCache() (string, error)
+	// SupportsDiscard and DiscardGranularity return whether the device
+	// accepts discards and the smallest range it discards, see discard.go.
+	SupportsDiscard() (bool, error)
+	DiscardGranularity() (uint64, error)
+	// LogicalBlockSize and PhysicalBlockSize return the sector sizes of the
+	// device, in bytes, see block_size.go.
+	LogicalBlockSize() (uint64, error)
+	PhysicalBlockSize() (uint64, error)
+	// PartitionAlignment returns whether the partition the device was
+	// resolved from starts on a physical block, or nil if it wasn't
+	// resolved from a partition.
+	PartitionAlignment() (*PartitionAlignment, error)
+	// Capabilities returns which features are meaningful for the device,
+	// see capabilities.go.
+	Capabilities() DeviceCapabilities
 }
 
 type blockDevice struct {
-	syspath string
-	devnode string
-	parent  BlockDevice
+	syspath    string
+	devnode    string
+	parent     BlockDevice
+	nvme       *NvmeNamespace
+	partition  BlockDevice
+	md         *MdArray
+	raidLevel  string
+	multipath  *Multipath
+	cache      *CacheDevice
+	thin       *ThinVolume
+	cacheRole  string
+	rotational bool
+	class      DeviceClass
+	// stacked is set for devices with slaves, whose rotational value is
+	// derived from their physical devices the first time it's needed.
+	stacked      bool
+	capabilities DeviceCapabilities
+	// stackedCapabilities is set for devices with slaves until the
+	// capabilities derived from their physical devices are.
+	stackedCapabilities bool
+	resolver            *DeviceResolver
 }
 
 func (d *blockDevice) Syspath() string {
@@ -43,31 +337,171 @@ func (d *blockDevice) Parent() BlockDevice {
 	return d.parent
 }
 
-func deviceFromSystemPath(syspath string, fs afero.Fs) (BlockDevice, error) {
-	log.Debugf("Reading block device details from '%s'", syspath)
-	lines, err := utils.ReadFileLines(fs, filepath.Join(syspath, "uevent"))
+func (d *blockDevice) Nvme() *NvmeNamespace {
+	return d.nvme
+}
+
+func (d *blockDevice) Partition() BlockDevice {
+	return d.partition
+}
+
+func (d *blockDevice) Md() *MdArray {
+	return d.md
+}
+
+func (d *blockDevice) Class() DeviceClass {
+	return d.class
+}
+
+func (d *blockDevice) RaidLevel() string {
+	if d.md != nil {
+		return d.md.Level
+	}
+	return d.raidLevel
+}
+
+func (d *blockDevice) Multipath() *Multipath {
+	return d.multipath
+}
+
+func (d *blockDevice) Cache() *CacheDevice {
+	return d.cache
+}
+
+func (d *blockDevice) Thin() *ThinVolume {
+	return d.thin
+}
+
+func (d *blockDevice) CacheRole() string {
+	return d.cacheRole
+}
+
+func (d *blockDevice) IsRotational() (bool, error) {
+	if d.resolver == nil {
+		return d.rotational, nil
+	}
+	// Devices are shared through the resolver cache, the lazily derived
+	// value is guarded by the resolver.
+	d.resolver.rotationalMu.Lock()
+	defer d.resolver.rotationalMu.Unlock()
+	if !d.stacked {
+		return d.rotational, nil
+	}
+	physDevices, err := d.resolver.resolvePhysicalDevices(context.Background(), d)
+	if err != nil {
+		return false, err
+	}
+	rotational := false
+	for _, physDevice := range physDevices {
+		if leaf, ok := physDevice.(*blockDevice); ok && leaf.rotational {
+			rotational = true
+			break
+		}
+	}
+	d.rotational, d.stacked = rotational, false
+	return rotational, nil
+}
+
+// deviceFromSystemPath returns the block device at syspath. Partitions are
+// resolved to the whole disk device holding them, as most of the queue
+// attributes are only exposed by the disk. It fails with the ctx error once
+// ctx is done, before each device is read.
+func (r *DeviceResolver) deviceFromSystemPath(
+	ctx context.Context, syspath string,
+) (BlockDevice, error) {
+	device, err := r.readDevice(ctx, syspath)
 	if err != nil {
 		return nil, err
 	}
-	deviceAttrs, err := parseUeventFile(lines)
+	if !isPartition(syspath, r.fs) {
+		return device, nil
+	}
+	diskPath, err := partitionDiskPath(syspath, r.fs)
 	if err != nil {
 		return nil, err
 	}
+	log.Debugf("'%s' is a partition of '%s'", syspath, diskPath)
+	disk, err := r.readDevice(ctx, diskPath)
+	if err != nil {
+		return nil, err
+	}
+	disk.partition = device
+	return disk, nil
+}
+
+func (r *DeviceResolver) readDevice(ctx context.Context, syspath string) (*blockDevice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, readError(syspath, err)
+	}
+	log.Debugf("Reading block device details from '%s'", syspath)
+	lines, err := utils.ReadFileLines(r.fs, filepath.Join(syspath, "uevent"))
+	if err != nil {
+		return nil, readError(syspath, err)
+	}
+	deviceAttrs, err := parseUeventFile(lines)
+	if err != nil {
+		return nil, readError(syspath, err)
+	}
 
 	parentPath := filepath.Dir(syspath)
 	var parent BlockDevice
-	if exists, _ := afero.Exists(fs, filepath.Join(parentPath, "uevent")); exists {
-		parent, err = deviceFromSystemPath(parentPath, fs)
+	if exists, _ := afero.Exists(r.fs, filepath.Join(parentPath, "uevent")); exists {
+		parent, err = r.deviceFromSystemPath(ctx, parentPath)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return &blockDevice{
-		syspath: syspath,
-		devnode: filepath.Join("/dev", deviceAttrs["DEVNAME"]),
-		parent:  parent,
-	}, nil
+	nvme, err := r.nvmeNamespaceFromSystemPath(syspath)
+	if err != nil {
+		return nil, readError(syspath, err)
+	}
+	md, err := mdArrayFromSystemPath(syspath, r.fs)
+	if err != nil {
+		return nil, readError(syspath, err)
+	}
+
+	rotational, err := readRotational(syspath, r.fs)
+	if err != nil {
+		return nil, readError(syspath, err)
+	}
+	slaves, err := readSlaves(syspath, r.fs)
+	if err != nil {
+		return nil, readError(syspath, err)
+	}
+
+	device := &blockDevice{
+		syspath:    syspath,
+		devnode:    filepath.Join("/dev", deviceAttrs["DEVNAME"]),
+		parent:     parent,
+		nvme:       nvme,
+		md:         md,
+		multipath:  r.multipathFromSystemPath(syspath, slaves),
+		cache:      r.cacheDeviceFromSystemPath(syspath, slaves),
+		thin:       r.thinVolumeFromSystemPath(syspath, slaves),
+		rotational: rotational,
+		class:      readDeviceClass(syspath, rotational, r.fs),
+		stacked:    len(slaves) > 0,
+		resolver:   r,
+	}
+	device.capabilities = r.readCapabilities(device)
+	device.stackedCapabilities = device.stacked
+	return device, nil
+}
+
+// readRotational reads the 'queue/rotational' attribute of the device at
+// syspath. Devices not exposing it, e.g. partitions, are reported as non
+// rotational.
+func readRotational(syspath string, fs afero.Fs) (bool, error) {
+	rotationalFile := filepath.Join(syspath, "queue", "rotational")
+	if exists, _ := afero.Exists(fs, rotationalFile); !exists {
+		return false, nil
+	}
+	value, err := readSysfsString(fs, rotationalFile)
+	if err != nil {
+		return false, err
+	}
+	return value == "1", nil
 }
 
 func parseUeventFile(lines []string) (map[string]string, error) {
@@ -75,9 +509,94 @@ func parseUeventFile(lines []string) (map[string]string, error) {
 	for _, line := range lines {
 		parts := strings.Split(line, "=")
 		if len(parts) != 2 {
-			return nil, errors.New("Malformed uevent file content")
+			return nil, errMalformedUevent
 		}
 		deviceAttrs[parts[0]] = parts[1]
 	}
 	return deviceAttrs, nil
 }
+
+func isPartition(syspath string, fs afero.Fs) bool {
+	exists, _ := afero.Exists(fs, filepath.Join(syspath, "partition"))
+	return exists
+}
+
+// partitionDiskPath returns the system path of the disk holding the
+// partition at syspath. Partitions are children of their disk in the sysfs
+// hierarchy, we walk it up looking for the disk 'queue' directory and fall
+// back to the disk named after the partition in the same directory otherwise,
+// e.g. '/sys/class/block/sda' for '/sys/class/block/sda1'.
+func partitionDiskPath(syspath string, fs afero.Fs) (string, error) {
+	for dir := filepath.Dir(syspath); dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+		if exists, _ := afero.DirExists(fs, filepath.Join(dir, "queue")); exists {
+			return dir, nil
+		}
+	}
+	name := parentDiskName(filepath.Base(syspath))
+	diskPath := filepath.Join(filepath.Dir(syspath), name)
+	if exists, _ := afero.DirExists(fs, filepath.Join(diskPath, "queue")); exists {
+		return diskPath, nil
+	}
+	return "", &DeviceResolveError{
+		Path: syspath,
+		Op:   "find the disk of partition",
+		Kind: ResolveErrorNotFound,
+		Err:  errors.New("no disk holding it in sysfs"),
+	}
+}
+
+// partitionAttributes are the sysfs attributes exposed by partitions
+// themselves, every other attribute (e.g. the 'queue' ones such as the
+// scheduler or nr_requests) is only exposed by the disk holding them.
+var partitionAttributes = map[string]bool{
+	"alignment_offset":  true,
+	"discard_alignment": true,
+	"holders":           true,
+	"inflight":          true,
+	"partition":         true,
+	"power":             true,
+	"ro":                true,
+	"size":              true,
+	"start":             true,
+	"stat":              true,
+	"uevent":            true,
+}
+
+// attributePath returns the path of the given sysfs attribute, e.g.
+// 'queue/scheduler' or 'size', of the device at syspath. Disk-wide
+// attributes of partitions are looked up in the disk holding them, while
+// partition-scoped ones are kept in the partition.
+func attributePath(syspath, attribute string, fs afero.Fs) (string, error) {
+	topLevel := strings.SplitN(attribute, string(filepath.Separator), 2)[0]
+	if !isPartition(syspath, fs) || partitionAttributes[topLevel] {
+		return filepath.Join(syspath, attribute), nil
+	}
+	diskPath, err := partitionDiskPath(syspath, fs)
+	if err != nil {
+		return "", err
+	}
+	log.Debugf("Using '%s' of disk '%s' for partition '%s'", attribute, diskPath, syspath)
+	return filepath.Join(diskPath, attribute), nil
+}
+
+var (
+	// Disks whose name ends with a digit separate the partition number with
+	// a 'p', e.g. 'nvme0n1p1' or 'mmcblk0p2'.
+	numberedDiskPartitionPattern = regexp.MustCompile(`^(.*\d)p\d+$`)
+	diskPartitionPattern         = regexp.MustCompile(`^(.*\D)\d+$`)
+)
+
+// parentDiskName returns the name of the disk holding the given partition,
+// e.g. 'sda' for 'sda1' and 'nvme0n1' for 'nvme0n1p1'.
+func parentDiskName(partition string) string {
+	if nvme, ok := parseNvmeName(partition); ok {
+		return nvme.Disk
+	}
+	if matches := numberedDiskPartitionPattern.FindStringSubmatch(partition); matches != nil {
+		return matches[1]
+	}
+	if matches := diskPartitionPattern.FindStringSubmatch(partition); matches != nil {
+		return matches[1]
+	}
+	return partition
+}
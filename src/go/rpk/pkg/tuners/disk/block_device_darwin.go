@@ -10,11 +10,40 @@
 package disk
 
 import (
-	"errors"
+	"context"
 
-	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
 )
 
-func NewDevice(_ uint64, _ afero.Fs) (BlockDevice, error) {
-	return nil, errors.New("NewDevice not available in MacOS")
+// NewDevice returns the block device with the given device number.
+func (r *DeviceResolver) NewDevice(ctx context.Context, dev uint64) (BlockDevice, error) {
+	syspath, err := r.readSyspath(unix.Major(dev), unix.Minor(dev))
+	if err != nil {
+		return nil, err
+	}
+	return r.deviceFromSystemPath(ctx, syspath)
+}
+
+// statPath returns the number of the device holding the given path,
+// following its symbolic links.
+func statPath(path string) (dev uint64, virtualFsType string, err error) {
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return 0, "", err
+	}
+	return uint64(stat.Dev), "", nil
+}
+
+// deviceFromMountInfo always fails with ErrUnsupportedPlatform as there is
+// no mount table in MacOS.
+func (*DeviceResolver) deviceFromMountInfo(
+	_ context.Context, _ string,
+) (BlockDevice, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// readSyspath always fails with ErrUnsupportedPlatform as there is no sysfs
+// in MacOS.
+func (*DeviceResolver) readSyspath(_, _ uint32) (string, error) {
+	return "", ErrUnsupportedPlatform
 }
@@ -0,0 +1,32 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build darwin
+
+package disk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDevice_unsupportedPlatform(t *testing.T) {
+	device, err := NewDevice(0, afero.NewMemMapFs())
+	require.Nil(t, device)
+	require.True(t, errors.Is(err, ErrUnsupportedPlatform))
+
+	_, err = NewDeviceFromPath(t.TempDir(), afero.NewMemMapFs())
+	require.True(t, errors.Is(err, ErrUnsupportedPlatform))
+
+	_, err = NewDeviceResolver(afero.NewMemMapFs(), "").readSyspath(8, 0)
+	require.True(t, errors.Is(err, ErrUnsupportedPlatform))
+}
@@ -10,32 +10,231 @@
 package disk
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"golang.org/x/sys/unix"
 )
 
-func NewDevice(dev uint64, fs afero.Fs) (BlockDevice, error) {
+// NewDevice returns the block device with the given device number. It fails
+// with the ctx error, wrapped with the device number, once ctx is done, and
+// with a CharDeviceError if the number is the one of a character device.
+func (r *DeviceResolver) NewDevice(ctx context.Context, dev uint64) (BlockDevice, error) {
 	maj := unix.Major(dev)
 	min := unix.Minor(dev)
+	if device, ok := r.cachedDevice(dev); ok {
+		log.Debugf("Using cached block device {%d, %d}", maj, min)
+		return device, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, &DeviceResolveError{Major: maj, Minor: min, Op: "create", Kind: ResolveErrorCanceled, Err: err}
+	}
 	log.Debugf("Creating block device from number {%d, %d}", maj, min)
-	syspath, err := readSyspath(maj, min)
+	syspath, err := r.readSyspath(maj, min)
+	if errors.Is(err, ErrSysfsUnavailable) {
+		// Stripped-down kernels may not populate the links to the devices
+		// numbers, the device is looked up by the name it's mounted from.
+		device, nameErr := r.deviceFromMountedName(ctx, maj, min)
+		if nameErr == nil {
+			return r.cacheDevice(dev, device), nil
+		}
+		log.Debugf("Unable to look up block device {%d, %d} by name: %v", maj, min, nameErr)
+		// The kernel still lists it in /proc/partitions, which identifies
+		// it, e.g. for the debug bundle, but none of its attributes.
+		device, procErr := r.deviceFromProcPartitions(maj, min)
+		if procErr != nil {
+			log.Debugf("Unable to identify block device {%d, %d} from /proc/partitions: %v", maj, min, procErr)
+			return nil, err
+		}
+		log.Debugf("Identified block device {%d, %d} as '%s' from /proc/partitions only", maj, min, device.Devnode())
+		return r.cacheDevice(dev, device), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	device, err := r.deviceFromSystemPath(ctx, syspath)
 	if err != nil {
+		// Name the device requested, the error may come from reading the
+		// devices it was resolved through.
+		var resolveErr *DeviceResolveError
+		if errors.As(err, &resolveErr) && resolveErr.Major == 0 && resolveErr.Minor == 0 {
+			resolveErr.Major, resolveErr.Minor = maj, min
+		}
 		return nil, err
 	}
-	return deviceFromSystemPath(syspath, fs)
+	return r.cacheDevice(dev, device), nil
 }
 
-func readSyspath(major, minor uint32) (string, error) {
-	blockBasePath := "/sys/dev/block"
+// statPath returns the number of the device holding the given path,
+// following its symbolic links, and the type of its filesystem if it's not
+// backed by a block device.
+func statPath(path string) (dev uint64, virtualFsType string, err error) {
+	var statfs unix.Statfs_t
+	if err := unix.Statfs(path, &statfs); err != nil {
+		return 0, "", err
+	}
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return 0, "", err
+	}
+	// The magic numbers are 32 bits, but Type is signed and its width
+	// depends on the architecture.
+	return stat.Dev, virtualFilesystemType(uint32(statfs.Type)), nil
+}
+
+// deviceFromMountInfo returns the block device backing the mount holding
+// path, looked up in the mount table. It resolves the devices the device
+// number of the path doesn't lead to, e.g. the anonymous ones of overlays,
+// bind mounts in containers or btrfs subvolumes: the device number of the
+// mount is tried first, then its source, e.g. '/dev/sda1'. Overlays are
+// resolved from their upper directory, where their writes go.
+func (r *DeviceResolver) deviceFromMountInfo(
+	ctx context.Context, path string,
+) (BlockDevice, error) {
+	mounts, err := readMountInfo(r.fs, r.MountInfoPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the mount table: %w", err)
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	if realPath, err = filepath.Abs(realPath); err != nil {
+		return nil, err
+	}
+	visited := map[*mountInfo]bool{}
+	for {
+		mount := findMount(mounts, realPath)
+		if mount == nil {
+			return nil, fmt.Errorf("no mount holding '%s' found in '%s'", realPath, r.MountInfoPath)
+		}
+		if visited[mount] {
+			return nil, &NotBlockDeviceError{
+				Path:   path,
+				FsType: "overlay",
+				Detail: fmt.Sprintf("whose upper directory '%s' is not visible", realPath),
+			}
+		}
+		visited[mount] = true
+		log.Debugf("'%s' is on the %s mount of '%s' at '%s'", realPath, mount.FsType, mount.Source, mount.MountPoint)
+
+		if mount.FsType == "overlay" {
+			upper := mount.SuperOptions["upperdir"]
+			if upper == "" {
+				return nil, &NotBlockDeviceError{Path: path, FsType: "overlay", Detail: "without upper directory"}
+			}
+			realPath = upper
+			continue
+		}
+		if mount.Major != 0 {
+			if device, err := r.NewDevice(ctx, unix.Mkdev(mount.Major, mount.Minor)); err == nil {
+				return device, nil
+			}
+		}
+		if strings.HasPrefix(mount.Source, "/dev/") {
+			// Device mapper sources are links, e.g. '/dev/mapper/vg-lv', to
+			// the device named in sysfs.
+			name := filepath.Base(mount.Source)
+			if target, err := filepath.EvalSymlinks(mount.Source); err == nil {
+				name = filepath.Base(target)
+			}
+			return r.DeviceFromName(ctx, name)
+		}
+		return nil, &NotBlockDeviceError{
+			Path:   path,
+			FsType: mount.FsType,
+			Detail: fmt.Sprintf("mounted from '%s'", mount.Source),
+		}
+	}
+}
+
+// deviceFromMountedName returns the block device with the given numbers,
+// looked up by the name of the device node it's mounted from in the mount
+// table, e.g. '/dev/sda1', see DeviceFromName.
+func (r *DeviceResolver) deviceFromMountedName(
+	ctx context.Context, major, minor uint32,
+) (BlockDevice, error) {
+	mounts, err := readMountInfo(r.fs, r.MountInfoPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the mount table: %w", err)
+	}
+	for _, mount := range mounts {
+		if mount.Major != major || mount.Minor != minor || !strings.HasPrefix(mount.Source, "/dev/") {
+			continue
+		}
+		name := filepath.Base(mount.Source)
+		if target, err := filepath.EvalSymlinks(mount.Source); err == nil {
+			name = filepath.Base(target)
+		}
+		return r.DeviceFromName(ctx, name)
+	}
+	return nil, fmt.Errorf("no mount of block device {%d, %d} found in '%s'", major, minor, r.MountInfoPath)
+}
+
+// readSyspath returns the system path of the block device with the given
+// numbers by following its '<sysfs>/dev/block/<major>:<minor>' link. It fails
+// with a CharDeviceError if the numbers are those of a character device
+// instead, and if the link leads to a device of another class than the block
+// one. The resolver filesystem must support reading links, see
+// afero.LinkReader.
+func (r *DeviceResolver) readSyspath(major, minor uint32) (string, error) {
+	blockBasePath := r.path("dev", "block")
 	path := fmt.Sprintf("%s/%d:%d", blockBasePath, major, minor)
-	linkpath, err := os.Readlink(path)
+	reader, ok := r.fs.(afero.LinkReader)
+	if !ok {
+		return "", &DeviceResolveError{
+			Major: major,
+			Minor: minor,
+			Path:  path,
+			Op:    "resolve",
+			Err:   errors.New("filesystem does not support links"),
+		}
+	}
+	linkpath, err := reader.ReadlinkIfPossible(path)
 	if err != nil {
-		return "", err
+		if errors.Is(err, os.ErrNotExist) {
+			// The caller may have passed the number of a character
+			// device, e.g. the st_rdev of a file rather than its st_dev.
+			charPath := r.path("dev", "char", fmt.Sprintf("%d:%d", major, minor))
+			if _, ok := readLinkIfPossible(r.fs, charPath); ok {
+				return "", &CharDeviceError{Major: major, Minor: minor, Syspath: resolveLink(r.fs, charPath)}
+			}
+		}
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.EINVAL) {
+			err = &sysfsUnavailableError{err: err}
+		}
+		return "", &DeviceResolveError{
+			Major: major,
+			Minor: minor,
+			Path:  path,
+			Op:    "resolve",
+			Kind:  resolveErrorKind(err),
+			Err:   err,
+		}
+	}
+	syspath := filepath.Join(blockBasePath, linkpath)
+	if filepath.IsAbs(linkpath) {
+		syspath = filepath.Clean(linkpath)
+	}
+	// Devices link to the class they belong to, block devices always
+	// belong to the block one.
+	if subsystem, ok := readLinkIfPossible(r.fs, filepath.Join(syspath, "subsystem")); ok &&
+		filepath.Base(subsystem) != "block" {
+		return "", &DeviceResolveError{
+			Major: major,
+			Minor: minor,
+			Path:  path,
+			Op:    "resolve",
+			Kind:  ResolveErrorMalformed,
+			Err:   fmt.Errorf("'%s' is in the '%s' class, not the block one", syspath, filepath.Base(subsystem)),
+		}
 	}
-	return filepath.Abs(filepath.Join(blockBasePath, linkpath))
+	return syspath, nil
 }
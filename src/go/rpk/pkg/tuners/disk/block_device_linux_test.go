@@ -0,0 +1,685 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build linux
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestNewDevice(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/259:0": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1",
+			"/sys/dev/block/259:1": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1/nvme0n1p1",
+		},
+	}
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	writeFakeDevice(fs, nvmePath+"/nvme0n1p1", "nvme0n1p1", true)
+
+	device, err := NewDevice(unix.Mkdev(259, 0), fs)
+	require.NoError(t, err)
+	require.Equal(t, nvmePath, device.Syspath())
+	require.Equal(t, "/dev/nvme0n1", device.Devnode())
+	require.Nil(t, device.Partition())
+
+	device, err = NewDevice(unix.Mkdev(259, 1), fs)
+	require.NoError(t, err)
+	require.Equal(t, "/dev/nvme0n1", device.Devnode())
+	require.Equal(t, "/dev/nvme0n1p1", device.Partition().Devnode())
+
+	_, err = NewDevice(unix.Mkdev(8, 0), fs)
+	require.Error(t, err)
+}
+
+func TestNewDeviceFromNumbers(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/259:1": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1/nvme0n1p1",
+		},
+	}
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	writeFakeDevice(fs, nvmePath+"/nvme0n1p1", "nvme0n1p1", true)
+
+	major, minor, err := ParseDeviceNumbers("259:1")
+	require.NoError(t, err)
+	device, err := NewDeviceFromNumbers(major, minor, fs)
+	require.NoError(t, err)
+	require.Equal(t, nvmePath, device.Syspath())
+	require.Equal(t, "/dev/nvme0n1p1", device.Partition().Devnode())
+
+	_, err = NewDeviceFromNumbers(8, 0, fs)
+	var resolveErr *DeviceResolveError
+	require.ErrorAs(t, err, &resolveErr)
+	require.Equal(t, uint32(8), resolveErr.Major)
+	require.Equal(t, uint32(0), resolveErr.Minor)
+}
+
+func TestNewDevice_linksNotSupported(t *testing.T) {
+	_, err := NewDevice(unix.Mkdev(259, 0), afero.NewMemMapFs())
+	require.Error(t, err)
+}
+
+func TestDeviceResolver_NewDevice_errors(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/259:0": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1",
+		},
+	}
+	resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+	tests := []struct {
+		name             string
+		dev              uint64
+		want             DeviceResolveError
+		wantMsg          string
+		sysfsUnavailable bool
+	}{
+		{
+			name: "missing sysfs link",
+			dev:  unix.Mkdev(8, 0),
+			want: DeviceResolveError{Major: 8, Minor: 0, Path: "/sys/dev/block/8:0", Op: "resolve", Kind: ResolveErrorSysfsUnavailable},
+			wantMsg: "unable to resolve block device {8, 0} at '/sys/dev/block/8:0': " +
+				"sysfs not available: readlink /sys/dev/block/8:0: file does not exist",
+			sysfsUnavailable: true,
+		},
+		{
+			name: "missing uevent",
+			dev:  unix.Mkdev(259, 0),
+			want: DeviceResolveError{Major: 259, Minor: 0, Path: nvmePath, Op: "read", Kind: ResolveErrorNotFound},
+			wantMsg: "unable to read block device {259, 0} at '" + nvmePath + "': " +
+				"open " + nvmePath + "/uevent: file does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolver.NewDevice(context.Background(), tt.dev)
+			require.EqualError(t, err, tt.wantMsg)
+			require.True(t, errors.Is(err, os.ErrNotExist))
+			require.Equal(t, tt.sysfsUnavailable, errors.Is(err, ErrSysfsUnavailable))
+			var resolveErr *DeviceResolveError
+			require.True(t, errors.As(err, &resolveErr))
+			require.Equal(t, tt.want.Major, resolveErr.Major)
+			require.Equal(t, tt.want.Minor, resolveErr.Minor)
+			require.Equal(t, tt.want.Path, resolveErr.Path)
+			require.Equal(t, tt.want.Op, resolveErr.Op)
+			require.Equal(t, tt.want.Kind, resolveErr.Kind)
+			require.Equal(t, tt.want.Kind, ResolveErrorKindOf(err))
+		})
+	}
+}
+
+func TestDeviceResolver_NewDevice_charDevice(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			// 4:1 is tty1, and 259:0 a block device whose link leads to
+			// the device of its NVMe controller, of the nvme class.
+			"/sys/dev/char/4:1":     "../../devices/virtual/tty/tty1",
+			"/sys/dev/block/259:0":  "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1",
+			nvmePath + "/subsystem": "../../../../../../../class/nvme",
+		},
+	}
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+
+	_, err := resolver.NewDevice(context.Background(), unix.Mkdev(4, 1))
+	var charErr *CharDeviceError
+	require.True(t, errors.As(err, &charErr), "%v", err)
+	require.Equal(t, &CharDeviceError{Major: 4, Minor: 1, Syspath: "/sys/devices/virtual/tty/tty1"}, charErr)
+	require.EqualError(t, err, "{4, 1} is character device 'tty1', not a block device")
+	require.False(t, errors.Is(err, ErrSysfsUnavailable))
+
+	_, err = resolver.NewDevice(context.Background(), unix.Mkdev(259, 0))
+	require.EqualError(t, err, "unable to resolve block device {259, 0} at '/sys/dev/block/259:0': '"+
+		nvmePath+"' is in the 'nvme' class, not the block one")
+	require.Equal(t, ResolveErrorMalformed, ResolveErrorKindOf(err))
+
+	// Devices of the block class resolve.
+	fs.links[nvmePath+"/subsystem"] = "../../../../../../../class/block"
+	device, err := resolver.NewDevice(context.Background(), unix.Mkdev(259, 0))
+	require.NoError(t, err)
+	require.Equal(t, nvmePath, device.Syspath())
+}
+
+func TestDeviceResolver_NewDevice_sysBlock(t *testing.T) {
+	const sdaPath = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+	// Neither '/sys/dev/block' nor '/sys/class/block' are populated.
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/block/sda": "../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+		},
+	}
+	writeFakeDevice(fs, sdaPath, "sda", false)
+	writeFakeDevice(fs, sdaPath+"/sda1", "sda1", true)
+	fs.MkdirAll("/sys/block/sda", 0o755)
+	afero.WriteFile(fs, "/proc/self/mountinfo", []byte(`1 0 8:1 / /var/lib/redpanda rw - xfs /dev/sda1 rw
+2 0 8:16 / /mnt rw - xfs /dev/sdb rw
+`), 0o644)
+	resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+
+	device, err := resolver.NewDevice(context.Background(), unix.Mkdev(8, 1))
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sda", device.Devnode())
+	require.Equal(t, sdaPath, device.Syspath())
+	require.Equal(t, "/dev/sda1", device.Partition().Devnode())
+
+	device, err = resolver.DeviceFromName(context.Background(), "sda")
+	require.NoError(t, err)
+	require.Equal(t, sdaPath, device.Syspath())
+
+	// Devices found neither by number nor by name fail with
+	// ErrSysfsUnavailable, which the disk tuners skip tuning on.
+	_, err = resolver.NewDevice(context.Background(), unix.Mkdev(8, 16))
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+	_, err = resolver.NewDevice(context.Background(), unix.Mkdev(8, 32))
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+}
+
+func TestDeviceResolver_NewDevice_procPartitions(t *testing.T) {
+	// Neither sysfs nor the mount table know the devices, which are only
+	// listed in /proc/partitions.
+	fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+	fs.MkdirAll("/sys/block", 0o755)
+	afero.WriteFile(fs, DefaultProcPartitionsPath, []byte(procPartitions), 0o644)
+	resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+
+	device, err := resolver.NewDevice(context.Background(), unix.Mkdev(8, 1))
+	require.NoError(t, err)
+	require.True(t, IsIdentifiedOnly(device))
+	require.Equal(t, "/dev/sda", device.Devnode())
+	require.Equal(t, "/dev/sda1", device.Partition().Devnode())
+	require.Empty(t, device.Syspath())
+	require.Equal(t, DeviceClassUnknown, device.Class())
+	_, err = device.IsRotational()
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+
+	// The identified devices have no slaves, they are their own physical
+	// device.
+	physDevices, err := resolver.resolvePhysicalDevices(context.Background(), device)
+	require.NoError(t, err)
+	require.Equal(t, []BlockDevice{device}, physDevices)
+
+	device, err = resolver.NewDevice(context.Background(), unix.Mkdev(253, 0))
+	require.NoError(t, err)
+	require.Equal(t, "/dev/dm-0", device.Devnode())
+	require.Nil(t, device.Partition())
+
+	// The disk tuners still skip them.
+	blockDevices := &blockDevices{fs: fs, resolver: resolver}
+	_, err = blockDevices.getPhysDevices(context.Background(), device)
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+
+	_, err = resolver.NewDevice(context.Background(), unix.Mkdev(8, 16))
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+}
+
+func TestDeviceResolver_NewDevice(t *testing.T) {
+	root := t.TempDir()
+	fs := afero.NewOsFs()
+	sdaPath := filepath.Join(root, "devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda")
+	writeFakeDevice(fs, sdaPath, "sda", false)
+	writeFakeDevice(fs, filepath.Join(sdaPath, "sda3"), "sda3", true)
+	blockPath := filepath.Join(root, "dev", "block")
+	require.NoError(t, fs.MkdirAll(blockPath, 0o755))
+	require.NoError(t, os.Symlink(
+		"../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/sda3",
+		filepath.Join(blockPath, "8:3")))
+
+	device, err := NewDeviceResolver(fs, root).NewDevice(context.Background(), unix.Mkdev(8, 3))
+	require.NoError(t, err)
+	require.Equal(t, sdaPath, device.Syspath())
+	require.Equal(t, "/dev/sda", device.Devnode())
+	require.Equal(t, "/dev/sda3", device.Partition().Devnode())
+
+	_, err = NewDeviceResolver(fs, root).NewDevice(context.Background(), unix.Mkdev(8, 0))
+	require.Error(t, err)
+}
+
+func TestDeviceResolver_NewDeviceFromPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte{0}, 0o644))
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Symlink(file, link))
+	var statfs unix.Statfs_t
+	require.NoError(t, unix.Statfs(file, &statfs))
+	if fsType := virtualFilesystemType(uint32(statfs.Type)); fsType != "" {
+		t.Skipf("'%s' is on %s", dir, fsType)
+	}
+	var stat unix.Stat_t
+	require.NoError(t, unix.Stat(file, &stat))
+
+	const sdaPath = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(stat.Dev), unix.Minor(stat.Dev)): "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+		},
+	}
+	writeFakeDevice(fs, sdaPath, "sda", false)
+
+	for _, path := range []string{file, link} {
+		device, err := NewDeviceFromPath(path, fs)
+		require.NoError(t, err)
+		require.Equal(t, sdaPath, device.Syspath())
+		require.Equal(t, "/dev/sda", device.Devnode())
+	}
+
+	_, err := NewDeviceFromPath(filepath.Join(dir, "missing"), fs)
+	require.Error(t, err)
+}
+
+func TestDeviceResolver_NewDeviceFromPath_noBackingDevice(t *testing.T) {
+	const shm = "/dev/shm"
+	var statfs unix.Statfs_t
+	if err := unix.Statfs(shm, &statfs); err != nil || statfs.Type != unix.TMPFS_MAGIC {
+		t.Skipf("'%s' is not a tmpfs mount", shm)
+	}
+	_, err := NewDeviceFromPath(shm, afero.NewMemMapFs())
+	require.True(t, errors.Is(err, ErrNotBlockDevice))
+}
+
+func TestDeviceResolver_NewDeviceFromPath_virtualFilesystems(t *testing.T) {
+	// Without sysfs, the anonymous device numbers of these filesystems would
+	// otherwise fail to resolve as if sysfs was unavailable.
+	resolver := NewDeviceResolver(afero.NewMemMapFs(), DefaultSysfsRoot)
+	for _, fsType := range []string{"tmpfs", "nfs"} {
+		resolver.statPath = func(string) (uint64, string, error) {
+			return unix.Mkdev(0, 52), fsType, nil
+		}
+		_, err := resolver.NewDeviceFromPath(context.Background(), "/var/lib/redpanda/data")
+		require.ErrorIs(t, err, ErrNotBlockDevice)
+		require.NotErrorIs(t, err, ErrSysfsUnavailable)
+		var notBlockErr *NotBlockDeviceError
+		require.ErrorAs(t, err, &notBlockErr)
+		require.Equal(t, fsType, notBlockErr.FsType)
+		require.Equal(t, "/var/lib/redpanda/data", notBlockErr.Path)
+	}
+}
+
+func TestDeviceResolver_DevicesForPaths(t *testing.T) {
+	dir := t.TempDir()
+	data, wal := filepath.Join(dir, "data"), filepath.Join(dir, "wal")
+	require.NoError(t, os.Mkdir(data, 0o755))
+	require.NoError(t, os.Mkdir(wal, 0o755))
+	var statfs unix.Statfs_t
+	require.NoError(t, unix.Statfs(dir, &statfs))
+	if fsType := virtualFilesystemType(uint32(statfs.Type)); fsType != "" {
+		t.Skipf("'%s' is on %s", dir, fsType)
+	}
+	var stat unix.Stat_t
+	require.NoError(t, unix.Stat(dir, &stat))
+
+	const sdaPath = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(stat.Dev), unix.Minor(stat.Dev)): "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/sda1",
+		},
+	}
+	writeFakeDevice(fs, sdaPath, "sda", false)
+	writeFakeDevice(fs, sdaPath+"/sda1", "sda1", true)
+
+	devices, err := DevicesForPaths(context.Background(), []string{data, wal}, fs)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	require.Equal(t, "/dev/sda", devices[0].Devnode())
+
+	missing := filepath.Join(dir, "missing")
+	devices, err = DevicesForPaths(context.Background(), []string{missing, data, filepath.Join(dir, "other")}, fs)
+	require.Len(t, devices, 1)
+	require.Equal(t, "/dev/sda", devices[0].Devnode())
+	var errs *multierror.Error
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs.Errors, 2)
+	require.Contains(t, errs.Errors[0].Error(), missing)
+}
+
+func TestDevicesForDirectory(t *testing.T) {
+	dir := t.TempDir()
+	var statfs unix.Statfs_t
+	require.NoError(t, unix.Statfs(dir, &statfs))
+	if fsType := virtualFilesystemType(uint32(statfs.Type)); fsType != "" {
+		t.Skipf("'%s' is on %s", dir, fsType)
+	}
+	var stat unix.Stat_t
+	require.NoError(t, unix.Stat(dir, &stat))
+
+	const sdaPath = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(stat.Dev), unix.Minor(stat.Dev)): "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/sda1",
+		},
+	}
+	writeFakeDevice(fs, sdaPath, "sda", false)
+	writeFakeDevice(fs, sdaPath+"/sda1", "sda1", true)
+
+	// The partition is resolved to its disk.
+	devices, err := DevicesForDirectory(dir, fs)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	require.Equal(t, "/dev/sda", devices[0].Devnode())
+	require.Equal(t, sdaPath, devices[0].Syspath())
+	require.Equal(t, "/dev/sda1", devices[0].Partition().Devnode())
+
+	_, err = DevicesForDirectory(filepath.Join(dir, "missing"), fs)
+	require.Error(t, err)
+}
+
+func TestDeviceResolver_DevicesForDirectory_stacked(t *testing.T) {
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/253:0":       "../../block/dm-0",
+			"/sys/dev/block/9:0":         "../../block/md0",
+			"/sys/block/md0/slaves/sdb1": "../../sdb/sdb1",
+		},
+	}
+	// A striped LVM volume over two disks, and an md array over a disk and
+	// the partition of another.
+	writeFakeStackedDevice(fs, "dm-0", "nvme0n1", "nvme1n1")
+	writeFakeStackedDevice(fs, "nvme0n1")
+	writeFakeStackedDevice(fs, "nvme1n1")
+	writeFakeStackedDevice(fs, "md0", "sda", "sdb1")
+	writeFakeStackedDevice(fs, "sda")
+	writeFakeStackedDevice(fs, "sdb")
+	writeFakeDevice(fs, "/sys/block/sdb/sdb1", "sdb1", true)
+
+	resolver := NewDeviceResolver(fs, "")
+	resolver.statPath = func(path string) (uint64, string, error) {
+		switch path {
+		case "/var/lib/redpanda/data":
+			return unix.Mkdev(253, 0), "", nil
+		case "/var/lib/redpanda/wal":
+			return unix.Mkdev(9, 0), "", nil
+		}
+		return 0, "", os.ErrNotExist
+	}
+	devnodes := func(devices []BlockDevice) []string {
+		var nodes []string
+		for _, device := range devices {
+			nodes = append(nodes, device.Devnode())
+		}
+		return nodes
+	}
+
+	devices, err := resolver.DevicesForDirectory(context.Background(), "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"/dev/nvme0n1", "/dev/nvme1n1"}, devnodes(devices))
+
+	devices, err = resolver.DevicesForDirectory(context.Background(), "/var/lib/redpanda/wal")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"/dev/sda", "/dev/sdb"}, devnodes(devices))
+
+	_, err = resolver.DevicesForDirectory(context.Background(), "/var/lib/redpanda/missing")
+	require.Error(t, err)
+}
+
+func TestDeviceResolver_NewDevice_cache(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/259:0": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1",
+		},
+	}
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	resolver := NewDeviceResolver(fs, "")
+
+	devices := make([]BlockDevice, 8)
+	errs := make([]error, len(devices))
+	var wg sync.WaitGroup
+	for i := range devices {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			devices[i], errs[i] = resolver.NewDevice(context.Background(), unix.Mkdev(259, 0))
+		}(i)
+	}
+	wg.Wait()
+	for i, device := range devices {
+		require.NoError(t, errs[i])
+		require.Same(t, devices[0], device)
+	}
+
+	// Cached devices are reused without reading sysfs again.
+	delete(fs.links, "/sys/dev/block/259:0")
+	device, err := resolver.NewDevice(context.Background(), unix.Mkdev(259, 0))
+	require.NoError(t, err)
+	require.Same(t, devices[0], device)
+
+	resolver.ResetCache()
+	_, err = resolver.NewDevice(context.Background(), unix.Mkdev(259, 0))
+	require.Error(t, err)
+
+	fs.links["/sys/dev/block/259:0"] = "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	resolver.DisableCache()
+	first, err := resolver.NewDevice(context.Background(), unix.Mkdev(259, 0))
+	require.NoError(t, err)
+	second, err := resolver.NewDevice(context.Background(), unix.Mkdev(259, 0))
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+	require.Equal(t, first.Syspath(), second.Syspath())
+}
+
+func Test_blockDevices_directory_spanning_devices(t *testing.T) {
+	const (
+		sdaPath  = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+		nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+		data     = "/var/lib/redpanda/data"
+	)
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/8:0":   "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			"/sys/dev/block/259:0": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1",
+		},
+	}
+	writeFakeDevice(fs, sdaPath, "sda", false)
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	for _, dir := range []string{"kafka", "wal", "cache"} {
+		fs.MkdirAll(filepath.Join(data, dir), 0o755)
+	}
+	afero.WriteFile(fs, filepath.Join(data, "pid.lock"), []byte("1"), 0o644)
+
+	resolver := NewDeviceResolver(fs, "")
+	resolver.statPath = func(path string) (uint64, string, error) {
+		switch path {
+		case data, filepath.Join(data, "kafka"), filepath.Join(data, "pid.lock"):
+			return unix.Mkdev(8, 0), "", nil
+		case filepath.Join(data, "wal"):
+			return unix.Mkdev(259, 0), "", nil
+		case filepath.Join(data, "cache"):
+			return unix.Mkdev(0, 42), "tmpfs", nil
+		}
+		return 0, "", os.ErrNotExist
+	}
+	blockDevices := &blockDevices{fs: fs, resolver: resolver}
+
+	mounts, err := blockDevices.directoryMounts(data)
+	require.NoError(t, err)
+	require.Equal(t, []string{data, filepath.Join(data, "wal")}, mounts)
+
+	devices, err := blockDevices.GetDirectoryDevices(data)
+	require.NoError(t, err)
+	require.Equal(t, []string{"sda", "nvme0n1"}, devices)
+
+	stacks, err := blockDevices.GetDirectoryStacks(context.Background(), data)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"sda"}, {"nvme0n1"}}, stacks)
+}
+
+func Test_blockDevices_directoryMounts_unlistable(t *testing.T) {
+	const data = "/var/lib/redpanda/data"
+	// The directory is stat'ed but can't be listed.
+	fs := afero.NewMemMapFs()
+	resolver := NewDeviceResolver(fs, "")
+	resolver.statPath = func(string) (uint64, string, error) {
+		return unix.Mkdev(8, 0), "", nil
+	}
+	blockDevices := &blockDevices{fs: fs, resolver: resolver}
+
+	mounts, err := blockDevices.directoryMounts(data)
+	require.NoError(t, err)
+	require.Equal(t, []string{data}, mounts)
+}
+
+func TestDeviceResolver_DevicesForPaths_concurrency(t *testing.T) {
+	const devices = 64
+	fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+	var paths, want []string
+	for i := 0; i < devices; i++ {
+		name := fmt.Sprintf("sd%d", i)
+		writeFakeDevice(fs, filepath.Join("/sys/block", name), name, false)
+		fs.links[fmt.Sprintf("/sys/dev/block/8:%d", i)] = "../../block/" + name
+		// Every device holds two paths.
+		paths = append(paths, fmt.Sprintf("/mnt/%s/data", name), fmt.Sprintf("/mnt/%s/wal", name))
+		want = append(want, "/dev/"+name)
+	}
+
+	var inFlight, maxInFlight int32
+	resolver := NewDeviceResolver(fs, "")
+	resolver.Concurrency = 4
+	resolver.statPath = func(path string) (uint64, string, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		var minor uint32
+		if _, err := fmt.Sscanf(path, "/mnt/sd%d/", &minor); err != nil {
+			return 0, "", err
+		}
+		return unix.Mkdev(8, minor), "", nil
+	}
+
+	got, err := resolver.DevicesForPaths(context.Background(), paths)
+	require.NoError(t, err)
+	var devnodes []string
+	for _, device := range got {
+		devnodes = append(devnodes, device.Devnode())
+	}
+	require.Equal(t, want, devnodes)
+	require.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(4))
+	require.Positive(t, atomic.LoadInt32(&maxInFlight))
+
+	// Errors are collected in the order of the paths.
+	resolver.ResetCache()
+	got, err = resolver.DevicesForPaths(context.Background(), []string{"/not/mounted", paths[0], "/other"})
+	require.Len(t, got, 1)
+	var errs *multierror.Error
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs.Errors, 2)
+	require.Contains(t, errs.Errors[0].Error(), "/not/mounted")
+	require.Contains(t, errs.Errors[1].Error(), "/other")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err = resolver.DevicesForPaths(ctx, paths)
+	require.Nil(t, got)
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestDeviceResolver_NewDeviceFromPath_mountInfo(t *testing.T) {
+	const (
+		sdaPath  = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+		nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	)
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	paths := map[string]uint64{
+		"overlay": unix.Mkdev(0, 40),
+		"upper":   unix.Mkdev(8, 0),
+		"btrfs":   unix.Mkdev(0, 50),
+		"nfs":     unix.Mkdev(0, 60),
+		"hidden":  unix.Mkdev(0, 70),
+	}
+	for name := range paths {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0o755))
+	}
+
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/8:0":         "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			"/sys/class/block/nvme0n1p1": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1/nvme0n1p1",
+		},
+	}
+	writeFakeDevice(fs, sdaPath, "sda", false)
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	writeFakeDevice(fs, nvmePath+"/nvme0n1p1", "nvme0n1p1", true)
+	fs.MkdirAll("/sys/class/block/nvme0n1p1", 0o755)
+	mountInfo := fmt.Sprintf(`1 0 0:30 / / rw - overlay overlay rw,upperdir=/var/lib/docker/overlay2/a/diff
+2 1 0:40 / %[1]s/overlay rw - overlay overlay rw,lowerdir=/lower,upperdir=%[1]s/upper/diff,workdir=%[1]s/upper/work
+3 1 8:0 / %[1]s/upper rw - ext4 /dev/sda rw
+4 1 0:50 /subvol %[1]s/btrfs rw - btrfs /dev/nvme0n1p1 rw
+5 1 0:60 / %[1]s/nfs rw - nfs4 10.0.0.1:/export rw
+6 1 0:70 / %[1]s/hidden rw - overlay overlay rw,upperdir=/var/lib/docker/overlay2/b/diff
+`, dir)
+	afero.WriteFile(fs, "/proc/self/mountinfo", []byte(mountInfo), 0o644)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "upper", "diff"), 0o755))
+
+	resolver := NewDeviceResolver(fs, "")
+	resolver.statPath = func(path string) (uint64, string, error) {
+		name, _, _ := strings.Cut(strings.TrimPrefix(path, dir+"/"), "/")
+		dev, ok := paths[name]
+		if !ok {
+			return 0, "", os.ErrNotExist
+		}
+		if name == "overlay" || name == "hidden" {
+			return dev, "overlay", nil
+		}
+		return dev, "", nil
+	}
+
+	device, err := resolver.NewDeviceFromPath(context.Background(), filepath.Join(dir, "overlay"))
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sda", device.Devnode())
+
+	device, err = resolver.NewDeviceFromPath(context.Background(), filepath.Join(dir, "btrfs"))
+	require.NoError(t, err)
+	require.Equal(t, "/dev/nvme0n1", device.Devnode())
+
+	_, err = resolver.NewDeviceFromPath(context.Background(), filepath.Join(dir, "nfs"))
+	require.True(t, errors.Is(err, ErrNotBlockDevice))
+	require.Contains(t, err.Error(), "nfs4 mounted from '10.0.0.1:/export'")
+
+	_, err = resolver.NewDeviceFromPath(context.Background(), filepath.Join(dir, "hidden"))
+	require.True(t, errors.Is(err, ErrNotBlockDevice))
+	require.Contains(t, err.Error(), "upper directory '/var/lib/docker/overlay2/a/diff' is not visible")
+}
@@ -0,0 +1,34 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !linux && !darwin
+
+package disk
+
+import "context"
+
+// NewDevice always fails with ErrUnsupportedPlatform, block devices are
+// only detected through the Linux sysfs.
+func (*DeviceResolver) NewDevice(_ context.Context, _ uint64) (BlockDevice, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// statPath always fails with ErrUnsupportedPlatform, block devices are only
+// detected through the Linux sysfs.
+func statPath(_ string) (dev uint64, virtualFsType string, err error) {
+	return 0, "", ErrUnsupportedPlatform
+}
+
+// deviceFromMountInfo always fails with ErrUnsupportedPlatform, block devices
+// are only detected through the Linux sysfs.
+func (*DeviceResolver) deviceFromMountInfo(
+	_ context.Context, _ string,
+) (BlockDevice, error) {
+	return nil, ErrUnsupportedPlatform
+}
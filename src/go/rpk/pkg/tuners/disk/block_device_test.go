@@ -10,6 +10,9 @@
 package disk
 
 import (
+	"context"
+	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -56,6 +59,11 @@ func Test_deviceFromSystemPath(t *testing.T) {
 			want: &blockDevice{
 				syspath: "/sys/devices/pci0000:00/0000:00:1d.0/nvme/nvme0/nvme0n1",
 				devnode: "/dev/child",
+				nvme: &NvmeNamespace{
+					Controller:     "nvme0",
+					ControllerPath: "/sys/devices/pci0000:00/0000:00:1d.0/nvme/nvme0",
+					ID:             1,
+				},
 				parent: &blockDevice{
 					syspath: "/sys/devices/pci0000:00/0000:00:1d.0/nvme/nvme0",
 					devnode: "/dev/parent",
@@ -68,9 +76,264 @@ func Test_deviceFromSystemPath(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			fs := afero.NewMemMapFs()
 			tt.before(fs, tt.syspath)
-			got, err := deviceFromSystemPath(tt.syspath, fs)
+			resolver := NewDeviceResolver(fs, "")
+			got, err := resolver.deviceFromSystemPath(context.Background(), tt.syspath)
 			require.NoError(t, err)
+			for d, ok := tt.want.(*blockDevice); ok; d, ok = d.parent.(*blockDevice) {
+				d.resolver = resolver
+			}
 			require.Exactly(t, tt.want, got)
 		})
 	}
 }
+
+func Test_deviceFromSystemPath_cancelled(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	fs := afero.NewMemMapFs()
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewDeviceResolver(fs, "").deviceFromSystemPath(ctx, nvmePath)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Contains(t, err.Error(), nvmePath)
+}
+
+func Test_deviceFromSystemPath_partitions(t *testing.T) {
+	const (
+		sdaPath  = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+		nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	)
+	tests := []struct {
+		name          string
+		syspath       string
+		before        func(afero.Fs)
+		wantDisk      string
+		wantPartition string
+	}{
+		{
+			name:    "shall resolve a SCSI partition to its disk",
+			syspath: sdaPath + "/sda1",
+			before: func(fs afero.Fs) {
+				writeFakeDevice(fs, sdaPath, "sda", false)
+				writeFakeDevice(fs, sdaPath+"/sda1", "sda1", true)
+			},
+			wantDisk:      "/dev/sda",
+			wantPartition: "/dev/sda1",
+		},
+		{
+			name:    "shall resolve an NVMe partition to its namespace",
+			syspath: nvmePath + "/nvme0n1p1",
+			before: func(fs afero.Fs) {
+				writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+				writeFakeDevice(fs, nvmePath+"/nvme0n1p1", "nvme0n1p1", true)
+			},
+			wantDisk:      "/dev/nvme0n1",
+			wantPartition: "/dev/nvme0n1p1",
+		},
+		{
+			name:    "shall infer the disk from the partition name",
+			syspath: "/sys/class/block/nvme0n1p2",
+			before: func(fs afero.Fs) {
+				writeFakeDevice(fs, "/sys/class/block/nvme0n1", "nvme0n1", false)
+				writeFakeDevice(fs, "/sys/class/block/nvme0n1p2", "nvme0n1p2", true)
+			},
+			wantDisk:      "/dev/nvme0n1",
+			wantPartition: "/dev/nvme0n1p2",
+		},
+		{
+			name:    "shall return the disk itself",
+			syspath: sdaPath,
+			before: func(fs afero.Fs) {
+				writeFakeDevice(fs, sdaPath, "sda", false)
+			},
+			wantDisk: "/dev/sda",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			got, err := NewDeviceResolver(fs, "").deviceFromSystemPath(context.Background(), tt.syspath)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantDisk, got.Devnode())
+			if tt.wantPartition == "" {
+				require.Nil(t, got.Partition())
+				return
+			}
+			require.NotNil(t, got.Partition())
+			require.Equal(t, tt.wantPartition, got.Partition().Devnode())
+		})
+	}
+}
+
+func TestDeviceResolver_DeviceFromName(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/class/block/nvme0n1": "../../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1",
+		},
+	}
+	// MemMapFs doesn't follow links, the link itself must exist.
+	fs.MkdirAll("/sys/class/block/nvme0n1", 0o755)
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	resolver := NewDeviceResolver(fs, "")
+
+	device, err := resolver.DeviceFromName(context.Background(), "nvme0n1")
+	require.NoError(t, err)
+	require.Equal(t, nvmePath, device.Syspath())
+	require.Equal(t, "/dev/nvme0n1", device.Devnode())
+
+	_, err = resolver.DeviceFromName(context.Background(), "nvme1n1")
+	require.Error(t, err)
+}
+
+func TestNewDeviceFromName(t *testing.T) {
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/block/nvme0n1":  "../devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1",
+			"/dev/mapper/vg-data": "../dm-0",
+		},
+	}
+	fs.MkdirAll("/sys/block/nvme0n1", 0o755)
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	writeFakeDevice(fs, nvmePath+"/nvme0n1p1", "nvme0n1p1", true)
+	writeFakeStackedDevice(fs, "sda")
+	writeFakeStackedDevice(fs, "dm-0", "nvme0n1p1")
+	fs.MkdirAll("/dev/mapper/vg-data", 0o755)
+
+	tests := []struct {
+		name          string
+		wantSyspath   string
+		wantPartition string
+	}{
+		{name: "/dev/sda", wantSyspath: "/sys/block/sda"},
+		{name: "sda", wantSyspath: "/sys/block/sda"},
+		{name: "/dev/nvme0n1", wantSyspath: nvmePath},
+		{name: "/dev/nvme0n1p1", wantSyspath: nvmePath, wantPartition: "/dev/nvme0n1p1"},
+		{name: "nvme0n1p1", wantSyspath: nvmePath, wantPartition: "/dev/nvme0n1p1"},
+		{name: "/dev/mapper/vg-data", wantSyspath: "/sys/block/dm-0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device, err := NewDeviceFromName(tt.name, fs)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSyspath, device.Syspath())
+			if tt.wantPartition == "" {
+				require.Nil(t, device.Partition())
+				return
+			}
+			require.NotNil(t, device.Partition())
+			require.Equal(t, tt.wantPartition, device.Partition().Devnode())
+		})
+	}
+
+	for _, name := range []string{"sdb", "/dev/sdb", "/dev/nvme0n1p2", "", "../block/sda"} {
+		_, err := NewDeviceFromName(name, fs)
+		require.ErrorIs(t, err, ErrDeviceNotFound, name)
+		var notFound *DeviceNotFoundError
+		require.ErrorAs(t, err, &notFound, name)
+	}
+}
+
+func TestParseDeviceNumbers(t *testing.T) {
+	major, minor, err := ParseDeviceNumbers("259:0")
+	require.NoError(t, err)
+	require.Equal(t, uint32(259), major)
+	require.Equal(t, uint32(0), minor)
+
+	major, minor, err = ParseDeviceNumbers("8:16\n")
+	require.NoError(t, err)
+	require.Equal(t, uint32(8), major)
+	require.Equal(t, uint32(16), minor)
+
+	for _, numbers := range []string{"", "259", "259:", ":0", "nvme0n1", "259:-1", "4294967296:0"} {
+		_, _, err := ParseDeviceNumbers(numbers)
+		require.Error(t, err, numbers)
+	}
+}
+
+func Test_parentDiskName(t *testing.T) {
+	for partition, disk := range map[string]string{
+		"sda1":        "sda",
+		"sdab12":      "sdab",
+		"xvdf3":       "xvdf",
+		"nvme0n1p1":   "nvme0n1",
+		"nvme10n2p15": "nvme10n2",
+		"mmcblk0p2":   "mmcblk0",
+	} {
+		require.Equal(t, disk, parentDiskName(partition), partition)
+	}
+}
+
+func Test_attributePath(t *testing.T) {
+	const sdaPath = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+	tests := []struct {
+		name      string
+		syspath   string
+		attribute string
+		want      string
+	}{
+		{
+			name:      "shall return disk attributes of a disk",
+			syspath:   sdaPath,
+			attribute: "queue/scheduler",
+			want:      sdaPath + "/queue/scheduler",
+		},
+		{
+			name:      "shall return partition scoped attributes of a disk",
+			syspath:   sdaPath,
+			attribute: "size",
+			want:      sdaPath + "/size",
+		},
+		{
+			name:      "shall return disk attributes of a partition from its disk",
+			syspath:   sdaPath + "/sda3",
+			attribute: "queue/nr_requests",
+			want:      sdaPath + "/queue/nr_requests",
+		},
+		{
+			name:      "shall return partition scoped attributes from the partition",
+			syspath:   sdaPath + "/sda3",
+			attribute: "start",
+			want:      sdaPath + "/sda3/start",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeFakeDevice(fs, sdaPath, "sda", false)
+			writeFakeDevice(fs, sdaPath+"/sda3", "sda3", true)
+			got, err := attributePath(tt.syspath, tt.attribute, fs)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// linkFs is an in-memory filesystem with read only symbolic links, which
+// afero.MemMapFs doesn't model.
+type linkFs struct {
+	afero.Fs
+	links map[string]string
+}
+
+func (l *linkFs) ReadlinkIfPossible(name string) (string, error) {
+	target, ok := l.links[name]
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: os.ErrNotExist}
+	}
+	return target, nil
+}
+
+func writeFakeDevice(fs afero.Fs, syspath, name string, partition bool) {
+	fs.MkdirAll(syspath, 0o755)
+	utils.WriteFileLines(fs, []string{"DEVNAME=" + name}, filepath.Join(syspath, "uevent"))
+	if partition {
+		afero.WriteFile(fs, filepath.Join(syspath, "partition"), []byte("1\n"), 0o644)
+		return
+	}
+	fs.MkdirAll(filepath.Join(syspath, "queue"), 0o755)
+}
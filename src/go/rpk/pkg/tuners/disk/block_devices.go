@@ -10,8 +10,11 @@
 package disk
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -40,17 +43,28 @@ type DevicesIRQs struct {
 type BlockDevices interface {
 	GetDirectoriesDevices(directories []string) (map[string][]string, error)
 	GetDirectoryDevices(directory string) ([]string, error)
+	// GetDirectoryStacks returns, for each device holding the directory or
+	// one of its top-level subdirectories, the device followed by the
+	// physical devices it's stacked on, if any, e.g. the members of an md
+	// array.
+	GetDirectoryStacks(ctx context.Context, directory string) ([][]string, error)
 	GetDeviceFromPath(path string) (BlockDevice, error)
 	GetDeviceSystemPath(devicePath string) (string, error)
 	GetDiskInfoByType(devices []string) (map[DiskType]DevicesIRQs, error)
+	// GetDeviceNumaNode returns the NUMA node the device is local to, or -1
+	// if it's unknown.
+	GetDeviceNumaNode(device string) (int, error)
 }
 
+// blockDevices resolves its devices with a background context, as the
+// BlockDevices methods, but GetDirectoryStacks, don't take one yet.
 type blockDevices struct {
 	proc          os.Proc
 	fs            afero.Fs
 	irqDeviceInfo irq.DeviceInfo
 	irqProcFile   irq.ProcFile
 	timeout       time.Duration
+	resolver      *DeviceResolver
 }
 
 func NewBlockDevices(
@@ -59,6 +73,7 @@ func NewBlockDevices(
 	irqProcFile irq.ProcFile,
 	proc os.Proc,
 	timeout time.Duration,
+	sysfsRoot string,
 ) BlockDevices {
 	return &blockDevices{
 		fs:            fs,
@@ -66,6 +81,7 @@ func NewBlockDevices(
 		irqDeviceInfo: irqDeviceInfo,
 		irqProcFile:   irqProcFile,
 		timeout:       timeout,
+		resolver:      NewDeviceResolver(fs, sysfsRoot),
 	}
 }
 
@@ -92,12 +108,84 @@ func (b *blockDevices) GetDirectoryDevices(path string) ([]string, error) {
 		// path/to/whatever does not exist
 		return []string{}, nil
 	}
-	device, err := b.getBlockDeviceFromPath(path, getDevNumFromDirectory)
+	mounts, err := b.directoryMounts(path)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		devices []string
+		mapping []string
+		seen    = map[string]bool{}
+	)
+	for _, mount := range mounts {
+		mountDevices, err := b.pathDevices(mount)
+		if err != nil {
+			return nil, err
+		}
+		mapping = append(mapping, fmt.Sprintf("'%s' -> %s", mount, strings.Join(mountDevices, ", ")))
+		for _, device := range mountDevices {
+			if !seen[device] {
+				seen[device] = true
+				devices = append(devices, device)
+			}
+		}
+	}
+	if len(mounts) > 1 {
+		log.Warnf("Directory '%s' spans several devices, all of them will be tuned: %s",
+			path, strings.Join(mapping, "; "))
+	}
+	return devices, nil
+}
+
+// directoryMounts returns the directory followed by its top-level
+// subdirectories stored on another device, e.g. a disk mounted for the
+// write-ahead log, so that every device holding the directory data is
+// tuned. Subdirectories on filesystems without a block device are skipped,
+// as are all of them if the directory can't be listed.
+func (b *blockDevices) directoryMounts(path string) ([]string, error) {
+	dev, _, err := b.resolver.statPath(path)
+	if err != nil {
+		return nil, err
+	}
+	mounts := []string{path}
+	seen := map[uint64]bool{dev: true}
+	entries, err := afero.ReadDir(b.fs, path)
+	if err != nil {
+		log.Debugf("Unable to list the subdirectories of '%s', only its own device is used: %v", path, err)
+		return mounts, nil
+	}
+	for _, entry := range entries {
+		subdir := filepath.Join(path, entry.Name())
+		// Stat follows the links to directories.
+		if info, err := b.fs.Stat(subdir); err != nil || !info.IsDir() {
+			continue
+		}
+		subdev, fsType, err := b.resolver.statPath(subdir)
+		if err != nil {
+			log.Debugf("Skipping '%s': %v", subdir, err)
+			continue
+		}
+		if fsType != "" {
+			log.Warnf("Skipping '%s' as it's on %s, which has no block device", subdir, fsType)
+			continue
+		}
+		if seen[subdev] {
+			continue
+		}
+		seen[subdev] = true
+		mounts = append(mounts, subdir)
+	}
+	return mounts, nil
+}
+
+// pathDevices returns the physical devices holding the path.
+func (b *blockDevices) pathDevices(path string) ([]string, error) {
+	device, err := b.resolver.NewDeviceFromPath(context.Background(), path)
 	if err != nil {
 		return nil, err
 	}
 	if device != nil {
-		return b.getPhysDevices(device)
+		return b.getPhysDevices(context.Background(), device)
 	}
 
 	var devices []string
@@ -124,42 +212,77 @@ func (b *blockDevices) GetDirectoryDevices(path string) ([]string, error) {
 	return []string{}, nil
 }
 
-func (b *blockDevices) getPhysDevices(device BlockDevice) ([]string, error) {
-	log.Debugf("Getting physical device from '%s'", device.Syspath())
-	if strings.Contains(device.Syspath(), "virtual") {
-		joinedPath := path.Join(device.Syspath(), "slaves")
-		files, err := afero.ReadDir(b.fs, joinedPath)
+func (b *blockDevices) GetDirectoryStacks(
+	ctx context.Context, path string,
+) ([][]string, error) {
+	log.Debugf("Collecting the device stacks of directory '%s'", path)
+	if exists, _ := afero.Exists(b.fs, path); !exists {
+		return nil, nil
+	}
+	mounts, err := b.directoryMounts(path)
+	if err != nil {
+		return nil, err
+	}
+	var stacks [][]string
+	for _, mount := range mounts {
+		device, err := b.resolver.NewDeviceFromPath(ctx, mount)
 		if err != nil {
 			return nil, err
 		}
-		var physDevices []string
-		for _, deviceDirectory := range files {
-			slavePath := "/dev/" + deviceDirectory.Name()
-			log.Debugf("Dealing with virtual device, checking slave %s", slavePath)
-			deviceFromPath, err := b.GetDeviceFromPath(slavePath)
-			if err != nil {
-				return nil, err
-			}
-			devices, err := b.getPhysDevices(deviceFromPath)
-			if err != nil {
-				return nil, err
+		physDevices, err := b.getPhysDevices(ctx, device)
+		if err != nil {
+			return nil, err
+		}
+		top := deviceName(device)
+		stack := []string{top}
+		for _, physDevice := range physDevices {
+			if physDevice != top {
+				stack = append(stack, physDevice)
 			}
-			physDevices = append(physDevices, devices...)
 		}
-		return physDevices, nil
+		stacks = append(stacks, stack)
 	}
-	return []string{strings.Replace(device.Devnode(),
-		"/dev/", "", 1)}, nil
+	return stacks, nil
+}
+
+func (b *blockDevices) getPhysDevices(
+	ctx context.Context, device BlockDevice,
+) ([]string, error) {
+	physDevices, err := b.resolver.resolvePhysicalDevices(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, physDevice := range physDevices {
+		// The disk tuners act on sysfs, devices identified without it
+		// can't be tuned.
+		if d, ok := physDevice.(*identifiedDevice); ok {
+			return nil, d.unavailable()
+		}
+		names = append(names, deviceName(physDevice))
+	}
+	return names, nil
 }
 
 func (b *blockDevices) GetDeviceFromPath(path string) (BlockDevice, error) {
-	return b.getBlockDeviceFromPath(path,
+	device, err := b.getBlockDeviceFromPath(path,
 		getDevNumFromDeviceDirectory)
+	if err == nil || errors.Is(err, ErrUnsupportedPlatform) ||
+		!strings.HasPrefix(path, "/dev/") {
+		return device, err
+	}
+	// Some devices, e.g. those of multipath setups, can't be resolved
+	// from their device number, we look them up by name instead.
+	byName, nameErr := b.resolver.DeviceFromName(context.Background(), filepath.Base(path))
+	if nameErr != nil {
+		log.Debugf("Unable to look up '%s' by name: %v", path, nameErr)
+		return nil, err
+	}
+	return byName, nil
 }
 
 func (b *blockDevices) GetDeviceSystemPath(path string) (string, error) {
-	device, err := b.getBlockDeviceFromPath(path,
-		getDevNumFromDeviceDirectory)
+	device, err := b.GetDeviceFromPath(path)
 	if err != nil {
 		return "", err
 	}
@@ -176,7 +299,7 @@ func (b *blockDevices) getBlockDeviceFromPath(
 		return nil, err
 	}
 	devNumber := devNumExtractor(stat)
-	return NewDevice(devNumber, b.fs)
+	return b.resolver.NewDevice(context.Background(), devNumber)
 }
 
 func (b *blockDevices) getDevicesIRQs(
@@ -189,10 +312,21 @@ func (b *blockDevices) getDevicesIRQs(
 		}
 		log.Debugf("Getting '%s' IRQs", device)
 		devicePath := path.Join("/dev", device)
-		devSystemPath, err := b.GetDeviceSystemPath(devicePath)
+		blockDevice, err := b.GetDeviceFromPath(devicePath)
 		if err != nil {
 			return nil, err
 		}
+		devSystemPath := blockDevice.Syspath()
+		// All the namespaces of an NVMe controller share the controller
+		// IRQs. Namespaces of multipath capable controllers live under the
+		// virtual nvme-subsystem hierarchy, so we use the controller path
+		// to find the PCI device instead.
+		if nvme := blockDevice.Nvme(); nvme != nil &&
+			strings.HasPrefix(nvme.ControllerPath, b.resolver.path("devices")+"/") {
+			log.Debugf("'%s' is namespace %d of NVMe controller '%s'",
+				device, nvme.ID, nvme.Controller)
+			devSystemPath = nvme.ControllerPath
+		}
 		controllerPath, err := b.getDeviceControllerPath(devSystemPath)
 		if err != nil {
 			return nil, err
@@ -207,15 +341,49 @@ func (b *blockDevices) getDevicesIRQs(
 	return diskIRQs, nil
 }
 
-func (*blockDevices) getDeviceControllerPath(
+// GetDeviceNumaNode returns the NUMA node of the device, as reported in the
+// 'numa_node' of its device, e.g. its PCI function, or of the device of its
+// NVMe controller, for the namespaces of multipath capable controllers. It
+// returns -1 if neither reports one, e.g. for virtual devices, as does the
+// kernel on single node machines.
+func (b *blockDevices) GetDeviceNumaNode(device string) (int, error) {
+	blockDevice, err := b.GetDeviceFromPath(path.Join("/dev", device))
+	if err != nil {
+		return -1, err
+	}
+	files := []string{filepath.Join(blockDevice.Syspath(), "device", "numa_node")}
+	if nvme := blockDevice.Nvme(); nvme != nil && nvme.ControllerPath != "" {
+		files = append(files, filepath.Join(nvme.ControllerPath, "device", "numa_node"))
+	}
+	for _, file := range files {
+		if exists, _ := afero.Exists(b.fs, file); !exists {
+			continue
+		}
+		content, err := readSysfsString(b.fs, file)
+		if err != nil {
+			return -1, err
+		}
+		node, err := strconv.Atoi(content)
+		if err != nil {
+			return -1, fmt.Errorf("unexpected NUMA node '%s' in '%s'", content, file)
+		}
+		log.Debugf("'%s' is on NUMA node %d", device, node)
+		return node, nil
+	}
+	return -1, nil
+}
+
+func (b *blockDevices) getDeviceControllerPath(
 	devSystemPath string,
 ) (string, error) {
 	log.Debugf("Getting controller path for '%s'", devSystemPath)
-	splitSystemPath := strings.Split(devSystemPath, "/")
-	controllerPathParts := append([]string{"/"}, splitSystemPath[0:4]...)
+	devicesPath := b.resolver.path("devices")
+	splitSystemPath := strings.Split(
+		strings.TrimPrefix(devSystemPath, devicesPath+"/"), "/")
+	controllerPathParts := []string{devicesPath, splitSystemPath[0]}
 	pattern, _ := regexp.Compile(
 		`^[0-9ABCDEFabcdef]{4}:[0-9ABCDEFabcdef]{2}:[0-9ABCDEFabcdef]{2}\.[0-9ABCDEFabcdef]$`)
-	for _, systemPathPart := range splitSystemPath[4:] {
+	for _, systemPathPart := range splitSystemPath[1:] {
 		controllerPathParts = append(controllerPathParts, systemPathPart)
 		if pattern.MatchString(systemPathPart) {
 			break
@@ -1,4 +1,4 @@
-// Copyright 2020 Redpanda Data, Inc.
+// Copyright 2023 Redpanda Data, Inc.
 //
 // Use of this software is governed by the Business Source License
 // included in the file licenses/BSL.md
@@ -7,14 +7,12 @@
 // the Business Source License, use of this software will be governed
 // by the Apache License, Version 2.0
 
-package filesystem
+//go:build !linux && !darwin && !windows
 
-type FsType string
+package disk
 
-const (
-	Xfs     FsType = "xfs"
-	Ext     FsType = "ext"
-	Tmpfs   FsType = "tmpfs"
-	Hfs     FsType = "hfs"
-	Unknown FsType = "unknown"
-)
+import "syscall"
+
+func getDevNumFromDeviceDirectory(stat syscall.Stat_t) uint64 {
+	return uint64(stat.Rdev)
+}
@@ -10,6 +10,7 @@
 package disk
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -47,6 +48,7 @@ func Test_blockDevices_getDeviceControllerPath(t *testing.T) {
 		irqDeviceInfo: irqDeviceInfo,
 		irqProcFile:   irqProcFile,
 		proc:          proc,
+		resolver:      NewDeviceResolver(fs, ""),
 	}
 	devSystemPath := "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0" +
 		"/target0:0:0/0:0:0:0/block/sda/sda1"
@@ -68,6 +70,16 @@ func Test_blockDevices_getDeviceControllerPath(t *testing.T) {
 	// then
 	require.Nil(t, err)
 	require.Equal(t, expected, controllerPath)
+
+	// given
+	blockDevices.resolver = NewDeviceResolver(fs, "/host/sys")
+	devSystemPath = "/host/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0" +
+		"/target0:0:0/0:0:0:0/block/sda"
+	// when
+	controllerPath, err = blockDevices.getDeviceControllerPath(devSystemPath)
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "/host/sys/devices/pci0000:00/0000:00:1f.2", controllerPath)
 }
 
 func Test_blockDevices_isIRQNvmeFastPathIRQ(t *testing.T) {
@@ -139,3 +151,42 @@ func Test_blockDevices_isIRQNvmeFastPathIRQ(t *testing.T) {
 		})
 	}
 }
+
+func Test_blockDevices_GetDeviceNumaNode(t *testing.T) {
+	const (
+		nvmePath = "/sys/devices/pci0000:80/0000:80:01.0/0000:81:00.0/nvme/nvme0/nvme0n1"
+		sdaPath  = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+		vdaPath  = "/sys/devices/virtual/block/vda"
+	)
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/class/block/nvme0n1": "../../devices/pci0000:80/0000:80:01.0/0000:81:00.0/nvme/nvme0/nvme0n1",
+			"/sys/class/block/sda":     "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			"/sys/class/block/vda":     "../../devices/virtual/block/vda",
+		},
+	}
+	for _, name := range []string{"nvme0n1", "sda", "vda"} {
+		fs.MkdirAll("/sys/class/block/"+name, 0o755)
+	}
+	writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+	writeFakeDevice(fs, sdaPath, "sda", false)
+	writeFakeDevice(fs, vdaPath, "vda", false)
+	// The NVMe controller is on the second socket, the SATA disk reports
+	// an unknown node.
+	afero.WriteFile(fs, filepath.Join(nvmePath, "device", "numa_node"), []byte("1\n"), 0o644)
+	afero.WriteFile(fs, filepath.Join(sdaPath, "device", "numa_node"), []byte("-1\n"), 0o644)
+	blockDevices := &blockDevices{fs: fs, resolver: NewDeviceResolver(fs, "")}
+
+	for device, expNode := range map[string]int{"nvme0n1": 1, "sda": -1, "vda": -1} {
+		node, err := blockDevices.GetDeviceNumaNode(device)
+		require.NoError(t, err, device)
+		require.Equal(t, expNode, node, device)
+	}
+
+	afero.WriteFile(fs, filepath.Join(sdaPath, "device", "numa_node"), []byte("x\n"), 0o644)
+	_, err := blockDevices.GetDeviceNumaNode("sda")
+	require.Error(t, err)
+	_, err = blockDevices.GetDeviceNumaNode("sdb")
+	require.Error(t, err)
+}
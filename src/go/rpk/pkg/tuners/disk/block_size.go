@@ -0,0 +1,87 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// sysfsSectorSize is the unit of the sectors sysfs reports, e.g. the 'start'
+// of partitions, whatever the block size of the device.
+const sysfsSectorSize = 512
+
+// LogicalBlockSize returns the smallest size in bytes the device can address,
+// as read from its 'queue/logical_block_size' attribute, or 0 if the device
+// doesn't expose it.
+func (d *blockDevice) LogicalBlockSize() (uint64, error) {
+	if d.resolver == nil {
+		return 0, nil
+	}
+	return d.readQueueUint("logical_block_size")
+}
+
+// PhysicalBlockSize returns the smallest size in bytes the device can write
+// without a read-modify-write cycle, as read from its
+// 'queue/physical_block_size' attribute, or 0 if the device doesn't expose it.
+// It's larger than the logical block size on 512e drives, which emulate 512
+// bytes sectors on top of 4KiB ones.
+func (d *blockDevice) PhysicalBlockSize() (uint64, error) {
+	if d.resolver == nil {
+		return 0, nil
+	}
+	return d.readQueueUint("physical_block_size")
+}
+
+// PartitionAlignment is the alignment of the start of a partition to the
+// physical blocks of the disk holding it. Writes to misaligned partitions
+// straddle physical blocks, which the drive has to read, modify and write.
+type PartitionAlignment struct {
+	Partition string
+	// Offset is the start of the partition, in bytes.
+	Offset            uint64
+	PhysicalBlockSize uint64
+	// Aligned is set if the offset is a multiple of the physical block
+	// size.
+	Aligned bool
+}
+
+// PartitionAlignment returns the alignment of the partition the device was
+// resolved from, or nil if it wasn't resolved from a partition. Disks not
+// exposing their physical block size are assumed to have 512 bytes sectors.
+func (d *blockDevice) PartitionAlignment() (*PartitionAlignment, error) {
+	if d.partition == nil || d.resolver == nil {
+		return nil, nil
+	}
+	startPath := filepath.Join(d.partition.Syspath(), "start")
+	value, err := readIdentityAttribute(d.resolver.fs, startPath)
+	if err != nil {
+		return nil, err
+	}
+	start, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse '%s': %v", startPath, err)
+	}
+	blockSize, err := d.PhysicalBlockSize()
+	if err != nil {
+		return nil, err
+	}
+	if blockSize == 0 {
+		blockSize = sysfsSectorSize
+	}
+	offset := start * sysfsSectorSize
+	return &PartitionAlignment{
+		Partition:         deviceName(d.partition),
+		Offset:            offset,
+		PhysicalBlockSize: blockSize,
+		Aligned:           offset%blockSize == 0,
+	}, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockDevice_PartitionAlignment(t *testing.T) {
+	const sdaPath = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"
+	writeDisk := func(fs afero.Fs, logical, physical string) {
+		writeFakeDevice(fs, sdaPath, "sda", false)
+		queue := filepath.Join(sdaPath, "queue")
+		if logical != "" {
+			afero.WriteFile(fs, filepath.Join(queue, "logical_block_size"), []byte(logical+"\n"), 0o644)
+		}
+		if physical != "" {
+			afero.WriteFile(fs, filepath.Join(queue, "physical_block_size"), []byte(physical+"\n"), 0o644)
+		}
+	}
+	writePartition := func(fs afero.Fs, start string) {
+		syspath := filepath.Join(sdaPath, "sda1")
+		writeFakeDevice(fs, syspath, "sda1", true)
+		afero.WriteFile(fs, filepath.Join(syspath, "start"), []byte(start+"\n"), 0o644)
+	}
+	tests := []struct {
+		name         string
+		syspath      string
+		before       func(afero.Fs)
+		wantLogical  uint64
+		wantPhysical uint64
+		want         *PartitionAlignment
+		wantErr      bool
+	}{
+		{
+			name:    "shall flag a misaligned partition of a 4Kn disk",
+			syspath: sdaPath + "/sda1",
+			before: func(fs afero.Fs) {
+				writeDisk(fs, "4096", "4096")
+				writePartition(fs, "63")
+			},
+			wantLogical:  4096,
+			wantPhysical: 4096,
+			want: &PartitionAlignment{
+				Partition:         "sda1",
+				Offset:            32256,
+				PhysicalBlockSize: 4096,
+			},
+		},
+		{
+			name:    "shall pass an aligned partition of a 4Kn disk",
+			syspath: sdaPath + "/sda1",
+			before: func(fs afero.Fs) {
+				writeDisk(fs, "4096", "4096")
+				writePartition(fs, "2048")
+			},
+			wantLogical:  4096,
+			wantPhysical: 4096,
+			want: &PartitionAlignment{
+				Partition:         "sda1",
+				Offset:            1048576,
+				PhysicalBlockSize: 4096,
+				Aligned:           true,
+			},
+		},
+		{
+			name:    "shall flag a misaligned partition of a 512e disk",
+			syspath: sdaPath + "/sda1",
+			before: func(fs afero.Fs) {
+				writeDisk(fs, "512", "4096")
+				writePartition(fs, "63")
+			},
+			wantLogical:  512,
+			wantPhysical: 4096,
+			want: &PartitionAlignment{
+				Partition:         "sda1",
+				Offset:            32256,
+				PhysicalBlockSize: 4096,
+			},
+		},
+		{
+			name:    "shall assume 512 bytes sectors if the disk doesn't expose them",
+			syspath: sdaPath + "/sda1",
+			before: func(fs afero.Fs) {
+				writeDisk(fs, "", "")
+				writePartition(fs, "63")
+			},
+			want: &PartitionAlignment{
+				Partition:         "sda1",
+				Offset:            32256,
+				PhysicalBlockSize: 512,
+				Aligned:           true,
+			},
+		},
+		{
+			name:    "shall return no alignment for whole disks",
+			syspath: sdaPath,
+			before: func(fs afero.Fs) {
+				writeDisk(fs, "512", "512")
+			},
+			wantLogical:  512,
+			wantPhysical: 512,
+		},
+		{
+			name:    "shall fail on invalid partition starts",
+			syspath: sdaPath + "/sda1",
+			before: func(fs afero.Fs) {
+				writeDisk(fs, "4096", "4096")
+				writePartition(fs, "-1")
+			},
+			wantLogical:  4096,
+			wantPhysical: 4096,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), tt.syspath)
+			require.NoError(t, err)
+			logical, err := device.LogicalBlockSize()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLogical, logical)
+			physical, err := device.PhysicalBlockSize()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPhysical, physical)
+			got, err := device.PartitionAlignment()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// The kinds of layered cache devices.
+const (
+	CacheKindBcache       = "bcache"
+	CacheKindDmCache      = "dm-cache"
+	CacheKindDmWritecache = "dm-writecache"
+)
+
+// The roles of the physical devices resolved through a cache device, see
+// BlockDevice.CacheRole.
+const (
+	// CacheRoleCache is a fast device, usually an SSD, caching the backing
+	// devices.
+	CacheRoleCache = "cache"
+	// CacheRoleBacking is a slow device, usually an HDD, holding the data.
+	CacheRoleBacking = "backing"
+)
+
+// CacheDevice describes a device fronting slow backing devices with fast
+// caching ones: a bcache device or an LVM cache volume (dm-cache or
+// dm-writecache).
+type CacheDevice struct {
+	// Kind is one of 'bcache', 'dm-cache' or 'dm-writecache'.
+	Kind string
+	// Mode is the caching mode, e.g. 'writeback', empty if not exposed,
+	// which is the case of the device-mapper caches.
+	Mode string
+	// State is the bcache state, e.g. 'clean', 'dirty' or 'no cache' if no
+	// cache set is attached.
+	State string
+	// Backing and Cache are the names of the backing and caching devices.
+	// Cache is empty if the cache is missing, in which case the cache device
+	// only passes the I/O through to its backing device.
+	Backing []string
+	Cache   []string
+	// cachePaths are the system paths of the caching devices of bcache
+	// devices, which are not slaves of them.
+	cachePaths []string
+}
+
+var (
+	bcacheName      = regexp.MustCompile(`^bcache\d+$`)
+	bcacheCacheName = regexp.MustCompile(`^cache\d+$`)
+)
+
+// cacheDeviceFromSystemPath returns the layered cache details of the device
+// at syspath, or nil if the device is not a cache device.
+func (r *DeviceResolver) cacheDeviceFromSystemPath(syspath string, slaves []string) *CacheDevice {
+	if len(slaves) == 0 {
+		return nil
+	}
+	if bcacheName.MatchString(filepath.Base(syspath)) {
+		return r.readBcache(syspath, slaves)
+	}
+	if deviceMapperName(syspath, r.fs) != "" {
+		return r.readDmCache(syspath, slaves)
+	}
+	return nil
+}
+
+// readBcache reads the bcache device at syspath, whose 'bcache' directory
+// links to the one of its backing device. Its caching devices are those of
+// the cache set the 'cache' link of that directory points to, e.g.
+// '/sys/fs/bcache/<uuid>', which links to their own 'bcache' directory as
+// 'cache0', 'cache1'...
+func (r *DeviceResolver) readBcache(syspath string, slaves []string) *CacheDevice {
+	bcacheDir := resolveLink(r.fs, filepath.Join(syspath, "bcache"))
+	cache := &CacheDevice{Kind: CacheKindBcache, Backing: slaves}
+	if modeFile := filepath.Join(bcacheDir, "cache_mode"); exists(r.fs, modeFile) {
+		// e.g. 'writethrough [writeback] writearound none'.
+		if mode, _, err := ReadSysfsBracketed(r.fs, modeFile); err == nil {
+			cache.Mode = mode
+		}
+	}
+	if state, err := readIdentityAttribute(r.fs, filepath.Join(bcacheDir, "state")); err == nil {
+		cache.State = state
+	}
+	setLink := filepath.Join(bcacheDir, "cache")
+	setDir := resolveLink(r.fs, setLink)
+	if setDir == setLink {
+		log.Debugf("bcache device '%s' has no cache set attached", filepath.Base(syspath))
+		return cache
+	}
+	entries, err := afero.ReadDir(r.fs, setDir)
+	if err != nil {
+		log.Debugf("Unable to read the cache set of '%s': %v", filepath.Base(syspath), err)
+		return cache
+	}
+	for _, entry := range entries {
+		if !bcacheCacheName.MatchString(entry.Name()) {
+			continue
+		}
+		link := filepath.Join(setDir, entry.Name())
+		if target := resolveLink(r.fs, link); target != link {
+			cachePath := filepath.Dir(target)
+			cache.Cache = append(cache.Cache, filepath.Base(cachePath))
+			cache.cachePaths = append(cache.cachePaths, cachePath)
+		}
+	}
+	return cache
+}
+
+// readDmCache identifies the LVM cache volumes at syspath from the names of
+// their slaves: the origin volume ('<vg>-<lv>_corig', '_wcorig' for
+// writecache) is the backing device, the others are the cache pool data and
+// metadata ('_cdata', '_cmeta') or the cache volume ('_cvol'). The cache
+// targets created with dmsetup alone can't be identified from sysfs.
+func (r *DeviceResolver) readDmCache(syspath string, slaves []string) *CacheDevice {
+	cache := &CacheDevice{}
+	for _, slave := range slaves {
+		name := deviceMapperName(r.slaveSystemPath(syspath, slave), r.fs)
+		switch {
+		case strings.HasSuffix(name, "_corig"):
+			cache.Kind = CacheKindDmCache
+			cache.Backing = append(cache.Backing, slave)
+		case strings.HasSuffix(name, "_wcorig"):
+			cache.Kind = CacheKindDmWritecache
+			cache.Backing = append(cache.Backing, slave)
+		default:
+			cache.Cache = append(cache.Cache, slave)
+		}
+	}
+	if cache.Kind == "" {
+		return nil
+	}
+	return cache
+}
+
+// role returns the role of the slave of the cache device.
+func (c *CacheDevice) role(slave string) string {
+	for _, backing := range c.Backing {
+		if backing == slave {
+			return CacheRoleBacking
+		}
+	}
+	return CacheRoleCache
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFakeBcache creates bcache0 on sdb, with the cache set caching it on
+// the given devices, e.g. 'nvme0n1/nvme0n1p1', if any.
+func writeFakeBcache(fs *linkFs, state string, caches ...string) {
+	writeFakeStackedDevice(fs, "bcache0", "sdb")
+	writeFakeStackedDevice(fs, "sdb")
+	fs.links["/sys/block/bcache0/bcache"] = "../sdb/bcache"
+	afero.WriteFile(fs, "/sys/block/sdb/bcache/cache_mode", []byte("writethrough [writeback] writearound none\n"), 0o644)
+	afero.WriteFile(fs, "/sys/block/sdb/bcache/state", []byte(state+"\n"), 0o644)
+	if len(caches) == 0 {
+		return
+	}
+	fs.links["/sys/block/sdb/bcache/cache"] = "../../../fs/bcache/0f3b5cbe-5d4b-4d3e-9be7-1e4b3c1f7a2d"
+	setDir := "/sys/fs/bcache/0f3b5cbe-5d4b-4d3e-9be7-1e4b3c1f7a2d"
+	afero.WriteFile(fs, filepath.Join(setDir, "bdev0"), nil, 0o644)
+	afero.WriteFile(fs, filepath.Join(setDir, "cache_available_percent"), []byte("98\n"), 0o644)
+	for i, cache := range caches {
+		link := filepath.Join(setDir, fmt.Sprintf("cache%d", i))
+		afero.WriteFile(fs, link, nil, 0o644)
+		fs.links[link] = "../../../block/" + cache + "/bcache"
+	}
+}
+
+// writeFakeLvmCache creates the dm-3 LVM volume 'vg0-data', whose origin is
+// on sda and cache pool on nvme0n1, the origin being suffixed with origin,
+// e.g. '_corig' or '_wcorig'.
+func writeFakeLvmCache(fs afero.Fs, origin string) {
+	writeDm := func(name, dmName string, slaves ...string) {
+		writeFakeStackedDevice(fs, name, slaves...)
+		afero.WriteFile(fs, "/sys/block/"+name+"/dm/name", []byte(dmName+"\n"), 0o644)
+	}
+	writeDm("dm-3", "vg0-data", "dm-0", "dm-1", "dm-2")
+	writeDm("dm-0", "vg0-cpool_cdata", "nvme0n1")
+	writeDm("dm-1", "vg0-cpool_cmeta", "nvme0n1")
+	writeDm("dm-2", "vg0-data"+origin, "sda")
+	writeFakeStackedDevice(fs, "nvme0n1")
+	writeFakeStackedDevice(fs, "sda")
+}
+
+func TestDeviceResolver_cacheDevices(t *testing.T) {
+	type physical struct {
+		devnode, role string
+	}
+	tests := []struct {
+		name         string
+		device       string
+		before       func(*linkFs)
+		wantCache    *CacheDevice
+		wantPhysical []physical
+	}{
+		{
+			name:   "shall resolve a bcache device to its backing and caching devices",
+			device: "bcache0",
+			before: func(fs *linkFs) {
+				writeFakeBcache(fs, "clean", "nvme0n1/nvme0n1p1")
+				writeFakeDevice(fs, "/sys/block/nvme0n1", "nvme0n1", false)
+				writeFakeDevice(fs, "/sys/block/nvme0n1/nvme0n1p1", "nvme0n1p1", true)
+			},
+			wantCache: &CacheDevice{
+				Kind:       CacheKindBcache,
+				Mode:       "writeback",
+				State:      "clean",
+				Backing:    []string{"sdb"},
+				Cache:      []string{"nvme0n1p1"},
+				cachePaths: []string{"/sys/block/nvme0n1/nvme0n1p1"},
+			},
+			wantPhysical: []physical{
+				{"/dev/sdb", CacheRoleBacking},
+				{"/dev/nvme0n1", CacheRoleCache},
+			},
+		},
+		{
+			name:   "shall resolve a bcache device without cache to its backing device",
+			device: "bcache0",
+			before: func(fs *linkFs) {
+				writeFakeBcache(fs, "no cache")
+			},
+			wantCache: &CacheDevice{
+				Kind:    CacheKindBcache,
+				Mode:    "writeback",
+				State:   "no cache",
+				Backing: []string{"sdb"},
+			},
+			wantPhysical: []physical{
+				{"/dev/sdb", CacheRoleBacking},
+			},
+		},
+		{
+			name:   "shall resolve an LVM cache volume to its origin and cache pool devices",
+			device: "dm-3",
+			before: func(fs *linkFs) {
+				writeFakeLvmCache(fs, "_corig")
+			},
+			wantCache: &CacheDevice{
+				Kind:    CacheKindDmCache,
+				Backing: []string{"dm-2"},
+				Cache:   []string{"dm-0", "dm-1"},
+			},
+			wantPhysical: []physical{
+				{"/dev/nvme0n1", CacheRoleCache},
+				{"/dev/sda", CacheRoleBacking},
+			},
+		},
+		{
+			name:   "shall resolve an LVM writecache volume",
+			device: "dm-3",
+			before: func(fs *linkFs) {
+				writeFakeLvmCache(fs, "_wcorig")
+			},
+			wantCache: &CacheDevice{
+				Kind:    CacheKindDmWritecache,
+				Backing: []string{"dm-2"},
+				Cache:   []string{"dm-0", "dm-1"},
+			},
+			wantPhysical: []physical{
+				{"/dev/nvme0n1", CacheRoleCache},
+				{"/dev/sda", CacheRoleBacking},
+			},
+		},
+		{
+			name:   "shall not report other device-mapper volumes as caches",
+			device: "dm-0",
+			before: func(fs *linkFs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda")
+				afero.WriteFile(fs, "/sys/block/dm-0/dm/name", []byte("vg0-data\n"), 0o644)
+				writeFakeStackedDevice(fs, "sda")
+			},
+			wantPhysical: []physical{
+				{"/dev/sda", ""},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+			tt.before(fs)
+			resolver := NewDeviceResolver(fs, "")
+			device, err := resolver.deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCache, device.Cache())
+			devices, err := resolver.resolvePhysicalDevices(context.Background(), device)
+			require.NoError(t, err)
+			var got []physical
+			for _, d := range devices {
+				got = append(got, physical{d.Devnode(), d.CacheRole()})
+			}
+			require.Equal(t, tt.wantPhysical, got)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DeviceCapabilities describes which of the features of block devices are
+// meaningful for a device, so that callers can tell beforehand which tuners
+// apply to it, e.g. loop, RAM and cloud devices lack many of the knobs.
+type DeviceCapabilities struct {
+	// TunableScheduler is set if the device exposes its I/O scheduler and
+	// has more than one to pick from.
+	TunableScheduler bool
+	// TunableReadAhead is set if the device exposes its read-ahead.
+	TunableReadAhead bool
+	// SupportsDiscard is set if the device accepts discards, see
+	// SupportsDiscard.
+	SupportsDiscard bool
+	// Rotational is set if the device is a spinning disk, see IsRotational.
+	Rotational bool
+	// Zoned is set if the device is zoned, host-aware or host-managed, see
+	// ZonedModel.
+	Zoned bool
+	// CloudAttached is set if the device is network-attached, e.g. a cloud
+	// block storage volume (DeviceClassNetwork).
+	CloudAttached bool
+	// Pseudo is set if the device is backed by a file or memory, which
+	// makes tuning it meaningless (DeviceClassLoop and DeviceClassRAM).
+	Pseudo bool
+}
+
+// Capabilities returns the capabilities of the device. They're read when the
+// device is constructed, except the ones stacked devices derive from their
+// physical devices, which are derived the first time they're needed. Those
+// that can't be read are left unset.
+func (d *blockDevice) Capabilities() DeviceCapabilities {
+	if d.resolver == nil {
+		return d.capabilities
+	}
+	// Devices are shared through the resolver cache, the lazily derived
+	// capabilities are guarded by the resolver.
+	d.resolver.capabilitiesMu.Lock()
+	defer d.resolver.capabilitiesMu.Unlock()
+	if !d.stackedCapabilities {
+		return d.capabilities
+	}
+	caps := &d.capabilities
+	var err error
+	if caps.Rotational, err = d.IsRotational(); err != nil {
+		log.Debugf("Unable to read whether '%s' is rotational: %v", d.devnode, err)
+	}
+	if caps.SupportsDiscard, err = d.SupportsDiscard(); err != nil {
+		log.Debugf("Unable to read whether '%s' supports discard: %v", d.devnode, err)
+	}
+	zoned, err := d.ZonedModel()
+	if err != nil {
+		log.Debugf("Unable to read the zoned model of '%s': %v", d.devnode, err)
+	}
+	caps.Zoned = err == nil && zoned != ZonedNone
+	d.stackedCapabilities = false
+	return d.capabilities
+}
+
+// readCapabilities reads the capabilities of the device from its own
+// attributes, the ones of stacked devices derived from their physical devices
+// being left to Capabilities.
+func (r *DeviceResolver) readCapabilities(d *blockDevice) DeviceCapabilities {
+	caps := DeviceCapabilities{
+		TunableScheduler: r.hasTunableScheduler(d.syspath),
+		TunableReadAhead: r.hasQueueAttribute(d.syspath, "read_ahead_kb"),
+		CloudAttached:    d.class == DeviceClassNetwork,
+		Pseudo:           d.class.IsPseudo(),
+	}
+	if d.stacked {
+		return caps
+	}
+	caps.Rotational = d.rotational
+	granularity, err := d.leafDiscardGranularity()
+	if err != nil {
+		log.Debugf("Unable to read the discard granularity of '%s': %v", d.syspath, err)
+	}
+	caps.SupportsDiscard = granularity > 0
+	zoned, err := d.leafZonedModel()
+	if err != nil {
+		log.Debugf("Unable to read the zoned model of '%s': %v", d.syspath, err)
+	}
+	caps.Zoned = err == nil && zoned != ZonedNone
+	return caps
+}
+
+func (r *DeviceResolver) hasQueueAttribute(syspath, attribute string) bool {
+	path, err := attributePath(syspath, filepath.Join("queue", attribute), r.fs)
+	return err == nil && exists(r.fs, path)
+}
+
+// hasTunableScheduler returns whether the device at syspath has more than one
+// I/O scheduler to pick from, e.g. not only 'none'.
+func (r *DeviceResolver) hasTunableScheduler(syspath string) bool {
+	path, err := attributePath(syspath, filepath.Join("queue", "scheduler"), r.fs)
+	if err != nil || !exists(r.fs, path) {
+		return false
+	}
+	// The active scheduler is bracketed, e.g. '[none] mq-deadline kyber'.
+	schedulers, err := readIdentityAttribute(r.fs, path)
+	if err != nil {
+		log.Debugf("Unable to read the schedulers of '%s': %v", syspath, err)
+		return false
+	}
+	return len(strings.Fields(schedulers)) > 1
+}
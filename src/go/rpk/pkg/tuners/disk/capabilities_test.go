@@ -0,0 +1,104 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// writeQueueAttributes writes the given 'queue' attributes of the device at
+// syspath.
+func writeQueueAttributes(fs afero.Fs, syspath string, attributes map[string]string) {
+	for attribute, value := range attributes {
+		afero.WriteFile(fs, filepath.Join(syspath, "queue", attribute), []byte(value+"\n"), 0o644)
+	}
+}
+
+func TestDeviceResolver_capabilities(t *testing.T) {
+	const (
+		nvmePath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0/nvme0n1"
+		loopPath = "/sys/devices/virtual/block/loop0"
+	)
+	tests := []struct {
+		name    string
+		syspath string
+		before  func(afero.Fs)
+		want    DeviceCapabilities
+	}{
+		{
+			name:    "NVMe namespace",
+			syspath: nvmePath,
+			before: func(fs afero.Fs) {
+				writeFakeDevice(fs, nvmePath, "nvme0n1", false)
+				writeQueueAttributes(fs, nvmePath, map[string]string{
+					"scheduler":           "[none] mq-deadline kyber",
+					"read_ahead_kb":       "128",
+					"discard_max_bytes":   "2199023255040",
+					"discard_granularity": "4096",
+					"zoned":               "none",
+				})
+			},
+			want: DeviceCapabilities{
+				TunableScheduler: true,
+				TunableReadAhead: true,
+				SupportsDiscard:  true,
+			},
+		},
+		{
+			name:    "loop device",
+			syspath: loopPath,
+			before: func(fs afero.Fs) {
+				writeFakeDevice(fs, loopPath, "loop0", false)
+				afero.WriteFile(fs, filepath.Join(loopPath, "loop", "backing_file"), []byte("/tmp/rp.img\n"), 0o644)
+				writeQueueAttributes(fs, loopPath, map[string]string{
+					"scheduler":     "[none]",
+					"read_ahead_kb": "128",
+				})
+			},
+			want: DeviceCapabilities{
+				TunableReadAhead: true,
+				Pseudo:           true,
+			},
+		},
+		{
+			name:    "device-mapper volume on a zoned and a rotational disk",
+			syspath: "/sys/block/dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+				writeQueueAttributes(fs, "/sys/block/dm-0", map[string]string{"read_ahead_kb": "256"})
+				writeQueueAttributes(fs, "/sys/block/sda", map[string]string{"zoned": "host-aware"})
+				writeQueueAttributes(fs, "/sys/block/sdb", map[string]string{"rotational": "1"})
+			},
+			want: DeviceCapabilities{
+				TunableReadAhead: true,
+				Rotational:       true,
+				Zoned:            true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "").deviceFromSystemPath(context.Background(), tt.syspath)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, device.Capabilities())
+			// The capabilities are derived once.
+			require.Equal(t, tt.want, device.Capabilities())
+		})
+	}
+}
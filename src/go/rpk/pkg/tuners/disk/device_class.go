@@ -0,0 +1,152 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// DeviceClass is the kind of storage backing a block device, which sets
+// what performance to expect from it.
+type DeviceClass int
+
+const (
+	// DeviceClassLocalSSD is a non rotational device attached to the host,
+	// e.g. a local NVMe or SATA SSD.
+	DeviceClassLocalSSD DeviceClass = iota
+	// DeviceClassLocalHDD is a spinning disk attached to the host.
+	DeviceClassLocalHDD
+	// DeviceClassVirtio is a paravirtualized device, e.g. a virtio or Xen
+	// block device, whose backing storage is hidden by the hypervisor.
+	DeviceClassVirtio
+	// DeviceClassNetwork is a network-attached device: NVMe over Fabrics or
+	// cloud block storage, e.g. AWS EBS volumes or GCP persistent disks,
+	// even if they present as NVMe namespaces.
+	DeviceClassNetwork
+	// DeviceClassLoop is a loop device, backed by a file of another
+	// filesystem, e.g. in CI or container setups.
+	DeviceClassLoop
+	// DeviceClassRAM is a device backed by memory: a ramdisk (brd) or a
+	// compressed zram device.
+	DeviceClassRAM
+	// DeviceClassUnknown is a device whose attributes can't be read, e.g.
+	// one identified from /proc/partitions only.
+	DeviceClassUnknown
+)
+
+func (c DeviceClass) String() string {
+	switch c {
+	case DeviceClassLocalSSD:
+		return "local SSD"
+	case DeviceClassLocalHDD:
+		return "local HDD"
+	case DeviceClassVirtio:
+		return "virtio"
+	case DeviceClassNetwork:
+		return "network-attached"
+	case DeviceClassLoop:
+		return "loop"
+	case DeviceClassRAM:
+		return "ram"
+	}
+	return "unknown"
+}
+
+// IsLocal returns whether the device is attached to the host, which the
+// disk tuners assume.
+func (c DeviceClass) IsLocal() bool {
+	return c == DeviceClassLocalSSD || c == DeviceClassLocalHDD
+}
+
+// IsPseudo returns whether the device is backed by a file or memory rather
+// than by storage, which makes tuning it meaningless.
+func (c DeviceClass) IsPseudo() bool {
+	return c == DeviceClassLoop || c == DeviceClassRAM
+}
+
+// ramDevicePattern matches the names of the ramdisks of the brd driver and of
+// the zram devices, neither of which has a device link to tell them apart.
+var ramDevicePattern = regexp.MustCompile(`^z?ram\d+$`)
+
+// networkDeviceModels are the models reported by the cloud block storage
+// devices, local instance storage reports other ones, e.g. 'Amazon EC2 NVMe
+// Instance Storage' or GCP 'nvme_card'.
+var networkDeviceModels = []string{
+	"Amazon Elastic Block Store", // AWS EBS, on Nitro instances.
+	"nvme_card-pd",               // GCP persistent disks over NVMe.
+	"PersistentDisk",             // GCP persistent disks over SCSI.
+	"Virtual Disk",               // Azure managed disks.
+}
+
+// paravirtualizedDrivers are the drivers of the paravirtualized devices.
+var paravirtualizedDrivers = []string{"virtio_blk", "vbd"}
+
+// readDeviceClass infers the class of the device at syspath from its name
+// and loop attributes for loop and RAM devices, its driver, the model it
+// reports and, for NVMe devices, the transport of its controller.
+func readDeviceClass(syspath string, rotational bool, fs afero.Fs) DeviceClass {
+	name := filepath.Base(syspath)
+	// Loop devices expose their backing file in 'loop/backing_file'.
+	if exists, _ := afero.DirExists(fs, filepath.Join(syspath, "loop")); exists {
+		log.Debugf("'%s' is a loop device", name)
+		return DeviceClassLoop
+	}
+	if ramDevicePattern.MatchString(name) {
+		log.Debugf("'%s' is a RAM-backed device", name)
+		return DeviceClassRAM
+	}
+	devicePath := filepath.Join(syspath, "device")
+	if driver, ok := readLinkIfPossible(fs, filepath.Join(devicePath, "driver")); ok {
+		for _, paravirtualized := range paravirtualizedDrivers {
+			if filepath.Base(driver) == paravirtualized {
+				log.Debugf("'%s' is a paravirtualized device, using driver '%s'", name, paravirtualized)
+				return DeviceClassVirtio
+			}
+		}
+	}
+	if model := readDeviceAttribute(filepath.Join(devicePath, "model"), fs); model != "" {
+		for _, network := range networkDeviceModels {
+			if strings.Contains(model, network) {
+				log.Debugf("'%s' is a network-attached '%s' device", name, model)
+				return DeviceClassNetwork
+			}
+		}
+	}
+	// NVMe controllers are reached over PCIe, or over a network with
+	// NVMe-oF, e.g. 'tcp', 'rdma' or 'fc'.
+	transport := readDeviceAttribute(filepath.Join(devicePath, "transport"), fs)
+	if transport != "" && transport != "pcie" && transport != "loop" {
+		log.Debugf("'%s' is an NVMe over Fabrics device, using transport '%s'", name, transport)
+		return DeviceClassNetwork
+	}
+	if rotational {
+		return DeviceClassLocalHDD
+	}
+	return DeviceClassLocalSSD
+}
+
+// readDeviceAttribute returns the trimmed value of the device attribute at
+// path, or an empty string if the device doesn't expose it.
+func readDeviceAttribute(path string, fs afero.Fs) string {
+	if exists, _ := afero.Exists(fs, path); !exists {
+		return ""
+	}
+	value, err := readSysfsString(fs, path)
+	if err != nil {
+		log.Debugf("Unable to read '%s': %v", path, err)
+		return ""
+	}
+	return value
+}
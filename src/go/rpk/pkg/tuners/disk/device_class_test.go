@@ -0,0 +1,140 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readDeviceClass(t *testing.T) {
+	const (
+		nvmePath = "/sys/devices/pci0000:00/0000:00:1f.0/nvme/nvme1/nvme1n1"
+		sdaPath  = "/sys/devices/pci0000:00/0000:00:03.0/virtio0/host0/target0:0:1/0:0:1:0/block/sda"
+		vdaPath  = "/sys/devices/pci0000:00/0000:00:04.0/virtio1/block/vda"
+		loopPath = "/sys/devices/virtual/block/loop3"
+	)
+	tests := []struct {
+		name       string
+		syspath    string
+		rotational bool
+		attributes map[string]string
+		links      map[string]string
+		want       DeviceClass
+	}{
+		{
+			name:    "shall detect AWS EBS volumes presenting as NVMe",
+			syspath: nvmePath,
+			attributes: map[string]string{
+				"device/model":     "Amazon Elastic Block Store              ",
+				"device/transport": "pcie",
+			},
+			want: DeviceClassNetwork,
+		},
+		{
+			name:    "shall detect AWS instance storage as local",
+			syspath: nvmePath,
+			attributes: map[string]string{
+				"device/model":     "Amazon EC2 NVMe Instance Storage        ",
+				"device/transport": "pcie",
+			},
+			want: DeviceClassLocalSSD,
+		},
+		{
+			name:       "shall detect GCP persistent disks over SCSI",
+			syspath:    sdaPath,
+			attributes: map[string]string{"device/model": "PersistentDisk  "},
+			want:       DeviceClassNetwork,
+		},
+		{
+			name:       "shall detect NVMe over Fabrics devices",
+			syspath:    nvmePath,
+			attributes: map[string]string{"device/model": "Linux", "device/transport": "tcp"},
+			want:       DeviceClassNetwork,
+		},
+		{
+			name:    "shall detect virtio devices from their driver",
+			syspath: vdaPath,
+			links:   map[string]string{vdaPath + "/device/driver": "../../../../bus/virtio/drivers/virtio_blk"},
+			want:    DeviceClassVirtio,
+		},
+		{
+			name:       "shall detect local spinning disks",
+			syspath:    sdaPath,
+			rotational: true,
+			attributes: map[string]string{"device/model": "ST4000NM0035-1V4"},
+			want:       DeviceClassLocalHDD,
+		},
+		{
+			name:       "shall detect loop devices",
+			syspath:    loopPath,
+			rotational: true,
+			attributes: map[string]string{"loop/backing_file": "/var/lib/docker/rp.img"},
+			want:       DeviceClassLoop,
+		},
+		{
+			name:    "shall detect ramdisks",
+			syspath: "/sys/devices/virtual/block/ram0",
+			want:    DeviceClassRAM,
+		},
+		{
+			name:    "shall detect zram devices",
+			syspath: "/sys/devices/virtual/block/zram1",
+			want:    DeviceClassRAM,
+		},
+		{
+			name:    "shall default to local SSDs",
+			syspath: nvmePath,
+			want:    DeviceClassLocalSSD,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: tt.links}
+			for attribute, value := range tt.attributes {
+				afero.WriteFile(fs, filepath.Join(tt.syspath, attribute), []byte(value+"\n"), 0o644)
+			}
+			require.Equal(t, tt.want, readDeviceClass(tt.syspath, tt.rotational, fs))
+		})
+	}
+}
+
+func Test_deviceFromSystemPath_class(t *testing.T) {
+	const nvmePath = "/sys/devices/pci0000:00/0000:00:1f.0/nvme/nvme1/nvme1n1"
+	fs := afero.NewMemMapFs()
+	writeFakeDevice(fs, nvmePath, "nvme1n1", false)
+	afero.WriteFile(fs, filepath.Join(nvmePath, "device", "model"), []byte("Amazon Elastic Block Store\n"), 0o644)
+
+	device, err := NewDeviceResolver(fs, "").deviceFromSystemPath(context.Background(), nvmePath)
+	require.NoError(t, err)
+	require.NotNil(t, device.Nvme())
+	require.Equal(t, DeviceClassNetwork, device.Class())
+	require.False(t, device.Class().IsLocal())
+	require.Equal(t, "network-attached", device.Class().String())
+}
+
+func Test_deviceFromSystemPath_loop(t *testing.T) {
+	const loopPath = "/sys/devices/virtual/block/loop0"
+	fs := afero.NewMemMapFs()
+	writeFakeDevice(fs, loopPath, "loop0", false)
+	afero.WriteFile(fs, filepath.Join(loopPath, "loop", "backing_file"), []byte("/tmp/rp.img\n"), 0o644)
+
+	// Loop devices still resolve, so their detection can be reported.
+	device, err := NewDeviceResolver(fs, "").deviceFromSystemPath(context.Background(), loopPath)
+	require.NoError(t, err)
+	require.Equal(t, "/dev/loop0", device.Devnode())
+	require.Equal(t, DeviceClassLoop, device.Class())
+	require.True(t, device.Class().IsPseudo())
+	require.Equal(t, "loop", device.Class().String())
+}
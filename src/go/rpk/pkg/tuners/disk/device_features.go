@@ -10,12 +10,11 @@
 package disk
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
-	"strconv"
 	"strings"
 
-	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/system"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
@@ -33,6 +32,51 @@ type DeviceFeatures interface {
 	GetSchedulerFeatureFile(device string) (string, error)
 	GetWriteCache(device string) (string, error)
 	GetWriteCacheFeatureFile(device string) (string, error)
+	// GetDriveWriteCacheFeatureFile returns the SCSI 'cache_type' attribute
+	// of the drive of the device, through which its write cache is enabled
+	// or disabled, or an empty string if the device is not a SCSI disk.
+	GetDriveWriteCacheFeatureFile(device string) (string, error)
+	// HasVolatileWriteCache returns whether the drive of the device caches
+	// writes in volatile memory, where they are lost on power failure.
+	HasVolatileWriteCache(device string) (bool, error)
+	GetRotational(device string) (bool, error)
+	// IsNvme returns whether the device is an NVMe namespace or is backed by
+	// an NVMe controller.
+	IsNvme(device string) (bool, error)
+	// GetMdArray returns the md array details of the device, or nil if the
+	// device is not an md array.
+	GetMdArray(device string) (*MdArray, error)
+	// GetDeviceClass returns the kind of storage backing the device.
+	GetDeviceClass(device string) (DeviceClass, error)
+	// GetZonedModel returns the zoned model of the device, e.g. ZonedNone.
+	GetZonedModel(device string) (string, error)
+	GetNrRequests(device string) (int, error)
+	GetNrRequestsFeatureFile(device string) (string, error)
+	// GetAddRandom returns whether the I/O completions of the device
+	// contribute to the kernel entropy pool (queue/add_random).
+	GetAddRandom(device string) (int, error)
+	GetAddRandomFeatureFile(device string) (string, error)
+	// GetRqAffinity returns on which CPUs the I/O completions of the device
+	// are processed (queue/rq_affinity): 0 on any, 1 on the group of the
+	// requesting CPU, 2 on the requesting CPU itself.
+	GetRqAffinity(device string) (int, error)
+	GetRqAffinityFeatureFile(device string) (string, error)
+	GetReadAheadKB(device string) (int, error)
+	GetReadAheadKBFeatureFile(device string) (string, error)
+	// GetMaxSectorsKB returns the largest request the block layer sends to
+	// the device, in KB (queue/max_sectors_kb).
+	GetMaxSectorsKB(device string) (int, error)
+	GetMaxSectorsKBFeatureFile(device string) (string, error)
+	// GetMaxHwSectorsKB returns the largest request the device accepts, in
+	// KB, which max_sectors_kb can't exceed, or 0 if the device doesn't
+	// expose it.
+	GetMaxHwSectorsKB(device string) (int, error)
+	// GetOptimalIOSize returns the I/O size the device prefers, in bytes,
+	// e.g. the stripe width of RAID devices, or 0 if it reports none.
+	GetOptimalIOSize(device string) (int, error)
+	// GetQueueDepth returns the hardware queue depth of the device, or 0 if
+	// the device exposes neither its blk-mq tags nor its SCSI queue depth.
+	GetQueueDepth(device string) (int, error)
 }
 
 func NewDeviceFeatures(fs afero.Fs, blockDevices BlockDevices) DeviceFeatures {
@@ -48,21 +92,15 @@ type deviceFeatures struct {
 }
 
 func (d *deviceFeatures) GetScheduler(device string) (string, error) {
-	schedulerOpts, err := d.getSchedulerOptions(device)
-	if err != nil {
-		return "", err
-	}
-	return schedulerOpts.GetActive(), nil
+	active, _, err := d.getSchedulerOptions(device)
+	return active, err
 }
 
 func (d *deviceFeatures) GetSupportedSchedulers(
 	device string,
 ) ([]string, error) {
-	schedulerOpts, err := d.getSchedulerOptions(device)
-	if err != nil {
-		return nil, err
-	}
-	return schedulerOpts.GetAvailable(), nil
+	_, options, err := d.getSchedulerOptions(device)
+	return options, err
 }
 
 func (d *deviceFeatures) GetNomerges(device string) (int, error) {
@@ -71,12 +109,7 @@ func (d *deviceFeatures) GetNomerges(device string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	log.Debugf("Feature file %s", featureFile)
-	bytes, err := afero.ReadFile(d.fs, featureFile)
-	if err != nil {
-		return 0, err
-	}
-	return strconv.Atoi(strings.TrimSpace(string(bytes)))
+	return d.readIntFeature(featureFile)
 }
 
 func (d *deviceFeatures) GetNomergesFeatureFile(device string) (string, error) {
@@ -96,45 +129,298 @@ func (d *deviceFeatures) GetWriteCache(device string) (string, error) {
 		return "", err
 	}
 	log.Debugf("Feature file %s", featureFile)
-	bytes, err := afero.ReadFile(d.fs, featureFile)
+	return readSysfsString(d.fs, featureFile)
+}
+
+func (d *deviceFeatures) GetWriteCacheFeatureFile(
+	device string,
+) (string, error) {
+	return d.getQueueFeatureFile(deviceNode(device), "write_cache")
+}
+
+// GetDriveWriteCacheFeatureFile returns the 'cache_type' attribute of the
+// SCSI disk of the device, e.g.
+// '<syspath>/device/scsi_disk/0:0:0:0/cache_type', named after the
+// host:channel:target:lun of the disk. SATA disks are SCSI disks too, through
+// libata. Writing 'write through' to it clears the WCE bit of the drive.
+func (d *deviceFeatures) GetDriveWriteCacheFeatureFile(
+	device string,
+) (string, error) {
+	scsiDiskDir, err := d.getFeatureFile(deviceNode(device), filepath.Join("device", "scsi_disk"))
+	if err != nil || scsiDiskDir == "" {
+		return "", err
+	}
+	hctls, err := afero.ReadDir(d.fs, scsiDiskDir)
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(bytes)), nil
+	for _, hctl := range hctls {
+		featureFile := filepath.Join(scsiDiskDir, hctl.Name(), "cache_type")
+		if exists, _ := afero.Exists(d.fs, featureFile); exists {
+			return featureFile, nil
+		}
+	}
+	return "", nil
 }
 
-func (d *deviceFeatures) GetWriteCacheFeatureFile(
+// HasVolatileWriteCache reads the cache type of the SCSI disk of the device
+// where exposed, e.g. 'write back' or 'write through', as 'queue/write_cache'
+// only tells whether the kernel flushes the cache and may have been set to
+// 'write through' to stop it from doing so, e.g. by the GCP write cache
+// tuner. It falls back to 'queue/write_cache' for the other devices, e.g.
+// NVMe ones, and is false for devices exposing neither.
+func (d *deviceFeatures) HasVolatileWriteCache(device string) (bool, error) {
+	log.Debugf("Getting '%s' volatile write cache", device)
+	featureFile, err := d.GetDriveWriteCacheFeatureFile(device)
+	if err != nil {
+		return false, err
+	}
+	if featureFile == "" {
+		featureFile, err = d.GetWriteCacheFeatureFile(device)
+		if err != nil || featureFile == "" {
+			return false, err
+		}
+	}
+	cacheType, err := readSysfsString(d.fs, featureFile)
+	if err != nil {
+		return false, err
+	}
+	// SCSI cache types may carry flags, e.g. 'write back, no read (daft)'.
+	return strings.HasPrefix(cacheType, CachePolicyWriteBack), nil
+}
+
+func (d *deviceFeatures) GetNrRequests(device string) (int, error) {
+	log.Debugf("Getting '%s' nr_requests", device)
+	featureFile, err := d.GetNrRequestsFeatureFile(device)
+	if err != nil {
+		return 0, err
+	}
+	return d.readIntFeature(featureFile)
+}
+
+func (d *deviceFeatures) GetNrRequestsFeatureFile(
 	device string,
 ) (string, error) {
-	return d.getQueueFeatureFile(deviceNode(device), "write_cache")
+	return d.getQueueFeatureFile(deviceNode(device), "nr_requests")
+}
+
+func (d *deviceFeatures) GetAddRandom(device string) (int, error) {
+	log.Debugf("Getting '%s' add_random", device)
+	featureFile, err := d.GetAddRandomFeatureFile(device)
+	if err != nil {
+		return 0, err
+	}
+	return d.readIntFeature(featureFile)
+}
+
+func (d *deviceFeatures) GetAddRandomFeatureFile(
+	device string,
+) (string, error) {
+	return d.getQueueFeatureFile(deviceNode(device), "add_random")
 }
 
+func (d *deviceFeatures) GetRqAffinity(device string) (int, error) {
+	log.Debugf("Getting '%s' rq_affinity", device)
+	featureFile, err := d.GetRqAffinityFeatureFile(device)
+	if err != nil {
+		return 0, err
+	}
+	return d.readIntFeature(featureFile)
+}
+
+func (d *deviceFeatures) GetRqAffinityFeatureFile(
+	device string,
+) (string, error) {
+	return d.getQueueFeatureFile(deviceNode(device), "rq_affinity")
+}
+
+func (d *deviceFeatures) GetMaxSectorsKB(device string) (int, error) {
+	log.Debugf("Getting '%s' max_sectors_kb", device)
+	featureFile, err := d.GetMaxSectorsKBFeatureFile(device)
+	if err != nil {
+		return 0, err
+	}
+	return d.readIntFeature(featureFile)
+}
+
+func (d *deviceFeatures) GetMaxSectorsKBFeatureFile(
+	device string,
+) (string, error) {
+	return d.getQueueFeatureFile(deviceNode(device), "max_sectors_kb")
+}
+
+func (d *deviceFeatures) GetMaxHwSectorsKB(device string) (int, error) {
+	log.Debugf("Getting '%s' max_hw_sectors_kb", device)
+	return d.readOptionalQueueFeature(device, "max_hw_sectors_kb")
+}
+
+func (d *deviceFeatures) GetOptimalIOSize(device string) (int, error) {
+	log.Debugf("Getting '%s' optimal_io_size", device)
+	return d.readOptionalQueueFeature(device, "optimal_io_size")
+}
+
+// readOptionalQueueFeature reads the queue attribute of the device, or
+// returns 0 if the device doesn't expose it.
+func (d *deviceFeatures) readOptionalQueueFeature(
+	device, featureType string,
+) (int, error) {
+	featureFile, err := d.getQueueFeatureFile(deviceNode(device), featureType)
+	if err != nil || featureFile == "" {
+		return 0, err
+	}
+	return d.readIntFeature(featureFile)
+}
+
+func (d *deviceFeatures) GetReadAheadKB(device string) (int, error) {
+	log.Debugf("Getting '%s' read_ahead_kb", device)
+	featureFile, err := d.GetReadAheadKBFeatureFile(device)
+	if err != nil {
+		return 0, err
+	}
+	return d.readIntFeature(featureFile)
+}
+
+func (d *deviceFeatures) GetReadAheadKBFeatureFile(
+	device string,
+) (string, error) {
+	return d.getQueueFeatureFile(deviceNode(device), "read_ahead_kb")
+}
+
+func (d *deviceFeatures) GetQueueDepth(device string) (int, error) {
+	log.Debugf("Getting '%s' queue depth", device)
+	mqDir, err := d.getFeatureFile(deviceNode(device), "mq")
+	if err != nil {
+		return 0, err
+	}
+	depth := 0
+	if mqDir != "" {
+		hwQueues, err := afero.ReadDir(d.fs, mqDir)
+		if err != nil {
+			return 0, err
+		}
+		for _, hwQueue := range hwQueues {
+			tagsFile := filepath.Join(mqDir, hwQueue.Name(), "nr_tags")
+			if exists, _ := afero.Exists(d.fs, tagsFile); !exists {
+				continue
+			}
+			tags, err := d.readIntFeature(tagsFile)
+			if err != nil {
+				return 0, err
+			}
+			if tags > depth {
+				depth = tags
+			}
+		}
+	}
+	if depth > 0 {
+		return depth, nil
+	}
+	// Legacy SCSI devices don't use blk-mq tags but expose the depth of
+	// their device queue.
+	queueDepthFile, err := d.getFeatureFile(
+		deviceNode(device), filepath.Join("device", "queue_depth"))
+	if err != nil || queueDepthFile == "" {
+		return 0, err
+	}
+	return d.readIntFeature(queueDepthFile)
+}
+
+func (d *deviceFeatures) readIntFeature(featureFile string) (int, error) {
+	log.Debugf("Feature file %s", featureFile)
+	value, err := readSysfsUint(d.fs, featureFile)
+	return int(value), err
+}
+
+func (d *deviceFeatures) GetRotational(device string) (bool, error) {
+	log.Debugf("Getting '%s' rotational", device)
+	blockDevice, err := d.blockDevices.GetDeviceFromPath(deviceNode(device))
+	if err != nil {
+		return false, err
+	}
+	return blockDevice.IsRotational()
+}
+
+func (d *deviceFeatures) IsNvme(device string) (bool, error) {
+	if strings.HasPrefix(device, "nvme") {
+		return true, nil
+	}
+	blockDevice, err := d.blockDevices.GetDeviceFromPath(deviceNode(device))
+	if err != nil {
+		return false, err
+	}
+	if blockDevice.Nvme() != nil {
+		return true, nil
+	}
+	return isNvmeDevice(blockDevice.Syspath(), d.fs), nil
+}
+
+func (d *deviceFeatures) GetMdArray(device string) (*MdArray, error) {
+	blockDevice, err := d.blockDevices.GetDeviceFromPath(deviceNode(device))
+	if err != nil {
+		return nil, err
+	}
+	return blockDevice.Md(), nil
+}
+
+func (d *deviceFeatures) GetDeviceClass(device string) (DeviceClass, error) {
+	blockDevice, err := d.blockDevices.GetDeviceFromPath(deviceNode(device))
+	if err != nil {
+		return 0, err
+	}
+	return blockDevice.Class(), nil
+}
+
+func (d *deviceFeatures) GetZonedModel(device string) (string, error) {
+	blockDevice, err := d.blockDevices.GetDeviceFromPath(deviceNode(device))
+	if err != nil {
+		return "", err
+	}
+	return blockDevice.ZonedModel()
+}
+
+// getSchedulerOptions returns the active scheduler of the device and all
+// of those it supports, in the order the kernel lists them.
 func (d *deviceFeatures) getSchedulerOptions(
 	device string,
-) (*system.RuntimeOptions, error) {
+) (active string, options []string, err error) {
 	log.Debugf("Getting '%s' scheduler options", device)
 	featureFile, err := d.GetSchedulerFeatureFile(device)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 	log.Debugf("Feature file %s", featureFile)
-	return system.ReadRuntineOptions(d.fs, featureFile)
+	return ReadSysfsBracketed(d.fs, featureFile)
 }
 
 func (d *deviceFeatures) getQueueFeatureFile(
 	deviceNode string, featureType string,
+) (string, error) {
+	return d.getFeatureFile(deviceNode, filepath.Join("queue", featureType))
+}
+
+// getFeatureFile returns the path of the given sysfs attribute of the
+// device, looking it up in its parents if the device doesn't expose it, or
+// an empty string if none of them does.
+func (d *deviceFeatures) getFeatureFile(
+	deviceNode string, attribute string,
 ) (string, error) {
 	device, err := d.blockDevices.GetDeviceFromPath(deviceNode)
 	if err != nil {
+		if errors.Is(err, ErrUnsupportedPlatform) {
+			return "", err
+		}
 		log.Error(err.Error())
 		return "", nil
 	}
-	featureFile := filepath.Join(device.Syspath(), "queue", featureType)
+	featureFile, err := attributePath(device.Syspath(), attribute, d.fs)
+	if err != nil {
+		return "", err
+	}
 	log.Debugf("Trying to open feature file '%s'", featureFile)
 	if exists, _ := afero.Exists(d.fs, featureFile); exists {
 		return featureFile, nil
 	} else if device.Parent() != nil {
-		return d.getQueueFeatureFile(device.Parent().Devnode(), featureType)
+		return d.getFeatureFile(device.Parent().Devnode(), attribute)
 	} else {
 		return "", nil
 	}
@@ -10,6 +10,7 @@
 package disk
 
 import (
+	"context"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -23,6 +24,7 @@ const (
 type blockDevicesMock struct {
 	getDirectoriesDevices    func([]string) (map[string][]string, error)
 	getDirectoryDevices      func(string) ([]string, error)
+	getDirectoryStacks       func(string) ([][]string, error)
 	getBlockDeviceFromPath   func(string) (BlockDevice, error)
 	getBlockDeviceSystemPath func(string) (string, error)
 	getDiskInfoByType        func([]string) (map[DiskType]DevicesIRQs, error)
@@ -42,6 +44,12 @@ func (m *blockDevicesMock) GetDeviceSystemPath(path string) (string, error) {
 	return m.getBlockDeviceSystemPath(path)
 }
 
+func (m *blockDevicesMock) GetDirectoryStacks(
+	_ context.Context, path string,
+) ([][]string, error) {
+	return m.getDirectoryStacks(path)
+}
+
 func (m *blockDevicesMock) GetDirectoryDevices(path string) ([]string, error) {
 	return m.getDirectoryDevices(path)
 }
@@ -52,6 +60,10 @@ func (m *blockDevicesMock) GetDiskInfoByType(
 	return m.getDiskInfoByType(devices)
 }
 
+func (*blockDevicesMock) GetDeviceNumaNode(string) (int, error) {
+	return -1, nil
+}
+
 var noopSchedulerEnabled = "deadline cfq [noop]"
 
 func TestDeviceFeatures_GetScheduler(t *testing.T) {
@@ -126,6 +138,36 @@ func TestDeviceFeatures_GetNoMerges(t *testing.T) {
 	require.Equal(t, nomerges, 2)
 }
 
+func TestDeviceFeatures_GetRqAffinity(t *testing.T) {
+	// given
+	blockDevices := &blockDevicesMock{
+		getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+			return &blockDevice{
+				devnode: "/dev/fake",
+				syspath: testDevicePath,
+			}, nil
+		},
+	}
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll(testDevicePath+"/queue", 0o644)
+	deviceFeatures := NewDeviceFeatures(fs, blockDevices)
+	// when
+	featureFile, err := deviceFeatures.GetRqAffinityFeatureFile("fake")
+	// then
+	require.NoError(t, err)
+	require.Empty(t, featureFile)
+
+	// given
+	afero.WriteFile(fs,
+		testDevicePath+"/queue/rq_affinity",
+		[]byte("1\n"), 0o644)
+	// when
+	rqAffinity, err := deviceFeatures.GetRqAffinity("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 1, rqAffinity)
+}
+
 func TestDeviceFeatures_GetWriteCache(t *testing.T) {
 	// given
 	blockDevices := &blockDevicesMock{
@@ -148,3 +190,203 @@ func TestDeviceFeatures_GetWriteCache(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, cache, CachePolicyWriteBack)
 }
+
+func TestDeviceFeatures_HasVolatileWriteCache(t *testing.T) {
+	const cacheType = testDevicePath + "/device/scsi_disk/0:0:0:0/cache_type"
+	tests := []struct {
+		name       string
+		writeCache string
+		cacheType  string
+		want       bool
+		wantFile   string
+	}{
+		{
+			name:       "shall read the cache type of SCSI disks",
+			writeCache: CachePolicyWriteThrough,
+			cacheType:  "write back, no read (daft)\n",
+			want:       true,
+			wantFile:   cacheType,
+		},
+		{
+			name:       "shall report SCSI disks with their write cache disabled",
+			writeCache: CachePolicyWriteBack,
+			cacheType:  "write through\n",
+			wantFile:   cacheType,
+		},
+		{
+			name:       "shall fall back to the queue write cache",
+			writeCache: CachePolicyWriteBack + "\n",
+			want:       true,
+		},
+		{
+			name: "shall report devices exposing no write cache",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+					return &blockDevice{
+						devnode: "/dev/fake",
+						syspath: testDevicePath,
+					}, nil
+				},
+			}
+			fs := afero.NewMemMapFs()
+			fs.MkdirAll(testDevicePath+"/queue", 0o644)
+			if tt.writeCache != "" {
+				afero.WriteFile(fs, testDevicePath+"/queue/write_cache", []byte(tt.writeCache), 0o644)
+			}
+			if tt.cacheType != "" {
+				afero.WriteFile(fs, cacheType, []byte(tt.cacheType), 0o644)
+			}
+			deviceFeatures := NewDeviceFeatures(fs, blockDevices)
+			featureFile, err := deviceFeatures.GetDriveWriteCacheFeatureFile("fake")
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFile, featureFile)
+			volatile, err := deviceFeatures.HasVolatileWriteCache("fake")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, volatile)
+		})
+	}
+}
+
+func TestDeviceFeatures_GetQueueLimits(t *testing.T) {
+	// given
+	blockDevices := &blockDevicesMock{
+		getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+			return &blockDevice{
+				devnode: "/dev/fake",
+				syspath: testDevicePath,
+			}, nil
+		},
+	}
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll(testDevicePath+"/queue", 0o644)
+	afero.WriteFile(fs, testDevicePath+"/queue/nr_requests", []byte("1023\n"), 0o644)
+	afero.WriteFile(fs, testDevicePath+"/queue/read_ahead_kb", []byte("128\n"), 0o644)
+	afero.WriteFile(fs, testDevicePath+"/queue/max_sectors_kb", []byte("128\n"), 0o644)
+	afero.WriteFile(fs, testDevicePath+"/queue/max_hw_sectors_kb", []byte("2048\n"), 0o644)
+	deviceFeatures := NewDeviceFeatures(fs, blockDevices)
+	// when
+	nrRequests, err := deviceFeatures.GetNrRequests("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 1023, nrRequests)
+	// when
+	readAheadKB, err := deviceFeatures.GetReadAheadKB("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 128, readAheadKB)
+	// when
+	maxSectorsKB, err := deviceFeatures.GetMaxSectorsKB("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 128, maxSectorsKB)
+	// when
+	maxHwSectorsKB, err := deviceFeatures.GetMaxHwSectorsKB("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 2048, maxHwSectorsKB)
+	// when the device doesn't report its optimal I/O size
+	optimalIOSize, err := deviceFeatures.GetOptimalIOSize("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 0, optimalIOSize)
+}
+
+func TestDeviceFeatures_GetQueueDepth(t *testing.T) {
+	tests := []struct {
+		name   string
+		before func(afero.Fs)
+		want   int
+	}{
+		{
+			name: "shall return the largest blk-mq tags depth",
+			before: func(fs afero.Fs) {
+				fs.MkdirAll(testDevicePath+"/mq/0", 0o644)
+				fs.MkdirAll(testDevicePath+"/mq/1", 0o644)
+				afero.WriteFile(fs, testDevicePath+"/mq/0/nr_tags", []byte("1023\n"), 0o644)
+				afero.WriteFile(fs, testDevicePath+"/mq/1/nr_tags", []byte("511\n"), 0o644)
+			},
+			want: 1023,
+		},
+		{
+			name: "shall fall back to the SCSI device queue depth",
+			before: func(fs afero.Fs) {
+				fs.MkdirAll(testDevicePath+"/device", 0o644)
+				afero.WriteFile(fs, testDevicePath+"/device/queue_depth", []byte("32\n"), 0o644)
+			},
+			want: 32,
+		},
+		{
+			name:   "shall return 0 when the queue depth is not exposed",
+			before: func(fs afero.Fs) {},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+					return &blockDevice{
+						devnode: "/dev/fake",
+						syspath: testDevicePath,
+					}, nil
+				},
+			}
+			fs := afero.NewMemMapFs()
+			fs.MkdirAll(testDevicePath+"/queue", 0o644)
+			tt.before(fs)
+			depth, err := NewDeviceFeatures(fs, blockDevices).GetQueueDepth("fake")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, depth)
+		})
+	}
+}
+
+func TestDeviceFeatures_IsNvme(t *testing.T) {
+	tests := []struct {
+		name   string
+		device string
+		links  map[string]string
+		want   bool
+	}{
+		{
+			name:   "shall detect NVMe devices by their name",
+			device: "nvme0n1",
+			want:   true,
+		},
+		{
+			name:   "shall detect devices backed by an NVMe controller",
+			device: "fake",
+			links: map[string]string{
+				testDevicePath + "/device/subsystem": "../../../../../class/nvme",
+			},
+			want: true,
+		},
+		{
+			name:   "shall not detect other devices",
+			device: "fake",
+			links: map[string]string{
+				testDevicePath + "/device/subsystem": "../../../../../bus/scsi",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+					return &blockDevice{
+						devnode: "/dev/fake",
+						syspath: testDevicePath,
+					}, nil
+				},
+			}
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: tt.links}
+			nvme, err := NewDeviceFeatures(fs, blockDevices).IsNvme(tt.device)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, nvme)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+type identityAttribute int
+
+const (
+	identityModel identityAttribute = iota
+	identityVendor
+	identitySerial
+)
+
+// Model returns the model of the drive, e.g. 'Samsung SSD 980 PRO 1TB'.
+func (d *blockDevice) Model() (string, error) {
+	return d.identity(identityModel)
+}
+
+// Vendor returns the vendor of the drive, e.g. 'ATA' for SATA drives. NVMe
+// controllers don't report one, the PCI vendor ID of the controller is
+// returned instead, e.g. '0x144d'.
+func (d *blockDevice) Vendor() (string, error) {
+	return d.identity(identityVendor)
+}
+
+// Serial returns the serial number of the drive or, for SCSI drives, their
+// world wide identifier.
+func (d *blockDevice) Serial() (string, error) {
+	return d.identity(identitySerial)
+}
+
+// identity returns the attribute of the device or, for stacked devices, the
+// ones of each of their physical devices, comma separated.
+func (d *blockDevice) identity(attribute identityAttribute) (string, error) {
+	if d.resolver == nil {
+		return "", nil
+	}
+	slaves, err := readSlaves(d.syspath, d.resolver.fs)
+	if err != nil {
+		return "", err
+	}
+	if len(slaves) == 0 {
+		return d.leafIdentity(attribute)
+	}
+	physDevices, err := d.resolver.resolvePhysicalDevices(context.Background(), d)
+	if err != nil {
+		return "", err
+	}
+	var (
+		values []string
+		found  bool
+	)
+	for _, physDevice := range physDevices {
+		leaf, ok := physDevice.(*blockDevice)
+		if !ok {
+			continue
+		}
+		value, err := leaf.leafIdentity(attribute)
+		if err != nil {
+			return "", err
+		}
+		found = found || value != ""
+		values = append(values, value)
+	}
+	if !found {
+		return "", nil
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// leafIdentity reads the attribute of a physical device. SCSI devices expose
+// it in their 'device' directory, while NVMe namespaces expose it in their
+// controller and virtio devices expose their serial themselves.
+func (d *blockDevice) leafIdentity(attribute identityAttribute) (string, error) {
+	var paths []string
+	if d.nvme != nil {
+		controller := d.nvme.ControllerPath
+		switch attribute {
+		case identityModel:
+			paths = []string{filepath.Join(controller, "model")}
+		case identityVendor:
+			paths = []string{filepath.Join(controller, "device", "vendor")}
+		case identitySerial:
+			paths = []string{filepath.Join(controller, "serial"), filepath.Join(d.syspath, "wwid")}
+		}
+	} else {
+		device := filepath.Join(d.syspath, "device")
+		switch attribute {
+		case identityModel:
+			paths = []string{filepath.Join(device, "model")}
+		case identityVendor:
+			paths = []string{filepath.Join(device, "vendor")}
+		case identitySerial:
+			paths = []string{
+				filepath.Join(d.syspath, "serial"),
+				filepath.Join(device, "serial"),
+				filepath.Join(device, "wwid"),
+			}
+		}
+	}
+	for _, path := range paths {
+		value, err := readIdentityAttribute(d.resolver.fs, path)
+		if err != nil || value != "" {
+			return value, err
+		}
+	}
+	return "", nil
+}
+
+// readIdentityAttribute returns the value of the attribute at path without
+// its padding, or an empty string if the device doesn't expose it.
+func readIdentityAttribute(fs afero.Fs, path string) (string, error) {
+	if exists, _ := afero.Exists(fs, path); !exists {
+		return "", nil
+	}
+	return readSysfsString(fs, path)
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockDevice_identity(t *testing.T) {
+	writeAttributes := func(fs afero.Fs, attributes map[string]string) {
+		for path, value := range attributes {
+			afero.WriteFile(fs, path, []byte(value+"\n"), 0o644)
+		}
+	}
+	tests := []struct {
+		name       string
+		device     string
+		before     func(afero.Fs)
+		wantModel  string
+		wantVendor string
+		wantSerial string
+	}{
+		{
+			name:   "shall read the identity of SCSI drives",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeAttributes(fs, map[string]string{
+					"/sys/block/sda/device/model":  "ST4000NM0035-1V4",
+					"/sys/block/sda/device/vendor": "ATA     ",
+					"/sys/block/sda/device/wwid":   "naa.5000c500a1b2c3d4",
+				})
+			},
+			wantModel:  "ST4000NM0035-1V4",
+			wantVendor: "ATA",
+			wantSerial: "naa.5000c500a1b2c3d4",
+		},
+		{
+			name:   "shall read the identity of NVMe namespaces from their controller",
+			device: "nvme0n1",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeAttributes(fs, map[string]string{
+					"/sys/block/nvme0n1/wwid":             "eui.0025385b71b2c3d4",
+					"/sys/class/nvme/nvme0/model":         "Samsung SSD 980 PRO 1TB                 ",
+					"/sys/class/nvme/nvme0/serial":        "S5GXNF0R123456A     ",
+					"/sys/class/nvme/nvme0/device/vendor": "0x144d",
+				})
+			},
+			wantModel:  "Samsung SSD 980 PRO 1TB",
+			wantVendor: "0x144d",
+			wantSerial: "S5GXNF0R123456A",
+		},
+		{
+			name:   "shall fall back to the namespace wwid of NVMe namespaces",
+			device: "nvme0n1",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeAttributes(fs, map[string]string{"/sys/block/nvme0n1/wwid": "eui.0025385b71b2c3d4"})
+			},
+			wantSerial: "eui.0025385b71b2c3d4",
+		},
+		{
+			name:   "shall read the serial of virtio devices",
+			device: "vda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "vda")
+				writeAttributes(fs, map[string]string{"/sys/block/vda/serial": "disk-1"})
+			},
+			wantSerial: "disk-1",
+		},
+		{
+			name:   "shall return empty values for missing attributes",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+			},
+		},
+		{
+			name:   "shall return the identity of each physical device of a volume",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+				writeAttributes(fs, map[string]string{
+					"/sys/block/sda/device/model": "ST4000NM0035-1V4",
+					"/sys/block/sda/device/wwid":  "naa.5000c500a1b2c3d4",
+					"/sys/block/sdb/device/model": "ST4000NM0035-1V4",
+				})
+			},
+			wantModel:  "ST4000NM0035-1V4, ST4000NM0035-1V4",
+			wantSerial: "naa.5000c500a1b2c3d4, ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			model, err := device.Model()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantModel, model)
+			vendor, err := device.Vendor()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVendor, vendor)
+			serial, err := device.Serial()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSerial, serial)
+		})
+	}
+}
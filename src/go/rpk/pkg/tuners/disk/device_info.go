@@ -0,0 +1,117 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// DeviceInfo is a snapshot of what's known of a block device, for support
+// cases. It's gathered best-effort: the fields which can't be read are left
+// unset, and why is recorded in Errors, keyed by the JSON name of the field,
+// e.g. 'nr_requests'.
+type DeviceInfo struct {
+	Name    string `json:"name"`
+	Syspath string `json:"syspath"`
+	Class   string `json:"class"`
+	// Model, Vendor and Serial list the ones of each physical device for
+	// stacked devices, comma separated.
+	Model      string `json:"model"`
+	Vendor     string `json:"vendor"`
+	Serial     string `json:"serial"`
+	Transport  string `json:"transport"`
+	Rotational bool   `json:"rotational"`
+	ZonedModel string `json:"zoned_model"`
+	// Scheduler is the active I/O scheduler, one of AvailableSchedulers.
+	Scheduler           string   `json:"scheduler"`
+	AvailableSchedulers []string `json:"available_schedulers"`
+	ReadAheadKB         uint64   `json:"read_ahead_kb"`
+	NrRequests          uint64   `json:"nr_requests"`
+	Discard             bool     `json:"discard"`
+	// DiscardGranularity, LogicalBlockSize and PhysicalBlockSize are in
+	// bytes, 0 if the device doesn't expose them.
+	DiscardGranularity uint64            `json:"discard_granularity"`
+	LogicalBlockSize   uint64            `json:"logical_block_size"`
+	PhysicalBlockSize  uint64            `json:"physical_block_size"`
+	Errors             map[string]string `json:"errors,omitempty"`
+}
+
+// NewDeviceInfo returns the snapshot of the device. It never fails: the
+// failures to read each field are recorded in its Errors instead.
+func NewDeviceInfo(ctx context.Context, device BlockDevice) DeviceInfo {
+	info := DeviceInfo{
+		Name:                deviceName(device),
+		Syspath:             device.Syspath(),
+		Class:               device.Class().String(),
+		AvailableSchedulers: []string{},
+	}
+	record := func(field string, err error) {
+		if err == nil {
+			return
+		}
+		if info.Errors == nil {
+			info.Errors = map[string]string{}
+		}
+		info.Errors[field] = err.Error()
+	}
+	var err error
+	info.Model, err = device.Model()
+	record("model", err)
+	info.Vendor, err = device.Vendor()
+	record("vendor", err)
+	info.Serial, err = device.Serial()
+	record("serial", err)
+	transport, err := device.Transport(ctx)
+	info.Transport = transport.String()
+	record("transport", err)
+	info.Rotational, err = device.IsRotational()
+	record("rotational", err)
+	info.ZonedModel, err = device.ZonedModel()
+	record("zoned_model", err)
+	info.DiscardGranularity, err = device.DiscardGranularity()
+	info.Discard = info.DiscardGranularity > 0
+	record("discard_granularity", err)
+	info.LogicalBlockSize, err = device.LogicalBlockSize()
+	record("logical_block_size", err)
+	info.PhysicalBlockSize, err = device.PhysicalBlockSize()
+	record("physical_block_size", err)
+
+	d, ok := device.(*blockDevice)
+	if !ok || d.resolver == nil {
+		// The queue of devices identified without sysfs can't be read,
+		// which their other attributes already report.
+		return info
+	}
+	scheduler, options, err := d.readQueueBracketed("scheduler")
+	if options != nil {
+		info.Scheduler, info.AvailableSchedulers = scheduler, options
+	}
+	record("scheduler", err)
+	info.ReadAheadKB, err = d.readQueueUint("read_ahead_kb")
+	record("read_ahead_kb", err)
+	info.NrRequests, err = d.readQueueUint("nr_requests")
+	record("nr_requests", err)
+	return info
+}
+
+// readQueueBracketed reads the given multiple choice attribute of the queue
+// of the device, see ReadSysfsBracketed. Devices not exposing it have no
+// options.
+func (d *blockDevice) readQueueBracketed(attribute string) (string, []string, error) {
+	path, err := attributePath(d.syspath, filepath.Join("queue", attribute), d.resolver.fs)
+	if err != nil {
+		return "", nil, err
+	}
+	if !exists(d.resolver.fs, path) {
+		return "", nil, nil
+	}
+	return ReadSysfsBracketed(d.resolver.fs, path)
+}
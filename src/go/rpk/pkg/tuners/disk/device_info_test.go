@@ -0,0 +1,102 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFakeSataDisk writes the sysfs attributes of a SATA SSD.
+func writeFakeSataDisk(fs *linkFs, name string) {
+	syspath := filepath.Join("/sys/block", name)
+	writeFakeStackedDevice(fs, name)
+	fs.MkdirAll(filepath.Join(syspath, "device"), 0o755)
+	fs.links[filepath.Join(syspath, "device")] = sataDeviceLink
+	for attribute, value := range map[string]string{
+		"device/model":              "Samsung SSD 870\n",
+		"device/vendor":             "ATA     \n",
+		"device/wwid":               "naa.5002538f41234567\n",
+		"queue/rotational":          "0\n",
+		"queue/zoned":               "none\n",
+		"queue/scheduler":           "none [mq-deadline] kyber\n",
+		"queue/read_ahead_kb":       "128\n",
+		"queue/nr_requests":         "64\n",
+		"queue/discard_granularity": "4096\n",
+		"queue/discard_max_bytes":   "2147450880\n",
+		"queue/logical_block_size":  "512\n",
+		"queue/physical_block_size": "4096\n",
+	} {
+		afero.WriteFile(fs, filepath.Join(syspath, attribute), []byte(value), 0o644)
+	}
+}
+
+func TestNewDeviceInfo(t *testing.T) {
+	fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+	writeFakeSataDisk(fs, "sda")
+
+	device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), "/sys/block/sda")
+	require.NoError(t, err)
+	require.Equal(t, DeviceInfo{
+		Name:                "sda",
+		Syspath:             "/sys/block/sda",
+		Class:               DeviceClassLocalSSD.String(),
+		Model:               "Samsung SSD 870",
+		Vendor:              "ATA",
+		Serial:              "naa.5002538f41234567",
+		Transport:           "sata",
+		ZonedModel:          ZonedNone,
+		Scheduler:           "mq-deadline",
+		AvailableSchedulers: []string{"none", "mq-deadline", "kyber"},
+		ReadAheadKB:         128,
+		NrRequests:          64,
+		Discard:             true,
+		DiscardGranularity:  4096,
+		LogicalBlockSize:    512,
+		PhysicalBlockSize:   4096,
+	}, NewDeviceInfo(context.Background(), device))
+}
+
+func TestNewDeviceInfo_partialFailures(t *testing.T) {
+	fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+	writeFakeSataDisk(fs, "sda")
+	afero.WriteFile(fs, "/sys/block/sda/queue/nr_requests", []byte("many\n"), 0o644)
+	afero.WriteFile(fs, "/sys/block/sda/queue/scheduler", []byte("[none] [kyber]\n"), 0o644)
+	fs.Remove("/sys/block/sda/queue/read_ahead_kb")
+
+	device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), "/sys/block/sda")
+	require.NoError(t, err)
+	info := NewDeviceInfo(context.Background(), device)
+	// The fields which failed to read are left unset and annotated, the
+	// missing ones are only left unset.
+	require.Zero(t, info.NrRequests)
+	require.Empty(t, info.Scheduler)
+	require.Empty(t, info.AvailableSchedulers)
+	require.Zero(t, info.ReadAheadKB)
+	require.Len(t, info.Errors, 2)
+	require.Contains(t, info.Errors["nr_requests"], "/sys/block/sda/queue/nr_requests")
+	require.Contains(t, info.Errors["scheduler"], "more than one active option")
+	// The other fields are still read.
+	require.Equal(t, "Samsung SSD 870", info.Model)
+	require.Equal(t, "sata", info.Transport)
+	require.Equal(t, uint64(4096), info.PhysicalBlockSize)
+}
+
+func TestNewDeviceInfo_identifiedOnly(t *testing.T) {
+	info := NewDeviceInfo(context.Background(), &identifiedDevice{devnode: "/dev/sda"})
+	require.Equal(t, "sda", info.Name)
+	require.Empty(t, info.Syspath)
+	require.Contains(t, info.Errors, "model")
+	require.Contains(t, info.Errors, "rotational")
+}
@@ -0,0 +1,411 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DefaultSysfsRoot is where sysfs is mounted by default.
+	DefaultSysfsRoot = "/sys"
+	// SysfsRootEnv is the environment variable overriding where sysfs is
+	// mounted, e.g. '/host/sys' when running in a container with the host
+	// sysfs mounted.
+	SysfsRootEnv = "RPK_SYSFS_ROOT"
+)
+
+// DeviceResolver resolves block devices through the sysfs mounted at
+// SysfsRoot. The devices resolved from their device number are cached, so
+// directories sharing a disk get the same BlockDevice without reading sysfs
+// again. It's safe for concurrent use.
+type DeviceResolver struct {
+	SysfsRoot string
+	// MountInfoPath is the mount table the devices not found in sysfs are
+	// looked up in, see DefaultMountInfoPath.
+	MountInfoPath string
+	// ProcPartitionsPath lists the devices identified by their number only
+	// when they are neither in sysfs nor mounted, see
+	// DefaultProcPartitionsPath.
+	ProcPartitionsPath string
+	// Concurrency bounds the number of devices resolved concurrently, it
+	// defaults to GOMAXPROCS.
+	Concurrency int
+	fs          afero.Fs
+
+	mu           sync.Mutex
+	cache        map[uint64]BlockDevice
+	cacheDisable bool
+	// rotationalMu guards the rotational value of stacked devices, which is
+	// derived the first time it's needed.
+	rotationalMu sync.Mutex
+	// capabilitiesMu likewise guards the capabilities of stacked devices.
+	capabilitiesMu sync.Mutex
+	// statPath returns the number of the device holding a path and the type
+	// of its filesystem if it's not backed by a block device, it's replaced
+	// in tests.
+	statPath func(path string) (dev uint64, virtualFsType string, err error)
+}
+
+// NewDeviceResolver returns a DeviceResolver using the sysfs mounted at
+// sysfsRoot, or at DefaultSysfsRoot if empty.
+func NewDeviceResolver(fs afero.Fs, sysfsRoot string) *DeviceResolver {
+	if sysfsRoot == "" {
+		sysfsRoot = DefaultSysfsRoot
+	}
+	return &DeviceResolver{
+		SysfsRoot:          filepath.Clean(sysfsRoot),
+		MountInfoPath:      DefaultMountInfoPath,
+		ProcPartitionsPath: DefaultProcPartitionsPath,
+		fs:                 fs,
+		cache:              map[uint64]BlockDevice{},
+		statPath:           statPath,
+	}
+}
+
+// ResetCache drops the cached devices, e.g. after sysfs changed.
+func (r *DeviceResolver) ResetCache() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = map[uint64]BlockDevice{}
+}
+
+// DisableCache drops the cached devices and stops caching new ones, every
+// device is then resolved from sysfs.
+func (r *DeviceResolver) DisableCache() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = map[uint64]BlockDevice{}
+	r.cacheDisable = true
+}
+
+// cachedDevice returns the cached device with the given device number.
+func (r *DeviceResolver) cachedDevice(dev uint64) (BlockDevice, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	device, ok := r.cache[dev]
+	return device, ok
+}
+
+// cacheDevice caches the device with the given device number, unless the
+// cache is disabled. If another device was cached concurrently for the same
+// number that one is returned instead, so that callers share a single
+// BlockDevice.
+func (r *DeviceResolver) cacheDevice(dev uint64, device BlockDevice) BlockDevice {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cacheDisable {
+		return device
+	}
+	if cached, ok := r.cache[dev]; ok {
+		return cached
+	}
+	r.cache[dev] = device
+	return device
+}
+
+// SysfsRootFromEnv returns the sysfs root set in the SysfsRootEnv
+// environment variable, or DefaultSysfsRoot if not set.
+func SysfsRootFromEnv() string {
+	if root := os.Getenv(SysfsRootEnv); root != "" {
+		return root
+	}
+	return DefaultSysfsRoot
+}
+
+// NewDevice returns the block device with the given device number, resolved
+// through the sysfs mounted at DefaultSysfsRoot. It can't be cancelled, see
+// DeviceResolver.NewDevice.
+func NewDevice(dev uint64, fs afero.Fs) (BlockDevice, error) {
+	return NewDeviceResolver(fs, DefaultSysfsRoot).NewDevice(context.Background(), dev)
+}
+
+// NewDeviceFromNumbers returns the block device with the given major and
+// minor numbers, resolved through the sysfs mounted at DefaultSysfsRoot. It
+// can't be cancelled, see DeviceResolver.DeviceFromNumbers.
+func NewDeviceFromNumbers(major, minor uint32, fs afero.Fs) (BlockDevice, error) {
+	return NewDeviceResolver(fs, DefaultSysfsRoot).DeviceFromNumbers(context.Background(), major, minor)
+}
+
+// DeviceFromNumbers returns the block device with the given major and minor
+// numbers, e.g. 259 and 0 for the first NVMe namespace, as NewDevice does
+// for the device number packing them.
+func (r *DeviceResolver) DeviceFromNumbers(
+	ctx context.Context, major, minor uint32,
+) (BlockDevice, error) {
+	return r.NewDevice(ctx, unix.Mkdev(major, minor))
+}
+
+// ParseDeviceNumbers parses the major and minor numbers of a device written
+// as '<major>:<minor>', e.g. '259:0', as in the 'dev' sysfs attribute of the
+// devices.
+func ParseDeviceNumbers(s string) (major, minor uint32, err error) {
+	majorStr, minorStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid device numbers %q, expected <major>:<minor>", s)
+	}
+	maj, err := strconv.ParseUint(majorStr, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid major number in %q: %w", s, err)
+	}
+	min, err := strconv.ParseUint(minorStr, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid minor number in %q: %w", s, err)
+	}
+	return uint32(maj), uint32(min), nil
+}
+
+// NewDeviceFromPath returns the block device holding the given path, e.g.
+// a data directory, resolved through the sysfs mounted at DefaultSysfsRoot.
+// Symbolic links in the path are followed. It fails with a
+// NotBlockDeviceError if the path lives on a filesystem without a block
+// device. It can't be cancelled, see DeviceResolver.NewDeviceFromPath.
+func NewDeviceFromPath(path string, fs afero.Fs) (BlockDevice, error) {
+	return NewDeviceResolver(fs, DefaultSysfsRoot).NewDeviceFromPath(context.Background(), path)
+}
+
+// NewDeviceFromPath returns the block device holding the given path,
+// following its symbolic links. Paths on an overlay, or whose device number
+// is not in sysfs, e.g. bind mounts in containers, are resolved from the
+// device backing their mount, see deviceFromMountInfo.
+func (r *DeviceResolver) NewDeviceFromPath(
+	ctx context.Context, path string,
+) (BlockDevice, error) {
+	log.Debugf("Creating block device from path '%s'", path)
+	dev, fsType, err := r.statPath(path)
+	if err != nil {
+		return nil, err
+	}
+	switch fsType {
+	case "":
+	case "overlay":
+		return r.deviceFromMountInfo(ctx, path)
+	default:
+		// Checked up front, as the anonymous device numbers of these
+		// filesystems aren't in sysfs, which would fail as if sysfs was
+		// unavailable.
+		return nil, &NotBlockDeviceError{Path: path, FsType: fsType}
+	}
+	device, err := r.NewDevice(ctx, dev)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return device, err
+	}
+	log.Debugf("Looking up the device of '%s' in the mount table: %v", path, err)
+	device, mountErr := r.deviceFromMountInfo(ctx, path)
+	if mountErr != nil {
+		if errors.Is(mountErr, ErrNotBlockDevice) {
+			return nil, mountErr
+		}
+		if errors.Is(err, ErrSysfsUnavailable) {
+			return nil, fmt.Errorf("unable to resolve the block device of '%s', it's not in the mount table either (%v): %w",
+				path, mountErr, err)
+		}
+		return nil, fmt.Errorf("unable to resolve the block device of '%s', it's neither in sysfs (%v) nor in the mount table: %w",
+			path, err, mountErr)
+	}
+	return device, nil
+}
+
+// DevicesForPaths returns the block devices holding the given paths, e.g.
+// the data directories, resolved through the sysfs mounted at
+// DefaultSysfsRoot. See DeviceResolver.DevicesForPaths.
+func DevicesForPaths(ctx context.Context, paths []string, fs afero.Fs) ([]BlockDevice, error) {
+	return NewDeviceResolver(fs, DefaultSysfsRoot).DevicesForPaths(ctx, paths)
+}
+
+// DevicesForPaths returns the block devices holding the given paths, in the
+// order of the paths, each device once even if it holds several of them. As
+// partitions are resolved to their disk, paths on different partitions of a
+// disk share its device too. Paths which fail to resolve don't stop the
+// others from being resolved, their errors are returned together along with
+// the devices that were resolved.
+//
+// The paths are resolved concurrently by up to Concurrency goroutines. Once
+// ctx is done the paths that were not resolved yet are left aside and the
+// context error is returned.
+func (r *DeviceResolver) DevicesForPaths(
+	ctx context.Context, paths []string,
+) ([]BlockDevice, error) {
+	workers := r.Concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+	resolved := make([]BlockDevice, len(paths))
+	resolveErrs := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				resolved[i], resolveErrs[i] = r.NewDeviceFromPath(ctx, paths[i])
+			}
+		}()
+	}
+dispatch:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		devices []BlockDevice
+		errs    *multierror.Error
+		seen    = map[string]bool{}
+	)
+	for i, path := range paths {
+		if err := resolveErrs[i]; err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("unable to resolve the device of '%s': %w", path, err))
+			continue
+		}
+		device := resolved[i]
+		key := device.Syspath()
+		if key == "" {
+			key = device.Devnode()
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		devices = append(devices, device)
+	}
+	return devices, errs.ErrorOrNil()
+}
+
+// DevicesForDirectory returns the physical block devices holding the given
+// directory, resolved through the sysfs mounted at DefaultSysfsRoot. It can't
+// be cancelled, see DeviceResolver.DevicesForDirectory.
+func DevicesForDirectory(path string, fs afero.Fs) ([]BlockDevice, error) {
+	return NewDeviceResolver(fs, DefaultSysfsRoot).DevicesForDirectory(context.Background(), path)
+}
+
+// DevicesForDirectory returns the physical block devices holding the given
+// directory: the device holding it, as returned by NewDeviceFromPath, with
+// its partition resolved to the disk holding it, and stacked devices such as
+// device-mapper devices or md arrays resolved down to their physical devices,
+// each returned once.
+func (r *DeviceResolver) DevicesForDirectory(
+	ctx context.Context, path string,
+) ([]BlockDevice, error) {
+	device, err := r.NewDeviceFromPath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return r.resolvePhysicalDevices(ctx, device)
+}
+
+// NewDeviceFromName returns the block device with the given name, resolved
+// through the sysfs mounted at DefaultSysfsRoot. It can't be cancelled, see
+// DeviceResolver.DeviceFromName.
+func NewDeviceFromName(name string, fs afero.Fs) (BlockDevice, error) {
+	return NewDeviceResolver(fs, DefaultSysfsRoot).DeviceFromName(context.Background(), name)
+}
+
+// DeviceFromName returns the block device with the given name, e.g.
+// 'nvme0n1', or device node, e.g. '/dev/nvme0n1' or a link to it such as
+// '/dev/mapper/vg0-data', looked up in the 'class/block' sysfs directory, or
+// in the 'block' one where kernels don't populate the former. Partitions,
+// e.g. '/dev/nvme0n1p1', are resolved to the disk holding them. Unlike
+// NewDevice it doesn't need the device number, which lets operators name the
+// devices to tune when those can't be resolved from their device number. It
+// fails with a DeviceNotFoundError if the name is not in sysfs.
+func (r *DeviceResolver) DeviceFromName(
+	ctx context.Context, name string,
+) (BlockDevice, error) {
+	if strings.HasPrefix(name, "/dev/") {
+		name = filepath.Base(resolveLink(r.fs, filepath.Clean(name)))
+	}
+	notFound := &DeviceNotFoundError{
+		Name:     name,
+		Searched: []string{r.path("class", "block"), r.path("block")},
+	}
+	if name == "" || name == "." || name == ".." || strings.ContainsRune(name, filepath.Separator) {
+		return nil, notFound
+	}
+	link := r.path("class", "block", name)
+	if exists, _ := afero.Exists(r.fs, link); !exists {
+		syspath, ok := r.sysBlockPath(name)
+		if !ok {
+			return nil, notFound
+		}
+		return r.deviceFromSystemPath(ctx, syspath)
+	}
+	return r.deviceFromSystemPath(ctx, resolveLink(r.fs, link))
+}
+
+// sysBlockPath returns the system path of the device with the given name in
+// the 'block' sysfs directory, which lists the disks, their partitions being
+// in the directory of their disk.
+func (r *DeviceResolver) sysBlockPath(name string) (string, bool) {
+	if path := r.path("block", name); exists(r.fs, path) {
+		return resolveLink(r.fs, path), true
+	}
+	disks, err := afero.ReadDir(r.fs, r.path("block"))
+	if err != nil {
+		return "", false
+	}
+	for _, disk := range disks {
+		diskPath := resolveLink(r.fs, r.path("block", disk.Name()))
+		if path := filepath.Join(diskPath, name); exists(r.fs, filepath.Join(path, "partition")) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// resolveLink returns the target of the link at path, or path itself if it
+// isn't a link or the filesystem doesn't support links.
+func resolveLink(fs afero.Fs, path string) string {
+	target, ok := readLinkIfPossible(fs, path)
+	if !ok {
+		return path
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return filepath.Clean(target)
+}
+
+func exists(fs afero.Fs, path string) bool {
+	ok, _ := afero.Exists(fs, path)
+	return ok
+}
+
+func (r *DeviceResolver) path(elem ...string) string {
+	return filepath.Join(append([]string{r.SysfsRoot}, elem...)...)
+}
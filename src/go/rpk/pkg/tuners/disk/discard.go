@@ -0,0 +1,96 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// SupportsDiscard returns whether the device accepts discards, i.e. TRIM on
+// SATA, UNMAP on SCSI or deallocate on NVMe, as reported by a non-zero discard
+// granularity, see DiscardGranularity.
+func (d *blockDevice) SupportsDiscard() (bool, error) {
+	granularity, err := d.DiscardGranularity()
+	if err != nil {
+		return false, err
+	}
+	return granularity > 0, nil
+}
+
+// DiscardGranularity returns the size in bytes of the smallest range the
+// device can discard, as read from its 'queue/discard_granularity' attribute,
+// or 0 if it doesn't support discards: the attribute is zero or missing, or
+// its 'queue/discard_max_bytes' is zero. Device-mapper only passes discards
+// down if all the layers below support them, so stacked devices support
+// discards only if all their physical devices do, with the largest of their
+// granularities.
+func (d *blockDevice) DiscardGranularity() (uint64, error) {
+	if d.resolver == nil {
+		return 0, nil
+	}
+	slaves, err := readSlaves(d.syspath, d.resolver.fs)
+	if err != nil {
+		return 0, err
+	}
+	if len(slaves) == 0 {
+		return d.leafDiscardGranularity()
+	}
+	physDevices, err := d.resolver.resolvePhysicalDevices(context.Background(), d)
+	if err != nil {
+		return 0, err
+	}
+	var granularity uint64
+	for _, physDevice := range physDevices {
+		leaf, ok := physDevice.(*blockDevice)
+		if !ok {
+			continue
+		}
+		leafGranularity, err := leaf.leafDiscardGranularity()
+		if err != nil {
+			return 0, err
+		}
+		if leafGranularity == 0 {
+			return 0, nil
+		}
+		if leafGranularity > granularity {
+			granularity = leafGranularity
+		}
+	}
+	return granularity, nil
+}
+
+func (d *blockDevice) leafDiscardGranularity() (uint64, error) {
+	maxBytes, err := d.readQueueUint("discard_max_bytes")
+	if err != nil || maxBytes == 0 {
+		return 0, err
+	}
+	return d.readQueueUint("discard_granularity")
+}
+
+// readQueueUint reads the given unsigned integer attribute of the queue of
+// the device, 0 if it doesn't exist.
+func (d *blockDevice) readQueueUint(attribute string) (uint64, error) {
+	path, err := attributePath(d.syspath, filepath.Join("queue", attribute), d.resolver.fs)
+	if err != nil {
+		return 0, err
+	}
+	value, err := readIdentityAttribute(d.resolver.fs, path)
+	if err != nil || value == "" {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse '%s': %v", path, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockDevice_DiscardGranularity(t *testing.T) {
+	writeDiscard := func(fs afero.Fs, device, granularity, maxBytes string) {
+		queue := filepath.Join("/sys/block", device, "queue")
+		afero.WriteFile(fs, filepath.Join(queue, "discard_granularity"), []byte(granularity+"\n"), 0o644)
+		afero.WriteFile(fs, filepath.Join(queue, "discard_max_bytes"), []byte(maxBytes+"\n"), 0o644)
+	}
+	tests := []struct {
+		name            string
+		device          string
+		before          func(afero.Fs)
+		wantGranularity uint64
+		wantErr         bool
+	}{
+		{
+			name:   "shall read the granularity of a device supporting discard",
+			device: "nvme0n1",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeDiscard(fs, "nvme0n1", "512", "2199023255040")
+			},
+			wantGranularity: 512,
+		},
+		{
+			name:   "shall report a zero granularity as no discard support",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeDiscard(fs, "sda", "0", "0")
+			},
+		},
+		{
+			name:   "shall report a zero discard_max_bytes as no discard support",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeDiscard(fs, "sda", "4096", "0")
+			},
+		},
+		{
+			name:   "shall report devices not exposing it as not supporting discard",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+			},
+		},
+		{
+			name:   "shall fail on invalid granularities",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeDiscard(fs, "sda", "-1", "4096")
+			},
+			wantErr: true,
+		},
+		{
+			name:   "shall return the largest granularity of the physical devices",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+				writeDiscard(fs, "dm-0", "512", "4096")
+				writeDiscard(fs, "sda", "512", "4096")
+				writeDiscard(fs, "sdb", "4096", "4096")
+			},
+			wantGranularity: 4096,
+		},
+		{
+			name:   "shall not support discard if a physical device doesn't",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+				writeDiscard(fs, "dm-0", "512", "4096")
+				writeDiscard(fs, "sda", "512", "4096")
+				writeDiscard(fs, "sdb", "0", "0")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			granularity, err := device.DiscardGranularity()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantGranularity, granularity)
+			supported, err := device.SupportsDiscard()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantGranularity > 0, supported)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+// UnknownFilesystem is the type reported for filesystems we don't recognize.
+const UnknownFilesystem = "unknown"
+
+// filesystemMagics are the filesystem types statfs(2) reports by their magic
+// number, see linux/magic.h. We list them here rather than with the unix
+// constants to identify them on any platform in tests.
+var filesystemMagics = map[uint32]string{
+	0x58465342: "xfs",
+	// ext2 and ext3 share the magic of ext4, which mounts them all.
+	0xef53:     "ext4",
+	0x9123683e: "btrfs",
+	0x2fc12fc1: "zfs",
+	0x794c7630: "overlay",
+	0x01021994: "tmpfs",
+	0x858458f6: "ramfs",
+	0xf2f52010: "f2fs",
+	0x6969:     "nfs",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x00c36400: "ceph",
+	0x01021997: "9p",
+	0x6a656a63: "virtiofs",
+	0x65735546: "fuse",
+}
+
+// noBlockDeviceMagics are the magic numbers of the filesystems no block
+// device backs: in memory, overlay and network ones. Their device number is
+// an anonymous one, with no sysfs entry. FUSE is left out, as fuseblk
+// filesystems, e.g. ntfs-3g, have a block device.
+var noBlockDeviceMagics = map[uint32]bool{
+	0x794c7630: true, // overlay
+	0x01021994: true, // tmpfs
+	0x858458f6: true, // ramfs
+	0x6969:     true, // nfs
+	0xff534d42: true, // cifs
+	0xfe534d42: true, // smb2
+	0x00c36400: true, // ceph
+	0x01021997: true, // 9p
+	0x6a656a63: true, // virtiofs
+}
+
+// filesystemFromMagic returns the type of the filesystem with the given
+// statfs(2) magic number, or UnknownFilesystem for exotic ones.
+func filesystemFromMagic(magic uint32) string {
+	if fsType, ok := filesystemMagics[magic]; ok {
+		return fsType
+	}
+	return UnknownFilesystem
+}
+
+// virtualFilesystemType returns the type of the filesystem with the given
+// statfs(2) magic number if no block device backs it, or an empty string
+// otherwise.
+func virtualFilesystemType(magic uint32) string {
+	if noBlockDeviceMagics[magic] {
+		return filesystemFromMagic(magic)
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import "golang.org/x/sys/unix"
+
+// Filesystem returns the type of the filesystem holding path, e.g. 'xfs' or
+// 'ext4', or UnknownFilesystem if it's not one we recognize.
+func Filesystem(path string) (string, error) {
+	var statfs unix.Statfs_t
+	if err := unix.Statfs(path, &statfs); err != nil {
+		return "", err
+	}
+	// The magic numbers are 32 bits, but Type is signed and its width
+	// depends on the architecture.
+	return filesystemFromMagic(uint32(statfs.Type)), nil
+}
+
+// SameFilesystem returns whether both paths are on the same mounted
+// filesystem.
+func SameFilesystem(a, b string) (bool, error) {
+	var statA, statB unix.Stat_t
+	if err := unix.Stat(a, &statA); err != nil {
+		return false, err
+	}
+	if err := unix.Stat(b, &statB); err != nil {
+		return false, err
+	}
+	return statA.Dev == statB.Dev, nil
+}
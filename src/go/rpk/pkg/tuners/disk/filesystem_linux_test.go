@@ -0,0 +1,42 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystem(t *testing.T) {
+	dir := t.TempDir()
+	fsType, err := Filesystem(dir)
+	require.NoError(t, err)
+	require.NotEmpty(t, fsType)
+
+	_, err = Filesystem(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+func TestSameFilesystem(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "cloud_storage_cache")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+	same, err := SameFilesystem(dir, sub)
+	require.NoError(t, err)
+	require.True(t, same)
+
+	// procfs is always a mount of its own.
+	same, err = SameFilesystem(dir, "/proc")
+	require.NoError(t, err)
+	require.False(t, same)
+}
@@ -0,0 +1,26 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !linux
+
+package disk
+
+import "errors"
+
+var errFilesystemUnsupported = errors.New("filesystem detection is only available on Linux")
+
+// Filesystem is only available on Linux.
+func Filesystem(string) (string, error) {
+	return "", errFilesystemUnsupported
+}
+
+// SameFilesystem is only available on Linux.
+func SameFilesystem(string, string) (bool, error) {
+	return false, errFilesystemUnsupported
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_filesystemFromMagic(t *testing.T) {
+	for _, tt := range []struct {
+		magic uint32
+		want  string
+	}{
+		{0x58465342, "xfs"},
+		{0xef53, "ext4"},
+		{0x9123683e, "btrfs"},
+		{0x2fc12fc1, "zfs"},
+		{0x794c7630, "overlay"},
+		{0x01021994, "tmpfs"},
+		{0x6969, "nfs"},
+		// hfs, as reported on macOS.
+		{0x4244, UnknownFilesystem},
+		{0, UnknownFilesystem},
+	} {
+		require.Equal(t, tt.want, filesystemFromMagic(tt.magic), "magic 0x%x", tt.magic)
+	}
+}
+
+func Test_filesystemFromMagic_signedType(t *testing.T) {
+	// statfs(2) reports btrfs as a negative int32 on 32 bit architectures.
+	var magic int32 = -0x6edc97c2
+	require.Equal(t, "btrfs", filesystemFromMagic(uint32(magic)))
+}
+
+func Test_virtualFilesystemType(t *testing.T) {
+	for _, tt := range []struct {
+		magic uint32
+		want  string
+	}{
+		// TMPFS_MAGIC and NFS_SUPER_MAGIC.
+		{0x01021994, "tmpfs"},
+		{0x6969, "nfs"},
+		{0x794c7630, "overlay"},
+		{0xff534d42, "cifs"},
+		// Filesystems on block devices, including fuseblk ones.
+		{0x58465342, ""},
+		{0xef53, ""},
+		{0x65735546, ""},
+	} {
+		require.Equal(t, tt.want, virtualFilesystemType(tt.magic), "magic 0x%x", tt.magic)
+	}
+}
+
+func TestNotBlockDeviceError(t *testing.T) {
+	var err error = &NotBlockDeviceError{Path: "/mnt/data", FsType: "nfs4", Detail: "mounted from '10.0.0.1:/export'"}
+	require.ErrorIs(t, err, ErrNotBlockDevice)
+	require.NotErrorIs(t, err, ErrSysfsUnavailable)
+	require.EqualError(t, err, "no block device backs the filesystem: '/mnt/data' is on nfs4 mounted from '10.0.0.1:/export'")
+
+	var notBlockErr *NotBlockDeviceError
+	require.ErrorAs(t, fmt.Errorf("unable to resolve: %w", err), &notBlockErr)
+	require.Equal(t, "nfs4", notBlockErr.FsType)
+}
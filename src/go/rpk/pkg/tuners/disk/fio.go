@@ -0,0 +1,213 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FioBin is the fio executable, looked up in the PATH.
+const FioBin = "fio"
+
+// The names of the fio jobs, as reported in its output.
+const (
+	fioSequentialJob = "sequential-write"
+	fioRandomJob     = "random-mix"
+)
+
+// FioJob is the bounded benchmark run by RunFio: large sequential writes to
+// measure the bandwidth, then small random reads and writes to measure the
+// IOPS and latency, each for half the duration.
+type FioJob struct {
+	// Size is the size of the file written by each phase, in bytes.
+	Size int64
+	// Duration is the total duration of the benchmark.
+	Duration time.Duration
+	// ReadPercent is the percentage of reads of the random phase.
+	ReadPercent int
+}
+
+// DefaultFioJob is the fio job run when none is given, short enough to be
+// run along the other checks.
+var DefaultFioJob = FioJob{
+	Size:        1 << 30,
+	Duration:    30 * time.Second,
+	ReadPercent: 50,
+}
+
+// FioStats is the throughput and latency of the reads or the writes of a fio
+// phase. Bandwidths are in bytes per second.
+type FioStats struct {
+	Iops      int64 `json:"iops"`
+	Bandwidth int64 `json:"bandwidth"`
+	// The latencies are in nanoseconds in the JSON output.
+	MeanLatency time.Duration `json:"mean_latency_ns"`
+	P99Latency  time.Duration `json:"p99_latency_ns"`
+}
+
+// FioPhase is the result of a phase of the benchmark.
+type FioPhase struct {
+	Read  FioStats `json:"read"`
+	Write FioStats `json:"write"`
+}
+
+// FioResult is the result of RunFio. If fio isn't installed, the benchmark
+// isn't run and Available is false.
+type FioResult struct {
+	Available  bool     `json:"available"`
+	Sequential FioPhase `json:"sequential"`
+	Random     FioPhase `json:"random"`
+}
+
+// RunFio runs the fio job against directory, with fio writing its files to a
+// temporary directory within it, which is removed once done, even if fio
+// fails or ctx is cancelled, which kills it. Direct IO is used, so the
+// filesystem must support O_DIRECT.
+func RunFio(ctx context.Context, directory string, job FioJob) (*FioResult, error) {
+	bin, err := exec.LookPath(FioBin)
+	if err != nil {
+		log.Debugf("Unable to find '%s': %v", FioBin, err)
+		return &FioResult{}, nil
+	}
+	if job.Size <= 0 || job.Duration <= 0 {
+		return nil, fmt.Errorf("invalid fio job, size and duration must be positive: %+v", job)
+	}
+	if job.ReadPercent < 0 || job.ReadPercent > 100 {
+		return nil, fmt.Errorf("invalid fio job, the read percentage must be between 0 and 100, got %d", job.ReadPercent)
+	}
+	dir, err := os.MkdirTemp(directory, ".rpk-fio-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the fio directory in '%s': %w", directory, err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Warnf("Unable to remove the fio directory '%s': %v", dir, err)
+		}
+	}()
+
+	args := fioArgs(dir, job)
+	log.Debugf("Running command '%s' with arguments '%s'", bin, args)
+	cmd := exec.CommandContext(ctx, bin, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("fio cancelled: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("fio failed: %v, stderr=%s", err, stderr.String())
+	}
+	return parseFioOutput(stdout.Bytes())
+}
+
+// fioArgs returns the command line of the job, each phase waiting for the
+// previous one to complete.
+func fioArgs(dir string, job FioJob) []string {
+	engine := "posixaio"
+	if runtime.GOOS == "linux" {
+		engine = "libaio"
+	}
+	seconds := int64((job.Duration / 2).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return []string{
+		"--output-format=json",
+		"--directory=" + dir,
+		"--size=" + strconv.FormatInt(job.Size, 10),
+		"--runtime=" + strconv.FormatInt(seconds, 10),
+		"--time_based",
+		"--ioengine=" + engine,
+		"--direct=1",
+		"--stonewall",
+		"--name=" + fioSequentialJob,
+		"--rw=write",
+		"--bs=1M",
+		"--iodepth=4",
+		"--name=" + fioRandomJob,
+		"--rw=randrw",
+		"--rwmixread=" + strconv.Itoa(job.ReadPercent),
+		"--bs=4k",
+		"--iodepth=32",
+	}
+}
+
+// fioOutput is the subset of fio's JSON output read by parseFioOutput.
+type fioOutput struct {
+	Jobs []struct {
+		Name  string       `json:"jobname"`
+		Error int          `json:"error"`
+		Read  fioJobOutput `json:"read"`
+		Write fioJobOutput `json:"write"`
+	} `json:"jobs"`
+}
+
+type fioJobOutput struct {
+	Iops    float64 `json:"iops"`
+	BwBytes int64   `json:"bw_bytes"`
+	ClatNs  fioLat  `json:"clat_ns"`
+	LatNs   fioLat  `json:"lat_ns"`
+}
+
+type fioLat struct {
+	Mean        float64            `json:"mean"`
+	Percentiles map[string]float64 `json:"percentile"`
+}
+
+func (s fioJobOutput) stats() FioStats {
+	return FioStats{
+		Iops:        int64(s.Iops),
+		Bandwidth:   s.BwBytes,
+		MeanLatency: time.Duration(s.LatNs.Mean),
+		P99Latency:  time.Duration(s.ClatNs.Percentiles["99.000000"]),
+	}
+}
+
+// parseFioOutput parses the JSON output of the job run by RunFio. fio may
+// print warnings before it, which are skipped.
+func parseFioOutput(out []byte) (*FioResult, error) {
+	start := bytes.IndexByte(out, '{')
+	if start < 0 {
+		return nil, errors.New("unable to find the JSON output of fio")
+	}
+	var output fioOutput
+	if err := json.Unmarshal(out[start:], &output); err != nil {
+		return nil, fmt.Errorf("unable to parse the output of fio: %v", err)
+	}
+	result := &FioResult{Available: true}
+	var sequential, random bool
+	for _, job := range output.Jobs {
+		if job.Error != 0 {
+			return nil, fmt.Errorf("fio job '%s' failed with error %d", job.Name, job.Error)
+		}
+		phase := FioPhase{Read: job.Read.stats(), Write: job.Write.stats()}
+		switch job.Name {
+		case fioSequentialJob:
+			result.Sequential, sequential = phase, true
+		case fioRandomJob:
+			result.Random, random = phase, true
+		}
+	}
+	if !sequential || !random {
+		return nil, errors.New("missing jobs in the output of fio")
+	}
+	return result, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fioSample is the output of fio 3.28 running the job of fioArgs, trimmed of
+// the fields parseFioOutput doesn't read.
+const fioSample = `fio: native_fallocate call failed: Operation not supported
+{
+  "fio version" : "fio-3.28",
+  "timestamp" : 1684327210,
+  "time" : "Wed May 17 12:40:10 2023",
+  "global options" : {
+    "directory" : "/var/lib/redpanda/data/.rpk-fio-123",
+    "size" : "1073741824",
+    "runtime" : "15",
+    "ioengine" : "libaio",
+    "direct" : "1",
+    "stonewall" : "1"
+  },
+  "jobs" : [
+    {
+      "jobname" : "sequential-write",
+      "groupid" : 0,
+      "error" : 0,
+      "read" : {
+        "io_bytes" : 0,
+        "bw_bytes" : 0,
+        "iops" : 0.000000,
+        "total_ios" : 0,
+        "clat_ns" : {
+          "min" : 0,
+          "max" : 0,
+          "mean" : 0.000000
+        },
+        "lat_ns" : {
+          "min" : 0,
+          "max" : 0,
+          "mean" : 0.000000
+        }
+      },
+      "write" : {
+        "io_bytes" : 15934160896,
+        "bw_bytes" : 1062277393,
+        "iops" : 1013.065796,
+        "total_ios" : 15196,
+        "clat_ns" : {
+          "min" : 1722216,
+          "max" : 13329065,
+          "mean" : 3941277.503224,
+          "percentile" : {
+            "50.000000" : 3817472,
+            "90.000000" : 4620288,
+            "99.000000" : 6651904,
+            "99.900000" : 10420224
+          }
+        },
+        "lat_ns" : {
+          "min" : 1736000,
+          "max" : 13349961,
+          "mean" : 3948632.133062
+        }
+      }
+    },
+    {
+      "jobname" : "random-mix",
+      "groupid" : 1,
+      "error" : 0,
+      "read" : {
+        "io_bytes" : 3655077888,
+        "bw_bytes" : 243666666,
+        "iops" : 59489.908673,
+        "total_ios" : 892353,
+        "clat_ns" : {
+          "min" : 69619,
+          "max" : 2855478,
+          "mean" : 268341.417846,
+          "percentile" : {
+            "50.000000" : 254976,
+            "90.000000" : 354304,
+            "99.000000" : 514048,
+            "99.900000" : 831488
+          }
+        },
+        "lat_ns" : {
+          "min" : 71476,
+          "max" : 2857522,
+          "mean" : 269075.306841
+        }
+      },
+      "write" : {
+        "io_bytes" : 3654770688,
+        "bw_bytes" : 243646185,
+        "iops" : 59484.908006,
+        "total_ios" : 892278,
+        "clat_ns" : {
+          "min" : 15519,
+          "max" : 2562688,
+          "mean" : 267221.682719,
+          "percentile" : {
+            "50.000000" : 252928,
+            "90.000000" : 350208,
+            "99.000000" : 509952,
+            "99.900000" : 823296
+          }
+        },
+        "lat_ns" : {
+          "min" : 17250,
+          "max" : 2564795,
+          "mean" : 268104.713598
+        }
+      }
+    }
+  ],
+  "disk_util" : [
+    {
+      "name" : "nvme0n1",
+      "util" : 99.350862
+    }
+  ]
+}
+`
+
+func TestParseFioOutput(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		out    string
+		exp    *FioResult
+		expErr bool
+	}{
+		{
+			name: "captured sample",
+			out:  fioSample,
+			exp: &FioResult{
+				Available: true,
+				Sequential: FioPhase{
+					Write: FioStats{
+						Iops:        1013,
+						Bandwidth:   1062277393,
+						MeanLatency: 3948632,
+						P99Latency:  6651904,
+					},
+				},
+				Random: FioPhase{
+					Read: FioStats{
+						Iops:        59489,
+						Bandwidth:   243666666,
+						MeanLatency: 269075,
+						P99Latency:  514048,
+					},
+					Write: FioStats{
+						Iops:        59484,
+						Bandwidth:   243646185,
+						MeanLatency: 268104,
+						P99Latency:  509952,
+					},
+				},
+			},
+		},
+		{
+			name:   "no JSON",
+			out:    "fio: failed to create file",
+			expErr: true,
+		},
+		{
+			name:   "missing job",
+			out:    `{"jobs": [{"jobname": "sequential-write", "error": 0}]}`,
+			expErr: true,
+		},
+		{
+			name:   "failed job",
+			out:    `{"jobs": [{"jobname": "sequential-write", "error": 22}, {"jobname": "random-mix", "error": 0}]}`,
+			expErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := parseFioOutput([]byte(test.out))
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestRunFioNotAvailable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	dir := t.TempDir()
+	res, err := RunFio(context.Background(), dir, DefaultFioJob)
+	require.NoError(t, err)
+	require.False(t, res.Available)
+	// Nothing is written if fio isn't installed.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestFioArgs(t *testing.T) {
+	args := fioArgs("/data/.rpk-fio-1", FioJob{Size: 1 << 20, Duration: 10 * time.Second, ReadPercent: 70})
+	require.Contains(t, args, "--output-format=json")
+	require.Contains(t, args, "--directory=/data/.rpk-fio-1")
+	require.Contains(t, args, "--size=1048576")
+	require.Contains(t, args, "--runtime=5")
+	require.Contains(t, args, "--rwmixread=70")
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// DefaultIoProbeDuration is how long the IO probe runs for when no duration
+// is given, split evenly between its phases.
+const DefaultIoProbeDuration = 2 * time.Minute
+
+// IoMeasurement is the maximum throughput the IO probe measured, in
+// operations and bytes per second.
+type IoMeasurement struct {
+	ReadIops       int64
+	ReadBandwidth  int64
+	WriteIops      int64
+	WriteBandwidth int64
+}
+
+// ioProbe is the access pattern of the IO probe: IOPS are measured with
+// small random requests and the bandwidth with large sequential ones, each
+// with enough requests in flight to saturate the device.
+type ioProbe struct {
+	fileSize            int64
+	iopsBlockSize       int
+	iopsQueueDepth      int
+	bandwidthBlockSize  int
+	bandwidthQueueDepth int
+}
+
+var defaultIoProbe = ioProbe{
+	fileSize:            1 << 30,
+	iopsBlockSize:       4096,
+	iopsQueueDepth:      32,
+	bandwidthBlockSize:  1 << 20,
+	bandwidthQueueDepth: 4,
+}
+
+// ProbeIo measures the maximum read and write IOPS and bandwidth of the
+// device holding directory, like Seastar's iotune does. The requests go to a
+// temporary file, opened with O_DIRECT where supported so they bypass the
+// page cache, which is removed once done, even if the probe fails.
+func ProbeIo(
+	ctx context.Context, fs afero.Fs, directory string, duration time.Duration,
+) (*IoMeasurement, error) {
+	return defaultIoProbe.run(ctx, fs, directory, duration)
+}
+
+func (p ioProbe) run(
+	ctx context.Context, fs afero.Fs, directory string, duration time.Duration,
+) (measurement *IoMeasurement, err error) {
+	if duration <= 0 {
+		duration = DefaultIoProbeDuration
+	}
+	file, err := afero.TempFile(fs, directory, ".rpk-disk-iotune-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the probe file in '%s': %w", directory, err)
+	}
+	name := file.Name()
+	defer func() {
+		if removeErr := fs.Remove(name); err == nil {
+			err = removeErr
+		}
+	}()
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	flag := os.O_RDWR | directIoFlag
+	if probe, err := fs.OpenFile(name, flag, 0); err != nil {
+		if !errors.Is(err, syscall.EINVAL) {
+			return nil, err
+		}
+		// Some filesystems, e.g. tmpfs, don't support direct IO.
+		log.Warnf("'%s' doesn't support direct IO, the page cache will inflate the measurement", directory)
+		flag = os.O_RDWR
+	} else if err := probe.Close(); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Filling the probe file '%s' with %d bytes", name, p.fileSize)
+	if _, _, err := p.phase(ctx, fs, name, flag, true, false, p.fileSize); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("unable to fill the probe file '%s': %w", name, err)
+	}
+	phase := duration / 4
+	measurement = &IoMeasurement{}
+	log.Debugf("Probing the write bandwidth of '%s' for %s", name, phase)
+	_, measurement.WriteBandwidth, err = p.timedPhase(ctx, fs, name, flag, true, false, phase)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Probing the write IOPS of '%s' for %s", name, phase)
+	measurement.WriteIops, _, err = p.timedPhase(ctx, fs, name, flag, true, true, phase)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Probing the read bandwidth of '%s' for %s", name, phase)
+	_, measurement.ReadBandwidth, err = p.timedPhase(ctx, fs, name, flag, false, false, phase)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Probing the read IOPS of '%s' for %s", name, phase)
+	measurement.ReadIops, _, err = p.timedPhase(ctx, fs, name, flag, false, true, phase)
+	if err != nil {
+		return nil, err
+	}
+	return measurement, nil
+}
+
+// timedPhase issues requests for the given duration and returns their rate,
+// in operations and bytes per second.
+func (p ioProbe) timedPhase(
+	ctx context.Context,
+	fs afero.Fs,
+	name string,
+	flag int,
+	write, random bool,
+	duration time.Duration,
+) (iops, bandwidth int64, err error) {
+	phaseCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	start := time.Now()
+	ops, bytes, err := p.phase(phaseCtx, fs, name, flag, write, random, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	// The phase ends once its own deadline is exceeded, not the parent's.
+	if err := ctx.Err(); err != nil {
+		return 0, 0, fmt.Errorf("unable to probe '%s': %w", name, err)
+	}
+	elapsed := time.Since(start).Seconds()
+	return int64(float64(ops) / elapsed), int64(float64(bytes) / elapsed), nil
+}
+
+// phase issues requests to the probe file from as many workers as the queue
+// depth until ctx is done or, if limit is positive, until limit bytes were
+// transferred. It returns the number of requests and bytes transferred.
+func (p ioProbe) phase(
+	ctx context.Context,
+	fs afero.Fs,
+	name string,
+	flag int,
+	write, random bool,
+	limit int64,
+) (ops, bytes int64, err error) {
+	blockSize, queueDepth := p.bandwidthBlockSize, p.bandwidthQueueDepth
+	if random {
+		blockSize, queueDepth = p.iopsBlockSize, p.iopsQueueDepth
+	}
+	blocks := p.fileSize / int64(blockSize)
+	var (
+		cursor   int64
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < queueDepth; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			if err := func() error {
+				file, err := fs.OpenFile(name, flag, 0)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				block := alignedBlock(blockSize)
+				rng := rand.New(rand.NewSource(seed))
+				for ctx.Err() == nil {
+					var offset int64
+					if random {
+						offset = rng.Int63n(blocks) * int64(blockSize)
+					} else {
+						next := atomic.AddInt64(&cursor, int64(blockSize)) - int64(blockSize)
+						if limit > 0 && next >= limit {
+							return nil
+						}
+						offset = next % (blocks * int64(blockSize))
+					}
+					var n int
+					if write {
+						n, err = file.WriteAt(block, offset)
+					} else {
+						n, err = file.ReadAt(block, offset)
+					}
+					if err != nil {
+						return fmt.Errorf("unable to access '%s' at offset %d: %w", name, offset, err)
+					}
+					atomic.AddInt64(&ops, 1)
+					atomic.AddInt64(&bytes, int64(n))
+				}
+				return nil
+			}(); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return 0, 0, firstErr
+	}
+	return ops, bytes, nil
+}
+
+// alignedBlock returns a buffer of size bytes aligned to the page size, as
+// direct IO requires.
+func alignedBlock(size int) []byte {
+	const alignment = 4096
+	buf := make([]byte, size+alignment)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&buf[0])) & (alignment - 1)); rem != 0 {
+		offset = alignment - rem
+	}
+	return buf[offset : offset+size]
+}
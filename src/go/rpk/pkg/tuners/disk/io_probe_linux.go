@@ -0,0 +1,15 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import "syscall"
+
+// directIoFlag opens files for direct IO, bypassing the page cache.
+const directIoFlag = syscall.O_DIRECT
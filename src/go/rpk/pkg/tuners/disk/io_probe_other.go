@@ -0,0 +1,16 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !linux
+
+package disk
+
+// directIoFlag is unset, direct IO isn't requested through open flags on
+// this platform.
+const directIoFlag = 0
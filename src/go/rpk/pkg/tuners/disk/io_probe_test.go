@@ -0,0 +1,100 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// testIoProbe keeps the probe file small enough for an in-memory filesystem.
+var testIoProbe = ioProbe{
+	fileSize:            1 << 20,
+	iopsBlockSize:       4096,
+	iopsQueueDepth:      4,
+	bandwidthBlockSize:  64 << 10,
+	bandwidthQueueDepth: 2,
+}
+
+// noDirectIoFs is a filesystem rejecting direct IO like tmpfs does, and
+// whose files fail to be read if failReads is set.
+type noDirectIoFs struct {
+	afero.Fs
+	failReads bool
+}
+
+type failingReadFile struct {
+	afero.File
+}
+
+func (f *noDirectIoFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if directIoFlag != 0 && flag&directIoFlag != 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EINVAL}
+	}
+	file, err := f.Fs.OpenFile(name, flag, perm)
+	if err != nil || !f.failReads {
+		return file, err
+	}
+	return &failingReadFile{file}, nil
+}
+
+func (*failingReadFile) ReadAt([]byte, int64) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestProbeIo(t *testing.T) {
+	for _, fs := range []afero.Fs{
+		afero.NewMemMapFs(),
+		&noDirectIoFs{Fs: afero.NewMemMapFs()},
+	} {
+		fs.MkdirAll("/var/lib/redpanda/data", 0o755)
+		measurement, err := testIoProbe.run(context.Background(), fs, "/var/lib/redpanda/data", 40*time.Millisecond)
+		require.NoError(t, err)
+		require.Positive(t, measurement.ReadIops)
+		require.Positive(t, measurement.ReadBandwidth)
+		require.Positive(t, measurement.WriteIops)
+		require.Positive(t, measurement.WriteBandwidth)
+		files, err := afero.ReadDir(fs, "/var/lib/redpanda/data")
+		require.NoError(t, err)
+		require.Empty(t, files)
+	}
+}
+
+func TestProbeIo_cleans_up_on_error(t *testing.T) {
+	fs := &noDirectIoFs{Fs: afero.NewMemMapFs(), failReads: true}
+	fs.MkdirAll("/var/lib/redpanda/data", 0o755)
+	_, err := testIoProbe.run(context.Background(), fs, "/var/lib/redpanda/data", 40*time.Millisecond)
+	require.ErrorContains(t, err, "read failed")
+	files, err := afero.ReadDir(fs, "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	_, err = testIoProbe.run(context.Background(), fs, "/missing", time.Second)
+	require.Error(t, err)
+}
+
+func TestProbeIo_cancelled(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/var/lib/redpanda/data", 0o755)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := testIoProbe.run(ctx, fs, "/var/lib/redpanda/data", time.Second)
+	require.ErrorIs(t, err, context.Canceled)
+	files, err := afero.ReadDir(fs, "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
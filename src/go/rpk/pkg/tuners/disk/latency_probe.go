@@ -0,0 +1,104 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// DefaultProbeDuration is how long the write latency probe runs for
+	// when no duration is given.
+	DefaultProbeDuration = 3 * time.Second
+	// probeBlockSize is the size of each write of the probe, the smallest
+	// one most devices handle without a read-modify-write.
+	probeBlockSize = 4096
+	// probeFileSize bounds the size of the probe file, writes wrap around
+	// once it's reached.
+	probeFileSize = 16 << 20
+)
+
+// WriteLatency is the latency distribution of the synchronous writes of a
+// latency probe.
+type WriteLatency struct {
+	Samples int
+	P50     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// ProbeWriteLatency measures how long a write followed by an fsync takes in
+// directory, the access pattern of the Redpanda log, by issuing them back to
+// back for the given duration. The writes go to a temporary file which is
+// removed once done, even if the probe fails.
+func ProbeWriteLatency(
+	fs afero.Fs, directory string, duration time.Duration,
+) (latency *WriteLatency, err error) {
+	if duration <= 0 {
+		duration = DefaultProbeDuration
+	}
+	file, err := afero.TempFile(fs, directory, ".rpk-disk-benchmark-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the probe file in '%s': %w", directory, err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if removeErr := fs.Remove(file.Name()); err == nil {
+			err = closeErr
+			if err == nil {
+				err = removeErr
+			}
+		}
+	}()
+	log.Debugf("Probing the write latency of '%s' for %s", file.Name(), duration)
+
+	block := make([]byte, probeBlockSize)
+	var samples []time.Duration
+	var offset int64
+	for deadline := time.Now().Add(duration); time.Now().Before(deadline); {
+		start := time.Now()
+		if _, err := file.WriteAt(block, offset); err != nil {
+			return nil, fmt.Errorf("unable to write to '%s': %w", file.Name(), err)
+		}
+		if err := file.Sync(); err != nil {
+			return nil, fmt.Errorf("unable to sync '%s': %w", file.Name(), err)
+		}
+		samples = append(samples, time.Since(start))
+		offset = (offset + probeBlockSize) % probeFileSize
+	}
+	return newWriteLatency(samples), nil
+}
+
+func newWriteLatency(samples []time.Duration) *WriteLatency {
+	latency := &WriteLatency{Samples: len(samples)}
+	if len(samples) == 0 {
+		return latency
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	latency.P50 = percentile(samples, 0.5)
+	latency.P99 = percentile(samples, 0.99)
+	latency.Max = samples[len(samples)-1]
+	return latency
+}
+
+// percentile returns the nearest-rank percentile p of the sorted samples.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
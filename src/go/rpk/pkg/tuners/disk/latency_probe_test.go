@@ -0,0 +1,81 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSyncFs is a filesystem whose files fail to sync.
+type failingSyncFs struct {
+	afero.Fs
+}
+
+type failingSyncFile struct {
+	afero.File
+}
+
+func (f *failingSyncFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	file, err := f.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &failingSyncFile{file}, nil
+}
+
+func (*failingSyncFile) Sync() error {
+	return errors.New("sync failed")
+}
+
+func TestProbeWriteLatency(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/var/lib/redpanda/data", 0o755)
+	latency, err := ProbeWriteLatency(fs, "/var/lib/redpanda/data", 20*time.Millisecond)
+	require.NoError(t, err)
+	require.Positive(t, latency.Samples)
+	require.LessOrEqual(t, latency.P50, latency.P99)
+	require.LessOrEqual(t, latency.P99, latency.Max)
+	files, err := afero.ReadDir(fs, "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestProbeWriteLatency_cleans_up_on_error(t *testing.T) {
+	fs := &failingSyncFs{afero.NewMemMapFs()}
+	fs.MkdirAll("/var/lib/redpanda/data", 0o755)
+	_, err := ProbeWriteLatency(fs, "/var/lib/redpanda/data", time.Second)
+	require.Error(t, err)
+	files, err := afero.ReadDir(fs, "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	_, err = ProbeWriteLatency(fs, "/missing", time.Second)
+	require.Error(t, err)
+}
+
+func Test_newWriteLatency(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i > 0; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	require.Equal(t, &WriteLatency{
+		Samples: 100,
+		P50:     50 * time.Millisecond,
+		P99:     99 * time.Millisecond,
+		Max:     100 * time.Millisecond,
+	}, newWriteLatency(samples))
+	require.Equal(t, &WriteLatency{}, newWriteLatency(nil))
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// MdArray describes a Linux software RAID (md) array.
+type MdArray struct {
+	// RAID level, e.g. 'raid0' or 'raid10'.
+	Level string
+	// Chunk size in bytes.
+	ChunkSize uint64
+	// Number of member devices the array is configured with.
+	Disks int
+}
+
+// StripeCount returns the number of members a full stripe of data is spread
+// across, which is 1 for arrays that don't stripe data.
+func (a *MdArray) StripeCount() int {
+	count := 1
+	switch a.Level {
+	case "raid0":
+		count = a.Disks
+	case "raid10":
+		// Using the default 'near' layout with 2 copies of each chunk.
+		count = a.Disks / 2
+	}
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// StripeWidth returns the size in bytes of a full stripe of data.
+func (a *MdArray) StripeWidth() uint64 {
+	return a.ChunkSize * uint64(a.StripeCount())
+}
+
+// mdArrayFromSystemPath returns the md array details of the device at
+// syspath, or nil if the device is not an md array.
+func mdArrayFromSystemPath(syspath string, fs afero.Fs) (*MdArray, error) {
+	mdDir := filepath.Join(syspath, "md")
+	if exists, _ := afero.DirExists(fs, mdDir); !exists {
+		return nil, nil
+	}
+	log.Debugf("Device '%s' is an md array", filepath.Base(syspath))
+	array := &MdArray{}
+	level, err := readMdAttribute(fs, mdDir, "level")
+	if err != nil {
+		return nil, err
+	}
+	array.Level = level
+	if chunkSize, err := readMdAttribute(fs, mdDir, "chunk_size"); err != nil {
+		return nil, err
+	} else if chunkSize != "" {
+		array.ChunkSize, err = strconv.ParseUint(chunkSize, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse md chunk size of '%s': %v", syspath, err)
+		}
+	}
+	if disks, err := readMdAttribute(fs, mdDir, "raid_disks"); err != nil {
+		return nil, err
+	} else if disks != "" {
+		array.Disks, err = strconv.Atoi(disks)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse md disks of '%s': %v", syspath, err)
+		}
+	} else {
+		// Older kernels don't expose the configured number of disks.
+		slaves, err := readSlaves(syspath, fs)
+		if err != nil {
+			return nil, err
+		}
+		array.Disks = len(slaves)
+	}
+	return array, nil
+}
+
+// readMdAttribute returns the value of the given md attribute, or an empty
+// string if the attribute is not present.
+func readMdAttribute(fs afero.Fs, mdDir, attribute string) (string, error) {
+	path := filepath.Join(mdDir, attribute)
+	if exists, _ := afero.Exists(fs, path); !exists {
+		return "", nil
+	}
+	return readSysfsString(fs, path)
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFakeMdArray(fs afero.Fs, name, level string, disks string, members ...string) {
+	writeFakeStackedDevice(fs, name, members...)
+	mdDir := filepath.Join("/sys/block", name, "md")
+	fs.MkdirAll(mdDir, 0o755)
+	afero.WriteFile(fs, filepath.Join(mdDir, "level"), []byte(level+"\n"), 0o644)
+	afero.WriteFile(fs, filepath.Join(mdDir, "chunk_size"), []byte("524288\n"), 0o644)
+	if disks != "" {
+		afero.WriteFile(fs, filepath.Join(mdDir, "raid_disks"), []byte(disks+"\n"), 0o644)
+	}
+}
+
+func TestMdArray(t *testing.T) {
+	tests := []struct {
+		name            string
+		level           string
+		disks           string
+		members         []string
+		wantStripes     int
+		wantStripeWidth uint64
+		wantDevices     []string
+	}{
+		{
+			name:            "shall stripe raid0 across all its members",
+			level:           "raid0",
+			disks:           "4",
+			members:         []string{"sda", "sdb", "sdc", "sdd"},
+			wantStripes:     4,
+			wantStripeWidth: 4 * 524288,
+			wantDevices:     []string{"/dev/sda", "/dev/sdb", "/dev/sdc", "/dev/sdd"},
+		},
+		{
+			name:            "shall stripe raid10 across half its members",
+			level:           "raid10",
+			disks:           "4",
+			members:         []string{"sda", "sdb", "sdc", "sdd"},
+			wantStripes:     2,
+			wantStripeWidth: 2 * 524288,
+			wantDevices:     []string{"/dev/sda", "/dev/sdb", "/dev/sdc", "/dev/sdd"},
+		},
+		{
+			name:            "shall not stripe raid1",
+			level:           "raid1",
+			members:         []string{"sda", "sdb"},
+			wantStripes:     1,
+			wantStripeWidth: 524288,
+			wantDevices:     []string{"/dev/sda", "/dev/sdb"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeFakeMdArray(fs, "md0", tt.level, tt.disks, tt.members...)
+			for _, member := range tt.members {
+				writeFakeStackedDevice(fs, member)
+			}
+			resolver := NewDeviceResolver(fs, "")
+			device, err := resolver.deviceFromSystemPath(context.Background(), "/sys/block/md0")
+			require.NoError(t, err)
+			require.NotNil(t, device.Md())
+			require.Equal(t, tt.level, device.Md().Level)
+			require.Equal(t, tt.wantStripes, device.Md().StripeCount())
+			require.Equal(t, tt.wantStripeWidth, device.Md().StripeWidth())
+
+			physDevices, err := resolver.resolvePhysicalDevices(context.Background(), device)
+			require.NoError(t, err)
+			var devnodes []string
+			for _, d := range physDevices {
+				devnodes = append(devnodes, d.Devnode())
+			}
+			require.Equal(t, tt.wantDevices, devnodes)
+		})
+	}
+}
+
+func TestMdArray_degraded(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeFakeMdArray(fs, "md0", "raid1", "2", "sda", "sdb")
+	writeFakeStackedDevice(fs, "sda")
+	resolver := NewDeviceResolver(fs, "")
+	device, err := resolver.deviceFromSystemPath(context.Background(), "/sys/block/md0")
+	require.NoError(t, err)
+	physDevices, err := resolver.resolvePhysicalDevices(context.Background(), device)
+	require.NoError(t, err)
+	require.Len(t, physDevices, 1)
+	require.Equal(t, "/dev/sda", physDevices[0].Devnode())
+}
+
+func TestMdArray_nested(t *testing.T) {
+	tests := []struct {
+		name   string
+		device string
+		before func(afero.Fs)
+		want   map[string]string
+	}{
+		{
+			name:   "shall resolve LVM on top of md",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "md0")
+				writeFakeMdArray(fs, "md0", "raid0", "2", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+			},
+			want: map[string]string{"/dev/sda": "raid0", "/dev/sdb": "raid0"},
+		},
+		{
+			name:   "shall resolve md on top of LUKS",
+			device: "md0",
+			before: func(fs afero.Fs) {
+				writeFakeMdArray(fs, "md0", "raid1", "2", "dm-0", "dm-1")
+				writeFakeStackedDevice(fs, "dm-0", "sda")
+				writeFakeStackedDevice(fs, "dm-1", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+			},
+			want: map[string]string{"/dev/sda": "raid1", "/dev/sdb": "raid1"},
+		},
+		{
+			name:   "shall not carry a RAID level without an md array",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda")
+				writeFakeStackedDevice(fs, "sda")
+			},
+			want: map[string]string{"/dev/sda": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			resolver := NewDeviceResolver(fs, "")
+			device, err := resolver.deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			physDevices, err := resolver.resolvePhysicalDevices(context.Background(), device)
+			require.NoError(t, err)
+			got := map[string]string{}
+			for _, d := range physDevices {
+				got[d.Devnode()] = d.RaidLevel()
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMdArray_cycle(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeFakeMdArray(fs, "md0", "raid1", "1", "dm-0")
+	writeFakeStackedDevice(fs, "dm-0", "md0")
+	resolver := NewDeviceResolver(fs, "")
+	device, err := resolver.deviceFromSystemPath(context.Background(), "/sys/block/md0")
+	require.NoError(t, err)
+	_, err = resolver.resolvePhysicalDevices(context.Background(), device)
+	require.Error(t, err)
+}
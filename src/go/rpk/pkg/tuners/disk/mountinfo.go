@@ -0,0 +1,253 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
+	"github.com/spf13/afero"
+)
+
+// DefaultMountInfoPath is the mount table of the rpk mount namespace.
+const DefaultMountInfoPath = "/proc/self/mountinfo"
+
+// mountInfo is a line of the mountinfo file, see proc(5).
+type mountInfo struct {
+	Major      uint32
+	Minor      uint32
+	Root       string
+	MountPoint string
+	FsType     string
+	Source     string
+	// Options are the options of the mount itself, e.g. noatime, which
+	// differ between the bind mounts of a filesystem.
+	Options map[string]string
+	// SuperOptions are the options of the filesystem, e.g. the lowerdir and
+	// upperdir of an overlay.
+	SuperOptions map[string]string
+}
+
+// readMountInfo parses the mountinfo file at path.
+func readMountInfo(fs afero.Fs, path string) ([]mountInfo, error) {
+	lines, err := utils.ReadFileLines(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	var mounts []mountInfo
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		mount, err := parseMountInfoLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse '%s': %w", path, err)
+		}
+		mounts = append(mounts, *mount)
+	}
+	return mounts, nil
+}
+
+// parseMountInfoLine parses a mountinfo line, e.g.
+//
+//	36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+//
+// where the optional fields before the '-' separator vary in number.
+func parseMountInfoLine(line string) (*mountInfo, error) {
+	fields := strings.Fields(line)
+	separator := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			separator = i
+			break
+		}
+	}
+	if separator < 0 || len(fields) < separator+3 {
+		return nil, fmt.Errorf("malformed mountinfo line '%s'", line)
+	}
+	major, minor, ok := strings.Cut(fields[2], ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed device number '%s'", fields[2])
+	}
+	maj, err := strconv.ParseUint(major, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("malformed device number '%s': %w", fields[2], err)
+	}
+	min, err := strconv.ParseUint(minor, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("malformed device number '%s': %w", fields[2], err)
+	}
+	mount := &mountInfo{
+		Major:        uint32(maj),
+		Minor:        uint32(min),
+		Root:         unescapeMountField(fields[3]),
+		MountPoint:   unescapeMountField(fields[4]),
+		FsType:       fields[separator+1],
+		Source:       unescapeMountField(fields[separator+2]),
+		Options:      parseMountOptions(fields[5]),
+		SuperOptions: map[string]string{},
+	}
+	if len(fields) > separator+3 {
+		mount.SuperOptions = parseMountOptions(fields[separator+3])
+	}
+	return mount, nil
+}
+
+// parseMountOptions parses comma separated options, e.g. 'rw,errors=continue'.
+func parseMountOptions(field string) map[string]string {
+	options := map[string]string{}
+	for _, option := range strings.Split(field, ",") {
+		key, value, _ := strings.Cut(option, "=")
+		options[key] = value
+	}
+	return options
+}
+
+// unescapeMountField replaces the octal escapes the kernel uses for the
+// spaces, tabs, newlines and backslashes of mountinfo fields.
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+// findMount returns the mount holding path, which must be absolute and free
+// of symbolic links: the one with the longest mount point containing it. As
+// later mounts hide earlier ones on the same mount point, the last of those
+// wins.
+func findMount(mounts []mountInfo, path string) *mountInfo {
+	path = filepath.Clean(path)
+	var found *mountInfo
+	for i := range mounts {
+		mountPoint := mounts[i].MountPoint
+		if !pathContains(mountPoint, path) {
+			continue
+		}
+		if found == nil || len(mountPoint) >= len(found.MountPoint) {
+			found = &mounts[i]
+		}
+	}
+	return found
+}
+
+// pathContains returns whether path is dir or one of its descendants.
+func pathContains(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// Mount is the mount holding a path, see FindMount.
+type Mount struct {
+	MountPoint string
+	FsType     string
+	Source     string
+	// Options are the effective options of the mount: the ones of the mount
+	// itself, e.g. noatime, and the ones of its filesystem, e.g. nobarrier.
+	Options map[string]string
+}
+
+// FindMount returns the mount holding path in the mountinfo file at
+// mountInfoPath, following the symbolic links of path. Bind mounts and
+// submounts are told apart by their mount point, see findMount.
+func FindMount(fs afero.Fs, mountInfoPath, path string) (*Mount, error) {
+	mounts, err := readMountInfo(fs, mountInfoPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the mount table: %w", err)
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	if realPath, err = filepath.Abs(realPath); err != nil {
+		return nil, err
+	}
+	mount := findMount(mounts, realPath)
+	if mount == nil {
+		return nil, fmt.Errorf("no mount holding '%s' found in '%s'", realPath, mountInfoPath)
+	}
+	return mount.effective(), nil
+}
+
+// effective returns the mount with its options merged with the ones of its
+// filesystem, the former taking precedence.
+func (m *mountInfo) effective() *Mount {
+	options := make(map[string]string, len(m.Options)+len(m.SuperOptions))
+	for key, value := range m.SuperOptions {
+		options[key] = value
+	}
+	for key, value := range m.Options {
+		options[key] = value
+	}
+	return &Mount{
+		MountPoint: m.MountPoint,
+		FsType:     m.FsType,
+		Source:     m.Source,
+		Options:    options,
+	}
+}
+
+// HasOption returns whether the mount has the given option set.
+func (m *Mount) HasOption(option string) bool {
+	_, ok := m.Options[option]
+	return ok
+}
+
+// BarriersDisabled returns whether the write barriers, which flush the drive
+// write cache on commits, are disabled: with ext4 'nobarrier' or 'barrier=0',
+// or the 'nobarrier' option removed from XFS in Linux 4.19.
+func (m *Mount) BarriersDisabled() bool {
+	if m.HasOption("nobarrier") {
+		return true
+	}
+	barrier, ok := m.Options["barrier"]
+	return ok && barrier == "0"
+}
+
+// AtimeMode returns how reads update the access time of the files of the
+// mount: 'noatime', 'relatime' or, if neither is set, 'strictatime'.
+func (m *Mount) AtimeMode() string {
+	switch {
+	case m.HasOption("noatime"):
+		return "noatime"
+	case m.HasOption("relatime"):
+		return "relatime"
+	}
+	return "strictatime"
+}
+
+// OptionsString returns the effective options of the mount as they'd be
+// passed to mount(8), e.g. 'rw,noatime,attr2', in lexical order.
+func (m *Mount) OptionsString() string {
+	options := make([]string, 0, len(m.Options))
+	for key, value := range m.Options {
+		if value != "" {
+			key += "=" + value
+		}
+		options = append(options, key)
+	}
+	sort.Strings(options)
+	return strings.Join(options, ",")
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMountInfoLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    *mountInfo
+		wantErr bool
+	}{
+		{
+			name: "shall parse a mount with optional fields",
+			line: "36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue",
+			want: &mountInfo{
+				Major:        98,
+				Minor:        0,
+				Root:         "/mnt1",
+				MountPoint:   "/mnt2",
+				FsType:       "ext3",
+				Source:       "/dev/root",
+				Options:      map[string]string{"rw": "", "noatime": ""},
+				SuperOptions: map[string]string{"rw": "", "errors": "continue"},
+			},
+		},
+		{
+			name: "shall parse an overlay",
+			line: "512 480 0:52 / / rw,relatime - overlay overlay rw,lowerdir=/l1:/l2,upperdir=/var/lib/docker/overlay2/a/diff,workdir=/var/lib/docker/overlay2/a/work",
+			want: &mountInfo{
+				Major:      0,
+				Minor:      52,
+				Root:       "/",
+				MountPoint: "/",
+				FsType:     "overlay",
+				Source:     "overlay",
+				Options:    map[string]string{"rw": "", "relatime": ""},
+				SuperOptions: map[string]string{
+					"rw":       "",
+					"lowerdir": "/l1:/l2",
+					"upperdir": "/var/lib/docker/overlay2/a/diff",
+					"workdir":  "/var/lib/docker/overlay2/a/work",
+				},
+			},
+		},
+		{
+			name: "shall unescape the mount point",
+			line: `40 35 8:1 / /mnt/my\040data rw - xfs /dev/sda1 rw`,
+			want: &mountInfo{
+				Major:        8,
+				Minor:        1,
+				Root:         "/",
+				MountPoint:   "/mnt/my data",
+				FsType:       "xfs",
+				Source:       "/dev/sda1",
+				Options:      map[string]string{"rw": ""},
+				SuperOptions: map[string]string{"rw": ""},
+			},
+		},
+		{
+			name:    "shall fail without the separator",
+			line:    "36 35 98:0 /mnt1 /mnt2 rw,noatime ext3 /dev/root rw",
+			wantErr: true,
+		},
+		{
+			name:    "shall fail with a malformed device number",
+			line:    "36 35 98 /mnt1 /mnt2 rw - ext3 /dev/root rw",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMountInfoLine(tt.line)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_findMount(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "/proc/self/mountinfo", []byte(`1 0 8:1 / / rw - ext4 /dev/sda1 rw
+2 1 8:16 / /var/lib/redpanda rw - xfs /dev/sdb rw
+3 1 8:32 / /var/lib/redpanda rw - xfs /dev/sdc rw
+4 1 0:40 / /var/lib/redpanda-tmp rw - tmpfs tmpfs rw
+`), 0o644)
+	mounts, err := readMountInfo(fs, "/proc/self/mountinfo")
+	require.NoError(t, err)
+	require.Len(t, mounts, 4)
+
+	for path, source := range map[string]string{
+		"/":                            "/dev/sda1",
+		"/var/lib":                     "/dev/sda1",
+		"/var/lib/redpanda":            "/dev/sdc",
+		"/var/lib/redpanda/data/kafka": "/dev/sdc",
+		"/var/lib/redpanda-tmp/a":      "tmpfs",
+	} {
+		require.Equal(t, source, findMount(mounts, path).Source, path)
+	}
+	require.Nil(t, findMount(mounts[1:], "/etc"))
+}
+
+func TestFindMount(t *testing.T) {
+	// The mount points are compared with the path free of links.
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	fs := afero.NewMemMapFs()
+	// The data directory is a bind mount of a subdirectory of an XFS
+	// filesystem mounted elsewhere, with a nested ext4 mount under it.
+	afero.WriteFile(fs, "/proc/self/mountinfo", []byte(fmt.Sprintf(
+		`1 0 8:1 / / rw,relatime - ext4 /dev/sda1 rw
+2 1 8:16 / /mnt/disks/nvme rw,noatime - xfs /dev/nvme0n1 rw,attr2,inode64
+3 1 8:16 /redpanda %[1]s rw,relatime - xfs /dev/nvme0n1 rw,attr2,inode64
+4 3 8:32 / %[1]s/cache rw,noatime - ext4 /dev/sdb rw,nobarrier
+5 3 8:48 / %[1]s/cache-old rw,noatime - ext4 /dev/sdc rw,barrier=0
+`, dir)), 0o644)
+	for _, sub := range []string{"data", "cache/a", "cache-old"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0o755))
+	}
+
+	tests := []struct {
+		path             string
+		mountPoint       string
+		source           string
+		noatime          bool
+		barriersDisabled bool
+	}{
+		{path: dir, mountPoint: dir, source: "/dev/nvme0n1", noatime: false},
+		{path: filepath.Join(dir, "data"), mountPoint: dir, source: "/dev/nvme0n1", noatime: false},
+		{path: filepath.Join(dir, "cache", "a"), mountPoint: filepath.Join(dir, "cache"), source: "/dev/sdb", noatime: true, barriersDisabled: true},
+		{path: filepath.Join(dir, "cache-old"), mountPoint: filepath.Join(dir, "cache-old"), source: "/dev/sdc", noatime: true, barriersDisabled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			mount, err := FindMount(fs, "/proc/self/mountinfo", tt.path)
+			require.NoError(t, err)
+			require.Equal(t, tt.mountPoint, mount.MountPoint)
+			require.Equal(t, tt.source, mount.Source)
+			require.Equal(t, tt.noatime, mount.HasOption("noatime"))
+			require.Equal(t, tt.barriersDisabled, mount.BarriersDisabled())
+		})
+	}
+
+	mount, err := FindMount(fs, "/proc/self/mountinfo", dir)
+	require.NoError(t, err)
+	// The bind mount options take precedence over the filesystem ones.
+	require.Equal(t, map[string]string{"rw": "", "relatime": "", "attr2": "", "inode64": ""}, mount.Options)
+	require.Equal(t, "attr2,inode64,relatime,rw", mount.OptionsString())
+	require.Equal(t, "relatime", mount.AtimeMode())
+
+	mount, err = FindMount(fs, "/proc/self/mountinfo", filepath.Join(dir, "cache-old"))
+	require.NoError(t, err)
+	require.Equal(t, "barrier=0,noatime,rw", mount.OptionsString())
+	require.Equal(t, "noatime", mount.AtimeMode())
+	require.Equal(t, "strictatime", (&Mount{Options: map[string]string{"rw": ""}}).AtimeMode())
+
+	_, err = FindMount(fs, "/proc/self/mountinfo", filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
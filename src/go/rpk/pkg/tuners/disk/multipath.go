@@ -0,0 +1,63 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// multipathUUIDPrefix prefixes the device-mapper UUID of the devices created
+// by multipathd, e.g. 'mpath-3600508b400105e210000900000490000'.
+const multipathUUIDPrefix = "mpath-"
+
+// Multipath describes a device-mapper multipath (dm-mpath) device, which
+// aggregates the paths to a SAN volume, each path being a block device.
+type Multipath struct {
+	// Name is the device-mapper name of the device, e.g. 'mpatha'.
+	Name string
+	// UUID is the device-mapper UUID of the device, which is the WWID of the
+	// volume prefixed with 'mpath-'.
+	UUID string
+	// Paths are the names of the path devices, e.g. 'sdb'.
+	Paths []string
+	// FailedPaths are the names of the paths whose device is gone, e.g. after
+	// a SAN link failure, which multipathd didn't remove yet.
+	FailedPaths []string
+}
+
+// WWID returns the World Wide Identifier of the volume the paths lead to.
+func (m *Multipath) WWID() string {
+	return strings.TrimPrefix(m.UUID, multipathUUIDPrefix)
+}
+
+// multipathFromSystemPath returns the multipath details of the device at
+// syspath, or nil if the device is not a multipath device.
+func (r *DeviceResolver) multipathFromSystemPath(syspath string, slaves []string) *Multipath {
+	uuid, err := readIdentityAttribute(r.fs, filepath.Join(syspath, "dm", "uuid"))
+	if err != nil || !strings.HasPrefix(uuid, multipathUUIDPrefix) {
+		return nil
+	}
+	multipath := &Multipath{
+		Name: deviceMapperName(syspath, r.fs),
+		UUID: uuid,
+	}
+	for _, slave := range slaves {
+		slavePath := r.slaveSystemPath(syspath, slave)
+		if exists, _ := afero.Exists(r.fs, filepath.Join(slavePath, "uevent")); !exists {
+			multipath.FailedPaths = append(multipath.FailedPaths, slave)
+			continue
+		}
+		multipath.Paths = append(multipath.Paths, slave)
+	}
+	return multipath
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const mpathUUID = "mpath-3600508b400105e210000900000490000"
+
+// writeFakeMultipath creates the dm-0 'mpatha' multipath device with the
+// given paths, the paths not in live being dangling slaves.
+func writeFakeMultipath(fs *linkFs, paths []string, live ...string) {
+	writeFakeStackedDevice(fs, "dm-0", paths...)
+	afero.WriteFile(fs, "/sys/block/dm-0/dm/name", []byte("mpatha\n"), 0o644)
+	afero.WriteFile(fs, "/sys/block/dm-0/dm/uuid", []byte(mpathUUID+"\n"), 0o644)
+	for _, path := range paths {
+		fs.links["/sys/block/dm-0/slaves/"+path] = "../../" + path
+	}
+	for _, path := range live {
+		writeFakeStackedDevice(fs, path)
+	}
+}
+
+func TestDeviceResolver_multipath(t *testing.T) {
+	tests := []struct {
+		name         string
+		before       func(*linkFs)
+		expMultipath *Multipath
+		expPhysical  []string
+	}{
+		{
+			name: "two paths",
+			before: func(fs *linkFs) {
+				writeFakeMultipath(fs, []string{"sdb", "sdc"}, "sdb", "sdc")
+			},
+			expMultipath: &Multipath{
+				Name:  "mpatha",
+				UUID:  mpathUUID,
+				Paths: []string{"sdb", "sdc"},
+			},
+			expPhysical: []string{"/dev/sdb", "/dev/sdc"},
+		},
+		{
+			name: "failed path",
+			before: func(fs *linkFs) {
+				writeFakeMultipath(fs, []string{"sdb", "sdc"}, "sdc")
+			},
+			expMultipath: &Multipath{
+				Name:        "mpatha",
+				UUID:        mpathUUID,
+				Paths:       []string{"sdc"},
+				FailedPaths: []string{"sdb"},
+			},
+			expPhysical: []string{"/dev/sdc"},
+		},
+		{
+			name: "not a multipath device",
+			before: func(fs *linkFs) {
+				writeFakeStackedDevice(fs, "dm-0", "sdb")
+				afero.WriteFile(fs, "/sys/block/dm-0/dm/name", []byte("vg0-data\n"), 0o644)
+				afero.WriteFile(fs, "/sys/block/dm-0/dm/uuid", []byte("LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8Tc\n"), 0o644)
+				writeFakeStackedDevice(fs, "sdb")
+			},
+			expPhysical: []string{"/dev/sdb"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+			tt.before(fs)
+			resolver := NewDeviceResolver(fs, "")
+			device, err := resolver.deviceFromSystemPath(context.Background(), "/sys/block/dm-0")
+			require.NoError(t, err)
+			require.Equal(t, tt.expMultipath, device.Multipath())
+			devices, err := resolver.resolvePhysicalDevices(context.Background(), device)
+			require.NoError(t, err)
+			var devnodes []string
+			for _, d := range devices {
+				devnodes = append(devnodes, d.Devnode())
+			}
+			require.Equal(t, tt.expPhysical, devnodes)
+		})
+	}
+}
+
+func TestMultipath_WWID(t *testing.T) {
+	m := &Multipath{UUID: mpathUUID}
+	require.Equal(t, "3600508b400105e210000900000490000", m.WWID())
+}
@@ -0,0 +1,200 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+var (
+	// nvmeNamePattern matches the names of NVMe namespaces, e.g. 'nvme0n1',
+	// of their partitions, e.g. 'nvme0n1p3', and of the per-controller paths
+	// of multipath namespaces, e.g. 'nvme0c1n1' for namespace 1 of
+	// subsystem 0 reached through controller 'nvme1'.
+	nvmeNamePattern       = regexp.MustCompile(`^nvme(\d+)(?:c(\d+))?n(\d+)(?:p(\d+))?$`)
+	nvmeControllerPattern = regexp.MustCompile(`^nvme\d+$`)
+)
+
+// nvmeName is the parsed name of an NVMe namespace or partition.
+type nvmeName struct {
+	// Disk is the name of the whole namespace, e.g. 'nvme0n1' for
+	// 'nvme0n1p3'.
+	Disk string
+	// Controller is the name of the controller the namespace is reached
+	// through, e.g. 'nvme0' for 'nvme0n1' or 'nvme1' for 'nvme0c1n1'.
+	Controller string
+	// Index is the namespace index assigned by the kernel, which doesn't
+	// need to match the NSID.
+	Index int
+	// Partition is the partition number, or 0 for a whole namespace.
+	Partition int
+}
+
+// parseNvmeName parses the name of an NVMe namespace or partition, returning
+// false for any other device, including the controllers themselves.
+func parseNvmeName(name string) (nvmeName, bool) {
+	matches := nvmeNamePattern.FindStringSubmatch(name)
+	if matches == nil {
+		return nvmeName{}, false
+	}
+	controller := matches[1]
+	if matches[2] != "" {
+		controller = matches[2]
+	}
+	// The pattern only matches digits, which can still overflow.
+	index, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return nvmeName{}, false
+	}
+	var partition int
+	if matches[4] != "" {
+		if partition, err = strconv.Atoi(matches[4]); err != nil {
+			return nvmeName{}, false
+		}
+	}
+	disk := name
+	if partition != 0 {
+		disk = strings.TrimSuffix(name, "p"+matches[4])
+	}
+	return nvmeName{
+		Disk:       disk,
+		Controller: "nvme" + controller,
+		Index:      index,
+		Partition:  partition,
+	}, true
+}
+
+// NvmeNamespace describes the NVMe namespace backing a block device and the
+// controller the namespace is attached to.
+type NvmeNamespace struct {
+	// Controller name, e.g. 'nvme0'.
+	Controller string
+	// Controller system path, e.g. '/sys/devices/pci0000:00/.../nvme/nvme0'.
+	ControllerPath string
+	// Namespace ID as reported by the controller.
+	ID int
+	// Indexes of the blk-mq hardware queues exposed by the namespace.
+	Queues []int
+}
+
+// nvmeNamespaceFromSystemPath returns the NVMe namespace details of the
+// device at syspath, or nil if the device is not an NVMe namespace.
+func (r *DeviceResolver) nvmeNamespaceFromSystemPath(
+	syspath string,
+) (*NvmeNamespace, error) {
+	name := filepath.Base(syspath)
+	// Partitions are resolved to the namespace holding them.
+	parsed, ok := parseNvmeName(name)
+	if !ok || parsed.Partition != 0 {
+		return nil, nil
+	}
+	log.Debugf("Device '%s' is an NVMe namespace", name)
+	// The namespace index in the device name is assigned by the kernel and
+	// does not need to match the NSID, e.g. for namespaces hot-added after
+	// boot, so we prefer the 'nsid' attribute when present.
+	id := parsed.Index
+	nsidFile := filepath.Join(syspath, "nsid")
+	if exists, _ := afero.Exists(r.fs, nsidFile); exists {
+		line, err := utils.ReadEnsureSingleLine(r.fs, nsidFile)
+		if err != nil {
+			return nil, err
+		}
+		id, err = strconv.Atoi(strings.TrimSpace(line))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse NSID of '%s': %v", name, err)
+		}
+	}
+	controllerPath := nvmeControllerPath(syspath, r.fs)
+	if controllerPath == "" {
+		controllerPath = r.path("class", "nvme", parsed.Controller)
+	}
+	queues, err := nvmeNamespaceQueues(syspath, r.fs)
+	if err != nil {
+		return nil, err
+	}
+	return &NvmeNamespace{
+		Controller:     filepath.Base(controllerPath),
+		ControllerPath: controllerPath,
+		ID:             id,
+		Queues:         queues,
+	}, nil
+}
+
+// nvmeControllerPath returns the system path of the controller owning the
+// namespace at syspath. The 'device' link of the namespace points to its
+// controller; if it can't be read we fall back to the directory hierarchy,
+// where namespaces are children of their controller.
+func nvmeControllerPath(syspath string, fs afero.Fs) string {
+	if target, ok := readLinkIfPossible(fs, filepath.Join(syspath, "device")); ok {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(syspath, target)
+		}
+		if nvmeControllerPattern.MatchString(filepath.Base(target)) {
+			return filepath.Clean(target)
+		}
+	}
+	parent := filepath.Dir(syspath)
+	if nvmeControllerPattern.MatchString(filepath.Base(parent)) {
+		return parent
+	}
+	return ""
+}
+
+func nvmeNamespaceQueues(syspath string, fs afero.Fs) ([]int, error) {
+	mqDir := filepath.Join(syspath, "mq")
+	if exists, _ := afero.DirExists(fs, mqDir); !exists {
+		return nil, nil
+	}
+	files, err := afero.ReadDir(fs, mqDir)
+	if err != nil {
+		return nil, err
+	}
+	var queues []int
+	for _, file := range files {
+		queue, err := strconv.Atoi(file.Name())
+		if err != nil {
+			continue
+		}
+		queues = append(queues, queue)
+	}
+	sort.Ints(queues)
+	return queues, nil
+}
+
+// isNvmeDevice returns whether the device at syspath is backed by an NVMe
+// controller, i.e. its 'device' belongs to the 'nvme' class, which covers
+// devices not named after their namespace, e.g. native NVMe multipath ones.
+func isNvmeDevice(syspath string, fs afero.Fs) bool {
+	target, ok := readLinkIfPossible(fs, filepath.Join(syspath, "device", "subsystem"))
+	return ok && filepath.Base(target) == "nvme"
+}
+
+// readLinkIfPossible returns the target of the symbolic link at path if the
+// underlying filesystem supports reading links.
+func readLinkIfPossible(fs afero.Fs, path string) (string, bool) {
+	reader, ok := fs.(afero.LinkReader)
+	if !ok {
+		return "", false
+	}
+	target, err := reader.ReadlinkIfPossible(path)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const nvmeControllerSyspath = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/nvme0"
+
+func Test_nvmeNamespaceFromSystemPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		syspath string
+		before  func(afero.Fs)
+		want    *NvmeNamespace
+	}{
+		{
+			name:    "shall return nil for non NVMe devices",
+			syspath: "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			before:  func(afero.Fs) {},
+			want:    nil,
+		},
+		{
+			name:    "shall return nil for the NVMe controller",
+			syspath: nvmeControllerSyspath,
+			before:  func(afero.Fs) {},
+			want:    nil,
+		},
+		{
+			name:    "shall return the namespace of a single namespace controller",
+			syspath: nvmeControllerSyspath + "/nvme0n1",
+			before: func(fs afero.Fs) {
+				for _, q := range []string{"0", "1", "10", "2"} {
+					fs.MkdirAll(filepath.Join(nvmeControllerSyspath, "nvme0n1", "mq", q), 0o755)
+				}
+			},
+			want: &NvmeNamespace{
+				Controller:     "nvme0",
+				ControllerPath: nvmeControllerSyspath,
+				ID:             1,
+				Queues:         []int{0, 1, 2, 10},
+			},
+		},
+		{
+			name:    "shall return the second namespace of a multi namespace controller",
+			syspath: nvmeControllerSyspath + "/nvme0n2",
+			before: func(fs afero.Fs) {
+				fs.MkdirAll(filepath.Join(nvmeControllerSyspath, "nvme0n1", "mq", "0"), 0o755)
+				fs.MkdirAll(filepath.Join(nvmeControllerSyspath, "nvme0n2", "mq", "0"), 0o755)
+			},
+			want: &NvmeNamespace{
+				Controller:     "nvme0",
+				ControllerPath: nvmeControllerSyspath,
+				ID:             2,
+				Queues:         []int{0},
+			},
+		},
+		{
+			name:    "shall prefer the NSID of hot-added namespaces",
+			syspath: nvmeControllerSyspath + "/nvme0n2",
+			before: func(fs afero.Fs) {
+				afero.WriteFile(fs, filepath.Join(nvmeControllerSyspath, "nvme0n2", "nsid"), []byte("5\n"), 0o644)
+			},
+			want: &NvmeNamespace{
+				Controller:     "nvme0",
+				ControllerPath: nvmeControllerSyspath,
+				ID:             5,
+			},
+		},
+		{
+			name:    "shall fall back to the controller class for subsystem namespaces",
+			syspath: "/sys/devices/virtual/nvme-subsystem/nvme-subsys3/nvme3n1",
+			before:  func(afero.Fs) {},
+			want: &NvmeNamespace{
+				Controller:     "nvme3",
+				ControllerPath: "/sys/class/nvme/nvme3",
+				ID:             1,
+			},
+		},
+		{
+			name:    "shall fall back to the controller of per-controller multipath paths",
+			syspath: "/sys/devices/virtual/nvme-subsystem/nvme-subsys0/nvme0c2n1",
+			before:  func(afero.Fs) {},
+			want: &NvmeNamespace{
+				Controller:     "nvme2",
+				ControllerPath: "/sys/class/nvme/nvme2",
+				ID:             1,
+			},
+		},
+		{
+			name:    "shall return nil for NVMe partitions",
+			syspath: nvmeControllerSyspath + "/nvme0n1/nvme0n1p1",
+			before:  func(afero.Fs) {},
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			got, err := NewDeviceResolver(fs, "").nvmeNamespaceFromSystemPath(tt.syspath)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_parseNvmeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want nvmeName
+		ok   bool
+	}{
+		{name: "nvme0n1", want: nvmeName{Disk: "nvme0n1", Controller: "nvme0", Index: 1}, ok: true},
+		{name: "nvme0n1p3", want: nvmeName{Disk: "nvme0n1", Controller: "nvme0", Index: 1, Partition: 3}, ok: true},
+		{name: "nvme10n2p15", want: nvmeName{Disk: "nvme10n2", Controller: "nvme10", Index: 2, Partition: 15}, ok: true},
+		{name: "nvme1n12", want: nvmeName{Disk: "nvme1n12", Controller: "nvme1", Index: 12}, ok: true},
+		{name: "nvme0c1n1", want: nvmeName{Disk: "nvme0c1n1", Controller: "nvme1", Index: 1}, ok: true},
+		{name: "nvme0", ok: false},
+		{name: "nvme0n", ok: false},
+		{name: "nvme0n1p", ok: false},
+		{name: "nvme-subsys0", ok: false},
+		{name: "sda1", ok: false},
+		{name: "nvme0n99999999999999999999", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseNvmeName(tt.name)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// resolvePhysicalDevices returns the leaf devices backing the given device by
+// recursively following the slaves of stacked devices such as device-mapper
+// (LVM, LUKS/dm-crypt) devices or md arrays. Every device-mapper target
+// (linear, striped, mirror, crypt, ...) lists the devices it maps to as
+// slaves, e.g. a mirror is resolved through its mimage devices down to the
+// disks holding each leg, and an opened LUKS volume down to the partition or
+// LVM volume holding it. A physical device shared by several stacked devices
+// is only returned once.
+//
+// Physical devices resolved through an md array carry the array RAID level,
+// see BlockDevice.RaidLevel.
+func (r *DeviceResolver) resolvePhysicalDevices(
+	ctx context.Context, device BlockDevice,
+) ([]BlockDevice, error) {
+	return r.resolveSlaves(ctx, device, nil, stackRoles{}, map[string]bool{})
+}
+
+// maxStackDepth bounds the number of stacked devices between a device and
+// its physical devices, real setups (e.g. LVM on LUKS on md on partitions)
+// are far from it.
+const maxStackDepth = 16
+
+// stackError returns the error of resolving the physical devices of the
+// stacked device.
+func stackError(device BlockDevice, kind ResolveErrorKind, err error) error {
+	return &DeviceResolveError{
+		Path: device.Syspath(),
+		Op:   "resolve the physical devices of",
+		Kind: kind,
+		Err:  err,
+	}
+}
+
+func (r *DeviceResolver) resolveSlaves(
+	ctx context.Context,
+	device BlockDevice,
+	chain []string,
+	roles stackRoles,
+	seen map[string]bool,
+) ([]BlockDevice, error) {
+	log.Debugf("Getting physical device from '%s'", device.Syspath())
+	if partition := device.Partition(); partition != nil {
+		log.Debugf("Using disk '%s' holding partition '%s'",
+			device.Devnode(), partition.Devnode())
+	}
+	name := deviceName(device)
+	for _, stacked := range chain {
+		if stacked == name {
+			return nil, stackError(device, ResolveErrorUnsupportedStack,
+				fmt.Errorf("found a cycle: %s -> %s", strings.Join(chain, " -> "), name))
+		}
+	}
+	chain = append(append([]string{}, chain...), name)
+	if len(chain) > maxStackDepth {
+		return nil, stackError(device, ResolveErrorUnsupportedStack,
+			fmt.Errorf("too many stacked devices: %s", strings.Join(chain, " -> ")))
+	}
+	slaves, err := readSlaves(device.Syspath(), r.fs)
+	if err != nil {
+		return nil, err
+	}
+	if len(slaves) == 0 {
+		if seen[name] {
+			log.Debugf("Physical device '%s' already resolved, skipping", name)
+			return nil, nil
+		}
+		seen[name] = true
+		log.Debugf("Resolved physical device: %s", strings.Join(chain, " -> "))
+		if d, ok := device.(*blockDevice); ok && roles != (stackRoles{}) {
+			member := *d
+			member.raidLevel, member.cacheRole = roles.raidLevel, roles.cacheRole
+			device = &member
+		}
+		return []BlockDevice{device}, nil
+	}
+	if dmName := deviceMapperName(device.Syspath(), r.fs); dmName != "" {
+		if isDmCrypt(device.Syspath(), r.fs) {
+			log.Debugf("'%s' is dm-crypt device '%s', resolving its backing devices", name, dmName)
+		} else {
+			log.Debugf("'%s' is device-mapper device '%s'", name, dmName)
+		}
+	}
+	if mpath := device.Multipath(); mpath != nil {
+		log.Debugf("'%s' is multipath device '%s' with WWID %s and %d paths",
+			name, mpath.Name, mpath.WWID(), len(mpath.Paths))
+	}
+	if md := device.Md(); md != nil {
+		log.Debugf("'%s' is a %s md array with %d disks", name, md.Level, md.Disks)
+		roles.raidLevel = md.Level
+	}
+	cache := device.Cache()
+	if cache != nil {
+		log.Debugf("'%s' is a %s device caching %s on %s", name, cache.Kind,
+			strings.Join(cache.Backing, ", "), strings.Join(cache.Cache, ", "))
+	}
+	var physDevices []BlockDevice
+	for _, slave := range slaves {
+		log.Debugf("Dealing with stacked device '%s', checking slave '%s'", name, slave)
+		slaveRoles := roles
+		if cache != nil {
+			slaveRoles.cacheRole = cache.role(slave)
+		}
+		slavePath := r.slaveSystemPath(device.Syspath(), slave)
+		if exists, _ := afero.Exists(r.fs, filepath.Join(slavePath, "uevent")); !exists {
+			// Members of degraded arrays may be gone while the array keeps
+			// working with the remaining ones.
+			if device.Md() != nil {
+				log.Warnf("Skipping missing member '%s' of degraded md array '%s'", slave, name)
+				continue
+			}
+			// Likewise for the failed paths of multipath devices.
+			if mpath := device.Multipath(); mpath != nil {
+				log.Warnf("Skipping failed path '%s' of multipath device '%s' (%s)", slave, mpath.Name, name)
+				continue
+			}
+			return nil, stackError(device, ResolveErrorNotFound,
+				fmt.Errorf("slave '%s' of '%s' disappeared", slave, name))
+		}
+		slaveDevice, err := r.deviceFromSystemPath(ctx, slavePath)
+		if err != nil {
+			return nil, err
+		}
+		devices, err := r.resolveSlaves(ctx, slaveDevice, chain, slaveRoles, seen)
+		if err != nil {
+			return nil, err
+		}
+		physDevices = append(physDevices, devices...)
+	}
+	// The caching devices of bcache devices are not slaves of them.
+	if cache != nil {
+		for _, cachePath := range cache.cachePaths {
+			cacheDevice, err := r.deviceFromSystemPath(ctx, cachePath)
+			if err != nil {
+				return nil, err
+			}
+			cacheRoles := roles
+			cacheRoles.cacheRole = CacheRoleCache
+			devices, err := r.resolveSlaves(ctx, cacheDevice, chain, cacheRoles, seen)
+			if err != nil {
+				return nil, err
+			}
+			physDevices = append(physDevices, devices...)
+		}
+	}
+	return physDevices, nil
+}
+
+// stackRoles are the roles of the physical devices within the stacked
+// devices they are resolved through.
+type stackRoles struct {
+	// raidLevel is the level of the nearest md array.
+	raidLevel string
+	// cacheRole is the role within the nearest cache device.
+	cacheRole string
+}
+
+// readSlaves returns the names of the devices the device at syspath is
+// stacked on, which is empty for physical devices and for those without a
+// syspath.
+func readSlaves(syspath string, fs afero.Fs) ([]string, error) {
+	if syspath == "" {
+		return nil, nil
+	}
+	slavesPath := filepath.Join(syspath, "slaves")
+	if exists, _ := afero.DirExists(fs, slavesPath); !exists {
+		return nil, nil
+	}
+	files, err := afero.ReadDir(fs, slavesPath)
+	if err != nil {
+		return nil, err
+	}
+	var slaves []string
+	for _, file := range files {
+		slaves = append(slaves, file.Name())
+	}
+	return slaves, nil
+}
+
+// slaveSystemPath returns the system path of the named slave of the device at
+// syspath. Slaves are links to the slave device directory; if they can't be
+// read we fall back to the slave entry in /sys/block.
+func (r *DeviceResolver) slaveSystemPath(syspath, slave string) string {
+	if target, ok := readLinkIfPossible(r.fs, filepath.Join(syspath, "slaves", slave)); ok {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(syspath, "slaves", target)
+		}
+		return filepath.Clean(target)
+	}
+	return r.path("block", slave)
+}
+
+// deviceMapperName returns the device-mapper name of the device at syspath,
+// e.g. the 'vg0-data' LVM volume, or an empty string if the device is not a
+// device-mapper device.
+func deviceMapperName(syspath string, fs afero.Fs) string {
+	nameFile := filepath.Join(syspath, "dm", "name")
+	if exists, _ := afero.Exists(fs, nameFile); !exists {
+		return ""
+	}
+	name, err := readSysfsString(fs, nameFile)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// isDmCrypt returns whether the device-mapper device at syspath is a dm-crypt
+// device, e.g. an opened LUKS volume, which cryptsetup creates with a
+// 'CRYPT-' prefixed device-mapper UUID such as 'CRYPT-LUKS2-<uuid>-<name>'.
+// The partitions kpartx maps on top of it are prefixed with 'part<N>-' and
+// are not dm-crypt devices themselves.
+func isDmCrypt(syspath string, fs afero.Fs) bool {
+	uuid, err := readIdentityAttribute(fs, filepath.Join(syspath, "dm", "uuid"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(uuid, "CRYPT-")
+}
+
+func deviceName(device BlockDevice) string {
+	return strings.TrimPrefix(device.Devnode(), "/dev/")
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFakeStackedDevice creates a stacked device under /sys/block with the
+// given slaves.
+func writeFakeStackedDevice(fs afero.Fs, name string, slaves ...string) {
+	syspath := filepath.Join("/sys/block", name)
+	writeFakeDevice(fs, syspath, name, false)
+	fs.MkdirAll(filepath.Join(syspath, "slaves"), 0o755)
+	for _, slave := range slaves {
+		afero.WriteFile(fs, filepath.Join(syspath, "slaves", slave), nil, 0o644)
+	}
+}
+
+func Test_resolvePhysicalDevices(t *testing.T) {
+	tests := []struct {
+		name        string
+		device      string
+		before      func(afero.Fs)
+		want        []string
+		wantErr     bool
+		wantErrKind ResolveErrorKind
+	}{
+		{
+			name:   "shall return a physical device",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+			},
+			want: []string{"/dev/sda"},
+		},
+		{
+			name:   "shall resolve a linear volume",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "nvme0n1")
+				writeFakeStackedDevice(fs, "nvme0n1")
+			},
+			want: []string{"/dev/nvme0n1"},
+		},
+		{
+			name:   "shall resolve a striped volume",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+			},
+			want: []string{"/dev/sda", "/dev/sdb"},
+		},
+		{
+			name:   "shall resolve a mirror through its images",
+			device: "dm-2",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-2", "dm-0", "dm-1")
+				writeFakeStackedDevice(fs, "dm-0", "sda")
+				writeFakeStackedDevice(fs, "dm-1", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+			},
+			want: []string{"/dev/sda", "/dev/sdb"},
+		},
+		{
+			name:   "shall return shared physical devices once",
+			device: "dm-2",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-2", "dm-0", "dm-1")
+				writeFakeStackedDevice(fs, "dm-0", "sda")
+				writeFakeStackedDevice(fs, "dm-1", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+			},
+			want: []string{"/dev/sda", "/dev/sdb"},
+		},
+		{
+			name:   "shall fail when a slave disappears",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda", "sdb")
+				writeFakeStackedDevice(fs, "sda")
+			},
+			wantErr:     true,
+			wantErrKind: ResolveErrorNotFound,
+		},
+		{
+			name:   "shall fail on a cycle",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "dm-1")
+				writeFakeStackedDevice(fs, "dm-1", "dm-0")
+			},
+			wantErr:     true,
+			wantErrKind: ResolveErrorUnsupportedStack,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			resolver := NewDeviceResolver(fs, "")
+			device, err := resolver.deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			got, err := resolver.resolvePhysicalDevices(context.Background(), device)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tt.wantErrKind, ResolveErrorKindOf(err))
+				return
+			}
+			require.NoError(t, err)
+			var devnodes []string
+			for _, d := range got {
+				devnodes = append(devnodes, d.Devnode())
+			}
+			require.Equal(t, tt.want, devnodes)
+		})
+	}
+}
+
+func TestBlockDevice_IsRotational(t *testing.T) {
+	writeRotational := func(fs afero.Fs, name, rotational string) {
+		afero.WriteFile(fs, filepath.Join("/sys/block", name, "queue", "rotational"),
+			[]byte(rotational+"\n"), 0o644)
+	}
+	tests := []struct {
+		name   string
+		device string
+		before func(afero.Fs)
+		want   bool
+	}{
+		{
+			name:   "shall return a non rotational disk",
+			device: "nvme0n1",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeRotational(fs, "nvme0n1", "0")
+			},
+		},
+		{
+			name:   "shall return a rotational disk",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeRotational(fs, "sda", "1")
+			},
+			want: true,
+		},
+		{
+			name:   "shall return a rotational volume if any member is rotational",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "nvme0n1", "sda")
+				writeRotational(fs, "dm-0", "0")
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeRotational(fs, "nvme0n1", "0")
+				writeFakeStackedDevice(fs, "sda")
+				writeRotational(fs, "sda", "1")
+			},
+			want: true,
+		},
+		{
+			name:   "shall return a non rotational array of non rotational members",
+			device: "md0",
+			before: func(fs afero.Fs) {
+				writeFakeMdArray(fs, "md0", "raid1", "2", "nvme0n1", "nvme1n1")
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeRotational(fs, "nvme0n1", "0")
+				writeFakeStackedDevice(fs, "nvme1n1")
+				writeRotational(fs, "nvme1n1", "0")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "").deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			got, err := device.IsRotational()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBlockDevice_IsRotational_stackedOnce(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeFakeStackedDevice(fs, "dm-0", "sda")
+	writeFakeStackedDevice(fs, "sda")
+	rotationalFile := "/sys/block/sda/queue/rotational"
+	afero.WriteFile(fs, rotationalFile, []byte("1\n"), 0o644)
+	device, err := NewDeviceResolver(fs, "").deviceFromSystemPath(context.Background(), "/sys/block/dm-0")
+	require.NoError(t, err)
+	rotational, err := device.IsRotational()
+	require.NoError(t, err)
+	require.True(t, rotational)
+
+	// The stack is only resolved once.
+	fs.RemoveAll("/sys/block/sda")
+	rotational, err = device.IsRotational()
+	require.NoError(t, err)
+	require.True(t, rotational)
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
+	"github.com/spf13/afero"
+)
+
+// DefaultProcPartitionsPath is the list of the block devices known to the
+// kernel, which the devices missing from sysfs are identified from.
+const DefaultProcPartitionsPath = "/proc/partitions"
+
+// procPartition is a line of /proc/partitions.
+type procPartition struct {
+	major, minor uint32
+	name         string
+}
+
+// readProcPartitions parses /proc/partitions, e.g.:
+//
+//	major minor  #blocks  name
+//
+//	   8        0  488386584 sda
+//	   8        1     524288 sda1
+func readProcPartitions(fs afero.Fs, path string) ([]procPartition, error) {
+	lines, err := utils.ReadFileLines(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	var partitions []procPartition
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "major" {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed line in '%s': %q", path, line)
+		}
+		major, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("malformed major number in '%s': %q", path, line)
+		}
+		minor, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("malformed minor number in '%s': %q", path, line)
+		}
+		partitions = append(partitions, procPartition{uint32(major), uint32(minor), fields[3]})
+	}
+	return partitions, nil
+}
+
+// deviceFromProcPartitions returns the block device with the given numbers,
+// identified by its name in /proc/partitions only: see IsIdentifiedOnly.
+// Partitions are resolved to the disk holding them, like in sysfs.
+func (r *DeviceResolver) deviceFromProcPartitions(major, minor uint32) (BlockDevice, error) {
+	partitions, err := readProcPartitions(r.fs, r.ProcPartitionsPath)
+	if err != nil {
+		return nil, err
+	}
+	var name string
+	names := map[string]bool{}
+	for _, p := range partitions {
+		names[p.name] = true
+		if p.major == major && p.minor == minor {
+			name = p.name
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("block device {%d, %d} not found in '%s'", major, minor, r.ProcPartitionsPath)
+	}
+	device := &identifiedDevice{devnode: filepath.Join("/dev", name)}
+	if disk := parentDiskName(name); disk != name && names[disk] {
+		return &identifiedDevice{
+			devnode:   filepath.Join("/dev", disk),
+			partition: device,
+		}, nil
+	}
+	return device, nil
+}
+
+// identifiedDevice is a block device missing from sysfs, identified by its
+// name only, e.g. from /proc/partitions. None of its attributes can be read,
+// nor tuned: reading them fails with ErrSysfsUnavailable.
+type identifiedDevice struct {
+	devnode   string
+	partition BlockDevice
+}
+
+// IsIdentifiedOnly returns whether the device was identified without sysfs,
+// e.g. from /proc/partitions, in which case its name is known but its
+// attributes can't be read nor tuned.
+func IsIdentifiedOnly(device BlockDevice) bool {
+	_, ok := device.(*identifiedDevice)
+	return ok
+}
+
+func (d *identifiedDevice) unavailable() error {
+	return &sysfsUnavailableError{
+		err: fmt.Errorf("'%s' was identified without sysfs, its attributes are not available", d.devnode),
+	}
+}
+
+func (*identifiedDevice) Syspath() string { return "" }
+
+func (d *identifiedDevice) Devnode() string { return d.devnode }
+
+func (*identifiedDevice) Parent() BlockDevice { return nil }
+
+func (*identifiedDevice) Nvme() *NvmeNamespace { return nil }
+
+func (d *identifiedDevice) Partition() BlockDevice { return d.partition }
+
+func (*identifiedDevice) Md() *MdArray { return nil }
+
+func (*identifiedDevice) RaidLevel() string { return "" }
+
+func (*identifiedDevice) Multipath() *Multipath { return nil }
+
+func (*identifiedDevice) Cache() *CacheDevice { return nil }
+
+func (*identifiedDevice) Thin() *ThinVolume { return nil }
+
+func (*identifiedDevice) CacheRole() string { return "" }
+
+func (*identifiedDevice) Class() DeviceClass { return DeviceClassUnknown }
+
+func (d *identifiedDevice) IsRotational() (bool, error) { return false, d.unavailable() }
+
+func (d *identifiedDevice) Model() (string, error) { return "", d.unavailable() }
+
+func (d *identifiedDevice) Vendor() (string, error) { return "", d.unavailable() }
+
+func (d *identifiedDevice) Serial() (string, error) { return "", d.unavailable() }
+
+func (d *identifiedDevice) ZonedModel() (string, error) { return "", d.unavailable() }
+
+func (d *identifiedDevice) Transport(context.Context) (TransportType, error) {
+	return TransportUnknown, d.unavailable()
+}
+
+func (d *identifiedDevice) WriteCache() (string, error) { return "", d.unavailable() }
+
+func (d *identifiedDevice) SupportsDiscard() (bool, error) { return false, d.unavailable() }
+
+func (d *identifiedDevice) DiscardGranularity() (uint64, error) { return 0, d.unavailable() }
+
+func (d *identifiedDevice) LogicalBlockSize() (uint64, error) { return 0, d.unavailable() }
+
+func (d *identifiedDevice) PhysicalBlockSize() (uint64, error) { return 0, d.unavailable() }
+
+func (d *identifiedDevice) PartitionAlignment() (*PartitionAlignment, error) {
+	return nil, d.unavailable()
+}
+
+// Capabilities returns no capabilities, none of the attributes of the device
+// can be read.
+func (*identifiedDevice) Capabilities() DeviceCapabilities { return DeviceCapabilities{} }
@@ -0,0 +1,90 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const procPartitions = `major minor  #blocks  name
+
+ 259        0  488386584 nvme0n1
+ 259        1     524288 nvme0n1p1
+ 259        2  487860224 nvme0n1p2
+   8        0  976762584 sda
+   8        1  976761560 sda1
+ 253        0  487858176 dm-0
+`
+
+func TestReadProcPartitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []procPartition
+		expErr   bool
+	}{
+		{
+			name:    "disks, partitions and device-mapper devices",
+			content: procPartitions,
+			expected: []procPartition{
+				{259, 0, "nvme0n1"},
+				{259, 1, "nvme0n1p1"},
+				{259, 2, "nvme0n1p2"},
+				{8, 0, "sda"},
+				{8, 1, "sda1"},
+				{253, 0, "dm-0"},
+			},
+		},
+		{
+			name:    "header only",
+			content: "major minor  #blocks  name\n\n",
+		},
+		{
+			name:    "missing field",
+			content: "major minor  #blocks  name\n\n   8        0  sda\n",
+			expErr:  true,
+		},
+		{
+			name:    "malformed minor number",
+			content: "major minor  #blocks  name\n\n   8        a  976762584 sda\n",
+			expErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, DefaultProcPartitionsPath, []byte(tt.content), 0o644))
+			partitions, err := readProcPartitions(fs, DefaultProcPartitionsPath)
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, partitions)
+		})
+	}
+}
+
+func TestIdentifiedDevice(t *testing.T) {
+	device := &identifiedDevice{devnode: "/dev/sda"}
+	require.True(t, IsIdentifiedOnly(device))
+	require.Equal(t, DeviceClassUnknown, device.Class())
+	require.Empty(t, device.Syspath())
+	_, err := device.IsRotational()
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+	_, err = device.DiscardGranularity()
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+	_, err = device.WriteCache()
+	require.True(t, errors.Is(err, ErrSysfsUnavailable))
+}
@@ -0,0 +1,213 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
+)
+
+// Resolution describes each step of the resolution of a path to the
+// physical devices backing it, see DeviceResolver.ResolvePath.
+type Resolution struct {
+	Path string
+	// Major and Minor are the number of the device holding the path, as
+	// reported by stat.
+	Major, Minor uint32
+	// FilesystemType is the type of the filesystem holding the path if it's
+	// not backed by a block device, e.g. 'overlay'.
+	FilesystemType string
+	// Syspath is the system path the device number links to in sysfs, it's
+	// empty if the device number isn't in sysfs, e.g. for overlays.
+	Syspath string
+	// Device is the device holding the path, the disk holding it for
+	// partitions.
+	Device BlockDevice
+	// Layers are the stacked devices between Device and its physical
+	// devices, starting with Device itself if it's stacked.
+	Layers []Layer
+	// Encrypted is set if one of the layers is a dm-crypt device, i.e. the
+	// path is encrypted before reaching its physical devices.
+	Encrypted bool
+	// PhysicalDevices are the leaf devices actually holding the path.
+	PhysicalDevices []BlockDevice
+}
+
+// Layer is a stacked device, e.g. a device-mapper volume or an md array.
+type Layer struct {
+	Name string
+	// Kind is 'crypt' for dm-crypt devices, 'multipath' for dm-mpath
+	// devices, the cache kind of cache devices ('bcache', 'dm-cache' or
+	// 'dm-writecache'), 'dm' for the other device-mapper devices, 'md' for md
+	// arrays, or 'stacked' for the other devices with slaves.
+	Kind string
+	// Detail is the device-mapper name of crypt and dm devices, e.g.
+	// 'vg0-data', the name, WWID and failed paths of multipath devices, e.g.
+	// 'mpatha, wwid 3600508b4..., failed paths sdd', the RAID level of md
+	// arrays, or the backing and caching devices of cache devices, e.g.
+	// 'backing sdb, cache nvme0n1p1, writeback, clean', after the
+	// device-mapper name of LVM caches.
+	Detail string
+	// Slaves are the names of the devices the layer is stacked on, including
+	// the caching devices of bcache devices, and the failed paths of
+	// multipath devices.
+	Slaves []string
+}
+
+// ResolvePath resolves path to its physical devices like NewDeviceFromPath
+// and resolvePhysicalDevices do, recording each step along the way. It's a
+// diagnostic for the resolution, when a tuner acts on unexpected devices.
+func (r *DeviceResolver) ResolvePath(ctx context.Context, path string) (*Resolution, error) {
+	dev, fsType, err := r.statPath(path)
+	if err != nil {
+		return nil, err
+	}
+	resolution := &Resolution{
+		Path:           path,
+		Major:          unix.Major(dev),
+		Minor:          unix.Minor(dev),
+		FilesystemType: fsType,
+	}
+	if syspath, err := r.readSyspath(resolution.Major, resolution.Minor); err == nil {
+		resolution.Syspath = syspath
+	} else {
+		log.Debugf("Device {%d, %d} of '%s' is not in sysfs: %v", resolution.Major, resolution.Minor, path, err)
+	}
+	device, err := r.NewDeviceFromPath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	resolution.Device = device
+	// Resolving the physical devices first reports the cycles and missing
+	// slaves the layers walk doesn't check for.
+	physDevices, err := r.resolvePhysicalDevices(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	resolution.PhysicalDevices = physDevices
+	if err := r.appendLayers(ctx, device, &resolution.Layers, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	for _, layer := range resolution.Layers {
+		if layer.Kind == "crypt" {
+			resolution.Encrypted = true
+		}
+	}
+	return resolution, nil
+}
+
+// appendLayers appends the stacked devices from device down to its physical
+// devices, each once, depth first.
+func (r *DeviceResolver) appendLayers(
+	ctx context.Context, device BlockDevice, layers *[]Layer, seen map[string]bool,
+) error {
+	name := deviceName(device)
+	if seen[name] {
+		return nil
+	}
+	seen[name] = true
+	slaves, err := readSlaves(device.Syspath(), r.fs)
+	if err != nil || len(slaves) == 0 {
+		return err
+	}
+	layer := Layer{Name: name, Kind: "stacked", Slaves: slaves}
+	if dmName := deviceMapperName(device.Syspath(), r.fs); dmName != "" {
+		layer.Kind, layer.Detail = "dm", dmName
+		if isDmCrypt(device.Syspath(), r.fs) {
+			layer.Kind = "crypt"
+		} else if mpath := device.Multipath(); mpath != nil {
+			layer.Kind, layer.Detail = "multipath", multipathDetail(mpath)
+		}
+	} else if md := device.Md(); md != nil {
+		layer.Kind, layer.Detail = "md", md.Level
+	}
+	slavePaths := make([]string, 0, len(slaves))
+	for _, slave := range slaves {
+		slavePaths = append(slavePaths, r.slaveSystemPath(device.Syspath(), slave))
+	}
+	if cache := device.Cache(); cache != nil {
+		detail := cacheDetail(cache)
+		if layer.Detail != "" {
+			detail = layer.Detail + ": " + detail
+		}
+		layer.Kind, layer.Detail = cache.Kind, detail
+		if cache.Kind == CacheKindBcache {
+			layer.Slaves = append(append([]string{}, slaves...), cache.Cache...)
+			slavePaths = append(slavePaths, cache.cachePaths...)
+		}
+	}
+	*layers = append(*layers, layer)
+	for _, slavePath := range slavePaths {
+		if exists, _ := afero.Exists(r.fs, filepath.Join(slavePath, "uevent")); !exists {
+			continue
+		}
+		slaveDevice, err := r.deviceFromSystemPath(ctx, slavePath)
+		if err != nil {
+			return err
+		}
+		if err := r.appendLayers(ctx, slaveDevice, layers, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheDetail describes the topology of the cache device, e.g. 'backing sdb,
+// cache nvme0n1p1, writeback, clean'.
+func cacheDetail(cache *CacheDevice) string {
+	parts := []string{"backing " + strings.Join(cache.Backing, ", ")}
+	if len(cache.Cache) > 0 {
+		parts = append(parts, "cache "+strings.Join(cache.Cache, ", "))
+	} else {
+		parts = append(parts, "no cache attached")
+	}
+	for _, attr := range []string{cache.Mode, cache.State} {
+		if attr != "" && attr != "no cache" {
+			parts = append(parts, attr)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// multipathDetail describes the multipath device, e.g. 'mpatha, wwid
+// 3600508b400105e210000900000490000, failed paths sdd'.
+func multipathDetail(mpath *Multipath) string {
+	detail := fmt.Sprintf("%s, wwid %s", mpath.Name, mpath.WWID())
+	if len(mpath.FailedPaths) > 0 {
+		detail += ", failed paths " + strings.Join(mpath.FailedPaths, ", ")
+	}
+	return detail
+}
+
+// DeviceInfo resolves the devices of path, e.g. a data directory, and returns
+// the snapshot of the device holding it, followed by the ones of its
+// physical devices if it's stacked. Only failing to resolve the devices
+// fails: the fields which can't be read are reported in the Errors of each
+// snapshot, see NewDeviceInfo.
+func (r *DeviceResolver) DeviceInfo(ctx context.Context, path string) ([]DeviceInfo, error) {
+	resolution, err := r.ResolvePath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	infos := []DeviceInfo{NewDeviceInfo(ctx, resolution.Device)}
+	for _, device := range resolution.PhysicalDevices {
+		if device.Syspath() == resolution.Device.Syspath() && device.Devnode() == resolution.Device.Devnode() {
+			continue
+		}
+		infos = append(infos, NewDeviceInfo(ctx, device))
+	}
+	return infos, nil
+}
@@ -0,0 +1,282 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestDeviceResolver_ResolvePath(t *testing.T) {
+	fs := &linkFs{
+		Fs:    afero.NewMemMapFs(),
+		links: map[string]string{"/sys/dev/block/253:0": "../../block/dm-0"},
+	}
+	writeFakeStackedDevice(fs, "dm-0", "md0")
+	afero.WriteFile(fs, "/sys/block/dm-0/dm/name", []byte("vg0-data\n"), 0o644)
+	writeFakeMdArray(fs, "md0", "raid1", "2", "sda", "sdb")
+	writeFakeStackedDevice(fs, "sda")
+	writeFakeStackedDevice(fs, "sdb")
+	afero.WriteFile(fs, "/sys/block/sdb/queue/rotational", []byte("1\n"), 0o644)
+
+	resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+	resolver.statPath = func(string) (uint64, string, error) {
+		return unix.Mkdev(253, 0), "", nil
+	}
+	resolution, err := resolver.ResolvePath(context.Background(), "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.False(t, resolution.Encrypted)
+	require.Equal(t, "/var/lib/redpanda/data", resolution.Path)
+	require.Equal(t, uint32(253), resolution.Major)
+	require.Equal(t, uint32(0), resolution.Minor)
+	require.Equal(t, "/sys/block/dm-0", resolution.Syspath)
+	require.Equal(t, "/dev/dm-0", resolution.Device.Devnode())
+	require.Equal(t, []Layer{
+		{Name: "dm-0", Kind: "dm", Detail: "vg0-data", Slaves: []string{"md0"}},
+		{Name: "md0", Kind: "md", Detail: "raid1", Slaves: []string{"sda", "sdb"}},
+	}, resolution.Layers)
+	var physical []string
+	for _, device := range resolution.PhysicalDevices {
+		physical = append(physical, device.Devnode())
+		require.Equal(t, "raid1", device.RaidLevel())
+	}
+	require.Equal(t, []string{"/dev/sda", "/dev/sdb"}, physical)
+	rotational, err := resolution.PhysicalDevices[1].IsRotational()
+	require.NoError(t, err)
+	require.True(t, rotational)
+
+	resolver.statPath = func(string) (uint64, string, error) {
+		return 0, "tmpfs", nil
+	}
+	_, err = resolver.ResolvePath(context.Background(), "/tmp")
+	require.ErrorIs(t, err, ErrNotBlockDevice)
+}
+
+func TestDeviceResolver_ResolvePath_dmCrypt(t *testing.T) {
+	writeCrypt := func(fs afero.Fs, name, dmName, uuid string, slaves ...string) {
+		writeFakeStackedDevice(fs, name, slaves...)
+		afero.WriteFile(fs, "/sys/block/"+name+"/dm/name", []byte(dmName+"\n"), 0o644)
+		afero.WriteFile(fs, "/sys/block/"+name+"/dm/uuid", []byte(uuid+"\n"), 0o644)
+	}
+	tests := []struct {
+		name         string
+		device       string
+		links        map[string]string
+		before       func(afero.Fs)
+		wantLayers   []Layer
+		wantPhysical []string
+	}{
+		{
+			name:   "shall resolve LUKS on a partition to its disk",
+			device: "dm-0",
+			links: map[string]string{
+				"/sys/block/dm-0/slaves/nvme0n1p2": "../../nvme0n1/nvme0n1p2",
+			},
+			before: func(fs afero.Fs) {
+				writeCrypt(fs, "dm-0", "luks-data", "CRYPT-LUKS2-7a1fc3e2d5b04f6c8e9a0b1c2d3e4f50-luks-data", "nvme0n1p2")
+				writeFakeDevice(fs, "/sys/block/nvme0n1", "nvme0n1", false)
+				writeFakeDevice(fs, "/sys/block/nvme0n1/nvme0n1p2", "nvme0n1p2", true)
+			},
+			wantLayers: []Layer{
+				{Name: "dm-0", Kind: "crypt", Detail: "luks-data", Slaves: []string{"nvme0n1p2"}},
+			},
+			wantPhysical: []string{"/dev/nvme0n1"},
+		},
+		{
+			name:   "shall resolve LUKS on LVM to the disks of the volume group",
+			device: "dm-1",
+			before: func(fs afero.Fs) {
+				writeCrypt(fs, "dm-1", "luks-data", "CRYPT-LUKS2-7a1fc3e2d5b04f6c8e9a0b1c2d3e4f50-luks-data", "dm-0")
+				writeCrypt(fs, "dm-0", "vg0-data", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8Tc", "nvme0n1", "nvme1n1")
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeFakeStackedDevice(fs, "nvme1n1")
+			},
+			wantLayers: []Layer{
+				{Name: "dm-1", Kind: "crypt", Detail: "luks-data", Slaves: []string{"dm-0"}},
+				{Name: "dm-0", Kind: "dm", Detail: "vg0-data", Slaves: []string{"nvme0n1", "nvme1n1"}},
+			},
+			wantPhysical: []string{"/dev/nvme0n1", "/dev/nvme1n1"},
+		},
+		{
+			name:   "shall resolve partitions mapped on LUKS to the disk beneath",
+			device: "dm-1",
+			before: func(fs afero.Fs) {
+				writeCrypt(fs, "dm-1", "luks-data1", "part1-CRYPT-LUKS2-7a1fc3e2d5b04f6c8e9a0b1c2d3e4f50-luks-data", "dm-0")
+				writeCrypt(fs, "dm-0", "luks-data", "CRYPT-LUKS2-7a1fc3e2d5b04f6c8e9a0b1c2d3e4f50-luks-data", "sda")
+				writeFakeStackedDevice(fs, "sda")
+			},
+			wantLayers: []Layer{
+				{Name: "dm-1", Kind: "dm", Detail: "luks-data1", Slaves: []string{"dm-0"}},
+				{Name: "dm-0", Kind: "crypt", Detail: "luks-data", Slaves: []string{"sda"}},
+			},
+			wantPhysical: []string{"/dev/sda"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := map[string]string{"/sys/dev/block/253:0": "../../block/" + tt.device}
+			for link, target := range tt.links {
+				links[link] = target
+			}
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: links}
+			tt.before(fs)
+			resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+			resolver.statPath = func(string) (uint64, string, error) {
+				return unix.Mkdev(253, 0), "", nil
+			}
+			resolution, err := resolver.ResolvePath(context.Background(), "/var/lib/redpanda/data")
+			require.NoError(t, err)
+			require.True(t, resolution.Encrypted)
+			require.Equal(t, tt.wantLayers, resolution.Layers)
+			var physical []string
+			for _, device := range resolution.PhysicalDevices {
+				physical = append(physical, device.Devnode())
+			}
+			require.Equal(t, tt.wantPhysical, physical)
+		})
+	}
+}
+
+func TestDeviceResolver_ResolvePath_cache(t *testing.T) {
+	tests := []struct {
+		name         string
+		device       string
+		before       func(*linkFs)
+		wantLayers   []Layer
+		wantPhysical []string
+	}{
+		{
+			name:   "shall report the caching devices of bcache devices",
+			device: "bcache0",
+			before: func(fs *linkFs) {
+				writeFakeBcache(fs, "dirty", "nvme0n1")
+				writeFakeStackedDevice(fs, "nvme0n1")
+			},
+			wantLayers: []Layer{{
+				Name:   "bcache0",
+				Kind:   "bcache",
+				Detail: "backing sdb, cache nvme0n1, writeback, dirty",
+				Slaves: []string{"sdb", "nvme0n1"},
+			}},
+			wantPhysical: []string{"/dev/sdb", "/dev/nvme0n1"},
+		},
+		{
+			name:   "shall report bcache devices without cache",
+			device: "bcache0",
+			before: func(fs *linkFs) {
+				writeFakeBcache(fs, "no cache")
+			},
+			wantLayers: []Layer{{
+				Name:   "bcache0",
+				Kind:   "bcache",
+				Detail: "backing sdb, no cache attached, writeback",
+				Slaves: []string{"sdb"},
+			}},
+			wantPhysical: []string{"/dev/sdb"},
+		},
+		{
+			name:   "shall report the origin and cache pool of LVM cache volumes",
+			device: "dm-3",
+			before: func(fs *linkFs) {
+				writeFakeLvmCache(fs, "_corig")
+			},
+			wantLayers: []Layer{
+				{Name: "dm-3", Kind: "dm-cache", Detail: "vg0-data: backing dm-2, cache dm-0, dm-1", Slaves: []string{"dm-0", "dm-1", "dm-2"}},
+				{Name: "dm-0", Kind: "dm", Detail: "vg0-cpool_cdata", Slaves: []string{"nvme0n1"}},
+				{Name: "dm-1", Kind: "dm", Detail: "vg0-cpool_cmeta", Slaves: []string{"nvme0n1"}},
+				{Name: "dm-2", Kind: "dm", Detail: "vg0-data_corig", Slaves: []string{"sda"}},
+			},
+			wantPhysical: []string{"/dev/nvme0n1", "/dev/sda"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{
+				"/sys/dev/block/252:0": "../../block/" + tt.device,
+			}}
+			tt.before(fs)
+			resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+			resolver.statPath = func(string) (uint64, string, error) {
+				return unix.Mkdev(252, 0), "", nil
+			}
+			resolution, err := resolver.ResolvePath(context.Background(), "/var/lib/redpanda/data")
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLayers, resolution.Layers)
+			var physical []string
+			for _, device := range resolution.PhysicalDevices {
+				physical = append(physical, device.Devnode())
+			}
+			require.Equal(t, tt.wantPhysical, physical)
+		})
+	}
+}
+
+func TestDeviceResolver_ResolvePath_multipath(t *testing.T) {
+	fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{
+		"/sys/dev/block/253:0": "../../block/dm-0",
+	}}
+	writeFakeMultipath(fs, []string{"sdb", "sdc", "sdd"}, "sdb", "sdc")
+	resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+	resolver.statPath = func(string) (uint64, string, error) {
+		return unix.Mkdev(253, 0), "", nil
+	}
+	resolution, err := resolver.ResolvePath(context.Background(), "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.Equal(t, []Layer{{
+		Name:   "dm-0",
+		Kind:   "multipath",
+		Detail: "mpatha, wwid 3600508b400105e210000900000490000, failed paths sdd",
+		Slaves: []string{"sdb", "sdc", "sdd"},
+	}}, resolution.Layers)
+	var physical []string
+	for _, device := range resolution.PhysicalDevices {
+		physical = append(physical, device.Devnode())
+	}
+	require.Equal(t, []string{"/dev/sdb", "/dev/sdc"}, physical)
+}
+
+func TestDeviceResolver_DeviceInfo(t *testing.T) {
+	fs := &linkFs{
+		Fs:    afero.NewMemMapFs(),
+		links: map[string]string{"/sys/dev/block/9:0": "../../block/md0"},
+	}
+	writeFakeMdArray(fs, "md0", "raid1", "2", "sda", "sdb")
+	writeFakeSataDisk(fs, "sda")
+	writeFakeSataDisk(fs, "sdb")
+	afero.WriteFile(fs, "/sys/block/md0/queue/read_ahead_kb", []byte("256\n"), 0o644)
+
+	resolver := NewDeviceResolver(fs, DefaultSysfsRoot)
+	resolver.statPath = func(string) (uint64, string, error) {
+		return unix.Mkdev(9, 0), "", nil
+	}
+	infos, err := resolver.DeviceInfo(context.Background(), "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+		require.Empty(t, info.Errors)
+	}
+	// The array comes first, followed by its physical devices.
+	require.Equal(t, []string{"md0", "sda", "sdb"}, names)
+	require.Equal(t, "sata", infos[0].Transport)
+	require.Equal(t, "Samsung SSD 870, Samsung SSD 870", infos[0].Model)
+	require.Equal(t, uint64(256), infos[0].ReadAheadKB)
+	require.Equal(t, "mq-deadline", infos[1].Scheduler)
+
+	resolver.statPath = func(string) (uint64, string, error) {
+		return 0, "tmpfs", nil
+	}
+	_, err = resolver.DeviceInfo(context.Background(), "/tmp")
+	require.ErrorIs(t, err, ErrNotBlockDevice)
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/afero/mem"
+)
+
+// RetryPolicy bounds the retries of the reads of sysfs attributes failing
+// with a transient error, see RetryingFs.
+type RetryPolicy struct {
+	// Attempts is the number of times an attribute is read before giving
+	// up, the first one included. Values below 1 read it once.
+	Attempts int
+	// Delay is the wait before the first retry, doubled for each of the
+	// following ones.
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of the tune runs: it rides out the
+// link flaps of NVMe-over-Fabrics devices, which last a few hundreds of
+// milliseconds.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 4, Delay: 50 * time.Millisecond}
+
+// RetryingFs is a filesystem retrying the reads of the sysfs attributes which
+// fail with a transient error, EIO or EAGAIN, as returned by the attributes
+// of NVMe-over-Fabrics devices while their link flaps. The other errors, e.g.
+// ENOENT for the attributes a device doesn't expose, are permanent and
+// returned right away.
+//
+// Attributes are read whole when opened, so that a failure to read them is
+// retried along with opening them. Only the paths under the sysfs root are
+// retried, the others and the writes are passed through.
+type RetryingFs struct {
+	afero.Fs
+	root   string
+	policy RetryPolicy
+	sleep  func(time.Duration)
+}
+
+// NewRetryingFs returns a RetryingFs retrying the reads of the attributes of
+// the sysfs mounted at sysfsRoot, or at DefaultSysfsRoot if empty, from fs
+// according to policy.
+func NewRetryingFs(fs afero.Fs, sysfsRoot string, policy RetryPolicy) *RetryingFs {
+	if sysfsRoot == "" {
+		sysfsRoot = DefaultSysfsRoot
+	}
+	return &RetryingFs{
+		Fs:     fs,
+		root:   filepath.Clean(sysfsRoot),
+		policy: policy,
+		sleep:  time.Sleep,
+	}
+}
+
+func (r *RetryingFs) Name() string {
+	return "RetryingFs"
+}
+
+func (r *RetryingFs) retried(name string) bool {
+	return strings.HasPrefix(filepath.Clean(name), r.root+string(filepath.Separator))
+}
+
+// isTransientError returns whether err may go away if the read is retried.
+func isTransientError(err error) bool {
+	return errors.Is(err, syscall.EIO) || errors.Is(err, syscall.EAGAIN)
+}
+
+// retry calls fn until it succeeds, fails with a permanent error, or the
+// attempts of the policy are exhausted, and returns its last error.
+func (r *RetryingFs) retry(name string, fn func() error) error {
+	delay := r.policy.Delay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isTransientError(err) || attempt >= r.policy.Attempts {
+			return err
+		}
+		log.Debugf("Retrying the read of '%s' in %s after transient failure %d/%d: %v",
+			name, delay, attempt, r.policy.Attempts, err)
+		r.sleep(delay)
+		delay *= 2
+	}
+}
+
+func (r *RetryingFs) Stat(name string) (os.FileInfo, error) {
+	if !r.retried(name) {
+		return r.Fs.Stat(name)
+	}
+	var info os.FileInfo
+	err := r.retry(name, func() (err error) {
+		info, err = r.Fs.Stat(name)
+		return err
+	})
+	return info, err
+}
+
+// ReadlinkIfPossible reads the link through the underlying filesystem, see
+// afero.LinkReader.
+func (r *RetryingFs) ReadlinkIfPossible(name string) (string, error) {
+	reader, ok := r.Fs.(afero.LinkReader)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}
+	}
+	if !r.retried(name) {
+		return reader.ReadlinkIfPossible(name)
+	}
+	var target string
+	err := r.retry(name, func() (err error) {
+		target, err = reader.ReadlinkIfPossible(name)
+		return err
+	})
+	return target, err
+}
+
+func (r *RetryingFs) Open(name string) (afero.File, error) {
+	return r.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (r *RetryingFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 || !r.retried(name) {
+		return r.Fs.OpenFile(name, flag, perm)
+	}
+	// Directories are listed, and errors reported, by the underlying
+	// filesystem.
+	info, err := r.Stat(name)
+	if err != nil || info.IsDir() {
+		return r.Fs.OpenFile(name, flag, perm)
+	}
+	var content []byte
+	err = r.retry(name, func() (err error) {
+		content, err = afero.ReadFile(r.Fs, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := mem.CreateFile(filepath.Clean(name))
+	w := mem.NewFileHandle(data)
+	w.Write(content)
+	mem.SetMode(data, info.Mode())
+	return mem.NewReadOnlyFileHandle(data), nil
+}
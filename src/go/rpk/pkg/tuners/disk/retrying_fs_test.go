@@ -0,0 +1,136 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyFs is a filesystem whose files fail to read with err for the given
+// number of times, before succeeding.
+type flakyFs struct {
+	afero.Fs
+	err      error
+	failures int
+	opened   int
+}
+
+func (f *flakyFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	file, err := f.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	f.opened++
+	if f.failures == 0 {
+		return file, nil
+	}
+	f.failures--
+	return &flakyFile{File: file, err: &os.PathError{Op: "read", Path: name, Err: f.err}}, nil
+}
+
+func (f *flakyFs) Open(name string) (afero.File, error) {
+	return f.OpenFile(name, os.O_RDONLY, 0)
+}
+
+type flakyFile struct {
+	afero.File
+	err error
+}
+
+func (f *flakyFile) Read([]byte) (int, error) {
+	return 0, f.err
+}
+
+func TestRetryingFs(t *testing.T) {
+	const attribute = "/sys/block/nvme1n1/queue/nr_requests"
+	tests := []struct {
+		name       string
+		err        error
+		failures   int
+		wantErr    error
+		wantOpened int
+		wantSleeps []time.Duration
+	}{
+		{
+			name:       "shall read attributes which don't fail",
+			wantOpened: 1,
+		},
+		{
+			name:       "shall retry EIO failures with backoff",
+			err:        syscall.EIO,
+			failures:   2,
+			wantOpened: 3,
+			wantSleeps: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond},
+		},
+		{
+			name:       "shall retry EAGAIN failures",
+			err:        syscall.EAGAIN,
+			failures:   1,
+			wantOpened: 2,
+			wantSleeps: []time.Duration{10 * time.Millisecond},
+		},
+		{
+			name:       "shall give up once the attempts are exhausted",
+			err:        syscall.EIO,
+			failures:   5,
+			wantErr:    syscall.EIO,
+			wantOpened: 3,
+			wantSleeps: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond},
+		},
+		{
+			name:       "shall not retry permanent failures",
+			err:        syscall.EACCES,
+			failures:   1,
+			wantErr:    syscall.EACCES,
+			wantOpened: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyFs{Fs: afero.NewMemMapFs(), err: tt.err, failures: tt.failures}
+			require.NoError(t, afero.WriteFile(flaky.Fs, attribute, []byte("1023\n"), 0o644))
+			fs := NewRetryingFs(flaky, DefaultSysfsRoot, RetryPolicy{Attempts: 3, Delay: 10 * time.Millisecond})
+			var sleeps []time.Duration
+			fs.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			n, err := readSysfsUint(fs, attribute)
+			require.Equal(t, tt.wantOpened, flaky.opened)
+			require.Equal(t, tt.wantSleeps, sleeps)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, uint64(1023), n)
+		})
+	}
+}
+
+func TestRetryingFs_missing(t *testing.T) {
+	fs := NewRetryingFs(afero.NewMemMapFs(), DefaultSysfsRoot, RetryPolicy{Attempts: 3, Delay: time.Millisecond})
+	fs.sleep = func(time.Duration) { t.Fatal("missing attributes shall not be retried") }
+	_, err := readSysfsString(fs, "/sys/block/nvme1n1/queue/write_cache")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRetryingFs_outsideSysfs(t *testing.T) {
+	flaky := &flakyFs{Fs: afero.NewMemMapFs(), err: syscall.EIO, failures: 1}
+	require.NoError(t, afero.WriteFile(flaky.Fs, "/proc/sys/vm/swappiness", []byte("60\n"), 0o644))
+	fs := NewRetryingFs(flaky, DefaultSysfsRoot, RetryPolicy{Attempts: 3, Delay: time.Millisecond})
+	_, err := readSysfsString(fs, "/proc/sys/vm/swappiness")
+	require.ErrorIs(t, err, syscall.EIO)
+	require.Equal(t, 1, flaky.opened)
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+// SharedDevice is a physical device holding several paths.
+type SharedDevice struct {
+	Name         string
+	Major, Minor uint32
+	// Paths are the paths the device holds, in the order they were given.
+	Paths []string
+}
+
+// SharedDevices returns the physical devices holding more than one of the
+// given paths, resolved through the sysfs mounted at DefaultSysfsRoot. See
+// DeviceResolver.SharedDevices.
+func SharedDevices(ctx context.Context, paths []string, fs afero.Fs) ([]SharedDevice, error) {
+	return NewDeviceResolver(fs, DefaultSysfsRoot).SharedDevices(ctx, paths)
+}
+
+// SharedDevices returns the physical devices holding more than one of the
+// given paths, in the order of the first path each holds. Paths are resolved
+// with DevicesForDirectory down to their physical devices, through
+// device-mapper devices and md arrays, which are told apart by their device
+// numbers: paths on different partitions of a disk, or on LVM volumes of a
+// single disk, share it. Paths which fail to resolve don't stop the others
+// from being resolved, their errors are returned together along with the
+// shared devices of the others.
+func (r *DeviceResolver) SharedDevices(
+	ctx context.Context, paths []string,
+) ([]SharedDevice, error) {
+	var (
+		shared []*SharedDevice
+		byDev  = map[uint64]*SharedDevice{}
+		errs   *multierror.Error
+	)
+	for _, path := range paths {
+		physDevices, err := r.DevicesForDirectory(ctx, path)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("unable to resolve the devices of '%s': %w", path, err))
+			continue
+		}
+		for _, device := range physDevices {
+			dev, err := readSysfsString(r.fs, filepath.Join(device.Syspath(), "dev"))
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("unable to read the device numbers of '%s': %w", deviceName(device), err))
+				continue
+			}
+			major, minor, err := ParseDeviceNumbers(dev)
+			if err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			key := uint64(major)<<32 | uint64(minor)
+			s, ok := byDev[key]
+			if !ok {
+				s = &SharedDevice{Name: deviceName(device), Major: major, Minor: minor}
+				byDev[key] = s
+				shared = append(shared, s)
+			}
+			// A path given twice is listed once.
+			if n := len(s.Paths); n == 0 || s.Paths[n-1] != path {
+				s.Paths = append(s.Paths, path)
+			}
+		}
+	}
+	var devices []SharedDevice
+	for _, s := range shared {
+		if len(s.Paths) > 1 {
+			devices = append(devices, *s)
+		}
+	}
+	return devices, errs.ErrorOrNil()
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestDeviceResolver_SharedDevices(t *testing.T) {
+	fs := &linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/sys/dev/block/8:1":   "../../block/sda/sda1",
+			"/sys/dev/block/8:2":   "../../block/sda/sda2",
+			"/sys/dev/block/8:16":  "../../block/sdb",
+			"/sys/dev/block/253:0": "../../block/dm-0",
+			"/sys/dev/block/253:1": "../../block/dm-1",
+		},
+	}
+	writeFakeDevice(fs, "/sys/block/sda", "sda", false)
+	writeFakeDevice(fs, "/sys/block/sda/sda1", "sda1", true)
+	writeFakeDevice(fs, "/sys/block/sda/sda2", "sda2", true)
+	writeFakeStackedDevice(fs, "sdb")
+	writeFakeStackedDevice(fs, "nvme0n1")
+	// Two LVM volumes of a single disk.
+	writeFakeStackedDevice(fs, "dm-0", "nvme0n1")
+	writeFakeStackedDevice(fs, "dm-1", "nvme0n1")
+	for name, dev := range map[string]string{"sda": "8:0", "sdb": "8:16", "nvme0n1": "259:0"} {
+		afero.WriteFile(fs, filepath.Join("/sys/block", name, "dev"), []byte(dev+"\n"), 0o644)
+	}
+
+	resolver := NewDeviceResolver(fs, "")
+	resolver.statPath = func(path string) (uint64, string, error) {
+		switch path {
+		case "/mnt/data":
+			return unix.Mkdev(8, 1), "", nil
+		case "/mnt/wal":
+			return unix.Mkdev(8, 2), "", nil
+		case "/mnt/archive":
+			return unix.Mkdev(8, 16), "", nil
+		case "/mnt/cache":
+			return unix.Mkdev(253, 0), "", nil
+		case "/mnt/logs":
+			return unix.Mkdev(253, 1), "", nil
+		}
+		return 0, "", os.ErrNotExist
+	}
+
+	shared, err := resolver.SharedDevices(context.Background(), []string{
+		"/mnt/cache", "/mnt/data", "/mnt/archive", "/mnt/wal", "/mnt/logs",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []SharedDevice{
+		{Name: "nvme0n1", Major: 259, Minor: 0, Paths: []string{"/mnt/cache", "/mnt/logs"}},
+		{Name: "sda", Major: 8, Minor: 0, Paths: []string{"/mnt/data", "/mnt/wal"}},
+	}, shared)
+
+	shared, err = resolver.SharedDevices(context.Background(), []string{"/mnt/data", "/mnt/archive", "/mnt/data"})
+	require.NoError(t, err)
+	require.Empty(t, shared)
+
+	shared, err = resolver.SharedDevices(context.Background(), []string{"/mnt/data", "/mnt/gone", "/mnt/wal"})
+	require.Error(t, err)
+	require.Len(t, shared, 1)
+}
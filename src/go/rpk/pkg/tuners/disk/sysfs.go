@@ -0,0 +1,82 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// readSysfsString returns the value of the sysfs attribute at path without
+// its surrounding whitespace, e.g. its trailing newline. Empty attributes, and
+// those holding only whitespace, read as an empty string.
+func readSysfsString(fs afero.Fs, path string) (string, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// readSysfsUint returns the value of the sysfs attribute at path, an
+// unsigned integer, e.g. 'queue/nr_requests'. It fails if the attribute is
+// empty.
+func readSysfsUint(fs afero.Fs, path string) (uint64, error) {
+	value, err := readSysfsString(fs, path)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return 0, fmt.Errorf("'%s' is empty", path)
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected value '%s' in '%s', not an unsigned integer", value, path)
+	}
+	return n, nil
+}
+
+// ReadActiveOption returns the active option of the multiple choice sysfs
+// attribute at path, e.g. 'mq-deadline' for 'none [mq-deadline] kyber' in
+// 'queue/scheduler', see ReadSysfsBracketed.
+func ReadActiveOption(fs afero.Fs, path string) (string, error) {
+	active, _, err := ReadSysfsBracketed(fs, path)
+	return active, err
+}
+
+// ReadSysfsBracketed returns the active option of the multiple choice sysfs
+// attribute at path, the one between brackets, e.g. 'mq-deadline' for
+// 'none [mq-deadline] kyber' in 'queue/scheduler', along with all of the
+// options in order, the active one included. The only option of attributes
+// listing a single one is active, bracketed or not, e.g. 'none' for queues
+// without a scheduler. Empty attributes have no options and none active.
+func ReadSysfsBracketed(fs afero.Fs, path string) (active string, options []string, err error) {
+	value, err := readSysfsString(fs, path)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, field := range strings.Fields(value) {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") && len(field) > 2 {
+			if active != "" {
+				return "", nil, fmt.Errorf("more than one active option in '%s': '%s'", path, value)
+			}
+			field = field[1 : len(field)-1]
+			active = field
+		}
+		options = append(options, field)
+	}
+	if len(options) == 1 {
+		active = options[0]
+	}
+	return active, options, nil
+}
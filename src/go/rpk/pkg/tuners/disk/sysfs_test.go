@@ -0,0 +1,143 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const sysfsAttribute = "/sys/block/nvme0n1/queue/attribute"
+
+func TestReadSysfsString(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "trailing newline", content: "none\n", want: "none"},
+		{name: "surrounding whitespace", content: " \twrite back \n", want: "write back"},
+		{name: "empty", content: "", want: ""},
+		{name: "only whitespace", content: " \n\n", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, sysfsAttribute, []byte(tt.content), 0o644))
+			got, err := readSysfsString(fs, sysfsAttribute)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := readSysfsString(afero.NewMemMapFs(), sysfsAttribute)
+	require.Error(t, err)
+}
+
+func TestReadSysfsUint(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    uint64
+		wantErr bool
+	}{
+		{name: "trailing newline", content: "1023\n", want: 1023},
+		{name: "surrounding whitespace", content: "  128 \n", want: 128},
+		{name: "leading zeros", content: "0128\n", want: 128},
+		{name: "empty", content: "", wantErr: true},
+		{name: "only whitespace", content: " \n", wantErr: true},
+		{name: "negative", content: "-1\n", wantErr: true},
+		{name: "not a number", content: "none\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, sysfsAttribute, []byte(tt.content), 0o644))
+			got, err := readSysfsUint(fs, sysfsAttribute)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadSysfsBracketed(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantActive  string
+		wantOptions []string
+		wantErr     bool
+	}{
+		{
+			name:        "active in the middle",
+			content:     "none [mq-deadline] kyber\n",
+			wantActive:  "mq-deadline",
+			wantOptions: []string{"none", "mq-deadline", "kyber"},
+		},
+		{
+			name:        "active at the start",
+			content:     "[none] mq-deadline kyber bfq\n",
+			wantActive:  "none",
+			wantOptions: []string{"none", "mq-deadline", "kyber", "bfq"},
+		},
+		{
+			name:        "active at the end",
+			content:     "always madvise [never]\n",
+			wantActive:  "never",
+			wantOptions: []string{"always", "madvise", "never"},
+		},
+		{
+			name:        "single unbracketed option",
+			content:     "none\n",
+			wantActive:  "none",
+			wantOptions: []string{"none"},
+		},
+		{
+			name:        "single bracketed option",
+			content:     "[none]\n",
+			wantActive:  "none",
+			wantOptions: []string{"none"},
+		},
+		{
+			name:        "no active option",
+			content:     "noop deadline cfq\n",
+			wantOptions: []string{"noop", "deadline", "cfq"},
+		},
+		{
+			name:        "extra whitespace",
+			content:     "  none\t[kyber]  \n",
+			wantActive:  "kyber",
+			wantOptions: []string{"none", "kyber"},
+		},
+		{name: "empty", content: ""},
+		{name: "only whitespace", content: " \n"},
+		{name: "several active options", content: "[none] [kyber]\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, sysfsAttribute, []byte(tt.content), 0o644))
+			active, options, err := ReadSysfsBracketed(fs, sysfsAttribute)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantActive, active)
+			require.Equal(t, tt.wantOptions, options)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+)
+
+// thinPoolSuffix suffixes the device-mapper name and UUID of the thin-pool
+// target of LVM thin pools, e.g. 'vg0-pool-tpool', which the thin volumes of
+// the pool are stacked on.
+const thinPoolSuffix = "-tpool"
+
+// ThinVolume describes an LVM thin volume, whose blocks are allocated from
+// its thin pool on first write. Pools may be overcommitted, in which case
+// writes to any of their volumes fail once the pool is full, with I/O
+// errors that look like a disk failure.
+type ThinVolume struct {
+	// Name is the device-mapper name of the volume, e.g. 'vg0-data'.
+	Name string
+	// Pool is the device-mapper name of the thin-pool target, e.g.
+	// 'vg0-pool-tpool', and PoolDevice its kernel name, e.g. 'dm-2'.
+	Pool       string
+	PoolDevice string
+}
+
+// thinVolumeFromSystemPath returns the thin volume details of the device at
+// syspath, or nil if the device is not a thin volume: a device-mapper device
+// stacked on a thin-pool target. Only LVM pools are identified, the thin
+// targets created with dmsetup alone can't be told apart from sysfs.
+func (r *DeviceResolver) thinVolumeFromSystemPath(syspath string, slaves []string) *ThinVolume {
+	name := deviceMapperName(syspath, r.fs)
+	if name == "" || strings.HasSuffix(name, thinPoolSuffix) {
+		return nil
+	}
+	for _, slave := range slaves {
+		slavePath := r.slaveSystemPath(syspath, slave)
+		pool := deviceMapperName(slavePath, r.fs)
+		uuid, _ := readIdentityAttribute(r.fs, filepath.Join(slavePath, "dm", "uuid"))
+		if strings.HasSuffix(pool, thinPoolSuffix) || strings.HasSuffix(uuid, thinPoolSuffix) {
+			return &ThinVolume{Name: name, Pool: pool, PoolDevice: slave}
+		}
+	}
+	return nil
+}
+
+// The modes of a thin pool, see ThinPoolStatus.Mode.
+const (
+	ThinPoolModeReadWrite      = "rw"
+	ThinPoolModeReadOnly       = "ro"
+	ThinPoolModeOutOfDataSpace = "out_of_data_space"
+	ThinPoolModeFail           = "Fail"
+)
+
+// ThinPoolStatus is the status of a thin pool as reported by the kernel,
+// see the thin-provisioning documentation of the device-mapper.
+type ThinPoolStatus struct {
+	TransactionID int64
+	// The usage of the metadata and data devices of the pool, in blocks.
+	UsedMetadataBlocks  uint64
+	TotalMetadataBlocks uint64
+	UsedDataBlocks      uint64
+	TotalDataBlocks     uint64
+	// Mode is 'rw', 'ro' once the metadata can't be changed anymore,
+	// 'out_of_data_space' or 'Fail', in which case the usage is unknown.
+	Mode string
+	// ErrorIfNoSpace is set if writes fail as soon as the pool is full,
+	// rather than being queued until it's extended.
+	ErrorIfNoSpace bool
+	// NeedsCheck is set when the metadata must be repaired.
+	NeedsCheck bool
+}
+
+// DataUsedPercent returns the percentage of the data blocks of the pool in
+// use.
+func (s *ThinPoolStatus) DataUsedPercent() float64 {
+	return usedPercent(s.UsedDataBlocks, s.TotalDataBlocks)
+}
+
+// MetadataUsedPercent returns the percentage of the metadata blocks of the
+// pool in use.
+func (s *ThinPoolStatus) MetadataUsedPercent() float64 {
+	return usedPercent(s.UsedMetadataBlocks, s.TotalMetadataBlocks)
+}
+
+func usedPercent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) * 100 / float64(total)
+}
+
+// ParseThinPoolStatus parses the status of a thin-pool target as printed by
+// 'dmsetup status', e.g.
+//
+//	0 209715200 thin-pool 3 1203/16384 51200/102400 - rw no_discard_passdown queue_if_no_space - 1024
+//
+// optionally prefixed with the name of the device, as dmsetup does when
+// listing all of them.
+func ParseThinPoolStatus(line string) (*ThinPoolStatus, error) {
+	fields := strings.Fields(line)
+	if len(fields) > 0 && strings.HasSuffix(fields[0], ":") {
+		fields = fields[1:]
+	}
+	if len(fields) < 4 || fields[2] != "thin-pool" {
+		return nil, fmt.Errorf("not a thin-pool status: '%s'", line)
+	}
+	// The fields following the target type are the transaction id, the
+	// metadata and data usages, the held metadata root, the mode and the
+	// features of the pool.
+	fields = fields[3:]
+	if fields[0] == ThinPoolModeFail {
+		return &ThinPoolStatus{Mode: ThinPoolModeFail}, nil
+	}
+	if len(fields) < 5 {
+		return nil, fmt.Errorf("malformed thin-pool status: '%s'", line)
+	}
+	transactionID, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed thin-pool transaction id '%s': %w", fields[0], err)
+	}
+	status := &ThinPoolStatus{TransactionID: transactionID, Mode: fields[4]}
+	status.UsedMetadataBlocks, status.TotalMetadataBlocks, err = parseBlocksUsage(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	status.UsedDataBlocks, status.TotalDataBlocks, err = parseBlocksUsage(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields[5:] {
+		switch field {
+		case "error_if_no_space":
+			status.ErrorIfNoSpace = true
+		case "needs_check":
+			status.NeedsCheck = true
+		}
+	}
+	return status, nil
+}
+
+// parseBlocksUsage parses a '<used>/<total>' blocks usage.
+func parseBlocksUsage(field string) (used, total uint64, err error) {
+	u, t, ok := strings.Cut(field, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("malformed thin-pool blocks usage '%s'", field)
+	}
+	if used, err = strconv.ParseUint(u, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("malformed thin-pool blocks usage '%s': %w", field, err)
+	}
+	if total, err = strconv.ParseUint(t, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("malformed thin-pool blocks usage '%s': %w", field, err)
+	}
+	return used, total, nil
+}
+
+// ReadThinPoolStatus returns the status of the thin pool with the given
+// device-mapper name, from 'dmsetup status', as sysfs doesn't expose it. It
+// requires root privileges.
+func ReadThinPoolStatus(proc os.Proc, timeout time.Duration, pool string) (*ThinPoolStatus, error) {
+	lines, err := proc.RunWithSystemLdPath(timeout, "dmsetup", "status", pool)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the status of thin pool '%s': %w", pool, err)
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return ParseThinPoolStatus(line)
+		}
+	}
+	return nil, fmt.Errorf("empty status of thin pool '%s'", pool)
+}
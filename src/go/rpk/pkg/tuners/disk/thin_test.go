@@ -0,0 +1,199 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFakeDm creates the device-mapper device name, named dmName, with the
+// given UUID, stacked on slaves.
+func writeFakeDm(fs afero.Fs, name, dmName, uuid string, slaves ...string) {
+	writeFakeStackedDevice(fs, name, slaves...)
+	afero.WriteFile(fs, "/sys/block/"+name+"/dm/name", []byte(dmName+"\n"), 0o644)
+	afero.WriteFile(fs, "/sys/block/"+name+"/dm/uuid", []byte(uuid+"\n"), 0o644)
+}
+
+func TestDeviceResolver_thinVolumes(t *testing.T) {
+	tests := []struct {
+		name        string
+		before      func(*linkFs)
+		expThin     *ThinVolume
+		expPhysical []string
+	}{
+		{
+			name: "shall identify an LVM thin volume",
+			before: func(fs *linkFs) {
+				// 'lvcreate --thin -L 100G vg0/pool' then
+				// 'lvcreate --thin -V 1T -n data vg0/pool'.
+				writeFakeDm(fs, "dm-3", "vg0-data", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcQ2s9VbH4mN7pL1kJ6wXzY8aB5cD0eFgH", "dm-2")
+				writeFakeDm(fs, "dm-2", "vg0-pool-tpool", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcR4t6UvW8xY0zA2bC4dE6fG8hJ0kL2mN4-tpool", "dm-0", "dm-1")
+				writeFakeDm(fs, "dm-0", "vg0-pool_tmeta", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcS5u7WxY9zA1bC3dE5fG7hJ9kL1mN3pQ5-tmeta", "nvme0n1")
+				writeFakeDm(fs, "dm-1", "vg0-pool_tdata", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcT6v8XyZ0aB2cD4eF6gH8jK0lM2nP4qR6-tdata", "nvme0n1")
+				writeFakeStackedDevice(fs, "nvme0n1")
+			},
+			expThin: &ThinVolume{
+				Name:       "vg0-data",
+				Pool:       "vg0-pool-tpool",
+				PoolDevice: "dm-2",
+			},
+			expPhysical: []string{"/dev/nvme0n1"},
+		},
+		{
+			name: "shall identify a thin volume from the UUID of its pool",
+			before: func(fs *linkFs) {
+				writeFakeDm(fs, "dm-3", "vg0-data", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcQ2s9VbH4mN7pL1kJ6wXzY8aB5cD0eFgH", "dm-2")
+				writeFakeDm(fs, "dm-2", "pool", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcR4t6UvW8xY0zA2bC4dE6fG8hJ0kL2mN4-tpool", "sda")
+				writeFakeStackedDevice(fs, "sda")
+			},
+			expThin: &ThinVolume{
+				Name:       "vg0-data",
+				Pool:       "pool",
+				PoolDevice: "dm-2",
+			},
+			expPhysical: []string{"/dev/sda"},
+		},
+		{
+			name: "shall not identify a linear LVM volume as thin",
+			before: func(fs *linkFs) {
+				writeFakeDm(fs, "dm-3", "vg0-data", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcQ2s9VbH4mN7pL1kJ6wXzY8aB5cD0eFgH", "sda")
+				writeFakeStackedDevice(fs, "sda")
+			},
+			expPhysical: []string{"/dev/sda"},
+		},
+		{
+			name: "shall not identify a volume stacked on another volume as thin",
+			before: func(fs *linkFs) {
+				writeFakeDm(fs, "dm-3", "data_crypt", "CRYPT-LUKS2-2f7e1a9c3b5d4e6f8a0b1c2d3e4f5a6b-data_crypt", "dm-2")
+				writeFakeDm(fs, "dm-2", "vg0-data", "LVM-Kx1dWdLq3xG5VfA0pYh7Eo2ZfM3Rn8TcQ2s9VbH4mN7pL1kJ6wXzY8aB5cD0eFgH", "sda")
+				writeFakeStackedDevice(fs, "sda")
+			},
+			expPhysical: []string{"/dev/sda"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+			tt.before(fs)
+			resolver := NewDeviceResolver(fs, "")
+			device, err := resolver.deviceFromSystemPath(context.Background(), "/sys/block/dm-3")
+			require.NoError(t, err)
+			require.Equal(t, tt.expThin, device.Thin())
+			devices, err := resolver.resolvePhysicalDevices(context.Background(), device)
+			require.NoError(t, err)
+			var devnodes []string
+			for _, d := range devices {
+				devnodes = append(devnodes, d.Devnode())
+			}
+			require.Equal(t, tt.expPhysical, devnodes)
+		})
+	}
+}
+
+func TestParseThinPoolStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             string
+		exp                *ThinPoolStatus
+		expDataPercent     float64
+		expMetadataPercent float64
+		expErr             bool
+	}{
+		{
+			name:   "healthy pool",
+			status: "0 209715200 thin-pool 3 1203/16384 51200/102400 - rw no_discard_passdown queue_if_no_space - 1024",
+			exp: &ThinPoolStatus{
+				TransactionID:       3,
+				UsedMetadataBlocks:  1203,
+				TotalMetadataBlocks: 16384,
+				UsedDataBlocks:      51200,
+				TotalDataBlocks:     102400,
+				Mode:                ThinPoolModeReadWrite,
+			},
+			expDataPercent:     50,
+			expMetadataPercent: 1203 * 100.0 / 16384,
+		},
+		{
+			name:   "full pool failing writes",
+			status: "vg0-pool-tpool: 0 209715200 thin-pool 12 4096/16384 102400/102400 - out_of_data_space discard_passdown error_if_no_space needs_check 1024",
+			exp: &ThinPoolStatus{
+				TransactionID:       12,
+				UsedMetadataBlocks:  4096,
+				TotalMetadataBlocks: 16384,
+				UsedDataBlocks:      102400,
+				TotalDataBlocks:     102400,
+				Mode:                ThinPoolModeOutOfDataSpace,
+				ErrorIfNoSpace:      true,
+				NeedsCheck:          true,
+			},
+			expDataPercent:     100,
+			expMetadataPercent: 25,
+		},
+		{
+			name:   "failed pool",
+			status: "0 209715200 thin-pool Fail",
+			exp:    &ThinPoolStatus{Mode: ThinPoolModeFail},
+		},
+		{
+			name:   "thin volume",
+			status: "0 2147483648 thin 104857600 2147483647",
+			expErr: true,
+		},
+		{
+			name:   "malformed usage",
+			status: "0 209715200 thin-pool 3 1203 51200/102400 - rw",
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := ParseThinPoolStatus(tt.status)
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.exp, status)
+			require.InDelta(t, tt.expDataPercent, status.DataUsedPercent(), 0.001)
+			require.InDelta(t, tt.expMetadataPercent, status.MetadataUsedPercent(), 0.001)
+		})
+	}
+}
+
+type dmsetupProc struct {
+	args   []string
+	output []string
+	err    error
+}
+
+func (p *dmsetupProc) RunWithSystemLdPath(_ time.Duration, command string, args ...string) ([]string, error) {
+	p.args = append([]string{command}, args...)
+	return p.output, p.err
+}
+
+func (*dmsetupProc) IsRunning(time.Duration, string) bool { return false }
+
+func TestReadThinPoolStatus(t *testing.T) {
+	proc := &dmsetupProc{output: []string{"0 209715200 thin-pool 3 1203/16384 92160/102400 - rw no_discard_passdown queue_if_no_space - 1024", ""}}
+	status, err := ReadThinPoolStatus(proc, time.Second, "vg0-pool-tpool")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dmsetup", "status", "vg0-pool-tpool"}, proc.args)
+	require.InDelta(t, 90, status.DataUsedPercent(), 0.001)
+
+	proc = &dmsetupProc{err: errors.New("permission denied")}
+	_, err = ReadThinPoolStatus(proc, time.Second, "vg0-pool-tpool")
+	require.Error(t, err)
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TransportType is the bus a drive is attached to the host through, which
+// sets what to expect from it: e.g. SAS and SATA disks have different queue
+// depths, and USB drives shouldn't back production data.
+type TransportType int
+
+const (
+	// TransportUnknown is the transport of the devices without a known bus
+	// in their device path, e.g. plain SCSI or Xen devices, and of those
+	// without a device, e.g. loop devices.
+	TransportUnknown TransportType = iota
+	TransportNVMe
+	TransportSATA
+	TransportSAS
+	TransportUSB
+	TransportVirtio
+	// TransportMixed is the transport of stacked devices whose physical
+	// devices are attached through different buses.
+	TransportMixed
+)
+
+func (t TransportType) String() string {
+	switch t {
+	case TransportNVMe:
+		return "nvme"
+	case TransportSATA:
+		return "sata"
+	case TransportSAS:
+		return "sas"
+	case TransportUSB:
+		return "usb"
+	case TransportVirtio:
+		return "virtio"
+	case TransportMixed:
+		return "mixed"
+	}
+	return "unknown"
+}
+
+// transportComponents map the components of the sysfs device paths to the
+// transport they belong to, in precedence order: e.g. a SATA drive in a USB
+// enclosure is a USB device, and a virtio-scsi disk a virtio one.
+var transportComponents = []struct {
+	transport TransportType
+	pattern   *regexp.Regexp
+}{
+	// e.g. '/sys/devices/pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/...'.
+	{TransportUSB, regexp.MustCompile(`^usb\d+$`)},
+	// e.g. '/sys/devices/pci0000:00/0000:00:04.0/virtio1'.
+	{TransportVirtio, regexp.MustCompile(`^virtio\d+$`)},
+	// e.g. '/sys/devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0',
+	// or the subsystem of native multipath namespaces.
+	{TransportNVMe, regexp.MustCompile(`^nvme(-subsystem|-fabrics)?$`)},
+	// e.g. '/sys/devices/pci0000:00/.../host0/port-0:0/end_device-0:0/...'.
+	{TransportSAS, regexp.MustCompile(`^end_device-\d+:\d+(:\d+)?$`)},
+	// e.g. '/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/...'.
+	{TransportSATA, regexp.MustCompile(`^ata\d+$`)},
+}
+
+// Transport returns the bus the device is attached through, as inferred from
+// the components of the real path of its sysfs device, e.g.
+// '/sys/block/sda/device'. Partitions are attached through the bus of their
+// disk. Stacked devices return the transport shared by their physical
+// devices, or TransportMixed if they differ, e.g. for an md array of SATA and
+// USB drives.
+func (d *blockDevice) Transport(ctx context.Context) (TransportType, error) {
+	if d.resolver == nil {
+		return TransportUnknown, nil
+	}
+	slaves, err := readSlaves(d.syspath, d.resolver.fs)
+	if err != nil {
+		return TransportUnknown, err
+	}
+	if len(slaves) == 0 {
+		return d.leafTransport()
+	}
+	physDevices, err := d.resolver.resolvePhysicalDevices(ctx, d)
+	if err != nil {
+		return TransportUnknown, err
+	}
+	transport, first := TransportUnknown, true
+	for _, physDevice := range physDevices {
+		leaf, ok := physDevice.(*blockDevice)
+		if !ok {
+			continue
+		}
+		leafTransport, err := leaf.leafTransport()
+		if err != nil {
+			return TransportUnknown, err
+		}
+		if first {
+			transport, first = leafTransport, false
+		} else if leafTransport != transport {
+			return TransportMixed, nil
+		}
+	}
+	return transport, nil
+}
+
+func (d *blockDevice) leafTransport() (TransportType, error) {
+	path, err := attributePath(d.syspath, "device", d.resolver.fs)
+	if err != nil {
+		return TransportUnknown, err
+	}
+	if !exists(d.resolver.fs, path) {
+		return TransportUnknown, nil
+	}
+	return transportOf(resolveLink(d.resolver.fs, path)), nil
+}
+
+// transportOf returns the transport of the device at the given real sysfs
+// device path.
+func transportOf(devicePath string) TransportType {
+	components := strings.Split(filepath.ToSlash(devicePath), "/")
+	for _, c := range transportComponents {
+		for _, component := range components {
+			if c.pattern.MatchString(component) {
+				log.Debugf("'%s' is attached through %s", devicePath, c.transport)
+				return c.transport
+			}
+		}
+	}
+	return TransportUnknown
+}
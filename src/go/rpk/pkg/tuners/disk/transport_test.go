@@ -0,0 +1,139 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// The targets of the '/sys/block/<dev>/device' links of each transport.
+const (
+	nvmeDeviceLink   = "../../devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0"
+	sataDeviceLink   = "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0"
+	sasDeviceLink    = "../../devices/pci0000:00/0000:00:01.0/0000:01:00.0/host0/port-0:0/end_device-0:0/target0:0:0/0:0:0:0"
+	usbDeviceLink    = "../../devices/pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0"
+	virtioDeviceLink = "../../devices/pci0000:00/0000:00:04.0/virtio1"
+	scsiDeviceLink   = "../../devices/platform/host0/target0:0:0/0:0:0:0"
+)
+
+func TestBlockDevice_Transport(t *testing.T) {
+	writeTransport := func(fs *linkFs, device, link string) {
+		writeFakeStackedDevice(fs, device)
+		fs.MkdirAll(filepath.Join("/sys/block", device, "device"), 0o755)
+		fs.links[filepath.Join("/sys/block", device, "device")] = link
+	}
+	tests := []struct {
+		name   string
+		device string
+		before func(*linkFs)
+		want   TransportType
+	}{
+		{
+			name:   "shall detect NVMe devices",
+			device: "nvme0n1",
+			before: func(fs *linkFs) { writeTransport(fs, "nvme0n1", nvmeDeviceLink) },
+			want:   TransportNVMe,
+		},
+		{
+			name:   "shall detect native NVMe multipath namespaces",
+			device: "nvme0n1",
+			before: func(fs *linkFs) {
+				writeTransport(fs, "nvme0n1", "../../devices/virtual/nvme-subsystem/nvme-subsys0")
+			},
+			want: TransportNVMe,
+		},
+		{
+			name:   "shall detect SATA devices",
+			device: "sda",
+			before: func(fs *linkFs) { writeTransport(fs, "sda", sataDeviceLink) },
+			want:   TransportSATA,
+		},
+		{
+			name:   "shall detect SAS devices",
+			device: "sda",
+			before: func(fs *linkFs) { writeTransport(fs, "sda", sasDeviceLink) },
+			want:   TransportSAS,
+		},
+		{
+			name:   "shall detect USB devices",
+			device: "sda",
+			before: func(fs *linkFs) { writeTransport(fs, "sda", usbDeviceLink) },
+			want:   TransportUSB,
+		},
+		{
+			name:   "shall detect virtio devices",
+			device: "vda",
+			before: func(fs *linkFs) { writeTransport(fs, "vda", virtioDeviceLink) },
+			want:   TransportVirtio,
+		},
+		{
+			name:   "shall report other SCSI devices as unknown",
+			device: "sda",
+			before: func(fs *linkFs) { writeTransport(fs, "sda", scsiDeviceLink) },
+			want:   TransportUnknown,
+		},
+		{
+			name:   "shall report devices without a device as unknown",
+			device: "loop0",
+			before: func(fs *linkFs) { writeFakeStackedDevice(fs, "loop0") },
+			want:   TransportUnknown,
+		},
+		{
+			name:   "shall return the transport shared by the physical devices",
+			device: "md0",
+			before: func(fs *linkFs) {
+				writeFakeStackedDevice(fs, "md0", "sda", "sdb")
+				writeTransport(fs, "sda", sataDeviceLink)
+				writeTransport(fs, "sdb", sataDeviceLink)
+			},
+			want: TransportSATA,
+		},
+		{
+			name:   "shall detect arrays of devices on different buses",
+			device: "md0",
+			before: func(fs *linkFs) {
+				writeFakeStackedDevice(fs, "md0", "sda", "sdb")
+				writeTransport(fs, "sda", sataDeviceLink)
+				writeTransport(fs, "sdb", usbDeviceLink)
+			},
+			want: TransportMixed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			got, err := device.Transport(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBlockDevice_Transport_partition(t *testing.T) {
+	fs := &linkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+	writeFakeDevice(fs, "/sys/block/sda", "sda", false)
+	writeFakeDevice(fs, "/sys/block/sda/sda1", "sda1", true)
+	fs.MkdirAll("/sys/block/sda/device", 0o755)
+	fs.links["/sys/block/sda/device"] = usbDeviceLink
+
+	device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), "/sys/block/sda/sda1")
+	require.NoError(t, err)
+	got, err := device.Transport(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, TransportUSB, got)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// WriteCache returns the write cache mode of the device, as reported by its
+// 'queue/write_cache' attribute: CachePolicyWriteBack if the kernel flushes
+// the volatile cache of the drive on fsync, or CachePolicyWriteThrough. It's
+// empty if the device doesn't expose it. Stacked devices return the mode of
+// each of their physical devices, e.g. 'sda: write back, sdb: write through'.
+func (d *blockDevice) WriteCache() (string, error) {
+	if d.resolver == nil {
+		return "", nil
+	}
+	slaves, err := readSlaves(d.syspath, d.resolver.fs)
+	if err != nil {
+		return "", err
+	}
+	if len(slaves) == 0 {
+		return d.leafWriteCache()
+	}
+	physDevices, err := d.resolver.resolvePhysicalDevices(context.Background(), d)
+	if err != nil {
+		return "", err
+	}
+	var modes []string
+	for _, physDevice := range physDevices {
+		leaf, ok := physDevice.(*blockDevice)
+		if !ok {
+			continue
+		}
+		mode, err := leaf.leafWriteCache()
+		if err != nil {
+			return "", err
+		}
+		if mode != "" {
+			modes = append(modes, fmt.Sprintf("%s: %s", strings.TrimPrefix(leaf.devnode, "/dev/"), mode))
+		}
+	}
+	return strings.Join(modes, ", "), nil
+}
+
+func (d *blockDevice) leafWriteCache() (string, error) {
+	path, err := attributePath(d.syspath, filepath.Join("queue", "write_cache"), d.resolver.fs)
+	if err != nil {
+		return "", err
+	}
+	mode, err := readIdentityAttribute(d.resolver.fs, path)
+	if err != nil {
+		return "", err
+	}
+	switch mode {
+	case "", CachePolicyWriteBack, CachePolicyWriteThrough:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown write cache mode '%s' in '%s'", mode, path)
+}
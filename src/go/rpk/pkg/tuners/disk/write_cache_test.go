@@ -0,0 +1,96 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockDevice_WriteCache(t *testing.T) {
+	writeCache := func(fs afero.Fs, device, mode string) {
+		afero.WriteFile(fs, filepath.Join("/sys/block", device, "queue", "write_cache"), []byte(mode+"\n"), 0o644)
+	}
+	tests := []struct {
+		name    string
+		device  string
+		before  func(afero.Fs)
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "shall read a write back cache",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeCache(fs, "sda", "write back")
+			},
+			want: CachePolicyWriteBack,
+		},
+		{
+			name:   "shall read a write through cache",
+			device: "nvme0n1",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "nvme0n1")
+				writeCache(fs, "nvme0n1", "write through")
+			},
+			want: CachePolicyWriteThrough,
+		},
+		{
+			name:   "shall return nothing for devices not exposing it",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+			},
+		},
+		{
+			name:   "shall fail on unknown modes",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeCache(fs, "sda", "write around")
+			},
+			wantErr: true,
+		},
+		{
+			name:   "shall return the mode of each physical device",
+			device: "md0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "md0", "sda", "sdb", "sdc")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+				writeFakeStackedDevice(fs, "sdc")
+				writeCache(fs, "md0", "write back")
+				writeCache(fs, "sda", "write back")
+				writeCache(fs, "sdb", "write through")
+			},
+			want: "sda: write back, sdb: write through",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			got, err := device.WriteCache()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
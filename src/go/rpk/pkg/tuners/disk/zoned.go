@@ -0,0 +1,91 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// The zoned models of block devices, as reported by their 'queue/zoned'
+// attribute. Zoned devices are mostly SMR (shingled magnetic recording)
+// drives, whose zones must be written sequentially.
+const (
+	// ZonedNone is a regular block device.
+	ZonedNone = "none"
+	// ZonedHostAware is a device accepting random writes, which it handles
+	// by rewriting whole zones at the cost of severe write latency spikes.
+	ZonedHostAware = "host-aware"
+	// ZonedHostManaged is a ZBC/ZAC device rejecting random writes, which
+	// can't be used as a regular block device.
+	ZonedHostManaged = "host-managed"
+)
+
+// zonedModelRank orders the zoned models from the least to the most
+// restrictive one.
+var zonedModelRank = map[string]int{
+	ZonedNone:        0,
+	ZonedHostAware:   1,
+	ZonedHostManaged: 2,
+}
+
+// ZonedModel returns the zoned model of the device: ZonedNone, ZonedHostAware
+// or ZonedHostManaged, or, for stacked devices, the most restrictive of their
+// physical devices. Devices not exposing it are reported as ZonedNone.
+func (d *blockDevice) ZonedModel() (string, error) {
+	if d.resolver == nil {
+		return ZonedNone, nil
+	}
+	slaves, err := readSlaves(d.syspath, d.resolver.fs)
+	if err != nil {
+		return "", err
+	}
+	if len(slaves) == 0 {
+		return d.leafZonedModel()
+	}
+	physDevices, err := d.resolver.resolvePhysicalDevices(context.Background(), d)
+	if err != nil {
+		return "", err
+	}
+	zoned := ZonedNone
+	for _, physDevice := range physDevices {
+		leaf, ok := physDevice.(*blockDevice)
+		if !ok {
+			continue
+		}
+		model, err := leaf.leafZonedModel()
+		if err != nil {
+			return "", err
+		}
+		if zonedModelRank[model] > zonedModelRank[zoned] {
+			zoned = model
+		}
+	}
+	return zoned, nil
+}
+
+func (d *blockDevice) leafZonedModel() (string, error) {
+	path, err := attributePath(d.syspath, filepath.Join("queue", "zoned"), d.resolver.fs)
+	if err != nil {
+		return "", err
+	}
+	model, err := readIdentityAttribute(d.resolver.fs, path)
+	if err != nil {
+		return "", err
+	}
+	if model == "" {
+		return ZonedNone, nil
+	}
+	if _, ok := zonedModelRank[model]; !ok {
+		return "", fmt.Errorf("unknown zoned model '%s' in '%s'", model, path)
+	}
+	return model, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockDevice_ZonedModel(t *testing.T) {
+	writeZoned := func(fs afero.Fs, device, zoned string) {
+		afero.WriteFile(fs, filepath.Join("/sys/block", device, "queue", "zoned"), []byte(zoned+"\n"), 0o644)
+	}
+	tests := []struct {
+		name    string
+		device  string
+		before  func(afero.Fs)
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "shall read a regular device",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeZoned(fs, "sda", "none")
+			},
+			want: ZonedNone,
+		},
+		{
+			name:   "shall read a host-aware device",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeZoned(fs, "sda", "host-aware")
+			},
+			want: ZonedHostAware,
+		},
+		{
+			name:   "shall read a host-managed device",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeZoned(fs, "sda", "host-managed")
+			},
+			want: ZonedHostManaged,
+		},
+		{
+			name:   "shall report devices not exposing it as regular",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+			},
+			want: ZonedNone,
+		},
+		{
+			name:   "shall fail on unknown models",
+			device: "sda",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "sda")
+				writeZoned(fs, "sda", "drive-managed")
+			},
+			wantErr: true,
+		},
+		{
+			name:   "shall return the most restrictive model of the physical devices",
+			device: "dm-0",
+			before: func(fs afero.Fs) {
+				writeFakeStackedDevice(fs, "dm-0", "sda", "sdb", "sdc")
+				writeFakeStackedDevice(fs, "sda")
+				writeFakeStackedDevice(fs, "sdb")
+				writeFakeStackedDevice(fs, "sdc")
+				writeZoned(fs, "dm-0", "none")
+				writeZoned(fs, "sda", "none")
+				writeZoned(fs, "sdb", "host-aware")
+				writeZoned(fs, "sdc", "none")
+			},
+			want: ZonedHostAware,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			device, err := NewDeviceResolver(fs, "/sys").deviceFromSystemPath(context.Background(), filepath.Join("/sys/block", tt.device))
+			require.NoError(t, err)
+			got, err := device.ZonedModel()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
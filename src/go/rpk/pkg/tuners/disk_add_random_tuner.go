@@ -0,0 +1,119 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// NewDeviceAddRandomTuner returns a tuner stopping the I/O completions of the
+// device from feeding the kernel entropy pool, which costs CPU time on every
+// request and is pointless for SSDs: their timings are hardly random.
+// Rotational devices are left untouched, and reported as skipped.
+func NewDeviceAddRandomTuner(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) Tunable {
+	return &nonRotationalTunable{
+		checkedTunable: NewCheckedTunable(
+			NewDeviceAddRandomChecker(device, deviceFeatures),
+			func() TuneResult {
+				return tuneAddRandom(fs, device, deviceFeatures, executor)
+			},
+			func() (bool, string) {
+				return true, ""
+			},
+			executor.IsLazy(),
+		).(*checkedTunable),
+		device:         device,
+		attribute:      "add_random",
+		deviceFeatures: deviceFeatures,
+	}
+}
+
+// nonRotationalTunable skips the rotational devices before checking them, so
+// that they are reported apart from the devices already tuned.
+type nonRotationalTunable struct {
+	*checkedTunable
+	device         string
+	attribute      string
+	deviceFeatures disk.DeviceFeatures
+}
+
+func (t *nonRotationalTunable) Tune(ctx context.Context) TuneResult {
+	rotational, err := t.deviceFeatures.GetRotational(t.device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if rotational {
+		log.Infof("Keeping %s of rotational device '%s'", t.attribute, t.device)
+		return NewTuneNotApplied("rotational device")
+	}
+	return t.checkedTunable.Tune(ctx)
+}
+
+func tuneAddRandom(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) TuneResult {
+	featureFile, err := deviceFeatures.GetAddRandomFeatureFile(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if featureFile == "" {
+		log.Infof("Skipping '%s' as it doesn't expose its add_random", device)
+		return NewTuneResult(false)
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, "0")
+	if isPermissionDenied(err) {
+		log.Infof("Unable to set '%s' add_random: %v", device, err)
+		return NewTuneNotApplied("not applied, permission denied")
+	}
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if !written {
+		return newTuneUnchanged()
+	}
+	log.Infof("Disabling the entropy contribution of device '%s'", device)
+
+	return NewTuneResult(false)
+}
+
+func NewAddRandomTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+) Tunable {
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+	return newDiskTuner(
+		fs,
+		directories,
+		devices,
+		blockDevices,
+		executor,
+		func(device string) Tunable {
+			return NewDeviceAddRandomTuner(fs, device, deviceFeatures, executor)
+		},
+		deviceFeatures.GetAddRandomFeatureFile,
+		nil,
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const fAddRandom = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue/add_random"
+
+func TestDeviceAddRandomTuner_Tune(t *testing.T) {
+	tests := []struct {
+		name        string
+		addRandom   string
+		rotational  bool
+		want        string
+		wantCurrent string
+		wantOk      bool
+		wantReason  string
+		wantTuned   bool
+	}{
+		{
+			name:        "shall disable add_random of SSDs",
+			addRandom:   "1",
+			want:        "0",
+			wantCurrent: "1",
+			wantTuned:   true,
+		},
+		{
+			name:        "shall skip SSDs already tuned",
+			addRandom:   "0",
+			want:        "0",
+			wantCurrent: "0",
+			wantOk:      true,
+		},
+		{
+			name:        "shall keep add_random of HDDs",
+			addRandom:   "1",
+			rotational:  true,
+			want:        "1",
+			wantCurrent: "1 (rotational)",
+			wantOk:      true,
+			wantReason:  "rotational device",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			afero.WriteFile(fs, fAddRandom, []byte(tt.addRandom+"\n"), 0o644)
+			deviceFeatures := &deviceFeaturesMock{
+				getAddRandomFeatureFile: func(string) (string, error) {
+					return fAddRandom, nil
+				},
+				getAddRandom: func(string) (int, error) {
+					content, err := afero.ReadFile(fs, fAddRandom)
+					require.NoError(t, err)
+					return int(content[0] - '0'), nil
+				},
+				getRotational: func(string) (bool, error) {
+					return tt.rotational, nil
+				},
+			}
+
+			result := NewDeviceAddRandomChecker("fake", deviceFeatures).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+
+			tuner := NewDeviceAddRandomTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+			res := tuner.Tune(context.Background())
+			require.NoError(t, res.Error())
+			require.Equal(t, tt.wantReason, res.NotAppliedReason())
+			_, _, tuned := tuner.(checkedValuer).checkedValues()
+			require.Equal(t, tt.wantTuned, tuned)
+			setValue, err := afero.ReadFile(fs, fAddRandom)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(setValue[:1]))
+		})
+	}
+}
+
+func TestDeviceAddRandomTuner_dryRun(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, fAddRandom, []byte("1\n"), 0o644)
+	deviceFeatures := &deviceFeaturesMock{
+		getAddRandomFeatureFile: func(string) (string, error) {
+			return fAddRandom, nil
+		},
+		getAddRandom: func(string) (int, error) {
+			return 1, nil
+		},
+	}
+	exec := executors.NewDryRunExecutor()
+	res := NewDeviceAddRandomTuner(fs, "fake", deviceFeatures, exec).Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Len(t, exec.Changes(), 1)
+	require.Equal(t, fAddRandom, exec.Changes()[0].Path)
+	setValue, err := afero.ReadFile(fs, fAddRandom)
+	require.NoError(t, err)
+	require.Equal(t, "1\n", string(setValue))
+}
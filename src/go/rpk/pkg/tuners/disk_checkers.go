@@ -10,10 +10,15 @@
 package tuners
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
@@ -36,15 +41,17 @@ func CreateDirectoryCheckers(
 func NewDeviceNomergesChecker(
 	device string, deviceFeatures disk.DeviceFeatures,
 ) Checker {
-	return NewEqualityChecker(
-		NomergesChecker,
-		fmt.Sprintf("Disk '%s' nomerges tuned", device),
-		Warning,
-		true,
-		func() (interface{}, error) {
+	return &devicesValueChecker{
+		id:       NomergesChecker,
+		desc:     fmt.Sprintf("Disk '%s' nomerges tuned", device),
+		required: "2 on non-rotational devices",
+		devices: func() ([]string, error) {
+			return []string{device}, nil
+		},
+		check: func(device string) (bool, string, error) {
 			return checkDeviceNomerges(deviceFeatures, device)
 		},
-	)
+	}
 }
 
 func NewDirectoryNomergesChecker(
@@ -52,41 +59,820 @@ func NewDirectoryNomergesChecker(
 	deviceFeatures disk.DeviceFeatures,
 	blockDevices disk.BlockDevices,
 ) Checker {
-	return NewEqualityChecker(
-		NomergesChecker,
-		fmt.Sprintf("Dir '%s' nomerges tuned", dir),
-		Warning,
-		true,
-		func() (interface{}, error) {
-			devices, err := blockDevices.GetDirectoryDevices(dir)
+	return &devicesValueChecker{
+		id:          NomergesChecker,
+		desc:        fmt.Sprintf("Dir '%s' nomerges tuned", dir),
+		required:    "2 on non-rotational devices",
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceNomerges(deviceFeatures, device)
+		},
+	}
+}
+
+func checkDeviceNomerges(
+	deviceFeatures disk.DeviceFeatures, device string,
+) (ok bool, current string, err error) {
+	featureFile, err := deviceFeatures.GetNomergesFeatureFile(device)
+	if err != nil {
+		return false, "", err
+	}
+	if featureFile == "" {
+		return true, "nomerges not exposed by the device", nil
+	}
+	nomerges, err := deviceFeatures.GetNomerges(device)
+	if err != nil {
+		return false, "", err
+	}
+	preferred, class, err := preferredNomerges(device, deviceFeatures)
+	if err != nil {
+		return false, "", err
+	}
+	if preferred < 0 {
+		return true, fmt.Sprintf("%d (%s device)", nomerges, class), nil
+	}
+	return nomerges == preferred, strconv.Itoa(nomerges), nil
+}
+
+func NewDeviceNrRequestsChecker(
+	device string, deviceFeatures disk.DeviceFeatures,
+) Checker {
+	return &devicesValueChecker{
+		id:       NrRequestsChecker,
+		desc:     fmt.Sprintf("Disk '%s' nr_requests tuned", device),
+		required: ">= hardware queue depth",
+		devices: func() ([]string, error) {
+			return []string{device}, nil
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceNrRequests(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDirectoryNrRequestsChecker(
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:          NrRequestsChecker,
+		desc:        fmt.Sprintf("Dir '%s' nr_requests tuned", dir),
+		required:    ">= hardware queue depth",
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceNrRequests(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDeviceAddRandomChecker(
+	device string, deviceFeatures disk.DeviceFeatures,
+) Checker {
+	return &devicesValueChecker{
+		id:       AddRandomChecker,
+		desc:     fmt.Sprintf("Disk '%s' add_random tuned", device),
+		required: "0 on non-rotational devices",
+		devices: func() ([]string, error) {
+			return []string{device}, nil
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceAddRandom(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDirectoryAddRandomChecker(
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:          AddRandomChecker,
+		desc:        fmt.Sprintf("Dir '%s' add_random tuned", dir),
+		required:    "0 on non-rotational devices",
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceAddRandom(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDeviceRqAffinityChecker(
+	device string, deviceFeatures disk.DeviceFeatures,
+) Checker {
+	return &devicesValueChecker{
+		id:       RqAffinityChecker,
+		desc:     fmt.Sprintf("Disk '%s' rq_affinity tuned", device),
+		required: "2 on non-rotational devices",
+		devices: func() ([]string, error) {
+			return []string{device}, nil
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceRqAffinity(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDirectoryRqAffinityChecker(
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:          RqAffinityChecker,
+		desc:        fmt.Sprintf("Dir '%s' rq_affinity tuned", dir),
+		required:    "2 on non-rotational devices",
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceRqAffinity(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDeviceMaxSectorsChecker(
+	device string, deviceFeatures disk.DeviceFeatures,
+) Checker {
+	return &devicesValueChecker{
+		id:       MaxSectorsChecker,
+		desc:     fmt.Sprintf("Disk '%s' max_sectors_kb tuned", device),
+		required: maxSectorsRequired,
+		devices: func() ([]string, error) {
+			return []string{device}, nil
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceMaxSectors(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDirectoryMaxSectorsChecker(
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:          MaxSectorsChecker,
+		desc:        fmt.Sprintf("Dir '%s' max_sectors_kb tuned", dir),
+		required:    maxSectorsRequired,
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceMaxSectors(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDeviceReadAheadChecker(
+	device string, deviceFeatures disk.DeviceFeatures,
+) Checker {
+	return &devicesValueChecker{
+		id:       ReadAheadChecker,
+		desc:     fmt.Sprintf("Disk '%s' read-ahead tuned", device),
+		required: readAheadRequired,
+		devices: func() ([]string, error) {
+			return []string{device}, nil
+		},
+		check: func(device string) (bool, string, error) {
+			target, err := deviceReadAheadTarget(device, deviceFeatures)
 			if err != nil {
-				return false, err
+				return false, "", err
 			}
-			tuned := true
-			for _, device := range devices {
-				ok, err := checkDeviceNomerges(deviceFeatures, device)
+			return checkDeviceReadAhead(deviceFeatures, device, target)
+		},
+	}
+}
+
+func NewDirectoryReadAheadChecker(
+	ctx context.Context,
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	// The members of stacked devices are checked against the read-ahead of
+	// the device holding the directory, see NewReadAheadTuner.
+	targets := map[string]readAheadTarget{}
+	return &devicesValueChecker{
+		id:          ReadAheadChecker,
+		desc:        fmt.Sprintf("Dir '%s' read-ahead tuned", dir),
+		required:    readAheadRequired,
+		listDevices: true,
+		devices: func() ([]string, error) {
+			stacks, err := blockDevices.GetDirectoryStacks(ctx, dir)
+			if err != nil {
+				return nil, err
+			}
+			var devices []string
+			for _, stack := range stacks {
+				target, err := deviceReadAheadTarget(stack[0], deviceFeatures)
 				if err != nil {
-					return false, err
+					return nil, err
+				}
+				for _, device := range stack {
+					if _, ok := targets[device]; !ok {
+						targets[device] = target
+						devices = append(devices, device)
+					}
 				}
-				tuned = tuned && ok
 			}
-			return tuned, nil
+			return devices, nil
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceReadAhead(deviceFeatures, device, targets[device])
+		},
+	}
+}
+
+// NewDirectoryDeviceClassChecker returns a checker warning about the devices
+// holding dir which are not attached to the host, e.g. cloud volumes, as the
+// disk tuners assume local NVMe or SSD devices while those are throughput
+// capped and prone to latency spikes.
+func NewDirectoryDeviceClassChecker(
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:          DeviceClassChecker,
+		desc:        fmt.Sprintf("Dir '%s' on local devices", dir),
+		required:    "local devices",
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceClass(deviceFeatures, device)
+		},
+	}
+}
+
+func checkDeviceClass(
+	deviceFeatures disk.DeviceFeatures, device string,
+) (ok bool, current string, err error) {
+	class, err := deviceFeatures.GetDeviceClass(device)
+	if err != nil {
+		return false, "", err
+	}
+	if class.IsPseudo() {
+		log.Warnf("'%s' is a %s device, the disk tuning doesn't apply to it", device, class)
+	} else if !class.IsLocal() {
+		log.Warnf("'%s' is a %s device, the disk tuning assumes local NVMe"+
+			" or SSD devices and doesn't apply to it: verify that its"+
+			" provisioned IOPS and throughput meet the expected load", device, class)
+	}
+	return class.IsLocal(), class.String(), nil
+}
+
+// NewDirectoryZonedChecker returns a checker warning about the zoned devices
+// holding dir, e.g. SMR drives, which are unfit for the Redpanda workload.
+// The check is fatal if any of them is host-managed, as Redpanda can't store
+// data on it at all.
+func NewDirectoryZonedChecker(
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:          ZonedDeviceChecker,
+		desc:        fmt.Sprintf("Dir '%s' on non-zoned devices", dir),
+		required:    disk.ZonedNone,
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceZoned(deviceFeatures, device)
+		},
+		fatal: func(current string) bool {
+			return strings.HasPrefix(current, disk.ZonedHostManaged)
+		},
+	}
+}
+
+func checkDeviceZoned(
+	deviceFeatures disk.DeviceFeatures, device string,
+) (ok bool, current string, err error) {
+	zoned, err := deviceFeatures.GetZonedModel(device)
+	if err != nil {
+		return false, "", err
+	}
+	switch zoned {
+	case disk.ZonedHostAware:
+		log.Warnf("'%s' is a host-aware zoned device, likely an SMR drive: its"+
+			" writes stall for seconds while it rewrites whole zones, which"+
+			" Redpanda's write pattern triggers constantly; use a"+
+			" conventional drive instead", device)
+		return false, zoned + " (SMR)", nil
+	case disk.ZonedHostManaged:
+		log.Errorf("'%s' is a host-managed zoned (ZBC/ZAC) device: it rejects"+
+			" random writes and can't be used as a regular block device,"+
+			" Redpanda can't store data on it", device)
+		return false, zoned + " (SMR, random writes unsupported)", nil
+	}
+	return true, zoned, nil
+}
+
+// NewDirectoryTransportChecker returns a checker warning about the physical
+// devices holding dir attached through USB, which shouldn't back production
+// data, and about the stacked devices, e.g. md arrays, whose physical devices
+// are attached through different buses, which perform unevenly. The
+// transport of each physical device is reported.
+func NewDirectoryTransportChecker(
+	ctx context.Context, dir string, blockDevices disk.BlockDevices,
+) Checker {
+	// members are the physical devices of each device holding dir, as of
+	// the last check.
+	var members map[string][]string
+	return &devicesValueChecker{
+		id:       TransportChecker,
+		desc:     fmt.Sprintf("Dir '%s' devices transport", dir),
+		required: "not usb, the same for all the devices of an array",
+		devices: func() ([]string, error) {
+			stacks, err := blockDevices.GetDirectoryStacks(ctx, dir)
+			if err != nil {
+				return nil, err
+			}
+			var tops []string
+			members = map[string][]string{}
+			for _, stack := range stacks {
+				if len(stack) == 0 {
+					continue
+				}
+				if _, ok := members[stack[0]]; !ok {
+					tops = append(tops, stack[0])
+				}
+				members[stack[0]] = stack[1:]
+			}
+			return tops, nil
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceTransport(ctx, blockDevices, device, members[device])
+		},
+	}
+}
+
+func checkDeviceTransport(
+	ctx context.Context, blockDevices disk.BlockDevices, device string, members []string,
+) (ok bool, current string, err error) {
+	top, err := blockDevices.GetDeviceFromPath(filepath.Join("/dev", device))
+	if err != nil {
+		return false, "", err
+	}
+	transport, err := top.Transport(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	if len(members) == 0 {
+		if transport == disk.TransportUSB {
+			warnUSBDevice(device)
+		}
+		return transport != disk.TransportUSB, fmt.Sprintf("%s: %s", device, transport), nil
+	}
+	ok = true
+	var transports []string
+	for _, member := range members {
+		blockDevice, err := blockDevices.GetDeviceFromPath(filepath.Join("/dev", member))
+		if err != nil {
+			return false, "", err
+		}
+		memberTransport, err := blockDevice.Transport(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		if memberTransport == disk.TransportUSB {
+			warnUSBDevice(member)
+			ok = false
+		}
+		transports = append(transports, fmt.Sprintf("%s: %s", member, memberTransport))
+	}
+	current = fmt.Sprintf("%s (%s)", device, strings.Join(transports, ", "))
+	if transport == disk.TransportMixed {
+		log.Warnf("The devices of '%s' are attached through different buses"+
+			" (%s): the array is as fast as its slowest device",
+			device, strings.Join(transports, ", "))
+		current += " mixed"
+		ok = false
+	}
+	return ok, current, nil
+}
+
+func warnUSBDevice(device string) {
+	log.Warnf("'%s' is a USB-attached drive: USB drives are slow, may"+
+		" be disconnected at any time and don't honor flushes"+
+		" reliably, they must never back production data", device)
+}
+
+// NewDirectoryPartitionAlignmentChecker returns a checker warning if dir is
+// on a partition whose start isn't aligned to the physical block size of its
+// disk, e.g. a partition starting at sector 63 of a 4Kn drive.
+func NewDirectoryPartitionAlignmentChecker(
+	dir string, blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:       PartitionAlignmentChecker,
+		desc:     fmt.Sprintf("Dir '%s' partition aligned", dir),
+		required: "partition start multiple of the physical block size",
+		devices: func() ([]string, error) {
+			return []string{dir}, nil
+		},
+		check: func(dir string) (bool, string, error) {
+			device, err := blockDevices.GetDeviceFromPath(dir)
+			if err != nil {
+				return false, "", err
+			}
+			return checkPartitionAlignment(device)
+		},
+	}
+}
+
+func checkPartitionAlignment(
+	device disk.BlockDevice,
+) (ok bool, current string, err error) {
+	alignment, err := device.PartitionAlignment()
+	if err != nil {
+		return false, "", err
+	}
+	if alignment == nil {
+		return true, "not on a partition", nil
+	}
+	if !alignment.Aligned {
+		return false, fmt.Sprintf("%s starts at %d bytes, not a multiple of the %d bytes physical block size",
+			alignment.Partition, alignment.Offset, alignment.PhysicalBlockSize), nil
+	}
+	return true, fmt.Sprintf("%s starts at %d bytes, a multiple of the %d bytes physical block size",
+		alignment.Partition, alignment.Offset, alignment.PhysicalBlockSize), nil
+}
+
+// thinPoolDataWarnPercent is the data usage of a thin pool above which the
+// thin-provisioning checker warns of it running out of space.
+const thinPoolDataWarnPercent = 80
+
+// NewDirectoryThinProvisioningChecker returns a checker warning if dir is on
+// an LVM thin volume: its pool may be overcommitted, and the writes to a full
+// pool fail with I/O errors that look like a disk failure. The data and
+// metadata usages of the pool are read with poolStatus, e.g.
+// disk.ReadThinPoolStatus, the check failing either way. Other volumes pass.
+func NewDirectoryThinProvisioningChecker(
+	dir string,
+	blockDevices disk.BlockDevices,
+	poolStatus func(pool string) (*disk.ThinPoolStatus, error),
+) Checker {
+	return &devicesValueChecker{
+		id:       ThinProvisioningChecker,
+		desc:     fmt.Sprintf("Dir '%s' not thin-provisioned", dir),
+		required: "not on a thin volume",
+		devices: func() ([]string, error) {
+			return []string{dir}, nil
+		},
+		check: func(dir string) (bool, string, error) {
+			device, err := blockDevices.GetDeviceFromPath(dir)
+			if err != nil {
+				return false, "", err
+			}
+			return checkThinProvisioning(device, poolStatus)
+		},
+	}
+}
+
+func checkThinProvisioning(
+	device disk.BlockDevice,
+	poolStatus func(pool string) (*disk.ThinPoolStatus, error),
+) (ok bool, current string, err error) {
+	thin := device.Thin()
+	if thin == nil {
+		return true, "not thin-provisioned", nil
+	}
+	current = fmt.Sprintf("thin volume '%s' of pool '%s'", thin.Name, thin.Pool)
+	status, err := poolStatus(thin.Pool)
+	if err != nil {
+		log.Debugf("Unable to read the status of thin pool '%s': %v", thin.Pool, err)
+		return false, current + ", pool usage unknown", nil
+	}
+	if status.Mode == disk.ThinPoolModeFail {
+		log.Errorf("Thin pool '%s' of '%s' failed, its volumes reject I/O", thin.Pool, thin.Name)
+		return false, current + ", pool failed", nil
+	}
+	current += fmt.Sprintf(", %.1f%% data and %.1f%% metadata used",
+		status.DataUsedPercent(), status.MetadataUsedPercent())
+	if status.Mode != disk.ThinPoolModeReadWrite {
+		current += fmt.Sprintf(", pool %s", status.Mode)
+	}
+	if status.DataUsedPercent() >= thinPoolDataWarnPercent || status.Mode == disk.ThinPoolModeOutOfDataSpace {
+		log.Warnf("Thin pool '%s' of '%s' is %.1f%% full: writes will fail"+
+			" with I/O errors once it's full, extend it or enable its"+
+			" autoextension", thin.Pool, thin.Name, status.DataUsedPercent())
+	}
+	return false, current, nil
+}
+
+const distinctDevicesRequired = "distinct devices"
+
+// NewSharedDevicesChecker returns a checker warning if directories meant to
+// be on distinct devices, i.e. which are on different mounts, as found in the
+// mountinfo file at mountInfoPath, share a physical device, e.g. two
+// partitions or LVM volumes of a single disk: their I/O isn't spread across
+// disks as it seems. Sharing devices may be intended, hence a warning. The
+// physical devices are grouped with sharedDevices, e.g. disk.SharedDevices.
+func NewSharedDevicesChecker(
+	fs afero.Fs,
+	mountInfoPath string,
+	dirs []string,
+	sharedDevices func(dirs []string) ([]disk.SharedDevice, error),
+) Checker {
+	return NewEqualityChecker(
+		SharedDevicesChecker,
+		"Directories on distinct devices",
+		Warning,
+		distinctDevicesRequired,
+		func() (interface{}, error) {
+			return checkSharedDevices(distinctMountDirs(fs, mountInfoPath, dirs), sharedDevices)
 		},
 	)
 }
 
-func checkDeviceNomerges(
+// distinctMountDirs returns the first of dirs on each mount: directories on
+// a single filesystem obviously share its devices. Directories whose mount
+// can't be found are kept.
+func distinctMountDirs(fs afero.Fs, mountInfoPath string, dirs []string) []string {
+	var distinct []string
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		mount, err := disk.FindMount(fs, mountInfoPath, dir)
+		if err != nil {
+			log.Debugf("Unable to find the mount of '%s': %v", dir, err)
+			distinct = append(distinct, dir)
+			continue
+		}
+		if seen[mount.MountPoint] {
+			log.Debugf("'%s' is on mount '%s' along with another directory", dir, mount.MountPoint)
+			continue
+		}
+		seen[mount.MountPoint] = true
+		distinct = append(distinct, dir)
+	}
+	return distinct
+}
+
+func checkSharedDevices(
+	dirs []string, sharedDevices func(dirs []string) ([]disk.SharedDevice, error),
+) (string, error) {
+	if len(dirs) < 2 {
+		return distinctDevicesRequired, nil
+	}
+	shared, err := sharedDevices(dirs)
+	if err != nil {
+		if len(shared) == 0 {
+			return "", err
+		}
+		log.Debugf("Unable to resolve the devices of some directories: %v", err)
+	}
+	if len(shared) == 0 {
+		return distinctDevicesRequired, nil
+	}
+	var current []string
+	for _, device := range shared {
+		quoted := make([]string, 0, len(device.Paths))
+		for _, path := range device.Paths {
+			quoted = append(quoted, fmt.Sprintf("'%s'", path))
+		}
+		log.Warnf("Directories %s are on different mounts but share device"+
+			" '%s' (%d:%d): their I/O isn't spread across disks, ignore"+
+			" this if it's intended", strings.Join(quoted, ", "),
+			device.Name, device.Major, device.Minor)
+		current = append(current, fmt.Sprintf("%s share %s (%d:%d)",
+			strings.Join(quoted, ", "), device.Name, device.Major, device.Minor))
+	}
+	return strings.Join(current, "; "), nil
+}
+
+const readAheadRequired = "128KB on non rotational devices, >= 4096KB on rotational devices, >= a full stripe on md arrays other than RAID1"
+
+func checkDeviceReadAhead(
+	deviceFeatures disk.DeviceFeatures, device string, target readAheadTarget,
+) (ok bool, current string, err error) {
+	featureFile, err := deviceFeatures.GetReadAheadKBFeatureFile(device)
+	if err != nil {
+		return false, "", err
+	}
+	if featureFile == "" {
+		return true, "read_ahead_kb not exposed by the device", nil
+	}
+	readAheadKB, err := deviceFeatures.GetReadAheadKB(device)
+	if err != nil {
+		return false, "", err
+	}
+	switch {
+	case target.met(readAheadKB):
+		return true, fmt.Sprintf("%dKB", readAheadKB), nil
+	case target.minimum:
+		return false, fmt.Sprintf("%dKB (below %dKB)", readAheadKB, target.kb), nil
+	default:
+		return false, fmt.Sprintf("%dKB (%dKB for %s device)", readAheadKB, target.kb, target.class), nil
+	}
+}
+
+// devicesValueChecker checks a value of each of the devices, reporting
+// their current values. Devices with nothing to tune, e.g. those not
+// exposing the value, are reported as such instead of failing the check.
+type devicesValueChecker struct {
+	id       CheckerID
+	desc     string
+	required string
+	devices  func() ([]string, error)
+	check    func(device string) (ok bool, current string, err error)
+	// listDevices prefixes the current value of each device with its name.
+	listDevices bool
+	// fatal, if set, returns whether the current value of a device failing
+	// the check makes the result Fatal rather than a Warning.
+	fatal func(current string) bool
+}
+
+func (c *devicesValueChecker) ID() CheckerID {
+	return c.id
+}
+
+func (c *devicesValueChecker) GetDesc() string {
+	return c.desc
+}
+
+func (c *devicesValueChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *devicesValueChecker) GetRequiredAsString() string {
+	return c.required
+}
+
+func (c *devicesValueChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerID: c.ID(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+		IsOk:      true,
+	}
+	devices, err := c.devices()
+	if err != nil {
+		res.IsOk = false
+		res.Err = err
+		return res
+	}
+	var currents []string
+	for _, device := range devices {
+		ok, current, err := c.check(device)
+		if err != nil {
+			res.IsOk = false
+			res.Err = err
+			return res
+		}
+		if !ok && c.fatal != nil && c.fatal(current) {
+			res.Severity = Fatal
+		}
+		if c.listDevices {
+			current = fmt.Sprintf("%s: %s", device, current)
+		}
+		res.IsOk = res.IsOk && ok
+		currents = append(currents, current)
+	}
+	res.Current = strings.Join(currents, ", ")
+	return res
+}
+
+func checkDeviceNrRequests(
 	deviceFeatures disk.DeviceFeatures, device string,
-) (bool, error) {
-	nomerges, err := deviceFeatures.GetNomerges(device)
+) (ok bool, current string, err error) {
+	featureFile, err := deviceFeatures.GetNrRequestsFeatureFile(device)
 	if err != nil {
-		return false, err
+		return false, "", err
+	}
+	if featureFile == "" {
+		return true, "nr_requests not exposed by the device", nil
+	}
+	nrRequests, err := deviceFeatures.GetNrRequests(device)
+	if err != nil {
+		return false, "", err
+	}
+	rotational, err := deviceFeatures.GetRotational(device)
+	if err != nil {
+		return false, "", err
+	}
+	if rotational {
+		return true, fmt.Sprintf("%d (rotational)", nrRequests), nil
+	}
+	depth, err := deviceFeatures.GetQueueDepth(device)
+	if err != nil {
+		return false, "", err
+	}
+	if depth == 0 {
+		return true, fmt.Sprintf("%d (unknown hardware queue depth)", nrRequests), nil
+	}
+	return nrRequests >= depth, strconv.Itoa(nrRequests), nil
+}
+
+func checkDeviceAddRandom(
+	deviceFeatures disk.DeviceFeatures, device string,
+) (ok bool, current string, err error) {
+	featureFile, err := deviceFeatures.GetAddRandomFeatureFile(device)
+	if err != nil {
+		return false, "", err
+	}
+	if featureFile == "" {
+		return true, "add_random not exposed by the device", nil
 	}
-	return nomerges == 2, nil
+	addRandom, err := deviceFeatures.GetAddRandom(device)
+	if err != nil {
+		return false, "", err
+	}
+	rotational, err := deviceFeatures.GetRotational(device)
+	if err != nil {
+		return false, "", err
+	}
+	if rotational {
+		return true, fmt.Sprintf("%d (rotational)", addRandom), nil
+	}
+	return addRandom == 0, strconv.Itoa(addRandom), nil
+}
+
+func checkDeviceRqAffinity(
+	deviceFeatures disk.DeviceFeatures, device string,
+) (ok bool, current string, err error) {
+	featureFile, err := deviceFeatures.GetRqAffinityFeatureFile(device)
+	if err != nil {
+		return false, "", err
+	}
+	if featureFile == "" {
+		return true, "rq_affinity not exposed by the device", nil
+	}
+	rqAffinity, err := deviceFeatures.GetRqAffinity(device)
+	if err != nil {
+		return false, "", err
+	}
+	rotational, err := deviceFeatures.GetRotational(device)
+	if err != nil {
+		return false, "", err
+	}
+	if rotational {
+		return true, fmt.Sprintf("%d (rotational)", rqAffinity), nil
+	}
+	return rqAffinity == 2, strconv.Itoa(rqAffinity), nil
+}
+
+const maxSectorsRequired = ">= half of max_hw_sectors_kb, aligned with optimal_io_size"
+
+// checkDeviceMaxSectors checks that the max_sectors_kb of the device is at
+// least half of its max_hw_sectors_kb, below which large writes are split in
+// many more requests than needed, and a multiple of its optimal I/O size, so
+// that the requests don't straddle its stripes.
+func checkDeviceMaxSectors(
+	deviceFeatures disk.DeviceFeatures, device string,
+) (ok bool, current string, err error) {
+	featureFile, err := deviceFeatures.GetMaxSectorsKBFeatureFile(device)
+	if err != nil {
+		return false, "", err
+	}
+	if featureFile == "" {
+		return true, "max_sectors_kb not exposed by the device", nil
+	}
+	maxSectorsKB, err := deviceFeatures.GetMaxSectorsKB(device)
+	if err != nil {
+		return false, "", err
+	}
+	maxHwSectorsKB, err := deviceFeatures.GetMaxHwSectorsKB(device)
+	if err != nil {
+		return false, "", err
+	}
+	if maxHwSectorsKB == 0 {
+		return true, fmt.Sprintf("%d (unknown max_hw_sectors_kb)", maxSectorsKB), nil
+	}
+	optimalKB, err := optimalIOSizeKB(device, deviceFeatures)
+	if err != nil {
+		return false, "", err
+	}
+	if optimalKB > 0 && optimalKB <= maxHwSectorsKB && maxSectorsKB%optimalKB != 0 {
+		return false, fmt.Sprintf("%d (misaligned with optimal_io_size %dKB)", maxSectorsKB, optimalKB), nil
+	}
+	if 2*maxSectorsKB < maxHwSectorsKB {
+		return false, fmt.Sprintf("%d (max_hw_sectors_kb %d)", maxSectorsKB, maxHwSectorsKB), nil
+	}
+	return true, strconv.Itoa(maxSectorsKB), nil
 }
 
 func NewDeviceSchedulerChecker(
-	_ afero.Fs, device string, deviceFeatures disk.DeviceFeatures,
+	_ afero.Fs, device, override string, deviceFeatures disk.DeviceFeatures,
 ) Checker {
 	return NewEqualityChecker(
 		SchedulerChecker,
@@ -94,7 +880,7 @@ func NewDeviceSchedulerChecker(
 		Warning,
 		true,
 		func() (interface{}, error) {
-			return checkScheduler(deviceFeatures, device)
+			return checkScheduler(deviceFeatures, device, override)
 		},
 	)
 }
@@ -116,7 +902,7 @@ func NewDirectorySchedulerChecker(
 			}
 			tuned := true
 			for _, device := range devices {
-				ok, err := checkScheduler(deviceFeatures, device)
+				ok, err := checkScheduler(deviceFeatures, device, "")
 				if err != nil {
 					return false, err
 				}
@@ -128,13 +914,20 @@ func NewDirectorySchedulerChecker(
 }
 
 func checkScheduler(
-	deviceFeatures disk.DeviceFeatures, device string,
+	deviceFeatures disk.DeviceFeatures, device, override string,
 ) (bool, error) {
+	preferred, err := getScheduler(device, override, deviceFeatures)
+	if err != nil {
+		return false, err
+	}
+	if preferred == "" {
+		return true, nil
+	}
 	scheduler, err := deviceFeatures.GetScheduler(device)
 	if err != nil {
 		return false, err
 	}
-	return scheduler == "none" || scheduler == "noop", nil
+	return scheduler == preferred, nil
 }
 
 func NewDeviceWriteCacheChecker(
@@ -189,6 +982,74 @@ func checkDeviceWriteCache(
 	return (cachePolicy == disk.CachePolicyWriteThrough), nil
 }
 
+func NewDeviceVolatileWriteCacheChecker(
+	device string, deviceFeatures disk.DeviceFeatures,
+) Checker {
+	return &devicesValueChecker{
+		id:       VolatileWriteCacheChecker,
+		desc:     fmt.Sprintf("Disk '%s' volatile write cache disabled", device),
+		required: disk.CachePolicyWriteThrough,
+		devices: func() ([]string, error) {
+			return []string{device}, nil
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceVolatileWriteCache(deviceFeatures, device)
+		},
+	}
+}
+
+func NewDirectoryVolatileWriteCacheChecker(
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return &devicesValueChecker{
+		id:          VolatileWriteCacheChecker,
+		desc:        fmt.Sprintf("Dir '%s' volatile write cache disabled", dir),
+		required:    disk.CachePolicyWriteThrough,
+		listDevices: true,
+		devices: func() ([]string, error) {
+			return blockDevices.GetDirectoryDevices(dir)
+		},
+		check: func(device string) (bool, string, error) {
+			return checkDeviceVolatileWriteCache(deviceFeatures, device)
+		},
+	}
+}
+
+// checkDeviceVolatileWriteCache is ok unless the drive of the device caches
+// writes in volatile memory, where acknowledged writes are lost on power
+// failure unless the drive has a power loss protection it doesn't report:
+// the warning is advisory. The write cache mode of the queue is reported too
+// if it differs from the cache of the drive, as the kernel doesn't flush the
+// cache on fsync when the queue is in write through mode.
+func checkDeviceVolatileWriteCache(
+	deviceFeatures disk.DeviceFeatures, device string,
+) (bool, string, error) {
+	volatile, err := deviceFeatures.HasVolatileWriteCache(device)
+	if err != nil {
+		return false, "", err
+	}
+	current := disk.CachePolicyWriteThrough
+	if volatile {
+		current = disk.CachePolicyWriteBack
+	}
+	featureFile, err := deviceFeatures.GetWriteCacheFeatureFile(device)
+	if err != nil {
+		return false, "", err
+	}
+	if featureFile != "" {
+		mode, err := deviceFeatures.GetWriteCache(device)
+		if err != nil {
+			return false, "", err
+		}
+		if mode != current {
+			current = fmt.Sprintf("%s (queue write_cache %s)", current, mode)
+		}
+	}
+	return !volatile, current, nil
+}
+
 func NewDisksIRQAffinityStaticChecker(
 	devices []string,
 	blockDevices disk.BlockDevices,
@@ -0,0 +1,344 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoryDeviceClassChecker(t *testing.T) {
+	classes := map[string]disk.DeviceClass{
+		"nvme0n1": disk.DeviceClassLocalSSD,
+		"nvme1n1": disk.DeviceClassNetwork,
+		"vda":     disk.DeviceClassVirtio,
+	}
+	deviceFeatures := &deviceFeaturesMock{
+		getDeviceClass: func(device string) (disk.DeviceClass, error) {
+			return classes[device], nil
+		},
+	}
+	tests := []struct {
+		name        string
+		devices     []string
+		wantOk      bool
+		wantCurrent string
+	}{
+		{
+			name:        "shall pass on local devices",
+			devices:     []string{"nvme0n1"},
+			wantOk:      true,
+			wantCurrent: "nvme0n1: local SSD",
+		},
+		{
+			name:        "shall warn about cloud and virtio devices",
+			devices:     []string{"nvme0n1", "nvme1n1", "vda"},
+			wantCurrent: "nvme0n1: local SSD, nvme1n1: network-attached, vda: virtio",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getDirectoryDevices: func(string) ([]string, error) {
+					return tt.devices, nil
+				},
+			}
+			result := NewDirectoryDeviceClassChecker("/var/lib/redpanda", deviceFeatures, blockDevices).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+			require.Equal(t, Severity(Warning), result.Severity)
+		})
+	}
+}
+
+func TestDirectoryZonedChecker(t *testing.T) {
+	models := map[string]string{
+		"sda": disk.ZonedNone,
+		"sdb": disk.ZonedHostAware,
+		"sdc": disk.ZonedHostManaged,
+	}
+	deviceFeatures := &deviceFeaturesMock{
+		getZonedModel: func(device string) (string, error) {
+			return models[device], nil
+		},
+	}
+	tests := []struct {
+		name         string
+		devices      []string
+		wantOk       bool
+		wantCurrent  string
+		wantSeverity Severity
+	}{
+		{
+			name:         "shall pass on regular devices",
+			devices:      []string{"sda"},
+			wantOk:       true,
+			wantCurrent:  "sda: none",
+			wantSeverity: Warning,
+		},
+		{
+			name:         "shall warn about host-aware devices",
+			devices:      []string{"sda", "sdb"},
+			wantCurrent:  "sda: none, sdb: host-aware (SMR)",
+			wantSeverity: Warning,
+		},
+		{
+			name:         "shall fail on host-managed devices",
+			devices:      []string{"sdb", "sdc"},
+			wantCurrent:  "sdb: host-aware (SMR), sdc: host-managed (SMR, random writes unsupported)",
+			wantSeverity: Fatal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getDirectoryDevices: func(string) ([]string, error) {
+					return tt.devices, nil
+				},
+			}
+			result := NewDirectoryZonedChecker("/var/lib/redpanda", deviceFeatures, blockDevices).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+			require.Equal(t, tt.wantSeverity, result.Severity)
+		})
+	}
+}
+
+func TestDirectoryTransportChecker(t *testing.T) {
+	transports := map[string]disk.TransportType{
+		"/dev/sda":     disk.TransportSATA,
+		"/dev/sdb":     disk.TransportSATA,
+		"/dev/sdc":     disk.TransportUSB,
+		"/dev/sdd":     disk.TransportSAS,
+		"/dev/nvme0n1": disk.TransportNVMe,
+	}
+	tests := []struct {
+		name        string
+		stacks      [][]string
+		array       disk.TransportType
+		wantOk      bool
+		wantCurrent string
+	}{
+		{
+			name:        "shall pass on a local disk",
+			stacks:      [][]string{{"nvme0n1"}},
+			wantOk:      true,
+			wantCurrent: "nvme0n1: nvme",
+		},
+		{
+			name:        "shall pass on an array of disks on the same bus",
+			stacks:      [][]string{{"md0", "sda", "sdb"}},
+			array:       disk.TransportSATA,
+			wantOk:      true,
+			wantCurrent: "md0 (sda: sata, sdb: sata)",
+		},
+		{
+			name:        "shall warn about USB disks",
+			stacks:      [][]string{{"nvme0n1"}, {"sdc"}},
+			wantCurrent: "nvme0n1: nvme, sdc: usb",
+		},
+		{
+			name:        "shall warn about arrays mixing buses",
+			stacks:      [][]string{{"md0", "sda", "sdd"}},
+			array:       disk.TransportMixed,
+			wantCurrent: "md0 (sda: sata, sdd: sas) mixed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getDirectoryStacks: func(string) ([][]string, error) {
+					return tt.stacks, nil
+				},
+				getBlockDeviceFromPath: func(path string) (disk.BlockDevice, error) {
+					transport, found := transports[path]
+					if !found {
+						transport = tt.array
+					}
+					return &blockDeviceMock{devnode: path, transport: transport}, nil
+				},
+			}
+			checker := NewDirectoryTransportChecker(context.Background(), "/var/lib/redpanda", blockDevices)
+			result := checker.Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+			require.Equal(t, Severity(Warning), result.Severity)
+			// Checking again reports the same.
+			require.Equal(t, tt.wantCurrent, checker.Check().Current)
+		})
+	}
+}
+
+func TestDirectoryPartitionAlignmentChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		alignment   *disk.PartitionAlignment
+		wantOk      bool
+		wantCurrent string
+	}{
+		{
+			name: "shall pass on aligned partitions",
+			alignment: &disk.PartitionAlignment{
+				Partition:         "sda1",
+				Offset:            1048576,
+				PhysicalBlockSize: 4096,
+				Aligned:           true,
+			},
+			wantOk:      true,
+			wantCurrent: "sda1 starts at 1048576 bytes, a multiple of the 4096 bytes physical block size",
+		},
+		{
+			name: "shall warn about misaligned partitions",
+			alignment: &disk.PartitionAlignment{
+				Partition:         "sda1",
+				Offset:            32256,
+				PhysicalBlockSize: 4096,
+			},
+			wantCurrent: "sda1 starts at 32256 bytes, not a multiple of the 4096 bytes physical block size",
+		},
+		{
+			name:        "shall pass on whole disks",
+			wantOk:      true,
+			wantCurrent: "not on a partition",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getBlockDeviceFromPath: func(string) (disk.BlockDevice, error) {
+					return &blockDeviceMock{alignment: tt.alignment}, nil
+				},
+			}
+			result := NewDirectoryPartitionAlignmentChecker("/var/lib/redpanda", blockDevices).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+			require.Equal(t, Severity(Warning), result.Severity)
+		})
+	}
+}
+
+func TestDirectoryThinProvisioningChecker(t *testing.T) {
+	thin := &disk.ThinVolume{Name: "vg0-data", Pool: "vg0-pool-tpool", PoolDevice: "dm-2"}
+	tests := []struct {
+		name        string
+		thin        *disk.ThinVolume
+		status      string
+		statusErr   error
+		wantOk      bool
+		wantCurrent string
+	}{
+		{
+			name:        "shall pass on volumes other than thin ones",
+			wantOk:      true,
+			wantCurrent: "not thin-provisioned",
+		},
+		{
+			name:        "shall warn about thin volumes",
+			thin:        thin,
+			status:      "0 209715200 thin-pool 3 1203/16384 51200/102400 - rw no_discard_passdown queue_if_no_space - 1024",
+			wantCurrent: "thin volume 'vg0-data' of pool 'vg0-pool-tpool', 50.0% data and 7.3% metadata used",
+		},
+		{
+			name:        "shall report full pools",
+			thin:        thin,
+			status:      "0 209715200 thin-pool 12 4096/16384 102400/102400 - out_of_data_space discard_passdown error_if_no_space - 1024",
+			wantCurrent: "thin volume 'vg0-data' of pool 'vg0-pool-tpool', 100.0% data and 25.0% metadata used, pool out_of_data_space",
+		},
+		{
+			name:        "shall report failed pools",
+			thin:        thin,
+			status:      "0 209715200 thin-pool Fail",
+			wantCurrent: "thin volume 'vg0-data' of pool 'vg0-pool-tpool', pool failed",
+		},
+		{
+			name:        "shall warn about thin volumes whose pool status is unknown",
+			thin:        thin,
+			statusErr:   errors.New("dmsetup: permission denied"),
+			wantCurrent: "thin volume 'vg0-data' of pool 'vg0-pool-tpool', pool usage unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDevices := &blockDevicesMock{
+				getBlockDeviceFromPath: func(string) (disk.BlockDevice, error) {
+					return &blockDeviceMock{thin: tt.thin}, nil
+				},
+			}
+			poolStatus := func(pool string) (*disk.ThinPoolStatus, error) {
+				require.Equal(t, "vg0-pool-tpool", pool)
+				if tt.statusErr != nil {
+					return nil, tt.statusErr
+				}
+				return disk.ParseThinPoolStatus(tt.status)
+			}
+			result := NewDirectoryThinProvisioningChecker("/var/lib/redpanda", blockDevices, poolStatus).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+			require.Equal(t, Severity(Warning), result.Severity)
+		})
+	}
+}
+
+func TestSharedDevicesChecker(t *testing.T) {
+	// The mount points are compared with the paths free of links.
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	data, cache, wal := filepath.Join(root, "data"), filepath.Join(root, "cache"), filepath.Join(root, "data", "wal")
+	for _, dir := range []string{data, cache, wal} {
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+	}
+	fs := afero.NewMemMapFs()
+	mountinfo := "1 0 8:1 / / rw,relatime - ext4 /dev/sda1 rw\n" +
+		"2 1 8:2 / " + data + " rw,noatime - xfs /dev/sda2 rw\n" +
+		"3 1 8:3 / " + cache + " rw,noatime - xfs /dev/sda3 rw\n"
+	require.NoError(t, afero.WriteFile(fs, "/proc/self/mountinfo", []byte(mountinfo), 0o644))
+
+	var resolved []string
+	sharedDevices := func(dirs []string) ([]disk.SharedDevice, error) {
+		resolved = dirs
+		return []disk.SharedDevice{{Name: "sda", Major: 8, Minor: 0, Paths: dirs}}, nil
+	}
+
+	// The directories on separate partitions of a disk share it.
+	res := NewSharedDevicesChecker(fs, "/proc/self/mountinfo", []string{data, cache}, sharedDevices).Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+	require.Equal(t, Severity(Warning), res.Severity)
+	require.Equal(t, "'"+data+"', '"+cache+"' share sda (8:0)", res.Current)
+
+	// A directory on the mount of another obviously shares its devices.
+	resolved = nil
+	res = NewSharedDevicesChecker(fs, "/proc/self/mountinfo", []string{data, wal}, sharedDevices).Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+	require.Equal(t, "distinct devices", res.Current)
+	require.Nil(t, resolved)
+
+	res = NewSharedDevicesChecker(fs, "/proc/self/mountinfo", []string{data, cache},
+		func([]string) ([]disk.SharedDevice, error) { return nil, nil }).Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+
+	res = NewSharedDevicesChecker(fs, "/proc/self/mountinfo", []string{data, cache},
+		func([]string) ([]disk.SharedDevice, error) { return nil, errors.New("no sysfs") }).Check()
+	require.Error(t, res.Err)
+}
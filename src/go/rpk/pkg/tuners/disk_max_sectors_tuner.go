@@ -0,0 +1,145 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"strconv"
+	"syscall"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// NewDeviceMaxSectorsTuner returns a tuner raising the max_sectors_kb of the
+// device, the largest request the block layer sends it, to the largest one
+// the device accepts, so that the large sequential writes of Redpanda aren't
+// split. Devices are only tuned if their max_sectors_kb is far below their
+// hardware max or misaligned with their optimal I/O size, see
+// checkDeviceMaxSectors.
+func NewDeviceMaxSectorsTuner(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewDeviceMaxSectorsChecker(device, deviceFeatures),
+		func() TuneResult {
+			return tuneMaxSectors(fs, device, deviceFeatures, executor)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+func tuneMaxSectors(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) TuneResult {
+	featureFile, err := deviceFeatures.GetMaxSectorsKBFeatureFile(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if featureFile == "" {
+		log.Infof("Skipping '%s' as it doesn't expose its max_sectors_kb", device)
+		return NewTuneResult(false)
+	}
+	target, err := maxSectorsTarget(device, deviceFeatures)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if target == 0 {
+		log.Infof("Skipping '%s' as its max_hw_sectors_kb is unknown", device)
+		return NewTuneResult(false)
+	}
+	maxSectorsKB, err := deviceFeatures.GetMaxSectorsKB(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, strconv.Itoa(target))
+	if err != nil {
+		// The kernel rejects the values above its ceiling, which some
+		// drivers lower below max_hw_sectors_kb.
+		if errors.Is(err, syscall.EINVAL) {
+			log.Infof("Unable to set '%s' max_sectors_kb to %d: %v", device, target, err)
+			return NewTuneNotApplied("max_sectors_kb rejected by the kernel")
+		}
+		if isPermissionDenied(err) {
+			log.Infof("Unable to set '%s' max_sectors_kb: %v", device, err)
+			return NewTuneNotApplied("not applied, permission denied")
+		}
+		return NewTuneError(err)
+	}
+	if !written {
+		return newTuneUnchanged()
+	}
+	log.Infof("Setting '%s' max_sectors_kb from %d to %d", device, maxSectorsKB, target)
+
+	return newTuneChanged(strconv.Itoa(maxSectorsKB), strconv.Itoa(target))
+}
+
+// maxSectorsTarget returns the max_sectors_kb the device is tuned to: its
+// max_hw_sectors_kb, the ceiling the kernel enforces, rounded down to a
+// multiple of its optimal I/O size, if it reports one that fits. It's 0 if
+// the device doesn't expose its max_hw_sectors_kb.
+func maxSectorsTarget(device string, deviceFeatures disk.DeviceFeatures) (int, error) {
+	maxHwSectorsKB, err := deviceFeatures.GetMaxHwSectorsKB(device)
+	if err != nil || maxHwSectorsKB == 0 {
+		return 0, err
+	}
+	optimalKB, err := optimalIOSizeKB(device, deviceFeatures)
+	if err != nil {
+		return 0, err
+	}
+	if optimalKB > 0 && optimalKB <= maxHwSectorsKB {
+		return maxHwSectorsKB - maxHwSectorsKB%optimalKB, nil
+	}
+	return maxHwSectorsKB, nil
+}
+
+// optimalIOSizeKB returns the optimal I/O size of the device in KB, or 0 if
+// it reports none or one which isn't a whole number of KB, which
+// max_sectors_kb can't be aligned with.
+func optimalIOSizeKB(device string, deviceFeatures disk.DeviceFeatures) (int, error) {
+	optimalIOSize, err := deviceFeatures.GetOptimalIOSize(device)
+	if err != nil || optimalIOSize%1024 != 0 {
+		return 0, err
+	}
+	return optimalIOSize / 1024, nil
+}
+
+func NewMaxSectorsTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+) Tunable {
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+	return newDiskTuner(
+		fs,
+		directories,
+		devices,
+		blockDevices,
+		executor,
+		func(device string) Tunable {
+			return NewDeviceMaxSectorsTuner(fs, device, deviceFeatures, executor)
+		},
+		deviceFeatures.GetMaxSectorsKBFeatureFile,
+		nil,
+	)
+}
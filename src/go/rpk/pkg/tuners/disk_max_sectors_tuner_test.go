@@ -0,0 +1,198 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const fMaxSectorsQueue = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue"
+
+// maxSectorsFeaturesMock returns the device features of a device whose
+// queue attributes are read from fs, 0 if missing.
+func maxSectorsFeaturesMock(t *testing.T, fs afero.Fs) *deviceFeaturesMock {
+	read := func(attribute string) (int, error) {
+		content, err := afero.ReadFile(fs, filepath.Join(fMaxSectorsQueue, attribute))
+		if err != nil {
+			return 0, nil
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		require.NoError(t, err)
+		return value, nil
+	}
+	return &deviceFeaturesMock{
+		getMaxSectorsKBFile: func(string) (string, error) {
+			return filepath.Join(fMaxSectorsQueue, "max_sectors_kb"), nil
+		},
+		getMaxSectorsKB: func(string) (int, error) {
+			return read("max_sectors_kb")
+		},
+		getMaxHwSectorsKB: func(string) (int, error) {
+			return read("max_hw_sectors_kb")
+		},
+		getOptimalIOSize: func(string) (int, error) {
+			return read("optimal_io_size")
+		},
+	}
+}
+
+func TestDeviceMaxSectorsTuner_Tune(t *testing.T) {
+	tests := []struct {
+		name        string
+		attributes  map[string]string
+		want        string
+		wantCurrent string
+		wantOk      bool
+		wantTuned   bool
+	}{
+		{
+			name: "shall raise max_sectors_kb to the hardware max",
+			attributes: map[string]string{
+				"max_sectors_kb":    "128",
+				"max_hw_sectors_kb": "2048",
+				"optimal_io_size":   "0",
+			},
+			want:        "2048",
+			wantCurrent: "128 (max_hw_sectors_kb 2048)",
+			wantTuned:   true,
+		},
+		{
+			name: "shall align max_sectors_kb with the optimal I/O size",
+			attributes: map[string]string{
+				"max_sectors_kb":    "256",
+				"max_hw_sectors_kb": "32767",
+				"optimal_io_size":   "1572864",
+			},
+			want:        "32256",
+			wantCurrent: "256 (misaligned with optimal_io_size 1536KB)",
+			wantTuned:   true,
+		},
+		{
+			name: "shall realign max_sectors_kb close to the hardware max",
+			attributes: map[string]string{
+				"max_sectors_kb":    "1280",
+				"max_hw_sectors_kb": "2048",
+				"optimal_io_size":   "524288",
+			},
+			want:        "2048",
+			wantCurrent: "1280 (misaligned with optimal_io_size 512KB)",
+			wantTuned:   true,
+		},
+		{
+			name: "shall skip devices close to the hardware max",
+			attributes: map[string]string{
+				"max_sectors_kb":    "1280",
+				"max_hw_sectors_kb": "2048",
+			},
+			want:        "1280",
+			wantCurrent: "1280",
+			wantOk:      true,
+		},
+		{
+			name: "shall ignore optimal I/O sizes above the hardware max",
+			attributes: map[string]string{
+				"max_sectors_kb":    "2048",
+				"max_hw_sectors_kb": "2048",
+				"optimal_io_size":   "4194304",
+			},
+			want:        "2048",
+			wantCurrent: "2048",
+			wantOk:      true,
+		},
+		{
+			name: "shall skip devices not exposing their hardware max",
+			attributes: map[string]string{
+				"max_sectors_kb": "128",
+			},
+			want:        "128",
+			wantCurrent: "128 (unknown max_hw_sectors_kb)",
+			wantOk:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for attribute, value := range tt.attributes {
+				afero.WriteFile(fs, filepath.Join(fMaxSectorsQueue, attribute), []byte(value+"\n"), 0o644)
+			}
+			deviceFeatures := maxSectorsFeaturesMock(t, fs)
+
+			result := NewDeviceMaxSectorsChecker("fake", deviceFeatures).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+
+			tuner := NewDeviceMaxSectorsTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+			res := tuner.Tune(context.Background())
+			require.NoError(t, res.Error())
+			_, _, tuned := tuner.(checkedValuer).checkedValues()
+			require.Equal(t, tt.wantTuned, tuned)
+			setValue, err := afero.ReadFile(fs, filepath.Join(fMaxSectorsQueue, "max_sectors_kb"))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, strings.TrimSpace(string(setValue)))
+
+			// Tuning again changes nothing.
+			result = NewDeviceMaxSectorsChecker("fake", deviceFeatures).Check()
+			require.NoError(t, result.Err)
+			require.True(t, result.IsOk)
+		})
+	}
+}
+
+func TestDeviceMaxSectorsTuner_dryRun(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, filepath.Join(fMaxSectorsQueue, "max_sectors_kb"), []byte("128\n"), 0o644)
+	afero.WriteFile(fs, filepath.Join(fMaxSectorsQueue, "max_hw_sectors_kb"), []byte("1024\n"), 0o644)
+	exec := executors.NewDryRunExecutor()
+	tuner := NewDeviceMaxSectorsTuner(fs, "fake", maxSectorsFeaturesMock(t, fs), exec)
+	res := tuner.Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Len(t, exec.Changes(), 1)
+	require.Equal(t, filepath.Join(fMaxSectorsQueue, "max_sectors_kb"), exec.Changes()[0].Path)
+	require.Equal(t, "1024", exec.Changes()[0].Proposed)
+	setValue, err := afero.ReadFile(fs, filepath.Join(fMaxSectorsQueue, "max_sectors_kb"))
+	require.NoError(t, err)
+	require.Equal(t, "128\n", string(setValue))
+}
+
+// rejectingFs fails the writes with err, as sysfs does for the values above
+// the ceiling the kernel enforces.
+type rejectingFs struct {
+	afero.Fs
+	err error
+}
+
+func (fs *rejectingFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: fs.err}
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func TestDeviceMaxSectorsTuner_rejected(t *testing.T) {
+	mem := afero.NewMemMapFs()
+	afero.WriteFile(mem, filepath.Join(fMaxSectorsQueue, "max_sectors_kb"), []byte("128\n"), 0o644)
+	afero.WriteFile(mem, filepath.Join(fMaxSectorsQueue, "max_hw_sectors_kb"), []byte("1024\n"), 0o644)
+	fs := &rejectingFs{Fs: mem, err: syscall.EINVAL}
+	res := NewDeviceMaxSectorsTuner(fs, "fake", maxSectorsFeaturesMock(t, mem), executors.NewDirectExecutor()).
+		Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, "max_sectors_kb rejected by the kernel", res.NotAppliedReason())
+}
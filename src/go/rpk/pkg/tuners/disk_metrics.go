@@ -0,0 +1,179 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
+)
+
+// The disk tuning metrics, rendered in the Prometheus text format by
+// WriteDiskMetrics, e.g. for the textfile collector of node_exporter. Each
+// is a gauge with a single 'device' label, the name of the block device,
+// e.g. 'nvme0n1'. Their names and labels are stable: dashboards and alerts
+// are built on them, they're only ever added to.
+const (
+	// MetricDiskSchedulerOptimal is 1 if the device uses the I/O scheduler
+	// the scheduler tuner prefers for its class, 0 otherwise.
+	MetricDiskSchedulerOptimal = "redpanda_disk_scheduler_optimal"
+	// MetricDiskReadAheadKB is the read_ahead_kb of the device.
+	MetricDiskReadAheadKB = "redpanda_disk_read_ahead_kb"
+	// MetricDiskReadAheadOptimal is 1 if the read_ahead_kb of the device is
+	// at least the one the read-ahead tuner recommends for its class, 0
+	// otherwise.
+	MetricDiskReadAheadOptimal = "redpanda_disk_read_ahead_optimal"
+	// MetricDiskNomerges is the nomerges of the device.
+	MetricDiskNomerges = "redpanda_disk_nomerges"
+	// MetricDiskNrRequests is the nr_requests of the device.
+	MetricDiskNrRequests = "redpanda_disk_nr_requests"
+)
+
+// DiskTuningState is the current state of the knobs of a block device the
+// disk tuners tune, as rendered by WriteDiskMetrics. The knobs the device
+// doesn't expose are left out of the metrics.
+type DiskTuningState struct {
+	// Device is the name of the device, e.g. 'nvme0n1'.
+	Device string
+	// Scheduler is the current I/O scheduler of the device, and
+	// PreferredScheduler the one preferred for its class. Both are empty if
+	// the device doesn't expose its scheduler.
+	Scheduler          string
+	PreferredScheduler string
+	// ReadAheadKB is the read_ahead_kb of the device, -1 if not exposed,
+	// and ReadAheadTargetKB the minimum recommended for its class, 0 if the
+	// tuner leaves it untouched.
+	ReadAheadKB       int
+	ReadAheadTargetKB int
+	// Nomerges and NrRequests are -1 if not exposed.
+	Nomerges   int
+	NrRequests int
+}
+
+// ReadDiskTuningState reads the current state of the knobs of the device.
+func ReadDiskTuningState(
+	device string, deviceFeatures disk.DeviceFeatures,
+) (DiskTuningState, error) {
+	state := DiskTuningState{Device: device, ReadAheadKB: -1, Nomerges: -1, NrRequests: -1}
+	preferred, err := getPreferredScheduler(device, deviceFeatures)
+	if err != nil {
+		return state, err
+	}
+	if preferred != "" {
+		state.PreferredScheduler = preferred
+		if state.Scheduler, err = deviceFeatures.GetScheduler(device); err != nil {
+			return state, err
+		}
+	}
+	if exposed, err := deviceFeatures.GetReadAheadKBFeatureFile(device); err != nil {
+		return state, err
+	} else if exposed != "" {
+		if state.ReadAheadKB, err = deviceFeatures.GetReadAheadKB(device); err != nil {
+			return state, err
+		}
+		target, err := deviceReadAheadTarget(device, deviceFeatures)
+		if err != nil {
+			return state, err
+		}
+		state.ReadAheadTargetKB = target.kb
+	}
+	if exposed, err := deviceFeatures.GetNomergesFeatureFile(device); err != nil {
+		return state, err
+	} else if exposed != "" {
+		if state.Nomerges, err = deviceFeatures.GetNomerges(device); err != nil {
+			return state, err
+		}
+	}
+	if exposed, err := deviceFeatures.GetNrRequestsFeatureFile(device); err != nil {
+		return state, err
+	} else if exposed != "" {
+		if state.NrRequests, err = deviceFeatures.GetNrRequests(device); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+// diskMetric is a gauge of the disk tuning metrics, value returning the value
+// of a device and whether the device has one.
+type diskMetric struct {
+	name  string
+	help  string
+	value func(DiskTuningState) (int, bool)
+}
+
+var diskMetrics = []diskMetric{
+	{
+		MetricDiskSchedulerOptimal,
+		"Whether the device uses the I/O scheduler preferred for its class.",
+		func(s DiskTuningState) (int, bool) {
+			return boolGauge(s.Scheduler == s.PreferredScheduler), s.PreferredScheduler != ""
+		},
+	},
+	{
+		MetricDiskReadAheadKB,
+		"The read_ahead_kb of the device.",
+		func(s DiskTuningState) (int, bool) { return s.ReadAheadKB, s.ReadAheadKB >= 0 },
+	},
+	{
+		MetricDiskReadAheadOptimal,
+		"Whether the read_ahead_kb of the device is at least the one recommended for its class.",
+		func(s DiskTuningState) (int, bool) {
+			return boolGauge(s.ReadAheadKB >= s.ReadAheadTargetKB), s.ReadAheadKB >= 0
+		},
+	},
+	{
+		MetricDiskNomerges,
+		"The nomerges of the device.",
+		func(s DiskTuningState) (int, bool) { return s.Nomerges, s.Nomerges >= 0 },
+	},
+	{
+		MetricDiskNrRequests,
+		"The nr_requests of the device.",
+		func(s DiskTuningState) (int, bool) { return s.NrRequests, s.NrRequests >= 0 },
+	},
+}
+
+func boolGauge(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// WriteDiskMetrics writes the disk tuning metrics of the devices to w, in
+// the Prometheus text format, the devices in name order. Metrics no device
+// has a value for are left out.
+func WriteDiskMetrics(w io.Writer, states []DiskTuningState) error {
+	sorted := append([]DiskTuningState{}, states...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Device < sorted[j].Device
+	})
+	bw := bufio.NewWriter(w)
+	for _, m := range diskMetrics {
+		header := false
+		for _, s := range sorted {
+			value, ok := m.value(s)
+			if !ok {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+				header = true
+			}
+			fmt.Fprintf(bw, "%s{device=\"%s\"} %d\n", m.name, utils.EscapePrometheusLabel(s.Device), value)
+		}
+	}
+	return bw.Flush()
+}
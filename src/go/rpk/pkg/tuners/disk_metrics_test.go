@@ -0,0 +1,76 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDiskMetrics(t *testing.T) {
+	states := []DiskTuningState{
+		{
+			Device:             "sda",
+			Scheduler:          "none",
+			PreferredScheduler: "mq-deadline",
+			ReadAheadKB:        128,
+			ReadAheadTargetKB:  4096,
+			Nomerges:           0,
+			NrRequests:         64,
+		},
+		{
+			Device:             "nvme0n1",
+			Scheduler:          "none",
+			PreferredScheduler: "none",
+			ReadAheadKB:        128,
+			Nomerges:           2,
+			NrRequests:         1023,
+		},
+		{
+			// A virtio device exposing none of its knobs.
+			Device:      "vda",
+			ReadAheadKB: -1,
+			Nomerges:    -1,
+			NrRequests:  -1,
+		},
+	}
+	var b bytes.Buffer
+	require.NoError(t, WriteDiskMetrics(&b, states))
+	require.Equal(t, `# HELP redpanda_disk_scheduler_optimal Whether the device uses the I/O scheduler preferred for its class.
+# TYPE redpanda_disk_scheduler_optimal gauge
+redpanda_disk_scheduler_optimal{device="nvme0n1"} 1
+redpanda_disk_scheduler_optimal{device="sda"} 0
+# HELP redpanda_disk_read_ahead_kb The read_ahead_kb of the device.
+# TYPE redpanda_disk_read_ahead_kb gauge
+redpanda_disk_read_ahead_kb{device="nvme0n1"} 128
+redpanda_disk_read_ahead_kb{device="sda"} 128
+# HELP redpanda_disk_read_ahead_optimal Whether the read_ahead_kb of the device is at least the one recommended for its class.
+# TYPE redpanda_disk_read_ahead_optimal gauge
+redpanda_disk_read_ahead_optimal{device="nvme0n1"} 1
+redpanda_disk_read_ahead_optimal{device="sda"} 0
+# HELP redpanda_disk_nomerges The nomerges of the device.
+# TYPE redpanda_disk_nomerges gauge
+redpanda_disk_nomerges{device="nvme0n1"} 2
+redpanda_disk_nomerges{device="sda"} 0
+# HELP redpanda_disk_nr_requests The nr_requests of the device.
+# TYPE redpanda_disk_nr_requests gauge
+redpanda_disk_nr_requests{device="nvme0n1"} 1023
+redpanda_disk_nr_requests{device="sda"} 64
+`, b.String())
+
+	// The states are left in their order.
+	require.Equal(t, "sda", states[0].Device)
+
+	b.Reset()
+	require.NoError(t, WriteDiskMetrics(&b, nil))
+	require.Empty(t, b.String())
+}
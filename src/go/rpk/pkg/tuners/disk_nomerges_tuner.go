@@ -10,9 +10,11 @@
 package tuners
 
 import (
+	"strconv"
+
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
-	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
@@ -44,14 +46,65 @@ func tuneNomerges(
 	if err != nil {
 		return NewTuneError(err)
 	}
-	err = executor.Execute(commands.NewWriteFileCmd(fs, featureFile, "2"))
+	if featureFile == "" {
+		log.Infof("Skipping '%s' as it doesn't expose its nomerges", device)
+		return NewTuneResult(false)
+	}
+	preferred, class, err := preferredNomerges(device, deviceFeatures)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if preferred < 0 {
+		log.Infof("Keeping request merging of %s device '%s'", class, device)
+		return NewTuneResult(false)
+	}
+	nomerges, err := deviceFeatures.GetNomerges(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, strconv.Itoa(preferred))
+	if isPermissionDenied(err) {
+		log.Infof("Unable to set '%s' nomerges: %v", device, err)
+		return NewTuneNotApplied("not applied, permission denied")
+	}
 	if err != nil {
 		return NewTuneError(err)
 	}
+	if !written {
+		return newTuneUnchanged()
+	}
+	log.Infof("Setting '%s' nomerges of %s device '%s' from %d to %d",
+		featureFile, class, device, nomerges, preferred)
 
 	return NewTuneResult(false)
 }
 
+// preferredNomerges returns the nomerges value to set for the device, and
+// the device class it was chosen for. Non rotational devices, NVMe ones in
+// particular, disable request merging altogether (2), as looking up the
+// outstanding requests to merge adds latency for little benefit. Rotational
+// devices return -1, as merging is still beneficial to reduce seeks and
+// they're left untouched.
+func preferredNomerges(
+	device string, deviceFeatures disk.DeviceFeatures,
+) (int, string, error) {
+	nvme, err := deviceFeatures.IsNvme(device)
+	if err != nil {
+		return 0, "", err
+	}
+	if nvme {
+		return 2, "NVMe", nil
+	}
+	rotational, err := deviceFeatures.GetRotational(device)
+	if err != nil {
+		return 0, "", err
+	}
+	if rotational {
+		return -1, "rotational", nil
+	}
+	return 2, "non-rotational", nil
+}
+
 func NewNomergesTuner(
 	fs afero.Fs,
 	directories []string,
@@ -60,13 +113,16 @@ func NewNomergesTuner(
 	executor executors.Executor,
 ) Tunable {
 	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
-	return NewDiskTuner(
+	return newDiskTuner(
 		fs,
 		directories,
 		devices,
 		blockDevices,
+		executor,
 		func(device string) Tunable {
 			return NewDeviceNomergesTuner(fs, device, deviceFeatures, executor)
 		},
+		deviceFeatures.GetNomergesFeatureFile,
+		nil,
 	)
 }
@@ -10,11 +10,14 @@
 package tuners
 
 import (
+	"context"
+	"strconv"
 	"testing"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeviceNomergesTuner_Tune(t *testing.T) {
@@ -31,8 +34,80 @@ func TestDeviceNomergesTuner_Tune(t *testing.T) {
 	fs.MkdirAll("/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue", 0o644)
 	tuner := NewDeviceNomergesTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
 	// when
-	tuner.Tune()
+	tuner.Tune(context.Background())
 	// then
 	setValue, _ := afero.ReadFile(fs, "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue/nomerges")
 	assert.Equal(t, "2", string(setValue))
 }
+
+func TestDeviceNomergesTuner_Tune_by_device_class(t *testing.T) {
+	const featureFile = "/sys/block/fake/queue/nomerges"
+	tests := []struct {
+		name       string
+		nvme       bool
+		rotational bool
+		readOnly   bool
+		want       string
+		wantDesc   string
+		notApplied string
+	}{
+		{
+			name:     "shall disable merges of NVMe devices",
+			nvme:     true,
+			want:     "2",
+			wantDesc: "0",
+		},
+		{
+			name:       "shall keep merges of rotational devices",
+			rotational: true,
+			want:       "0",
+			wantDesc:   "0 (rotational device)",
+		},
+		{
+			name:       "shall not apply changes to a read-only sysfs",
+			nvme:       true,
+			readOnly:   true,
+			want:       "0",
+			wantDesc:   "0",
+			notApplied: "not applied, permission denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fs afero.Fs = afero.NewMemMapFs()
+			afero.WriteFile(fs, featureFile, []byte("0"), 0o644)
+			if tt.readOnly {
+				fs = afero.NewReadOnlyFs(fs)
+			}
+			deviceFeatures := &deviceFeaturesMock{
+				getNomergesFeatureFile: func(string) (string, error) {
+					return featureFile, nil
+				},
+				getNomerges: func(string) (int, error) {
+					value, err := afero.ReadFile(fs, featureFile)
+					if err != nil {
+						return 0, err
+					}
+					return strconv.Atoi(string(value))
+				},
+				isNvme: func(string) (bool, error) {
+					return tt.nvme, nil
+				},
+				getRotational: func(string) (bool, error) {
+					return tt.rotational, nil
+				},
+			}
+
+			result := NewDeviceNomergesChecker("fake", deviceFeatures).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantDesc, result.Current)
+
+			res := NewDeviceNomergesTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor()).Tune(context.Background())
+			require.NoError(t, res.Error())
+			require.Equal(t, tt.notApplied, res.NotAppliedReason())
+			setValue, err := afero.ReadFile(fs, featureFile)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(setValue))
+		})
+	}
+}
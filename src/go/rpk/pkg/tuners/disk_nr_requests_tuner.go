@@ -0,0 +1,127 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"strconv"
+	"syscall"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+func NewDeviceNrRequestsTuner(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewDeviceNrRequestsChecker(device, deviceFeatures),
+		func() TuneResult {
+			return tuneNrRequests(fs, device, deviceFeatures, executor)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+// tuneNrRequests raises the nr_requests of non rotational devices to their
+// hardware queue depth, which is also the maximum the kernel accepts without
+// an I/O scheduler. Queues using the 'none' scheduler may still reject the
+// write, in which case they're skipped.
+func tuneNrRequests(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) TuneResult {
+	featureFile, err := deviceFeatures.GetNrRequestsFeatureFile(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if featureFile == "" {
+		log.Infof("Skipping '%s' as it doesn't expose its nr_requests", device)
+		return NewTuneResult(false)
+	}
+	rotational, err := deviceFeatures.GetRotational(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if rotational {
+		log.Infof("Keeping nr_requests of rotational device '%s'", device)
+		return NewTuneResult(false)
+	}
+	target, err := deviceFeatures.GetQueueDepth(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if target == 0 {
+		log.Infof("Skipping '%s' as its hardware queue depth is unknown", device)
+		return NewTuneResult(false)
+	}
+	nrRequests, err := deviceFeatures.GetNrRequests(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	// nr_requests is only raised, as a value above the hardware queue depth
+	// was set on purpose, e.g. to let the I/O scheduler merge and sort more
+	// requests.
+	if nrRequests >= target {
+		return NewTuneResult(false)
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, strconv.Itoa(target))
+	if err != nil {
+		if scheduler, _ := deviceFeatures.GetScheduler(device); scheduler == "none" &&
+			(errors.Is(err, syscall.EINVAL) || isPermissionDenied(err)) {
+			log.Infof("Unable to set '%s' nr_requests without an I/O scheduler: %v", device, err)
+			return NewTuneNotApplied("nr_requests fixed with the none scheduler")
+		}
+		if isPermissionDenied(err) {
+			log.Infof("Unable to set '%s' nr_requests: %v", device, err)
+			return NewTuneNotApplied("not applied, permission denied")
+		}
+		return NewTuneError(err)
+	}
+	if !written {
+		return newTuneUnchanged()
+	}
+	log.Infof("Raising '%s' nr_requests from %d to its queue depth %d",
+		device, nrRequests, target)
+
+	return newTuneChanged(strconv.Itoa(nrRequests), strconv.Itoa(target))
+}
+
+func NewNrRequestsTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+) Tunable {
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+	return newDiskTuner(
+		fs,
+		directories,
+		devices,
+		blockDevices,
+		executor,
+		func(device string) Tunable {
+			return NewDeviceNrRequestsTuner(fs, device, deviceFeatures, executor)
+		},
+		deviceFeatures.GetNrRequestsFeatureFile,
+		nil,
+	)
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const fNrRequests = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue/nr_requests"
+
+func TestDeviceNrRequestsTuner_Tune(t *testing.T) {
+	tests := []struct {
+		name        string
+		featureFile string
+		nrRequests  int
+		queueDepth  int
+		rotational  bool
+		want        string
+		wantDesc    string
+		wantOk      bool
+	}{
+		{
+			name:        "shall raise nr_requests to the queue depth",
+			featureFile: fNrRequests,
+			nrRequests:  256,
+			queueDepth:  1023,
+			want:        "1023",
+			wantDesc:    "256",
+		},
+		{
+			name:        "shall not lower nr_requests",
+			featureFile: fNrRequests,
+			nrRequests:  2048,
+			queueDepth:  1023,
+			want:        "unchanged",
+			wantDesc:    "2048",
+			wantOk:      true,
+		},
+		{
+			name:        "shall skip devices with an unknown queue depth",
+			featureFile: fNrRequests,
+			nrRequests:  256,
+			want:        "unchanged",
+			wantDesc:    "256 (unknown hardware queue depth)",
+			wantOk:      true,
+		},
+		{
+			name:        "shall keep nr_requests of rotational devices",
+			featureFile: fNrRequests,
+			nrRequests:  64,
+			queueDepth:  1023,
+			rotational:  true,
+			want:        "unchanged",
+			wantDesc:    "64 (rotational)",
+			wantOk:      true,
+		},
+		{
+			name:       "shall skip devices not exposing nr_requests",
+			nrRequests: 256,
+			queueDepth: 1023,
+			wantDesc:   "nr_requests not exposed by the device",
+			wantOk:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.featureFile != "" {
+				afero.WriteFile(fs, tt.featureFile, []byte("unchanged"), 0o644)
+			}
+			deviceFeatures := &deviceFeaturesMock{
+				getNrRequestsFeatureFile: func(string) (string, error) {
+					return tt.featureFile, nil
+				},
+				getNrRequests: func(string) (int, error) {
+					return tt.nrRequests, nil
+				},
+				getQueueDepth: func(string) (int, error) {
+					return tt.queueDepth, nil
+				},
+				getRotational: func(string) (bool, error) {
+					return tt.rotational, nil
+				},
+			}
+
+			result := NewDeviceNrRequestsChecker("fake", deviceFeatures).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantDesc, result.Current)
+
+			tuner := NewDeviceNrRequestsTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+			res := tuner.Tune(context.Background())
+			require.NoError(t, res.Error())
+			if tt.featureFile == "" {
+				exists, _ := afero.Exists(fs, fNrRequests)
+				require.False(t, exists)
+				return
+			}
+			setValue, err := afero.ReadFile(fs, tt.featureFile)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(setValue))
+		})
+	}
+}
+
+func TestDeviceNrRequestsTuner_values(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, fNrRequests, []byte("256\n"), 0o644)
+	deviceFeatures := &deviceFeaturesMock{
+		getNrRequestsFeatureFile: func(string) (string, error) {
+			return fNrRequests, nil
+		},
+		getNrRequests: func(string) (int, error) {
+			return 256, nil
+		},
+		getQueueDepth: func(string) (int, error) {
+			return 1023, nil
+		},
+	}
+	res := NewDeviceNrRequestsTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor()).Tune(context.Background())
+	require.NoError(t, res.Error())
+	changed, ok := res.(*tuneResult)
+	require.True(t, ok)
+	require.Equal(t, "256", changed.previous)
+	require.Equal(t, "1023", changed.value)
+}
+
+func TestDeviceNrRequestsTuner_unwritable(t *testing.T) {
+	for _, test := range []struct {
+		scheduler  string
+		wantReason string
+	}{
+		{scheduler: "none", wantReason: "nr_requests fixed with the none scheduler"},
+		{scheduler: "mq-deadline", wantReason: "not applied, permission denied"},
+	} {
+		t.Run(test.scheduler, func(t *testing.T) {
+			base := afero.NewMemMapFs()
+			afero.WriteFile(base, fNrRequests, []byte("256\n"), 0o644)
+			deviceFeatures := &deviceFeaturesMock{
+				getNrRequestsFeatureFile: func(string) (string, error) {
+					return fNrRequests, nil
+				},
+				getNrRequests: func(string) (int, error) {
+					return 256, nil
+				},
+				getQueueDepth: func(string) (int, error) {
+					return 1023, nil
+				},
+				getScheduler: func(string) (string, error) {
+					return test.scheduler, nil
+				},
+			}
+			fs := afero.NewReadOnlyFs(base)
+			res := NewDeviceNrRequestsTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor()).Tune(context.Background())
+			require.NoError(t, res.Error())
+			require.Equal(t, test.wantReason, res.NotAppliedReason())
+		})
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// Read-ahead of non rotational devices, which have no seeks to amortize.
+	flashReadAheadKB = 128
+	// Read-ahead of rotational devices, large enough to amortize the seeks
+	// of sequential reads.
+	rotationalReadAheadKB = 4096
+)
+
+// DeviceReadAhead is the read-ahead of a device before tuning and the one
+// computed for it.
+type DeviceReadAhead struct {
+	Device     string
+	PreviousKB int
+	TargetKB   int
+}
+
+// ReadAheadTuneResult is the result of the read-ahead tuner, listing the
+// read-ahead of the devices it went through.
+type ReadAheadTuneResult struct {
+	TuneResult
+	Devices []DeviceReadAhead
+	// notApplied is why the read-ahead of each device wasn't applied, if it
+	// wasn't.
+	notApplied map[string]string
+	// failed is the error each device failed to be tuned with, if it did.
+	failed map[string]string
+}
+
+func (result *ReadAheadTuneResult) DeviceResults() []DeviceTuneResult {
+	deviceResults := make([]DeviceTuneResult, 0, len(result.Devices))
+	for _, readAhead := range result.Devices {
+		res := DeviceTuneResult{
+			Device:   readAhead.Device,
+			Status:   DeviceApplied,
+			Previous: strconv.Itoa(readAhead.PreviousKB),
+			New:      strconv.Itoa(readAhead.TargetKB),
+		}
+		if failure := result.failed[readAhead.Device]; failure != "" {
+			res.Status, res.Previous, res.New, res.Error = DeviceFailed, "", "", failure
+		} else if reason := result.notApplied[readAhead.Device]; reason != "" {
+			res.Status, res.New, res.Reason = DeviceSkipped, "", reason
+			// Pseudo devices are skipped before their read-ahead is read.
+			if readAhead.TargetKB == 0 {
+				res.Previous = ""
+			}
+		} else if readAhead.PreviousKB >= readAhead.TargetKB {
+			res.Status, res.New, res.Reason = DeviceSkipped, "", "already tuned"
+		}
+		deviceResults = append(deviceResults, res)
+	}
+	return deviceResults
+}
+
+type readAheadTuner struct {
+	fs             afero.Fs
+	directories    []string
+	devices        []string
+	blockDevices   disk.BlockDevices
+	deviceFeatures disk.DeviceFeatures
+	executor       executors.Executor
+}
+
+// NewReadAheadTuner returns a tuner setting the read_ahead_kb of the given
+// devices, and of the devices holding the given directories, to a value
+// computed from their device class, see deviceReadAheadTarget. The kernel honors
+// the read-ahead of the device the reads go through, so stacked devices, e.g.
+// md arrays, get the value of the top device set on each of their members
+// too.
+func NewReadAheadTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+) Tunable {
+	return &readAheadTuner{
+		fs:             fs,
+		directories:    directories,
+		devices:        devices,
+		blockDevices:   blockDevices,
+		deviceFeatures: disk.NewDeviceFeatures(fs, blockDevices),
+		executor:       executor,
+	}
+}
+
+func (tuner *readAheadTuner) CheckIfSupported() (supported bool, reason string) {
+	if len(tuner.directories) == 0 && len(tuner.devices) == 0 {
+		return false,
+			"Either direcories or devices must be provided for disk tuner"
+	}
+	if stacks, err := tuner.deviceStacks(context.Background()); err != nil && len(stacks) == 0 {
+		if errors.Is(err, disk.ErrSysfsUnavailable) {
+			return true, ""
+		}
+		if errors.Is(err, disk.ErrUnsupportedPlatform) {
+			log.Infof("Skipping disk tuning: %v", err)
+		}
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// Tune raises the read-ahead of every device, even if some of them fail, or
+// the devices of some directories can't be resolved: the errors are returned
+// together as TuneErrors.
+func (tuner *readAheadTuner) Tune(ctx context.Context) TuneResult {
+	stacks, err := tuner.deviceStacks(ctx)
+	if err != nil && len(stacks) == 0 {
+		if res, ok := sysfsUnavailable(err); ok {
+			return res
+		}
+		return NewTuneError(err)
+	}
+	errs := (*TuneErrors)(nil).Append(err)
+	for _, stack := range stacks {
+		reportResolved(ctx, stack)
+	}
+	result := &ReadAheadTuneResult{
+		TuneResult: NewTuneResult(false),
+		notApplied: map[string]string{},
+		failed:     map[string]string{},
+	}
+	tuned := map[string]bool{}
+	fail := func(device string, err error) {
+		tuned[device] = true
+		errs = errs.Append(err)
+		result.failed[device] = err.Error()
+		result.Devices = append(result.Devices, DeviceReadAhead{Device: device})
+	}
+	skip := func(device string, res TuneResult) {
+		if !tuned[device] {
+			tuned[device] = true
+			result.TuneResult = res
+			result.notApplied[device] = res.NotAppliedReason()
+			result.Devices = append(result.Devices, DeviceReadAhead{Device: device})
+		}
+	}
+	// skipPseudo records the loop and RAM devices as not applied, e.g. the
+	// loop devices holding the directory in CI or the members of md arrays
+	// built on them.
+	skipPseudo := func(device string) bool {
+		res, ok := pseudoDevice(tuner.blockDevices, device)
+		if ok {
+			skip(device, res)
+		}
+		return ok
+	}
+	// skipUntunable records the devices not exposing their read-ahead as
+	// not applied, the other devices of their stack being tuned.
+	skipUntunable := func(device string) bool {
+		res, ok := lackingCapability(tuner.blockDevices, device, tunableReadAheadCapability)
+		if ok {
+			skip(device, res)
+		}
+		return ok
+	}
+stacks:
+	for _, stack := range stacks {
+		if skipPseudo(stack[0]) {
+			continue
+		}
+		target, err := deviceReadAheadTarget(stack[0], tuner.deviceFeatures)
+		if err != nil {
+			if !tuned[stack[0]] {
+				fail(stack[0], err)
+			}
+			continue
+		}
+		for _, device := range stack {
+			if tuned[device] {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				errs = errs.Append(fmt.Errorf("tuning of '%s' cancelled: %w", device, err))
+				break stacks
+			}
+			if skipPseudo(device) || skipUntunable(device) {
+				continue
+			}
+			tuned[device] = true
+			var readAhead *DeviceReadAhead
+			res := reportTune(ctx, device, func() (res TuneResult) {
+				readAhead, res = tuneReadAhead(
+					tuner.fs, device, target, tuner.deviceFeatures, tuner.executor)
+				return res
+			})
+			if res.IsFailed() {
+				fail(device, res.Error())
+				continue
+			}
+			if readAhead != nil {
+				result.Devices = append(result.Devices, *readAhead)
+			}
+			if res.NotAppliedReason() != "" {
+				result.TuneResult = res
+				result.notApplied[device] = res.NotAppliedReason()
+			}
+		}
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		result.TuneResult = NewTuneError(err)
+	}
+	return result
+}
+
+func (tuner *readAheadTuner) Devices() ([]string, error) {
+	stacks, err := tuner.deviceStacks(context.Background())
+	var devices []string
+	seen := map[string]bool{}
+	for _, stack := range stacks {
+		for _, device := range stack {
+			if !seen[device] {
+				seen[device] = true
+				devices = append(devices, device)
+			}
+		}
+	}
+	return devices, err
+}
+
+func (tuner *readAheadTuner) TunableFiles(device string) ([]string, error) {
+	if device == "" || !hasCapability(tuner.blockDevices, device, tunableReadAheadCapability) {
+		return nil, nil
+	}
+	file, err := tuner.deviceFeatures.GetReadAheadKBFeatureFile(device)
+	if err != nil || file == "" {
+		return nil, err
+	}
+	return []string{file}, nil
+}
+
+// deviceStacks returns the devices to tune, grouped by the device holding
+// the directory, or one of its subdirectories, they were resolved from,
+// which comes first. If the devices of some directories can't be resolved,
+// the stacks of the others are returned along with TuneErrors.
+func (tuner *readAheadTuner) deviceStacks(ctx context.Context) ([][]string, error) {
+	var (
+		stacks [][]string
+		errs   *TuneErrors
+	)
+	for _, directory := range tuner.directories {
+		directoryStacks, err := tuner.blockDevices.GetDirectoryStacks(ctx, directory)
+		if err != nil {
+			errs = errs.Append(err)
+			continue
+		}
+		stacks = append(stacks, directoryStacks...)
+	}
+	for _, device := range tuner.devices {
+		stacks = append(stacks, []string{device})
+	}
+	return stacks, errs.ErrorOrNil()
+}
+
+func tuneReadAhead(
+	fs afero.Fs,
+	device string,
+	target readAheadTarget,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) (*DeviceReadAhead, TuneResult) {
+	featureFile, err := deviceFeatures.GetReadAheadKBFeatureFile(device)
+	if err != nil {
+		return nil, NewTuneError(err)
+	}
+	if featureFile == "" {
+		log.Infof("Skipping '%s' as it doesn't expose its read_ahead_kb", device)
+		return nil, NewTuneResult(false)
+	}
+	readAheadKB, err := deviceFeatures.GetReadAheadKB(device)
+	if err != nil {
+		return nil, NewTuneError(err)
+	}
+	readAhead := &DeviceReadAhead{
+		Device:     device,
+		PreviousKB: readAheadKB,
+		TargetKB:   target.kb,
+	}
+	if target.met(readAheadKB) {
+		log.Debugf("'%s' read_ahead_kb %d already meets %d", device, readAheadKB, target.kb)
+		return readAhead, NewTuneResult(false)
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, strconv.Itoa(target.kb))
+	if written && err == nil {
+		log.Infof("Setting '%s' read_ahead_kb from %d to %d", device, readAheadKB, target.kb)
+	}
+	if isPermissionDenied(err) {
+		log.Infof("Unable to set '%s' read_ahead_kb: %v", device, err)
+		return readAhead, NewTuneNotApplied("not applied, permission denied")
+	}
+	if err != nil {
+		return nil, NewTuneError(err)
+	}
+	return readAhead, NewTuneResult(false)
+}
+
+// readAheadTarget is the read_ahead_kb recommended for a device, and the
+// device class it was chosen for.
+type readAheadTarget struct {
+	kb    int
+	class string
+	// minimum is set if larger values are kept rather than lowered, as they
+	// were set on purpose. Only the read-ahead of non rotational devices,
+	// which have no seeks to amortize, is set as is.
+	minimum bool
+}
+
+// met returns whether the read-ahead of the device meets the target.
+func (t readAheadTarget) met(readAheadKB int) bool {
+	if t.minimum {
+		return readAheadKB >= t.kb
+	}
+	return readAheadKB == t.kb
+}
+
+// deviceReadAheadTarget returns the read_ahead_kb to set for the device:
+// small for NVMe and other non rotational devices, large for rotational
+// ones. md arrays read at least a full stripe ahead, so that sequential reads
+// keep all of their members busy, except RAID1 arrays: each of their reads is
+// served by a single member, so there's no stripe to scale by. The targets of
+// rotational devices and md arrays are minimums.
+func deviceReadAheadTarget(
+	device string, deviceFeatures disk.DeviceFeatures,
+) (readAheadTarget, error) {
+	target := readAheadTarget{kb: flashReadAheadKB, class: "non-rotational"}
+	nvme, err := deviceFeatures.IsNvme(device)
+	if err != nil {
+		return readAheadTarget{}, err
+	}
+	if nvme {
+		target.class = "NVMe"
+	} else {
+		rotational, err := deviceFeatures.GetRotational(device)
+		if err != nil {
+			return readAheadTarget{}, err
+		}
+		if rotational {
+			target = readAheadTarget{kb: rotationalReadAheadKB, class: "rotational", minimum: true}
+		}
+	}
+	md, err := deviceFeatures.GetMdArray(device)
+	if err != nil {
+		return readAheadTarget{}, err
+	}
+	if md != nil {
+		if stripeKB := int(md.StripeWidth() / 1024); md.Level != "raid1" && stripeKB > target.kb {
+			target.kb, target.minimum = stripeKB, true
+		}
+		target.class = target.class + " " + md.Level
+	}
+	log.Debugf("Using %dKB read-ahead for %s device '%s'", target.kb, target.class, device)
+	return target, nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func readAheadFile(device string) string {
+	return filepath.Join("/sys/block", device, "queue", "read_ahead_kb")
+}
+
+func readAheadFeaturesMock(
+	fs afero.Fs, rotational map[string]bool, arrays map[string]*disk.MdArray,
+) *deviceFeaturesMock {
+	return &deviceFeaturesMock{
+		getReadAheadFeatureFile: func(device string) (string, error) {
+			return readAheadFile(device), nil
+		},
+		getReadAheadKB: func(device string) (int, error) {
+			value, err := afero.ReadFile(fs, readAheadFile(device))
+			if err != nil {
+				return 0, err
+			}
+			return strconv.Atoi(string(value))
+		},
+		isNvme: func(device string) (bool, error) {
+			return strings.HasPrefix(device, "nvme"), nil
+		},
+		getRotational: func(device string) (bool, error) {
+			return rotational[device], nil
+		},
+		getMdArray: func(device string) (*disk.MdArray, error) {
+			return arrays[device], nil
+		},
+	}
+}
+
+func TestReadAheadTuner_Tune(t *testing.T) {
+	tests := []struct {
+		name       string
+		stack      []string
+		rotational map[string]bool
+		arrays     map[string]*disk.MdArray
+		previous   string
+		untouched  bool
+		want       []DeviceReadAhead
+	}{
+		{
+			name:  "shall set a small read-ahead on NVMe devices",
+			stack: []string{"nvme0n1"},
+			want:  []DeviceReadAhead{{"nvme0n1", 256, 128}},
+		},
+		{
+			name:       "shall leave rotational devices reading further ahead untouched",
+			stack:      []string{"sda"},
+			rotational: map[string]bool{"sda": true},
+			previous:   "8192",
+			untouched:  true,
+			want:       []DeviceReadAhead{{"sda", 8192, 4096}},
+		},
+		{
+			name:       "shall set a large read-ahead on rotational devices",
+			stack:      []string{"sda"},
+			rotational: map[string]bool{"sda": true},
+			want:       []DeviceReadAhead{{"sda", 256, 4096}},
+		},
+		{
+			name:  "shall read a full stripe ahead on md arrays and their members",
+			stack: []string{"md0", "sda", "sdb", "sdc", "sdd"},
+			arrays: map[string]*disk.MdArray{
+				"md0": {Level: "raid0", ChunkSize: 512 * 1024, Disks: 4},
+			},
+			want: []DeviceReadAhead{
+				{"md0", 256, 2048},
+				{"sda", 256, 2048},
+				{"sdb", 256, 2048},
+				{"sdc", 256, 2048},
+				{"sdd", 256, 2048},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			previous := tt.previous
+			if previous == "" {
+				previous = "256"
+			}
+			for _, device := range tt.stack {
+				afero.WriteFile(fs, readAheadFile(device), []byte(previous), 0o644)
+			}
+			tuner := &readAheadTuner{
+				fs:          fs,
+				directories: []string{"/var/lib/redpanda"},
+				blockDevices: &blockDevicesMock{
+					getDirectoryStacks: func(string) ([][]string, error) {
+						return [][]string{tt.stack}, nil
+					},
+				},
+				deviceFeatures: readAheadFeaturesMock(fs, tt.rotational, tt.arrays),
+				executor:       executors.NewDirectExecutor(),
+			}
+			res := tuner.Tune(context.Background())
+			require.NoError(t, res.Error())
+			require.Equal(t, tt.want, res.(*ReadAheadTuneResult).Devices)
+			for _, readAhead := range tt.want {
+				want := strconv.Itoa(readAhead.TargetKB)
+				if tt.untouched {
+					want = previous
+				}
+				value, err := afero.ReadFile(fs, readAheadFile(readAhead.Device))
+				require.NoError(t, err)
+				require.Equal(t, want, string(value))
+			}
+
+			// Tuning again leaves the devices untouched.
+			res = tuner.Tune(context.Background())
+			require.NoError(t, res.Error())
+			for _, deviceResult := range res.(DeviceTuneResults).DeviceResults() {
+				require.Equal(t, DeviceSkipped, deviceResult.Status)
+			}
+		})
+	}
+}
+
+func TestReadAheadTuner_Tune_raid1(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	stack := []string{"md0", "sda", "sdb"}
+	for _, device := range stack {
+		afero.WriteFile(fs, readAheadFile(device), []byte("256"), 0o644)
+	}
+	tuner := &readAheadTuner{
+		fs:          fs,
+		directories: []string{"/var/lib/redpanda"},
+		blockDevices: &blockDevicesMock{
+			getDirectoryStacks: func(string) ([][]string, error) {
+				return [][]string{stack}, nil
+			},
+		},
+		// The chunk size isn't scaled by: each read is served by a single
+		// member.
+		deviceFeatures: readAheadFeaturesMock(
+			fs,
+			map[string]bool{"md0": true, "sda": true, "sdb": true},
+			map[string]*disk.MdArray{
+				"md0": {Level: "raid1", ChunkSize: 8192 * 1024, Disks: 2},
+			},
+		),
+		executor: executors.NewDirectExecutor(),
+	}
+	res := tuner.Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, []DeviceReadAhead{
+		{"md0", 256, 4096},
+		{"sda", 256, 4096},
+		{"sdb", 256, 4096},
+	}, res.(*ReadAheadTuneResult).Devices)
+	for _, device := range stack {
+		value, err := afero.ReadFile(fs, readAheadFile(device))
+		require.NoError(t, err)
+		require.Equal(t, "4096", string(value))
+	}
+}
+
+func TestReadAheadTuner_Tune_read_only(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, readAheadFile("nvme0n1"), []byte("256"), 0o644)
+	fs := afero.NewReadOnlyFs(memFs)
+	tuner := &readAheadTuner{
+		fs:      fs,
+		devices: []string{"nvme0n1"},
+		blockDevices: &blockDevicesMock{
+			getDirectoryStacks: func(string) ([][]string, error) {
+				return nil, nil
+			},
+		},
+		deviceFeatures: readAheadFeaturesMock(fs, nil, nil),
+		executor:       executors.NewDirectExecutor(),
+	}
+	res := tuner.Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, "not applied, permission denied", res.NotAppliedReason())
+	require.Equal(t, []DeviceReadAhead{{"nvme0n1", 256, 128}}, res.(*ReadAheadTuneResult).Devices)
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "nvme0n1", Status: DeviceSkipped, Previous: "256", Reason: "not applied, permission denied"},
+	}, res.(DeviceTuneResults).DeviceResults())
+}
+
+func TestReadAheadTuner_Tune_partialFailures(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, readAheadFile("sdb"), []byte("256"), 0o644)
+	tuner := &readAheadTuner{
+		fs:      fs,
+		devices: []string{"sda", "sdb"},
+		blockDevices: &blockDevicesMock{
+			getDirectoryStacks: func(string) ([][]string, error) {
+				return nil, nil
+			},
+		},
+		deviceFeatures: readAheadFeaturesMock(fs, map[string]bool{"sda": true, "sdb": true}, nil),
+		executor:       executors.NewDirectExecutor(),
+	}
+	// sda doesn't expose its read-ahead, sdb is tuned anyway.
+	res := tuner.Tune(context.Background())
+	require.True(t, res.IsFailed())
+	require.ErrorIs(t, res.Error(), os.ErrNotExist)
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "sda", Status: DeviceFailed, Error: res.Error().Error()},
+		{Device: "sdb", Status: DeviceApplied, Previous: "256", New: "4096"},
+	}, res.(DeviceTuneResults).DeviceResults())
+	value, err := afero.ReadFile(fs, readAheadFile("sdb"))
+	require.NoError(t, err)
+	require.Equal(t, "4096", string(value))
+}
+
+func TestDeviceReadAheadChecker(t *testing.T) {
+	tests := []struct {
+		name       string
+		device     string
+		readAhead  string
+		rotational bool
+		arrays     map[string]*disk.MdArray
+		wantOk     bool
+		wantDesc   string
+	}{
+		{
+			name:       "shall fail for rotational devices below the threshold",
+			device:     "sda",
+			readAhead:  "128",
+			rotational: true,
+			wantDesc:   "128KB (below 4096KB)",
+		},
+		{
+			name:       "shall pass for rotational devices above the threshold",
+			device:     "sda",
+			readAhead:  "8192",
+			rotational: true,
+			wantOk:     true,
+			wantDesc:   "8192KB",
+		},
+		{
+			name:      "shall pass for NVMe devices at the target",
+			device:    "nvme0n1",
+			readAhead: "128",
+			wantOk:    true,
+			wantDesc:  "128KB",
+		},
+		{
+			name:      "shall fail for NVMe devices off the target",
+			device:    "nvme0n1",
+			readAhead: "1024",
+			wantDesc:  "1024KB (128KB for NVMe device)",
+		},
+		{
+			name:       "shall fail for rotational RAID1 arrays below the rotational minimum",
+			device:     "md0",
+			readAhead:  "256",
+			rotational: true,
+			arrays:     map[string]*disk.MdArray{"md0": {Level: "raid1", ChunkSize: 8192 * 1024, Disks: 2}},
+			wantDesc:   "256KB (below 4096KB)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			afero.WriteFile(fs, readAheadFile(tt.device), []byte(tt.readAhead), 0o644)
+			deviceFeatures := readAheadFeaturesMock(
+				fs, map[string]bool{tt.device: tt.rotational}, tt.arrays)
+			result := NewDeviceReadAheadChecker(tt.device, deviceFeatures).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantDesc, result.Current)
+		})
+	}
+}
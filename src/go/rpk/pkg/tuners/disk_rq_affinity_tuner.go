@@ -0,0 +1,95 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// NewDeviceRqAffinityTuner returns a tuner completing the I/O requests of
+// the device on the CPU which issued them, so that each shard handles its own
+// completions. Rotational devices are left untouched, and reported as skipped.
+func NewDeviceRqAffinityTuner(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) Tunable {
+	return &nonRotationalTunable{
+		checkedTunable: NewCheckedTunable(
+			NewDeviceRqAffinityChecker(device, deviceFeatures),
+			func() TuneResult {
+				return tuneRqAffinity(fs, device, deviceFeatures, executor)
+			},
+			func() (bool, string) {
+				return true, ""
+			},
+			executor.IsLazy(),
+		).(*checkedTunable),
+		device:         device,
+		attribute:      "rq_affinity",
+		deviceFeatures: deviceFeatures,
+	}
+}
+
+func tuneRqAffinity(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) TuneResult {
+	featureFile, err := deviceFeatures.GetRqAffinityFeatureFile(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if featureFile == "" {
+		log.Infof("Skipping '%s' as it doesn't expose its rq_affinity", device)
+		return NewTuneResult(false)
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, "2")
+	if isPermissionDenied(err) {
+		log.Infof("Unable to set '%s' rq_affinity: %v", device, err)
+		return NewTuneNotApplied("not applied, permission denied")
+	}
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if !written {
+		return newTuneUnchanged()
+	}
+	log.Infof("Completing the requests of device '%s' on their CPU", device)
+
+	return NewTuneResult(false)
+}
+
+func NewRqAffinityTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+) Tunable {
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+	return newDiskTuner(
+		fs,
+		directories,
+		devices,
+		blockDevices,
+		executor,
+		func(device string) Tunable {
+			return NewDeviceRqAffinityTuner(fs, device, deviceFeatures, executor)
+		},
+		deviceFeatures.GetRqAffinityFeatureFile,
+		nil,
+	)
+}
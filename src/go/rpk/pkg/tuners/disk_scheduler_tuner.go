@@ -11,26 +11,33 @@ package tuners
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
-	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
+// NewDeviceSchedulerTuner returns a tuner setting the scheduler of the
+// device: the override if not empty, or the one preferred for its device
+// class, see schedulerPreferences.
 func NewDeviceSchedulerTuner(
 	fs afero.Fs,
 	device string,
+	override string,
 	deviceFeatures disk.DeviceFeatures,
 	executor executors.Executor,
 ) Tunable {
 	return NewCheckedTunable(
-		NewDeviceSchedulerChecker(fs, device, deviceFeatures),
+		NewDeviceSchedulerChecker(fs, device, override, deviceFeatures),
 		func() TuneResult {
-			return tuneScheduler(fs, device, deviceFeatures, executor)
+			return tuneScheduler(fs, device, override, deviceFeatures, executor)
 		},
 		func() (bool, string) {
-			_, err := getPreferredScheduler(device, deviceFeatures)
+			_, err := getScheduler(device, override, deviceFeatures)
 			if err != nil {
 				return false, err.Error()
 			}
@@ -43,34 +50,166 @@ func NewDeviceSchedulerTuner(
 func tuneScheduler(
 	fs afero.Fs,
 	device string,
+	override string,
 	deviceFeatures disk.DeviceFeatures,
 	executor executors.Executor,
 ) TuneResult {
-	preferredScheduler, err := getPreferredScheduler(device, deviceFeatures)
+	preferredScheduler, err := getScheduler(device, override, deviceFeatures)
 	if err != nil {
 		return NewTuneError(err)
 	}
+	if preferredScheduler == "" {
+		return NewTuneResult(false)
+	}
 	featureFile, err := deviceFeatures.GetSchedulerFeatureFile(device)
 	if err != nil {
 		return NewTuneError(err)
 	}
-	err = executor.Execute(
-		commands.NewWriteFileCmd(fs, featureFile, preferredScheduler))
+	// Read before the write only to report it if the device ignores it.
+	previous, err := disk.ReadActiveOption(fs, featureFile)
+	if err != nil {
+		log.Debugf("Unable to read the '%s' scheduler before setting it: %v", device, err)
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, preferredScheduler)
 	if err != nil {
 		return NewTuneError(err)
 	}
+	if !written {
+		log.Infof("'%s' scheduler is already '%s'", device, preferredScheduler)
+		return newTuneUnchanged()
+	}
+	// Lazy executors don't write anything yet, there is nothing to verify.
+	if !executor.IsLazy() {
+		if err := verifyScheduler(fs, device, featureFile, previous, preferredScheduler); err != nil {
+			return NewTuneError(err)
+		}
+	}
+	if override != "" {
+		log.Infof("Setting '%s' scheduler for device '%s', as requested", preferredScheduler, device)
+	} else {
+		_, class, err := schedulerPreferences(device, deviceFeatures)
+		if err != nil {
+			return NewTuneError(err)
+		}
+		log.Infof("Setting '%s' scheduler for %s device '%s'", preferredScheduler, class, device)
+	}
 
 	return NewTuneResult(false)
 }
 
+// verifyScheduler reads the active scheduler back after setting it, as some
+// devices, e.g. some virtio-blk ones, accept the write but ignore it. As
+// nothing changed, nothing is restored.
+func verifyScheduler(fs afero.Fs, device, featureFile, previous, requested string) error {
+	active, err := disk.ReadActiveOption(fs, featureFile)
+	if err != nil {
+		return fmt.Errorf("unable to read the '%s' scheduler back after setting it to '%s': %w", device, requested, err)
+	}
+	if active == requested {
+		return nil
+	}
+	log.Warnf("'%s' accepted the '%s' scheduler but its active scheduler is"+
+		" '%s': the device ignores the scheduler it's given, which is left"+
+		" as is", device, requested, active)
+	return fmt.Errorf("'%s' ignored the '%s' scheduler: active scheduler was '%s' before setting it and is '%s' after",
+		device, requested, previous, active)
+}
+
+// rotationalSchedulers are the schedulers of rotational devices, which
+// reduce seeks.
+var rotationalSchedulers = []string{"mq-deadline", "bfq", "deadline"}
+
+// schedulerPreferences returns the schedulers to use for the device, from
+// the most to the least preferred, and the device class they were chosen
+// for:
+//
+//   - NVMe devices bypass the scheduler as they handle the I/O ordering
+//     themselves.
+//   - Other non rotational devices, e.g. SATA SSDs, use mq-deadline to avoid
+//     write starvation, or no scheduler if it's not available.
+//   - Rotational devices use mq-deadline or bfq, which reduce seeks.
+//
+// 'noop' and 'deadline' are the legacy single queue equivalents of 'none'
+// and 'mq-deadline'.
+func schedulerPreferences(
+	device string, deviceFeatures disk.DeviceFeatures,
+) ([]string, string, error) {
+	if strings.HasPrefix(device, "nvme") {
+		return []string{"none", "noop"}, "NVMe", nil
+	}
+	rotational, err := deviceFeatures.GetRotational(device)
+	if err != nil {
+		return nil, "", err
+	}
+	if rotational {
+		return rotationalSchedulers, "rotational", nil
+	}
+	return []string{"mq-deadline", "none", "noop"}, "non-rotational", nil
+}
+
+// getPreferredScheduler returns the most preferred scheduler supported by
+// the device, or an empty string if the device doesn't expose its scheduler,
+// e.g. some virtio devices, as there is nothing to tune.
 func getPreferredScheduler(
 	device string, deviceFeatures disk.DeviceFeatures,
 ) (string, error) {
+	featureFile, err := deviceFeatures.GetSchedulerFeatureFile(device)
+	if err != nil {
+		return "", err
+	}
+	if featureFile == "" {
+		log.Infof("Skipping '%s' as it doesn't expose its I/O scheduler", device)
+		return "", nil
+	}
 	supported, err := deviceFeatures.GetSupportedSchedulers(device)
 	if err != nil {
 		return "", err
 	}
-	preferred := []string{"none", "noop"}
+	preferred, class, err := schedulerPreferences(device, deviceFeatures)
+	if err != nil {
+		return "", err
+	}
+	sched, err := pickScheduler(preferred, supported)
+	if err != nil {
+		return "", fmt.Errorf("%w for %s device %s", err, class, device)
+	}
+	log.Debugf("Using '%s' scheduler for %s device '%s'", sched, class, device)
+	return sched, nil
+}
+
+// getScheduler returns the scheduler to set for the device: the override if
+// not empty, which must be one of the schedulers the device supports, or
+// the preferred one otherwise, see getPreferredScheduler.
+func getScheduler(
+	device string, override string, deviceFeatures disk.DeviceFeatures,
+) (string, error) {
+	if override == "" {
+		return getPreferredScheduler(device, deviceFeatures)
+	}
+	featureFile, err := deviceFeatures.GetSchedulerFeatureFile(device)
+	if err != nil {
+		return "", err
+	}
+	if featureFile == "" {
+		return "", fmt.Errorf("unable to set the '%s' scheduler requested for '%s', it doesn't expose its I/O scheduler",
+			override, device)
+	}
+	supported, err := deviceFeatures.GetSupportedSchedulers(device)
+	if err != nil {
+		return "", err
+	}
+	for _, sched := range supported {
+		if sched == override {
+			return override, nil
+		}
+	}
+	return "", fmt.Errorf("scheduler '%s' requested for '%s' is not supported, the available schedulers are: %s",
+		override, device, strings.Join(supported, ", "))
+}
+
+// pickScheduler returns the first of the preferred schedulers which is
+// supported.
+func pickScheduler(preferred, supported []string) (string, error) {
 	supportedMap := make(map[string]bool)
 
 	for _, sched := range supported {
@@ -82,25 +221,94 @@ func getPreferredScheduler(
 			return sched, nil
 		}
 	}
-	return "", fmt.Errorf("None and Noop schedulers are not supported for %s",
-		device)
+	return "", fmt.Errorf("none of the %s schedulers are supported",
+		strings.Join(preferred, ", "))
+}
+
+// SlowDeviceWriteLatency is the p99 latency of synchronous writes above
+// which a non rotational device is scheduled like a rotational one, e.g.
+// SATA SSDs behind a controller that doesn't keep up under load.
+const SlowDeviceWriteLatency = 5 * time.Millisecond
+
+// SchedulerRecommendation is the scheduler recommended for a device from
+// its device class and the measured write latency of its directory.
+type SchedulerRecommendation struct {
+	Device      string `json:"device"`
+	Class       string `json:"class"`
+	Current     string `json:"current_scheduler"`
+	Recommended string `json:"recommended_scheduler"`
 }
 
+// RecommendScheduler returns the scheduler recommended for the device. It
+// prefers the schedulers of rotational devices for non rotational devices
+// whose write latency is above SlowDeviceWriteLatency, as they suffer from
+// concurrent I/O just as much. It returns nil for devices not exposing their
+// scheduler.
+func RecommendScheduler(
+	device string, deviceFeatures disk.DeviceFeatures, latency *disk.WriteLatency,
+) (*SchedulerRecommendation, error) {
+	featureFile, err := deviceFeatures.GetSchedulerFeatureFile(device)
+	if err != nil {
+		return nil, err
+	}
+	if featureFile == "" {
+		return nil, nil
+	}
+	preferred, class, err := schedulerPreferences(device, deviceFeatures)
+	if err != nil {
+		return nil, err
+	}
+	if class != "rotational" && latency != nil && latency.P99 > SlowDeviceWriteLatency {
+		preferred, class = rotationalSchedulers, "slow "+class
+	}
+	supported, err := deviceFeatures.GetSupportedSchedulers(device)
+	if err != nil {
+		return nil, err
+	}
+	recommended, err := pickScheduler(preferred, supported)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s device %s", err, class, device)
+	}
+	current, err := deviceFeatures.GetScheduler(device)
+	if err != nil {
+		return nil, err
+	}
+	return &SchedulerRecommendation{
+		Device:      device,
+		Class:       class,
+		Current:     current,
+		Recommended: recommended,
+	}, nil
+}
+
+// NewSchedulerTuner returns a tuner setting the scheduler of the devices,
+// and of the devices holding the directories. The overrides map device names,
+// or paths e.g. '/dev/nvme0n1', to the scheduler to set in place of the one
+// preferred for their device class. Devices without a scheduler to pick, see
+// disk.DeviceCapabilities, are skipped.
 func NewSchedulerTuner(
 	fs afero.Fs,
 	directories []string,
 	devices []string,
+	overrides map[string]string,
 	blockDevices disk.BlockDevices,
 	executor executors.Executor,
 ) Tunable {
 	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
-	return NewDiskTuner(
+	byName := make(map[string]string, len(overrides))
+	for device, sched := range overrides {
+		byName[filepath.Base(device)] = sched
+	}
+	return newDiskTuner(
 		fs,
 		directories,
 		devices,
 		blockDevices,
+		executor,
 		func(device string) Tunable {
-			return NewDeviceSchedulerTuner(fs, device, deviceFeatures, executor)
+			return NewDeviceSchedulerTuner(fs, device, byName[device], deviceFeatures, executor)
 		},
+		deviceFeatures.GetSchedulerFeatureFile,
+		tunableSchedulerCapability,
 	)
 }
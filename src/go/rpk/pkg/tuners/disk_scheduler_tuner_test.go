@@ -10,7 +10,10 @@
 package tuners
 
 import (
+	"context"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
@@ -32,6 +35,26 @@ type deviceFeaturesMock struct {
 	getScheduler             func(string) (string, error)
 	getWriteCacheFeatureFile func(string) (string, error)
 	getWriteCache            func(string) (string, error)
+	getRotational            func(string) (bool, error)
+	getNrRequests            func(string) (int, error)
+	getNrRequestsFeatureFile func(string) (string, error)
+	getAddRandom             func(string) (int, error)
+	getAddRandomFeatureFile  func(string) (string, error)
+	getRqAffinity            func(string) (int, error)
+	getRqAffinityFile        func(string) (string, error)
+	getMaxSectorsKB          func(string) (int, error)
+	getMaxSectorsKBFile      func(string) (string, error)
+	getMaxHwSectorsKB        func(string) (int, error)
+	getOptimalIOSize         func(string) (int, error)
+	getQueueDepth            func(string) (int, error)
+	isNvme                   func(string) (bool, error)
+	getMdArray               func(string) (*disk.MdArray, error)
+	getDeviceClass           func(string) (disk.DeviceClass, error)
+	getReadAheadKB           func(string) (int, error)
+	getReadAheadFeatureFile  func(string) (string, error)
+	getDriveWriteCacheFile   func(string) (string, error)
+	hasVolatileWriteCache    func(string) (bool, error)
+	getZonedModel            func(string) (string, error)
 }
 
 func (m *deviceFeaturesMock) GetScheduler(device string) (string, error) {
@@ -70,6 +93,113 @@ func (m *deviceFeaturesMock) GetWriteCache(device string) (string, error) {
 	return m.getWriteCache(device)
 }
 
+func (m *deviceFeaturesMock) GetNrRequests(device string) (int, error) {
+	return m.getNrRequests(device)
+}
+
+func (m *deviceFeaturesMock) GetNrRequestsFeatureFile(
+	device string,
+) (string, error) {
+	return m.getNrRequestsFeatureFile(device)
+}
+
+func (m *deviceFeaturesMock) GetAddRandom(device string) (int, error) {
+	return m.getAddRandom(device)
+}
+
+func (m *deviceFeaturesMock) GetAddRandomFeatureFile(
+	device string,
+) (string, error) {
+	return m.getAddRandomFeatureFile(device)
+}
+
+func (m *deviceFeaturesMock) GetRqAffinity(device string) (int, error) {
+	return m.getRqAffinity(device)
+}
+
+func (m *deviceFeaturesMock) GetRqAffinityFeatureFile(
+	device string,
+) (string, error) {
+	return m.getRqAffinityFile(device)
+}
+
+func (m *deviceFeaturesMock) GetMaxSectorsKB(device string) (int, error) {
+	return m.getMaxSectorsKB(device)
+}
+
+func (m *deviceFeaturesMock) GetMaxSectorsKBFeatureFile(
+	device string,
+) (string, error) {
+	return m.getMaxSectorsKBFile(device)
+}
+
+func (m *deviceFeaturesMock) GetMaxHwSectorsKB(device string) (int, error) {
+	return m.getMaxHwSectorsKB(device)
+}
+
+func (m *deviceFeaturesMock) GetOptimalIOSize(device string) (int, error) {
+	return m.getOptimalIOSize(device)
+}
+
+func (m *deviceFeaturesMock) GetQueueDepth(device string) (int, error) {
+	return m.getQueueDepth(device)
+}
+
+func (m *deviceFeaturesMock) IsNvme(device string) (bool, error) {
+	if m.isNvme == nil {
+		return false, nil
+	}
+	return m.isNvme(device)
+}
+
+func (m *deviceFeaturesMock) GetMdArray(device string) (*disk.MdArray, error) {
+	if m.getMdArray == nil {
+		return nil, nil
+	}
+	return m.getMdArray(device)
+}
+
+func (m *deviceFeaturesMock) GetDeviceClass(device string) (disk.DeviceClass, error) {
+	if m.getDeviceClass == nil {
+		return disk.DeviceClassLocalSSD, nil
+	}
+	return m.getDeviceClass(device)
+}
+
+func (m *deviceFeaturesMock) GetZonedModel(device string) (string, error) {
+	if m.getZonedModel == nil {
+		return disk.ZonedNone, nil
+	}
+	return m.getZonedModel(device)
+}
+
+func (m *deviceFeaturesMock) GetReadAheadKB(device string) (int, error) {
+	return m.getReadAheadKB(device)
+}
+
+func (m *deviceFeaturesMock) GetReadAheadKBFeatureFile(
+	device string,
+) (string, error) {
+	return m.getReadAheadFeatureFile(device)
+}
+
+func (m *deviceFeaturesMock) GetDriveWriteCacheFeatureFile(
+	device string,
+) (string, error) {
+	return m.getDriveWriteCacheFile(device)
+}
+
+func (m *deviceFeaturesMock) HasVolatileWriteCache(device string) (bool, error) {
+	return m.hasVolatileWriteCache(device)
+}
+
+func (m *deviceFeaturesMock) GetRotational(device string) (bool, error) {
+	if m.getRotational == nil {
+		return false, nil
+	}
+	return m.getRotational(device)
+}
+
 func TestDeviceSchedulerTuner_Tune(t *testing.T) {
 	// given
 	deviceFeatures := &deviceFeaturesMock{
@@ -85,9 +215,9 @@ func TestDeviceSchedulerTuner_Tune(t *testing.T) {
 	}
 	fs := afero.NewMemMapFs()
 	fs.MkdirAll("/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue", 0o644)
-	tuner := NewDeviceSchedulerTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+	tuner := NewDeviceSchedulerTuner(fs, "fake", "", deviceFeatures, executors.NewDirectExecutor())
 	// when
-	tuner.Tune()
+	tuner.Tune(context.Background())
 	// then
 	setValue, _ := afero.ReadFile(fs, fScheduler)
 	require.Equal(t, "noop", string(setValue))
@@ -108,7 +238,7 @@ func TestDeviceSchedulerTuner_IsSupported_Should_return_true(t *testing.T) {
 	}
 	fs := afero.NewMemMapFs()
 	fs.MkdirAll("/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue", 0o644)
-	tuner := NewDeviceSchedulerTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+	tuner := NewDeviceSchedulerTuner(fs, "fake", "", deviceFeatures, executors.NewDirectExecutor())
 	// when
 	supported, _ := tuner.CheckIfSupported()
 	// then
@@ -130,7 +260,7 @@ func TestDeviceSchedulerTuner_IsSupported_should_return_false(t *testing.T) {
 	}
 	fs := afero.NewMemMapFs()
 	fs.MkdirAll("/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue", 0o644)
-	tuner := NewDeviceSchedulerTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+	tuner := NewDeviceSchedulerTuner(fs, "fake", "", deviceFeatures, executors.NewDirectExecutor())
 	// when
 	supported, _ := tuner.CheckIfSupported()
 	// then
@@ -152,10 +282,264 @@ func TestDeviceSchedulerTuner_Tune_should_prefer_none_over_noop(t *testing.T) {
 	}
 	fs := afero.NewMemMapFs()
 	fs.MkdirAll("/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue", 0o644)
-	tuner := NewDeviceSchedulerTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+	tuner := NewDeviceSchedulerTuner(fs, "fake", "", deviceFeatures, executors.NewDirectExecutor())
 	// when
-	tuner.Tune()
+	tuner.Tune(context.Background())
 	// then
 	setValue, _ := afero.ReadFile(fs, fScheduler)
 	require.Equal(t, "none", string(setValue))
 }
+
+func TestDeviceSchedulerTuner_Tune_by_device_class(t *testing.T) {
+	tests := []struct {
+		name       string
+		device     string
+		rotational bool
+		supported  []string
+		active     string
+		want       string
+	}{
+		{
+			name:      "shall use none for NVMe devices",
+			device:    "nvme0n1",
+			supported: []string{"mq-deadline", "kyber", "none"},
+			active:    "mq-deadline",
+			want:      "none",
+		},
+		{
+			name:      "shall use mq-deadline for non rotational devices",
+			device:    "sda",
+			supported: []string{"mq-deadline", "kyber", "bfq", "none"},
+			active:    "none",
+			want:      "mq-deadline",
+		},
+		{
+			name:      "shall fall back to none for non rotational devices",
+			device:    "sda",
+			supported: []string{"kyber", "none"},
+			active:    "kyber",
+			want:      "none",
+		},
+		{
+			name:       "shall use mq-deadline for rotational devices",
+			device:     "sdb",
+			rotational: true,
+			supported:  []string{"mq-deadline", "bfq", "none"},
+			active:     "none",
+			want:       "mq-deadline",
+		},
+		{
+			name:       "shall fall back to bfq for rotational devices",
+			device:     "sdb",
+			rotational: true,
+			supported:  []string{"bfq", "none"},
+			active:     "none",
+			want:       "bfq",
+		},
+		{
+			name:      "shall not write the active scheduler",
+			device:    "nvme0n1",
+			supported: []string{"mq-deadline", "none"},
+			active:    "none",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceFeatures := &deviceFeaturesMock{
+				getSchedulerFeatureFile: func(string) (string, error) {
+					return fScheduler, nil
+				},
+				getScheduler: func(string) (string, error) {
+					return tt.active, nil
+				},
+				getSupportedSchedulers: func(string) ([]string, error) {
+					return tt.supported, nil
+				},
+				getRotational: func(string) (bool, error) {
+					return tt.rotational, nil
+				},
+			}
+			fs := afero.NewMemMapFs()
+			tuner := NewDeviceSchedulerTuner(fs, tt.device, "", deviceFeatures, executors.NewDirectExecutor())
+			supported, _ := tuner.CheckIfSupported()
+			require.True(t, supported)
+			res := tuner.Tune(context.Background())
+			require.False(t, res.IsFailed())
+			setValue, _ := afero.ReadFile(fs, fScheduler)
+			require.Equal(t, tt.want, string(setValue))
+		})
+	}
+}
+
+func TestDeviceSchedulerTuner_Tune_override(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "shall set the requested scheduler over the preferred one",
+			override: "kyber",
+			want:     "kyber",
+		},
+		{
+			name:     "shall fail listing the available schedulers if the requested one is not supported",
+			override: "bfq",
+			want:     "mq-deadline",
+			wantErr:  "scheduler 'bfq' requested for 'nvme0n1' is not supported, the available schedulers are: none, mq-deadline, kyber",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			afero.WriteFile(fs, fScheduler, []byte("mq-deadline"), 0o644)
+			deviceFeatures := &deviceFeaturesMock{
+				getSchedulerFeatureFile: func(string) (string, error) {
+					return fScheduler, nil
+				},
+				getScheduler: func(string) (string, error) {
+					value, err := afero.ReadFile(fs, fScheduler)
+					return string(value), err
+				},
+				getSupportedSchedulers: func(string) ([]string, error) {
+					return []string{"none", "mq-deadline", "kyber"}, nil
+				},
+			}
+			tuner := NewDeviceSchedulerTuner(fs, "nvme0n1", tt.override, deviceFeatures, executors.NewDirectExecutor())
+			res := tuner.Tune(context.Background())
+			if tt.wantErr != "" {
+				require.True(t, res.IsFailed())
+				require.EqualError(t, res.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, res.Error())
+			}
+			setValue, err := afero.ReadFile(fs, fScheduler)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(setValue))
+		})
+	}
+}
+
+func TestDeviceSchedulerTuner_without_scheduler(t *testing.T) {
+	deviceFeatures := &deviceFeaturesMock{
+		getSchedulerFeatureFile: func(string) (string, error) {
+			return "", nil
+		},
+	}
+	tuner := NewDeviceSchedulerTuner(afero.NewMemMapFs(), "vda", "", deviceFeatures, executors.NewDirectExecutor())
+	supported, _ := tuner.CheckIfSupported()
+	require.True(t, supported)
+	res := tuner.Tune(context.Background())
+	require.False(t, res.IsFailed())
+}
+
+// ignoringFs discards the writes, as the devices ignoring the scheduler they
+// are given do.
+type ignoringFs struct {
+	afero.Fs
+	discarded afero.Fs
+}
+
+func (fs *ignoringFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		return fs.discarded.OpenFile(name, flag|os.O_CREATE, perm)
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func TestDeviceSchedulerTuner_Tune_ignored(t *testing.T) {
+	mem := afero.NewMemMapFs()
+	deviceFeatures := &deviceFeaturesMock{
+		getSchedulerFeatureFile: func(string) (string, error) {
+			return fScheduler, nil
+		},
+		getScheduler: func(string) (string, error) {
+			return disk.ReadActiveOption(mem, fScheduler)
+		},
+		getSupportedSchedulers: func(string) ([]string, error) {
+			return []string{"none", "mq-deadline"}, nil
+		},
+	}
+	require.NoError(t, afero.WriteFile(mem, fScheduler, []byte("none [mq-deadline]\n"), 0o644))
+	fs := &ignoringFs{Fs: mem, discarded: afero.NewMemMapFs()}
+
+	res := NewDeviceSchedulerTuner(fs, "nvme0n1", "", deviceFeatures, executors.NewDirectExecutor()).
+		Tune(context.Background())
+	require.True(t, res.IsFailed())
+	require.EqualError(t, res.Error(), "'nvme0n1' ignored the 'none' scheduler: active scheduler was 'mq-deadline' before setting it and is 'mq-deadline' after")
+	setValue, err := afero.ReadFile(mem, fScheduler)
+	require.NoError(t, err)
+	require.Equal(t, "none [mq-deadline]\n", string(setValue))
+
+	// Dry runs don't write anything, which isn't verified.
+	res = NewDeviceSchedulerTuner(fs, "nvme0n1", "", deviceFeatures, executors.NewDryRunExecutor()).
+		Tune(context.Background())
+	require.False(t, res.IsFailed())
+}
+
+func TestRecommendScheduler(t *testing.T) {
+	tests := []struct {
+		name       string
+		device     string
+		rotational bool
+		p99        time.Duration
+		want       SchedulerRecommendation
+	}{
+		{
+			name:   "shall recommend mq-deadline for fast non rotational devices",
+			device: "sda",
+			p99:    200 * time.Microsecond,
+			want:   SchedulerRecommendation{"sda", "non-rotational", "none", "mq-deadline"},
+		},
+		{
+			name:   "shall recommend none for fast NVMe devices",
+			device: "nvme0n1",
+			p99:    100 * time.Microsecond,
+			want:   SchedulerRecommendation{"nvme0n1", "NVMe", "none", "none"},
+		},
+		{
+			name:   "shall fall back to none for fast non rotational devices",
+			device: "sdc",
+			p99:    200 * time.Microsecond,
+			want:   SchedulerRecommendation{"sdc", "non-rotational", "none", "none"},
+		},
+		{
+			name:   "shall schedule slow non rotational devices like rotational ones",
+			device: "sdc",
+			p99:    20 * time.Millisecond,
+			want:   SchedulerRecommendation{"sdc", "slow non-rotational", "none", "bfq"},
+		},
+		{
+			name:       "shall recommend bfq for rotational devices",
+			device:     "sdb",
+			rotational: true,
+			p99:        20 * time.Millisecond,
+			want:       SchedulerRecommendation{"sdb", "rotational", "none", "bfq"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceFeatures := &deviceFeaturesMock{
+				getSchedulerFeatureFile: func(string) (string, error) {
+					return fScheduler, nil
+				},
+				getScheduler: func(string) (string, error) {
+					return "none", nil
+				},
+				getSupportedSchedulers: func(device string) ([]string, error) {
+					if device == "sda" {
+						return []string{"mq-deadline", "bfq", "none"}, nil
+					}
+					return []string{"bfq", "none"}, nil
+				},
+				getRotational: func(string) (bool, error) {
+					return tt.rotational, nil
+				},
+			}
+			got, err := RecommendScheduler(tt.device, deviceFeatures, &disk.WriteLatency{P99: tt.p99})
+			require.NoError(t, err)
+			require.Equal(t, &tt.want, got)
+		})
+	}
+}
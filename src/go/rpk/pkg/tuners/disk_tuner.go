@@ -10,26 +10,71 @@
 package tuners
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
+// NewDiskTuner returns a tuner running the tuner created by the factory for
+// each of the devices, and of the devices holding the directories. Devices
+// are tuned concurrently, by up to GOMAXPROCS goroutines, unless the
+// executor is lazy: scripts and dry runs list the commands in device order.
 func NewDiskTuner(
 	fs afero.Fs,
 	directories []string,
 	devices []string,
 	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+	deviceTunerFactory func(string) Tunable,
+) Tunable {
+	return newDiskTuner(fs, directories, devices, blockDevices, executor, deviceTunerFactory, nil, nil)
+}
+
+// newDiskTuner returns a disk tuner skipping the devices lacking the
+// capability the tunables of the devices require, if any. If featureFile is
+// set, the tuner is a FileTunable writing to the attribute it returns for
+// each device.
+func newDiskTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
 	deviceTunerFactory func(string) Tunable,
+	featureFile func(device string) (string, error),
+	requires *deviceCapability,
 ) Tunable {
-	return &diskTuner{
+	concurrency := runtime.GOMAXPROCS(0)
+	if executor != nil && executor.IsLazy() {
+		concurrency = 1
+	}
+	tuner := &diskTuner{
 		fs:                 fs,
 		directories:        directories,
 		devices:            devices,
 		blockDevices:       blockDevices,
 		deviceTunerFactory: deviceTunerFactory,
+		concurrency:        concurrency,
+		requires:           requires,
+	}
+	if featureFile != nil {
+		return &fileDiskTuner{diskTuner: tuner, featureFile: featureFile}
 	}
+	return tuner
 }
 
 type diskTuner struct {
@@ -38,14 +83,264 @@ type diskTuner struct {
 	blockDevices       disk.BlockDevices
 	directories        []string
 	devices            []string
+	// concurrency bounds the number of devices tuned concurrently.
+	concurrency int
+	requires    *deviceCapability
 }
 
-func (tuner *diskTuner) Tune() TuneResult {
-	tunables, err := tuner.createDeviceTuners()
-	if err != nil {
+// fileDiskTuner is a disk tuner writing to a single attribute of each device,
+// e.g. its 'queue/scheduler'.
+type fileDiskTuner struct {
+	*diskTuner
+	featureFile func(device string) (string, error)
+}
+
+func (tuner *fileDiskTuner) TunableFiles(device string) ([]string, error) {
+	if device == "" {
+		return nil, nil
+	}
+	if !hasCapability(tuner.blockDevices, device, tuner.requires) {
+		return nil, nil
+	}
+	file, err := tuner.featureFile(device)
+	if err != nil || file == "" {
+		return nil, err
+	}
+	return []string{file}, nil
+}
+
+// Tune tunes every device, even if some of them fail or panic, or the devices
+// of some directories can't be resolved: the errors are returned together as
+// TuneErrors, the resolution ones first and then the ones of the devices in
+// device order. The progress is reported to the Reporter of ctx, if any, see
+// WithReporter.
+func (tuner *diskTuner) Tune(ctx context.Context) TuneResult {
+	devices, err := tuner.Devices()
+	if err != nil && len(devices) == 0 {
+		if res, ok := sysfsUnavailable(err); ok {
+			return res
+		}
 		return NewTuneError(err)
 	}
-	return NewAggregatedTunable(tunables).Tune()
+	errs := (*TuneErrors)(nil).Append(err)
+	reportResolved(ctx, devices)
+	tunables := tuner.createDeviceTuners(devices)
+	workers := tuner.concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(tunables) {
+		workers = len(tunables)
+	}
+	results := make([]TuneResult, len(tunables))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = reportTune(ctx, devices[i], func() TuneResult {
+					return tuneRecovered(ctx, devices[i], tunables[i])
+				})
+			}
+		}()
+	}
+	for i := range tunables {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var (
+		rebootRequired bool
+		notApplied     string
+	)
+	for _, result := range results {
+		if result.IsFailed() {
+			errs = errs.Append(result.Error())
+			continue
+		}
+		rebootRequired = rebootRequired || result.IsRebootRequired()
+		if notApplied == "" {
+			notApplied = result.NotAppliedReason()
+		}
+	}
+	diskResult := &DiskTuneResult{
+		TuneResult: &tuneResult{rebootRequired: rebootRequired, notApplied: notApplied},
+		Devices:    tuner.deviceResults(tunables, results),
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		diskResult.TuneResult = NewTuneError(err)
+	}
+	return diskResult
+}
+
+// tuneRecovered tunes the device, turning a panic of its tunable into the
+// failure of the device, so that it doesn't take the other devices down.
+func tuneRecovered(ctx context.Context, device string, tunable Tunable) (res TuneResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Debugf("Tuning of '%s' panicked: %v\n%s", device, r, debug.Stack())
+			res = NewTuneError(fmt.Errorf("tuning of '%s' panicked: %v", device, r))
+		}
+	}()
+	return tunable.Tune(ctx)
+}
+
+// DiskTuneResult is the result of a disk tuner, listing the result of each
+// of its devices.
+type DiskTuneResult struct {
+	TuneResult
+	Devices []DeviceTuneResult
+}
+
+func (result *DiskTuneResult) DeviceResults() []DeviceTuneResult {
+	return result.Devices
+}
+
+// checkedValuer is implemented by the tunables reporting the values they
+// checked, see checkedTunable.
+type checkedValuer interface {
+	checkedValues() (current, required string, tuned bool)
+}
+
+// deviceResults returns the result of tuning each device from the results of
+// their tunables, which are in the same order.
+func (tuner *diskTuner) deviceResults(
+	tunables []Tunable, results []TuneResult,
+) []DeviceTuneResult {
+	deviceResults := make([]DeviceTuneResult, 0, len(tunables))
+	for i, tunable := range tunables {
+		device := tunable.(*deviceTunable)
+		res := DeviceTuneResult{Device: device.device, Status: DeviceApplied}
+		if syspath, err := tuner.blockDevices.GetDeviceSystemPath(
+			filepath.Join("/dev", device.device)); err == nil {
+			res.Syspath = syspath
+		}
+		tuned := true
+		if valuer, ok := device.Tunable.(checkedValuer); ok {
+			current, required, ran := valuer.checkedValues()
+			res.Previous, res.New, tuned = current, required, ran
+		}
+		// The values set are more precise than the required one, e.g.
+		// '>= hardware queue depth'.
+		if r, ok := results[i].(*tuneResult); ok && r.value != "" {
+			res.Previous, res.New = r.previous, r.value
+		}
+		switch result := results[i]; {
+		case result.IsFailed():
+			res.Status, res.New, res.Error = DeviceFailed, "", result.Error().Error()
+		case result.NotAppliedReason() != "":
+			res.Status, res.New, res.Reason = DeviceSkipped, "", result.NotAppliedReason()
+		case !tuned:
+			res.Status, res.New, res.Reason = DeviceSkipped, "", "already tuned"
+		}
+		deviceResults = append(deviceResults, res)
+	}
+	return deviceResults
+}
+
+// pseudoDevice returns the result of the devices the disk tuners don't apply
+// to, loop and RAM devices, e.g. holding the data directory in CI, and
+// whether the device is one of them, as told by its capabilities. Devices
+// whose capabilities are unknown are tuned.
+func pseudoDevice(blockDevices disk.BlockDevices, device string) (TuneResult, bool) {
+	blockDevice, err := blockDevices.GetDeviceFromPath(filepath.Join("/dev", device))
+	if err != nil {
+		log.Debugf("Unable to read the capabilities of '%s': %v", device, err)
+		return nil, false
+	}
+	if !blockDevice.Capabilities().Pseudo {
+		return nil, false
+	}
+	class := blockDevice.Class()
+	log.Infof("Skipping '%s' as it's a %s device", device, class)
+	return NewTuneNotApplied(fmt.Sprintf("tuning not applicable to %s device", class)), true
+}
+
+// deviceCapability is a capability of block devices a disk tuner requires,
+// and the reason the devices lacking it are skipped.
+type deviceCapability struct {
+	has    func(disk.DeviceCapabilities) bool
+	reason string
+}
+
+var (
+	tunableSchedulerCapability = &deviceCapability{
+		has:    func(caps disk.DeviceCapabilities) bool { return caps.TunableScheduler },
+		reason: "no I/O scheduler to pick, e.g. only 'none'",
+	}
+	tunableReadAheadCapability = &deviceCapability{
+		has:    func(caps disk.DeviceCapabilities) bool { return caps.TunableReadAhead },
+		reason: "read-ahead not exposed",
+	}
+)
+
+// lackingCapability returns the result of the devices lacking the capability,
+// and whether the device lacks it, see hasCapability.
+func lackingCapability(
+	blockDevices disk.BlockDevices, device string, capability *deviceCapability,
+) (TuneResult, bool) {
+	if hasCapability(blockDevices, device, capability) {
+		return nil, false
+	}
+	log.Infof("Skipping '%s': %s", device, capability.reason)
+	return NewTuneNotApplied(capability.reason), true
+}
+
+// hasCapability returns whether the device has the capability, if any, as
+// told by its capabilities. Devices whose capabilities are unknown are
+// assumed to have it, and are tuned.
+func hasCapability(
+	blockDevices disk.BlockDevices, device string, capability *deviceCapability,
+) bool {
+	if capability == nil {
+		return true
+	}
+	blockDevice, err := blockDevices.GetDeviceFromPath(filepath.Join("/dev", device))
+	if err != nil {
+		log.Debugf("Unable to read the capabilities of '%s': %v", device, err)
+		return true
+	}
+	return capability.has(blockDevice.Capabilities())
+}
+
+// applyIfChanged writes desired to the sysfs attribute at path through the
+// executor, unless the attribute already holds it, and returns whether it was
+// written. Rewriting an attribute with its value is noisy in audit logs and
+// some drivers briefly stall I/O on every write, e.g. to the scheduler.
+func applyIfChanged(
+	fs afero.Fs, executor executors.Executor, path, desired string,
+) (bool, error) {
+	cmd := commands.NewWriteFileCmd(fs, path, desired)
+	change, err := cmd.(commands.ChangeReporter).Change()
+	if err != nil {
+		return false, err
+	}
+	if sameSysfsValue(change.Current, desired) {
+		log.Debugf("'%s' is already set to '%s'", path, change.Current)
+		return false, nil
+	}
+	return true, executor.Execute(cmd)
+}
+
+// sameSysfsValue returns whether the value read from a sysfs attribute, with
+// the active option of multiple choice ones like the scheduler already
+// picked, is the desired one. Whitespace is normalized and numbers compared
+// by value, e.g. '0128\n' is 128.
+func sameSysfsValue(current, desired string) bool {
+	current = strings.Join(strings.Fields(current), " ")
+	desired = strings.Join(strings.Fields(desired), " ")
+	if current == desired {
+		return true
+	}
+	currentNumber, err := strconv.ParseInt(current, 10, 64)
+	if err != nil {
+		return false
+	}
+	desiredNumber, err := strconv.ParseInt(desired, 10, 64)
+	return err == nil && currentNumber == desiredNumber
 }
 
 func (tuner *diskTuner) CheckIfSupported() (supported bool, reason string) {
@@ -53,18 +348,49 @@ func (tuner *diskTuner) CheckIfSupported() (supported bool, reason string) {
 		return false,
 			"Either direcories or devices must be provided for disk tuner"
 	}
-	tunables, err := tuner.createDeviceTuners()
-	if err != nil {
+	devices, err := tuner.Devices()
+	if err != nil && len(devices) == 0 {
+		if errors.Is(err, disk.ErrSysfsUnavailable) {
+			// Tune skips tuning, without failing the other tuners.
+			return true, ""
+		}
+		if errors.Is(err, disk.ErrUnsupportedPlatform) {
+			log.Infof("Skipping disk tuning: %v", err)
+		}
 		return false, err.Error()
 	}
-	return NewAggregatedTunable(tunables).CheckIfSupported()
+	return NewAggregatedTunable(tuner.createDeviceTuners(devices)).CheckIfSupported()
+}
+
+// sysfsUnavailable returns the result of the disk tuners whose devices can't
+// be resolved as sysfs is not available, e.g. in containers with a
+// stripped-down kernel: they skip tuning instead of failing.
+func sysfsUnavailable(err error) (TuneResult, bool) {
+	if !errors.Is(err, disk.ErrSysfsUnavailable) {
+		return nil, false
+	}
+	log.Infof("Skipping disk tuning: %v", err)
+	return NewTuneNotApplied("sysfs not available, skipping disk tuning"), true
 }
 
-func (tuner *diskTuner) createDeviceTuners() ([]Tunable, error) {
+// Devices returns the devices of the tuner, sorted. If the devices of some
+// directories can't be resolved, the devices of the others are returned along
+// with TuneErrors.
+func (tuner *diskTuner) Devices() ([]string, error) {
+	var errs *TuneErrors
 	directoryDevices, err := tuner.blockDevices.GetDirectoriesDevices(
 		tuner.directories)
-	if err != nil {
-		return nil, err
+	if err != nil && len(tuner.directories) > 1 {
+		// Resolve the directories one by one to tell the failing ones.
+		directoryDevices = map[string][]string{}
+		for _, directory := range tuner.directories {
+			devices, err := tuner.blockDevices.GetDirectoriesDevices(
+				[]string{directory})
+			errs = errs.Append(err)
+			directoryDevices[directory] = devices[directory]
+		}
+	} else {
+		errs = errs.Append(err)
 	}
 	disksSetMap := map[string]bool{}
 	for _, devices := range directoryDevices {
@@ -76,10 +402,47 @@ func (tuner *diskTuner) createDeviceTuners() ([]Tunable, error) {
 		disksSetMap[device] = true
 	}
 	devices := utils.GetKeys(disksSetMap)
+	sort.Strings(devices)
+	return devices, errs.ErrorOrNil()
+}
+
+func (tuner *diskTuner) createDeviceTuners(devices []string) []Tunable {
 	var tuners []Tunable
 	for _, device := range devices {
 		log.Debugf("Creating disk tuner for '%s'", device)
-		tuners = append(tuners, tuner.deviceTunerFactory(device))
+		tuners = append(tuners, &deviceTunable{
+			Tunable:      tuner.deviceTunerFactory(device),
+			device:       device,
+			blockDevices: tuner.blockDevices,
+			requires:     tuner.requires,
+		})
+	}
+	return tuners
+}
+
+// deviceTunable is the tunable of a single device, it reports the device
+// its tuning was cancelled on and skips the pseudo devices, and those lacking
+// the capability the tunable requires.
+type deviceTunable struct {
+	Tunable
+	device       string
+	blockDevices disk.BlockDevices
+	requires     *deviceCapability
+}
+
+func (t *deviceTunable) Tune(ctx context.Context) TuneResult {
+	if err := ctx.Err(); err != nil {
+		return NewTuneError(fmt.Errorf("tuning of '%s' cancelled: %w", t.device, err))
+	}
+	if res, ok := pseudoDevice(t.blockDevices, t.device); ok {
+		return res
+	}
+	if res, ok := lackingCapability(t.blockDevices, t.device, t.requires); ok {
+		return res
+	}
+	result := t.Tunable.Tune(ctx)
+	if err := ctx.Err(); err != nil && result.IsFailed() && errors.Is(result.Error(), err) {
+		return NewTuneError(fmt.Errorf("tuning of '%s' cancelled: %w", t.device, err))
 	}
-	return tuners, nil
+	return result
 }
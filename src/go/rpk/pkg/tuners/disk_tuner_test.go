@@ -0,0 +1,566 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceTunable_Devices(t *testing.T) {
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{
+				"/var/lib/redpanda/data": {"sdb", "sda"},
+				"/var/lib/redpanda/wal":  {"nvme0n1", "sda"},
+			}, nil
+		},
+		getDirectoryStacks: func(directory string) ([][]string, error) {
+			if directory == "/var/lib/redpanda/data" {
+				return [][]string{{"md0", "sda", "sdb"}}, nil
+			}
+			return [][]string{{"nvme0n1"}}, nil
+		},
+	}
+	directories := []string{"/var/lib/redpanda/data", "/var/lib/redpanda/wal"}
+	tests := []struct {
+		name  string
+		tuner Tunable
+		want  []string
+	}{
+		{
+			name: "shall return the sorted devices of disk tuners",
+			tuner: NewDiskTuner(afero.NewMemMapFs(), directories, []string{"sdc"}, blockDevices, nil,
+				func(string) Tunable { return nil }),
+			want: []string{"nvme0n1", "sda", "sdb", "sdc"},
+		},
+		{
+			name: "shall return the device stacks of the read-ahead tuner",
+			tuner: &readAheadTuner{
+				directories:  directories,
+				devices:      []string{"sda"},
+				blockDevices: blockDevices,
+			},
+			want: []string{"md0", "sda", "sdb", "nvme0n1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceTuner, ok := tt.tuner.(DeviceTunable)
+			require.True(t, ok)
+			devices, err := deviceTuner.Devices()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, devices)
+		})
+	}
+}
+
+func TestDiskTuner_Tune_cancelled(t *testing.T) {
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{"/var/lib/redpanda/data": {"sda", "sdb"}}, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var tuned []string
+	// Dry runs tune the devices one after the other.
+	tuner := NewDiskTuner(afero.NewMemMapFs(), []string{"/var/lib/redpanda/data"}, nil, blockDevices,
+		executors.NewDryRunExecutor(), func(device string) Tunable {
+			return &mockedTunable{tune: func() TuneResult {
+				tuned = append(tuned, device)
+				// The user interrupts the tuning of the first device.
+				cancel()
+				return NewTuneResult(false)
+			}}
+		})
+
+	result := tuner.Tune(ctx)
+	require.True(t, result.IsFailed())
+	require.True(t, errors.Is(result.Error(), context.Canceled))
+	require.Contains(t, result.Error().Error(), "'sdb'")
+	require.Equal(t, []string{"sda"}, tuned)
+}
+
+func TestDiskTuner_Tune_concurrently(t *testing.T) {
+	var devices []string
+	for i := 0; i < 12; i++ {
+		devices = append(devices, fmt.Sprintf("nvme%dn1", i))
+	}
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{}, nil
+		},
+	}
+	var inFlight, maxInFlight, tuned int32
+	tuner := NewDiskTuner(afero.NewMemMapFs(), nil, devices, blockDevices, executors.NewDirectExecutor(),
+		func(device string) Tunable {
+			return &mockedTunable{tune: func() TuneResult {
+				n := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				for {
+					max := atomic.LoadInt32(&maxInFlight)
+					if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+						break
+					}
+				}
+				atomic.AddInt32(&tuned, 1)
+				// A slow device, e.g. one whose sysfs writes take a while.
+				time.Sleep(20 * time.Millisecond)
+				switch device {
+				case "nvme3n1", "nvme10n1":
+					return NewTuneError(fmt.Errorf("unable to tune '%s'", device))
+				case "nvme5n1":
+					return NewTuneNotApplied("not applied, permission denied")
+				}
+				return NewTuneResult(false)
+			}}
+		})
+	tuner.(*diskTuner).concurrency = 4
+
+	result := tuner.Tune(context.Background())
+	// A failing device doesn't stop the others from being tuned.
+	require.Equal(t, int32(len(devices)), atomic.LoadInt32(&tuned))
+	require.True(t, result.IsFailed())
+	// The devices are sorted by name, their errors are reported in order.
+	require.EqualError(t, result.Error(),
+		"2 devices failed: unable to tune 'nvme10n1'; unable to tune 'nvme3n1'")
+	require.Greater(t, atomic.LoadInt32(&maxInFlight), int32(1))
+	require.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(4))
+}
+
+func TestDiskTuner_Tune_deviceResults(t *testing.T) {
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{}, nil
+		},
+		getBlockDeviceSystemPath: func(path string) (string, error) {
+			if path == "/dev/sdd" {
+				return "", errors.New("no such device")
+			}
+			return "/sys/devices/virtual/block/" + filepath.Base(path), nil
+		},
+	}
+	tuner := NewDiskTuner(afero.NewMemMapFs(), nil, []string{"sda", "sdb", "sdc", "sdd"}, blockDevices,
+		executors.NewDirectExecutor(), func(device string) Tunable {
+			c := &checkedTunerMock{
+				supported: func() (bool, string) { return true, "" },
+				check: func() *CheckResult {
+					return &CheckResult{IsOk: device == "sda", Current: "c"}
+				},
+				tune: func() TuneResult {
+					switch device {
+					case "sdc":
+						return NewTuneError(errors.New("unable to tune 'sdc'"))
+					case "sdd":
+						return NewTuneNotApplied("not applied, permission denied")
+					}
+					return NewTuneResult(false)
+				},
+				severity: Warning,
+			}
+			return NewCheckedTunable(c, c.Tune, c.CheckIfSupported, true)
+		})
+
+	result := tuner.Tune(context.Background())
+	require.True(t, result.IsFailed())
+	deviceResults, ok := result.(DeviceTuneResults)
+	require.True(t, ok)
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "sda", Syspath: "/sys/devices/virtual/block/sda", Status: DeviceSkipped, Previous: "c", Reason: "already tuned"},
+		{Device: "sdb", Syspath: "/sys/devices/virtual/block/sdb", Status: DeviceApplied, Previous: "c", New: "r"},
+		{Device: "sdc", Syspath: "/sys/devices/virtual/block/sdc", Status: DeviceFailed, Previous: "c", Error: "unable to tune 'sdc'"},
+		{Device: "sdd", Status: DeviceSkipped, Previous: "c", Reason: "not applied, permission denied"},
+	}, deviceResults.DeviceResults())
+}
+
+func TestDiskTuner_Tune_partialFailures(t *testing.T) {
+	errUnreadable := errors.New("unreadable nomerges")
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func(directories []string) (map[string][]string, error) {
+			devices := map[string][]string{}
+			for _, directory := range directories {
+				if directory == "/mnt/gone" {
+					return nil, fmt.Errorf("unable to resolve '%s': %w", directory, disk.ErrDeviceNotFound)
+				}
+				devices[directory] = []string{"nvme0n1", "nvme1n1"}
+			}
+			return devices, nil
+		},
+		getBlockDeviceSystemPath: func(string) (string, error) {
+			return "", errors.New("no such device")
+		},
+	}
+	tuner := NewDiskTuner(afero.NewMemMapFs(), []string{"/var/lib/redpanda/data", "/mnt/gone"},
+		[]string{"sda", "sdb"}, blockDevices, executors.NewDirectExecutor(), func(device string) Tunable {
+			return &mockedTunable{checkIfSupported: func() (bool, string) { return true, "" }, tune: func() TuneResult {
+				switch device {
+				case "nvme1n1":
+					return NewTuneError(fmt.Errorf("unable to tune '%s': %w", device, errUnreadable))
+				case "sdb":
+					var devices map[string]int
+					devices[device]++
+				}
+				return NewTuneResult(false)
+			}}
+		})
+
+	devices, err := tuner.(DeviceTunable).Devices()
+	require.ErrorIs(t, err, disk.ErrDeviceNotFound)
+	require.Equal(t, []string{"nvme0n1", "nvme1n1", "sda", "sdb"}, devices)
+	supported, _ := tuner.CheckIfSupported()
+	require.True(t, supported)
+
+	result := tuner.Tune(context.Background())
+	require.True(t, result.IsFailed())
+	var errs *TuneErrors
+	require.ErrorAs(t, result.Error(), &errs)
+	require.Len(t, errs.Errs, 3)
+	require.ErrorIs(t, result.Error(), disk.ErrDeviceNotFound)
+	require.ErrorIs(t, result.Error(), errUnreadable)
+	require.Contains(t, result.Error().Error(), "tuning of 'sdb' panicked: assignment to entry in nil map")
+	deviceResults := result.(DeviceTuneResults).DeviceResults()
+	statuses := map[string]string{}
+	for _, res := range deviceResults {
+		statuses[res.Device] = res.Status
+	}
+	require.Equal(t, map[string]string{
+		"nvme0n1": DeviceApplied,
+		"nvme1n1": DeviceFailed,
+		"sda":     DeviceApplied,
+		"sdb":     DeviceFailed,
+	}, statuses)
+}
+
+func TestDiskTuners_sysfsUnavailable(t *testing.T) {
+	err := fmt.Errorf("unable to resolve block device {8, 0}: %w", disk.ErrSysfsUnavailable)
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return nil, err
+		},
+		getDirectoryStacks: func(string) ([][]string, error) {
+			return nil, err
+		},
+	}
+	directories := []string{"/var/lib/redpanda/data"}
+	tests := []struct {
+		name  string
+		tuner Tunable
+	}{
+		{
+			name: "disk tuner",
+			tuner: NewDiskTuner(afero.NewMemMapFs(), directories, nil, blockDevices, nil,
+				func(string) Tunable { return nil }),
+		},
+		{
+			name:  "read-ahead tuner",
+			tuner: &readAheadTuner{directories: directories, blockDevices: blockDevices},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The tuner is skipped rather than failing the whole run.
+			supported, reason := tt.tuner.CheckIfSupported()
+			require.True(t, supported)
+			require.Empty(t, reason)
+			result := tt.tuner.Tune(context.Background())
+			require.False(t, result.IsFailed())
+			require.Equal(t, "sysfs not available, skipping disk tuning", result.NotAppliedReason())
+		})
+	}
+}
+
+func TestDiskTuner_Tune_dryRun_readOnly(t *testing.T) {
+	const featureFile = "/sys/block/nvme0n1/queue/nomerges"
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, featureFile, []byte("0"), 0o644)
+	// Any write to a read-only sysfs fails.
+	fs := afero.NewReadOnlyFs(memFs)
+	deviceFeatures := &deviceFeaturesMock{
+		getNomergesFeatureFile: func(string) (string, error) {
+			return featureFile, nil
+		},
+		getNomerges: func(string) (int, error) {
+			value, err := afero.ReadFile(fs, featureFile)
+			if err != nil {
+				return 0, err
+			}
+			return strconv.Atoi(string(value))
+		},
+		isNvme: func(string) (bool, error) {
+			return true, nil
+		},
+	}
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{}, nil
+		},
+	}
+	newTuner := func(executor executors.Executor) Tunable {
+		return NewDiskTuner(fs, nil, []string{"nvme0n1"}, blockDevices, executor,
+			func(device string) Tunable {
+				return NewDeviceNomergesTuner(fs, device, deviceFeatures, executor)
+			})
+	}
+
+	// The dry run reads the current value and reports the change it would
+	// make, without writing.
+	recorder := executors.NewDryRunExecutor()
+	result := newTuner(recorder).Tune(context.Background())
+	require.NoError(t, result.Error())
+	require.Empty(t, result.NotAppliedReason())
+	require.Equal(t, []commands.Change{{Path: featureFile, Current: "0", Proposed: "2"}}, recorder.Changes())
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "nvme0n1", Status: DeviceApplied, Previous: "0", New: "2 on non-rotational devices"},
+	}, result.(DeviceTuneResults).DeviceResults())
+
+	// While the real run can't apply it.
+	result = newTuner(executors.NewDirectExecutor()).Tune(context.Background())
+	require.NoError(t, result.Error())
+	require.Equal(t, "not applied, permission denied", result.NotAppliedReason())
+	value, err := afero.ReadFile(memFs, featureFile)
+	require.NoError(t, err)
+	require.Equal(t, "0", string(value))
+}
+
+func TestApplyIfChanged(t *testing.T) {
+	const featureFile = "/sys/block/sda/queue/attribute"
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		written bool
+	}{
+		{
+			name:    "shall not write the value in place",
+			current: "2\n",
+			desired: "2",
+		},
+		{
+			name:    "shall compare numbers by value",
+			current: "0128\n",
+			desired: "128",
+		},
+		{
+			name:    "shall compare the active option",
+			current: "mq-deadline [none] kyber\n",
+			desired: "none",
+		},
+		{
+			name:    "shall normalize whitespace",
+			current: "write  through\n",
+			desired: "write through",
+		},
+		{
+			name:    "shall write a different value",
+			current: "[mq-deadline] none\n",
+			desired: "none",
+			written: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fs afero.Fs = afero.NewMemMapFs()
+			afero.WriteFile(fs, featureFile, []byte(tt.current), 0o644)
+			if !tt.written {
+				// Any write would fail.
+				fs = afero.NewReadOnlyFs(fs)
+			}
+			executor := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+			written, err := applyIfChanged(fs, executor, featureFile, tt.desired)
+			require.NoError(t, err)
+			require.Equal(t, tt.written, written)
+			content, err := afero.ReadFile(fs, featureFile)
+			require.NoError(t, err)
+			if tt.written {
+				require.Equal(t, tt.desired, string(content))
+				require.Len(t, executor.Changes(), 1)
+				return
+			}
+			require.Equal(t, tt.current, string(content))
+			require.Empty(t, executor.Changes())
+		})
+	}
+}
+
+func TestDeviceNomergesTuner_Tune_unchanged(t *testing.T) {
+	const featureFile = "/sys/block/nvme0n1/queue/nomerges"
+	var fs afero.Fs = afero.NewMemMapFs()
+	afero.WriteFile(fs, featureFile, []byte("02\n"), 0o644)
+	fs = afero.NewReadOnlyFs(fs)
+	deviceFeatures := &deviceFeaturesMock{
+		getNomergesFeatureFile: func(string) (string, error) {
+			return featureFile, nil
+		},
+		getNomerges: func(string) (int, error) {
+			return 0, nil
+		},
+		isNvme: func(string) (bool, error) {
+			return true, nil
+		},
+	}
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{}, nil
+		},
+	}
+	// The stale check sees 0, but the file already holds 2.
+	res := NewDiskTuner(fs, nil, []string{"nvme0n1"}, blockDevices, executors.NewDirectExecutor(),
+		func(device string) Tunable {
+			return NewDeviceNomergesTuner(fs, device, deviceFeatures, executors.NewDirectExecutor())
+		},
+	).Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Empty(t, res.NotAppliedReason())
+	require.Equal(t, []DeviceTuneResult{{
+		Device:   "nvme0n1",
+		Status:   DeviceSkipped,
+		Previous: "0",
+		Reason:   "already tuned",
+	}}, res.(DeviceTuneResults).DeviceResults())
+}
+
+type blockDeviceMock struct {
+	disk.BlockDevice
+	syspath    string
+	devnode    string
+	nvme       *disk.NvmeNamespace
+	class      disk.DeviceClass
+	alignment  *disk.PartitionAlignment
+	writeCache string
+	thin       *disk.ThinVolume
+	transport  disk.TransportType
+	caps       disk.DeviceCapabilities
+	rotational bool
+}
+
+func (m *blockDeviceMock) Syspath() string {
+	return m.syspath
+}
+
+func (m *blockDeviceMock) Devnode() string {
+	return m.devnode
+}
+
+func (m *blockDeviceMock) Nvme() *disk.NvmeNamespace {
+	return m.nvme
+}
+
+func (m *blockDeviceMock) Class() disk.DeviceClass {
+	return m.class
+}
+
+func (m *blockDeviceMock) Capabilities() disk.DeviceCapabilities {
+	caps := m.caps
+	caps.Pseudo = m.class.IsPseudo()
+	return caps
+}
+
+func (m *blockDeviceMock) IsRotational() (bool, error) {
+	return m.rotational, nil
+}
+
+func (*blockDeviceMock) Md() *disk.MdArray {
+	return nil
+}
+
+func (m *blockDeviceMock) PartitionAlignment() (*disk.PartitionAlignment, error) {
+	return m.alignment, nil
+}
+
+func (m *blockDeviceMock) WriteCache() (string, error) {
+	return m.writeCache, nil
+}
+
+func (m *blockDeviceMock) Thin() *disk.ThinVolume {
+	return m.thin
+}
+
+func (m *blockDeviceMock) Transport(context.Context) (disk.TransportType, error) {
+	return m.transport, nil
+}
+
+func TestDiskTuners_pseudoDevices(t *testing.T) {
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{}, nil
+		},
+		getBlockDeviceFromPath: func(path string) (disk.BlockDevice, error) {
+			switch path {
+			case "/dev/loop0":
+				return &blockDeviceMock{class: disk.DeviceClassLoop}, nil
+			case "/dev/zram0":
+				return &blockDeviceMock{class: disk.DeviceClassRAM}, nil
+			}
+			return nil, fmt.Errorf("unexpected device '%s'", path)
+		},
+	}
+	failing := &deviceFeaturesMock{
+		getNomergesFeatureFile: func(device string) (string, error) {
+			return "", fmt.Errorf("'%s' shall not be tuned", device)
+		},
+	}
+	fs := afero.NewMemMapFs()
+	executor := executors.NewDirectExecutor()
+	res := NewDiskTuner(fs, nil, []string{"loop0", "zram0"}, blockDevices, executor,
+		func(device string) Tunable {
+			return &checkedTunable{
+				checker:    NewDeviceNomergesChecker(device, failing),
+				tuneAction: func() TuneResult { return NewTuneError(errors.New("tuned")) },
+			}
+		},
+	).Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, "tuning not applicable to loop device", res.NotAppliedReason())
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "loop0", Status: DeviceSkipped, Reason: "tuning not applicable to loop device"},
+		{Device: "zram0", Status: DeviceSkipped, Reason: "tuning not applicable to ram device"},
+	}, res.(DeviceTuneResults).DeviceResults())
+
+	res = NewReadAheadTuner(fs, nil, []string{"loop0"}, blockDevices, executor).Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "loop0", Status: DeviceSkipped, Reason: "tuning not applicable to loop device"},
+	}, res.(DeviceTuneResults).DeviceResults())
+}
+
+func TestDiskTuners_untunableDevices(t *testing.T) {
+	blockDevices := &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{}, nil
+		},
+		getBlockDeviceFromPath: func(path string) (disk.BlockDevice, error) {
+			return &blockDeviceMock{devnode: path, rotational: true}, nil
+		},
+	}
+	fs := afero.NewMemMapFs()
+	executor := executors.NewDirectExecutor()
+	res := NewSchedulerTuner(fs, nil, []string{"xvda"}, nil, blockDevices, executor).Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "xvda", Status: DeviceSkipped, Reason: "no I/O scheduler to pick, e.g. only 'none'"},
+	}, res.(DeviceTuneResults).DeviceResults())
+
+	res = NewReadAheadTuner(fs, nil, []string{"xvda"}, blockDevices, executor).Tune(context.Background())
+	require.NoError(t, res.Error())
+	require.Equal(t, "read-ahead not exposed", res.NotAppliedReason())
+}
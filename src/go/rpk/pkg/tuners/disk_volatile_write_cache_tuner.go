@@ -0,0 +1,96 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+func NewDeviceVolatileWriteCacheTuner(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewDeviceVolatileWriteCacheChecker(device, deviceFeatures),
+		func() TuneResult {
+			return tuneVolatileWriteCache(fs, device, deviceFeatures, executor)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+// tuneVolatileWriteCache disables the volatile write cache of the drive of
+// the device by setting its SCSI cache type to 'write through', which clears
+// its WCE bit. Unlike 'queue/write_cache', which only stops the kernel from
+// flushing the cache, this makes the drive persist each write before
+// acknowledging it. The write goes through the executor, so its previous
+// value is recorded in the snapshot and restored by --revert.
+func tuneVolatileWriteCache(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) TuneResult {
+	featureFile, err := deviceFeatures.GetDriveWriteCacheFeatureFile(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if featureFile == "" {
+		// NVMe drives only expose their volatile write cache through the
+		// admin commands of nvme-cli.
+		log.Warnf("Unable to disable the volatile write cache of '%s' as it's not a SCSI disk", device)
+		return NewTuneNotApplied("not applied, no SCSI cache type")
+	}
+	written, err := applyIfChanged(fs, executor, featureFile, disk.CachePolicyWriteThrough)
+	if isPermissionDenied(err) {
+		log.Infof("Unable to set '%s' cache type: %v", device, err)
+		return NewTuneNotApplied("not applied, permission denied")
+	}
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if !written {
+		return newTuneUnchanged()
+	}
+	log.Infof("Setting '%s' cache type of '%s' to '%s'",
+		featureFile, device, disk.CachePolicyWriteThrough)
+
+	return NewTuneResult(false)
+}
+
+func NewVolatileWriteCacheTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+) Tunable {
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+	return newDiskTuner(
+		fs,
+		directories,
+		devices,
+		blockDevices,
+		executor,
+		func(device string) Tunable {
+			return NewDeviceVolatileWriteCacheTuner(fs, device, deviceFeatures, executor)
+		},
+		deviceFeatures.GetDriveWriteCacheFeatureFile,
+		nil,
+	)
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceVolatileWriteCacheTuner_Tune(t *testing.T) {
+	const featureFile = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/device/scsi_disk/0:0:0:0/cache_type"
+	tests := []struct {
+		name       string
+		cacheType  string
+		queueMode  string
+		noSCSI     bool
+		readOnly   bool
+		want       string
+		wantDesc   string
+		notApplied string
+	}{
+		{
+			name:      "shall disable the volatile write cache of SCSI disks",
+			cacheType: disk.CachePolicyWriteBack,
+			want:      disk.CachePolicyWriteThrough,
+			wantDesc:  disk.CachePolicyWriteBack,
+		},
+		{
+			name:      "shall report the write cache mode of the queue if it differs",
+			cacheType: disk.CachePolicyWriteBack,
+			queueMode: disk.CachePolicyWriteThrough,
+			want:      disk.CachePolicyWriteThrough,
+			wantDesc:  disk.CachePolicyWriteBack + " (queue write_cache " + disk.CachePolicyWriteThrough + ")",
+		},
+		{
+			name:      "shall keep disks with their write cache disabled",
+			cacheType: disk.CachePolicyWriteThrough,
+			want:      disk.CachePolicyWriteThrough,
+			wantDesc:  disk.CachePolicyWriteThrough,
+		},
+		{
+			name:       "shall not apply changes to devices without a SCSI cache type",
+			cacheType:  disk.CachePolicyWriteBack,
+			noSCSI:     true,
+			want:       disk.CachePolicyWriteBack,
+			wantDesc:   disk.CachePolicyWriteBack,
+			notApplied: "not applied, no SCSI cache type",
+		},
+		{
+			name:       "shall not apply changes to a read-only sysfs",
+			cacheType:  disk.CachePolicyWriteBack,
+			readOnly:   true,
+			want:       disk.CachePolicyWriteBack,
+			wantDesc:   disk.CachePolicyWriteBack,
+			notApplied: "not applied, permission denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fs afero.Fs = afero.NewMemMapFs()
+			afero.WriteFile(fs, featureFile, []byte(tt.cacheType), 0o644)
+			if tt.readOnly {
+				fs = afero.NewReadOnlyFs(fs)
+			}
+			deviceFeatures := &deviceFeaturesMock{
+				getDriveWriteCacheFile: func(string) (string, error) {
+					if tt.noSCSI {
+						return "", nil
+					}
+					return featureFile, nil
+				},
+				getWriteCacheFeatureFile: func(string) (string, error) {
+					if tt.queueMode == "" {
+						return "", nil
+					}
+					return "/sys/block/sda/queue/write_cache", nil
+				},
+				getWriteCache: func(string) (string, error) {
+					return tt.queueMode, nil
+				},
+				hasVolatileWriteCache: func(string) (bool, error) {
+					value, err := afero.ReadFile(fs, featureFile)
+					if err != nil {
+						return false, err
+					}
+					return strings.HasPrefix(string(value), disk.CachePolicyWriteBack), nil
+				},
+			}
+
+			result := NewDeviceVolatileWriteCacheChecker("sda", deviceFeatures).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantDesc, result.Current)
+
+			executor := executors.NewDirectExecutor()
+			res := NewDeviceVolatileWriteCacheTuner(fs, "sda", deviceFeatures, executor).Tune(context.Background())
+			require.NoError(t, res.Error())
+			require.Equal(t, tt.notApplied, res.NotAppliedReason())
+			setValue, err := afero.ReadFile(fs, featureFile)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(setValue))
+		})
+	}
+}
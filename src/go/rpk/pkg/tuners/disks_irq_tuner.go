@@ -10,6 +10,12 @@
 package tuners
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
@@ -78,25 +84,87 @@ func (tuner *disksIRQsTuner) CheckIfSupported() (
 	return true, ""
 }
 
-func (tuner *disksIRQsTuner) Tune() TuneResult {
-	directoryDevices, err := tuner.blockDevices.GetDirectoriesDevices(
-		tuner.directories)
+func (tuner *disksIRQsTuner) Tune(ctx context.Context) TuneResult {
+	allDevices, err := tuner.Devices()
 	if err != nil {
+		if errors.Is(err, disk.ErrUnsupportedPlatform) {
+			log.Infof("Skipping disk IRQs tuning: %v", err)
+			return NewTuneResult(false)
+		}
+		if res, ok := sysfsUnavailable(err); ok {
+			return res
+		}
 		return NewTuneError(err)
 	}
-
-	var allDevices []string
-	allDevices = append(allDevices, tuner.devices...)
-	for _, devices := range directoryDevices {
-		allDevices = append(allDevices, devices...)
+	// Loop and RAM devices have no IRQs to distribute.
+	var (
+		devices []string
+		pseudo  TuneResult
+	)
+	for _, device := range allDevices {
+		if res, ok := pseudoDevice(tuner.blockDevices, device); ok {
+			pseudo = res
+			continue
+		}
+		devices = append(devices, device)
+	}
+	if len(devices) == 0 && pseudo != nil {
+		return pseudo
 	}
+	allDevices = devices
 	balanceServiceTuner := NewDiskIRQsBalanceServiceTuner(allDevices, tuner.blockDevices, tuner.irqBalanceService, tuner.executor)
 
-	if result := balanceServiceTuner.Tune(); result.IsFailed() {
+	if result := balanceServiceTuner.Tune(ctx); result.IsFailed() {
 		return result
 	}
 	affinityTuner := NewDiskIRQsAffinityTuner(allDevices, tuner.baseCPUMask, tuner.mode, tuner.blockDevices, tuner.cpuMasks, tuner.executor)
-	return affinityTuner.Tune()
+	result := affinityTuner.Tune(ctx)
+	if result.IsFailed() {
+		return result
+	}
+	_, nodes, err := expectedIRQsDistribution(allDevices, tuner.blockDevices, tuner.mode, tuner.baseCPUMask, tuner.cpuMasks)
+	if err != nil {
+		log.Debugf("Unable to get the NUMA nodes of the devices: %v", err)
+		return result
+	}
+	tuned := true
+	if valuer, ok := affinityTuner.(checkedValuer); ok {
+		_, _, tuned = valuer.checkedValues()
+	}
+	return &DiskIRQsTuneResult{TuneResult: result, Nodes: nodes, tuned: tuned}
+}
+
+func (tuner *disksIRQsTuner) Devices() ([]string, error) {
+	directoryDevices, err := tuner.blockDevices.GetDirectoriesDevices(
+		tuner.directories)
+	if err != nil {
+		return nil, err
+	}
+	var allDevices []string
+	allDevices = append(allDevices, tuner.devices...)
+	for _, directory := range tuner.directories {
+		allDevices = append(allDevices, directoryDevices[directory]...)
+	}
+	return allDevices, nil
+}
+
+// TunableFiles returns the smp_affinity files of the IRQs of the device.
+func (tuner *disksIRQsTuner) TunableFiles(device string) ([]string, error) {
+	if device == "" {
+		return nil, nil
+	}
+	infos, err := tuner.blockDevices.GetDiskInfoByType([]string{device})
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, info := range infos {
+		for _, irq := range info.Irqs {
+			files = append(files, fmt.Sprintf("/proc/irq/%d/smp_affinity", irq))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
 }
 
 func NewDiskIRQsBalanceServiceTuner(
@@ -163,6 +231,54 @@ func NewDiskIRQsAffinityTuner(
 	)
 }
 
+// DeviceNumaNode is the NUMA node the disk IRQs tuner assigned the IRQs of a
+// device to, and the CPUs they were distributed across. Node is -1 if they
+// were distributed across the CPUs of any node: if the node of the device is
+// unknown, or has none of the CPUs to use, or for the devices other than NVMe
+// ones, whose IRQs are pinned to the CPUs of the IRQ mode.
+type DeviceNumaNode struct {
+	Device  string `json:"device"`
+	Node    int    `json:"numa_node"`
+	CPUMask string `json:"cpu_mask"`
+	// IRQs maps the MSI-X IRQs of the queues of NVMe devices to the CPU
+	// mask they were pinned to. The namespaces of a controller share its
+	// IRQs.
+	IRQs map[int]string `json:"irqs,omitempty"`
+}
+
+// DiskIRQsTuneResult is the result of the disk IRQs tuner, listing the NUMA
+// node each device was assigned.
+type DiskIRQsTuneResult struct {
+	TuneResult
+	Nodes []DeviceNumaNode
+	// tuned is whether the IRQs were distributed, rather than already
+	// distributed as expected.
+	tuned bool
+}
+
+func (result *DiskIRQsTuneResult) DeviceResults() []DeviceTuneResult {
+	deviceResults := make([]DeviceTuneResult, 0, len(result.Nodes))
+	for _, node := range result.Nodes {
+		res := DeviceTuneResult{Device: node.Device, Status: DeviceApplied}
+		if node.Node >= 0 {
+			res.New = fmt.Sprintf("numa node %d, cpus %s", node.Node, node.CPUMask)
+		} else {
+			res.New = fmt.Sprintf("any numa node, cpus %s", node.CPUMask)
+		}
+		if len(node.IRQs) > 0 {
+			res.New += fmt.Sprintf(" (%s)", formatIRQMasks(node.IRQs))
+		}
+		switch {
+		case result.NotAppliedReason() != "":
+			res.Status, res.New, res.Reason = DeviceSkipped, "", result.NotAppliedReason()
+		case !result.tuned:
+			res.Status, res.Reason = DeviceSkipped, "already tuned"
+		}
+		deviceResults = append(deviceResults, res)
+	}
+	return deviceResults
+}
+
 func GetExpectedIRQsDistribution(
 	devices []string,
 	blockDevices disk.BlockDevices,
@@ -170,16 +286,36 @@ func GetExpectedIRQsDistribution(
 	cpuMask string,
 	cpuMasks irq.CPUMasks,
 ) (map[int]string, error) {
+	distribution, _, err := expectedIRQsDistribution(devices, blockDevices, mode, cpuMask, cpuMasks)
+	return distribution, err
+}
+
+// expectedIRQsDistribution returns the expected distribution of the IRQs of
+// the devices, along with the NUMA node each device was assigned. The IRQs
+// of the NVMe devices are distributed across the CPUs of their NUMA node, as
+// serving them from the other sockets hurts latency, or across all the CPUs
+// if none of the CPUs of the mask are on their node, or if it's unknown.
+func expectedIRQsDistribution(
+	devices []string,
+	blockDevices disk.BlockDevices,
+	mode irq.Mode,
+	cpuMask string,
+	cpuMasks irq.CPUMasks,
+) (map[int]string, []DeviceNumaNode, error) {
 	log.Debugf("Getting %v IRQs distribution with mode %s and CPU mask %s",
 		devices,
 		mode, cpuMask)
 	finalCPUMask, err := cpuMasks.BaseCPUMask(cpuMask)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	finalCPUMask, err = cpuMasks.CgroupCPUMask(finalCPUMask)
+	if err != nil {
+		return nil, nil, err
 	}
 	diskInfoByType, err := blockDevices.GetDiskInfoByType(devices)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var effectiveMode irq.Mode
@@ -188,7 +324,7 @@ func GetExpectedIRQsDistribution(
 	} else {
 		effectiveMode, err = GetDefaultMode(finalCPUMask, diskInfoByType, cpuMasks)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -196,32 +332,133 @@ func GetExpectedIRQsDistribution(
 	nvmeDisksInfo := diskInfoByType[disk.Nvme]
 	irqCPUMask, err := cpuMasks.CPUMaskForIRQs(effectiveMode, finalCPUMask)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	devicesIRQsDistribution := make(map[int]string)
+	var nodes []DeviceNumaNode
 	if len(nonNvmeDisksInfo.Devices) > 0 {
 		IRQsDist, err := cpuMasks.GetIRQsDistributionMasks(
 			nonNvmeDisksInfo.Irqs, irqCPUMask)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		for IRQ, mask := range IRQsDist {
 			devicesIRQsDistribution[IRQ] = mask
 		}
+		for _, device := range nonNvmeDisksInfo.Devices {
+			nodes = append(nodes, DeviceNumaNode{Device: device, Node: -1, CPUMask: irqCPUMask})
+		}
 	}
 
 	if len(nvmeDisksInfo.Devices) > 0 {
-		IRQsDist, err := cpuMasks.GetIRQsDistributionMasks(
-			nvmeDisksInfo.Irqs, finalCPUMask)
-		if err != nil {
-			return nil, err
-		}
-		for IRQ, mask := range IRQsDist {
-			devicesIRQsDistribution[IRQ] = mask
+		groups := numaNodeGroups(blockDevices, nvmeDisksInfo.Devices)
+		for _, group := range groups {
+			nodeCPUMask := finalCPUMask
+			if group.node >= 0 {
+				restricted, err := cpuMasks.NumaNodeCPUMask(finalCPUMask, group.node)
+				switch {
+				case err != nil:
+					log.Debugf("Unable to get the CPUs of NUMA node %d, using all the CPUs: %v", group.node, err)
+					group.node = -1
+				case restricted == "":
+					log.Infof("CPU mask '%s' has none of the CPUs of NUMA node %d, distributing the IRQs of %v across all its CPUs",
+						finalCPUMask, group.node, group.devices)
+					group.node = -1
+				default:
+					nodeCPUMask = restricted
+				}
+			}
+			irqs := nvmeDisksInfo.Irqs
+			if len(groups) > 1 {
+				nodeDiskInfo, err := blockDevices.GetDiskInfoByType(group.devices)
+				if err != nil {
+					return nil, nil, err
+				}
+				irqs = nodeDiskInfo[disk.Nvme].Irqs
+			}
+			IRQsDist, err := cpuMasks.GetIRQsDistributionMasks(irqs, nodeCPUMask)
+			if err != nil {
+				return nil, nil, err
+			}
+			for IRQ, mask := range IRQsDist {
+				devicesIRQsDistribution[IRQ] = mask
+			}
+			for _, device := range group.devices {
+				deviceIRQs, err := nvmeDeviceIRQMasks(blockDevices, device, IRQsDist)
+				if err != nil {
+					return nil, nil, err
+				}
+				nodes = append(nodes, DeviceNumaNode{Device: device, Node: group.node, CPUMask: nodeCPUMask, IRQs: deviceIRQs})
+			}
 		}
 	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Device < nodes[j].Device })
 	log.Debugf("Calculated IRQs distribution %v", devicesIRQsDistribution)
-	return devicesIRQsDistribution, nil
+	return devicesIRQsDistribution, nodes, nil
+}
+
+// nvmeDeviceIRQMasks returns the CPU mask each IRQ of the queues of the NVMe
+// device is pinned to in the distribution.
+func nvmeDeviceIRQMasks(
+	blockDevices disk.BlockDevices, device string, distribution map[int]string,
+) (map[int]string, error) {
+	info, err := blockDevices.GetDiskInfoByType([]string{device})
+	if err != nil {
+		return nil, err
+	}
+	var masks map[int]string
+	for _, IRQ := range info[disk.Nvme].Irqs {
+		if mask, ok := distribution[IRQ]; ok {
+			if masks == nil {
+				masks = make(map[int]string)
+			}
+			masks[IRQ] = mask
+		}
+	}
+	return masks, nil
+}
+
+// formatIRQMasks formats the IRQs and their CPU masks in IRQ order, e.g.
+// 'irq 10 -> 0x00000001, irq 11 -> 0x00000002'.
+func formatIRQMasks(masks map[int]string) string {
+	IRQs := make([]int, 0, len(masks))
+	for IRQ := range masks {
+		IRQs = append(IRQs, IRQ)
+	}
+	sort.Ints(IRQs)
+	formatted := make([]string, 0, len(IRQs))
+	for _, IRQ := range IRQs {
+		formatted = append(formatted, fmt.Sprintf("irq %d -> %s", IRQ, masks[IRQ]))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// numaNodeGroup are devices on the same NUMA node, -1 if unknown.
+type numaNodeGroup struct {
+	node    int
+	devices []string
+}
+
+// numaNodeGroups groups the devices by NUMA node, in node order. Devices
+// whose node can't be read are considered of an unknown node: the NUMA node
+// only improves the distribution.
+func numaNodeGroups(blockDevices disk.BlockDevices, devices []string) []numaNodeGroup {
+	byNode := map[int][]string{}
+	for _, device := range devices {
+		node, err := blockDevices.GetDeviceNumaNode(device)
+		if err != nil {
+			log.Debugf("Unable to get the NUMA node of '%s': %v", device, err)
+			node = -1
+		}
+		byNode[node] = append(byNode[node], device)
+	}
+	groups := make([]numaNodeGroup, 0, len(byNode))
+	for node, nodeDevices := range byNode {
+		sort.Strings(nodeDevices)
+		groups = append(groups, numaNodeGroup{node, nodeDevices})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].node < groups[j].node })
+	return groups
 }
 
 func GetDefaultMode(
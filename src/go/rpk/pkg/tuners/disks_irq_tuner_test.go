@@ -10,32 +10,53 @@
 package tuners
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
 type cpuMasksMock struct {
 	irq.CPUMasks
 	baseCPUMask              func(string) (string, error)
+	cgroupCPUMask            func(string) (string, error)
 	cpuMaskForIRQs           func(irq.Mode, string) (string, error)
 	getIRQsDistributionMasks func([]int, string) (map[int]string, error)
+	getDistributionMasks     func(uint) ([]string, error)
+	cpuMaskForComputations   func(irq.Mode, string) (string, error)
+	numaNodeCPUMask          func(string, int) (string, error)
 }
 
 type blockDevicesMock struct {
 	getDirectoriesDevices    func([]string) (map[string][]string, error)
 	getDirectoryDevices      func(string) ([]string, error)
+	getDirectoryStacks       func(string) ([][]string, error)
 	getBlockDeviceFromPath   func(string) (disk.BlockDevice, error)
 	getBlockDeviceSystemPath func(string) (string, error)
 	getDiskInfoByType        func([]string) (map[disk.DiskType]disk.DevicesIRQs, error)
+	getDeviceNumaNode        func(string) (int, error)
 }
 
 func (m *cpuMasksMock) BaseCPUMask(cpuMask string) (string, error) {
 	return m.baseCPUMask(cpuMask)
 }
 
+func (m *cpuMasksMock) CgroupCPUMask(cpuMask string) (string, error) {
+	if m.cgroupCPUMask == nil {
+		return cpuMask, nil
+	}
+	return m.cgroupCPUMask(cpuMask)
+}
+
+func (m *cpuMasksMock) NumaNodeCPUMask(cpuMask string, node int) (string, error) {
+	return m.numaNodeCPUMask(cpuMask, node)
+}
+
 func (m *cpuMasksMock) CPUMaskForIRQs(
 	mode irq.Mode, cpuMask string,
 ) (string, error) {
@@ -48,6 +69,16 @@ func (m *cpuMasksMock) GetIRQsDistributionMasks(
 	return m.getIRQsDistributionMasks(IRQs, cpuMask)
 }
 
+func (m *cpuMasksMock) GetDistributionMasks(count uint) ([]string, error) {
+	return m.getDistributionMasks(count)
+}
+
+func (m *cpuMasksMock) CPUMaskForComputations(
+	mode irq.Mode, cpuMask string,
+) (string, error) {
+	return m.cpuMaskForComputations(mode, cpuMask)
+}
+
 func (m *blockDevicesMock) GetDirectoriesDevices(
 	directories []string,
 ) (map[string][]string, error) {
@@ -57,13 +88,27 @@ func (m *blockDevicesMock) GetDirectoriesDevices(
 func (m *blockDevicesMock) GetDeviceFromPath(
 	path string,
 ) (disk.BlockDevice, error) {
+	// The disk tuners look up the class of the devices they tune.
+	if m.getBlockDeviceFromPath == nil {
+		return nil, errors.New("no device")
+	}
 	return m.getBlockDeviceFromPath(path)
 }
 
 func (m *blockDevicesMock) GetDeviceSystemPath(path string) (string, error) {
+	// The disk tuners look up the system path of the devices they report.
+	if m.getBlockDeviceSystemPath == nil {
+		return "", errors.New("no system path")
+	}
 	return m.getBlockDeviceSystemPath(path)
 }
 
+func (m *blockDevicesMock) GetDirectoryStacks(
+	_ context.Context, path string,
+) ([][]string, error) {
+	return m.getDirectoryStacks(path)
+}
+
 func (m *blockDevicesMock) GetDirectoryDevices(path string) ([]string, error) {
 	return m.getDirectoryDevices(path)
 }
@@ -74,6 +119,13 @@ func (m *blockDevicesMock) GetDiskInfoByType(
 	return m.getDiskInfoByType(devices)
 }
 
+func (m *blockDevicesMock) GetDeviceNumaNode(device string) (int, error) {
+	if m.getDeviceNumaNode == nil {
+		return -1, nil
+	}
+	return m.getDeviceNumaNode(device)
+}
+
 func TestGetExpectedIRQsDistribution(t *testing.T) {
 	type args struct {
 		devices      []string
@@ -139,6 +191,48 @@ func TestGetExpectedIRQsDistribution(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "shall distribute the IRQs on the CPUs of the cgroup",
+			args: args{
+				devices: []string{"nvme0n1"},
+				mode:    irq.Mq,
+				cpuMask: "all",
+				blockDevices: &blockDevicesMock{
+					getDiskInfoByType: func([]string) (map[disk.DiskType]disk.DevicesIRQs, error) {
+						return map[disk.DiskType]disk.DevicesIRQs{
+							disk.Nvme: {
+								Devices: []string{"nvme0n1"},
+								Irqs:    []int{12, 15},
+							},
+						}, nil
+					},
+				},
+				cpuMasks: &cpuMasksMock{
+					baseCPUMask: func(string) (string, error) {
+						return "0x000000ff", nil
+					},
+					cgroupCPUMask: func(string) (string, error) {
+						return "0x0000000c", nil
+					},
+					cpuMaskForIRQs: func(mode irq.Mode, cpuMask string) (string, error) {
+						return cpuMask, nil
+					},
+					getIRQsDistributionMasks: func(IRQs []int, cpuMask string) (map[int]string, error) {
+						if cpuMask != "0x0000000c" {
+							return nil, errors.New("unexpected mask " + cpuMask)
+						}
+						return map[int]string{
+							12: "0x00000004",
+							15: "0x00000008",
+						}, nil
+					},
+				},
+			},
+			want: map[int]string{
+				12: "0x00000004",
+				15: "0x00000008",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -154,3 +248,76 @@ func TestGetExpectedIRQsDistribution(t *testing.T) {
 		})
 	}
 }
+
+func TestExpectedIRQsDistribution_numaNodes(t *testing.T) {
+	// A dual-socket box: nvme0n1 and nvme1n1 are local to node 0, nvme2n1
+	// to node 1, the node of nvme3n1 is unknown. Node 2 has no CPUs.
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "/sys/devices/system/node/node0/cpulist", []byte("0-3\n"), 0o644)
+	afero.WriteFile(fs, "/sys/devices/system/node/node1/cpulist", []byte("4-7\n"), 0o644)
+	afero.WriteFile(fs, "/sys/devices/system/node/node2/cpulist", []byte("\n"), 0o644)
+	nodes := map[string]int{"nvme0n1": 0, "nvme1n1": 0, "nvme2n1": 1, "nvme3n1": -1, "nvme4n1": 2}
+	irqs := map[string][]int{"nvme0n1": {10, 11}, "nvme1n1": {12}, "nvme2n1": {20, 21}, "nvme3n1": {30}, "nvme4n1": {40}}
+	var devices []string
+	for device := range nodes {
+		devices = append(devices, device)
+	}
+
+	blockDevices := &blockDevicesMock{
+		getDiskInfoByType: func(devices []string) (map[disk.DiskType]disk.DevicesIRQs, error) {
+			var info disk.DevicesIRQs
+			for _, device := range devices {
+				info.Devices = append(info.Devices, device)
+				info.Irqs = append(info.Irqs, irqs[device]...)
+			}
+			return map[disk.DiskType]disk.DevicesIRQs{disk.Nvme: info}, nil
+		},
+		getDeviceNumaNode: func(device string) (int, error) {
+			return nodes[device], nil
+		},
+	}
+	realMasks := irq.NewCPUMasks(fs, nil, executors.NewDirectExecutor())
+	cpuMasks := &cpuMasksMock{
+		baseCPUMask: func(string) (string, error) {
+			return "0x000000ff", nil
+		},
+		cpuMaskForIRQs: func(_ irq.Mode, cpuMask string) (string, error) {
+			return cpuMask, nil
+		},
+		numaNodeCPUMask: realMasks.NumaNodeCPUMask,
+		// Each IRQ is pinned to the whole mask it's distributed across.
+		getIRQsDistributionMasks: func(IRQs []int, cpuMask string) (map[int]string, error) {
+			dist := map[int]string{}
+			for _, IRQ := range IRQs {
+				dist[IRQ] = cpuMask
+			}
+			return dist, nil
+		},
+	}
+
+	distribution, deviceNodes, err := expectedIRQsDistribution(devices, blockDevices, irq.Mq, "all", cpuMasks)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{
+		10: "0x0000000f",
+		11: "0x0000000f",
+		12: "0x0000000f",
+		20: "0x000000f0",
+		21: "0x000000f0",
+		30: "0x000000ff",
+		40: "0x000000ff",
+	}, distribution)
+	require.Equal(t, []DeviceNumaNode{
+		{Device: "nvme0n1", Node: 0, CPUMask: "0x0000000f", IRQs: map[int]string{10: "0x0000000f", 11: "0x0000000f"}},
+		{Device: "nvme1n1", Node: 0, CPUMask: "0x0000000f", IRQs: map[int]string{12: "0x0000000f"}},
+		{Device: "nvme2n1", Node: 1, CPUMask: "0x000000f0", IRQs: map[int]string{20: "0x000000f0", 21: "0x000000f0"}},
+		{Device: "nvme3n1", Node: -1, CPUMask: "0x000000ff", IRQs: map[int]string{30: "0x000000ff"}},
+		{Device: "nvme4n1", Node: -1, CPUMask: "0x000000ff", IRQs: map[int]string{40: "0x000000ff"}},
+	}, deviceNodes)
+
+	result := &DiskIRQsTuneResult{TuneResult: NewTuneResult(false), Nodes: deviceNodes[1:4], tuned: true}
+	require.Equal(t, []DeviceTuneResult{
+		{Device: "nvme1n1", Status: DeviceApplied, New: "numa node 0, cpus 0x0000000f (irq 12 -> 0x0000000f)"},
+		{Device: "nvme2n1", Status: DeviceApplied, New: "numa node 1, cpus 0x000000f0 (irq 20 -> 0x000000f0, irq 21 -> 0x000000f0)"},
+		{Device: "nvme3n1", Status: DeviceApplied, New: "any numa node, cpus 0x000000ff (irq 30 -> 0x000000ff)"},
+	}, result.DeviceResults())
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Change describes a change a command makes to the system.
+type Change struct {
+	// Path of the changed file, empty for changes not made to a file.
+	Path string
+	// Current value, empty if the file doesn't exist yet. For files listing
+	// the available options with the active one in brackets, such as the
+	// disk scheduler, only the active option is kept.
+	Current string
+	// Proposed value.
+	Proposed string
+}
+
+// ChangeReporter is implemented by commands able to report the change they
+// would make to the system without executing it.
+type ChangeReporter interface {
+	Change() (Change, error)
+}
+
+var activeOptionPattern = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// currentValue returns the value of a file content as it can be written
+// back, e.g. 'mq-deadline' for a 'none [mq-deadline] kyber' scheduler file.
+func currentValue(content string) string {
+	content = strings.TrimSpace(content)
+	if matches := activeOptionPattern.FindStringSubmatch(content); matches != nil {
+		return matches[1]
+	}
+	return content
+}
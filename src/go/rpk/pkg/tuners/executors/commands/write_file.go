@@ -75,3 +75,15 @@ func (c *writeFileCommand) RenderScript(w *bufio.Writer) error {
 	}
 	return w.Flush()
 }
+
+func (c *writeFileCommand) Change() (Change, error) {
+	var current string
+	if exists, _ := afero.Exists(c.fs, c.path); exists {
+		content, err := afero.ReadFile(c.fs, c.path)
+		if err != nil {
+			return Change{}, err
+		}
+		current = currentValue(string(content))
+	}
+	return Change{Path: c.path, Current: current, Proposed: c.content}, nil
+}
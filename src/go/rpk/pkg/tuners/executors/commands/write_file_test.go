@@ -141,3 +141,53 @@ func TestWriteFileCmdRenderExistingFile(t *testing.T) {
 		t.Errorf("expected:\n\"%s\"\ngot:\n\"%s\"\n", expected, buf.String())
 	}
 }
+
+func TestWriteFileCmdChange(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cmd := commands.NewWriteFileCmd(fs, path, "none")
+	reporter, ok := cmd.(commands.ChangeReporter)
+	if !ok {
+		t.Fatal("expected the command to report its change")
+	}
+	change, err := reporter.Change()
+	if err != nil {
+		t.Errorf("an error happened while getting the change: %v", err)
+	}
+	expected := commands.Change{Path: path, Proposed: "none"}
+	if change != expected {
+		t.Errorf("got %+v, expected %+v", change, expected)
+	}
+
+	err = afero.WriteFile(fs, path, []byte("mq-deadline\n"), 0o644)
+	if err != nil {
+		t.Errorf("got an error writing the file: %v", err)
+	}
+	change, err = reporter.Change()
+	if err != nil {
+		t.Errorf("an error happened while getting the change: %v", err)
+	}
+	expected = commands.Change{Path: path, Current: "mq-deadline", Proposed: "none"}
+	if change != expected {
+		t.Errorf("got %+v, expected %+v", change, expected)
+	}
+	content, _ := afero.ReadFile(fs, path)
+	if string(content) != "mq-deadline\n" {
+		t.Errorf("reporting the change modified the file: %q", content)
+	}
+}
+
+func TestWriteFileCmdChangeActiveOption(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, path, []byte("none [mq-deadline] kyber\n"), 0o644)
+	if err != nil {
+		t.Errorf("got an error writing the file: %v", err)
+	}
+	cmd := commands.NewWriteFileCmd(fs, path, "none")
+	change, err := cmd.(commands.ChangeReporter).Change()
+	if err != nil {
+		t.Errorf("an error happened while getting the change: %v", err)
+	}
+	if change.Current != "mq-deadline" {
+		t.Errorf("got current value %q, expected %q", change.Current, "mq-deadline")
+	}
+}
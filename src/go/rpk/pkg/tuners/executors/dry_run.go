@@ -0,0 +1,71 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type dryRunExecutor struct {
+	mu      sync.Mutex
+	changes []commands.Change
+}
+
+// NewDryRunExecutor returns an executor recording the changes commands would
+// make to the system instead of executing them.
+func NewDryRunExecutor() RecordingExecutor {
+	return &dryRunExecutor{}
+}
+
+func (e *dryRunExecutor) Execute(cmd commands.Command) error {
+	if reporter, ok := cmd.(commands.ChangeReporter); ok {
+		change, err := reporter.Change()
+		if err != nil {
+			return err
+		}
+		e.record(change)
+		return nil
+	}
+	// Commands not changing a single file are reported as the script
+	// they would run.
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := cmd.RenderScript(w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	e.record(commands.Change{
+		Proposed: strings.TrimSpace(buf.String()),
+	})
+	return nil
+}
+
+func (e *dryRunExecutor) record(change commands.Change) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.changes = append(e.changes, change)
+}
+
+func (*dryRunExecutor) IsLazy() bool {
+	return true
+}
+
+func (e *dryRunExecutor) Changes() []commands.Change {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]commands.Change(nil), e.changes...)
+}
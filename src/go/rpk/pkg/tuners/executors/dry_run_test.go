@@ -0,0 +1,38 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunExecutor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	scheduler := "/sys/block/sda/queue/scheduler"
+	require.NoError(t, afero.WriteFile(fs, scheduler, []byte("mq-deadline\n"), 0o644))
+
+	executor := executors.NewDryRunExecutor()
+	require.True(t, executor.IsLazy())
+	require.NoError(t, executor.Execute(commands.NewWriteFileCmd(fs, scheduler, "none")))
+	require.NoError(t, executor.Execute(commands.NewSysctlSetCmd("vm.swappiness", "1")))
+
+	require.Equal(t, []commands.Change{
+		{Path: scheduler, Current: "mq-deadline", Proposed: "none"},
+		{Proposed: "sysctl -w vm.swappiness=1"},
+	}, executor.Changes())
+	content, err := afero.ReadFile(fs, scheduler)
+	require.NoError(t, err)
+	require.Equal(t, "mq-deadline\n", string(content))
+}
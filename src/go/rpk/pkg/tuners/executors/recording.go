@@ -0,0 +1,71 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"sync"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// RecordingExecutor is an executor keeping track of the changes made by the
+// commands it executes. It's safe for concurrent use.
+type RecordingExecutor interface {
+	Executor
+	// Changes returns the changes recorded so far, in execution order.
+	Changes() []commands.Change
+}
+
+type recordingExecutor struct {
+	executor Executor
+	mu       sync.Mutex
+	changes  []commands.Change
+}
+
+// NewRecordingExecutor returns an executor recording the changes of the
+// commands reporting them, see commands.ChangeReporter, before executing
+// them with the given executor.
+func NewRecordingExecutor(executor Executor) RecordingExecutor {
+	return &recordingExecutor{executor: executor}
+}
+
+func (e *recordingExecutor) Execute(cmd commands.Command) error {
+	var (
+		change   commands.Change
+		reported bool
+	)
+	if reporter, ok := cmd.(commands.ChangeReporter); ok {
+		var err error
+		change, err = reporter.Change()
+		if err != nil {
+			return err
+		}
+		reported = true
+	}
+	if err := e.executor.Execute(cmd); err != nil {
+		return err
+	}
+	if reported {
+		e.mu.Lock()
+		e.changes = append(e.changes, change)
+		e.mu.Unlock()
+	}
+	return nil
+}
+
+func (e *recordingExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+func (e *recordingExecutor) Changes() []commands.Change {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]commands.Change(nil), e.changes...)
+}
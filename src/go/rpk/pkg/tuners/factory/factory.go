@@ -13,7 +13,11 @@
 package factory
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cloud/gcp"
@@ -35,19 +39,28 @@ import (
 )
 
 var allTuners = map[string]func(*tunersFactory, *TunerParams) tuners.Tunable{
-	"disk_irq":              (*tunersFactory).newDiskIRQTuner,
-	"disk_scheduler":        (*tunersFactory).newDiskSchedulerTuner,
-	"disk_nomerges":         (*tunersFactory).newDiskNomergesTuner,
-	"disk_write_cache":      (*tunersFactory).newGcpWriteCacheTuner,
-	"fstrim":                (*tunersFactory).newFstrimTuner,
-	"net":                   (*tunersFactory).newNetworkTuner,
-	"cpu":                   (*tunersFactory).newCPUTuner,
-	"aio_events":            (*tunersFactory).newMaxAIOEventsTuner,
-	"clocksource":           (*tunersFactory).newClockSourceTuner,
-	"swappiness":            (*tunersFactory).newSwappinessTuner,
-	"transparent_hugepages": (*tunersFactory).newTHPTuner,
-	"coredump":              (*tunersFactory).newCoredumpTuner,
-	"ballast_file":          (*tunersFactory).newBallastFileTuner,
+	"disk_irq":                  (*tunersFactory).newDiskIRQTuner,
+	"disk_scheduler":            (*tunersFactory).newDiskSchedulerTuner,
+	"disk_nomerges":             (*tunersFactory).newDiskNomergesTuner,
+	"disk_nr_requests":          (*tunersFactory).newDiskNrRequestsTuner,
+	"disk_add_random":           (*tunersFactory).newDiskAddRandomTuner,
+	"disk_max_sectors":          (*tunersFactory).newDiskMaxSectorsTuner,
+	"disk_rq_affinity":          (*tunersFactory).newDiskRqAffinityTuner,
+	"disk_read_ahead":           (*tunersFactory).newDiskReadAheadTuner,
+	"disk_write_cache":          (*tunersFactory).newGcpWriteCacheTuner,
+	"disk_volatile_write_cache": (*tunersFactory).newDiskVolatileWriteCacheTuner,
+	"fstrim":                    (*tunersFactory).newFstrimTuner,
+	"net":                       (*tunersFactory).newNetworkTuner,
+	"rps":                       (*tunersFactory).newRpsTuner,
+	"xps":                       (*tunersFactory).newXpsTuner,
+	"cpu":                       (*tunersFactory).newCPUTuner,
+	"aio_events":                (*tunersFactory).newMaxAIOEventsTuner,
+	"clocksource":               (*tunersFactory).newClockSourceTuner,
+	"swappiness":                (*tunersFactory).newSwappinessTuner,
+	"cpu_governor":              (*tunersFactory).newCPUGovernorTuner,
+	"transparent_hugepages":     (*tunersFactory).newTHPTuner,
+	"coredump":                  (*tunersFactory).newCoredumpTuner,
+	"ballast_file":              (*tunersFactory).newBallastFileTuner,
 }
 
 type TunerParams struct {
@@ -57,10 +70,38 @@ type TunerParams struct {
 	Disks         []string
 	Directories   []string
 	Nics          []string
+	// KafkaNics are the interfaces serving the Kafka API, which the rps and
+	// xps tuners tune in place of the Nics. They're detected from its
+	// advertised address when the Nics aren't given.
+	KafkaNics []string
+	// DiskDevices are the paths of the block devices to tune, e.g.
+	// '/dev/nvme0n1', which replace the devices of the data directories.
+	DiskDevices []string
+	// DiskNumbers are the major and minor numbers of the block devices to
+	// tune, e.g. '259:0', which replace the devices of the data directories
+	// along with the DiskDevices.
+	DiskNumbers []string
+	// AdditionalDirectories are directories holding other data than the
+	// data directories, e.g. coredumps or the WAL, whose devices are tuned
+	// along with theirs. They're added to the Directories by
+	// MergeTunerParamsConfig.
+	AdditionalDirectories []string
+	// SchedulerOverrides map the devices, e.g. 'nvme0n1', to the I/O
+	// scheduler to set on them in place of the one the scheduler tuner
+	// prefers for their device class.
+	SchedulerOverrides map[string]string
+	// DryRun is set when the tuners only report the changes they would make,
+	// they then run with an executor recording their commands without
+	// executing them, see executors.NewDryRunExecutor. They still read the
+	// current values, but never write.
+	DryRun bool
 }
 
 type TunersFactory interface {
 	CreateTuner(tunerType string, params *TunerParams) tuners.Tunable
+	// DeviceStacks returns the device stacks of the Directories of the
+	// params, which the results of the disk tuners are grouped by.
+	DeviceStacks(ctx context.Context, params *TunerParams) ([]tuners.DeviceStack, error)
 }
 
 type tunersFactory struct {
@@ -96,6 +137,20 @@ func NewScriptRenderingTunersFactory(
 	return newTunersFactory(fs, conf, irqProcFile, proc, irqDeviceInfo, executor, timeout)
 }
 
+// NewRecordingTunersFactory returns a factory of tuners executing their
+// commands with the given executor, which keeps track of their changes.
+func NewRecordingTunersFactory(
+	fs afero.Fs,
+	conf config.Config,
+	executor executors.RecordingExecutor,
+	timeout time.Duration,
+) TunersFactory {
+	irqProcFile := irq.NewProcFile(fs)
+	proc := os.NewProc()
+	irqDeviceInfo := irq.NewDeviceInfo(fs, irqProcFile)
+	return newTunersFactory(fs, conf, irqProcFile, proc, irqDeviceInfo, executor, timeout)
+}
+
 func newTunersFactory(
 	fs afero.Fs,
 	conf config.Config,
@@ -112,7 +167,7 @@ func newTunersFactory(
 		irqDeviceInfo:     irqDeviceInfo,
 		cpuMasks:          irq.NewCPUMasks(fs, hwloc.NewHwLocCmd(proc, timeout), executor),
 		irqBalanceService: irq.NewBalanceService(fs, proc, executor, timeout),
-		blockDevices:      disk.NewBlockDevices(fs, irqDeviceInfo, irqProcFile, proc, timeout),
+		blockDevices:      disk.NewBlockDevices(fs, irqDeviceInfo, irqProcFile, proc, timeout, disk.SysfsRootFromEnv()),
 		grub:              system.NewGrub(os.NewCommands(proc), proc, fs, executor, timeout),
 		proc:              proc,
 		executor:          executor,
@@ -139,14 +194,28 @@ func IsTunerEnabled(tuner string, rpkConfig config.RpkConfig) bool {
 		return rpkConfig.TuneDiskScheduler
 	case "disk_nomerges":
 		return rpkConfig.TuneNomerges
+	case "disk_nr_requests":
+		return rpkConfig.TuneDiskNrRequests
+	case "disk_add_random":
+		return rpkConfig.TuneDiskAddRandom
+	case "disk_max_sectors":
+		return rpkConfig.TuneDiskMaxSectors
+	case "disk_rq_affinity":
+		return rpkConfig.TuneDiskRqAffinity
+	case "disk_read_ahead":
+		return rpkConfig.TuneDiskReadAhead
 	case "disk_write_cache":
 		return rpkConfig.TuneDiskWriteCache
+	case "disk_volatile_write_cache":
+		return rpkConfig.TuneDiskVolatileWriteCache
 	case "fstrim":
 		return rpkConfig.TuneFstrim
-	case "net":
+	case "net", "rps", "xps":
 		return rpkConfig.TuneNetwork
 	case "cpu":
 		return rpkConfig.TuneCPU
+	case "cpu_governor":
+		return rpkConfig.TuneCPUGovernor
 	case "aio_events":
 		return rpkConfig.TuneAioEvents
 	case "clocksource":
@@ -169,6 +238,12 @@ func (factory *tunersFactory) CreateTuner(
 	return allTuners[tunerName](factory, tunerParams)
 }
 
+func (factory *tunersFactory) DeviceStacks(
+	ctx context.Context, params *TunerParams,
+) ([]tuners.DeviceStack, error) {
+	return tuners.DirectoryDeviceStacks(ctx, factory.blockDevices, params.Directories)
+}
+
 func (factory *tunersFactory) newDiskIRQTuner(
 	params *TunerParams,
 ) tuners.Tunable {
@@ -195,6 +270,7 @@ func (factory *tunersFactory) newDiskSchedulerTuner(
 		factory.fs,
 		params.Directories,
 		params.Disks,
+		params.SchedulerOverrides,
 		factory.blockDevices,
 		factory.executor,
 	)
@@ -212,6 +288,78 @@ func (factory *tunersFactory) newDiskNomergesTuner(
 	)
 }
 
+func (factory *tunersFactory) newDiskNrRequestsTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewNrRequestsTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newDiskAddRandomTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewAddRandomTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newDiskMaxSectorsTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewMaxSectorsTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newDiskRqAffinityTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewRqAffinityTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newDiskReadAheadTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewReadAheadTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newDiskVolatileWriteCacheTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewVolatileWriteCacheTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		factory.executor,
+	)
+}
+
 func (factory *tunersFactory) newGcpWriteCacheTuner(
 	params *TunerParams,
 ) tuners.Tunable {
@@ -250,6 +398,50 @@ func (factory *tunersFactory) newNetworkTuner(
 	)
 }
 
+func (factory *tunersFactory) newRpsTuner(params *TunerParams) tuners.Tunable {
+	ethtool, err := ethtool.NewEthtoolWrapper()
+	if err != nil {
+		panic(err)
+	}
+	return tuners.NewRpsTuner(
+		irq.ModeFromString(params.Mode),
+		params.CPUMask,
+		params.kafkaNics(),
+		factory.fs,
+		factory.irqDeviceInfo,
+		factory.cpuMasks,
+		factory.irqBalanceService,
+		factory.irqProcFile,
+		ethtool,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newXpsTuner(params *TunerParams) tuners.Tunable {
+	ethtool, err := ethtool.NewEthtoolWrapper()
+	if err != nil {
+		panic(err)
+	}
+	return tuners.NewXpsTuner(
+		params.kafkaNics(),
+		factory.fs,
+		factory.irqDeviceInfo,
+		factory.cpuMasks,
+		factory.irqBalanceService,
+		factory.irqProcFile,
+		ethtool,
+		factory.executor,
+	)
+}
+
+// kafkaNics returns the KafkaNics, or the Nics if they weren't detected.
+func (params *TunerParams) kafkaNics() []string {
+	if len(params.KafkaNics) > 0 {
+		return params.KafkaNics
+	}
+	return params.Nics
+}
+
 func (factory *tunersFactory) newCPUTuner(params *TunerParams) tuners.Tunable {
 	return cpu.NewCPUTuner(
 		factory.cpuMasks,
@@ -257,6 +449,7 @@ func (factory *tunersFactory) newCPUTuner(params *TunerParams) tuners.Tunable {
 		factory.fs,
 		params.RebootAllowed,
 		factory.executor,
+		factory.newCPUGovernorTuner(params),
 	)
 }
 
@@ -278,8 +471,14 @@ func (factory *tunersFactory) newSwappinessTuner(
 	return tuners.NewSwappinessTuner(factory.fs, factory.executor)
 }
 
+func (factory *tunersFactory) newCPUGovernorTuner(
+	_ *TunerParams,
+) tuners.Tunable {
+	return tuners.NewCPUGovernorTuner(factory.fs, tuners.CPUGovernor(&factory.conf), factory.executor)
+}
+
 func (factory *tunersFactory) newTHPTuner(_ *TunerParams) tuners.Tunable {
-	return tuners.NewEnableTHPTuner(factory.fs, factory.executor)
+	return tuners.NewTransparentHugePagesTuner(factory.fs, factory.executor)
 }
 
 func (factory *tunersFactory) newCoredumpTuner(_ *TunerParams) tuners.Tunable {
@@ -292,8 +491,11 @@ func (factory *tunersFactory) newBallastFileTuner(
 	return ballast.NewBallastFileTuner(factory.conf, factory.executor)
 }
 
+// MergeTunerParamsConfig fills the params not given by flags from the
+// configuration. The data directory is only required if one of the given
+// tuners is an enabled disk tuner, the others don't tune directories.
 func MergeTunerParamsConfig(
-	params *TunerParams, conf *config.Config,
+	params *TunerParams, conf *config.Config, tunerNames []string,
 ) (*TunerParams, error) {
 	if len(params.Nics) == 0 {
 		addrs := []string{conf.Redpanda.RPCServer.Address}
@@ -307,13 +509,98 @@ func MergeTunerParamsConfig(
 			return params, err
 		}
 		params.Nics = nics
+		params.KafkaNics = kafkaInterfaces(conf)
 	}
-	if len(params.Directories) == 0 {
-		params.Directories = []string{conf.Redpanda.Directory}
+	if len(params.DiskDevices) == 0 && len(params.DiskNumbers) == 0 {
+		directories, err := conf.DataDirectories(params.Directories)
+		if err != nil && diskTunerEnabled(tunerNames, conf.Rpk) {
+			return params, err
+		}
+		if err == nil {
+			params.Directories = directories
+		}
 	}
+	params.Directories = appendDirectories(params.Directories, params.AdditionalDirectories)
 	return params, nil
 }
 
+func diskTunerEnabled(tunerNames []string, rpkConfig config.RpkConfig) bool {
+	for _, tunerName := range tunerNames {
+		if strings.HasPrefix(tunerName, "disk_") && IsTunerEnabled(tunerName, rpkConfig) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendDirectories appends the additional directories to the directories,
+// skipping those already listed. Directories on the same device are kept:
+// the device is tuned once, and its results list all of them.
+func appendDirectories(directories, additional []string) []string {
+	listed := map[string]bool{}
+	for _, directory := range directories {
+		listed[filepath.Clean(directory)] = true
+	}
+	for _, directory := range additional {
+		if listed[filepath.Clean(directory)] {
+			continue
+		}
+		listed[filepath.Clean(directory)] = true
+		directories = append(directories, directory)
+	}
+	return directories
+}
+
+// ResolveDiskDevices validates that the DiskDevices and DiskNumbers of the
+// params exist in sysfs and adds their names to the Disks to tune. Devices
+// may be given by name, e.g. 'nvme0n1', or by device node, e.g.
+// '/dev/nvme0n1' or a link to it such as '/dev/mapper/vg0-data', see
+// disk.DeviceResolver.DeviceFromName, or by their major and minor numbers,
+// e.g. '259:0', see disk.DeviceResolver.DeviceFromNumbers.
+func ResolveDiskDevices(ctx context.Context, fs afero.Fs, params *TunerParams) error {
+	resolver := disk.NewDeviceResolver(fs, disk.SysfsRootFromEnv())
+	for _, devicePath := range params.DiskDevices {
+		device, err := resolver.DeviceFromName(ctx, devicePath)
+		if err != nil {
+			return fmt.Errorf("invalid disk device '%s': %w", devicePath, err)
+		}
+		params.Disks = append(params.Disks, diskName(device))
+	}
+	for _, numbers := range params.DiskNumbers {
+		major, minor, err := disk.ParseDeviceNumbers(numbers)
+		if err != nil {
+			return err
+		}
+		device, err := resolver.DeviceFromNumbers(ctx, major, minor)
+		if err != nil {
+			return fmt.Errorf("invalid disk device '%s': %w", numbers, err)
+		}
+		params.Disks = append(params.Disks, diskName(device))
+	}
+	return nil
+}
+
+// diskName returns the name the device is tuned by. Partitions resolve to
+// their disk, they're still tuned by their own name.
+func diskName(device disk.BlockDevice) string {
+	if partition := device.Partition(); partition != nil {
+		device = partition
+	}
+	return strings.TrimPrefix(device.Devnode(), "/dev/")
+}
+
+// kafkaInterfaces returns the interfaces serving the Kafka API, or none if
+// they can't be found, e.g. as its address doesn't resolve: the rps and xps
+// tuners then tune the Nics, and the other tuners are unaffected.
+func kafkaInterfaces(conf *config.Config) []string {
+	nics, err := tuners.KafkaInterfaces(conf)
+	if err != nil {
+		log.Warnf("Unable to find the interfaces serving the Kafka API: %v", err)
+		return nil
+	}
+	return nics
+}
+
 func FillTunerParamsWithValuesFromConfig(
 	params *TunerParams, conf *config.Config,
 ) error {
@@ -328,7 +615,9 @@ func FillTunerParamsWithValuesFromConfig(
 		return err
 	}
 	params.Nics = nics
+	params.KafkaNics = kafkaInterfaces(conf)
 	log.Infof("Redpanda uses '%v' NICs", params.Nics)
+	log.Infof("Redpanda serves the Kafka API on '%v' NICs", params.KafkaNics)
 	log.Infof("Redpanda data directory '%s'", conf.Redpanda.Directory)
 	params.Directories = []string{conf.Redpanda.Directory}
 	return nil
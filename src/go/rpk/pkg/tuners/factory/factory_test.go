@@ -13,10 +13,14 @@
 package factory_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/factory"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,15 +61,142 @@ func TestMergeTunerParamsConfig(t *testing.T) {
 				return params
 			},
 		},
+		{
+			name: "it should not take the directories from the configuration when disk devices are set",
+			tunerParams: func() *factory.TunerParams {
+				params := getValidTunerParams()
+				params.Directories = []string{}
+				params.DiskDevices = []string{"/dev/nvme0n1"}
+				return params
+			},
+			expected: func() *factory.TunerParams {
+				params := getValidTunerParams()
+				params.Directories = []string{}
+				params.DiskDevices = []string{"/dev/nvme0n1"}
+				return params
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			conf := config.DevDefault()
-			res, err := factory.MergeTunerParamsConfig(tt.tunerParams(), conf)
+			res, err := factory.MergeTunerParamsConfig(tt.tunerParams(), conf, factory.AvailableTuners())
 			require.NoError(t, err)
 			expected := tt.expected()
 			require.Exactly(t, expected, res)
 		})
 	}
 }
+
+func TestMergeTunerParamsConfigAdditionalDirectories(t *testing.T) {
+	conf := config.DevDefault()
+	params := getValidTunerParams()
+	params.Directories = []string{}
+	params.AdditionalDirectories = []string{
+		"/var/lib/redpanda/coredump",
+		conf.Redpanda.Directory + "/",
+		"/var/lib/redpanda/coredump",
+	}
+	res, err := factory.MergeTunerParamsConfig(params, conf, factory.AvailableTuners())
+	require.NoError(t, err)
+	require.Exactly(t, []string{conf.Redpanda.Directory, "/var/lib/redpanda/coredump"}, res.Directories)
+
+	// The additional directories are tuned along with the disk devices.
+	params = getValidTunerParams()
+	params.Directories = []string{}
+	params.DiskDevices = []string{"/dev/nvme0n1"}
+	params.AdditionalDirectories = []string{"/var/lib/redpanda/coredump"}
+	res, err = factory.MergeTunerParamsConfig(params, conf, factory.AvailableTuners())
+	require.NoError(t, err)
+	require.Exactly(t, []string{"/var/lib/redpanda/coredump"}, res.Directories)
+}
+
+func TestMergeTunerParamsConfigWithoutDataDirectory(t *testing.T) {
+	conf := config.DevDefault()
+	conf.Redpanda.Directory = ""
+	conf.Rpk.TuneDiskScheduler = true
+	params := getValidTunerParams()
+	params.Directories = []string{}
+	_, err := factory.MergeTunerParamsConfig(params, conf, factory.AvailableTuners())
+	require.Error(t, err)
+
+	// The other tuners don't need a data directory.
+	_, err = factory.MergeTunerParamsConfig(params, conf, []string{"swappiness", "aio_events"})
+	require.NoError(t, err)
+	conf.Rpk.TuneDiskScheduler = false
+	_, err = factory.MergeTunerParamsConfig(params, conf, []string{"disk_scheduler"})
+	require.NoError(t, err)
+
+	params.DiskDevices = []string{"/dev/nvme0n1"}
+	_, err = factory.MergeTunerParamsConfig(params, conf, factory.AvailableTuners())
+	require.NoError(t, err)
+}
+
+func TestMergeTunerParamsConfigUnresolvableKafkaAddress(t *testing.T) {
+	conf := config.DevDefault()
+	conf.Redpanda.KafkaAPI[0].Address = "redpanda.invalid"
+	conf.Redpanda.AdvertisedKafkaAPI = nil
+	params := getValidTunerParams()
+	params.Nics = nil
+	// The Kafka NICs not being found doesn't stop the other tuners.
+	res, err := factory.MergeTunerParamsConfig(params, conf, factory.AvailableTuners())
+	require.NoError(t, err)
+	require.Empty(t, res.KafkaNics)
+
+	params = getValidTunerParams()
+	err = factory.FillTunerParamsWithValuesFromConfig(params, conf)
+	require.NoError(t, err)
+	require.Empty(t, params.KafkaNics)
+}
+
+func TestResolveDiskDevices(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/sys/class/block/nvme0n1/queue", 0o755)
+	afero.WriteFile(fs, "/sys/class/block/nvme0n1/uevent", []byte("DEVNAME=nvme0n1\n"), 0o644)
+
+	params := &factory.TunerParams{DiskDevices: []string{"/dev/nvme0n1"}}
+	err := factory.ResolveDiskDevices(context.Background(), fs, params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"nvme0n1"}, params.Disks)
+
+	params = &factory.TunerParams{DiskDevices: []string{"/dev/nvme0n1", "/dev/nvme1n1"}}
+	err = factory.ResolveDiskDevices(context.Background(), fs, params)
+	require.ErrorIs(t, err, disk.ErrDeviceNotFound)
+
+	params = &factory.TunerParams{DiskNumbers: []string{"nvme0n1"}}
+	err = factory.ResolveDiskDevices(context.Background(), fs, params)
+	require.EqualError(t, err, `invalid device numbers "nvme0n1", expected <major>:<minor>`)
+}
+
+func TestTunableFiles(t *testing.T) {
+	tunersFactory := factory.NewDirectExecutorTunersFactory(afero.NewMemMapFs(), config.Config{}, 0)
+	tests := []struct {
+		tuner          string
+		want           []string
+		notFileTunable bool
+	}{
+		{tuner: "swappiness", want: []string{"/proc/sys/vm/swappiness"}},
+		{tuner: "aio_events", want: []string{"/proc/sys/fs/aio-max-nr"}},
+		{tuner: "clocksource", want: []string{"/sys/devices/system/clocksource/clocksource0/current_clocksource"}},
+		// The kernel lacks transparent huge pages.
+		{tuner: "transparent_hugepages"},
+		// The files of the tuners running commands aren't known.
+		{tuner: "cpu", notFileTunable: true},
+		// The disk tuners only have files for their devices.
+		{tuner: "disk_scheduler"},
+		{tuner: "disk_irq"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tuner, func(t *testing.T) {
+			tuner, ok := tunersFactory.CreateTuner(tt.tuner, &factory.TunerParams{}).(tuners.FileTunable)
+			require.Equal(t, !tt.notFileTunable, ok)
+			if !ok {
+				return
+			}
+			files, err := tuner.TunableFiles("")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, files)
+		})
+	}
+}
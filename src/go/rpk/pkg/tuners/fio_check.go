@@ -0,0 +1,85 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !windows
+
+package tuners
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	log "github.com/sirupsen/logrus"
+)
+
+// The minimum recommendations the fio benchmark of the data directory is
+// compared to.
+const (
+	MinFioWriteBandwidth = 200 << 20
+	MinFioRandomIops     = 16000
+	MaxFioWriteLatency   = 10 * time.Millisecond
+)
+
+// CheckFio benchmarks the data directory with fio and returns the results of
+// comparing the measured sequential write bandwidth, random IOPS and random
+// write p99 latency to the minimum recommendations, with the measurements as
+// details. If fio isn't installed, a single failed result says so.
+func CheckFio(ctx context.Context, directory string, job disk.FioJob) []CheckResult {
+	return checkFio(ctx, directory, job, disk.RunFio)
+}
+
+func checkFio(
+	ctx context.Context,
+	directory string,
+	job disk.FioJob,
+	runFio func(context.Context, string, disk.FioJob) (*disk.FioResult, error),
+) []CheckResult {
+	newResult := func(desc, required string) CheckResult {
+		return CheckResult{
+			CheckerID: FioChecker,
+			Desc:      fmt.Sprintf("Data directory %s (fio)", desc),
+			Severity:  Warning,
+			Required:  required,
+		}
+	}
+	bandwidth := newResult("sequential write bandwidth", ">= "+units.BytesSize(MinFioWriteBandwidth)+"/s")
+	iops := newResult("random IOPS", fmt.Sprintf(">= %d", MinFioRandomIops))
+	latency := newResult("random write p99 latency", fmt.Sprintf("<= %s", MaxFioWriteLatency))
+
+	res, err := runFio(ctx, directory, job)
+	switch {
+	case err != nil:
+		log.Warnf("Unable to benchmark '%s' with fio: %v", directory, err)
+		bandwidth.Err, iops.Err, latency.Err = err, err, err
+		return []CheckResult{bandwidth, iops, latency}
+	case !res.Available:
+		unavailable := newResult("benchmark", "fio installed")
+		unavailable.Current = "fio not available"
+		return []CheckResult{unavailable}
+	}
+
+	writeBandwidth := res.Sequential.Write.Bandwidth
+	bandwidth.IsOk = writeBandwidth >= MinFioWriteBandwidth
+	bandwidth.Current = units.BytesSize(float64(writeBandwidth)) + "/s"
+	bandwidth.Details = res.Sequential
+
+	randomIops := res.Random.Read.Iops + res.Random.Write.Iops
+	iops.IsOk = randomIops >= MinFioRandomIops
+	iops.Current = fmt.Sprintf("%d (%d reads, %d writes)", randomIops, res.Random.Read.Iops, res.Random.Write.Iops)
+	iops.Details = res.Random
+
+	writeLatency := res.Random.Write.P99Latency
+	latency.IsOk = writeLatency <= MaxFioWriteLatency
+	latency.Current = writeLatency.String()
+	latency.Details = res.Random
+	return []CheckResult{bandwidth, iops, latency}
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !windows
+
+package tuners
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFio(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		res        *disk.FioResult
+		err        error
+		expOk      []bool
+		expCurrent []string
+	}{
+		{
+			name: "fast device",
+			res: &disk.FioResult{
+				Available:  true,
+				Sequential: disk.FioPhase{Write: disk.FioStats{Bandwidth: 1 << 30}},
+				Random: disk.FioPhase{
+					Read:  disk.FioStats{Iops: 50000},
+					Write: disk.FioStats{Iops: 50000, P99Latency: 500 * time.Microsecond},
+				},
+			},
+			expOk:      []bool{true, true, true},
+			expCurrent: []string{"1GiB/s", "100000 (50000 reads, 50000 writes)", "500µs"},
+		},
+		{
+			name: "slow device",
+			res: &disk.FioResult{
+				Available:  true,
+				Sequential: disk.FioPhase{Write: disk.FioStats{Bandwidth: 100 << 20}},
+				Random: disk.FioPhase{
+					Read:  disk.FioStats{Iops: 1500},
+					Write: disk.FioStats{Iops: 1500, P99Latency: 20 * time.Millisecond},
+				},
+			},
+			expOk:      []bool{false, false, false},
+			expCurrent: []string{"100MiB/s", "3000 (1500 reads, 1500 writes)", "20ms"},
+		},
+		{
+			name:       "fio not available",
+			res:        &disk.FioResult{},
+			expOk:      []bool{false},
+			expCurrent: []string{"fio not available"},
+		},
+		{
+			name:       "fio failed",
+			err:        errors.New("fio failed"),
+			expOk:      []bool{false, false, false},
+			expCurrent: []string{"", "", ""},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			results := checkFio(context.Background(), "/data", disk.DefaultFioJob,
+				func(context.Context, string, disk.FioJob) (*disk.FioResult, error) {
+					return test.res, test.err
+				})
+			require.Len(t, results, len(test.expOk))
+			for i, res := range results {
+				require.Equal(t, FioChecker, int(res.CheckerID))
+				require.Equal(t, test.expOk[i], res.IsOk, res.Desc)
+				require.Equal(t, test.expCurrent[i], res.Current, res.Desc)
+				require.Equal(t, test.err, res.Err)
+			}
+		})
+	}
+}
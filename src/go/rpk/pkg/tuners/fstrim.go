@@ -10,6 +10,7 @@
 package tuners
 
 import (
+	"context"
 	"time"
 
 	"github.com/pkg/errors"
@@ -68,7 +69,7 @@ func (*fstrimTuner) CheckIfSupported() (bool, string) {
 	return true, ""
 }
 
-func (t *fstrimTuner) Tune() TuneResult {
+func (t *fstrimTuner) Tune(_ context.Context) TuneResult {
 	c, err := systemd.NewDbusClient()
 	if err != nil {
 		return NewTuneError(err)
@@ -27,15 +27,18 @@ func NewGcpWriteCacheTuner(
 	executor executors.Executor,
 ) Tunable {
 	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
-	return NewDiskTuner(
+	return newDiskTuner(
 		fs,
 		directories,
 		devices,
 		blockDevices,
+		executor,
 		func(device string) Tunable {
 			return NewDeviceGcpWriteCacheTuner(fs, device, deviceFeatures,
 				vendor, executor)
 		},
+		deviceFeatures.GetWriteCacheFeatureFile,
+		nil,
 	)
 }
 
@@ -10,6 +10,7 @@
 package tuners
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -66,7 +67,7 @@ func TestDeviceWriteCacheTuner_Tune(t *testing.T) {
 	fs.MkdirAll(devicePath+"/queue", 0o644)
 	tuner := NewDeviceGcpWriteCacheTuner(fs, "fake", deviceFeatures, v, executors.NewDirectExecutor())
 	// when
-	tuner.Tune()
+	tuner.Tune(context.Background())
 	// then
 	setValue, _ := afero.ReadFile(fs, devicePath+"/queue/write_cache")
 	require.Equal(t, "write through", string(setValue))
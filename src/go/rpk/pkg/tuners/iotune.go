@@ -10,6 +10,7 @@
 package tuners
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -49,7 +50,7 @@ func (tuner *ioTuner) CheckIfSupported() (bool, string) {
 	return true, ""
 }
 
-func (tuner *ioTuner) Tune() TuneResult {
+func (tuner *ioTuner) Tune(_ context.Context) TuneResult {
 	ioTune := iotune.NewIoTune(os.NewProc(), tuner.timeout)
 	args := iotune.IoTuneArgs{
 		Dirs:           tuner.evalDirectories,
@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/system"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/hwloc"
@@ -23,6 +24,8 @@ import (
 
 type CPUMasks interface {
 	BaseCPUMask(cpuMask string) (string, error)
+	CgroupCPUMask(cpuMask string) (string, error)
+	NumaNodeCPUMask(cpuMask string, node int) (string, error)
 	CPUMaskForComputations(mode Mode, cpuMask string) (string, error)
 	CPUMaskForIRQs(mode Mode, cpuMask string) (string, error)
 	SetMask(path string, mask string) error
@@ -62,6 +65,46 @@ func (masks *cpuMasks) BaseCPUMask(cpuMask string) (string, error) {
 	return masks.hwloc.CalcSingle(cpuMask)
 }
 
+// CgroupCPUMask restricts the mask to the CPUs in the cpuset.cpus.effective
+// of the cgroup v2 rpk runs in, e.g. in a container limited to a subset of
+// the host CPUs, as interrupts pinned to the other CPUs would be served by
+// CPUs the process can't use. The mask is returned unchanged when not in a
+// cgroup v2, i.e. all the online CPUs are available.
+func (masks *cpuMasks) CgroupCPUMask(cpuMask string) (string, error) {
+	cpus, err := system.ReadCgroupV2EffectiveCpus(masks.fs)
+	if err != nil {
+		return "", err
+	}
+	if cpus == nil {
+		return cpuMask, nil
+	}
+	restricted, err := intersectMask(cpuMask, cpus)
+	if err != nil {
+		return "", err
+	}
+	if isEmptyMask(restricted) {
+		return "", fmt.Errorf("CPU mask '%s' has none of the CPUs %v of the"+
+			" cgroup cpuset", cpuMask, cpus)
+	}
+	log.Debugf("Restricted CPU mask '%s' to the cgroup cpuset: '%s'", cpuMask, restricted)
+	return restricted, nil
+}
+
+// NumaNodeCPUMask restricts the mask to the CPUs of the NUMA node, e.g. those
+// local to a device. It returns an empty mask if none of the CPUs of the mask
+// are on the node.
+func (masks *cpuMasks) NumaNodeCPUMask(cpuMask string, node int) (string, error) {
+	cpus, err := system.ReadNumaNodeCpus(masks.fs, node)
+	if err != nil {
+		return "", err
+	}
+	restricted, err := intersectMask(cpuMask, cpus)
+	if err != nil || isEmptyMask(restricted) {
+		return "", err
+	}
+	return restricted, nil
+}
+
 func (masks *cpuMasks) IsSupported() bool {
 	return masks.hwloc.IsSupported()
 }
@@ -246,6 +289,39 @@ func MasksEqual(a, b string) (bool, error) {
 	return true, nil
 }
 
+// intersectMask returns the CPUs of the mask, in the hwloc format of comma
+// separated 32 bit groups with the most significant first, e.g.
+// '0x00000001,0xffffffff', that are in cpus.
+func intersectMask(mask string, cpus []uint) (string, error) {
+	parts := strings.Split(mask, ",")
+	groups := make([]uint, len(parts))
+	for _, cpu := range cpus {
+		group := int(cpu / 32)
+		if group >= len(parts) {
+			continue
+		}
+		groups[len(parts)-1-group] |= 1 << (cpu % 32)
+	}
+	restricted := make([]string, len(parts))
+	for i, part := range parts {
+		numeric, err := parseMask(part)
+		if err != nil {
+			return "", err
+		}
+		restricted[i] = fmt.Sprintf("0x%08x", numeric&groups[i])
+	}
+	return strings.Join(restricted, ","), nil
+}
+
+func isEmptyMask(mask string) bool {
+	for _, part := range strings.Split(mask, ",") {
+		if numeric, _ := parseMask(part); numeric != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func parseMask(mask string) (uint, error) {
 	if mask == "" {
 		return 0, nil
@@ -71,3 +71,89 @@ func Test_masksEqual(t *testing.T) {
 		})
 	}
 }
+
+func Test_cpuMasks_CgroupCPUMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		cgroup  string
+		cpusets map[string]string
+		mask    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "shall keep the mask when not in a cgroup",
+			mask: "0x000000ff",
+			want: "0x000000ff",
+		},
+		{
+			name:    "shall keep the mask on cgroup v1",
+			cgroup:  "1:cpuset:/docker/rp\n0::/",
+			cpusets: map[string]string{"/sys/fs/cgroup/cpuset/docker/rp/cpuset.effective_cpus": "0-1"},
+			mask:    "0x000000ff",
+			want:    "0x000000ff",
+		},
+		{
+			name:    "shall intersect the mask with the cgroup cpuset",
+			cgroup:  "0::/docker/rp",
+			cpusets: map[string]string{"/sys/fs/cgroup/docker/rp/cpuset.cpus.effective": "2-3,6\n"},
+			mask:    "0x0000000f",
+			want:    "0x0000000c",
+		},
+		{
+			name:   "shall look up the cpuset of the parent cgroups",
+			cgroup: "0::/kubepods.slice/pod1/rp",
+			cpusets: map[string]string{
+				"/sys/fs/cgroup/kubepods.slice/cpuset.cpus.effective":      "0-63",
+				"/sys/fs/cgroup/kubepods.slice/pod1/cpuset.cpus.effective": "1,33-34",
+			},
+			mask: "0xffffffff,0xffffffff",
+			want: "0x00000006,0x00000002",
+		},
+		{
+			name:    "shall fail if the mask has none of the cgroup CPUs",
+			cgroup:  "0::/docker/rp",
+			cpusets: map[string]string{"/sys/fs/cgroup/docker/rp/cpuset.cpus.effective": "4-7"},
+			mask:    "0x0000000f",
+			wantErr: "CPU mask '0x0000000f' has none of the CPUs [4 5 6 7] of the cgroup cpuset",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.cgroup != "" {
+				afero.WriteFile(fs, "/proc/self/cgroup", []byte(tt.cgroup), 0o644)
+			}
+			for file, cpus := range tt.cpusets {
+				afero.WriteFile(fs, file, []byte(cpus), 0o644)
+			}
+			cpuMasks := NewCPUMasks(fs, nil, executors.NewDirectExecutor())
+			got, err := cpuMasks.CgroupCPUMask(tt.mask)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_cpuMasks_NumaNodeCPUMask(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	// A dual-socket box, with the hyperthreads of node 0 at 32-47.
+	afero.WriteFile(fs, "/sys/devices/system/node/node0/cpulist", []byte("0-15,32-47\n"), 0o644)
+	afero.WriteFile(fs, "/sys/devices/system/node/node1/cpulist", []byte("16-31,48-63\n"), 0o644)
+	cpuMasks := NewCPUMasks(fs, nil, executors.NewDirectExecutor())
+
+	got, err := cpuMasks.NumaNodeCPUMask("0xffffffff,0xffffffff", 0)
+	require.NoError(t, err)
+	require.Equal(t, "0x0000ffff,0x0000ffff", got)
+
+	got, err = cpuMasks.NumaNodeCPUMask("0x0000000f", 1)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = cpuMasks.NumaNodeCPUMask("0x0000000f", 2)
+	require.Error(t, err)
+}
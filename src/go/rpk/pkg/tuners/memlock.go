@@ -0,0 +1,144 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !windows
+
+package tuners
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	vos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
+	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	redpandaService = "redpanda"
+	// procMaxLockedMemory is the memlock row of /proc/<pid>/limits.
+	procMaxLockedMemory = "Max locked memory"
+)
+
+// NewMemlockLimitChecker warns when the memlock limit of redpanda doesn't
+// allow locking all of its memory, as rpk.enable_memory_locking requires. The
+// limit is read from /proc/<pid>/limits of the process of the pid file in the
+// data directory, or, if redpanda isn't running, from the LimitMEMLOCK of the
+// redpanda service, which the process will inherit once started.
+func NewMemlockLimitChecker(
+	fs afero.Fs, pidFile string, proc vos.Proc, timeout time.Duration,
+) Checker {
+	return newMemlockLimitChecker(func() (uint64, error) {
+		return redpandaMemlockLimit(fs, pidFile, proc, timeout)
+	})
+}
+
+func newMemlockLimitChecker(getLimit func() (uint64, error)) Checker {
+	return NewEqualityChecker(
+		MemlockLimitChecker,
+		"Memory lock limit",
+		Warning,
+		"unlimited",
+		func() (interface{}, error) {
+			limit, err := getLimit()
+			if err != nil {
+				return "", err
+			}
+			if limit == unix.RLIM_INFINITY {
+				return "unlimited", nil
+			}
+			return units.BytesSize(float64(limit)), nil
+		},
+	)
+}
+
+func redpandaMemlockLimit(
+	fs afero.Fs, pidFile string, proc vos.Proc, timeout time.Duration,
+) (uint64, error) {
+	pidStr, err := utils.ReadEnsureSingleLine(fs, pidFile)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("unable to read the redpanda pid file: %v", err)
+	}
+	if err == nil {
+		pid, err := strconv.Atoi(strings.TrimSpace(pidStr))
+		if err != nil {
+			return 0, fmt.Errorf("invalid pid in %s: %v", pidFile, err)
+		}
+		running, err := vos.IsRunningPID(fs, pid)
+		if err != nil {
+			return 0, err
+		}
+		if running {
+			return processMemlockLimit(fs, pid)
+		}
+	}
+	return serviceMemlockLimit(proc, timeout)
+}
+
+// processMemlockLimit returns the soft memlock limit of the given process.
+func processMemlockLimit(fs afero.Fs, pid int) (uint64, error) {
+	file := fmt.Sprintf("/proc/%d/limits", pid)
+	f, err := fs.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, procMaxLockedMemory) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, procMaxLockedMemory))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return unix.RLIM_INFINITY, nil
+		}
+		return strconv.ParseUint(fields[0], 10, 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no %q limit in %s", procMaxLockedMemory, file)
+}
+
+// serviceMemlockLimit returns the LimitMEMLOCK of the redpanda service.
+func serviceMemlockLimit(proc vos.Proc, timeout time.Duration) (uint64, error) {
+	lines, err := proc.RunWithSystemLdPath(
+		timeout, "systemctl", "show", "--property=LoadState,LimitMEMLOCK", redpandaService,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("redpanda isn't running and the limits of the %s service can't be read: %v", redpandaService, err)
+	}
+	props := make(map[string]string)
+	for _, line := range lines {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			props[k] = v
+		}
+	}
+	if props["LoadState"] != "loaded" {
+		return 0, fmt.Errorf("redpanda isn't running and there is no %s service", redpandaService)
+	}
+	limit, ok := props["LimitMEMLOCK"]
+	if !ok {
+		return 0, fmt.Errorf("no LimitMEMLOCK for the %s service", redpandaService)
+	}
+	if limit == "infinity" {
+		return unix.RLIM_INFINITY, nil
+	}
+	return strconv.ParseUint(limit, 10, 64)
+}
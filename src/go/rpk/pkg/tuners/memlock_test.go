@@ -0,0 +1,151 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !windows
+
+package tuners
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestMemlockLimitChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       uint64
+		err         error
+		wantOk      bool
+		wantCurrent string
+	}{
+		{
+			name:        "shall pass when unlimited",
+			limit:       unix.RLIM_INFINITY,
+			wantOk:      true,
+			wantCurrent: "unlimited",
+		},
+		{
+			name:        "shall warn about limited locked memory",
+			limit:       64 << 10,
+			wantCurrent: "64KiB",
+		},
+		{
+			name: "shall fail if the limit can't be read",
+			err:  errors.New("getrlimit failed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newMemlockLimitChecker(func() (uint64, error) {
+				return tt.limit, tt.err
+			}).Check()
+			if tt.err != nil {
+				require.Error(t, result.Err)
+				return
+			}
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+			require.Equal(t, "unlimited", result.Required)
+			require.Equal(t, Severity(Warning), result.Severity)
+		})
+	}
+}
+
+type systemctlShowProc struct {
+	lines []string
+	err   error
+}
+
+func (p *systemctlShowProc) RunWithSystemLdPath(
+	_ time.Duration, _ string, _ ...string,
+) ([]string, error) {
+	return p.lines, p.err
+}
+
+func (*systemctlShowProc) IsRunning(_ time.Duration, _ string) bool {
+	return false
+}
+
+func TestRedpandaMemlockLimit(t *testing.T) {
+	const pidFile = "/var/lib/redpanda/data/pid.lock"
+	tests := []struct {
+		name    string
+		files   map[string]string
+		unit    []string
+		unitErr error
+		want    uint64
+		wantErr bool
+	}{
+		{
+			name: "shall read the limit of the running process",
+			files: map[string]string{
+				pidFile:           "1234",
+				"/proc/1234/stat": "1234 (redpanda) S 1",
+				"/proc/1234/limits": "Limit                     Soft Limit           Hard Limit           Units\n" +
+					"Max locked memory         65536                65536                bytes\n",
+			},
+			unit: []string{"LoadState=loaded", "LimitMEMLOCK=infinity"},
+			want: 64 << 10,
+		},
+		{
+			name: "shall read an unlimited limit of the running process",
+			files: map[string]string{
+				pidFile:             "1234",
+				"/proc/1234/stat":   "1234 (redpanda) S 1",
+				"/proc/1234/limits": "Max locked memory         unlimited            unlimited            bytes\n",
+			},
+			want: unix.RLIM_INFINITY,
+		},
+		{
+			name: "shall read the limit of the service if redpanda isn't running",
+			unit: []string{"LoadState=loaded", "LimitMEMLOCK=8388608"},
+			want: 8 << 20,
+		},
+		{
+			name: "shall read the limit of the service if the process is gone",
+			files: map[string]string{
+				pidFile: "1234",
+			},
+			unit: []string{"LoadState=loaded", "LimitMEMLOCK=infinity"},
+			want: unix.RLIM_INFINITY,
+		},
+		{
+			name:    "shall fail if there is no redpanda service",
+			unit:    []string{"LoadState=not-found", "LimitMEMLOCK=8388608"},
+			wantErr: true,
+		},
+		{
+			name:    "shall fail if systemctl fails",
+			unitErr: errors.New("systemctl: command not found"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for path, content := range tt.files {
+				require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0o644))
+			}
+			proc := &systemctlShowProc{lines: tt.unit, err: tt.unitErr}
+			limit, err := redpandaMemlockLimit(fs, pidFile, proc, time.Second)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, limit)
+		})
+	}
+}
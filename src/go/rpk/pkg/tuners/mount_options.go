@@ -0,0 +1,112 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/spf13/afero"
+)
+
+// discouragedFsTypes are the filesystem types Redpanda discourages for its
+// data directory, with the reason why: those not honoring fsync semantics
+// across the network, or not persisting data at all.
+var discouragedFsTypes = map[string]string{
+	"nfs":       "a network filesystem",
+	"nfs4":      "a network filesystem",
+	"cifs":      "a network filesystem",
+	"smb2":      "a network filesystem",
+	"smb3":      "a network filesystem",
+	"ceph":      "a network filesystem",
+	"glusterfs": "a network filesystem",
+	"9p":        "a network filesystem",
+	"tmpfs":     "not persistent",
+	"ramfs":     "not persistent",
+	"overlay":   "a container overlay",
+}
+
+// describeFsType returns the filesystem type, along with why Redpanda
+// discourages it for its data if it does, e.g. 'nfs (a network filesystem,
+// discouraged by Redpanda)'.
+func describeFsType(fsType string) string {
+	reason, ok := discouragedFsTypes[fsType]
+	if !ok && strings.HasPrefix(fsType, "fuse") {
+		reason, ok = "a FUSE filesystem", true
+	}
+	if !ok {
+		return fsType
+	}
+	return fmt.Sprintf("%s (%s, discouraged by Redpanda)", fsType, reason)
+}
+
+// MountOptionsDetails are the details of the mount checkers: the mount
+// holding the data directory.
+type MountOptionsDetails struct {
+	MountPoint string `json:"mount_point"`
+	FsType     string `json:"fs_type"`
+	Source     string `json:"source"`
+	Options    string `json:"options"`
+}
+
+// mountChecker checks the mount holding a directory, as found in the
+// mountinfo file at mountInfoPath. Its current value is followed by the
+// mount options found, so that operators can tell what to remount with: the
+// mount is never changed.
+type mountChecker struct {
+	id            CheckerID
+	desc          string
+	required      string
+	fs            afero.Fs
+	mountInfoPath string
+	path          string
+	check         func(*disk.Mount) (ok bool, current string)
+}
+
+func (c *mountChecker) ID() CheckerID {
+	return c.id
+}
+
+func (c *mountChecker) GetDesc() string {
+	return c.desc
+}
+
+func (*mountChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *mountChecker) GetRequiredAsString() string {
+	return c.required
+}
+
+func (c *mountChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerID: c.ID(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	mount, err := disk.FindMount(c.fs, c.mountInfoPath, c.path)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	var current string
+	res.IsOk, current = c.check(mount)
+	res.Current = fmt.Sprintf("%s (%s on %s: %s)", current, mount.FsType, mount.MountPoint, mount.OptionsString())
+	res.Details = &MountOptionsDetails{
+		MountPoint: mount.MountPoint,
+		FsType:     mount.FsType,
+		Source:     mount.Source,
+		Options:    mount.OptionsString(),
+	}
+	return res
+}
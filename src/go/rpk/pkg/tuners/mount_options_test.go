@@ -0,0 +1,97 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataDirMountCheckers(t *testing.T) {
+	// The mount points are compared with the path free of links.
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	tests := []struct {
+		name            string
+		options         string
+		super           string
+		expNoatimeOk    bool
+		expNoatime      string
+		expBarriersOk   bool
+		expBarriers     string
+		expMountOptions string
+	}{
+		{
+			name:            "noatime with barriers passes",
+			options:         "rw,noatime",
+			super:           "rw,attr2",
+			expNoatimeOk:    true,
+			expNoatime:      "noatime",
+			expBarriersOk:   true,
+			expBarriers:     "barriers enabled",
+			expMountOptions: "attr2,noatime,rw",
+		},
+		{
+			name:            "relatime is advised against",
+			options:         "rw,relatime",
+			super:           "rw,attr2",
+			expNoatime:      "relatime",
+			expBarriersOk:   true,
+			expBarriers:     "barriers enabled",
+			expMountOptions: "attr2,relatime,rw",
+		},
+		{
+			name:            "disabled barriers are advised against",
+			options:         "rw",
+			super:           "rw,nobarrier",
+			expNoatime:      "strictatime",
+			expBarriers:     "barriers disabled, risking data loss on power failure unless the write cache is battery-backed",
+			expMountOptions: "nobarrier,rw",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mountinfo := "1 0 8:1 / / rw,relatime - ext4 /dev/sda1 rw\n" +
+				"2 1 8:16 / " + dir + " " + tt.options + " - xfs /dev/nvme0n1 " + tt.super + "\n"
+			require.NoError(t, afero.WriteFile(fs, "/proc/self/mountinfo", []byte(mountinfo), 0o644))
+
+			mount := " (xfs on " + dir + ": " + tt.expMountOptions + ")"
+			res := NewDataDirMountNoatimeChecker(fs, dir).Check()
+			require.NoError(t, res.Err)
+			require.Equal(t, tt.expNoatimeOk, res.IsOk)
+			require.Equal(t, tt.expNoatime+mount, res.Current)
+			details, ok := res.Details.(*MountOptionsDetails)
+			require.True(t, ok)
+			require.Equal(t, dir, details.MountPoint)
+			require.Equal(t, "xfs", details.FsType)
+
+			res = NewDataDirMountBarriersChecker(fs, dir).Check()
+			require.NoError(t, res.Err)
+			require.Equal(t, tt.expBarriersOk, res.IsOk)
+			require.Equal(t, tt.expBarriers+mount, res.Current)
+		})
+	}
+
+	res := NewDataDirMountNoatimeChecker(afero.NewMemMapFs(), dir).Check()
+	require.Error(t, res.Err)
+	require.False(t, res.IsOk)
+}
+
+func TestDescribeFsType(t *testing.T) {
+	require.Equal(t, "xfs", describeFsType("xfs"))
+	require.Equal(t, "unknown", describeFsType("unknown"))
+	require.Equal(t, "nfs4 (a network filesystem, discouraged by Redpanda)", describeFsType("nfs4"))
+	require.Equal(t, "tmpfs (not persistent, discouraged by Redpanda)", describeFsType("tmpfs"))
+	require.Equal(t, "fuse.sshfs (a FUSE filesystem, discouraged by Redpanda)", describeFsType("fuse.sshfs"))
+}
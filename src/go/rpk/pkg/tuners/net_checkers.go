@@ -13,7 +13,9 @@ package tuners
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/lorenzosaino/go-sysctl"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/ethtool"
@@ -36,6 +38,10 @@ type NetCheckersFactory interface {
 	NewNicNTupleChecker(nic network.Nic) Checker
 	NewNicXpsCheckers(interfaces []string) []Checker
 	NewNicXpsChecker(nic network.Nic) Checker
+	NewNicRpsMasksCheckers(interfaces []string, mode irq.Mode, mask string) []Checker
+	NewNicRpsMasksChecker(nic network.Nic, mode irq.Mode, mask string) Checker
+	NewNicXpsMasksCheckers(interfaces []string) []Checker
+	NewNicXpsMasksChecker(nic network.Nic) Checker
 	NewRfsTableSizeChecker() Checker
 	NewListenBacklogChecker() Checker
 	NewSynBacklogChecker() Checker
@@ -281,6 +287,82 @@ func (f *netCheckersFactory) NewNicXpsChecker(nic network.Nic) Checker {
 	)
 }
 
+func (f *netCheckersFactory) NewNicRpsMasksCheckers(
+	interfaces []string, mode irq.Mode, cpuMask string,
+) []Checker {
+	return f.forNonVirtualInterfaces(
+		interfaces,
+		func(nic network.Nic) Checker {
+			return f.NewNicRpsMasksChecker(nic, mode, cpuMask)
+		})
+}
+
+// NewNicRpsMasksChecker reports the RPS CPU mask of each Rx queue of the NIC,
+// or of its slaves if it's a bond, which should be the mask of the CPUs left
+// for computations by the IRQs distribution mode.
+func (f *netCheckersFactory) NewNicRpsMasksChecker(
+	nic network.Nic, mode irq.Mode, cpuMask string,
+) Checker {
+	return &queueMasksChecker{
+		id:       NicRpsMasksChecker,
+		desc:     fmt.Sprintf("NIC %s RPS CPU masks", nic.Name()),
+		required: "computations CPUs of the IRQs distribution mode",
+		cpuMasks: f.cpuMasks,
+		queues: func() ([]queueMask, error) {
+			rpsMask, err := network.GetRpsCPUMask(nic, mode, cpuMask, f.cpuMasks)
+			if err != nil {
+				return nil, err
+			}
+			return hwQueueMasks(nic, func(hwNic network.Nic) ([]queueMask, error) {
+				rpsCPUFiles, err := hwNic.GetRpsCPUFiles()
+				if err != nil {
+					return nil, err
+				}
+				var queues []queueMask
+				for _, file := range rpsCPUFiles {
+					queues = append(queues, newQueueMask(hwNic, file, rpsMask))
+				}
+				return queues, nil
+			})
+		},
+	}
+}
+
+func (f *netCheckersFactory) NewNicXpsMasksCheckers(
+	interfaces []string,
+) []Checker {
+	return f.forNonVirtualInterfaces(interfaces, f.NewNicXpsMasksChecker)
+}
+
+// NewNicXpsMasksChecker reports the XPS CPU mask of each Tx queue of the NIC,
+// or of its slaves if it's a bond, which should distribute the CPUs among
+// the queues.
+func (f *netCheckersFactory) NewNicXpsMasksChecker(nic network.Nic) Checker {
+	return &queueMasksChecker{
+		id:       NicXpsMasksChecker,
+		desc:     fmt.Sprintf("NIC %s XPS CPU masks", nic.Name()),
+		required: "CPUs distributed among the Tx queues",
+		cpuMasks: f.cpuMasks,
+		queues: func() ([]queueMask, error) {
+			return hwQueueMasks(nic, func(hwNic network.Nic) ([]queueMask, error) {
+				xpsCPUFiles, err := hwNic.GetXpsCPUFiles()
+				if err != nil {
+					return nil, err
+				}
+				masks, err := f.cpuMasks.GetDistributionMasks(uint(len(xpsCPUFiles)))
+				if err != nil {
+					return nil, err
+				}
+				var queues []queueMask
+				for i, mask := range masks {
+					queues = append(queues, newQueueMask(hwNic, xpsCPUFiles[i], mask))
+				}
+				return queues, nil
+			})
+		},
+	}
+}
+
 func (*netCheckersFactory) NewRfsTableSizeChecker() Checker {
 	return NewIntChecker(
 		RfsTableEntriesChecker,
@@ -376,3 +458,108 @@ func (f *netCheckersFactory) forNonVirtualInterfaces(
 	}
 	return chkrs
 }
+
+// queueMask is the CPU mask a queue of a HW interface should have.
+type queueMask struct {
+	// queue is the interface and queue, e.g. 'eth0/rx-0'.
+	queue string
+	file  string
+	mask  string
+}
+
+func newQueueMask(nic network.Nic, file, mask string) queueMask {
+	return queueMask{
+		queue: nic.Name() + "/" + filepath.Base(filepath.Dir(file)),
+		file:  file,
+		mask:  mask,
+	}
+}
+
+// hwQueueMasks returns the queue masks of the NIC if it's a HW interface, or
+// those of its slaves if it's a bond.
+func hwQueueMasks(
+	nic network.Nic, hwQueues func(network.Nic) ([]queueMask, error),
+) ([]queueMask, error) {
+	if nic.IsHwInterface() {
+		return hwQueues(nic)
+	}
+	if !nic.IsBondIface() {
+		return nil, nil
+	}
+	slaves, err := nic.Slaves()
+	if err != nil {
+		return nil, err
+	}
+	var queues []queueMask
+	for _, slave := range slaves {
+		slaveQueues, err := hwQueueMasks(slave, hwQueues)
+		if err != nil {
+			return nil, err
+		}
+		queues = append(queues, slaveQueues...)
+	}
+	return queues, nil
+}
+
+// queueMasksChecker reports the current CPU mask of each queue, it passes if
+// they all are the masks the queues should have.
+type queueMasksChecker struct {
+	id       CheckerID
+	desc     string
+	required string
+	cpuMasks irq.CPUMasks
+	queues   func() ([]queueMask, error)
+}
+
+func (c *queueMasksChecker) ID() CheckerID {
+	return c.id
+}
+
+func (c *queueMasksChecker) GetDesc() string {
+	return c.desc
+}
+
+func (*queueMasksChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *queueMasksChecker) GetRequiredAsString() string {
+	return c.required
+}
+
+func (c *queueMasksChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerID: c.ID(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+		IsOk:      true,
+	}
+	queues, err := c.queues()
+	if err != nil {
+		res.IsOk = false
+		res.Err = err
+		return res
+	}
+	var currents []string
+	for _, q := range queues {
+		// Some drivers expose masks that can't be read, e.g. the XPS
+		// masks of single queue NICs, those are left as they are.
+		current, err := c.cpuMasks.ReadMask(q.file)
+		if err != nil {
+			log.Debugf("Unable to read '%s': %v", q.file, err)
+			currents = append(currents, fmt.Sprintf("%s: unavailable", q.queue))
+			continue
+		}
+		eq, err := irq.MasksEqual(current, q.mask)
+		if err != nil {
+			res.IsOk = false
+			res.Err = err
+			return res
+		}
+		res.IsOk = res.IsOk && eq
+		currents = append(currents, fmt.Sprintf("%s: %s", q.queue, current))
+	}
+	res.Current = strings.Join(currents, ", ")
+	return res
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+//go:build !windows
+
+package tuners
+
+import (
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/network"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNicXpsMasksChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		iface       string
+		masks       map[string]string
+		wantOk      bool
+		wantCurrent string
+	}{
+		{
+			name:  "shall report the masks of a HW interface",
+			iface: "eth0",
+			masks: map[string]string{
+				"/sys/class/net/eth0/queues/tx-0/xps_cpus": "00000001",
+				"/sys/class/net/eth0/queues/tx-1/xps_cpus": "00000002",
+			},
+			wantOk:      true,
+			wantCurrent: "eth0/tx-0: 0x00000001, eth0/tx-1: 0x00000002",
+		},
+		{
+			name:  "shall fail when a mask differs",
+			iface: "eth0",
+			masks: map[string]string{
+				"/sys/class/net/eth0/queues/tx-0/xps_cpus": "00000001",
+				"/sys/class/net/eth0/queues/tx-1/xps_cpus": "00000003",
+			},
+			wantCurrent: "eth0/tx-0: 0x00000001, eth0/tx-1: 0x00000003",
+		},
+		{
+			name:  "shall report the masks of the slaves of a bond",
+			iface: "bond0",
+			masks: map[string]string{
+				"/sys/class/net/eth0/queues/tx-0/xps_cpus": "00000001",
+				"/sys/class/net/eth0/queues/tx-1/xps_cpus": "00000002",
+				"/sys/class/net/eth1/queues/tx-0/xps_cpus": "00000003",
+				"/sys/class/net/eth1/queues/tx-1/xps_cpus": "00000002",
+			},
+			wantCurrent: "eth0/tx-0: 0x00000001, eth0/tx-1: 0x00000002, eth1/tx-0: 0x00000003, eth1/tx-1: 0x00000002",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, fs.MkdirAll("/sys/class/net/eth0/device", 0o755))
+			require.NoError(t, fs.MkdirAll("/sys/class/net/eth1/device", 0o755))
+			require.NoError(t, afero.WriteFile(fs, "/sys/class/net/bond_masters", []byte("bond0\n"), 0o644))
+			require.NoError(t, afero.WriteFile(fs, "/sys/class/net/bond0/bond/slaves", []byte("eth0 eth1\n"), 0o644))
+			for file, mask := range tt.masks {
+				require.NoError(t, afero.WriteFile(fs, file, []byte(mask+"\n"), 0o644))
+			}
+			cpuMasks := &cpuMasksMock{
+				CPUMasks: irq.NewCPUMasks(fs, nil, nil),
+				getDistributionMasks: func(count uint) ([]string, error) {
+					require.Equal(t, uint(2), count)
+					return []string{"0x00000001", "0x00000002"}, nil
+				},
+			}
+			f := &netCheckersFactory{fs: fs, cpuMasks: cpuMasks}
+			nic := network.NewNic(fs, nil, nil, nil, tt.iface)
+			result := f.NewNicXpsMasksChecker(nic).Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+		})
+	}
+}
+
+func TestNicRpsMasksChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		iface       string
+		masks       map[string]string
+		wantOk      bool
+		wantCurrent string
+	}{
+		{
+			name:  "shall report the masks of a HW interface",
+			iface: "eth0",
+			masks: map[string]string{
+				"/sys/class/net/eth0/queues/rx-0/rps_cpus": "0000000e",
+				"/sys/class/net/eth0/queues/rx-1/rps_cpus": "0000000e",
+			},
+			wantOk:      true,
+			wantCurrent: "eth0/rx-0: 0x0000000e, eth0/rx-1: 0x0000000e",
+		},
+		{
+			name:  "shall fail when a mask differs",
+			iface: "eth0",
+			masks: map[string]string{
+				"/sys/class/net/eth0/queues/rx-0/rps_cpus": "0000000e",
+				"/sys/class/net/eth0/queues/rx-1/rps_cpus": "00000000",
+			},
+			wantCurrent: "eth0/rx-0: 0x0000000e, eth0/rx-1: 0x00000000",
+		},
+		{
+			name:  "shall report the masks of the slaves of a bond",
+			iface: "bond0",
+			masks: map[string]string{
+				"/sys/class/net/eth0/queues/rx-0/rps_cpus": "0000000e",
+				"/sys/class/net/eth1/queues/rx-0/rps_cpus": "0000000e",
+			},
+			wantOk:      true,
+			wantCurrent: "eth0/rx-0: 0x0000000e, eth1/rx-0: 0x0000000e",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, fs.MkdirAll("/sys/class/net/eth0/device", 0o755))
+			require.NoError(t, fs.MkdirAll("/sys/class/net/eth1/device", 0o755))
+			require.NoError(t, afero.WriteFile(fs, "/sys/class/net/bond_masters", []byte("bond0\n"), 0o644))
+			require.NoError(t, afero.WriteFile(fs, "/sys/class/net/bond0/bond/slaves", []byte("eth0 eth1\n"), 0o644))
+			for file, mask := range tt.masks {
+				require.NoError(t, afero.WriteFile(fs, file, []byte(mask+"\n"), 0o644))
+			}
+			cpuMasks := &cpuMasksMock{
+				CPUMasks: irq.NewCPUMasks(fs, nil, nil),
+				baseCPUMask: func(string) (string, error) {
+					return "0x0000000f", nil
+				},
+				cpuMaskForComputations: func(mode irq.Mode, cpuMask string) (string, error) {
+					require.Equal(t, irq.Sq, mode)
+					require.Equal(t, "0x0000000f", cpuMask)
+					return "0x0000000e", nil
+				},
+			}
+			f := &netCheckersFactory{fs: fs, cpuMasks: cpuMasks}
+			nic := network.NewNic(fs, nil, nil, nil, tt.iface)
+			result := f.NewNicRpsMasksChecker(nic, irq.Sq, "all").Check()
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.wantOk, result.IsOk)
+			require.Equal(t, tt.wantCurrent, result.Current)
+		})
+	}
+}
@@ -14,6 +14,8 @@ package tuners
 import (
 	"fmt"
 
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/net"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/ethtool"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
@@ -51,6 +53,63 @@ func NewNetTuner(
 		})
 }
 
+// NewRpsTuner returns the tuner setting the RPS CPU masks of the Rx queues of
+// the given interfaces, as the net tuner does.
+func NewRpsTuner(
+	mode irq.Mode,
+	cpuMask string,
+	interfaces []string,
+	fs afero.Fs,
+	irqDeviceInfo irq.DeviceInfo,
+	cpuMasks irq.CPUMasks,
+	irqBalanceService irq.BalanceService,
+	irqProcFile irq.ProcFile,
+	ethtool ethtool.EthtoolWrapper,
+	executor executors.Executor,
+) Tunable {
+	factory := NewNetTunersFactory(
+		fs, irqProcFile, irqDeviceInfo, ethtool, irqBalanceService, cpuMasks, executor)
+	return factory.NewNICsRpsTuner(interfaces, mode, cpuMask)
+}
+
+// NewXpsTuner returns the tuner distributing the CPUs among the Tx queues of
+// the given interfaces with their XPS CPU masks, as the net tuner does.
+func NewXpsTuner(
+	interfaces []string,
+	fs afero.Fs,
+	irqDeviceInfo irq.DeviceInfo,
+	cpuMasks irq.CPUMasks,
+	irqBalanceService irq.BalanceService,
+	irqProcFile irq.ProcFile,
+	ethtool ethtool.EthtoolWrapper,
+	executor executors.Executor,
+) Tunable {
+	factory := NewNetTunersFactory(
+		fs, irqProcFile, irqDeviceInfo, ethtool, irqBalanceService, cpuMasks, executor)
+	return factory.NewNICsXpsTuner(interfaces)
+}
+
+// KafkaInterfaces returns the interfaces serving the Kafka API: those with its
+// first advertised address or, if none has it, e.g. as it's the address of a
+// NAT, those with the address of its first listener. No interface is returned
+// if there is no Kafka listener.
+func KafkaInterfaces(conf *config.Config) ([]string, error) {
+	if len(conf.Redpanda.KafkaAPI) == 0 {
+		return nil, nil
+	}
+	if len(conf.Redpanda.AdvertisedKafkaAPI) > 0 {
+		advertised := conf.Redpanda.AdvertisedKafkaAPI[0].Address
+		nics, err := net.GetInterfacesByHost(advertised)
+		if err != nil {
+			log.Debugf("Unable to find the interfaces with the advertised Kafka address '%s': %v", advertised, err)
+		} else if len(nics) > 0 {
+			return nics, nil
+		}
+		log.Debugf("No interface has the advertised Kafka address '%s', using the listener address", advertised)
+	}
+	return net.GetInterfacesByHost(conf.Redpanda.KafkaAPI[0].Address)
+}
+
 type NetTunersFactory interface {
 	NewNICsBalanceServiceTuner(interfaces []string) Tunable
 	NewNICsIRQsAffinityTuner(interfaces []string, mode irq.Mode, cpuMask string) Tunable
@@ -157,7 +216,7 @@ func (f *netTunersFactory) NewNICsRpsTuner(
 	return f.tuneNonVirtualInterfaces(
 		interfaces,
 		func(nic network.Nic) Checker {
-			return f.checkersFactory.NewNicRpsSetChecker(nic, mode, cpuMask)
+			return f.checkersFactory.NewNicRpsMasksChecker(nic, mode, cpuMask)
 		},
 		func(nic network.Nic) TuneResult {
 			log.Debugf("Tuning '%s' RPS", nic.Name())
@@ -239,7 +298,7 @@ func (f *netTunersFactory) NewNICsXpsTuner(interfaces []string) Tunable {
 	return f.tuneNonVirtualInterfaces(
 		interfaces,
 		func(nic network.Nic) Checker {
-			return f.checkersFactory.NewNicXpsChecker(nic)
+			return f.checkersFactory.NewNicXpsMasksChecker(nic)
 		},
 		func(nic network.Nic) TuneResult {
 			log.Debugf("Tuning '%s' XPS", nic.Name())
@@ -10,6 +10,7 @@
 package tuners_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -109,7 +110,7 @@ func TestSynBacklogTuner(t *testing.T) {
 			f, err := mockNetTunersFactory(fs, exec)
 			require.NoError(st, err)
 			tuner := f.NewSynBacklogTuner()
-			res := tuner.Tune()
+			res := tuner.Tune(context.Background())
 			if tt.expectedErrMsg != "" {
 				require.Contains(st, res.Error().Error(), tt.expectedErrMsg)
 				return
@@ -191,7 +192,7 @@ func TestListenBacklogTuner(t *testing.T) {
 			f, err := mockNetTunersFactory(fs, exec)
 			require.NoError(st, err)
 			tuner := f.NewListenBacklogTuner()
-			res := tuner.Tune()
+			res := tuner.Tune(context.Background())
 			if tt.expectedErrMsg != "" {
 				require.Contains(st, res.Error().Error(), tt.expectedErrMsg)
 				return
@@ -12,10 +12,12 @@
 package tuners
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/hashicorp/go-multierror"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cloud"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cloud/gcp"
@@ -29,6 +31,7 @@ import (
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/hwloc"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/irq"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
@@ -64,6 +67,26 @@ const (
 	KernelVersion
 	WriteCachePolicyChecker
 	BallastFileChecker
+	NrRequestsChecker
+	ReadAheadChecker
+	DeviceClassChecker
+	VolatileWriteCacheChecker
+	MountNoatimeChecker
+	MountBarriersChecker
+	ZonedDeviceChecker
+	NicRpsMasksChecker
+	NicXpsMasksChecker
+	PartitionAlignmentChecker
+	DiskFreePercentChecker
+	MemlockLimitChecker
+	AddRandomChecker
+	FioChecker
+	CPUGovernorChecker
+	ThinProvisioningChecker
+	TransportChecker
+	MaxSectorsChecker
+	SharedDevicesChecker
+	RqAffinityChecker
 )
 
 func NewConfigChecker(conf *config.Config) Checker {
@@ -111,6 +134,76 @@ func NewFreeDiskSpaceChecker(path string) Checker {
 		})
 }
 
+// NewDataDirFreePercentChecker warns when less than minFreePercent of the
+// filesystem holding the data directory is free, its usage is set as the
+// details of the result.
+func NewDataDirFreePercentChecker(path string, minFreePercent int) Checker {
+	return &diskUsageChecker{
+		minFreePercent: minFreePercent,
+		getUsage: func() (*filesystem.DiskUsage, error) {
+			return filesystem.GetDiskUsage(path)
+		},
+	}
+}
+
+// dataDirMinFreePercent returns the rpk.data_dir_min_free_percent of the
+// configuration, or its default if not set.
+func dataDirMinFreePercent(conf *config.Config) int {
+	if conf.Rpk.DataDirMinFreePercent != nil {
+		return *conf.Rpk.DataDirMinFreePercent
+	}
+	return config.DefaultDataDirMinFreePercent
+}
+
+// CPUGovernor returns the rpk.cpu_governor of the configuration, or
+// DefaultCPUGovernor if not set.
+func CPUGovernor(conf *config.Config) string {
+	if conf.Rpk.CPUGovernor != "" {
+		return conf.Rpk.CPUGovernor
+	}
+	return DefaultCPUGovernor
+}
+
+type diskUsageChecker struct {
+	minFreePercent int
+	getUsage       func() (*filesystem.DiskUsage, error)
+}
+
+func (*diskUsageChecker) ID() CheckerID {
+	return DiskFreePercentChecker
+}
+
+func (*diskUsageChecker) GetDesc() string {
+	return "Data partition free space [%]"
+}
+
+func (*diskUsageChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *diskUsageChecker) GetRequiredAsString() string {
+	return fmt.Sprintf(">= %d", c.minFreePercent)
+}
+
+func (c *diskUsageChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerID: c.ID(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	usage, err := c.getUsage()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.IsOk = usage.FreePercent >= float64(c.minFreePercent)
+	res.Current = fmt.Sprintf("%.2f (%s free of %s)", usage.FreePercent,
+		units.BytesSize(float64(usage.FreeBytes)), units.BytesSize(float64(usage.TotalBytes)))
+	res.Details = usage
+	return res
+}
+
 func NewMemoryChecker(fs afero.Fs) Checker {
 	return NewIntChecker(
 		FreeMemChecker,
@@ -150,16 +243,93 @@ func NewSwapChecker(fs afero.Fs) Checker {
 }
 
 func NewFilesystemTypeChecker(path string) Checker {
+	return newFilesystemTypeChecker("Data directory filesystem type", path)
+}
+
+func newFilesystemTypeChecker(desc, path string) Checker {
 	return NewEqualityChecker(
 		FsTypeChecker,
-		"Data directory filesystem type",
+		desc,
 		Warning,
-		filesystem.Xfs,
+		"xfs",
 		func() (interface{}, error) {
-			return filesystem.GetFilesystemType(path)
+			fsType, err := disk.Filesystem(path)
+			if err != nil {
+				return nil, err
+			}
+			return describeFsType(fsType), nil
 		})
 }
 
+// filesystemTypeCheckers returns the checkers of the filesystem type of the
+// data directory and, if it's on another mount, of the cloud storage cache
+// directory.
+func filesystemTypeCheckers(config *config.Config) []Checker {
+	checkers := []Checker{NewFilesystemTypeChecker(config.Redpanda.Directory)}
+	cacheDir := config.Redpanda.CloudStorageCacheDirectory
+	if cacheDir == "" {
+		return checkers
+	}
+	same, err := disk.SameFilesystem(config.Redpanda.Directory, cacheDir)
+	if err != nil {
+		log.Debugf("Unable to compare the filesystems of '%s' and '%s': %v", config.Redpanda.Directory, cacheDir, err)
+		return checkers
+	}
+	if !same {
+		checkers = append(checkers, newFilesystemTypeChecker("Cloud storage cache directory filesystem type", cacheDir))
+	}
+	return checkers
+}
+
+// configuredDirectories returns the directories Redpanda is configured to
+// write to: the data directory and, if set, the cloud storage cache
+// directory.
+func configuredDirectories(config *config.Config) []string {
+	dirs := []string{config.Redpanda.Directory}
+	if cacheDir := config.Redpanda.CloudStorageCacheDirectory; cacheDir != "" {
+		dirs = append(dirs, cacheDir)
+	}
+	return dirs
+}
+
+// NewDataDirMountNoatimeChecker warns when the data directory is mounted
+// without noatime, which makes reads update the access time of the segments,
+// its current value being the access time mode and the mount options.
+func NewDataDirMountNoatimeChecker(fs afero.Fs, path string) Checker {
+	return &mountChecker{
+		id:            MountNoatimeChecker,
+		desc:          "Data directory mounted with noatime",
+		required:      "noatime",
+		fs:            fs,
+		mountInfoPath: disk.DefaultMountInfoPath,
+		path:          path,
+		check: func(mount *disk.Mount) (bool, string) {
+			atime := mount.AtimeMode()
+			return atime == "noatime", atime
+		},
+	}
+}
+
+// NewDataDirMountBarriersChecker warns when the write barriers of the data
+// directory mount are disabled, which is only safe for drives whose write
+// cache is protected against power loss.
+func NewDataDirMountBarriersChecker(fs afero.Fs, path string) Checker {
+	return &mountChecker{
+		id:            MountBarriersChecker,
+		desc:          "Data directory mounted with write barriers",
+		required:      "barriers enabled",
+		fs:            fs,
+		mountInfoPath: disk.DefaultMountInfoPath,
+		path:          path,
+		check: func(mount *disk.Mount) (bool, string) {
+			if mount.BarriersDisabled() {
+				return false, "barriers disabled, risking data loss on power failure unless the write cache is battery-backed"
+			}
+			return true, "barriers enabled"
+		},
+	}
+}
+
 func NewIOConfigFileExistanceChecker(fs afero.Fs, filePath string) Checker {
 	return NewFileExistanceChecker(
 		fs,
@@ -196,6 +366,7 @@ func NewNTPSyncChecker(timeout time.Duration, fs afero.Fs) Checker {
 }
 
 func RedpandaCheckers(
+	ctx context.Context,
 	fs afero.Fs,
 	ioConfigFile string,
 	config *config.Config,
@@ -209,10 +380,29 @@ func RedpandaCheckers(
 	executor := executors.NewDirectExecutor()
 	irqProcFile := irq.NewProcFile(fs)
 	irqDeviceInfo := irq.NewDeviceInfo(fs, irqProcFile)
-	blockDevices := disk.NewBlockDevices(fs, irqDeviceInfo, irqProcFile, proc, timeout)
+	blockDevices := disk.NewBlockDevices(fs, irqDeviceInfo, irqProcFile, proc, timeout, disk.SysfsRootFromEnv())
 	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
 	schedulerChecker := NewDirectorySchedulerChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
 	nomergesChecker := NewDirectoryNomergesChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	nrRequestsChecker := NewDirectoryNrRequestsChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	readAheadChecker := NewDirectoryReadAheadChecker(ctx, config.Redpanda.Directory, deviceFeatures, blockDevices)
+	addRandomChecker := NewDirectoryAddRandomChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	rqAffinityChecker := NewDirectoryRqAffinityChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	maxSectorsChecker := NewDirectoryMaxSectorsChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	deviceClassChecker := NewDirectoryDeviceClassChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	volatileWriteCacheChecker := NewDirectoryVolatileWriteCacheChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	zonedChecker := NewDirectoryZonedChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)
+	partitionAlignmentChecker := NewDirectoryPartitionAlignmentChecker(config.Redpanda.Directory, blockDevices)
+	transportChecker := NewDirectoryTransportChecker(ctx, config.Redpanda.Directory, blockDevices)
+	thinProvisioningChecker := NewDirectoryThinProvisioningChecker(config.Redpanda.Directory, blockDevices,
+		func(pool string) (*disk.ThinPoolStatus, error) {
+			return disk.ReadThinPoolStatus(proc, timeout, pool)
+		})
+	resolver := disk.NewDeviceResolver(fs, disk.SysfsRootFromEnv())
+	sharedDevicesChecker := NewSharedDevicesChecker(fs, disk.DefaultMountInfoPath, configuredDirectories(config),
+		func(dirs []string) ([]disk.SharedDevice, error) {
+			return resolver.SharedDevices(ctx, dirs)
+		})
 	balanceService := irq.NewBalanceService(fs, proc, executor, timeout)
 	cpuMasks := irq.NewCPUMasks(fs, hwloc.NewHwLocCmd(proc, timeout), executor)
 	dirIRQAffinityChecker := NewDirectoryIRQAffinityChecker(config.Redpanda.Directory, "all", irq.Default, blockDevices, cpuMasks)
@@ -227,6 +417,11 @@ func RedpandaCheckers(
 	if err != nil {
 		return nil, err
 	}
+	kafkaInterfaces, err := KafkaInterfaces(config)
+	if err != nil {
+		log.Warnf("Unable to find the interfaces serving the Kafka API, skipping their RPS and XPS masks checks: %v", err)
+		kafkaInterfaces = nil
+	}
 	netCheckersFactory := NewNetCheckersFactory(
 		fs, irqProcFile, irqDeviceInfo, ethtool, balanceService, cpuMasks)
 	checkers := map[CheckerID][]Checker{
@@ -236,11 +431,26 @@ func RedpandaCheckers(
 		SwapChecker:                   {NewSwapChecker(fs)},
 		DataDirAccessChecker:          {NewDataDirWritableChecker(fs, config.Redpanda.Directory)},
 		DiskSpaceChecker:              {NewFreeDiskSpaceChecker(config.Redpanda.Directory)},
-		FsTypeChecker:                 {NewFilesystemTypeChecker(config.Redpanda.Directory)},
+		DiskFreePercentChecker:        {NewDataDirFreePercentChecker(config.Redpanda.Directory, dataDirMinFreePercent(config))},
+		FsTypeChecker:                 filesystemTypeCheckers(config),
+		MountNoatimeChecker:           {NewDataDirMountNoatimeChecker(fs, config.Redpanda.Directory)},
+		MountBarriersChecker:          {NewDataDirMountBarriersChecker(fs, config.Redpanda.Directory)},
 		TransparentHugePagesChecker:   {NewTransparentHugePagesChecker(fs)},
 		NtpChecker:                    {NewNTPSyncChecker(timeout, fs)},
 		SchedulerChecker:              {schedulerChecker},
 		NomergesChecker:               {nomergesChecker},
+		NrRequestsChecker:             {nrRequestsChecker},
+		ReadAheadChecker:              {readAheadChecker},
+		AddRandomChecker:              {addRandomChecker},
+		RqAffinityChecker:             {rqAffinityChecker},
+		MaxSectorsChecker:             {maxSectorsChecker},
+		SharedDevicesChecker:          {sharedDevicesChecker},
+		DeviceClassChecker:            {deviceClassChecker},
+		VolatileWriteCacheChecker:     {volatileWriteCacheChecker},
+		ZonedDeviceChecker:            {zonedChecker},
+		PartitionAlignmentChecker:     {partitionAlignmentChecker},
+		ThinProvisioningChecker:       {thinProvisioningChecker},
+		TransportChecker:              {transportChecker},
 		DiskIRQsAffinityChecker:       {dirIRQAffinityChecker},
 		DiskIRQsAffinityStaticChecker: {dirIRQAffinityStaticChecker},
 		FstrimChecker:                 {NewFstrimChecker()},
@@ -252,9 +462,12 @@ func RedpandaCheckers(
 		NicRpsChecker:                 netCheckersFactory.NewNicRpsSetCheckers(interfaces, irq.Default, "all"),
 		NicRfsChecker:                 netCheckersFactory.NewNicRfsCheckers(interfaces),
 		NicXpsChecker:                 netCheckersFactory.NewNicXpsCheckers(interfaces),
+		NicRpsMasksChecker:            netCheckersFactory.NewNicRpsMasksCheckers(kafkaInterfaces, irq.Default, "all"),
+		NicXpsMasksChecker:            netCheckersFactory.NewNicXpsMasksCheckers(kafkaInterfaces),
 		MaxAIOEvents:                  {NewMaxAIOEventsChecker(fs)},
 		ClockSource:                   {NewClockSourceChecker(fs)},
 		Swappiness:                    {NewSwappinessChecker(fs)},
+		CPUGovernorChecker:            {NewCPUGovernorChecker(fs, CPUGovernor(config))},
 		KernelVersion:                 {NewKernelVersionChecker(GetKernelVersion)},
 		BallastFileChecker:            {NewBallastFileChecker(fs, config)},
 	}
@@ -266,6 +479,9 @@ func RedpandaCheckers(
 	if err == nil && v.Name() == gcpVendor.Name() {
 		checkers[WriteCachePolicyChecker] = []Checker{NewDirectoryWriteCacheChecker(config.Redpanda.Directory, deviceFeatures, blockDevices)}
 	}
+	if config.Rpk.EnableMemoryLocking {
+		checkers[MemlockLimitChecker] = []Checker{NewMemlockLimitChecker(fs, config.PIDFile(), proc, timeout)}
+	}
 
 	return checkers, nil
 }
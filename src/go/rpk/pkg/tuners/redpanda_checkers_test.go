@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/system/filesystem"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
@@ -169,3 +170,74 @@ func TestFreeMemoryChecker(t *testing.T) {
 		})
 	}
 }
+
+func TestDataDirMountCheckers(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	tests := []struct {
+		name       string
+		options    string
+		super      string
+		noatimeOk  bool
+		barriersOk bool
+	}{
+		{
+			name:       "it should pass with noatime and barriers",
+			options:    "rw,noatime",
+			super:      "rw,attr2",
+			noatimeOk:  true,
+			barriersOk: true,
+		},
+		{
+			name:       "it should warn without noatime",
+			options:    "rw,relatime",
+			super:      "rw,attr2",
+			barriersOk: true,
+		},
+		{
+			name:      "it should warn with barriers disabled",
+			options:   "rw,noatime",
+			super:     "rw,nobarrier",
+			noatimeOk: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mountinfo := "1 0 8:1 / / rw - ext4 /dev/sda1 rw\n" +
+				"2 1 8:16 / " + dir + " " + tt.options + " - xfs /dev/nvme0n1 " + tt.super + "\n"
+			require.NoError(t, afero.WriteFile(fs, "/proc/self/mountinfo", []byte(mountinfo), 0o644))
+
+			noatime := tuners.NewDataDirMountNoatimeChecker(fs, dir).Check()
+			require.NoError(t, noatime.Err)
+			require.Equal(t, tt.noatimeOk, noatime.IsOk)
+			require.Equal(t, tuners.Severity(tuners.Warning), noatime.Severity)
+
+			barriers := tuners.NewDataDirMountBarriersChecker(fs, dir).Check()
+			require.NoError(t, barriers.Err)
+			require.Equal(t, tt.barriersOk, barriers.IsOk)
+			require.Equal(t, tuners.Severity(tuners.Warning), barriers.Severity)
+		})
+	}
+}
+
+func TestDataDirFreePercentChecker(t *testing.T) {
+	dir := t.TempDir()
+	res := tuners.NewDataDirFreePercentChecker(dir, 0).Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+	require.Equal(t, ">= 0", res.Required)
+	require.Equal(t, tuners.Severity(tuners.Warning), res.Severity)
+	usage, ok := res.Details.(*filesystem.DiskUsage)
+	require.True(t, ok)
+	require.NotZero(t, usage.TotalBytes)
+
+	// No filesystem is more than 100% free.
+	res = tuners.NewDataDirFreePercentChecker(dir, 101).Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+
+	res = tuners.NewDataDirFreePercentChecker(filepath.Join(dir, "data"), 10).Check()
+	require.EqualError(t, res.Err, "unable to get the disk usage of '"+filepath.Join(dir, "data")+"': it doesn't exist yet")
+	require.Nil(t, res.Details)
+}
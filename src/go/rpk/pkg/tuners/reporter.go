@@ -0,0 +1,136 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Reporter is told of the progress of the disk tuners, device by device, as
+// tuning many devices can take a while. Devices are tuned concurrently: its
+// methods are called from several goroutines.
+type Reporter interface {
+	// DeviceResolved is called once the device was resolved, e.g. from
+	// the data directories, before any tuner acts on it.
+	DeviceResolved(device string)
+	// TunerStarted and TunerFinished are called before and after the tuner
+	// tunes the device, TunerFinished with its result.
+	TunerStarted(device, tuner string)
+	TunerFinished(device, tuner string, result TuneResult)
+}
+
+type reporterKey struct{}
+
+// reporterValue is the reporter of a context, and the tuner it reports the
+// progress of.
+type reporterValue struct {
+	reporter Reporter
+	tuner    string
+}
+
+// WithReporter returns a context reporting the progress of the tuner to the
+// reporter, the context the tuner is to be run with.
+func WithReporter(ctx context.Context, reporter Reporter, tuner string) context.Context {
+	return context.WithValue(ctx, reporterKey{}, reporterValue{reporter, tuner})
+}
+
+// reporterFrom returns the reporter of the context and the name of the tuner
+// it reports the progress of, the no-op NopReporter if it has none.
+func reporterFrom(ctx context.Context) (Reporter, string) {
+	if v, ok := ctx.Value(reporterKey{}).(reporterValue); ok {
+		return v.reporter, v.tuner
+	}
+	return NopReporter{}, ""
+}
+
+// reportResolved reports the resolved devices to the reporter of the
+// context.
+func reportResolved(ctx context.Context, devices []string) {
+	reporter, _ := reporterFrom(ctx)
+	for _, device := range devices {
+		reporter.DeviceResolved(device)
+	}
+}
+
+// reportTune tunes the device with tune, reporting it to the reporter of the
+// context.
+func reportTune(ctx context.Context, device string, tune func() TuneResult) TuneResult {
+	reporter, tuner := reporterFrom(ctx)
+	reporter.TunerStarted(device, tuner)
+	result := tune()
+	reporter.TunerFinished(device, tuner, result)
+	return result
+}
+
+// NopReporter ignores the progress of the tuners, e.g. when the results are
+// printed as JSON.
+type NopReporter struct{}
+
+func (NopReporter) DeviceResolved(string) {}
+
+func (NopReporter) TunerStarted(string, string) {}
+
+func (NopReporter) TunerFinished(string, string, TuneResult) {}
+
+// NewLogReporter returns a Reporter logging the progress of the tuners at
+// info level, with the time each of them took to tune each device. Devices
+// are logged as resolved once, although each disk tuner resolves them.
+func NewLogReporter() Reporter {
+	return &logReporter{
+		resolved: map[string]bool{},
+		started:  map[[2]string]time.Time{},
+	}
+}
+
+type logReporter struct {
+	mu       sync.Mutex
+	resolved map[string]bool
+	// started is when each tuner started tuning each device, by device and
+	// tuner.
+	started map[[2]string]time.Time
+}
+
+func (r *logReporter) DeviceResolved(device string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resolved[device] {
+		return
+	}
+	r.resolved[device] = true
+	log.Infof("Resolved device '%s'", device)
+}
+
+func (r *logReporter) TunerStarted(device, tuner string) {
+	r.mu.Lock()
+	r.started[[2]string{device, tuner}] = time.Now()
+	r.mu.Unlock()
+	log.Infof("Tuning '%s' with %s", device, tuner)
+}
+
+func (r *logReporter) TunerFinished(device, tuner string, result TuneResult) {
+	r.mu.Lock()
+	key := [2]string{device, tuner}
+	elapsed := time.Since(r.started[key])
+	delete(r.started, key)
+	r.mu.Unlock()
+	elapsed = elapsed.Round(time.Millisecond)
+	switch {
+	case result.IsFailed():
+		log.Infof("Failed to tune '%s' with %s after %s: %v", device, tuner, elapsed, result.Error())
+	case result.NotAppliedReason() != "":
+		log.Infof("Skipped '%s' with %s after %s: %s", device, tuner, elapsed, result.NotAppliedReason())
+	default:
+		log.Infof("Tuned '%s' with %s in %s", device, tuner, elapsed)
+	}
+}
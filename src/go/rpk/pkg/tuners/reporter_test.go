@@ -0,0 +1,120 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReporter records the calls it's told of, in order.
+type recordingReporter struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recordingReporter) record(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingReporter) DeviceResolved(device string) {
+	r.record("resolved %s", device)
+}
+
+func (r *recordingReporter) TunerStarted(device, tuner string) {
+	r.record("started %s %s", tuner, device)
+}
+
+func (r *recordingReporter) TunerFinished(device, tuner string, result TuneResult) {
+	status := "ok"
+	if result.IsFailed() {
+		status = result.Error().Error()
+	}
+	r.record("finished %s %s: %s", tuner, device, status)
+}
+
+func reporterBlockDevices() *blockDevicesMock {
+	return &blockDevicesMock{
+		getDirectoriesDevices: func([]string) (map[string][]string, error) {
+			return map[string][]string{"/var/lib/redpanda/data": {"sdb"}}, nil
+		},
+	}
+}
+
+func TestDiskTuner_Tune_reporter(t *testing.T) {
+	tuner := NewDiskTuner(afero.NewMemMapFs(), []string{"/var/lib/redpanda/data"}, []string{"sda"},
+		reporterBlockDevices(), executors.NewDirectExecutor(), func(device string) Tunable {
+			return &mockedTunable{tune: func() TuneResult {
+				if device == "sdb" {
+					return NewTuneError(errors.New("unable to tune 'sdb'"))
+				}
+				return NewTuneResult(false)
+			}}
+		})
+	tuner.(*diskTuner).concurrency = 1
+
+	reporter := &recordingReporter{}
+	tuner.Tune(WithReporter(context.Background(), reporter, "disk_scheduler"))
+	require.Equal(t, []string{
+		"resolved sda",
+		"resolved sdb",
+		"started disk_scheduler sda",
+		"finished disk_scheduler sda: ok",
+		"started disk_scheduler sdb",
+		"finished disk_scheduler sdb: unable to tune 'sdb'",
+	}, reporter.calls)
+}
+
+func TestDiskTuner_Tune_reporterConcurrently(t *testing.T) {
+	var devices []string
+	for i := 0; i < 8; i++ {
+		devices = append(devices, fmt.Sprintf("nvme%dn1", i))
+	}
+	tuner := NewDiskTuner(afero.NewMemMapFs(), nil, devices, reporterBlockDevices(),
+		executors.NewDirectExecutor(), func(string) Tunable {
+			return &mockedTunable{tune: func() TuneResult { return NewTuneResult(false) }}
+		})
+	tuner.(*diskTuner).concurrency = 4
+
+	reporter := &recordingReporter{}
+	tuner.Tune(WithReporter(context.Background(), reporter, "disk_nomerges"))
+	// All the devices are resolved before any is tuned, each of them is
+	// tuned once, in whatever order.
+	require.Len(t, reporter.calls, 3*(len(devices)+1))
+	position := map[string]int{}
+	for i, call := range reporter.calls {
+		position[call] = i
+	}
+	for _, device := range append(devices, "sdb") {
+		resolved, ok := position["resolved "+device]
+		require.True(t, ok, device)
+		require.Less(t, resolved, len(devices)+1)
+		started, ok := position["started disk_nomerges "+device]
+		require.True(t, ok, device)
+		finished, ok := position["finished disk_nomerges "+device+": ok"]
+		require.True(t, ok, device)
+		require.Less(t, started, finished)
+	}
+}
+
+func TestReporterFrom_default(t *testing.T) {
+	reporter, tuner := reporterFrom(context.Background())
+	require.Equal(t, NopReporter{}, reporter)
+	require.Empty(t, tuner)
+}
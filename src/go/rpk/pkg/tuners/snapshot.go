@@ -0,0 +1,150 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// DefaultSnapshotFile is where the values overwritten by the tuners are
+// stored by default.
+const DefaultSnapshotFile = "/var/lib/redpanda/tune_snapshot.json"
+
+// Snapshot holds the values tuners overwrote, as they were before tuning.
+type Snapshot struct {
+	Values []SnapshotValue `json:"values"`
+}
+
+// SnapshotValue is the value a knob had before it was first tuned. Device is
+// the block device the knob belongs to, if any.
+type SnapshotValue struct {
+	Device string `json:"device,omitempty"`
+	Path   string `json:"path"`
+	Value  string `json:"value"`
+}
+
+// ReadSnapshot reads the snapshot stored in the given file, an empty
+// snapshot is returned if the file doesn't exist.
+func ReadSnapshot(fs afero.Fs, file string) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if exists, _ := afero.Exists(fs, file); !exists {
+		return snapshot, nil
+	}
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		return nil, fmt.Errorf("unable to parse tune snapshot '%s': %v", file, err)
+	}
+	return snapshot, nil
+}
+
+// WriteSnapshot stores the snapshot in the given file.
+func WriteSnapshot(fs afero.Fs, file string, snapshot *Snapshot) error {
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, file, content, 0o644)
+}
+
+// Record adds the values the given changes overwrote to the snapshot. Values
+// already in the snapshot are kept, so tuning again doesn't lose the values
+// from before the first run. Changes creating files are not recorded as there
+// is no value to restore, nor are those writing the value already in place,
+// so that reverting never touches knobs the tuners didn't actually change.
+func (s *Snapshot) Record(changes []commands.Change) {
+	recorded := make(map[string]bool)
+	for _, v := range s.Values {
+		recorded[v.Path] = true
+	}
+	for _, change := range changes {
+		if change.Path == "" || change.Current == "" || recorded[change.Path] {
+			continue
+		}
+		if change.Current == strings.TrimSpace(change.Proposed) {
+			continue
+		}
+		recorded[change.Path] = true
+		s.Values = append(s.Values, SnapshotValue{
+			Device: knobDevice(change.Path),
+			Path:   change.Path,
+			Value:  change.Current,
+		})
+	}
+}
+
+// Revert restores the values in the snapshot with the given executor and
+// returns the changes it made. Values already in place are left untouched
+// and files that no longer exist, e.g. those of removed devices, are skipped.
+// The values restored, in place or skipped are pruned from the snapshot, so
+// that only those left to restore remain if reverting fails.
+func (s *Snapshot) Revert(
+	fs afero.Fs, executor executors.Executor,
+) (changes []commands.Change, err error) {
+	reverted := 0
+	defer func() { s.Values = s.Values[reverted:] }()
+	for _, v := range s.Values {
+		if exists, _ := afero.Exists(fs, v.Path); !exists {
+			if v.Device != "" {
+				log.Warnf("Skipping '%s' as device '%s' no longer exists", v.Path, v.Device)
+			} else {
+				log.Warnf("Skipping '%s' as it no longer exists", v.Path)
+			}
+			reverted++
+			continue
+		}
+		cmd := commands.NewWriteFileCmd(fs, v.Path, v.Value)
+		change, err := cmd.(commands.ChangeReporter).Change()
+		if err != nil {
+			return changes, err
+		}
+		if change.Current == v.Value {
+			log.Debugf("'%s' is already set to '%s'", v.Path, v.Value)
+			reverted++
+			continue
+		}
+		if err := executor.Execute(cmd); err != nil {
+			return changes, err
+		}
+		reverted++
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// knobDevice returns the name of the block device the sysfs knob at path
+// belongs to, e.g. 'sda' for '/sys/block/sda/queue/scheduler' or
+// '/sys/devices/pci0000:00/.../block/sda/device/scsi_disk/0:0:0:0/cache_type',
+// or an empty string if it's not a knob of a block device.
+func knobDevice(path string) string {
+	if !strings.HasPrefix(path, "/sys/") {
+		return ""
+	}
+	elems := strings.Split(filepath.Clean(path), "/")
+	for i := len(elems) - 2; i >= 0; i-- {
+		if elems[i] == "block" {
+			return elems[i+1]
+		}
+	}
+	return ""
+}
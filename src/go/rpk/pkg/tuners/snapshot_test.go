@@ -0,0 +1,162 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	const (
+		scheduler     = "/sys/block/sda/queue/scheduler"
+		nomerges      = "/sys/block/sdb/queue/nomerges"
+		irqAffinity   = "/proc/irq/32/smp_affinity"
+		snapshotsFile = "/var/lib/redpanda/tune_snapshot.json"
+	)
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, scheduler, []byte("[mq-deadline] none\n"), 0o644)
+	afero.WriteFile(fs, nomerges, []byte("0\n"), 0o644)
+	afero.WriteFile(fs, irqAffinity, []byte("ff\n"), 0o644)
+	afero.WriteFile(fs, irqAffinity+"_list", []byte("0-7\n"), 0o644)
+
+	// Tune and record the overwritten values.
+	executor := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	require.NoError(t, executor.Execute(commands.NewWriteFileCmd(fs, scheduler, "none")))
+	require.NoError(t, executor.Execute(commands.NewWriteFileCmd(fs, nomerges, "2")))
+	require.NoError(t, executor.Execute(commands.NewWriteFileCmd(fs, irqAffinity, "1")))
+	require.NoError(t, executor.Execute(commands.NewWriteFileCmd(fs, "/etc/new", "x")))
+	// Writing the value in place records nothing to restore.
+	require.NoError(t, executor.Execute(commands.NewWriteFileCmd(fs, irqAffinity+"_list", "0-7")))
+	snapshot, err := ReadSnapshot(fs, snapshotsFile)
+	require.NoError(t, err)
+	snapshot.Record(executor.Changes())
+	require.NoError(t, WriteSnapshot(fs, snapshotsFile, snapshot))
+
+	// Tuning again keeps the original values.
+	snapshot, err = ReadSnapshot(fs, snapshotsFile)
+	require.NoError(t, err)
+	snapshot.Record([]commands.Change{{Path: scheduler, Current: "none", Proposed: "none"}})
+	require.Equal(t, []SnapshotValue{
+		{Device: "sda", Path: scheduler, Value: "mq-deadline"},
+		{Device: "sdb", Path: nomerges, Value: "0"},
+		{Path: irqAffinity, Value: "ff"},
+	}, snapshot.Values)
+
+	// Revert, with the second device gone.
+	require.NoError(t, fs.RemoveAll("/sys/block/sdb"))
+	changes, err := snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.NoError(t, err)
+	require.Equal(t, []commands.Change{
+		{Path: scheduler, Current: "none", Proposed: "mq-deadline"},
+		{Path: irqAffinity, Current: "1", Proposed: "ff"},
+	}, changes)
+	content, _ := afero.ReadFile(fs, scheduler)
+	require.Equal(t, "mq-deadline", string(content))
+	content, _ = afero.ReadFile(fs, irqAffinity)
+	require.Equal(t, "ff", string(content))
+	require.Empty(t, snapshot.Values)
+
+	// Reverting again is a no-op.
+	changes, err = snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestSnapshot_revertSchedulerTuner(t *testing.T) {
+	const (
+		scheduler     = "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/queue/scheduler"
+		snapshotsFile = "/var/lib/redpanda/tune_snapshot.json"
+	)
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, scheduler, []byte("noop [deadline] cfq\n"), 0o644)
+	deviceFeatures := &deviceFeaturesMock{
+		getSchedulerFeatureFile: func(string) (string, error) {
+			return scheduler, nil
+		},
+		getScheduler: func(string) (string, error) {
+			content, err := afero.ReadFile(fs, scheduler)
+			active := strings.TrimSpace(string(content))
+			if start := strings.Index(active, "["); start >= 0 {
+				active = active[start+1 : strings.Index(active, "]")]
+			}
+			return active, err
+		},
+		getSupportedSchedulers: func(string) ([]string, error) {
+			return []string{"noop", "deadline", "cfq"}, nil
+		},
+	}
+
+	executor := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	res := NewDeviceSchedulerTuner(fs, "sda", "", deviceFeatures, executor).Tune(context.Background())
+	require.NoError(t, res.Error())
+	content, _ := afero.ReadFile(fs, scheduler)
+	require.Equal(t, "noop", string(content))
+
+	snapshot, err := ReadSnapshot(fs, snapshotsFile)
+	require.NoError(t, err)
+	snapshot.Record(executor.Changes())
+	require.NoError(t, WriteSnapshot(fs, snapshotsFile, snapshot))
+
+	snapshot, err = ReadSnapshot(fs, snapshotsFile)
+	require.NoError(t, err)
+	require.Equal(t, []SnapshotValue{{Device: "sda", Path: scheduler, Value: "deadline"}}, snapshot.Values)
+	changes, err := snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.NoError(t, err)
+	require.Equal(t, []commands.Change{{Path: scheduler, Current: "noop", Proposed: "deadline"}}, changes)
+	content, _ = afero.ReadFile(fs, scheduler)
+	require.Equal(t, "deadline", string(content))
+
+	// Reverting again is a no-op.
+	changes, err = snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+// unwritableFs fails the writes to the file at path.
+type unwritableFs struct {
+	afero.Fs
+	path string
+}
+
+func (fs *unwritableFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if name == fs.path && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		return nil, os.ErrPermission
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func TestSnapshot_Revert_failure(t *testing.T) {
+	const (
+		scheduler = "/sys/block/sda/queue/scheduler"
+		nomerges  = "/sys/block/sdb/queue/nomerges"
+	)
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, scheduler, []byte("none\n"), 0o644)
+	afero.WriteFile(memFs, nomerges, []byte("2\n"), 0o644)
+	// The knob of sdb can't be written.
+	fs := &unwritableFs{Fs: memFs, path: nomerges}
+	snapshot := &Snapshot{Values: []SnapshotValue{
+		{Device: "sda", Path: scheduler, Value: "mq-deadline"},
+		{Device: "sdb", Path: nomerges, Value: "0"},
+	}}
+	changes, err := snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.Error(t, err)
+	require.Equal(t, []commands.Change{{Path: scheduler, Current: "none", Proposed: "mq-deadline"}}, changes)
+	// Only the value left to restore remains.
+	require.Equal(t, []SnapshotValue{{Device: "sdb", Path: nomerges, Value: "0"}}, snapshot.Values)
+}
@@ -42,13 +42,18 @@ func NewSwappinessChecker(fs afero.Fs) Checker {
 }
 
 func NewSwappinessTuner(fs afero.Fs, executor executors.Executor) Tunable {
-	return NewCheckedTunable(
+	return newFileCheckedTunable(
 		NewSwappinessChecker(fs),
 		func() TuneResult {
 			log.Debugf("Setting swappiness to %d", ExpectedSwappiness)
 			err := executor.Execute(
 				commands.NewWriteFileCmd(
 					fs, File, fmt.Sprint(ExpectedSwappiness)))
+			if isPermissionDenied(err) {
+				// e.g. in containers, where /proc/sys is mounted read-only.
+				log.Debugf("Unable to write %d to %s: %v", ExpectedSwappiness, File, err)
+				return NewTuneNotApplied("cannot modify vm.swappiness, /proc/sys is read-only")
+			}
 			if err != nil {
 				log.Errorf("got an error while writing %d to %s: %v", ExpectedSwappiness, File, err)
 				return NewTuneError(err)
@@ -59,5 +64,8 @@ func NewSwappinessTuner(fs afero.Fs, executor executors.Executor) Tunable {
 			return true, ""
 		},
 		executor.IsLazy(),
+		func() ([]string, error) {
+			return []string{File}, nil
+		},
 	)
 }
@@ -10,6 +10,7 @@
 package tuners_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -117,7 +118,7 @@ func TestTuner(t *testing.T) {
 				require.NoError(t, err)
 			}
 			tuner := tuners.NewSwappinessTuner(fs, executors.NewDirectExecutor())
-			res := tuner.Tune()
+			res := tuner.Tune(context.Background())
 			if tt.expectErr {
 				require.Error(t, res.Error())
 				return
@@ -130,3 +131,33 @@ func TestTuner(t *testing.T) {
 		})
 	}
 }
+
+func TestTunerReadOnlyProcSys(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := utils.WriteBytes(fs, []byte("60"), tuners.File)
+	require.NoError(t, err)
+
+	tuner := tuners.NewSwappinessTuner(afero.NewReadOnlyFs(fs), executors.NewDirectExecutor())
+	res := tuner.Tune(context.Background())
+	require.False(t, res.IsFailed())
+	require.Equal(t, "cannot modify vm.swappiness, /proc/sys is read-only", res.NotAppliedReason())
+}
+
+func TestTunerRevert(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := utils.WriteBytes(fs, []byte("60\n"), tuners.File)
+	require.NoError(t, err)
+
+	exec := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	res := tuners.NewSwappinessTuner(fs, exec).Tune(context.Background())
+	require.NoError(t, res.Error())
+
+	snapshot := &tuners.Snapshot{}
+	snapshot.Record(exec.Changes())
+	require.Equal(t, []tuners.SnapshotValue{{Path: tuners.File, Value: "60"}}, snapshot.Values)
+	_, err = snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.NoError(t, err)
+	result := tuners.NewSwappinessChecker(fs).Check()
+	require.False(t, result.IsOk)
+	require.Equal(t, "60", result.Current)
+}
@@ -15,18 +15,19 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/system"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/disk"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
 const enabledFile = "enabled"
 
-type thpTuner struct {
-	fs       afero.Fs
-	executor executors.Executor
-}
+// RecommendedTHPMode is the mode the transparent huge pages tuner sets: the
+// memory of the seastar runtime is then backed by huge pages without having to
+// be advised.
+const RecommendedTHPMode = "always"
 
 // Returns the known locations where config files for Transparent Huge Pages
 // might be found across distros.
@@ -53,48 +54,124 @@ func getTHPDir(fs afero.Fs) (string, error) {
 	)
 }
 
-// Create a new tuner to enable Transparent Huge Pages.
-func NewEnableTHPTuner(fs afero.Fs, executor executors.Executor) Tunable {
-	return &thpTuner{fs: fs, executor: executor}
-}
-
-func (t *thpTuner) CheckIfSupported() (bool, string) {
-	dir, err := getTHPDir(t.fs)
+// THPEnabledFile returns the path of the file holding the THP mode, failing
+// on kernels lacking it.
+func THPEnabledFile(fs afero.Fs) (string, error) {
+	dir, err := getTHPDir(fs)
 	if err != nil {
-		return false, err.Error()
+		return "", err
+	}
+	file := filepath.Join(dir, enabledFile)
+	if exists, _ := afero.Exists(fs, file); !exists {
+		return "", fmt.Errorf("'%s' was not found", file)
 	}
-	return dir != "", ""
+	return file, nil
 }
 
-func (t *thpTuner) Tune() TuneResult {
-	dir, err := getTHPDir(t.fs)
+// readTHPMode returns the content of the THP 'enabled' file, listing the
+// available modes with the active one in brackets, e.g. '[always] madvise
+// never', and the active mode.
+func readTHPMode(fs afero.Fs) (content, mode string, err error) {
+	file, err := THPEnabledFile(fs)
 	if err != nil {
-		return NewTuneError(err)
+		return "", "", err
 	}
-	// Write 'always' to the 'enabled' file in the existing THP dir so that
-	// they're always enabled.
-	// https://www.kernel.org/doc/Documentation/vm/transhuge.txt
-
-	cmd := commands.NewWriteFileCmd(
-		t.fs,
-		filepath.Join(dir, enabledFile),
-		"always",
-	)
-	err = t.executor.Execute(cmd)
+	mode, modes, err := disk.ReadSysfsBracketed(fs, file)
 	if err != nil {
-		return NewTuneError(err)
+		return "", "", err
+	}
+	if mode == "" {
+		return "", "", fmt.Errorf("unable to find the active mode in '%s': %q", file, strings.Join(modes, " "))
+	}
+	listed := make([]string, 0, len(modes))
+	for _, m := range modes {
+		if m == mode && len(modes) > 1 {
+			m = "[" + m + "]"
+		}
+		listed = append(listed, m)
 	}
-	return NewTuneResult(false)
+	return strings.Join(listed, " "), mode, nil
 }
 
-func NewTransparentHugePagesChecker(fs afero.Fs) Checker {
-	return NewEqualityChecker(
-		TransparentHugePagesChecker,
-		"Transparent huge pages active",
-		Warning,
-		true,
-		func() (interface{}, error) {
-			return system.GetTransparentHugePagesActive(fs)
+// NewTransparentHugePagesTuner creates a tuner setting the THP mode to
+// RecommendedTHPMode if they're disabled. The previous mode is recorded in
+// the tune snapshot, so it's restored by reverting. Kernels lacking THP are
+// reported as not supported.
+func NewTransparentHugePagesTuner(fs afero.Fs, executor executors.Executor) Tunable {
+	return newFileCheckedTunable(
+		NewTransparentHugePagesChecker(fs),
+		func() TuneResult {
+			file, err := THPEnabledFile(fs)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			// https://www.kernel.org/doc/Documentation/vm/transhuge.txt
+			log.Debugf("Setting the transparent huge pages mode to '%s'", RecommendedTHPMode)
+			err = executor.Execute(commands.NewWriteFileCmd(fs, file, RecommendedTHPMode))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			if _, err := THPEnabledFile(fs); err != nil {
+				return false, err.Error()
+			}
+			return true, ""
+		},
+		executor.IsLazy(),
+		func() ([]string, error) {
+			file, err := THPEnabledFile(fs)
+			if err != nil {
+				// The kernel lacks transparent huge pages, which
+				// CheckIfSupported already reports.
+				return nil, nil //nolint:nilerr // not an error when probing.
+			}
+			return []string{file}, nil
 		},
 	)
 }
+
+// NewTransparentHugePagesChecker creates a checker passing unless THP are
+// disabled, 'madvise' being enough as seastar advises huge pages for its
+// memory. The current value is the content of the THP 'enabled' file.
+func NewTransparentHugePagesChecker(fs afero.Fs) Checker {
+	return &thpChecker{fs: fs}
+}
+
+type thpChecker struct {
+	fs afero.Fs
+}
+
+func (*thpChecker) ID() CheckerID {
+	return TransparentHugePagesChecker
+}
+
+func (*thpChecker) GetDesc() string {
+	return "Transparent huge pages active"
+}
+
+func (*thpChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (*thpChecker) GetRequiredAsString() string {
+	return RecommendedTHPMode + " or madvise"
+}
+
+func (c *thpChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerID: c.ID(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	content, mode, err := readTHPMode(c.fs)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.IsOk = mode != "never"
+	res.Current = content
+	return res
+}
@@ -10,6 +10,7 @@
 package tuners_test
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
@@ -25,6 +26,7 @@ func TestTHPTunerSupported(t *testing.T) {
 	tests := []struct {
 		name           string
 		thpDir         string
+		noEnabledFile  bool
 		expected       bool
 		expectedReason string
 	}{
@@ -43,18 +45,28 @@ func TestTHPTunerSupported(t *testing.T) {
 			expected:       false,
 			expectedReason: "None of /sys/kernel/mm/transparent_hugepage, /sys/kernel/mm/redhat_transparent_hugepage was found",
 		},
+		{
+			name:           "should return false if the enabled file doesn't exist",
+			thpDir:         thpDir,
+			noEnabledFile:  true,
+			expected:       false,
+			expectedReason: "'/sys/kernel/mm/transparent_hugepage/enabled' was not found",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(st *testing.T) {
 			fs := afero.NewMemMapFs()
 
-			if tt.thpDir != "" {
+			if tt.noEnabledFile {
 				err := fs.MkdirAll(tt.thpDir, 0o755)
 				require.NoError(st, err)
+			} else if tt.thpDir != "" {
+				err := afero.WriteFile(fs, filepath.Join(tt.thpDir, "enabled"), []byte("always madvise [never]\n"), 0o644)
+				require.NoError(st, err)
 			}
 			exec := executors.NewDirectExecutor()
-			tuner := tuners.NewEnableTHPTuner(fs, exec)
+			tuner := tuners.NewTransparentHugePagesTuner(fs, exec)
 			supported, reason := tuner.CheckIfSupported()
 			require.Equal(st, tt.expected, supported)
 			require.Equal(st, tt.expectedReason, reason)
@@ -78,12 +90,12 @@ echo 'always' > /sys/kernel/mm/transparent_hugepage/enabled
 	err := fs.MkdirAll(dir, 0o755)
 	require.NoError(t, err)
 
-	_, err = fs.Create(filepath.Join(dir, "enabled"))
+	err = afero.WriteFile(fs, filepath.Join(dir, "enabled"), []byte("always madvise [never]\n"), 0o644)
 	require.NoError(t, err)
 
-	tuner := tuners.NewEnableTHPTuner(fs, exec)
+	tuner := tuners.NewTransparentHugePagesTuner(fs, exec)
 
-	res := tuner.Tune()
+	res := tuner.Tune(context.Background())
 	require.False(t, res.IsFailed())
 
 	bs, err := afero.ReadFile(fs, scriptFileName)
@@ -109,12 +121,12 @@ func TestTHPTunerDirectExecutor(t *testing.T) {
 	err := fs.MkdirAll(dir, 0o755)
 	require.NoError(t, err)
 
-	_, err = fs.Create(filePath)
+	err = afero.WriteFile(fs, filePath, []byte("always madvise [never]\n"), 0o644)
 	require.NoError(t, err)
 
-	tuner := tuners.NewEnableTHPTuner(fs, exec)
+	tuner := tuners.NewTransparentHugePagesTuner(fs, exec)
 
-	res := tuner.Tune()
+	res := tuner.Tune(context.Background())
 	require.False(t, res.IsFailed())
 	require.False(t, res.IsRebootRequired())
 
@@ -124,6 +136,29 @@ func TestTHPTunerDirectExecutor(t *testing.T) {
 	require.Equal(t, expected, string(bs))
 }
 
+func TestTHPTunerRevert(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filePath := filepath.Join(thpDir, "enabled")
+	err := afero.WriteFile(fs, filePath, []byte("always madvise [never]\n"), 0o644)
+	require.NoError(t, err)
+
+	exec := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	res := tuners.NewTransparentHugePagesTuner(fs, exec).Tune(context.Background())
+	require.False(t, res.IsFailed())
+	bs, err := afero.ReadFile(fs, filePath)
+	require.NoError(t, err)
+	require.Equal(t, "always", string(bs))
+
+	snapshot := &tuners.Snapshot{}
+	snapshot.Record(exec.Changes())
+	require.Equal(t, []tuners.SnapshotValue{{Path: filePath, Value: "never"}}, snapshot.Values)
+	_, err = snapshot.Revert(fs, executors.NewDirectExecutor())
+	require.NoError(t, err)
+	bs, err = afero.ReadFile(fs, filePath)
+	require.NoError(t, err)
+	require.Equal(t, "never", string(bs))
+}
+
 func TestTHPCheckID(t *testing.T) {
 	c := tuners.NewTransparentHugePagesChecker(afero.NewMemMapFs())
 	require.Equal(t, tuners.CheckerID(tuners.TransparentHugePagesChecker), c.ID())
@@ -165,7 +200,16 @@ func TestTHPCheck(t *testing.T) {
 			require.NoError(t, err)
 			c := tuners.NewTransparentHugePagesChecker(fs)
 			res := c.Check()
+			require.NoError(t, res.Err)
 			require.Equal(t, tt.expected, res.IsOk)
+			require.Equal(t, tt.contents, res.Current)
 		})
 	}
 }
+
+func TestTHPCheckWithoutTHP(t *testing.T) {
+	res := tuners.NewTransparentHugePagesChecker(afero.NewMemMapFs()).Check()
+	require.Error(t, res.Err)
+	require.False(t, res.IsOk)
+	require.Equal(t, tuners.Severity(tuners.Warning), res.Severity)
+}
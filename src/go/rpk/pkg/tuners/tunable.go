@@ -9,7 +9,30 @@
 
 package tuners
 
+import "context"
+
 type Tunable interface {
 	CheckIfSupported() (supported bool, reason string)
-	Tune() TuneResult
+	// Tune applies the tuning, it stops before its next blocking operation,
+	// e.g. a sysfs write, once ctx is done.
+	Tune(ctx context.Context) TuneResult
+}
+
+// DeviceTunable is a Tunable acting on block devices.
+type DeviceTunable interface {
+	Tunable
+	// Devices returns the physical devices the tuner acts on, resolved from
+	// its directories and devices.
+	Devices() ([]string, error)
+}
+
+// FileTunable is a Tunable whose files are known ahead of tuning, e.g. the
+// sysfs attributes it writes to, which 'rpk redpanda tune capabilities'
+// probes.
+type FileTunable interface {
+	Tunable
+	// TunableFiles returns the files the tuner writes to for the device, or
+	// the host-wide ones if device is empty. It's empty for the devices not
+	// exposing the file.
+	TunableFiles(device string) ([]string, error)
 }
@@ -0,0 +1,88 @@
+// Copyright 2023 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TuneErrors is the failures of a tuner on some of its devices, or to resolve
+// some of them, while the others were still tuned. errors.Is and errors.As
+// match any of them, e.g. a context.Canceled one or a disk.DeviceResolveError.
+type TuneErrors struct {
+	Errs []error
+}
+
+// Append adds the failures to errs, skipping the nil ones, and returns it,
+// allocating it if it's nil. The failures of nested TuneErrors are added
+// themselves.
+func (errs *TuneErrors) Append(failures ...error) *TuneErrors {
+	if errs == nil {
+		errs = &TuneErrors{}
+	}
+	for _, err := range failures {
+		var nested *TuneErrors
+		switch {
+		case err == nil:
+		case errors.As(err, &nested) && nested == err:
+			errs.Errs = append(errs.Errs, nested.Errs...)
+		default:
+			errs.Errs = append(errs.Errs, err)
+		}
+	}
+	return errs
+}
+
+// ErrorOrNil returns errs as an error if it holds any failure, nil otherwise.
+func (errs *TuneErrors) ErrorOrNil() error {
+	if errs == nil || len(errs.Errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Error formats the failures on a single line, to fit in the tune report.
+func (errs *TuneErrors) Error() string {
+	if len(errs.Errs) == 1 {
+		return errs.Errs[0].Error()
+	}
+	msgs := make([]string, 0, len(errs.Errs))
+	for _, err := range errs.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d devices failed: %s", len(errs.Errs), strings.Join(msgs, "; "))
+}
+
+func (errs *TuneErrors) Unwrap() []error {
+	return errs.Errs
+}
+
+// Is and As match any of the failures, for the errors package of the Go
+// versions not unwrapping multiple errors.
+
+func (errs *TuneErrors) Is(target error) bool {
+	for _, err := range errs.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (errs *TuneErrors) As(target interface{}) bool {
+	for _, err := range errs.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
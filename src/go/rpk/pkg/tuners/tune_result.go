@@ -9,15 +9,31 @@
 
 package tuners
 
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
 type TuneResult interface {
 	IsFailed() bool
 	Error() error
 	IsRebootRequired() bool
+	// NotAppliedReason returns why the tuner left the system untouched
+	// without failing, or an empty string if its changes were applied.
+	NotAppliedReason() string
 }
 
 type tuneResult struct {
 	err            error
 	rebootRequired bool
+	notApplied     string
+	// unchanged is set when the tuner found the system already tuned, see
+	// newTuneUnchanged.
+	unchanged bool
+	// previous and value are the value a tuner changed and the one it set,
+	// see newTuneChanged.
+	previous, value string
 }
 
 func NewTuneError(err error) TuneResult {
@@ -28,6 +44,31 @@ func NewTuneResult(rebootRequired bool) TuneResult {
 	return &tuneResult{rebootRequired: rebootRequired}
 }
 
+// NewTuneNotApplied returns the result of a tuner that couldn't apply its
+// changes for a reason that shouldn't fail the tuning, e.g. a read-only sysfs
+// in containers.
+func NewTuneNotApplied(reason string) TuneResult {
+	return &tuneResult{notApplied: reason}
+}
+
+// newTuneUnchanged returns the result of a tuner that had nothing to write as
+// the desired value was already in place, which is reported as already tuned.
+func newTuneUnchanged() TuneResult {
+	return &tuneResult{unchanged: true}
+}
+
+// newTuneChanged returns the result of a tuner that changed a value from
+// previous to value, which are reported as the previous and new values of
+// its DeviceTuneResult.
+func newTuneChanged(previous, value string) TuneResult {
+	return &tuneResult{previous: previous, value: value}
+}
+
+func isUnchanged(result TuneResult) bool {
+	r, ok := result.(*tuneResult)
+	return ok && r.unchanged
+}
+
 func (result *tuneResult) IsFailed() bool {
 	return result.err != nil
 }
@@ -39,3 +80,51 @@ func (result *tuneResult) Error() error {
 func (result *tuneResult) IsRebootRequired() bool {
 	return result.rebootRequired
 }
+
+func (result *tuneResult) NotAppliedReason() string {
+	return result.notApplied
+}
+
+// The statuses of DeviceTuneResult.
+const (
+	DeviceApplied = "applied"
+	DeviceSkipped = "skipped"
+	DeviceFailed  = "failed"
+)
+
+// DeviceTuneResult is the result of tuning a single device, as printed by
+// 'rpk redpanda tune --format json'. Its JSON field names are stable, CI
+// pipelines assert on them:
+//
+//   - tuner: the name of the tuner, e.g. 'disk_scheduler'.
+//   - device: the name of the device, e.g. 'nvme0n1'.
+//   - syspath: the system path of the device, if it could be resolved.
+//   - status: 'applied', 'skipped' or 'failed'.
+//   - previous_value: the value found before tuning, if it was read.
+//   - new_value: the value the device was tuned to, or the one required of
+//     it when the exact value isn't known, if applied.
+//   - reason: why the device was skipped.
+//   - error: why tuning the device failed.
+type DeviceTuneResult struct {
+	Tuner    string `json:"tuner"`
+	Device   string `json:"device"`
+	Syspath  string `json:"syspath,omitempty"`
+	Status   string `json:"status"`
+	Previous string `json:"previous_value,omitempty"`
+	New      string `json:"new_value,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DeviceTuneResults is implemented by the results of the disk tuners, listing
+// the result of each device they went through, in device order. The name of
+// the tuner is left for the caller to fill in.
+type DeviceTuneResults interface {
+	DeviceResults() []DeviceTuneResult
+}
+
+// isPermissionDenied returns whether err is caused by the lack of
+// permissions to write, including writes to read-only filesystems.
+func isPermissionDenied(err error) bool {
+	return errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
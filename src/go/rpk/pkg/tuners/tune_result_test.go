@@ -10,6 +10,7 @@
 package tuners
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -39,3 +40,42 @@ func TestNewTuneResult(t *testing.T) {
 		})
 	}
 }
+
+func TestDeviceTuneResult_json(t *testing.T) {
+	results := []DeviceTuneResult{
+		{
+			Tuner:    "disk_scheduler",
+			Device:   "nvme0n1",
+			Syspath:  "/sys/devices/pci0000:00/0000:00:04.0/nvme/nvme0/nvme0n1",
+			Status:   DeviceApplied,
+			Previous: "mq-deadline",
+			New:      "none",
+		},
+		{
+			Tuner:    "disk_scheduler",
+			Device:   "sda",
+			Status:   DeviceSkipped,
+			Previous: "none",
+			Reason:   "already tuned",
+		},
+		{
+			Tuner:  "disk_nomerges",
+			Device: "sdb",
+			Status: DeviceFailed,
+			Error:  "open /sys/block/sdb/queue/nomerges: permission denied",
+		},
+	}
+	// The field names are stable, the optional ones are left out when empty.
+	want := `[` +
+		`{"tuner":"disk_scheduler","device":"nvme0n1","syspath":"/sys/devices/pci0000:00/0000:00:04.0/nvme/nvme0/nvme0n1","status":"applied","previous_value":"mq-deadline","new_value":"none"},` +
+		`{"tuner":"disk_scheduler","device":"sda","status":"skipped","previous_value":"none","reason":"already tuned"},` +
+		`{"tuner":"disk_nomerges","device":"sdb","status":"failed","error":"open /sys/block/sdb/queue/nomerges: permission denied"}` +
+		`]`
+	got, err := json.Marshal(results)
+	require.NoError(t, err)
+	require.Equal(t, want, string(got))
+
+	var decoded []DeviceTuneResult
+	require.NoError(t, json.Unmarshal(got, &decoded))
+	require.Equal(t, results, decoded)
+}
@@ -9,6 +9,8 @@
 
 package utils
 
+import "strings"
+
 func StringInSlice(str string, ss []string) bool {
 	for _, s := range ss {
 		if str == s {
@@ -17,3 +19,11 @@ func StringInSlice(str string, ss []string) bool {
 	}
 	return false
 }
+
+var prometheusLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// EscapePrometheusLabel escapes the backslashes, double quotes and line feeds
+// of a label value, as required by the Prometheus text exposition format.
+func EscapePrometheusLabel(value string) string {
+	return prometheusLabelReplacer.Replace(value)
+}
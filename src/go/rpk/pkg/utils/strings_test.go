@@ -37,3 +37,8 @@ func TestStringInSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestEscapePrometheusLabel(t *testing.T) {
+	require.Equal(t, `nvme0n1`, utils.EscapePrometheusLabel("nvme0n1"))
+	require.Equal(t, `a\\b\"c\nd`, utils.EscapePrometheusLabel("a\\b\"c\nd"))
+}
@@ -203,9 +203,15 @@ tune_disk_irq: true
     tune_network: true
     tune_disk_scheduler: true
     tune_disk_nomerges: true
+    tune_disk_nr_requests: true
+    tune_disk_read_ahead: true
+    tune_disk_add_random: true
+    tune_disk_max_sectors: true
+    tune_disk_rq_affinity: true
     tune_disk_write_cache: true
     tune_disk_irq: true
     tune_cpu: true
+    tune_cpu_governor: true
     tune_aio_events: true
     tune_clocksource: true
     tune_swappiness: true
@@ -68,20 +68,29 @@ class RpkTunerTest(RedpandaTest):
         rpk.config_set('rpk.tune_transparent_hugepages', 'true')
         rpk.config_set('rpk.tune_coredump', 'true')
 
-        expected = '''TUNER                  ENABLED  SUPPORTED  UNSUPPORTED-REASON
-aio_events             true     true       
-ballast_file           true     true       
-clocksource            true     true       
-coredump               true     true       
-cpu                    true     true       
-disk_irq               true     true       
-disk_nomerges          true     true       
-disk_scheduler         true     true       
-disk_write_cache       true     false      Disk write cache tuner is only supported in GCP
-fstrim                 true     true       
-net                    true     true       
-swappiness             true     true       
-transparent_hugepages  true     true       
+        expected = '''TUNER                      ENABLED  SUPPORTED  UNSUPPORTED-REASON
+aio_events                 true     true       
+ballast_file               true     true       
+clocksource                true     true       
+coredump                   true     true       
+cpu                        true     true       
+cpu_governor               true     true       
+disk_add_random            true     true       
+disk_irq                   true     true       
+disk_max_sectors           true     true       
+disk_nomerges              true     true       
+disk_nr_requests           true     true       
+disk_read_ahead            true     true       
+disk_rq_affinity           true     true       
+disk_scheduler             true     true       
+disk_volatile_write_cache  false    true       
+disk_write_cache           true     false      Disk write cache tuner is only supported in GCP
+fstrim                     true     true       
+net                        true     true       
+rps                        true     true       
+swappiness                 true     true       
+transparent_hugepages      true     true       
+xps                        true     true       
 '''
 
         uname = str(node.account.ssh_output("uname -m"))
@@ -90,10 +99,20 @@ transparent_hugepages  true     true
 
         # Clocksource is only available for x86 architectures.
         expected = expected.replace(
-            "clocksource            true     true       ",
-            "clocksource            true     false      Clocksource setting not available for this architecture"
+            "clocksource                true     true       ",
+            "clocksource                true     false      Clocksource setting not available for this architecture"
         ) if is_not_x86 else expected
 
+        # The CPU governor is only available on CPUs exposing cpufreq, which
+        # VMs often don't.
+        has_cpufreq = node.account.ssh_output(
+            "ls /sys/devices/system/cpu/cpu*/cpufreq/scaling_governor 2>/dev/null | wc -l"
+        ).decode().strip() != "0"
+        expected = expected.replace(
+            "cpu_governor               true     true       ",
+            "cpu_governor               true     false      no CPU exposes cpufreq"
+        ) if not has_cpufreq else expected
+
         output = rpk.tune("list")
         self.logger.debug(output)
 